@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Prompt styles
+const (
+	promptMinimal = "minimal"
+	promptFull    = "full"
+)
+
+// promptStrategies are the debugging strategies every prompt carries
+var promptStrategies = []string{
+	"Keep the session address in $ADDR and pass --addr on every command; each command prints one JSON response and exits.",
+	"Form a hypothesis first, then set a breakpoint where it can be checked (`break file:line`, `--cond` to skip uninteresting hits) and `continue`.",
+	"At a stop read `locals`, `args` and `stack`; `eval` anything else; `frame N`, `up` and `down` select the frame they read.",
+	"Branch on the exit code and `error.code`, never on message text; `error.details` carries the context and often suggestions.",
+	"For a hang list `goroutines` and look at the blocked ones; for a crash break on the failing line and walk the `stack`.",
+}
+
+// promptFullStrategies are added by --style full
+var promptFullStrategies = []string{
+	"INSPECT_WHILE_RUNNING means the target is running: `halt` it, or `continue` with a breakpoint that will stop it.",
+	"STALE_CONTEXT means the selected frame belongs to a stop the target left: run `stack` and `frame N` again.",
+	"OPERATION_IN_PROGRESS with operation step means a breakpoint interrupted a step: `continue` to finish it, or repeat the step with --cancel-pending.",
+	"For a flaky failure let `hunt --until <expr>` restart the program until the condition reproduces.",
+	"Add --with-state to get the stop context with every response, and --budget-tokens N to cap large responses.",
+	"Change one thing at a time and confirm each finding against the program's state before moving on.",
+	"End the session with `quit` so the debug server and the target do not outlive the investigation.",
+}
+
+// promptFlag renders a flag for the command reference
+func promptFlag(p toolParam) string {
+	s := "--" + p.Name
+	if t, _ := p.Schema["type"].(string); t != "boolean" {
+		s += " <" + t + ">"
+	}
+	desc, _ := p.Schema["description"].(string)
+	if def, ok := p.Schema["default"]; ok {
+		desc += fmt.Sprintf(" (default %v)", def)
+	}
+	return fmt.Sprintf("`%s` %s", s, desc)
+}
+
+// agentPrompt renders the system prompt snippet for tools. The minimal style
+// lists each command's usage and summary; full adds their flags, the response
+// fields and more strategies.
+func agentPrompt(tools []toolDef, style string) string {
+	full := style == promptFull
+	var b strings.Builder
+
+	b.WriteString("# Debugging Go programs with godebug\n\n")
+	b.WriteString("godebug drives the Delve debugger from the command line. Every command prints a single JSON response:\n\n")
+	b.WriteString("    {\"success\": true, \"command\": \"locals\", \"data\": {...}, \"message\": \"...\"}\n")
+	b.WriteString("    {\"success\": false, \"command\": \"eval\", \"error\": {\"code\": \"NOT_FOUND\", \"message\": \"...\", \"details\": {...}}}\n\n")
+	if full {
+		// Required fields first, then the others by name
+		schema := responseSchema()
+		props := schema["properties"].(map[string]any)
+		fields := slices.Clone(schema["required"].([]string))
+		var optional []string
+		for name := range props {
+			if !slices.Contains(fields, name) {
+				optional = append(optional, name)
+			}
+		}
+		sort.Strings(optional)
+		b.WriteString("Response fields:\n")
+		for _, name := range append(fields, optional...) {
+			desc, _ := props[name].(map[string]any)["description"].(string)
+			fmt.Fprintf(&b, "- `%s`: %s\n", name, desc)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Commands\n\n")
+	var globals []toolParam
+	for _, t := range tools {
+		fmt.Fprintf(&b, "- `%s`: %s\n", t.Usage, t.Short)
+		for _, p := range t.Params {
+			switch {
+			case p.Global:
+				if !slices.ContainsFunc(globals, func(g toolParam) bool { return g.Name == p.Name }) {
+					globals = append(globals, p)
+				}
+			case full && !p.Positional:
+				fmt.Fprintf(&b, "  - %s\n", promptFlag(p))
+			}
+		}
+	}
+	if len(globals) > 0 {
+		b.WriteString("\nFlags of every command:\n")
+		for _, p := range globals {
+			if full || p.Name == "addr" || p.Name == "timeout" {
+				fmt.Fprintf(&b, "- %s\n", promptFlag(p))
+			}
+		}
+	}
+
+	b.WriteString("\n## Errors\n\n")
+	b.WriteString("A failed command exits non-zero and reports error.code:\n\n")
+	for _, ec := range output.ErrorCodes {
+		if full {
+			fmt.Fprintf(&b, "- `%s` (exit %d): %s\n", ec.Code, output.ExitCodeFor(ec.Code), ec.Description)
+		} else {
+			fmt.Fprintf(&b, "- `%s` (exit %d)\n", ec.Code, output.ExitCodeFor(ec.Code))
+		}
+	}
+
+	b.WriteString("\n## Strategy\n\n")
+	strategies := promptStrategies
+	if full {
+		strategies = append(slices.Clone(promptStrategies), promptFullStrategies...)
+	}
+	for _, s := range strategies {
+		fmt.Fprintf(&b, "- %s\n", s)
+	}
+	return b.String()
+}
+
+// addAgentPromptCommand adds the agent-prompt command
+func addAgentPromptCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat) {
+	var promptStyle string
+	var promptCommands []string
+
+	agentPromptCmd := &cobra.Command{
+		Use:   "agent-prompt",
+		Short: "Print a system prompt snippet describing godebug for agents",
+		Long: `Print a markdown snippet for an agent's system prompt: the response
+envelope, every command with its usage and summary, the error codes with
+their exit codes, and recommended debugging strategies. It is generated from
+the same command metadata as toolspec, so it never drifts from the build.
+
+  minimal   usage and summary of each command, error codes, core strategies
+  full      adds each command's flags, the response fields, error code
+            descriptions and more strategies
+
+Like toolspec, agent-prompt prints the snippet itself rather than the
+response envelope, so it can be redirected into a file.
+
+Example:
+  godebug agent-prompt > prompts/godebug.md
+  godebug agent-prompt --style minimal --command break --command continue --command locals`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if promptStyle != promptMinimal && promptStyle != promptFull {
+				output.ErrorWithInfo("agent-prompt", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("unknown style %q", promptStyle),
+					map[string]any{"style": promptStyle, "styles": []string{promptMinimal, promptFull}},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			tools, missing := selectTools(collectTools(cmd.Root(), true), promptCommands)
+			if missing != "" {
+				output.ErrorWithInfo("agent-prompt", output.NotFound("command", missing)).PrintAndExit(getOutputFormat())
+			}
+			if _, err := fmt.Fprint(os.Stdout, agentPrompt(tools, promptStyle)); err != nil {
+				output.Error("agent-prompt", err).PrintAndExit(getOutputFormat())
+			}
+		},
+	}
+
+	agentPromptCmd.Flags().StringVar(&promptStyle, "style", promptFull, "Prompt style: minimal or full")
+	agentPromptCmd.Flags().StringArrayVar(&promptCommands, "command", nil, "Only this command (repeatable, subcommands as \"analyze threads\")")
+	root.AddCommand(agentPromptCmd)
+}
+
+func init() {
+	addAgentPromptCommand(rootCmd, GetOutputFormat)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// TestAgentPromptStyles checks both styles list every command and error code,
+// and only full carries per-command flags.
+func TestAgentPromptStyles(t *testing.T) {
+	tools := collectTools(NewRootCmd(), true)
+	minimal := agentPrompt(tools, promptMinimal)
+	full := agentPrompt(tools, promptFull)
+
+	for _, prompt := range []string{minimal, full} {
+		for _, tool := range tools {
+			if !strings.Contains(prompt, "`"+tool.Usage+"`") {
+				t.Errorf("prompt is missing %s", tool.Usage)
+			}
+		}
+		for _, ec := range output.ErrorCodes {
+			if !strings.Contains(prompt, "`"+ec.Code+"`") {
+				t.Errorf("prompt is missing error code %s", ec.Code)
+			}
+		}
+		if strings.Contains(prompt, "godebug agent-prompt") || strings.Contains(prompt, "godebug toolspec") {
+			t.Errorf("prompt lists the manifest commands")
+		}
+	}
+
+	if strings.Contains(minimal, "`--cond <string>`") || !strings.Contains(full, "`--cond <string>`") {
+		t.Errorf("break --cond: want it in full only")
+	}
+	if !strings.Contains(minimal, "`--addr <string>`") || strings.Contains(minimal, "`--with-state`") {
+		t.Errorf("minimal should list only --addr and --timeout of the global flags")
+	}
+	if len(minimal) >= len(full) {
+		t.Errorf("minimal prompt (%d bytes) is not shorter than full (%d bytes)", len(minimal), len(full))
+	}
+}
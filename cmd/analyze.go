@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// Thread states reported by analyze threads
+const (
+	threadRunning = "running" // executing Go code
+	threadSyscall = "syscall" // its goroutine is blocked in a system call
+	threadCgo     = "cgo"     // executing C code through cgo
+	threadIdle    = "idle"    // parked in the scheduler or the network poller
+)
+
+// threadIdleFuncs are the runtime functions an M without work sleeps in
+var threadIdleFuncs = map[string]bool{
+	"runtime.futex":                        true,
+	"runtime.usleep":                       true,
+	"runtime.nanosleep":                    true,
+	"runtime.osyield":                      true,
+	"runtime.epollwait":                    true,
+	"runtime.kevent":                       true,
+	"runtime.semasleep":                    true,
+	"runtime.pthread_cond_wait_trampoline": true,
+}
+
+// threadStackDepth bounds the stack searched for the syscall and its caller
+const threadStackDepth = 30
+
+// threadHistoryFile holds the thread counts sampled by analyze threads
+const threadHistoryFile = "threads.json"
+
+// maxThreadSamples bounds the thread count history kept per session
+const maxThreadSamples = 50
+
+// threadSample is one thread count observation
+type threadSample struct {
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+}
+
+// blockedThread is a thread whose goroutine is stuck outside Go code
+type blockedThread struct {
+	ThreadID    int    `json:"threadId"`
+	GoroutineID int64  `json:"goroutineId,omitempty"`
+	State       string `json:"state"`
+	// Call is the syscall or cgo entry point the goroutine is blocked in
+	Call   string `json:"call,omitempty"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// isSyscallFunc reports whether fn enters the kernel or C code on behalf of Go code
+func isSyscallFunc(fn string) bool {
+	return fn == "runtime.cgocall" ||
+		strings.HasPrefix(fn, "syscall.") ||
+		strings.HasPrefix(fn, "internal/syscall/") ||
+		strings.HasPrefix(fn, "internal/runtime/syscall.") ||
+		strings.HasPrefix(fn, "golang.org/x/sys/")
+}
+
+// threadGrowth reports whether the sampled thread count is growing: it rose
+// over each of the last three samples, or by half and at least four threads
+// across the whole history
+func threadGrowth(history []threadSample) bool {
+	n := len(history)
+	if n < 3 {
+		return false
+	}
+	if history[n-3].Count < history[n-2].Count && history[n-2].Count < history[n-1].Count {
+		return true
+	}
+	first, last := history[0].Count, history[n-1].Count
+	return last-first >= 4 && last*2 >= first*3
+}
+
+// classifyThread determines what a thread is doing from its PC and the state
+// of the goroutine it runs
+func classifyThread(c *debugger.Client, t *api.Thread, g *api.Goroutine) blockedThread {
+	bt := blockedThread{ThreadID: t.ID, GoroutineID: t.GoroutineID, State: threadRunning}
+	switch {
+	case g != nil && g.Status == api.GoroutineSyscall:
+		bt.State = threadSyscall
+		frames, err := c.Stacktrace(g.ID, threadStackDepth, nil)
+		if err != nil {
+			return bt
+		}
+		for _, f := range frames {
+			if f.Function == nil {
+				continue
+			}
+			name := f.Function.Name()
+			if bt.Call == "" && isSyscallFunc(name) {
+				bt.Call = name
+				if name == "runtime.cgocall" {
+					bt.State = threadCgo
+				}
+			}
+			if bt.Origin == "" && isUserSource(f.File) {
+				bt.Origin = fmt.Sprintf("%s (%s:%d)", name, f.File, f.Line)
+			}
+		}
+	case t.Function == nil:
+		// No Go function at the PC: the thread is in C code
+		bt.State = threadCgo
+	case threadIdleFuncs[t.Function.Name()]:
+		bt.State = threadIdle
+	}
+	return bt
+}
+
+// recordThreadCount appends count to the session's thread history and returns it
+func recordThreadCount(addr string, count int) []threadSample {
+	var history []threadSample
+	_ = session.LoadData(addr, threadHistoryFile, &history)
+	history = append(history, threadSample{Time: time.Now(), Count: count})
+	if len(history) > maxThreadSamples {
+		history = history[len(history)-maxThreadSamples:]
+	}
+	_ = session.SaveData(addr, threadHistoryFile, history)
+	return history
+}
+
+// addAnalyzeCommand adds the analyze command and its analyses
+func addAnalyzeCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	analyzeCmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Run a diagnostic analysis of the stopped target",
+		Long: `Analyses that combine several views of the target into a diagnosis.
+
+Subcommands:
+  threads    OS threads blocked in syscalls/cgo and thread count growth
+  wait-for   wait-for graph between goroutines: deadlocks and wait chains`,
+	}
+
+	threadsCmd := &cobra.Command{
+		Use:   "threads",
+		Short: "Find threads blocked in syscalls or cgo and thread growth",
+		Long: `Classify every OS thread of the target as running Go code, blocked in a
+system call, in cgo, or idle, and group the blocked ones by the user code
+that made the call.
+
+A goroutine blocked in a syscall holds its OS thread, so the runtime starts
+another one to keep GOMAXPROCS threads running Go code. Slow syscalls or cgo
+calls made from many goroutines therefore grow the thread count without
+bound, which goroutine views do not show. Each run records the thread count
+in the session; run it at several stops to detect sustained growth.
+
+Example:
+  godebug --addr $ADDR analyze threads`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("analyze threads")
+			defer func() { _ = c.Close() }()
+
+			threads, err := c.ListThreads()
+			if err != nil {
+				output.Error("analyze threads", err).PrintAndExit(getOutputFormat())
+			}
+			goroutines, _, err := c.ListGoroutines(0, 0)
+			if err != nil {
+				output.Error("analyze threads", err).PrintAndExit(getOutputFormat())
+			}
+			byID := make(map[int64]*api.Goroutine, len(goroutines))
+			for _, g := range goroutines {
+				byID[g.ID] = g
+			}
+
+			byState := map[string]int{}
+			blocked := []blockedThread{}
+			origins := map[string]int{}
+			for _, t := range threads {
+				bt := classifyThread(c, t, byID[t.GoroutineID])
+				byState[bt.State]++
+				if bt.State == threadSyscall || bt.State == threadCgo {
+					blocked = append(blocked, bt)
+					if bt.Origin != "" {
+						origins[bt.Origin]++
+					}
+				}
+			}
+			sort.Slice(blocked, func(i, j int) bool { return blocked[i].ThreadID < blocked[j].ThreadID })
+
+			type originCount struct {
+				Origin  string `json:"origin"`
+				Threads int    `json:"threads"`
+			}
+			groups := []originCount{}
+			for origin, n := range origins {
+				groups = append(groups, originCount{origin, n})
+			}
+			sort.Slice(groups, func(i, j int) bool {
+				if groups[i].Threads != groups[j].Threads {
+					return groups[i].Threads > groups[j].Threads
+				}
+				return groups[i].Origin < groups[j].Origin
+			})
+
+			history := recordThreadCount(c.Addr(), len(threads))
+			growing := threadGrowth(history)
+
+			data := map[string]any{
+				"threads":    len(threads),
+				"goroutines": len(goroutines),
+				"byState":    byState,
+				"blocked":    blocked,
+				"origins":    groups,
+				"history":    history,
+				"growing":    growing,
+			}
+
+			var findings []string
+			if growing {
+				findings = append(findings, fmt.Sprintf("thread count is growing: %d -> %d over %d samples", history[0].Count, history[len(history)-1].Count, len(history)))
+			}
+			if len(groups) > 0 && groups[0].Threads > 1 {
+				findings = append(findings, fmt.Sprintf("%d threads are blocked in calls from %s", groups[0].Threads, groups[0].Origin))
+			}
+			if growing && len(blocked) > 0 {
+				findings = append(findings, "threads blocked in syscalls/cgo force the runtime to start new ones; bound the concurrency of these calls or make them non-blocking")
+			}
+			if len(findings) > 0 {
+				data["findings"] = findings
+			}
+
+			msg := fmt.Sprintf("%d threads, %d blocked in syscalls/cgo", len(threads), len(blocked))
+			if growing {
+				msg += "; thread count growing"
+			}
+			output.Success("analyze threads", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	analyzeCmd.AddCommand(threadsCmd)
+	addWaitForCommand(analyzeCmd, mustGetClient, getOutputFormat)
+	root.AddCommand(analyzeCmd)
+}
+
+func init() {
+	addAnalyzeCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
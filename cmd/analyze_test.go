@@ -0,0 +1,31 @@
+package cmd
+
+import "testing"
+
+// TestThreadGrowth checks the growth heuristic on short and long histories.
+func TestThreadGrowth(t *testing.T) {
+	samples := func(counts ...int) []threadSample {
+		out := make([]threadSample, len(counts))
+		for i, n := range counts {
+			out[i].Count = n
+		}
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		counts []int
+		want   bool
+	}{
+		{"too few samples", []int{5, 9}, false},
+		{"steady", []int{8, 8, 8, 8}, false},
+		{"rising over last three", []int{8, 8, 9, 10}, true},
+		{"grew by half overall", []int{8, 14, 13, 13}, true},
+		{"small absolute growth", []int{2, 4, 3, 3}, false},
+	}
+	for _, tt := range tests {
+		if got := threadGrowth(samples(tt.counts...)); got != tt.want {
+			t.Errorf("%s: threadGrowth(%v) = %v, want %v", tt.name, tt.counts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// annotateValueLen caps the length of a single rendered value in annotations
+const annotateValueLen = 80
+
+// predeclared identifiers that never need evaluation
+var predeclared = map[string]bool{
+	"true": true, "false": true, "nil": true, "iota": true, "_": true,
+	"bool": true, "byte": true, "rune": true, "string": true, "error": true, "any": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"len": true, "cap": true, "make": true, "new": true, "append": true, "copy": true,
+	"delete": true, "panic": true, "recover": true, "print": true, "println": true,
+	"close": true, "min": true, "max": true, "clear": true,
+}
+
+// lineExpressions extracts the variable references (identifiers and selector
+// chains such as user.Name) from a single line of Go source. Function and method
+// names, field keys in composite literals and predeclared identifiers are skipped.
+func lineExpressions(line string) []string {
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	src := []byte(line)
+	file := fset.AddFile("", fset.Base(), len(src))
+	s.Init(file, src, func(token.Position, string) {}, 0)
+
+	type tok struct {
+		tok token.Token
+		lit string
+	}
+	var toks []tok
+	for {
+		_, t, lit := s.Scan()
+		if t == token.EOF {
+			break
+		}
+		toks = append(toks, tok{t, lit})
+	}
+
+	seen := map[string]bool{}
+	var exprs []string
+	for i := 0; i < len(toks); i++ {
+		if toks[i].tok != token.IDENT || (i > 0 && toks[i-1].tok == token.PERIOD) {
+			continue
+		}
+		// Skip declarations of functions and types
+		if i > 0 && (toks[i-1].tok == token.FUNC || toks[i-1].tok == token.TYPE) {
+			continue
+		}
+		chain := toks[i].lit
+		j := i + 1
+		for j+1 < len(toks) && toks[j].tok == token.PERIOD && toks[j+1].tok == token.IDENT {
+			chain += "." + toks[j+1].lit
+			j += 2
+		}
+		next := token.ILLEGAL
+		if j < len(toks) {
+			next = toks[j].tok
+		}
+		i = j - 1
+
+		if next == token.LPAREN || next == token.COLON || predeclared[chain] || seen[chain] {
+			continue
+		}
+		seen[chain] = true
+		exprs = append(exprs, chain)
+	}
+	return exprs
+}
+
+// compactValue renders a variable on a single line, truncated for inline display
+func compactValue(v *api.Variable) string {
+	s := v.SinglelineString()
+	if len(s) > annotateValueLen {
+		// Cut on a rune boundary so the value stays valid UTF-8
+		cut := annotateValueLen
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut] + "..."
+	}
+	return s
+}
+
+// annotateLoadConfig loads just enough of each value for an inline comment
+func annotateLoadConfig() api.LoadConfig {
+	return api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 1,
+		MaxStringLen:       64,
+		MaxArrayValues:     8,
+		MaxStructFields:    8,
+	}
+}
+
+// addAnnotateCommand adds the annotate command
+func addAnnotateCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var annotateContext int
+	var annotateMaxEvals int
+
+	annotateCmd := &cobra.Command{
+		Use:   "annotate [file]",
+		Short: "Show source annotated with current runtime values",
+		Long: `Show source code with the current value of every variable referenced
+on each line, resolved in the current stop context.
+
+Without a file argument the file of the current location is used. Lines
+around the current line are annotated; after up, down or frame, values are
+resolved in the selected frame and its line is the current one; use --context to widen the window
+and --max-evals to bound the number of evaluations.
+
+Example:
+  godebug --addr $ADDR annotate
+  godebug --addr $ADDR annotate --context 20
+  godebug --addr $ADDR annotate /path/to/handler.go --context 0`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("annotate")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("annotate", err).PrintAndExit(getOutputFormat())
+			}
+
+			if state.SelectedGoroutine == nil {
+				output.ErrorWithInfo("annotate", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+			}
+
+			// The frame selected with up, down or frame is annotated around its line
+			goroutineID := state.SelectedGoroutine.ID
+			loc := state.SelectedGoroutine.CurrentLoc
+			frame := mustSelectedFrame(c, "annotate", state, goroutineID, getOutputFormat)
+			if frame > 0 {
+				frames, err := c.Stacktrace(goroutineID, frame, nil)
+				if err != nil {
+					output.Error("annotate", err).PrintAndExit(getOutputFormat())
+				}
+				if frame >= len(frames) {
+					output.ErrorWithInfo("annotate", output.NotFound("frame", strconv.Itoa(frame))).PrintAndExit(getOutputFormat())
+				}
+				loc = frames[frame].Location
+			}
+			// path is the file as compiled, local the file read from the checkout
+			path := loc.File
+			if len(args) > 0 {
+				path, _ = resolveSourceFile(c, args[0])
+			}
+			if path == "" {
+				output.ErrorWithInfo("annotate", output.NotFound("source location", "none available")).PrintAndExit(getOutputFormat())
+			}
+
+			local := localSourceFile(c, path)
+			file, err := os.Open(local)
+			if err != nil {
+				output.ErrorWithInfo("annotate", output.NotFound("source file", path)).PrintAndExit(getOutputFormat())
+			}
+			defer func() { _ = file.Close() }()
+
+			// Annotate the whole file for --context 0 or a file we are not stopped in
+			startLine, endLine := 1, math.MaxInt
+			if path == loc.File && annotateContext > 0 {
+				startLine = max(loc.Line-annotateContext, 1)
+				endLine = loc.Line + annotateContext
+			}
+
+			cfg := annotateLoadConfig()
+			cache := map[string]string{}
+			evals := 0
+			resolve := func(expr string) (string, bool) {
+				if v, ok := cache[expr]; ok {
+					return v, v != ""
+				}
+				if evals >= annotateMaxEvals {
+					return "", false
+				}
+				evals++
+				v, err := c.Eval(goroutineID, frame, expr, cfg)
+				if err != nil || v == nil || v.Unreadable != "" {
+					cache[expr] = ""
+					return "", false
+				}
+				cache[expr] = compactValue(v)
+				return cache[expr], true
+			}
+
+			scanner := bufio.NewScanner(file)
+			lineNum := 0
+			annotated := 0
+			var lines []map[string]any
+			var text strings.Builder
+
+			for scanner.Scan() {
+				lineNum++
+				if lineNum < startLine {
+					continue
+				}
+				if lineNum > endLine {
+					break
+				}
+
+				content := scanner.Text()
+				values := map[string]string{}
+				var parts []string
+				for _, expr := range lineExpressions(content) {
+					if v, ok := resolve(expr); ok {
+						values[expr] = v
+						parts = append(parts, expr+"="+v)
+					}
+				}
+
+				lineData := map[string]any{
+					"lineNumber": lineNum,
+					"content":    content,
+					"current":    path == loc.File && lineNum == loc.Line,
+				}
+				text.WriteString(content)
+				if len(parts) > 0 {
+					annotated++
+					lineData["values"] = values
+					text.WriteString(" // " + strings.Join(parts, ", "))
+				}
+				text.WriteString("\n")
+				lines = append(lines, lineData)
+			}
+
+			if err := scanner.Err(); err != nil {
+				output.Error("annotate", err).PrintAndExit(getOutputFormat())
+			}
+
+			data := map[string]any{
+				"file":      path,
+				"lines":     lines,
+				"annotated": text.String(),
+				"evals":     evals,
+			}
+			if path == loc.File {
+				data["currentLine"] = loc.Line
+			}
+			if frame > 0 {
+				data["frame"] = frame
+			}
+
+			output.Success("annotate", data, fmt.Sprintf("%s: %d lines annotated", path, annotated)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	annotateCmd.Flags().IntVar(&annotateContext, "context", 10, "Lines before and after the current line (0 = whole file)")
+	annotateCmd.Flags().IntVar(&annotateMaxEvals, "max-evals", 50, "Maximum number of expressions to evaluate")
+	root.AddCommand(annotateCmd)
+}
+
+func init() {
+	addAnnotateCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
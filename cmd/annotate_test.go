@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestLineExpressions checks that variables and selector chains are
+// extracted once each, without calls, field keys or predeclared identifiers.
+func TestLineExpressions(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"total += item.Price * qty", []string{"total", "item.Price", "qty"}},
+		{"if err := s.db.Save(u); err != nil {", []string{"err", "u"}},
+		{"p := Point{X: x, Y: len(ys)}", []string{"p", "Point", "x", "ys"}},
+		{"func (s *Server) Handle(w io.Writer) {", []string{"s", "Server", "w", "io.Writer"}},
+		{"return true", nil},
+	}
+	for _, tt := range tests {
+		if got := lineExpressions(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lineExpressions(%q) = %q; want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+// TestCompactValue checks that long values are truncated on a rune boundary.
+func TestCompactValue(t *testing.T) {
+	short := &api.Variable{Kind: reflect.Int, Value: "42"}
+	if got := compactValue(short); got != "42" {
+		t.Errorf("compactValue(42) = %q", got)
+	}
+
+	long := &api.Variable{Kind: reflect.String, Value: strings.Repeat("é", 60), Len: 60}
+	got := compactValue(long)
+	if !utf8.ValidString(got) {
+		t.Errorf("compactValue cut a rune: %q", got)
+	}
+	if !strings.HasSuffix(got, "...") || len(got) > annotateValueLen+len("...") {
+		t.Errorf("compactValue = %q (%d bytes); want at most %d bytes and ...", got, len(got), annotateValueLen)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+	"slices"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// archFile caches the target architecture of a session, which can only be
+// detected while the process is stopped
+const archFile = "arch.json"
+
+// supportedArches are the architectures Delve debugs
+var supportedArches = []string{"amd64", "386", "arm64", "riscv64", "ppc64le", "loong64"}
+
+// sessionArch returns the architecture of the session's target, detecting it
+// the first time. It returns nil if it is not known and cannot be detected.
+func sessionArch(c *debugger.Client) *debugger.Arch {
+	var arch debugger.Arch
+	if err := session.LoadData(c.Addr(), archFile, &arch); err == nil && arch.Name != "" {
+		return &arch
+	}
+	arch, err := c.TargetArch()
+	if err != nil {
+		return nil
+	}
+	_ = session.SaveData(c.Addr(), archFile, arch)
+	return &arch
+}
+
+// cachedArch returns the session's architecture if it was detected before,
+// without asking the server
+func cachedArch(addr string) *debugger.Arch {
+	var arch debugger.Arch
+	if err := session.LoadData(addr, archFile, &arch); err != nil || arch.Name == "" {
+		return nil
+	}
+	return &arch
+}
+
+// archData reports the target architecture next to this host's
+func archData(arch debugger.Arch) map[string]any {
+	byteOrder := "little"
+	if !arch.LittleEndian {
+		byteOrder = "big"
+	}
+	data := map[string]any{
+		"name":      arch.Name,
+		"ptrSize":   arch.PtrSize,
+		"byteOrder": byteOrder,
+		"host":      runtime.GOARCH,
+		"cross":     arch.Name != runtime.GOARCH,
+	}
+	if arch.Name != runtime.GOARCH {
+		data["note"] = fmt.Sprintf("the target runs on %s, this host is %s: memory is decoded in the target's byte order, and commands that read the binary (buildinfo) need a local copy of the %s build passed as an argument", arch.Name, runtime.GOARCH, arch.Name)
+	}
+	return data
+}
+
+// checkArch verifies the target runs on the architecture connect --arch
+// expects
+func checkArch(want string, arch *debugger.Arch) *output.ErrorInfo {
+	if !slices.Contains(supportedArches, want) {
+		return output.InvalidArgumentWithDetails(
+			fmt.Sprintf("unknown architecture %q", want),
+			map[string]any{"arch": want, "supported": supportedArches},
+		)
+	}
+	if arch == nil {
+		return output.InvalidArgumentWithDetails(
+			fmt.Sprintf("cannot verify the target runs on %s: its architecture can only be detected while it is stopped", want),
+			map[string]any{"expected": want, "hint": "halt the target, or connect without --arch"},
+		)
+	}
+	if arch.Name != want {
+		return output.InvalidArgumentWithDetails(
+			fmt.Sprintf("the target runs on %s, not %s", arch.Name, want),
+			map[string]any{"expected": want, "actual": arch.Name, "host": runtime.GOARCH},
+		)
+	}
+	return nil
+}
+
+// serverIsLocal reports whether the server at addr runs on this host, so the
+// PIDs it reports are this host's. A fake target's process is made up.
+func serverIsLocal(addr string) bool {
+	if debugger.IsFakeAddr(addr) {
+		return false
+	}
+	if arch := cachedArch(addr); arch != nil && arch.Name != runtime.GOARCH {
+		return false
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "" || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ips, err := net.LookupIP(host)
+		if err != nil || len(ips) == 0 {
+			return false
+		}
+		ip = ips[0]
+	}
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && n.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// defaultArtifactBytes bounds the content artifacts get returns inline
+const defaultArtifactBytes = 64 * 1024
+
+// artifactData is the listing of an artifact
+func artifactData(a session.Artifact) map[string]any {
+	data := map[string]any{
+		"name":    a.Name,
+		"kind":    a.Kind,
+		"path":    a.Path,
+		"size":    a.Size,
+		"modTime": a.ModTime.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if a.Recorded {
+		data["recorded"] = true
+	}
+	return data
+}
+
+// addArtifactsCommand adds the artifacts command and its subcommands
+func addArtifactsCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat) {
+	var artifactsSession string
+	var getMaxBytes int
+
+	// sessionAddr returns the session the command is about: --session, else --addr
+	sessionAddr := func(cmd *cobra.Command, name string) string {
+		addr := artifactsSession
+		if addr == "" {
+			addr = cmd.Flag("addr").Value.String()
+		}
+		if addr == "" {
+			output.ErrorWithInfo(name, output.InvalidArgument("--addr or --session is required")).PrintAndExit(getOutputFormat())
+		}
+		return addr
+	}
+
+	artifactsCmd := &cobra.Command{
+		Use:   "artifacts",
+		Short: "List, read and clean the files a session produced",
+		Long: `Files godebug writes for a session live in its artifacts directory, one
+subdirectory per capture (e.g. crash-20261017-091403 from start --on-crash
+capture). The program's output files and Delve's log, recorded by start, are
+listed with them as recorded artifacts.
+
+  artifacts list          - every artifact with its kind, path and size
+  artifacts get <name>    - an artifact's path, and its content if it is
+                            text of at most --max-bytes
+  artifacts clean [name]  - delete the artifacts, or those at or below name;
+                            recorded artifacts are kept
+
+The session is --addr or --session (a session ID or address). Only the
+session directory is read, so artifacts are available after the server died.
+
+Example:
+  godebug --addr $ADDR artifacts list
+  godebug --addr $ADDR artifacts get crash-20261017-091403/output.log
+  godebug artifacts clean --session 127.0.0.1_40213`,
+	}
+	artifactsCmd.PersistentFlags().StringVar(&artifactsSession, "session", "", "Session ID or address (default: --addr)")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the session's artifacts",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			addr := sessionAddr(cmd, "artifacts")
+			artifacts, err := session.Artifacts(addr)
+			if err != nil {
+				output.Error("artifacts", err).PrintAndExit(getOutputFormat())
+			}
+			listed := make([]map[string]any, 0, len(artifacts))
+			var size int64
+			for _, a := range artifacts {
+				listed = append(listed, artifactData(a))
+				size += a.Size
+			}
+			data := map[string]any{
+				"session":   session.ID(addr),
+				"artifacts": listed,
+				"count":     len(listed),
+				"size":      size,
+			}
+			output.Success("artifacts", data, fmt.Sprintf("%d artifacts, %d bytes", len(listed), size)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "Show an artifact's path and content",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			addr := sessionAddr(cmd, "artifacts")
+			if getMaxBytes < 0 {
+				output.ErrorWithInfo("artifacts", output.InvalidArgument("--max-bytes must not be negative")).PrintAndExit(getOutputFormat())
+			}
+			artifacts, err := session.Artifacts(addr)
+			if err != nil {
+				output.Error("artifacts", err).PrintAndExit(getOutputFormat())
+			}
+			var found *session.Artifact
+			for i := range artifacts {
+				if artifacts[i].Name == args[0] {
+					found = &artifacts[i]
+				}
+			}
+			if found == nil {
+				output.ErrorWithInfo("artifacts", output.NotFound("artifact", args[0]).WithDetails(map[string]any{
+					"hint": "artifacts list shows the names",
+				})).PrintAndExit(getOutputFormat())
+			}
+
+			data := artifactData(*found)
+			switch {
+			case found.Size > int64(getMaxBytes):
+				data["contentOmitted"] = fmt.Sprintf("larger than --max-bytes %d; read it from path", getMaxBytes)
+			default:
+				content, err := os.ReadFile(found.Path)
+				if err != nil {
+					output.Error("artifacts", err).PrintAndExit(getOutputFormat())
+				}
+				if utf8.Valid(content) {
+					data["content"] = string(content)
+				} else {
+					data["contentOmitted"] = "binary; read it from path"
+				}
+			}
+			output.Success("artifacts", data, fmt.Sprintf("%s (%s, %d bytes)", found.Name, found.Kind, found.Size)).PrintAndExit(getOutputFormat())
+		},
+	}
+	getCmd.Flags().IntVar(&getMaxBytes, "max-bytes", defaultArtifactBytes, "Return the content of artifacts up to this size")
+
+	cleanCmd := &cobra.Command{
+		Use:   "clean [name]...",
+		Short: "Delete the session's artifacts",
+		Run: func(cmd *cobra.Command, args []string) {
+			addr := sessionAddr(cmd, "artifacts")
+			if len(args) == 0 {
+				args = []string{""}
+			}
+			removed := []map[string]any{}
+			var freed int64
+			for _, name := range args {
+				gone, err := session.RemoveArtifacts(addr, name)
+				for _, a := range gone {
+					removed = append(removed, artifactData(a))
+					freed += a.Size
+				}
+				if err != nil {
+					output.Error("artifacts", err).PrintAndExit(getOutputFormat())
+				}
+				if name != "" && len(gone) == 0 {
+					output.ErrorWithInfo("artifacts", output.NotFound("artifact", name).WithDetails(map[string]any{
+						"removed": removed,
+						"hint":    "artifacts list shows the names; recorded artifacts are not cleaned",
+					})).PrintAndExit(getOutputFormat())
+				}
+			}
+			data := map[string]any{
+				"session": session.ID(addr),
+				"removed": removed,
+				"count":   len(removed),
+				"freed":   freed,
+			}
+			output.Success("artifacts", data, fmt.Sprintf("Removed %d artifacts, %d bytes", len(removed), freed)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	artifactsCmd.AddCommand(listCmd, getCmd, cleanCmd)
+	root.AddCommand(artifactsCmd)
+}
+
+func init() {
+	addArtifactsCommand(rootCmd, GetOutputFormat)
+}
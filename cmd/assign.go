@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+)
+
+// assignSite is a source location that writes a variable
+type assignSite struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Code string `json:"code"`
+}
+
+// atomicWriters are the sync/atomic function prefixes that write through their first argument
+var atomicWriters = []string{"Add", "And", "Or", "Store", "Swap", "CompareAndSwap"}
+
+// assignTargetMatches reports whether the written expression e is name. A bare
+// name also matches field selectors (s.counter), so struct fields are found
+// through any receiver; element writes (counter[i]) count as writes to counter.
+func assignTargetMatches(e ast.Expr, name string) bool {
+	for {
+		switch x := e.(type) {
+		case *ast.ParenExpr:
+			e = x.X
+			continue
+		case *ast.IndexExpr:
+			e = x.X
+			continue
+		case *ast.StarExpr:
+			e = x.X
+			continue
+		}
+		break
+	}
+	s := types.ExprString(e)
+	return s == name || strings.HasSuffix(s, "."+name)
+}
+
+// isAtomicWrite reports whether call is a sync/atomic write to &name
+func isAtomicWrite(call *ast.CallExpr, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) == 0 {
+		return false
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "atomic" {
+		return false
+	}
+	writer := false
+	for _, prefix := range atomicWriters {
+		if strings.HasPrefix(sel.Sel.Name, prefix) {
+			writer = true
+			break
+		}
+	}
+	addr, ok := call.Args[0].(*ast.UnaryExpr)
+	return writer && ok && addr.Op == token.AND && assignTargetMatches(addr.X, name)
+}
+
+// findAssignSites parses files and returns every statement that writes name:
+// assignments (not := declarations), ++/-- and sync/atomic writes. Matching is
+// by name only, so locals that shadow the variable are reported too.
+func findAssignSites(files []string, name string) []assignSite {
+	var sites []assignSite
+	seen := map[string]bool{}
+	for _, path := range files {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			continue
+		}
+		add := func(n ast.Node) {
+			pos := fset.Position(n.Pos())
+			key := fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+			if seen[key] {
+				return
+			}
+			seen[key] = true
+			var code string
+			switch x := n.(type) {
+			case ast.Stmt:
+				code = stmtString(x)
+			case ast.Expr:
+				code = types.ExprString(x)
+			}
+			sites = append(sites, assignSite{File: pos.Filename, Line: pos.Line, Code: code})
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.AssignStmt:
+				if x.Tok == token.DEFINE {
+					return true
+				}
+				for _, lhs := range x.Lhs {
+					if assignTargetMatches(lhs, name) {
+						add(x)
+						break
+					}
+				}
+			case *ast.IncDecStmt:
+				if assignTargetMatches(x.X, name) {
+					add(x)
+				}
+			case *ast.CallExpr:
+				if isAtomicWrite(x, name) {
+					add(x)
+				}
+			}
+			return true
+		})
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].File != sites[j].File {
+			return sites[i].File < sites[j].File
+		}
+		return sites[i].Line < sites[j].Line
+	})
+	return sites
+}
+
+// stmtString renders the statements findAssignSites reports on one line
+func stmtString(stmt ast.Stmt) string {
+	switch x := stmt.(type) {
+	case *ast.AssignStmt:
+		lhs := make([]string, len(x.Lhs))
+		for i, e := range x.Lhs {
+			lhs[i] = types.ExprString(e)
+		}
+		rhs := make([]string, len(x.Rhs))
+		for i, e := range x.Rhs {
+			rhs[i] = types.ExprString(e)
+		}
+		return strings.Join(lhs, ", ") + " " + x.Tok.String() + " " + strings.Join(rhs, ", ")
+	case *ast.IncDecStmt:
+		return types.ExprString(x.X) + x.Tok.String()
+	}
+	return ""
+}
+
+// breakOnAssignments sets a breakpoint at every site in the target's sources that
+// writes name, copying cond and collect to each
+func breakOnAssignments(c *debugger.Client, name, cond, collect string) (map[string]any, error) {
+	sources, err := c.ListSources("")
+	if err != nil {
+		return nil, err
+	}
+	sites := findAssignSites(userSources(sources), name)
+
+	var results itemResults
+	for _, site := range sites {
+		bp := &api.Breakpoint{File: site.File, Line: site.Line, Cond: cond}
+		if collect != "" {
+			bp.Variables = []string{collect}
+		}
+		created, err := c.CreateBreakpoint(bp)
+		if err != nil {
+			results.fail(map[string]any{
+				"file": site.File,
+				"line": site.Line,
+				"code": site.Code,
+			}, err)
+			continue
+		}
+		results.ok(map[string]any{
+			"id":       created.ID,
+			"file":     created.File,
+			"line":     created.Line,
+			"function": created.FunctionName,
+			"code":     site.Code,
+		})
+	}
+
+	data := map[string]any{
+		"assign": name,
+		"count":  len(results.succeeded),
+	}
+	results.apply(data, "breakpoints")
+	if cond != "" {
+		data["condition"] = cond
+	}
+	if info := results.allFailed("breakpoints", data); info != nil {
+		return nil, info
+	}
+	return data, nil
+}
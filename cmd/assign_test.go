@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const assignSource = `package main
+
+import "sync/atomic"
+
+var counter int64
+
+type stats struct{ counter int }
+
+func main() {
+	counter = 1
+	counter++
+	s := stats{}
+	s.counter += 2
+	counter := 5
+	_ = counter
+	atomic.AddInt64(&counter, 1)
+	println(counter)
+}
+`
+
+// TestFindAssignSites checks which statements count as writes to a variable.
+func TestFindAssignSites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte(assignSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sites := findAssignSites([]string{path}, "counter")
+	var lines []int
+	for _, s := range sites {
+		lines = append(lines, s.Line)
+	}
+	// := declarations and reads are not writes; field and atomic writes are
+	want := []int{10, 11, 13, 16}
+	if len(lines) != len(want) {
+		t.Fatalf("sites at lines %v; want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("sites at lines %v; want %v", lines, want)
+		}
+	}
+}
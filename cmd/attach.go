@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// launchMode maps a --mode value onto the launcher's modes
+func launchMode(mode string) (debugger.LaunchMode, bool) {
+	switch mode {
+	case "debug":
+		return debugger.ModeDebug, true
+	case "test":
+		return debugger.ModeTest, true
+	case "exec":
+		return debugger.ModeExec, true
+	case "attach":
+		return debugger.ModeAttach, true
+	}
+	return "", false
+}
+
+// validateStart rejects an unknown mode and what attach mode cannot do: a
+// target that is not a PID, program arguments and crash capture (the
+// process's output is not ours to redirect)
+func validateStart(mode, target string, programArgs []string, onCrash string, getOutputFormat func() output.OutputFormat) debugger.LaunchMode {
+	m, ok := launchMode(mode)
+	if !ok {
+		output.ErrorWithInfo("start", output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid mode: %s (expected debug, test, exec or attach)", mode),
+			map[string]any{"mode": mode},
+		)).PrintAndExit(getOutputFormat())
+	}
+	if m != debugger.ModeAttach {
+		return m
+	}
+	if pid, err := strconv.Atoi(target); err != nil || pid <= 0 {
+		output.ErrorWithInfo("start", output.InvalidArgumentWithDetails(
+			fmt.Sprintf("attach mode needs a process ID, got %q", target),
+			map[string]any{"target": target},
+		)).PrintAndExit(getOutputFormat())
+	}
+	if len(programArgs) > 0 {
+		output.ErrorWithInfo("start", output.InvalidArgument("attach mode takes no program arguments")).PrintAndExit(getOutputFormat())
+	}
+	if onCrash != "" {
+		output.ErrorWithInfo("start", output.InvalidArgument("--on-crash cannot be combined with attach mode")).PrintAndExit(getOutputFormat())
+	}
+	return m
+}
+
+// attachedPID returns the process ID of an attach session, or 0 if the
+// session launched its process
+func attachedPID(addr string) int {
+	s, err := session.Load(addr)
+	if err != nil || s.Mode != string(debugger.ModeAttach) {
+		return 0
+	}
+	pid, _ := strconv.Atoi(s.Target)
+	return pid
+}
+
+// endSession shuts down the Delve server. A launched process is killed, an
+// attached one is detached from and keeps running.
+func endSession(c *debugger.Client) (map[string]any, string, error) {
+	pid := attachedPID(c.Addr())
+	if pid == 0 {
+		return nil, "Debug session terminated", c.Detach(true)
+	}
+	if err := c.Detach(false); err != nil {
+		return nil, "", err
+	}
+	data := map[string]any{"detached": true, "pid": pid}
+	return data, fmt.Sprintf("Detached from process %d; it keeps running", pid), nil
+}
+
+// validateNoHalt rejects --no-halt outside attach mode, where a launched
+// process is stopped before its first instruction anyway
+func validateNoHalt(noHalt bool, mode debugger.LaunchMode, getOutputFormat func() output.OutputFormat) {
+	if noHalt && mode != debugger.ModeAttach {
+		output.ErrorWithInfo("start", output.InvalidArgumentWithDetails(
+			"--no-halt only applies to attach mode",
+			map[string]any{"mode": string(mode)},
+		)).PrintAndExit(getOutputFormat())
+	}
+}
+
+// attachHalted reports whether attaching left the process stopped, as the new
+// server sees it, falling back to what was asked for
+func attachHalted(addr string, noHalt bool) bool {
+	c, err := debugger.Connect(addr)
+	if err != nil {
+		return !noHalt
+	}
+	defer func() { _ = c.Close() }()
+	state, err := c.GetState()
+	if err != nil {
+		return !noHalt
+	}
+	return !state.Running && !state.Exited
+}
+
+// attachMessage describes the outcome of start in attach mode
+func attachMessage(target string, halted bool) string {
+	if halted {
+		return fmt.Sprintf("Attached to process %s; it is stopped", target)
+	}
+	return fmt.Sprintf("Attached to process %s; it keeps running", target)
+}
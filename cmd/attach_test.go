@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// TestAttachedPID checks that only attach sessions report the process to
+// leave running on quit.
+func TestAttachedPID(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	attach := session.New("127.0.0.1:1")
+	attach.Mode, attach.Target = "attach", "4242"
+	launched := session.New("127.0.0.1:2")
+	launched.Mode, launched.Target = "exec", "./app"
+	for _, s := range []*session.Session{attach, launched} {
+		if err := s.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if pid := attachedPID("127.0.0.1:1"); pid != 4242 {
+		t.Errorf("attach session: pid %d, want 4242", pid)
+	}
+	if pid := attachedPID("127.0.0.1:2"); pid != 0 {
+		t.Errorf("exec session: pid %d, want 0", pid)
+	}
+	if pid := attachedPID("127.0.0.1:3"); pid != 0 {
+		t.Errorf("no session: pid %d, want 0", pid)
+	}
+	if _, ok := launchMode("attach"); !ok {
+		t.Error("attach is not a launch mode")
+	}
+}
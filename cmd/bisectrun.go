@@ -0,0 +1,452 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// bisectOutPlaceholder is replaced with the binary path in the build command
+const bisectOutPlaceholder = "{out}"
+
+// errBisectBuild marks a commit whose build command failed
+var errBisectBuild = output.NewErrorInfo(output.ErrCodeInternalError, "build failed")
+
+// bisectStep is one scripted debugger action. Cmd is one of break, continue,
+// next, step, stepout, eval, locals or args; Arg is the location for break and
+// the expression for eval.
+type bisectStep struct {
+	Cmd  string `json:"cmd"`
+	Arg  string `json:"arg,omitempty"`
+	Cond string `json:"cond,omitempty"`
+}
+
+// bisectScript is the --script file: program arguments and the steps to replay
+type bisectScript struct {
+	Args  []string     `json:"args,omitempty"`
+	Steps []bisectStep `json:"steps"`
+}
+
+// loadBisectScript reads and validates a --script file
+func loadBisectScript(path string) (*bisectScript, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var script bisectScript
+	if err := json.Unmarshal(raw, &script); err != nil {
+		return nil, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid script %s: %v", path, err),
+			map[string]any{"script": path},
+		)
+	}
+	if len(script.Steps) == 0 {
+		return nil, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("script %s has no steps", path),
+			map[string]any{"script": path},
+		)
+	}
+	for i, step := range script.Steps {
+		switch step.Cmd {
+		case "continue", "next", "step", "stepout", "locals", "args":
+		case "break", "eval":
+			if step.Arg == "" {
+				return nil, output.InvalidArgumentWithDetails(
+					fmt.Sprintf("step %d: %s requires arg", i+1, step.Cmd),
+					map[string]any{"script": path, "step": i + 1},
+				)
+			}
+		default:
+			return nil, output.InvalidArgumentWithDetails(
+				fmt.Sprintf("step %d: unsupported cmd %q", i+1, step.Cmd),
+				map[string]any{"script": path, "step": i + 1},
+			)
+		}
+	}
+	return &script, nil
+}
+
+// bisectVariant is the outcome of replaying the script against one commit
+type bisectVariant struct {
+	Commit       string
+	Observations map[string]string
+	BuildOutput  []string
+}
+
+// gitOutput runs git in dir and returns its trimmed output
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...) //nolint:gosec // the command is fixed, args are built by the callers
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// runBisectVariant checks commit out into a temporary worktree, builds it with
+// buildCmd and replays the script against it. A build failure is reported as
+// errBisectBuild so callers can skip the commit.
+func runBisectVariant(repo, commit, buildCmd string, script *bisectScript, timeout time.Duration) (*bisectVariant, error) {
+	dir, err := os.MkdirTemp("", "godebug-bisect-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if _, err := gitOutput(repo, "worktree", "add", "--detach", dir, commit); err != nil {
+		return nil, err
+	}
+	defer func() { _, _ = gitOutput(repo, "worktree", "remove", "--force", dir) }()
+
+	variant := &bisectVariant{Commit: commit}
+	binary := filepath.Join(dir, ".godebug-bisect-bin")
+	build := exec.Command("sh", "-c", strings.ReplaceAll(buildCmd, bisectOutPlaceholder, binary)) //nolint:gosec // --build is the user's own build command, run like a Makefile recipe
+	build.Dir = dir
+	out, err := build.CombinedOutput()
+	if err != nil {
+		variant.BuildOutput = lastLines(string(out), 20)
+		return variant, errBisectBuild
+	}
+
+	result, err := debugger.Launch(debugger.LaunchConfig{
+		Mode:    debugger.ModeExec,
+		Target:  binary,
+		Args:    script.Args,
+		Timeout: timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = result.Kill() }()
+
+	c, err := debugger.Connect(result.Addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = c.Detach(true)
+		_ = c.Close()
+	}()
+	c.SetTimeout(timeout)
+
+	variant.Observations = replayBisectScript(c, dir, script)
+	return variant, nil
+}
+
+// replayBisectScript runs the steps and records what each one observed under
+// "<step>:<what>" keys. Errors are recorded as observations too, since a
+// breakpoint that no longer resolves is a behavioral difference as well.
+func replayBisectScript(c *debugger.Client, dir string, script *bisectScript) map[string]string {
+	obs := map[string]string{}
+	var state *api.DebuggerState
+	for i, step := range script.Steps {
+		key := strconv.Itoa(i+1) + ":" + step.Cmd
+		var err error
+		switch step.Cmd {
+		case "break":
+			bp := &api.Breakpoint{FunctionName: step.Arg, Cond: step.Cond}
+			if file, line, ok := strings.Cut(step.Arg, ":"); ok {
+				bp.FunctionName = ""
+				bp.File = filepath.Join(dir, file)
+				bp.Line, err = strconv.Atoi(line)
+			}
+			if err == nil {
+				_, err = c.CreateBreakpoint(bp)
+			}
+			if err == nil {
+				obs[key] = "ok"
+			}
+		case "continue", "next", "step", "stepout":
+			switch step.Cmd {
+			case "continue":
+				state, err = c.Continue()
+			case "next":
+				state, err = c.Next()
+			case "step":
+				state, err = c.Step()
+			default:
+				state, err = c.StepOut()
+			}
+			if err == nil {
+				obs[key] = bisectLocation(dir, state)
+			}
+		case "eval", "locals", "args":
+			observeBisectStep(c, state, step, key, obs)
+		}
+		if err != nil {
+			obs[key] = "error: " + err.Error()
+		}
+	}
+	return obs
+}
+
+// observeBisectStep records what an eval, locals or args step sees at the
+// current stop under key
+func observeBisectStep(c *debugger.Client, state *api.DebuggerState, step bisectStep, key string, obs map[string]string) {
+	if state == nil || state.Exited || state.SelectedGoroutine == nil {
+		obs[key] = "not stopped"
+		return
+	}
+	scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID}
+	var vars []api.Variable
+	var err error
+	switch step.Cmd {
+	case "eval":
+		var v *api.Variable
+		if v, err = c.EvalInScope(scope, step.Arg, debugger.DefaultLoadConfig()); err == nil {
+			v.Name = step.Arg
+			vars = []api.Variable{*v}
+		}
+	case "locals":
+		vars, err = c.ListLocalVarsInScope(scope, debugger.DefaultLoadConfig())
+	default:
+		vars, err = c.ListFunctionArgsInScope(scope, debugger.DefaultLoadConfig())
+	}
+	if err != nil {
+		obs[key] = "error: " + err.Error()
+		return
+	}
+	for _, v := range vars {
+		flattenVariable(key+":"+v.Name, v, obs)
+	}
+}
+
+// bisectLocation renders where execution stopped relative to the worktree so
+// that the same line compares equal across variants
+func bisectLocation(dir string, state *api.DebuggerState) string {
+	if state.Exited {
+		return fmt.Sprintf("exited %d", state.ExitStatus)
+	}
+	if state.SelectedGoroutine == nil {
+		return "stopped"
+	}
+	loc := state.SelectedGoroutine.CurrentLoc
+	file := loc.File
+	if rel, err := filepath.Rel(dir, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+	return fmt.Sprintf("%s:%d %s", file, loc.Line, loc.Function.Name())
+}
+
+// lastLines returns the last n lines of s
+func lastLines(s string, n int) []string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	return lines[max(0, len(lines)-n):]
+}
+
+// sameObservations reports whether two variants observed the same values
+func sameObservations(a, b map[string]string) bool {
+	return len(diffValues(a, b)) == 0
+}
+
+// Results of testing a commit in bisect-run
+const (
+	bisectResultGood      = "good"
+	bisectResultDivergent = "divergent"
+	bisectResultSkipped   = "skipped"
+)
+
+// bisector tests commits against the observations of the good commit and
+// records the result of each
+type bisector struct {
+	run    func(commit string) (*bisectVariant, error)
+	good   *bisectVariant
+	tested []map[string]any
+}
+
+// test replays the script against commit and classifies it as good (or the
+// first commit tested), divergent, or skipped when it does not build
+func (b *bisector) test(commit string) (string, *bisectVariant, error) {
+	variant, err := b.run(commit)
+	if errors.Is(err, errBisectBuild) {
+		b.tested = append(b.tested, map[string]any{"commit": commit, "result": bisectResultSkipped, "buildOutput": variant.BuildOutput})
+		return bisectResultSkipped, variant, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	result := bisectResultGood
+	if b.good != nil && !sameObservations(b.good.Observations, variant.Observations) {
+		result = bisectResultDivergent
+	}
+	b.tested = append(b.tested, map[string]any{"commit": commit, "result": result})
+	return result, variant, nil
+}
+
+// failed reports err for commit with the commits tested so far
+func (b *bisector) failed(commit string, err error) *output.ErrorInfo {
+	return output.FromError(err).WithDetails(map[string]any{"commit": commit, "tested": b.tested})
+}
+
+// bisectCommits compares the good and bad commits and, if their observations
+// differ, bisects the commits between them for the first one that behaves
+// like bad. run replays the script against a commit and git runs git in a
+// directory.
+func bisectCommits(repo, goodCommit, badCommit string, run func(string) (*bisectVariant, error), git func(string, ...string) (string, error)) (map[string]any, string, *output.ErrorInfo) {
+	b := &bisector{run: run}
+	result, good, err := b.test(goodCommit)
+	if err == nil && result == bisectResultSkipped {
+		err = errBisectBuild
+	}
+	if err != nil {
+		return nil, "", b.failed(goodCommit, err)
+	}
+	b.good = good
+	result, bad, err := b.test(badCommit)
+	if err == nil && result == bisectResultSkipped {
+		err = errBisectBuild
+	}
+	if err != nil {
+		return nil, "", b.failed(badCommit, err)
+	}
+
+	data := map[string]any{
+		"good": goodCommit,
+		"bad":  badCommit,
+	}
+	if result == bisectResultGood {
+		data["diverged"] = false
+		data["tested"] = b.tested
+		return data, "No divergence between good and bad", nil
+	}
+
+	// Bisect the commits between good and bad, oldest first; the last one is bad
+	first := bad
+	revs, err := git(repo, "rev-list", "--reverse", "--ancestry-path", goodCommit+".."+badCommit)
+	commits := strings.Fields(revs)
+	if err == nil && len(commits) > 1 {
+		lo, hi := -1, len(commits)-1
+		for hi-lo > 1 {
+			mid := (lo + hi) / 2
+			result, variant, err := b.test(commits[mid])
+			if err != nil {
+				return nil, "", b.failed(commits[mid], err)
+			}
+			switch result {
+			case bisectResultSkipped:
+				commits = append(commits[:mid], commits[mid+1:]...)
+				hi--
+			case bisectResultGood:
+				lo = mid
+			default:
+				hi = mid
+				first = variant
+			}
+		}
+		data["bisected"] = true
+	} else {
+		// Not an ancestry path (or adjacent commits): only the endpoints are compared
+		data["bisected"] = false
+	}
+
+	firstData := map[string]any{"commit": first.Commit}
+	if subject, err := git(repo, "log", "-1", "--format=%s", first.Commit); err == nil {
+		firstData["subject"] = subject
+	}
+	data["diverged"] = true
+	data["firstDivergent"] = firstData
+	data["differences"] = diffValues(good.Observations, first.Observations)
+	data["tested"] = b.tested
+	return data, fmt.Sprintf("Observations first diverge at %.12s", first.Commit), nil
+}
+
+// addBisectRunCommand adds the bisect-run command
+func addBisectRunCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var bisectGood, bisectBad, bisectScriptPath, bisectBuild string
+
+	bisectRunCmd := &cobra.Command{
+		Use:   "bisect-run",
+		Short: "Replay scripted debugging steps across commits and find where values diverge",
+		Long: `Build the --good and --bad commits with a user-supplied build command, replay
+the same scripted debugging steps against each and compare what they observed.
+If the observations differ, the commits between the two are bisected to find
+the first one that behaves like --bad.
+
+Each commit is checked out into a temporary git worktree, so the working tree
+is left untouched. The build command runs in the worktree through sh -c, with
+{out} replaced by the path the binary must be written to. Commits that fail to
+build are skipped.
+
+The script is JSON:
+  {
+    "args":  ["-port", "0"],
+    "steps": [
+      {"cmd": "break", "arg": "internal/pricing/calc.go:42", "cond": "qty > 1"},
+      {"cmd": "continue"},
+      {"cmd": "eval", "arg": "total"},
+      {"cmd": "locals"}
+    ]
+  }
+break takes a function or a file:line relative to the repository root.
+
+Example:
+  godebug bisect-run --good v1.4.0 --bad main --script steps.json \
+    --build "go build -gcflags='all=-N -l' -o {out} ./cmd/myapp"`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if bisectGood == "" || bisectBad == "" || bisectScriptPath == "" || bisectBuild == "" {
+				output.ErrorWithInfo("bisect-run", output.InvalidArgument("--good, --bad, --script and --build are required")).PrintAndExit(getOutputFormat())
+			}
+			if !strings.Contains(bisectBuild, bisectOutPlaceholder) {
+				output.ErrorWithInfo("bisect-run", output.InvalidArgumentWithDetails(
+					"--build must write the binary to {out}",
+					map[string]any{"build": bisectBuild},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			script, err := loadBisectScript(bisectScriptPath)
+			if err != nil {
+				output.Error("bisect-run", err).PrintAndExit(getOutputFormat())
+			}
+
+			repo, err := gitOutput(".", "rev-parse", "--show-toplevel")
+			if err != nil {
+				output.Error("bisect-run", err).PrintAndExit(getOutputFormat())
+			}
+			goodCommit, err := gitOutput(repo, "rev-parse", "--verify", bisectGood+"^{commit}")
+			if err != nil {
+				output.ErrorWithInfo("bisect-run", output.NotFound("commit", bisectGood)).PrintAndExit(getOutputFormat())
+			}
+			badCommit, err := gitOutput(repo, "rev-parse", "--verify", bisectBad+"^{commit}")
+			if err != nil {
+				output.ErrorWithInfo("bisect-run", output.NotFound("commit", bisectBad)).PrintAndExit(getOutputFormat())
+			}
+
+			run := func(commit string) (*bisectVariant, error) {
+				return runBisectVariant(repo, commit, bisectBuild, script, getTimeout())
+			}
+			data, msg, errInfo := bisectCommits(repo, goodCommit, badCommit, run, gitOutput)
+			if errInfo != nil {
+				output.ErrorWithInfo("bisect-run", errInfo).PrintAndExit(getOutputFormat())
+			}
+			data["steps"] = len(script.Steps)
+			output.Success("bisect-run", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	bisectRunCmd.Flags().StringVar(&bisectGood, "good", "", "Git ref with the expected behavior")
+	bisectRunCmd.Flags().StringVar(&bisectBad, "bad", "", "Git ref with the changed behavior")
+	bisectRunCmd.Flags().StringVar(&bisectScriptPath, "script", "", "JSON file with program args and debugging steps")
+	bisectRunCmd.Flags().StringVar(&bisectBuild, "build", "", "Build command run in each checkout; must write the binary to {out}")
+	root.AddCommand(bisectRunCmd)
+}
+
+func init() {
+	addBisectRunCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
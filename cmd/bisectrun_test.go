@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeBisect replays a script against commits whose observed total is
+// given, or that fail to build when it is "build-error"
+func fakeBisect(totals map[string]string, ran *[]string) func(string) (*bisectVariant, error) {
+	return func(commit string) (*bisectVariant, error) {
+		*ran = append(*ran, commit)
+		switch total := totals[commit]; total {
+		case "build-error":
+			return &bisectVariant{Commit: commit, BuildOutput: []string{"undefined: x"}}, errBisectBuild
+		case "":
+			return nil, errors.New("cannot launch " + commit)
+		default:
+			return &bisectVariant{Commit: commit, Observations: map[string]string{"3:eval:total": total}}, nil
+		}
+	}
+}
+
+// fakeGit answers rev-list with commits and log with a subject per commit
+func fakeGit(commits ...string) func(string, ...string) (string, error) {
+	return func(_ string, args ...string) (string, error) {
+		switch args[0] {
+		case "rev-list":
+			return strings.Join(commits, "\n"), nil
+		case "log":
+			return "subject of " + args[len(args)-1], nil
+		}
+		return "", errors.New("unexpected git " + args[0])
+	}
+}
+
+// testedResults lists the result recorded for each tested commit
+func testedResults(data map[string]any) []string {
+	var results []string
+	for _, t := range data["tested"].([]map[string]any) {
+		results = append(results, t["commit"].(string)+"="+t["result"].(string))
+	}
+	return results
+}
+
+// TestBisectCommits checks the classification of commits and that the
+// search finds the first divergent commit, skipping commits that do not build.
+func TestBisectCommits(t *testing.T) {
+	tests := []struct {
+		name      string
+		totals    map[string]string
+		between   []string
+		first     string
+		bisected  bool
+		tested    []string
+		noDiverge bool
+	}{
+		{
+			name:      "same observations",
+			totals:    map[string]string{"g": "10", "b": "10"},
+			between:   []string{"c1", "b"},
+			noDiverge: true,
+			tested:    []string{"g=good", "b=good"},
+		},
+		{
+			name:     "bisected",
+			totals:   map[string]string{"g": "10", "c1": "10", "c2": "10", "c3": "12", "c4": "12", "b": "12"},
+			between:  []string{"c1", "c2", "c3", "c4", "b"},
+			first:    "c3",
+			bisected: true,
+			tested:   []string{"g=good", "b=divergent", "c2=good", "c3=divergent"},
+		},
+		{
+			name:     "build failure skipped",
+			totals:   map[string]string{"g": "10", "c1": "10", "c2": "build-error", "c3": "12", "b": "12"},
+			between:  []string{"c1", "c2", "c3", "b"},
+			first:    "c3",
+			bisected: true,
+			tested:   []string{"g=good", "b=divergent", "c2=skipped", "c1=good", "c3=divergent"},
+		},
+		{
+			name:     "adjacent commits",
+			totals:   map[string]string{"g": "10", "b": "12"},
+			between:  []string{"b"},
+			first:    "b",
+			bisected: false,
+			tested:   []string{"g=good", "b=divergent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			data, msg, errInfo := bisectCommits("/repo", "g", "b", fakeBisect(tt.totals, &ran), fakeGit(tt.between...))
+			if errInfo != nil {
+				t.Fatalf("bisectCommits: %v", errInfo)
+			}
+			if got := testedResults(data); !reflect.DeepEqual(got, tt.tested) {
+				t.Errorf("tested = %v; want %v", got, tt.tested)
+			}
+			if tt.noDiverge {
+				if data["diverged"] != false {
+					t.Errorf("diverged = %v; want false (%s)", data["diverged"], msg)
+				}
+				return
+			}
+			first := data["firstDivergent"].(map[string]any)
+			if first["commit"] != tt.first || first["subject"] != "subject of "+tt.first {
+				t.Errorf("firstDivergent = %v; want %s", first, tt.first)
+			}
+			if data["bisected"] != tt.bisected {
+				t.Errorf("bisected = %v; want %v", data["bisected"], tt.bisected)
+			}
+		})
+	}
+}
+
+// TestBisectCommitsFailures checks that an endpoint that does not build and
+// a commit that cannot be run end the search with the commits tested so far.
+func TestBisectCommitsFailures(t *testing.T) {
+	var ran []string
+	_, _, errInfo := bisectCommits("/repo", "g", "b",
+		fakeBisect(map[string]string{"g": "build-error", "b": "12"}, &ran), fakeGit("b"))
+	if errInfo == nil || errInfo.Message != errBisectBuild.Message {
+		t.Fatalf("good does not build: error %v", errInfo)
+	}
+	if details := errInfo.Details.(map[string]any); details["commit"] != "g" || len(details["tested"].([]map[string]any)) != 1 {
+		t.Errorf("good does not build: details %v", details)
+	}
+
+	ran = nil
+	_, _, errInfo = bisectCommits("/repo", "g", "b",
+		fakeBisect(map[string]string{"g": "10", "b": "12"}, &ran), fakeGit("c1", "c2", "c3", "b"))
+	if errInfo == nil || errInfo.Details.(map[string]any)["commit"] != "c2" {
+		t.Fatalf("c2 cannot run: error %v", errInfo)
+	}
+	if want := []string{"g", "b", "c2"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran %v; want %v", ran, want)
+	}
+}
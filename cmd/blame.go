@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blameInfo is the last commit that touched a source line
+type blameInfo struct {
+	Commit  string `json:"commit"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Summary string `json:"summary"`
+}
+
+// uncommittedSHA is what git blame reports for lines not yet committed
+const uncommittedSHA = "0000000000000000000000000000000000000000"
+
+// parseBlamePorcelain maps final line numbers to commits from
+// git blame --porcelain output. Commit details are only printed on the first
+// line attributed to each commit, so they are collected separately.
+func parseBlamePorcelain(out string) map[int]blameInfo {
+	commits := map[string]*blameInfo{}
+	lineCommit := map[int]string{}
+	var sha string
+	var line int
+	for _, l := range strings.Split(out, "\n") {
+		if strings.HasPrefix(l, "\t") {
+			lineCommit[line] = sha
+			continue
+		}
+		fields := strings.Fields(l)
+		if len(fields) >= 3 && len(fields[0]) == len(uncommittedSHA) {
+			if n, err := strconv.Atoi(fields[2]); err == nil {
+				sha, line = fields[0], n
+				if commits[sha] == nil {
+					commits[sha] = &blameInfo{Commit: sha[:12]}
+				}
+				continue
+			}
+		}
+		info := commits[sha]
+		if info == nil {
+			continue
+		}
+		key, value, _ := strings.Cut(l, " ")
+		switch key {
+		case "author":
+			info.Author = value
+		case "author-time":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.Date = time.Unix(secs, 0).UTC().Format(time.RFC3339)
+			}
+		case "summary":
+			info.Summary = value
+		}
+	}
+
+	result := make(map[int]blameInfo, len(lineCommit))
+	for n, sha := range lineCommit {
+		info := *commits[sha]
+		if sha == uncommittedSHA {
+			info = blameInfo{Commit: "uncommitted"}
+		}
+		result[n] = info
+	}
+	return result
+}
+
+// blameLines runs git blame for the given lines of file. Files outside a git
+// repository (the standard library, module cache) return an error.
+func blameLines(file string, lines []int) (map[int]blameInfo, error) {
+	args := []string{"blame", "--porcelain"}
+	sort.Ints(lines)
+	for _, n := range lines {
+		if n > 0 {
+			args = append(args, "-L", strconv.Itoa(n)+",+1")
+		}
+	}
+	out, err := gitOutput(filepath.Dir(file), append(args, "--", filepath.Base(file))...)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlamePorcelain(out), nil
+}
+
+// addFramesBlame sets "blame" on each stack frame whose file is tracked by git
+func addFramesBlame(frames []map[string]any) {
+	byFile := map[string][]int{}
+	for _, f := range frames {
+		file, _ := f["file"].(string)
+		line, _ := f["line"].(int)
+		if file != "" && isUserSource(file) {
+			byFile[file] = append(byFile[file], line)
+		}
+	}
+	for file, lines := range byFile {
+		blame, err := blameLines(file, lines)
+		if err != nil {
+			continue
+		}
+		for _, f := range frames {
+			if f["file"] != file {
+				continue
+			}
+			if info, ok := blame[f["line"].(int)]; ok {
+				f["blame"] = info
+			}
+		}
+	}
+}
+
+// addLinesBlame sets "blame" on each listed line of file
+func addLinesBlame(file string, lines []map[string]any) {
+	nums := make([]int, 0, len(lines))
+	for _, l := range lines {
+		nums = append(nums, l["lineNumber"].(int))
+	}
+	blame, err := blameLines(file, nums)
+	if err != nil {
+		return
+	}
+	for _, l := range lines {
+		if info, ok := blame[l["lineNumber"].(int)]; ok {
+			l["blame"] = info
+		}
+	}
+}
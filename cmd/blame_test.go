@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseBlamePorcelain checks that commit details printed only once are
+// attributed to every line of that commit, and that uncommitted lines are marked.
+func TestParseBlamePorcelain(t *testing.T) {
+	out := `d38d047ba878f73f6c4582181f627679b07444a7 3 3 1
+author Ada
+author-mail <ada@example.com>
+author-time 1700000000
+author-tz +0000
+summary Add parser
+filename main.go
+	import "fmt"
+d38d047ba878f73f6c4582181f627679b07444a7 5 5 1
+	func main() {
+0000000000000000000000000000000000000000 6 6 1
+author Not Committed Yet
+author-time 1700000100
+summary Version of main.go from main.go
+filename main.go
+	fmt.Println("wip")
+`
+	want := map[int]blameInfo{
+		3: {Commit: "d38d047ba878", Author: "Ada", Date: "2023-11-14T22:13:20Z", Summary: "Add parser"},
+		5: {Commit: "d38d047ba878", Author: "Ada", Date: "2023-11-14T22:13:20Z", Summary: "Add parser"},
+		6: {Commit: "uncommitted"},
+	}
+	if got := parseBlamePorcelain(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBlamePorcelain = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// setBreakpointEnabled enables or disables breakpoint idArg through
+// AmendBreakpoint, keeping its ID, condition and hit counts
+func setBreakpointEnabled(mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, name, idArg string, enabled bool) {
+	id := parseBreakpointID(name, idArg, getOutputFormat)
+
+	c := mustGetClient(name)
+	defer func() { _ = c.Close() }()
+
+	bp, err := c.GetBreakpoint(id)
+	if err != nil {
+		output.Error(name, err).PrintAndExit(getOutputFormat())
+	}
+	changed := bp.Disabled == enabled
+	if changed {
+		bp.Disabled = !enabled
+		if err := c.AmendBreakpoint(bp); err != nil {
+			output.Error(name, err).PrintAndExit(getOutputFormat())
+		}
+	}
+
+	data := map[string]any{
+		"id":       bp.ID,
+		"file":     bp.File,
+		"line":     bp.Line,
+		"function": bp.FunctionName,
+		"enabled":  enabled,
+		"changed":  changed,
+		"hitCount": bp.TotalHitCount,
+	}
+	state := "enabled"
+	if !enabled {
+		state = "disabled"
+	}
+	msg := fmt.Sprintf("Breakpoint %d %s", bp.ID, state)
+	if !changed {
+		msg = fmt.Sprintf("Breakpoint %d was already %s", bp.ID, state)
+	}
+	output.Success(name, data, msg).PrintAndExit(getOutputFormat())
+}
+
+// addBreakpointCommand adds the breakpoint command with its enable, disable
+// and edit subcommands
+func addBreakpointCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var editCond, editHitCond string
+	var editClearCond, editClearHitCond bool
+
+	breakpointCmd := &cobra.Command{
+		Use:   "breakpoint",
+		Short: "Enable, disable or edit an existing breakpoint",
+		Long: `Change an existing breakpoint in place. It keeps its ID and hit counts,
+unlike clearing it and setting it again.
+
+Example:
+  godebug --addr $ADDR breakpoint disable 1
+  godebug --addr $ADDR breakpoint enable 1
+  godebug --addr $ADDR breakpoint edit 1 --cond "user.ID == 42"`,
+	}
+
+	enableCmd := &cobra.Command{
+		Use:   "enable <id>",
+		Short: "Enable a disabled breakpoint",
+		Long: `Enable a breakpoint disabled with breakpoint disable, so it stops the
+program again. data.changed is false if it was enabled already.
+
+Example:
+  godebug --addr $ADDR breakpoint enable 1`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			setBreakpointEnabled(mustGetClient, getOutputFormat, "breakpoint enable", args[0], true)
+		},
+	}
+
+	disableCmd := &cobra.Command{
+		Use:   "disable <id>",
+		Short: "Disable a breakpoint without removing it",
+		Long: `Disable a breakpoint: the program no longer stops there and its hit count
+stops growing, but it keeps its ID, condition and hit counts until breakpoint
+enable. breakpoints lists it with enabled: false. data.changed is false if it
+was disabled already.
+
+Example:
+  godebug --addr $ADDR breakpoint disable 1`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			setBreakpointEnabled(mustGetClient, getOutputFormat, "breakpoint disable", args[0], false)
+		},
+	}
+
+	editCmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Change a breakpoint's condition",
+		Long: `Set, replace or clear the condition and hit condition of an existing
+breakpoint, like condition. --cond takes the same helpers as break --cond.
+
+Options:
+  --cond "expr"      Stop only when expr is true
+  --hitcond "op N"   Stop only when the hit count satisfies op N, where op is
+                     one of ==, !=, >, >=, <, <= or % (e.g. "> 10", "% 5")
+  --clear-cond       Remove the condition
+  --clear-hitcond    Remove the hit condition
+
+Example:
+  godebug --addr $ADDR breakpoint edit 1 --cond "user.ID == 42"
+  godebug --addr $ADDR breakpoint edit 1 --hitcond "% 100" --clear-cond`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			amendCondition(mustGetClient, getOutputFormat, "breakpoint edit", args[0], conditionChange{
+				Label:        "--cond",
+				Cond:         editCond,
+				SetCond:      cmd.Flags().Changed("cond"),
+				HitCond:      editHitCond,
+				SetHitCond:   cmd.Flags().Changed("hitcond"),
+				ClearCond:    editClearCond,
+				ClearHitCond: editClearHitCond,
+			})
+		},
+	}
+
+	editCmd.Flags().StringVar(&editCond, "cond", "", "Conditional expression")
+	editCmd.Flags().StringVar(&editHitCond, "hitcond", "", "Hit count condition, e.g. \"> 10\" or \"% 5\"")
+	editCmd.Flags().BoolVar(&editClearCond, "clear-cond", false, "Remove the condition")
+	editCmd.Flags().BoolVar(&editClearHitCond, "clear-hitcond", false, "Remove the hit condition")
+
+	breakpointCmd.AddCommand(enableCmd, disableCmd, editCmd)
+	root.AddCommand(breakpointCmd)
+}
+
+func init() {
+	addBreakpointCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+// TestConditionChangeValidate checks contradictory and empty changes are
+// rejected under the label of the condition argument.
+func TestConditionChangeValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change conditionChange
+		want   string
+	}{
+		{"cond", conditionChange{Label: "--cond", SetCond: true, Cond: "x > 1"}, ""},
+		{"clear both", conditionChange{Label: "--cond", ClearCond: true, ClearHitCond: true}, ""},
+		{"cond and clear", conditionChange{Label: "--cond", SetCond: true, ClearCond: true}, "--cond cannot be combined with --clear-cond"},
+		{"hitcond and clear", conditionChange{Label: "an expression", SetHitCond: true, ClearHitCond: true}, "--hitcond cannot be combined with --clear-hitcond"},
+		{"nothing", conditionChange{Label: "an expression"}, "nothing to change: give an expression, --hitcond, --clear-cond or --clear-hitcond"},
+	}
+	for _, tt := range tests {
+		info := tt.change.validate()
+		got := ""
+		if info != nil {
+			got = info.Message
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// maxRegexBreakpoints bounds the functions break --regex sets breakpoints on;
+// a broader pattern is reported with a sample of its matches
+const maxRegexBreakpoints = 100
+
+// functionsArgsError checks the break arguments used with --regex or
+// --type, which set a breakpoint on each of several functions
+func functionsArgsError(args []string, pattern, typeName, assign string, ops []string, dump bool, hitCond string) *output.ErrorInfo {
+	if pattern == "" && typeName == "" {
+		return nil
+	}
+	flag := "--regex"
+	if typeName != "" {
+		flag = "--type"
+	}
+	if pattern != "" && typeName != "" {
+		return output.InvalidArgument("--regex cannot be combined with --type")
+	}
+	if len(args) > 0 || assign != "" || len(ops) > 0 {
+		return output.InvalidArgument(flag + " cannot be combined with a location, --assign or --chan-op")
+	}
+	// Hit counts and goroutine dumps are per breakpoint, and these set several
+	if dump || hitCond != "" {
+		return output.InvalidArgument(flag + " cannot be combined with --dump-goroutines or --hitcond")
+	}
+	if pattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid function regexp: %v", err),
+			map[string]any{"regexp": pattern},
+		)
+	}
+	return nil
+}
+
+// breakOnRegex sets a breakpoint at the entry of every function whose name
+// matches pattern, each a copy of tmpl (condition, collected expressions,
+// stack depth)
+func breakOnRegex(c *debugger.Client, pattern string, tmpl api.Breakpoint) (map[string]any, error) {
+	functions, err := c.ListFunctions(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(functions) == 0 {
+		return nil, output.NotFound("function matching", pattern)
+	}
+	if len(functions) > maxRegexBreakpoints {
+		return nil, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("%d functions match %s (more than %d); narrow the regexp", len(functions), pattern, maxRegexBreakpoints),
+			map[string]any{"regexp": pattern, "matches": len(functions), "sample": functions[:20]},
+		)
+	}
+
+	data, err := breakOnFunctions(c, functions, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	data["regex"] = pattern
+	return data, nil
+}
+
+// breakOnFunctions sets a breakpoint at the entry of each function, each a
+// copy of tmpl, and reports every one's outcome
+func breakOnFunctions(c *debugger.Client, functions []string, tmpl api.Breakpoint) (map[string]any, error) {
+	var results itemResults
+	for _, fn := range functions {
+		bp := tmpl
+		bp.FunctionName = fn
+		created, err := c.CreateBreakpoint(&bp)
+		if err != nil {
+			results.fail(map[string]any{"function": fn}, err)
+			continue
+		}
+		if tmpl.Stacktrace > 0 {
+			saveStackBreakpoint(c.Addr(), created.ID, tmpl.Stacktrace)
+		}
+		results.ok(map[string]any{
+			"id":       created.ID,
+			"file":     created.File,
+			"line":     created.Line,
+			"function": created.FunctionName,
+		})
+	}
+
+	data := map[string]any{"count": len(results.succeeded)}
+	results.apply(data, "breakpoints")
+	ids := make([]int, 0, len(results.succeeded))
+	for _, bp := range results.succeeded {
+		ids = append(ids, bp["id"].(int))
+	}
+	data["ids"] = ids
+	if tmpl.Cond != "" {
+		data["condition"] = tmpl.Cond
+	}
+	if tmpl.Stacktrace > 0 {
+		data["stacktrace"] = tmpl.Stacktrace
+	}
+	if info := results.allFailed("breakpoints", data); info != nil {
+		return nil, info
+	}
+	return data, nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import "testing"
+
+func TestFunctionsArgsError(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		pattern  string
+		typeName string
+		assign   string
+		ops      []string
+		dump     bool
+		hitCond  string
+		ok       bool
+	}{
+		{"valid", nil, `main\.handle.*`, "", "", nil, false, "", true},
+		{"no regex", []string{"main.go:1"}, "", "", "", nil, false, "== 2", true},
+		{"with location", []string{"main.go:1"}, "main", "", "", nil, false, "", false},
+		{"with assign", nil, "main", "", "counter", nil, false, "", false},
+		{"with chan-op", nil, "main", "", "", []string{"send"}, false, "", false},
+		{"with dump", nil, "main", "", "", nil, true, "", false},
+		{"with hitcond", nil, "main", "", "", nil, false, "== 2", false},
+		{"invalid regexp", nil, "main.(", "", "", nil, false, "", false},
+		{"type", nil, "", "main.Worker", "", nil, false, "", true},
+		{"type with regex", nil, "main", "main.Worker", "", nil, false, "", false},
+		{"type with location", []string{"main.go:1"}, "", "main.Worker", "", nil, false, "", false},
+	}
+	for _, tt := range tests {
+		if info := functionsArgsError(tt.args, tt.pattern, tt.typeName, tt.assign, tt.ops, tt.dump, tt.hitCond); (info == nil) != tt.ok {
+			t.Errorf("%s: got %v", tt.name, info)
+		}
+	}
+}
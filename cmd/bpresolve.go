@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Breakpoint resolution warning codes
+const (
+	warnBreakpointUnresolved   = "BREAKPOINT_UNRESOLVED"    // no instruction address; it can never trigger
+	warnBreakpointFileMismatch = "BREAKPOINT_FILE_MISMATCH" // resolved to a different file than requested
+	warnBreakpointLineMoved    = "BREAKPOINT_LINE_MOVED"    // resolved to a different line than requested
+	warnBreakpointNilChannel   = "BREAKPOINT_NIL_CHANNEL"   // --chan-op on a nil channel matches every nil channel
+)
+
+// maxFileCandidates bounds the source files suggested for an unknown file
+const maxFileCandidates = 10
+
+// breakpointWarning explains why a breakpoint that was set may not trigger
+// where it was asked for
+type breakpointWarning struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Requested string `json:"requested,omitempty"`
+	Resolved  string `json:"resolved,omitempty"`
+}
+
+// locationBreakpoint builds a breakpoint at a file:line or function location,
+// resolving it as break does
+func locationBreakpoint(c *debugger.Client, location string) (*api.Breakpoint, error) {
+	if file, line, ok := strings.Cut(location, ":"); ok {
+		n, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, output.InvalidArgumentWithDetails(
+				fmt.Sprintf("invalid line number: %s", line),
+				map[string]any{"location": location, "line": line},
+			)
+		}
+		bp := &api.Breakpoint{Line: n}
+		bp.File, _ = resolveSourceFile(c, file)
+		return bp, nil
+	}
+	name, err := resolveFunctionLocation(c, location)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Breakpoint{FunctionName: name}, nil
+}
+
+// breakpointWarnings compares the breakpoint Delve created with the request
+func breakpointWarnings(requested, created *api.Breakpoint) []breakpointWarning {
+	var warnings []breakpointWarning
+	if len(created.Addrs) == 0 && created.Addr == 0 {
+		warnings = append(warnings, breakpointWarning{
+			Code:    warnBreakpointUnresolved,
+			Message: "breakpoint has no instruction address and will never trigger; the location may be in code that is not compiled into the binary (build tags, dead code, another package's copy)",
+		})
+	}
+	if requested.File == "" {
+		return warnings
+	}
+	if created.File != "" && filepath.Clean(created.File) != filepath.Clean(requested.File) {
+		warnings = append(warnings, breakpointWarning{
+			Code:      warnBreakpointFileMismatch,
+			Message:   "breakpoint resolved to a different file than requested; the binary may have been built from another copy of the sources (module cache, vendor directory or another checkout)",
+			Requested: requested.File,
+			Resolved:  created.File,
+		})
+	}
+	if created.Line != 0 && created.Line != requested.Line {
+		warnings = append(warnings, breakpointWarning{
+			Code:      warnBreakpointLineMoved,
+			Message:   fmt.Sprintf("breakpoint resolved to line %d instead of line %d", created.Line, requested.Line),
+			Requested: fmt.Sprintf("%s:%d", requested.File, requested.Line),
+			Resolved:  fmt.Sprintf("%s:%d", created.File, created.Line),
+		})
+	}
+	return warnings
+}
+
+// fileCandidates returns the target's source files that share the longest
+// trailing path of file, e.g. the module cache copy of a workspace file
+func fileCandidates(sources []string, file string) []string {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(file)), "/")
+	for i := range parts {
+		suffix := strings.Join(parts[i:], "/")
+		if suffix == "" {
+			continue
+		}
+		var matches []string
+		for _, src := range sources {
+			src = filepath.ToSlash(src)
+			if src == suffix || strings.HasSuffix(src, "/"+suffix) {
+				matches = append(matches, src)
+			}
+		}
+		if len(matches) > 0 {
+			sort.Strings(matches)
+			if len(matches) > maxFileCandidates {
+				matches = matches[:maxFileCandidates]
+			}
+			return matches
+		}
+	}
+	return nil
+}
+
+// unresolvedFunctionError explains a breakpoint request for a function the
+// binary does not have and suggests functions with a similar name
+func unresolvedFunctionError(c *debugger.Client, bp *api.Breakpoint, err error) *output.ErrorInfo {
+	if bp.FunctionName == "" || !strings.Contains(err.Error(), "could not find function") {
+		return nil
+	}
+	name := stripTypeParams(bp.FunctionName)
+	last := name[strings.LastIndex(name, ".")+1:]
+	functions, listErr := c.ListFunctions("(?i)" + regexp.QuoteMeta(last))
+	if listErr != nil {
+		return nil
+	}
+	candidates := listedFunctions(functions, mainModuleFilter(mainModulePath(c)), false)
+	if len(candidates) == 0 {
+		candidates = listedFunctions(functions, nil, true)
+	}
+	if len(candidates) > maxFunctionCandidates {
+		candidates = candidates[:maxFunctionCandidates]
+	}
+	details := map[string]any{
+		"requested":  bp.FunctionName,
+		"candidates": candidates,
+		"hint":       "funcs <regexp> lists the functions in the binary",
+	}
+	msg := fmt.Sprintf("breakpoint not set: the binary has no function %s", bp.FunctionName)
+	if len(candidates) > 0 {
+		details["suggestion"] = candidates[0]
+		msg += fmt.Sprintf("; similar: %s", strings.Join(candidates[:min(len(candidates), 3)], ", "))
+	}
+	addPluginHint(c, details)
+	return output.NewErrorInfo(output.ErrCodeNotFound, msg).WithDetails(details)
+}
+
+// unresolvedFileError explains a breakpoint request for a file the binary was
+// not built from and suggests the files it was built from that match. Unknown
+// functions are explained by unresolvedFunctionError.
+func unresolvedFileError(c *debugger.Client, bp *api.Breakpoint, err error) *output.ErrorInfo {
+	if info := unresolvedFunctionError(c, bp, err); info != nil {
+		return info
+	}
+	if bp.File == "" || !strings.Contains(err.Error(), "could not find file") {
+		return nil
+	}
+	sources, listErr := c.ListSources("")
+	if listErr != nil {
+		return nil
+	}
+	candidates := fileCandidates(sources, bp.File)
+	details := map[string]any{
+		"requested":  bp.File,
+		"line":       bp.Line,
+		"candidates": candidates,
+	}
+	msg := fmt.Sprintf("breakpoint not set: the binary has no code from %s", bp.File)
+	if len(candidates) > 0 {
+		details["suggestion"] = fmt.Sprintf("%s:%d", candidates[0], bp.Line)
+		if len(candidates) == 1 {
+			msg += fmt.Sprintf("; it was built from %s", candidates[0])
+		} else {
+			msg += fmt.Sprintf("; %d of its files match, e.g. %s", len(candidates), candidates[0])
+		}
+	} else {
+		details["candidates"] = []string{}
+		msg += "; see sources for the files it was built from"
+	}
+	addPluginHint(c, details)
+	return output.NewErrorInfo(output.ErrCodeNotFound, msg).WithDetails(details)
+}
+
+// withoutWarning drops the warnings with code
+func withoutWarning(warnings []breakpointWarning, code string) []breakpointWarning {
+	kept := warnings[:0]
+	for _, w := range warnings {
+		if w.Code != code {
+			kept = append(kept, w)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestFileCandidates checks that the longest shared trailing path wins.
+func TestFileCandidates(t *testing.T) {
+	sources := []string{
+		"/root/go/pkg/mod/example.com/app@v1.2.0/internal/store/store.go",
+		"/root/go/pkg/mod/example.com/app@v1.2.0/main.go",
+		"/src/other/store/store.go",
+		"/usr/local/go/src/fmt/print.go",
+	}
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"/home/me/app/internal/store/store.go", sources[:1]},
+		{"/home/me/app/store/store.go", []string{sources[0], sources[2]}},
+		{"/home/me/app/main.go", sources[1:2]},
+		{"/home/me/app/missing.go", nil},
+	}
+	for _, tt := range tests {
+		if got := fileCandidates(sources, tt.file); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fileCandidates(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+// TestBreakpointWarnings checks the warnings for unresolved and moved breakpoints.
+func TestBreakpointWarnings(t *testing.T) {
+	requested := &api.Breakpoint{File: "/app/main.go", Line: 10}
+	codes := func(created *api.Breakpoint) []string {
+		var out []string
+		for _, w := range breakpointWarnings(requested, created) {
+			out = append(out, w.Code)
+		}
+		return out
+	}
+
+	if got := codes(&api.Breakpoint{File: "/app/main.go", Line: 10, Addrs: []uint64{0x1000}}); got != nil {
+		t.Errorf("resolved breakpoint: got warnings %v", got)
+	}
+	want := []string{warnBreakpointUnresolved}
+	if got := codes(&api.Breakpoint{File: "/app/main.go", Line: 10}); !reflect.DeepEqual(got, want) {
+		t.Errorf("unresolved breakpoint: got %v, want %v", got, want)
+	}
+	want = []string{warnBreakpointFileMismatch, warnBreakpointLineMoved}
+	if got := codes(&api.Breakpoint{File: "/mod/app/main.go", Line: 12, Addrs: []uint64{0x1000}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("moved breakpoint: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// matchTypeMethods returns the receiver type typeName names and its methods,
+// or the candidates when it names several types. The type is written as
+// break takes functions: by import path, its last element or, within the
+// main module, bare; * and type parameters are ignored. The (*T).M wrapper
+// of a value method is left out, as it only calls T.M.
+func matchTypeMethods(functions []string, typeName string, inMainModule func(string) bool) (string, []string, []string) {
+	want := strings.NewReplacer("(", "", ")", "", "*", "").Replace(stripTypeParams(typeName))
+
+	var receivers []string
+	methods := typeMethods(functions)
+	for recv := range methods {
+		dot := strings.LastIndex(recv, ".")
+		sym := funcSymbol{Pkg: recv[:dot], Name: recv[dot+1:]}
+		if slices.Contains(sym.keys(inMainModule), want) {
+			receivers = append(receivers, recv)
+		}
+	}
+	sort.Strings(receivers)
+	if len(receivers) != 1 {
+		return "", nil, receivers
+	}
+
+	recv := receivers[0]
+	var set []string
+	for _, m := range methods[recv] {
+		if sym := parseFuncSymbol(m); sym.Ptr && slices.Contains(methods[recv], recv+"."+sym.Name) {
+			continue
+		}
+		set = append(set, m)
+	}
+	return recv, set, nil
+}
+
+// breakOnType sets a breakpoint at the entry of every method of the type
+// typeName names, each a copy of tmpl
+func breakOnType(c *debugger.Client, typeName string, tmpl api.Breakpoint) (map[string]any, error) {
+	want := strings.NewReplacer("(", "", ")", "", "*", "").Replace(stripTypeParams(typeName))
+	// Only functions containing the type's name can be its methods
+	functions, err := c.ListFunctions(regexp.QuoteMeta(want[strings.LastIndex(want, ".")+1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	recv, methods, candidates := matchTypeMethods(functions, typeName, mainModuleFilter(mainModulePath(c)))
+	if len(candidates) > 1 {
+		if len(candidates) > maxFunctionCandidates {
+			candidates = candidates[:maxFunctionCandidates]
+		}
+		return nil, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("type %s matches several types; use one of the candidates", typeName),
+			map[string]any{"type": typeName, "candidates": candidates},
+		)
+	}
+	if len(methods) == 0 {
+		return nil, output.NotFound("methods of type", typeName)
+	}
+
+	data, err := breakOnFunctions(c, methods, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	data["type"] = recv
+	return data, nil
+}
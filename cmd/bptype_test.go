@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatchTypeMethods(t *testing.T) {
+	functions := []string{
+		"main.(*Worker).Run",
+		"main.(*Worker).Run.func1",
+		"main.Worker.String",
+		"main.(*Worker).String",
+		"main.newWorker",
+		"example.com/app/pool.(*Worker).Stop",
+		"main.(*Box[go.shape.int]).Get",
+	}
+	inMain := mainModuleFilter("example.com/app")
+
+	recv, methods, _ := matchTypeMethods(functions, "main.Worker", inMain)
+	if recv != "main.Worker" || !slices.Equal(methods, []string{"main.(*Worker).Run", "main.Worker.String"}) {
+		t.Errorf("main.Worker = %s %v", recv, methods)
+	}
+	if recv, methods, _ := matchTypeMethods(functions, "*pool.Worker", inMain); recv != "example.com/app/pool.Worker" || len(methods) != 1 {
+		t.Errorf("*pool.Worker = %s %v", recv, methods)
+	}
+	if _, methods, _ := matchTypeMethods(functions, "Box[int]", inMain); !slices.Equal(methods, []string{"main.(*Box).Get"}) {
+		t.Errorf("Box[int] = %v", methods)
+	}
+	if _, _, candidates := matchTypeMethods(functions, "Worker", inMain); len(candidates) != 2 {
+		t.Errorf("Worker candidates = %v, want 2", candidates)
+	}
+	if recv, methods, _ := matchTypeMethods(functions, "main.Missing", inMain); recv != "" || methods != nil {
+		t.Errorf("main.Missing = %s %v", recv, methods)
+	}
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -13,32 +12,211 @@ import (
 )
 
 var (
-	breakCond string
+	breakCond        string
+	breakHitCond     string
+	breakCollectDiff string
+	breakAssign      string
+	breakChanOps     []string
+	breakExpr        string
+	breakDump        bool
+	breakDumpFilter  string
+	breakOnPanic     bool
+	breakStacktrace  int
+	breakRegex       string
+	breakType        string
+	breakSuspended   bool
 )
 
 var breakCmd = &cobra.Command{
-	Use:   "break <location>",
+	Use:   "break [location]",
 	Short: "Set a breakpoint",
 	Long: `Set a breakpoint at the specified location.
 
 Location formats:
   file.go:line    - Set at file and line number
   pkg.Function    - Set at function entry
+  Type.Method     - Method shorthand: pkg.(*Type).Method, pkg.Type.Method,
+                    (*Type).Method, *Type.Method and Type.Method (main module)
+                    all work; type parameters may be left out (List.Push
+                    covers every instantiation) and pkg.init names the
+                    package's init functions
+
+Files are relative to the working directory and are translated to the paths
+the binary was compiled with (-trimpath, module cache, GOPATH or another
+checkout of the same module), so local workspace paths work as given.
 
 Options:
-  --cond "expr"   - Only trigger when expression is true
+  --cond "expr"          - Only trigger when expression is true
+                           (helpers: approx(x, y, eps), since(t), until(t),
+                           elapsed(a, b), unixnano(t), dur("2s"); see
+                           condition --help)
+  --hitcond "op N"       - Only trigger when the hit count satisfies op N:
+                           ==, !=, >, >=, <, <= or % (every Nth hit); hits
+                           are counted where --cond held
+  --collect-diff "expr"  - Evaluate expr at each hit; continue passes hits
+                           where it did not change and stops at the first
+                           that changed, reporting the diff
+  --assign name          - Instead of a location, break at every statement in
+                           the program's sources that writes name (assignments,
+                           ++/--, sync/atomic writes); a bare name also matches
+                           struct fields of that name
+  --chan-op ops --expr ch
+                         - Instead of a location, break whenever any goroutine
+                           sends on (send), receives from (recv) or closes
+                           (close) the channel ch; ops is a comma-separated
+                           list. ch is evaluated in the selected frame. The
+                           stop is inside the runtime function; the caller is
+                           frame 1. Operations in a select with several cases
+                           are not caught.
+  --dump-goroutines      - Do not stop continue here; at each hit record a
+                           summary of the goroutines with a user frame,
+                           grouped by location and state (see goroutine-dumps)
+  --dump-filter text     - Only summarize goroutines whose user location
+                           (function or file:line) contains text
+  --stacktrace N         - Do not stop continue here; at each hit record the
+                           call path (N frames) that led to it (see
+                           hit-stacks for the paths by frequency)
+  --regex pattern        - Instead of a location, break at the entry of every
+                           function whose name matches the regexp (at most
+                           100); --cond, --collect-diff and --stacktrace
+                           apply to each
+  --type T               - Instead of a location, break at the entry of every
+                           method of the named type T (value and pointer
+                           receivers), written like a function location
+  --on-panic             - Instead of a location, stop on unrecovered panics
+                           and fatal runtime errors at the panic site (the
+                           breakpoints Delve sets at launch; see panics)
+  --suspended            - Keep the breakpoint when the location is not found
+                           yet; Delve sets it once a Go plugin providing it
+                           is loaded (see libs)
+
+A file the binary has no code from is reported with the binary's files that
+match it (e.g. its module cache copy). A breakpoint that resolved to another
+file or line, or to no address at all, is set with warnings.
 
 Examples:
   godebug --addr $ADDR break main.go:42
   godebug --addr $ADDR break main.handleRequest
-  godebug --addr $ADDR break main.go:42 --cond "x > 10"`,
-	Args: cobra.ExactArgs(1),
+  godebug --addr $ADDR break main.go:42 --cond "x > 10"
+  godebug --addr $ADDR break loop.go:17 --hitcond "== 100"
+  godebug --addr $ADDR break main.go:42 --collect-diff "order"
+  godebug --addr $ADDR break --assign counter
+  godebug --addr $ADDR break --chan-op send,close --expr w.tasks
+  godebug --addr $ADDR break worker.go:30 --dump-goroutines --dump-filter worker
+  godebug --addr $ADDR break cache.go:88 --stacktrace 8
+  godebug --addr $ADDR break --regex 'main\.handle.*'
+  godebug --addr $ADDR break --type main.Worker
+  godebug --addr $ADDR break main.Handle --suspended
+  godebug --addr $ADDR break --on-panic`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		// A suspended breakpoint waits for one location to appear in a plugin
+		if breakSuspended && (len(args) == 0 || breakOnPanic || breakAssign != "" || len(breakChanOps) > 0 || breakRegex != "" || breakType != "") {
+			output.ErrorWithInfo("break", output.InvalidArgument("--suspended takes a location and cannot be combined with --on-panic, --assign, --chan-op, --regex or --type")).PrintAndExit(GetOutputFormat())
+		}
+		// --on-panic only turns on the crash breakpoints, like panics on
+		if breakOnPanic {
+			if len(args) > 0 || breakAssign != "" || len(breakChanOps) > 0 || breakRegex != "" || breakType != "" {
+				output.ErrorWithInfo("break", output.InvalidArgument("--on-panic cannot be combined with a location, --assign, --chan-op, --regex or --type")).PrintAndExit(GetOutputFormat())
+			}
+			c := MustGetClient("break")
+			data, err := setPanicBreakpoints(c, true)
+			_ = c.Close()
+			if err != nil {
+				output.Error("break", err).PrintAndExit(GetOutputFormat())
+			}
+			output.Success("break", data, panicsMessage(data)).PrintAndExit(GetOutputFormat())
+		}
+		// --assign places breakpoints itself and takes no location
+		if breakAssign != "" && len(args) > 0 {
+			output.ErrorWithInfo("break", output.InvalidArgumentWithDetails(
+				"--assign cannot be combined with a location",
+				map[string]any{"location": args[0], "assign": breakAssign},
+			)).PrintAndExit(GetOutputFormat())
+		}
+		if breakDumpFilter != "" {
+			breakDump = true
+		}
+		if breakAssign != "" && breakDump {
+			output.ErrorWithInfo("break", output.InvalidArgument("--dump-goroutines cannot be combined with --assign")).PrintAndExit(GetOutputFormat())
+		}
+		if info := chanOpArgsError(args, breakChanOps, breakExpr, breakAssign, breakDump); info != nil {
+			output.ErrorWithInfo("break", info).PrintAndExit(GetOutputFormat())
+		}
+		if info := functionsArgsError(args, breakRegex, breakType, breakAssign, breakChanOps, breakDump, breakHitCond); info != nil {
+			output.ErrorWithInfo("break", info).PrintAndExit(GetOutputFormat())
+		}
+		if breakAssign == "" && len(breakChanOps) == 0 && breakRegex == "" && breakType == "" && len(args) == 0 {
+			output.ErrorWithInfo("break", output.InvalidArgument("location is required (or use --assign, --chan-op, --regex, --type or --on-panic)")).PrintAndExit(GetOutputFormat())
+		}
+
+		// Condition helpers such as approx() are expanded into Delve expressions
+		cond, info := conditionFlag(breakCond)
+		if info != nil {
+			output.ErrorWithInfo("break", info).PrintAndExit(GetOutputFormat())
+		}
+		hitCond, info := hitCondFlag(breakHitCond)
+		if info != nil {
+			output.ErrorWithInfo("break", info).PrintAndExit(GetOutputFormat())
+		}
+		// Hit counts are per breakpoint, and --assign and --chan-op set several
+		if hitCond != "" && (breakAssign != "" || len(breakChanOps) > 0) {
+			output.ErrorWithInfo("break", output.InvalidArgument("--hitcond cannot be combined with --assign or --chan-op")).PrintAndExit(GetOutputFormat())
+		}
+		if breakStacktrace < 0 {
+			output.ErrorWithInfo("break", output.InvalidArgument("--stacktrace must not be negative")).PrintAndExit(GetOutputFormat())
+		}
+		if breakStacktrace > 0 && (breakAssign != "" || len(breakChanOps) > 0) {
+			output.ErrorWithInfo("break", output.InvalidArgument("--stacktrace cannot be combined with --assign or --chan-op")).PrintAndExit(GetOutputFormat())
+		}
+
 		c := MustGetClient("break")
 		defer func() { _ = c.Close() }()
 
+		if len(breakChanOps) > 0 {
+			data, err := breakOnChanOps(c, breakChanOps, breakExpr, cond)
+			if err != nil {
+				output.Error("break", err).PrintAndExit(GetOutputFormat())
+			}
+			output.Success("break", data, fmt.Sprintf("%d breakpoints set on %s of %s%s", data["count"], strings.Join(breakChanOps, "/"), breakExpr, failedSuffix(data))).PrintAndExit(GetOutputFormat())
+		}
+
+		if breakAssign != "" {
+			data, err := breakOnAssignments(c, breakAssign, cond, breakCollectDiff)
+			if err != nil {
+				output.Error("break", err).PrintAndExit(GetOutputFormat())
+			}
+			if data["count"] == 0 && data["failed"] == nil {
+				output.ErrorWithInfo("break", output.NotFound("assignment", breakAssign)).PrintAndExit(GetOutputFormat())
+			}
+			output.Success("break", data, fmt.Sprintf("%d breakpoints set on assignments to %s%s", data["count"], breakAssign, failedSuffix(data))).PrintAndExit(GetOutputFormat())
+		}
+
+		if breakRegex != "" || breakType != "" {
+			tmpl := api.Breakpoint{Cond: cond, Stacktrace: breakStacktrace}
+			if breakCollectDiff != "" {
+				tmpl.Variables = []string{breakCollectDiff}
+			}
+			var data map[string]any
+			var err error
+			if breakType != "" {
+				data, err = breakOnType(c, breakType, tmpl)
+			} else {
+				data, err = breakOnRegex(c, breakRegex, tmpl)
+			}
+			if err != nil {
+				output.Error("break", err).PrintAndExit(GetOutputFormat())
+			}
+			msg := fmt.Sprintf("%d breakpoints set on functions matching %s", data["count"], breakRegex)
+			if breakType != "" {
+				msg = fmt.Sprintf("%d breakpoints set on methods of %s", data["count"], data["type"])
+			}
+			output.Success("break", data, msg+failedSuffix(data)).PrintAndExit(GetOutputFormat())
+		}
+
 		location := args[0]
 		bp := &api.Breakpoint{}
+		var requestedFile, resolvedBy, requestedFunction string
 
 		// Parse location: file:line or function name
 		if strings.Contains(location, ":") {
@@ -51,26 +229,43 @@ Examples:
 					map[string]any{"location": location, "line": parts[1]},
 				)).PrintAndExit(GetOutputFormat())
 			}
-			// Convert to absolute path if relative
-			if !filepath.IsAbs(file) {
-				absPath, err := filepath.Abs(file)
-				if err == nil {
-					file = absPath
-				}
+			// Translate the argument to the path the binary was compiled with
+			bp.File, resolvedBy = resolveSourceFile(c, file)
+			if resolvedBy != "" {
+				requestedFile = file
 			}
-			bp.File = file
 			bp.Line = line
 		} else {
-			bp.FunctionName = location
+			// Shorthand such as Type.Method is normalized to the runtime name
+			name, err := resolveFunctionLocation(c, location)
+			if err != nil {
+				output.Error("break", err).PrintAndExit(GetOutputFormat())
+			}
+			bp.FunctionName = name
+			if name != location {
+				requestedFunction = location
+			}
 		}
 
 		// Add condition if specified
-		if breakCond != "" {
-			bp.Cond = breakCond
+		bp.Cond = cond
+		bp.HitCond = hitCond
+		bp.Stacktrace = breakStacktrace
+
+		// Collected expressions are diffed between consecutive hits by continue
+		if breakCollectDiff != "" {
+			bp.Variables = []string{breakCollectDiff}
 		}
 
-		created, err := c.CreateBreakpoint(bp)
+		create := c.CreateBreakpoint
+		if breakSuspended {
+			create = c.CreateSuspendedBreakpoint
+		}
+		created, err := create(bp)
 		if err != nil {
+			if info := unresolvedFileError(c, bp, err); info != nil {
+				output.ErrorWithInfo("break", info).PrintAndExit(GetOutputFormat())
+			}
 			output.Error("break", err).PrintAndExit(GetOutputFormat())
 		}
 
@@ -83,8 +278,53 @@ Examples:
 		if created.Cond != "" {
 			data["condition"] = created.Cond
 		}
+		if cond != breakCond {
+			data["conditionSource"] = breakCond
+		}
+		if created.HitCond != "" {
+			data["hitCondition"] = created.HitCond
+		}
+		if len(created.Variables) > 0 {
+			data["collectDiff"] = created.Variables
+		}
+		// A breakpoint that did not resolve where it was asked for is set but
+		// would silently never trigger there
+		if resolvedBy != "" {
+			data["requestedFile"] = requestedFile
+			data["resolvedBy"] = resolvedBy
+		}
+		if requestedFunction != "" {
+			data["requestedFunction"] = requestedFunction
+		}
+		warnings := breakpointWarnings(bp, created)
+		if breakSuspended && len(created.Addrs) == 0 && created.Addr == 0 {
+			// Waiting for a plugin to be loaded is what was asked for
+			warnings = withoutWarning(warnings, warnBreakpointUnresolved)
+			data["suspended"] = true
+		}
+		if len(warnings) > 0 {
+			data["warnings"] = warnings
+		}
+		if breakDump {
+			saveDumpBreakpoint(c.Addr(), created.ID, breakDumpFilter)
+			data["dumpGoroutines"] = true
+			if breakDumpFilter != "" {
+				data["dumpFilter"] = breakDumpFilter
+			}
+		}
+		if breakStacktrace > 0 {
+			saveStackBreakpoint(c.Addr(), created.ID, breakStacktrace)
+			data["stacktrace"] = breakStacktrace
+		}
 
-		output.Success("break", data, fmt.Sprintf("Breakpoint %d set", created.ID)).PrintAndExit(GetOutputFormat())
+		msg := fmt.Sprintf("Breakpoint %d set", created.ID)
+		if data["suspended"] == true {
+			msg += ", suspended until a plugin providing it is loaded"
+		}
+		if len(warnings) > 0 {
+			msg += fmt.Sprintf(" with warning: %s", warnings[0].Message)
+		}
+		output.Success("break", data, msg).PrintAndExit(GetOutputFormat())
 	},
 }
 
@@ -139,6 +379,7 @@ Example:
 			output.Error("breakpoints", err).PrintAndExit(GetOutputFormat())
 		}
 
+		dumps := loadDumpBreakpoints(c.Addr())
 		breakpoints := make([]map[string]any, 0, len(bps))
 		for _, bp := range bps {
 			// Skip internal breakpoints (negative IDs or special names)
@@ -156,9 +397,27 @@ Example:
 			if bp.Cond != "" {
 				bpData["condition"] = bp.Cond
 			}
+			if bp.HitCond != "" {
+				bpData["hitCondition"] = bp.HitCond
+			}
+			if len(bp.Variables) > 0 {
+				bpData["collectDiff"] = bp.Variables
+			}
+			if bp.Stacktrace > 0 {
+				bpData["stacktrace"] = bp.Stacktrace
+			}
+			if d, ok := dumps[bp.ID]; ok {
+				bpData["dumpGoroutines"] = true
+				if d.Filter != "" {
+					bpData["dumpFilter"] = d.Filter
+				}
+			}
 			if bp.TotalHitCount > 0 {
 				bpData["hitCount"] = bp.TotalHitCount
 			}
+			if len(bp.Addrs) == 0 && bp.Addr == 0 {
+				bpData["unresolved"] = true
+			}
 			breakpoints = append(breakpoints, bpData)
 		}
 
@@ -177,4 +436,16 @@ func init() {
 	rootCmd.AddCommand(breakpointsCmd)
 
 	breakCmd.Flags().StringVar(&breakCond, "cond", "", "Conditional expression")
+	breakCmd.Flags().StringVar(&breakHitCond, "hitcond", "", "Hit count condition, e.g. \"== 100\" or \"% 10\"")
+	breakCmd.Flags().StringVar(&breakCollectDiff, "collect-diff", "", "Expression to collect at each hit; continue stops when it changed since the previous hit")
+	breakCmd.Flags().StringVar(&breakAssign, "assign", "", "Break at every statement that writes this variable or field")
+	breakCmd.Flags().StringSliceVar(&breakChanOps, "chan-op", nil, "Break on these operations (send, recv, close) on the --expr channel")
+	breakCmd.Flags().StringVar(&breakExpr, "expr", "", "Channel expression for --chan-op")
+	breakCmd.Flags().BoolVar(&breakDump, "dump-goroutines", false, "Record a goroutine summary at each hit instead of stopping continue")
+	breakCmd.Flags().BoolVar(&breakOnPanic, "on-panic", false, "Stop on unrecovered panics and fatal runtime errors (same as panics on)")
+	breakCmd.Flags().StringVar(&breakDumpFilter, "dump-filter", "", "Only summarize goroutines whose user location contains this text (implies --dump-goroutines)")
+	breakCmd.Flags().IntVar(&breakStacktrace, "stacktrace", 0, "Record the call path (this many frames) at each hit instead of stopping continue")
+	breakCmd.Flags().StringVar(&breakRegex, "regex", "", "Break at the entry of every function whose name matches this regexp")
+	breakCmd.Flags().StringVar(&breakType, "type", "", "Break at the entry of every method of this type")
+	breakCmd.Flags().BoolVar(&breakSuspended, "suspended", false, "Keep the breakpoint if the location is not found and set it when a plugin providing it is loaded")
 }
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// hasFlag reports whether a -gcflags or -ldflags value contains flag, ignoring
+// package patterns such as all=
+func hasFlag(value, flag string) bool {
+	for _, f := range strings.Fields(value) {
+		if _, after, ok := strings.Cut(f, "="); ok && !strings.HasPrefix(f, "-") {
+			f = after
+		}
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDWARF reports whether the binary carries DWARF debug information
+func hasDWARF(path string) bool {
+	if f, err := elf.Open(path); err == nil {
+		defer func() { _ = f.Close() }()
+		return f.Section(".debug_info") != nil || f.Section(".zdebug_info") != nil
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer func() { _ = f.Close() }()
+		return f.Section("__debug_info") != nil || f.Section("__zdebug_info") != nil
+	}
+	if f, err := pe.Open(path); err == nil {
+		defer func() { _ = f.Close() }()
+		return f.Section(".debug_info") != nil || f.Section(".zdebug_info") != nil
+	}
+	return false
+}
+
+// targetBinary finds the executable of the session's debugged process
+func targetBinary(c *debugger.Client) (string, error) {
+	// A remote server's PIDs are not this host's
+	if runtime.GOOS == "linux" && serverIsLocal(c.Addr()) {
+		if pid, err := c.ProcessPid(); err == nil && pid > 0 {
+			if exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil {
+				return exe, nil
+			}
+		}
+	}
+	// Only exec sessions know their binary from the launch record
+	if s, err := session.Load(c.Addr()); err == nil && s.Mode == string(debugger.ModeExec) {
+		return s.Target, nil
+	}
+	return "", output.NotFound("target binary", "pass the binary path as an argument")
+}
+
+// buildInfoData reads the build information embedded in a Go binary and
+// derives what it means for debugging
+func buildInfoData(path string) (map[string]any, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, output.InvalidArgumentWithDetails(
+			err.Error(),
+			map[string]any{"binary": path},
+		)
+	}
+
+	settings := map[string]string{}
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+
+	optimized := !hasFlag(settings["-gcflags"], "-N")
+	inlined := !hasFlag(settings["-gcflags"], "-l")
+	stripped := hasFlag(settings["-ldflags"], "-s") || hasFlag(settings["-ldflags"], "-w")
+	dwarf := hasDWARF(path)
+
+	data := map[string]any{
+		"binary":        path,
+		"goVersion":     info.GoVersion,
+		"path":          info.Path,
+		"module":        map[string]any{"path": info.Main.Path, "version": info.Main.Version, "sum": info.Main.Sum},
+		"dependencies":  len(info.Deps),
+		"settings":      settings,
+		"optimizations": optimized,
+		"inlining":      inlined,
+		"race":          settings["-race"] == "true",
+		"cgo":           settings["CGO_ENABLED"] == "1",
+		"stripped":      stripped,
+		"debugSymbols":  dwarf,
+	}
+	if settings["vcs"] != "" {
+		data["vcs"] = map[string]any{
+			"system":   settings["vcs"],
+			"revision": settings["vcs.revision"],
+			"time":     settings["vcs.time"],
+			"modified": settings["vcs.modified"] == "true",
+		}
+	}
+
+	var warnings []string
+	if !dwarf {
+		warnings = append(warnings, "no DWARF debug info: breakpoints by file:line and variable inspection will not work")
+	}
+	if optimized {
+		warnings = append(warnings, "built with optimizations: variables may be unavailable and stepping may jump between lines; rebuild with -gcflags='all=-N -l'")
+	}
+	if inlined {
+		warnings = append(warnings, "inlining enabled: inlined functions have no frame of their own and breakpoints on them may not trigger")
+	}
+	if len(warnings) > 0 {
+		data["warnings"] = warnings
+	}
+	return data, nil
+}
+
+// Debuggability levels reported by start and connect
+const (
+	debuggabilityFull     = "full"     // DWARF present, built without optimizations and inlining
+	debuggabilityDegraded = "degraded" // DWARF present but optimized or inlined
+	debuggabilityNone     = "none"     // no DWARF: source-level debugging is impossible
+)
+
+// debuggability rates how well the binary at path can be debugged and advises
+// how to fix what limits it. It returns nil if the binary cannot be read.
+func debuggability(path string) map[string]any {
+	info, err := buildInfoData(path)
+	if err != nil {
+		if hasDWARF(path) {
+			return nil
+		}
+		info = map[string]any{"debugSymbols": false}
+	}
+
+	level := debuggabilityFull
+	var advice []string
+	switch {
+	case info["debugSymbols"] == false:
+		level = debuggabilityNone
+		advice = append(advice, "rebuild without -ldflags='-s -w' and without stripping the binary")
+	case info["optimizations"] == true || info["inlining"] == true:
+		level = debuggabilityDegraded
+		advice = append(advice, "rebuild with -gcflags='all=-N -l', or use godebug start on the package instead of --mode exec")
+	}
+
+	data := map[string]any{
+		"level":  level,
+		"binary": path,
+	}
+	if w, ok := info["warnings"]; ok {
+		data["warnings"] = w
+	}
+	if len(advice) > 0 {
+		data["advice"] = advice
+	}
+	return data
+}
+
+// sessionDebuggability assesses the binary of the session at addr. Assessment
+// is best effort: nil means it could not be determined.
+func sessionDebuggability(addr string) map[string]any {
+	c, err := debugger.Connect(addr)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = c.Close() }()
+	path, err := targetBinary(c)
+	if err != nil {
+		return nil
+	}
+	return debuggability(path)
+}
+
+// addBuildInfoCommand adds the buildinfo command
+func addBuildInfoCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	buildInfoCmd := &cobra.Command{
+		Use:   "buildinfo [binary]",
+		Short: "Show how the target binary was built",
+		Long: `Report the module, version and VCS stamp embedded in a Go binary, its build
+settings, and whether it was built with optimizations, inlining, the race
+detector, cgo and DWARF debug info. Warnings explain which debugging
+operations the build limits.
+
+Without an argument the binary of the current session is inspected.
+
+Example:
+  godebug --addr $ADDR buildinfo
+  godebug buildinfo ./bin/myapp`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var path string
+			if len(args) > 0 {
+				path = args[0]
+			} else {
+				c := mustGetClient("buildinfo")
+				var err error
+				path, err = targetBinary(c)
+				_ = c.Close()
+				if err != nil {
+					output.Error("buildinfo", err).PrintAndExit(getOutputFormat())
+				}
+			}
+
+			data, err := buildInfoData(path)
+			if err != nil {
+				output.Error("buildinfo", err).PrintAndExit(getOutputFormat())
+			}
+
+			output.Success("buildinfo", data, fmt.Sprintf("%s built with %s", path, data["goVersion"])).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	root.AddCommand(buildInfoCmd)
+}
+
+func init() {
+	addBuildInfoCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// callSnapshot reads what a call could visibly change: the arguments and
+// locals of the goroutine's topmost frame, the --watch expressions and the
+// number of goroutines. Values that cannot be read are left out.
+func callSnapshot(c *debugger.Client, goroutineID int64, watches []string) (map[string]string, int) {
+	cfg := debugger.DefaultLoadConfig()
+	scope := api.EvalScope{GoroutineID: goroutineID}
+	var vars []api.Variable
+	if args, err := c.ListFunctionArgsInScope(scope, cfg); err == nil {
+		vars = append(vars, args...)
+	}
+	if locals, err := c.ListLocalVarsInScope(scope, cfg); err == nil {
+		vars = append(vars, locals...)
+	}
+	for _, expr := range watches {
+		if v, err := c.EvalInScope(scope, expr, cfg); err == nil {
+			v.Name = expr
+			vars = append(vars, *v)
+		}
+	}
+	goroutines := -1
+	if gs, _, err := c.ListGoroutines(0, 0); err == nil {
+		goroutines = len(gs)
+	}
+	return flattenVariables(vars), goroutines
+}
+
+// addCallCommand adds the call command
+func addCallCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var callGoroutine int64
+	var callWatches []string
+
+	callCmd := &cobra.Command{
+		Use:   "call <expr>",
+		Short: "Call a function in the target and report its result and side effects",
+		Long: `Run a function or method call in the target, like eval --allow-calls, to
+test a hypothesis interactively: does u.Validate() fail for this user, what
+does cache.Get(k) return now?
+
+The call runs in the selected goroutine's topmost frame and resumes the
+target until it returns. The result is reported like eval (several return
+values in data.values, panicked if the call panicked), with:
+  calls, sideEffects  the name-based assessment of each call (see eval)
+  changes             arguments, locals and --watch expressions whose value
+                      changed during the call
+  goroutines          the goroutine count before and after, if it changed
+
+A call that hits a breakpoint stops there; continue to finish it.
+
+Example:
+  godebug --addr $ADDR call "u.Validate()"
+  godebug --addr $ADDR call "cache.Put(k, v)" --watch cache.size --watch "len(cache.items)"`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			expr := args[0]
+			calls, err := findCalls(expr)
+			if err != nil {
+				output.ErrorWithInfo("call", output.EvalFailed(expr, err)).PrintAndExit(getOutputFormat())
+			}
+			if len(calls) == 0 {
+				output.ErrorWithInfo("call", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("expression '%s' calls no function; use eval", expr),
+					map[string]any{"expression": expr},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("call")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("call", err).PrintAndExit(getOutputFormat())
+			}
+			goroutineID, ok := targetGoroutine(state, callGoroutine)
+			if !ok {
+				output.ErrorWithInfo("call", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+			}
+			if frame := mustSelectedFrame(c, "call", state, goroutineID, getOutputFormat); frame > 0 {
+				output.ErrorWithInfo("call", output.InvalidArgumentWithDetails(
+					"calls run in the goroutine's topmost frame; select frame 0 first",
+					map[string]any{"frame": frame},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			before, goroutinesBefore := callSnapshot(c, goroutineID, callWatches)
+			data, err := evalWithCalls(c, goroutineID, expr)
+			if err != nil {
+				output.Error("call", err).PrintAndExit(getOutputFormat())
+			}
+			after, goroutinesAfter := callSnapshot(c, goroutineID, callWatches)
+
+			changes := diffValues(before, after)
+			data["changes"] = changes
+			if goroutinesBefore != goroutinesAfter && goroutinesBefore >= 0 && goroutinesAfter >= 0 {
+				data["goroutines"] = map[string]any{"before": goroutinesBefore, "after": goroutinesAfter}
+			}
+			if callGoroutine > 0 {
+				data["goroutineId"] = goroutineID
+			}
+
+			msg := "Call returned"
+			switch {
+			case data["panicked"] == true:
+				msg = "Call panicked"
+			case len(changes) > 0:
+				msg = fmt.Sprintf("Call returned and changed %d values", len(changes))
+			case data["sideEffects"] == sideEffectsPossible:
+				msg = "Call returned; no visible changes, but it may have changed state elsewhere"
+			}
+			output.Success("call", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	callCmd.Flags().Int64Var(&callGoroutine, "goroutine", 0, "Goroutine to run the call on (default: selected)")
+	callCmd.Flags().StringArrayVar(&callWatches, "watch", nil, "Also report changes of this expression (repeatable)")
+	root.AddCommand(callCmd)
+}
+
+func init() {
+	addCallCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// chanOpFunctions are the runtime functions every operation of a kind goes
+// through, except those in a select with several cases (runtime.selectgo).
+// Each takes the channel as its parameter c.
+var chanOpFunctions = map[string]string{
+	"send":  "runtime.chansend",
+	"recv":  "runtime.chanrecv",
+	"close": "runtime.closechan",
+}
+
+// chanOpNames lists the operations in a stable order
+var chanOpNames = []string{"send", "recv", "close"}
+
+// chanOpArgsError checks the break arguments used with --chan-op
+func chanOpArgsError(args, ops []string, expr, assign string, dump bool) *output.ErrorInfo {
+	if len(ops) == 0 {
+		if expr != "" {
+			return output.InvalidArgument("--expr is only used with --chan-op")
+		}
+		return nil
+	}
+	if len(args) > 0 || assign != "" || dump {
+		return output.InvalidArgument("--chan-op cannot be combined with a location, --assign or --dump-goroutines")
+	}
+	if expr == "" {
+		return output.InvalidArgument("--chan-op requires --expr naming the channel")
+	}
+	for _, op := range ops {
+		if _, ok := chanOpFunctions[op]; !ok {
+			return output.InvalidArgumentWithDetails(
+				fmt.Sprintf("invalid --chan-op: %s", op),
+				map[string]any{"chanOp": op, "valid": chanOpNames},
+			)
+		}
+	}
+	return nil
+}
+
+// chanOpCondition matches calls on the channel whose runtime.hchan is at
+// base; a nil channel has base 0
+func chanOpCondition(base uint64, cond string) string {
+	match := "c == nil"
+	if base != 0 {
+		match = fmt.Sprintf("c == (*runtime.hchan)(%#x)", base)
+	}
+	if cond == "" {
+		return match
+	}
+	return fmt.Sprintf("%s && (%s)", match, cond)
+}
+
+// breakOnChanOps evaluates expr to a channel in the selected goroutine and
+// frame, then sets a breakpoint in the runtime function of each operation,
+// filtered to that channel
+func breakOnChanOps(c *debugger.Client, ops []string, expr, cond string) (map[string]any, error) {
+	state, err := c.GetState()
+	if err != nil {
+		return nil, err
+	}
+	// Without a selected goroutine only package variables can be evaluated
+	scope := api.EvalScope{GoroutineID: -1}
+	if goroutineID, ok := targetGoroutine(state, 0); ok {
+		scope = api.EvalScope{GoroutineID: goroutineID, Frame: selectedFrame(c.Addr(), state, goroutineID)}
+	}
+	v, err := c.EvalInScope(scope, expr, debugger.DefaultLoadConfig())
+	if err != nil {
+		return nil, err
+	}
+	if v.Kind != reflect.Chan {
+		return nil, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("'%s' is not a channel", expr),
+			map[string]any{"expr": expr, "type": v.Type},
+		)
+	}
+
+	var results itemResults
+	for _, op := range chanOpNames {
+		if !slices.Contains(ops, op) {
+			continue
+		}
+		bp := &api.Breakpoint{FunctionName: chanOpFunctions[op], Cond: chanOpCondition(v.Base, cond)}
+		created, err := c.CreateBreakpoint(bp)
+		if err != nil {
+			results.fail(map[string]any{
+				"op":       op,
+				"function": bp.FunctionName,
+			}, err)
+			continue
+		}
+		results.ok(map[string]any{
+			"id":       created.ID,
+			"op":       op,
+			"function": created.FunctionName,
+		})
+	}
+
+	data := map[string]any{
+		"chanOp":  ops,
+		"expr":    expr,
+		"type":    v.Type,
+		"channel": fmt.Sprintf("%#x", v.Base),
+		"count":   len(results.succeeded),
+	}
+	results.apply(data, "breakpoints")
+	if cond != "" {
+		data["condition"] = cond
+	}
+	if info := results.allFailed("breakpoints", data); info != nil {
+		return nil, info
+	}
+	if v.Base == 0 {
+		data["warnings"] = []breakpointWarning{{
+			Code:    warnBreakpointNilChannel,
+			Message: fmt.Sprintf("%s is nil: the breakpoints stop on %s of any nil channel", expr, strings.Join(ops, "/")),
+		}}
+	}
+	return data, nil
+}
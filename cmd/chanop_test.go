@@ -0,0 +1,39 @@
+package cmd
+
+import "testing"
+
+// TestChanOpCondition checks that the condition matches the channel's address
+// and keeps a user condition
+func TestChanOpCondition(t *testing.T) {
+	if got := chanOpCondition(0xc000010000, ""); got != "c == (*runtime.hchan)(0xc000010000)" {
+		t.Errorf("channel: %s", got)
+	}
+	if got := chanOpCondition(0, "block"); got != "c == nil && (block)" {
+		t.Errorf("nil channel with --cond: %s", got)
+	}
+}
+
+// TestChanOpArgsError checks the flags --chan-op needs and excludes
+func TestChanOpArgsError(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		ops    []string
+		expr   string
+		assign string
+		ok     bool
+	}{
+		{"valid", nil, []string{"send", "close"}, "w.tasks", "", true},
+		{"no chan-op", []string{"main.go:1"}, nil, "", "", true},
+		{"expr without chan-op", []string{"main.go:1"}, nil, "w.tasks", "", false},
+		{"missing expr", nil, []string{"send"}, "", "", false},
+		{"unknown op", nil, []string{"select"}, "w.tasks", "", false},
+		{"with location", []string{"main.go:1"}, []string{"send"}, "w.tasks", "", false},
+		{"with assign", nil, []string{"recv"}, "w.tasks", "counter", false},
+	}
+	for _, tt := range tests {
+		if info := chanOpArgsError(tt.args, tt.ops, tt.expr, tt.assign, false); (info == nil) != tt.ok {
+			t.Errorf("%s: got %v", tt.name, info)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// checkpointToMap converts a checkpoint for JSON output. When is rr's event
+// number of the checkpoint.
+func checkpointToMap(cp api.Checkpoint) map[string]any {
+	m := map[string]any{
+		"id":   cp.ID,
+		"when": cp.When,
+	}
+	if cp.Where != "" {
+		m["where"] = cp.Where
+	}
+	return m
+}
+
+// checkpointNotFound reports an unknown checkpoint ID with the existing ones
+func checkpointNotFound(id string, cps []api.Checkpoint) *output.ErrorInfo {
+	ids := make([]int, len(cps))
+	for i, cp := range cps {
+		ids[i] = cp.ID
+	}
+	return output.NotFound("checkpoint", id).WithDetails(map[string]any{"checkpoints": ids})
+}
+
+// hasCheckpoint reports whether a checkpoint with the ID exists
+func hasCheckpoint(cps []api.Checkpoint, id int) bool {
+	for _, cp := range cps {
+		if cp.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// restartFromCheckpoint moves the recording back to checkpoint id for
+// restart --from-checkpoint
+func restartFromCheckpoint(c *debugger.Client, id int) (*api.DebuggerState, error) {
+	if info := recordingError(c.Addr(), "checkpoints"); info != nil {
+		return nil, info
+	}
+	if cps, err := c.ListCheckpoints(); err == nil && !hasCheckpoint(cps, id) {
+		return nil, checkpointNotFound(strconv.Itoa(id), cps)
+	}
+	return c.RestartFromCheckpoint(id)
+}
+
+// addCheckpointCommands adds checkpoint, checkpoints and checkpoint-clear
+func addCheckpointCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	checkpointCmd := &cobra.Command{
+		Use:   "checkpoint [note]",
+		Short: "Save the current point of an rr recording",
+		Long: `Save the current point of the execution as a checkpoint, with an optional
+note, so restart --from-checkpoint can come back to it instead of replaying
+from the start. Checkpoints need a session started with --backend rr.
+
+Example:
+  godebug --addr $ADDR checkpoint "before the second flush"
+  godebug --addr $ADDR restart --from-checkpoint 1`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("checkpoint")
+			defer func() { _ = c.Close() }()
+			if info := recordingError(c.Addr(), "checkpoints"); info != nil {
+				output.ErrorWithInfo("checkpoint", info).PrintAndExit(getOutputFormat())
+			}
+
+			where := ""
+			if len(args) > 0 {
+				where = args[0]
+			}
+			cp, err := c.CreateCheckpoint(where)
+			if err != nil {
+				output.Error("checkpoint", err).PrintAndExit(getOutputFormat())
+			}
+
+			data := map[string]any{"id": cp.ID}
+			if where != "" {
+				data["where"] = where
+			}
+			if state, err := c.GetState(); err == nil && state.SelectedGoroutine != nil {
+				loc := state.SelectedGoroutine.CurrentLoc
+				data["location"] = map[string]any{
+					"file":     loc.File,
+					"line":     loc.Line,
+					"function": loc.Function.Name(),
+				}
+			}
+			output.Success("checkpoint", data, fmt.Sprintf("Checkpoint %d created", cp.ID)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	checkpointsCmd := &cobra.Command{
+		Use:   "checkpoints",
+		Short: "List the checkpoints of an rr recording",
+		Long: `List the checkpoints saved with checkpoint: their ID, rr event number
+(when) and note (where).
+
+Example:
+  godebug --addr $ADDR checkpoints`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("checkpoints")
+			defer func() { _ = c.Close() }()
+			if info := recordingError(c.Addr(), "checkpoints"); info != nil {
+				output.ErrorWithInfo("checkpoints", info).PrintAndExit(getOutputFormat())
+			}
+
+			cps, err := c.ListCheckpoints()
+			if err != nil {
+				output.Error("checkpoints", err).PrintAndExit(getOutputFormat())
+			}
+			list := make([]map[string]any, len(cps))
+			for i, cp := range cps {
+				list[i] = checkpointToMap(cp)
+			}
+			data := map[string]any{
+				"checkpoints": list,
+				"count":       len(list),
+			}
+			output.Success("checkpoints", data, fmt.Sprintf("%d checkpoints", len(list))).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	clearCmd := &cobra.Command{
+		Use:   "checkpoint-clear <id>",
+		Short: "Delete a checkpoint",
+		Long: `Delete the checkpoint with the given ID (see checkpoints).
+
+Example:
+  godebug --addr $ADDR checkpoint-clear 1`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				output.ErrorWithInfo("checkpoint-clear", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid checkpoint ID: %s", args[0]),
+					map[string]any{"id": args[0]},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("checkpoint-clear")
+			defer func() { _ = c.Close() }()
+			if info := recordingError(c.Addr(), "checkpoints"); info != nil {
+				output.ErrorWithInfo("checkpoint-clear", info).PrintAndExit(getOutputFormat())
+			}
+
+			if cps, err := c.ListCheckpoints(); err == nil && !hasCheckpoint(cps, id) {
+				output.ErrorWithInfo("checkpoint-clear", checkpointNotFound(args[0], cps)).PrintAndExit(getOutputFormat())
+			}
+			if err := c.ClearCheckpoint(id); err != nil {
+				output.Error("checkpoint-clear", err).PrintAndExit(getOutputFormat())
+			}
+			output.Success("checkpoint-clear", map[string]any{"id": id}, fmt.Sprintf("Checkpoint %d deleted", id)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	root.AddCommand(checkpointCmd)
+	root.AddCommand(checkpointsCmd)
+	root.AddCommand(clearCmd)
+}
+
+func init() {
+	addCheckpointCommands(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// TestCheckpointNotFound checks an unknown ID is reported with the IDs that
+// exist.
+func TestCheckpointNotFound(t *testing.T) {
+	cps := []api.Checkpoint{{ID: 1, When: "1204", Where: "before flush"}, {ID: 3, When: "5310"}}
+	if !hasCheckpoint(cps, 3) || hasCheckpoint(cps, 2) {
+		t.Errorf("hasCheckpoint: wrong result for %v", cps)
+	}
+	info := checkpointNotFound("2", cps)
+	if info.Code != output.ErrCodeNotFound {
+		t.Errorf("code %s, want %s", info.Code, output.ErrCodeNotFound)
+	}
+	details, _ := info.Details.(map[string]any)
+	if ids, _ := details["checkpoints"].([]int); len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("checkpoints %v, want [1 3]", details["checkpoints"])
+	}
+	if m := checkpointToMap(cps[1]); m["where"] != nil {
+		t.Errorf("checkpoint without a note has where: %v", m)
+	}
+}
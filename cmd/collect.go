@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// collectedFile stores the last collected values per breakpoint in the session directory
+const collectedFile = "collected.json"
+
+// collectedHit is the last value collected at a breakpoint, flattened to path -> value
+type collectedHit struct {
+	Hit    uint64            `json:"hit"`
+	Values map[string]string `json:"values"`
+}
+
+// flattenVariable turns a variable tree into leaf paths such as order.Items[2].Qty
+func flattenVariable(path string, v api.Variable, out map[string]string) {
+	if len(v.Children) == 0 || v.Kind == reflect.String {
+		out[path] = v.Value
+		if v.Unreadable != "" {
+			out[path] = "<unreadable: " + v.Unreadable + ">"
+		}
+		return
+	}
+
+	switch v.Kind {
+	case reflect.Ptr, reflect.Interface:
+		// Follow through pointers and interfaces transparently
+		flattenVariable(path, v.Children[0], out)
+	case reflect.Map:
+		// Map children alternate key, value
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			key := v.Children[i]
+			flattenVariable(fmt.Sprintf("%s[%s]", path, key.SinglelineString()), v.Children[i+1], out)
+		}
+	case reflect.Slice, reflect.Array:
+		for i, child := range v.Children {
+			flattenVariable(fmt.Sprintf("%s[%d]", path, i), child, out)
+		}
+	default:
+		for _, child := range v.Children {
+			flattenVariable(path+"."+child.Name, child, out)
+		}
+	}
+	if v.Len > int64(len(v.Children)) && (v.Kind == reflect.Slice || v.Kind == reflect.Array || v.Kind == reflect.Map) {
+		out[path+".len"] = fmt.Sprintf("%d", v.Len)
+	}
+}
+
+// diffValues compares two flattened values and returns one entry per changed path
+func diffValues(prev, cur map[string]string) []map[string]any {
+	paths := make([]string, 0, len(cur))
+	for p := range cur {
+		paths = append(paths, p)
+	}
+	for p := range prev {
+		if _, ok := cur[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	diff := make([]map[string]any, 0)
+	for _, p := range paths {
+		oldVal, hadOld := prev[p]
+		newVal, hasNew := cur[p]
+		switch {
+		case !hadOld:
+			diff = append(diff, map[string]any{"path": p, "change": "added", "new": newVal})
+		case !hasNew:
+			diff = append(diff, map[string]any{"path": p, "change": "removed", "old": oldVal})
+		case oldVal != newVal:
+			diff = append(diff, map[string]any{"path": p, "change": "modified", "old": oldVal, "new": newVal})
+		}
+	}
+	return diff
+}
+
+// collectChanged reports whether the results of a --collect-diff hit stop
+// continue: a value changed since the previous hit or could not be evaluated
+func collectChanged(results []map[string]any) bool {
+	for _, r := range results {
+		if r["error"] != nil {
+			return true
+		}
+		if n, _ := r["changed"].(int); n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// collectedDiff evaluates the expressions collected by the breakpoint we stopped at
+// and returns the changes since the previous hit, or nil if nothing is collected here
+func collectedDiff(c *debugger.Client, state *api.DebuggerState) []map[string]any {
+	if state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil || state.SelectedGoroutine == nil {
+		return nil
+	}
+	bp := state.CurrentThread.Breakpoint
+	if len(bp.Variables) == 0 {
+		return nil
+	}
+
+	stored := map[string]collectedHit{}
+	_ = session.LoadData(c.Addr(), collectedFile, &stored)
+
+	results := make([]map[string]any, 0, len(bp.Variables))
+	for _, expr := range bp.Variables {
+		key := fmt.Sprintf("%d:%s", bp.ID, expr)
+		result := map[string]any{
+			"breakpointId": bp.ID,
+			"expression":   expr,
+			"hit":          bp.TotalHitCount,
+		}
+
+		v, err := c.Eval(state.SelectedGoroutine.ID, 0, expr, debugger.DefaultLoadConfig())
+		if err != nil {
+			result["error"] = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		cur := map[string]string{}
+		flattenVariable(expr, *v, cur)
+
+		if prev, ok := stored[key]; ok {
+			diff := diffValues(prev.Values, cur)
+			result["previousHit"] = prev.Hit
+			result["diff"] = diff
+			result["changed"] = len(diff)
+		} else {
+			// First hit: there is nothing to compare with yet, so report the baseline
+			result["first"] = true
+			result["value"] = variableToMap(*v)
+		}
+		stored[key] = collectedHit{Hit: bp.TotalHitCount, Values: cur}
+		results = append(results, result)
+	}
+
+	_ = session.SaveData(c.Addr(), collectedFile, stored)
+	return results
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestFlattenVariable checks the leaf paths of nested structs, maps,
+// slices, pointers and partially loaded collections.
+func TestFlattenVariable(t *testing.T) {
+	item := func(qty string) api.Variable {
+		return api.Variable{Kind: reflect.Struct, Children: []api.Variable{
+			{Name: "Qty", Kind: reflect.Int, Value: qty},
+		}}
+	}
+	tests := []struct {
+		name string
+		v    api.Variable
+		want map[string]string
+	}{
+		{
+			name: "scalar",
+			v:    api.Variable{Kind: reflect.Int, Value: "3"},
+			want: map[string]string{"v": "3"},
+		},
+		{
+			name: "nested struct behind a pointer",
+			v: api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct, Children: []api.Variable{
+				{Name: "ID", Kind: reflect.Int, Value: "7"},
+				{Name: "Customer", Kind: reflect.Struct, Children: []api.Variable{
+					{Name: "Name", Kind: reflect.String, Value: "ada", Len: 3},
+				}},
+			}}}},
+			want: map[string]string{"v.ID": "7", "v.Customer.Name": "ada"},
+		},
+		{
+			name: "slice of structs",
+			v:    api.Variable{Kind: reflect.Slice, Len: 2, Children: []api.Variable{item("1"), item("2")}},
+			want: map[string]string{"v[0].Qty": "1", "v[1].Qty": "2"},
+		},
+		{
+			name: "partially loaded slice",
+			v:    api.Variable{Kind: reflect.Slice, Len: 100, Children: []api.Variable{item("1")}},
+			want: map[string]string{"v[0].Qty": "1", "v.len": "100"},
+		},
+		{
+			name: "map",
+			v: api.Variable{Kind: reflect.Map, Len: 1, Children: []api.Variable{
+				{Kind: reflect.String, Value: "a", Len: 1}, {Kind: reflect.Int, Value: "1"},
+			}},
+			want: map[string]string{`v["a"]`: "1"},
+		},
+		{
+			name: "unreadable",
+			v:    api.Variable{Kind: reflect.Int, Unreadable: "no memory"},
+			want: map[string]string{"v": "<unreadable: no memory>"},
+		},
+	}
+	for _, tt := range tests {
+		got := map[string]string{}
+		flattenVariable("v", tt.v, got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: flattenVariable = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestDiffValues checks added, removed and modified paths, including a
+// slice that grows and shrinks and a first hit with no previous value.
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur map[string]string
+		want      []map[string]any
+	}{
+		{
+			name: "first hit",
+			prev: nil,
+			cur:  map[string]string{"v": "1"},
+			want: []map[string]any{{"path": "v", "change": "added", "new": "1"}},
+		},
+		{
+			name: "unchanged",
+			prev: map[string]string{"v.ID": "7"},
+			cur:  map[string]string{"v.ID": "7"},
+			want: []map[string]any{},
+		},
+		{
+			name: "nested field modified",
+			prev: map[string]string{"v.ID": "7", "v.Customer.Name": "ada"},
+			cur:  map[string]string{"v.ID": "7", "v.Customer.Name": "bob"},
+			want: []map[string]any{{"path": "v.Customer.Name", "change": "modified", "old": "ada", "new": "bob"}},
+		},
+		{
+			name: "slice grows",
+			prev: map[string]string{"v[0]": "1"},
+			cur:  map[string]string{"v[0]": "1", "v[1]": "2"},
+			want: []map[string]any{{"path": "v[1]", "change": "added", "new": "2"}},
+		},
+		{
+			name: "slice shrinks",
+			prev: map[string]string{"v[0]": "1", "v[1]": "2"},
+			cur:  map[string]string{"v[0]": "1"},
+			want: []map[string]any{{"path": "v[1]", "change": "removed", "old": "2"}},
+		},
+		{
+			name: "map key replaced",
+			prev: map[string]string{`v["a"]`: "1"},
+			cur:  map[string]string{`v["b"]`: "1"},
+			want: []map[string]any{
+				{"path": `v["a"]`, "change": "removed", "old": "1"},
+				{"path": `v["b"]`, "change": "added", "new": "1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		if got := diffValues(tt.prev, tt.cur); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: diffValues = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestCollectDiffContinue checks that continue passes --collect-diff hits
+// whose value did not change and stops at the first one that changed.
+func TestCollectDiffContinue(t *testing.T) {
+	setupFuzzTest(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if resp := runFake(t, "break", "main.square", "--collect-diff", "n"); resp["success"] != true {
+		t.Fatalf("break: %v", resp)
+	}
+	resp := runFake(t, "continue")
+	data, _ := resp["data"].(map[string]any)
+	collected, _ := data["collected"].([]any)
+	if len(collected) != 1 || data["unchangedHits"] != float64(1) {
+		t.Fatalf("continue = %v, want one unchanged hit and a diff", resp)
+	}
+	want := []any{map[string]any{"path": "n", "change": "modified", "old": "1", "new": "2"}}
+	if diff := collected[0].(map[string]any)["diff"]; !reflect.DeepEqual(diff, want) {
+		t.Errorf("diff = %v, want %v", diff, want)
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// flattenVariables flattens each variable under its own name
+func flattenVariables(vars []api.Variable) map[string]string {
+	flat := map[string]string{}
+	for _, v := range vars {
+		flattenVariable(v.Name, v, flat)
+	}
+	return flat
+}
+
+// compareInspect runs one inspection against the session at addr and returns
+// its variables and current location
+func compareInspect(addr, inspection string, args []string) ([]api.Variable, map[string]any, error) {
+	c, err := debugger.Connect(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = c.Close() }()
+
+	state, err := c.GetState()
+	if err != nil {
+		return nil, nil, err
+	}
+	if errInfo := validateTransition(inspection, state); errInfo != nil {
+		return nil, nil, errInfo
+	}
+	goroutineID, ok := targetGoroutine(state, 0)
+	if !ok {
+		return nil, nil, output.NotFound("goroutine", "none selected")
+	}
+	location := stateToData(state)
+
+	scope := api.EvalScope{GoroutineID: goroutineID}
+	switch inspection {
+	case "eval":
+		v, err := c.EvalInScope(scope, args[0], debugger.DefaultLoadConfig())
+		if err != nil {
+			return nil, nil, output.EvalFailed(args[0], err)
+		}
+		v.Name = args[0]
+		return []api.Variable{*v}, location, nil
+	case "locals":
+		vars, err := c.ListLocalVarsInScope(scope, debugger.DefaultLoadConfig())
+		return vars, location, err
+	default:
+		vars, err := c.ListFunctionArgsInScope(scope, debugger.DefaultLoadConfig())
+		return vars, location, err
+	}
+}
+
+// addCompareCommand adds the compare command to the root
+func addCompareCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat) {
+	var compareSessions []string
+
+	compareCmd := &cobra.Command{
+		Use:   "compare eval <expr> | locals | args",
+		Short: "Run the same inspection against two sessions and diff the results",
+		Long: `Run one inspection against two simultaneous debug sessions and report
+where the results differ, e.g. an old and a new build of the same program
+stopped at the same breakpoint.
+
+Each --session is the address of a running Delve server. Values are compared
+leaf by leaf (pointers are followed, so addresses never differ). In each
+difference "old" is the first session's value and "new" the second's.
+
+Example:
+  godebug compare --session $OLD --session $NEW eval "config"
+  godebug compare --session $OLD --session $NEW locals`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			inspection := args[0]
+			switch {
+			case len(compareSessions) != 2:
+				output.ErrorWithInfo("compare", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("expected exactly two --session addresses, got %d", len(compareSessions)),
+					map[string]any{"sessions": compareSessions},
+				)).PrintAndExit(getOutputFormat())
+			case inspection != "eval" && inspection != "locals" && inspection != "args":
+				output.ErrorWithInfo("compare", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("unsupported inspection: %s (expected eval, locals or args)", inspection),
+					map[string]any{"inspection": inspection},
+				)).PrintAndExit(getOutputFormat())
+			case inspection == "eval" && len(args) != 2:
+				output.ErrorWithInfo("compare", output.InvalidArgument("eval requires exactly one expression")).PrintAndExit(getOutputFormat())
+			case (inspection == "locals" || inspection == "args") && len(args) != 1:
+				output.ErrorWithInfo("compare", output.InvalidArgument(inspection+" takes no arguments")).PrintAndExit(getOutputFormat())
+			}
+
+			var results [2][]api.Variable
+			sessions := make([]map[string]any, 2)
+			for i, addr := range compareSessions {
+				vars, location, err := compareInspect(addr, inspection, args[1:])
+				if err != nil {
+					errInfo := output.FromError(err)
+					output.ErrorWithInfo("compare", errInfo.WithDetails(map[string]any{
+						"session": addr,
+						"cause":   errInfo.Details,
+					})).PrintAndExit(getOutputFormat())
+				}
+				results[i] = vars
+				values := make([]map[string]any, len(vars))
+				for j, v := range vars {
+					values[j] = variableToMap(v)
+				}
+				sessions[i] = map[string]any{
+					"addr":     addr,
+					"location": location,
+					"values":   values,
+				}
+			}
+
+			diffs := diffValues(flattenVariables(results[0]), flattenVariables(results[1]))
+			data := map[string]any{
+				"inspection":  inspection,
+				"sessions":    sessions,
+				"equal":       len(diffs) == 0,
+				"differences": diffs,
+			}
+			if inspection == "eval" {
+				data["expression"] = args[1]
+			}
+
+			msg := "Results are identical"
+			if len(diffs) > 0 {
+				msg = fmt.Sprintf("%d differences", len(diffs))
+			}
+			output.Success("compare", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	compareCmd.Flags().StringArrayVar(&compareSessions, "session", nil, "Delve server address of a session to compare (give twice)")
+
+	root.AddCommand(compareCmd)
+}
+
+func init() {
+	addCompareCommand(rootCmd, GetOutputFormat)
+}
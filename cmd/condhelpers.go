@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// conditionHelpers are the functions expandCondition rewrites, with their
+// number of arguments
+var conditionHelpers = map[string]int{
+	"approx":   3,
+	"since":    1,
+	"until":    1,
+	"elapsed":  2,
+	"unixnano": 1,
+	"dur":      1,
+}
+
+// timeHelpers are the helpers whose result is a duration in nanoseconds, so a
+// duration string compared with them is converted too
+var timeHelpers = map[string]bool{"since": true, "until": true, "elapsed": true}
+
+// unixNanoExpr reads a time.Time as Unix nanoseconds from its wall and ext
+// fields. With the hasMonotonic bit set, wall holds the seconds since 1885;
+// otherwise ext holds the seconds since year 1. The low 30 bits of wall are
+// the nanoseconds.
+func unixNanoExpr(t string) string {
+	mono := fmt.Sprintf("int64((%s).wall >> 63)", t)
+	sec := fmt.Sprintf("(%s*(int64((%s).wall<<1>>31) - 2682288000) + (1-%s)*((%s).ext - 62135596800))", mono, t, mono, t)
+	return fmt.Sprintf("(%s*1000000000 + int64((%s).wall&0x3fffffff))", sec, t)
+}
+
+// durationLiteral converts a quoted duration such as "2s" to nanoseconds
+func durationLiteral(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatInt(int64(d), 10), true
+}
+
+// isTimeHelper reports whether e is a call of a helper returning a duration
+func isTimeHelper(e ast.Expr) bool {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		e = p.X
+	}
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	id, ok := call.Fun.(*ast.Ident)
+	return ok && timeHelpers[id.Name]
+}
+
+// conditionRewriter expands helpers against a fixed "now"
+type conditionRewriter struct {
+	now     int64
+	helpers []string
+}
+
+// rewrite returns e as a Delve expression with the helpers expanded
+func (r *conditionRewriter) rewrite(e ast.Expr) (string, error) {
+	switch x := e.(type) {
+	case *ast.ParenExpr:
+		inner, err := r.rewrite(x.X)
+		return "(" + inner + ")", err
+	case *ast.UnaryExpr:
+		inner, err := r.rewrite(x.X)
+		return x.Op.String() + inner, err
+	case *ast.BinaryExpr:
+		var operands [2]string
+		for i, side := range []ast.Expr{x.X, x.Y} {
+			other := []ast.Expr{x.Y, x.X}[i]
+			if ns, ok := durationLiteral(side); ok && isTimeHelper(other) {
+				operands[i] = ns
+				continue
+			}
+			s, err := r.rewrite(side)
+			if err != nil {
+				return "", err
+			}
+			operands[i] = s
+		}
+		return operands[0] + " " + x.Op.String() + " " + operands[1], nil
+	case *ast.CallExpr:
+		args := make([]string, len(x.Args))
+		for i, arg := range x.Args {
+			s, err := r.rewrite(arg)
+			if err != nil {
+				return "", err
+			}
+			args[i] = s
+		}
+		id, ok := x.Fun.(*ast.Ident)
+		if !ok {
+			return types.ExprString(x.Fun) + "(" + strings.Join(args, ", ") + ")", nil
+		}
+		n, ok := conditionHelpers[id.Name]
+		if !ok {
+			return id.Name + "(" + strings.Join(args, ", ") + ")", nil
+		}
+		if len(args) != n {
+			return "", fmt.Errorf("%s takes %d arguments, got %d", id.Name, n, len(args))
+		}
+		r.helpers = append(r.helpers, id.Name)
+		switch id.Name {
+		case "approx":
+			return fmt.Sprintf("((%s) - (%s) <= (%s) && (%s) - (%s) <= (%s))", args[0], args[1], args[2], args[1], args[0], args[2]), nil
+		case "since":
+			return fmt.Sprintf("(%d - %s)", r.now, unixNanoExpr(args[0])), nil
+		case "until":
+			return fmt.Sprintf("(%s - %d)", unixNanoExpr(args[0]), r.now), nil
+		case "elapsed":
+			return fmt.Sprintf("(%s - %s)", unixNanoExpr(args[1]), unixNanoExpr(args[0])), nil
+		case "unixnano":
+			return unixNanoExpr(args[0]), nil
+		case "dur":
+			ns, ok := durationLiteral(x.Args[0])
+			if !ok {
+				return "", fmt.Errorf("dur takes a quoted duration such as \"2s\", got %s", args[0])
+			}
+			return ns, nil
+		}
+	}
+	return types.ExprString(e), nil
+}
+
+// expandCondition rewrites the condition helpers in cond into plain Delve
+// expressions; since and until measure against now. Conditions without
+// helpers, or that are not Go syntax, are returned unchanged.
+func expandCondition(cond string, now time.Time) (string, []string, error) {
+	if cond == "" {
+		return cond, nil, nil
+	}
+	e, err := parser.ParseExpr(cond)
+	if err != nil {
+		return cond, nil, nil
+	}
+	r := &conditionRewriter{now: now.UnixNano()}
+	expanded, err := r.rewrite(e)
+	if err != nil || len(r.helpers) == 0 {
+		return cond, nil, err
+	}
+	return expanded, r.helpers, nil
+}
+
+// conditionFlag expands the helpers in a --cond value for a command
+func conditionFlag(cond string) (string, *output.ErrorInfo) {
+	expanded, _, err := expandCondition(cond, time.Now())
+	if err != nil {
+		return "", output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid condition: %v", err),
+			map[string]any{"condition": cond, "helpers": helperNames()},
+		)
+	}
+	return expanded, nil
+}
+
+// hitCondOps are the operators of a hit condition
+var hitCondOps = []string{"==", "!=", ">=", "<=", ">", "<", "%"}
+
+// hitCondFlag validates a --hitcond value, "op N", and normalizes its spacing
+// for Delve. An empty value is no hit condition.
+func hitCondFlag(hitCond string) (string, *output.ErrorInfo) {
+	s := strings.TrimSpace(hitCond)
+	if s == "" {
+		return "", nil
+	}
+	for _, op := range hitCondOps {
+		rest, ok := strings.CutPrefix(s, op)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(rest))
+		if err == nil && n >= 0 && (op != "%" || n > 0) {
+			return op + " " + strconv.Itoa(n), nil
+		}
+		break
+	}
+	return "", output.InvalidArgumentWithDetails(
+		fmt.Sprintf("invalid hit condition %q: want an operator and a count, such as \"== 100\" or \"%% 10\"", hitCond),
+		map[string]any{"hitCondition": hitCond, "operators": hitCondOps},
+	)
+}
+
+// helperNames lists the condition helpers for error details
+func helperNames() []string {
+	return []string{"approx(x, y, eps)", "since(t)", "until(t)", "elapsed(a, b)", "unixnano(t)", "dur(\"2s\")"}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+// TestExpandCondition checks the expansion of the helpers
+func TestExpandCondition(t *testing.T) {
+	now := time.Unix(100, 0)
+	tests := []struct {
+		cond, want string
+	}{
+		{"x > 1", "x > 1"},
+		{"approx(x, 1.5, 0.001)", "((x) - (1.5) <= (0.001) && (1.5) - (x) <= (0.001))"},
+		{`d > dur("1.5s")`, "d > 1500000000"},
+		{`since(t) > "2s" && ok`, "(100000000000 - " + unixNanoExpr("t") + ") > 2000000000 && ok"},
+	}
+	for _, tt := range tests {
+		got, _, err := expandCondition(tt.cond, now)
+		if err != nil || got != tt.want {
+			t.Errorf("expandCondition(%q) = %q, %v; want %q", tt.cond, got, err, tt.want)
+		}
+	}
+	if _, _, err := expandCondition("approx(x, 1)", now); err == nil {
+		t.Error("approx with two arguments was accepted")
+	}
+}
+
+// TestHitCondFlag checks hit conditions are normalized and malformed ones
+// rejected.
+func TestHitCondFlag(t *testing.T) {
+	for in, want := range map[string]string{
+		"== 100": "== 100",
+		"%10":    "% 10",
+		" >= 5 ": ">= 5",
+		"<3":     "< 3",
+		"":       "",
+	} {
+		got, info := hitCondFlag(in)
+		if info != nil || got != want {
+			t.Errorf("hitCondFlag(%q) = %q, %v; want %q", in, got, info, want)
+		}
+	}
+	for _, in := range []string{"100", "=~ 3", "% 0", "> -1", "== x"} {
+		if _, info := hitCondFlag(in); info == nil {
+			t.Errorf("hitCondFlag(%q) accepted", in)
+		}
+	}
+}
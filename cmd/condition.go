@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// conditionChange is a change to a breakpoint's condition and hit condition.
+// Label names the condition argument in errors.
+type conditionChange struct {
+	Label        string
+	Cond         string
+	SetCond      bool
+	HitCond      string
+	SetHitCond   bool
+	ClearCond    bool
+	ClearHitCond bool
+}
+
+// validate rejects contradictory and empty changes
+func (ch conditionChange) validate() *output.ErrorInfo {
+	switch {
+	case ch.SetCond && ch.ClearCond:
+		return output.InvalidArgument(ch.Label + " cannot be combined with --clear-cond")
+	case ch.SetHitCond && ch.ClearHitCond:
+		return output.InvalidArgument("--hitcond cannot be combined with --clear-hitcond")
+	case !ch.SetCond && !ch.SetHitCond && !ch.ClearCond && !ch.ClearHitCond:
+		return output.InvalidArgument("nothing to change: give " + ch.Label + ", --hitcond, --clear-cond or --clear-hitcond")
+	}
+	return nil
+}
+
+// parseBreakpointID parses a breakpoint ID argument or exits with
+// INVALID_ARGUMENT
+func parseBreakpointID(name, arg string, getOutputFormat func() output.OutputFormat) int {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		output.ErrorWithInfo(name, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid breakpoint ID: %s", arg),
+			map[string]any{"id": arg},
+		)).PrintAndExit(getOutputFormat())
+	}
+	return id
+}
+
+// amendCondition applies a condition change to breakpoint idArg, keeping
+// its ID and hit counts, and prints the result
+func amendCondition(mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, name, idArg string, change conditionChange) {
+	id := parseBreakpointID(name, idArg, getOutputFormat)
+	if info := change.validate(); info != nil {
+		output.ErrorWithInfo(name, info).PrintAndExit(getOutputFormat())
+	}
+
+	c := mustGetClient(name)
+	defer func() { _ = c.Close() }()
+
+	bp, err := c.GetBreakpoint(id)
+	if err != nil {
+		output.Error(name, err).PrintAndExit(getOutputFormat())
+	}
+	previous := map[string]any{"condition": bp.Cond, "hitCondition": bp.HitCond}
+
+	switch {
+	case change.SetCond:
+		cond, info := conditionFlag(change.Cond)
+		if info != nil {
+			output.ErrorWithInfo(name, info).PrintAndExit(getOutputFormat())
+		}
+		bp.Cond = cond
+	case change.ClearCond:
+		bp.Cond = ""
+	}
+	switch {
+	case change.SetHitCond:
+		hitCond, info := hitCondFlag(change.HitCond)
+		if info != nil {
+			output.ErrorWithInfo(name, info).PrintAndExit(getOutputFormat())
+		}
+		bp.HitCond = hitCond
+	case change.ClearHitCond:
+		bp.HitCond = ""
+	}
+
+	if err := c.AmendBreakpoint(bp); err != nil {
+		output.ErrorWithInfo(name, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("cannot change breakpoint %d: %v", id, err),
+			map[string]any{"id": id, "condition": bp.Cond, "hitCondition": bp.HitCond},
+		)).PrintAndExit(getOutputFormat())
+	}
+
+	data := map[string]any{
+		"id":           bp.ID,
+		"file":         bp.File,
+		"line":         bp.Line,
+		"function":     bp.FunctionName,
+		"condition":    bp.Cond,
+		"hitCondition": bp.HitCond,
+		"hitCount":     bp.TotalHitCount,
+		"enabled":      !bp.Disabled,
+		"previous":     previous,
+	}
+	if change.SetCond && bp.Cond != change.Cond {
+		data["conditionSource"] = change.Cond
+	}
+
+	output.Success(name, data, fmt.Sprintf("Breakpoint %d updated", bp.ID)).PrintAndExit(getOutputFormat())
+}
+
+// addConditionCommand adds the condition command
+func addConditionCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var conditionHitCond string
+	var conditionClearCond, conditionClearHitCond bool
+
+	conditionCmd := &cobra.Command{
+		Use:   "condition <id> [expr]",
+		Short: "Change a breakpoint's condition",
+		Long: `Set, replace or clear the condition of an existing breakpoint.
+
+The breakpoint keeps its ID and hit counts, so a condition can be refined
+step by step without re-creating the breakpoint.
+
+Conditions may use helpers that godebug expands into Delve expressions,
+since floating point and time.Time comparisons are easy to get wrong:
+  approx(x, y, eps)   |x - y| <= eps
+  unixnano(t)         a time.Time as Unix nanoseconds
+  elapsed(a, b)       nanoseconds from time a to time b
+  since(t), until(t)  nanoseconds from t to now, from now to t, where now is
+                      when the condition is set, not when it is evaluated
+  dur("2s")           a duration in nanoseconds
+A quoted duration compared with elapsed, since or until is converted too:
+elapsed(req.start, req.end) > "2s". The response shows the expanded
+condition and the original in conditionSource.
+
+Options:
+  --hitcond "op N"   Stop only when the hit count satisfies op N, where op is
+                     one of ==, !=, >, >=, <, <= or % (e.g. "> 10", "% 5")
+  --clear-cond       Remove the condition
+  --clear-hitcond    Remove the hit condition
+
+Example:
+  godebug --addr $ADDR condition 1 "user.ID == 42"
+  godebug --addr $ADDR condition 1 "approx(ratio, 1.5, 0.001)"
+  godebug --addr $ADDR condition 1 --hitcond ">= 100"
+  godebug --addr $ADDR condition 1 --clear-cond`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			change := conditionChange{
+				Label:        "an expression",
+				SetCond:      len(args) == 2,
+				HitCond:      conditionHitCond,
+				SetHitCond:   cmd.Flags().Changed("hitcond"),
+				ClearCond:    conditionClearCond,
+				ClearHitCond: conditionClearHitCond,
+			}
+			if change.SetCond {
+				change.Cond = args[1]
+			}
+			amendCondition(mustGetClient, getOutputFormat, "condition", args[0], change)
+		},
+	}
+
+	conditionCmd.Flags().StringVar(&conditionHitCond, "hitcond", "", "Hit count condition, e.g. \"> 10\" or \"% 5\"")
+	conditionCmd.Flags().BoolVar(&conditionClearCond, "clear-cond", false, "Remove the condition")
+	conditionCmd.Flags().BoolVar(&conditionClearHitCond, "clear-hitcond", false, "Remove the hit condition")
+	root.AddCommand(conditionCmd)
+}
+
+func init() {
+	addConditionCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
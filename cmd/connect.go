@@ -7,6 +7,8 @@ import (
 	"github.com/8gears/godebug-agentic/internal/output"
 )
 
+var connectArch string
+
 var connectCmd = &cobra.Command{
 	Use:   "connect <addr>",
 	Short: "Connect to an existing Delve server",
@@ -14,9 +16,16 @@ var connectCmd = &cobra.Command{
 
 This is useful for remote debugging or attaching to a manually started Delve server.
 
+The server may run on another architecture than this host (an ARM board
+debugged from an x86 workstation). While the target is stopped, connect
+detects its architecture and reports it in data.arch, with cross set when it
+differs from the host's. --arch fails the connect unless the target runs on
+the given architecture (amd64, 386, arm64, riscv64, ppc64le, loong64).
+
 Example:
   dlv debug ./myapp --headless --api-version=2 --listen=:38697
-  godebug connect localhost:38697`,
+  godebug connect localhost:38697
+  godebug connect --arch arm64 board.local:2345`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		serverAddr := args[0]
@@ -33,6 +42,15 @@ Example:
 			output.Error("connect", err).PrintAndExit(GetOutputFormat())
 		}
 
+		recordConnectSession(serverAddr)
+
+		arch := sessionArch(c)
+		if connectArch != "" {
+			if info := checkArch(connectArch, arch); info != nil {
+				output.ErrorWithInfo("connect", info).PrintAndExit(GetOutputFormat())
+			}
+		}
+
 		data := map[string]any{
 			"addr":    serverAddr,
 			"running": state.Running,
@@ -40,6 +58,12 @@ Example:
 		if state.SelectedGoroutine != nil {
 			data["goroutineId"] = state.SelectedGoroutine.ID
 		}
+		if arch != nil {
+			data["arch"] = archData(*arch)
+		}
+		if d := sessionDebuggability(serverAddr); d != nil {
+			data["debuggability"] = d
+		}
 
 		output.Success("connect", data, "Connected to debug server").PrintAndExit(GetOutputFormat())
 	},
@@ -47,4 +71,5 @@ Example:
 
 func init() {
 	rootCmd.AddCommand(connectCmd)
+	connectCmd.Flags().StringVar(&connectArch, "arch", "", "Fail unless the target runs on this architecture (GOARCH name)")
 }
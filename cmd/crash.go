@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// onCrashCapture is the start --on-crash mode that saves post-mortem artifacts
+const onCrashCapture = "capture"
+
+// Names of the breakpoints Delve sets on unrecovered panics and fatal runtime errors
+const (
+	unrecoveredPanicBreakpoint = "unrecovered-panic"
+	fatalThrowBreakpoint       = "runtime-fatal-throw"
+)
+
+const (
+	crashOutputLines = 50 // lines of stdout/stderr kept in a capture
+	crashStackDepth  = 50 // frames per goroutine in the final dump
+	crashDumpWaitMs  = 1000
+)
+
+// validateOnCrash rejects unknown --on-crash modes
+func validateOnCrash(onCrash string, getOutputFormat func() output.OutputFormat) {
+	if onCrash != "" && onCrash != onCrashCapture {
+		output.ErrorWithInfo("start", output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid --on-crash mode: %s (expected capture)", onCrash),
+			map[string]any{"onCrash": onCrash},
+		)).PrintAndExit(getOutputFormat())
+	}
+}
+
+// crashRedirects prepares files for the target's output so its tail survives a crash
+func crashRedirects() (redirects []string, stdout, stderr string, err error) {
+	dir, err := session.NewOutputDir()
+	if err != nil {
+		return nil, "", "", err
+	}
+	stdout = filepath.Join(dir, "stdout.log")
+	stderr = filepath.Join(dir, "stderr.log")
+	return []string{"stdout:" + stdout, "stderr:" + stderr}, stdout, stderr, nil
+}
+
+// recordCrashCapture enables crash capture in the session record
+func recordCrashCapture(addr, stdout, stderr string) {
+	s, err := session.Load(addr)
+	if err != nil {
+		s = session.New(addr)
+	}
+	s.OnCrash = onCrashCapture
+	s.Stdout = stdout
+	s.Stderr = stderr
+	_ = s.Save()
+}
+
+// crashReason describes why state counts as a crash, or returns "" if it does not
+func crashReason(state *api.DebuggerState) string {
+	if state.Exited {
+		if state.ExitStatus != 0 {
+			return fmt.Sprintf("exited with status %d", state.ExitStatus)
+		}
+		return ""
+	}
+	if state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return ""
+	}
+	switch state.CurrentThread.Breakpoint.Name {
+	case unrecoveredPanicBreakpoint:
+		return "unrecovered panic"
+	case fatalThrowBreakpoint:
+		return "fatal runtime error"
+	}
+	return ""
+}
+
+// captureCrash saves post-mortem artifacts when the session was started with
+// --on-crash capture and state is a crash. It returns the capture report for the
+// response, or nil when nothing was captured.
+func captureCrash(c *debugger.Client, state *api.DebuggerState) map[string]any {
+	sess, err := session.Load(c.Addr())
+	if err != nil || sess.OnCrash != onCrashCapture {
+		return nil
+	}
+	reason := crashReason(state)
+	if reason == "" {
+		return nil
+	}
+
+	now := time.Now()
+	dir, err := session.NewArtifactDir(c.Addr(), "crash", now)
+	if err != nil {
+		return map[string]any{"reason": reason, "error": err.Error()}
+	}
+	report := map[string]any{
+		"reason":    reason,
+		"dir":       dir,
+		"timestamp": newEventTimestamp(c.Addr(), now),
+	}
+
+	// Output tails are available even after the process is gone
+	var tail []string
+	for _, f := range []struct{ name, path string }{{"stdout", sess.Stdout}, {"stderr", sess.Stderr}} {
+		if f.path == "" {
+			continue
+		}
+		if lines := tailLines(f.path, crashOutputLines); len(lines) > 0 {
+			tail = append(tail, "=== "+f.name+" ===")
+			tail = append(tail, lines...)
+		}
+	}
+	if len(tail) > 0 {
+		path := filepath.Join(dir, "output.log")
+		if err := os.WriteFile(path, []byte(strings.Join(tail, "\n")+"\n"), 0o600); err == nil {
+			report["output"] = path
+		}
+	}
+
+	if state.Exited {
+		return report
+	}
+
+	goroutinesPath := filepath.Join(dir, "goroutines.json")
+	if n, err := writeGoroutineDump(c, goroutinesPath); err != nil {
+		report["goroutinesError"] = err.Error()
+	} else {
+		report["goroutines"] = goroutinesPath
+		report["goroutineCount"] = n
+	}
+
+	corePath := filepath.Join(dir, "core")
+	if err := writeCoreDump(c, corePath); err != nil {
+		report["coreError"] = err.Error()
+	} else {
+		report["core"] = corePath
+	}
+
+	return report
+}
+
+// tailLines returns the last n lines of the file at path
+func tailLines(path string, n int) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines
+}
+
+// writeGoroutineDump writes every goroutine with its stack to path
+func writeGoroutineDump(c *debugger.Client, path string) (int, error) {
+	goroutines, _, err := c.ListGoroutines(0, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	dump := make([]map[string]any, 0, len(goroutines))
+	for _, g := range goroutines {
+		gData := map[string]any{"id": g.ID}
+		if stack, err := goroutineStackData(c, g.ID, crashStackDepth); err == nil {
+			gData["frames"] = stack["frames"]
+		} else {
+			gData["error"] = err.Error()
+		}
+		dump = append(dump, gData)
+	}
+
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	return len(dump), os.WriteFile(path, data, 0o600)
+}
+
+// writeCoreDump asks Delve for a core dump at path and waits for it to finish
+func writeCoreDump(c *debugger.Client, path string) error {
+	state, err := c.DumpStart(path)
+	for err == nil && state.Dumping && !state.AllDone {
+		state, err = c.DumpWait(crashDumpWaitMs)
+	}
+	if err != nil {
+		return err
+	}
+	if state.Err != "" {
+		return errors.New(state.Err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestCrashReason checks that non-zero exits, unrecovered panics and fatal
+// throws are crashes, and that other stops are not.
+func TestCrashReason(t *testing.T) {
+	stoppedAt := func(name string) *api.DebuggerState {
+		return &api.DebuggerState{CurrentThread: &api.Thread{Breakpoint: &api.Breakpoint{Name: name}}}
+	}
+	tests := []struct {
+		name  string
+		state *api.DebuggerState
+		want  string
+	}{
+		{"exit status", &api.DebuggerState{Exited: true, ExitStatus: 2}, "exited with status 2"},
+		{"clean exit", &api.DebuggerState{Exited: true}, ""},
+		{"panic", stoppedAt("unrecovered-panic"), "unrecovered panic"},
+		{"fatal throw", stoppedAt("runtime-fatal-throw"), "fatal runtime error"},
+		{"user breakpoint", stoppedAt(""), ""},
+		{"no breakpoint", &api.DebuggerState{CurrentThread: &api.Thread{}}, ""},
+		{"no thread", &api.DebuggerState{}, ""},
+	}
+	for _, tt := range tests {
+		if got := crashReason(tt.state); got != tt.want {
+			t.Errorf("%s: crashReason = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestTailLines checks that a file shorter than n is returned whole and a
+// longer one is cut to its last n lines.
+func TestTailLines(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, lines int) string {
+		var b strings.Builder
+		for i := 1; i <= lines; i++ {
+			fmt.Fprintf(&b, "line %d\n", i)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if got, want := tailLines(write("short", 2), 5), []string{"line 1", "line 2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("short file: tailLines = %q; want %q", got, want)
+	}
+	if got, want := tailLines(write("long", 10), 3), []string{"line 8", "line 9", "line 10"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("long file: tailLines = %q; want %q", got, want)
+	}
+	if got := tailLines(filepath.Join(dir, "missing"), 3); got != nil {
+		t.Errorf("missing file: tailLines = %q; want nil", got)
+	}
+}
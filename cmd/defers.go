@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// deferLocation is the JSON form of a location defers reports
+func deferLocation(loc api.Location) map[string]any {
+	data := map[string]any{"file": loc.File, "line": loc.Line}
+	if loc.Function != nil {
+		data["function"] = loc.Function.Name()
+	}
+	return data
+}
+
+// frameDefers lists the deferred calls of a frame in the order they will
+// run, numbered as locals --deferred takes them
+func frameDefers(index int, frame api.Stackframe) map[string]any {
+	defers := make([]map[string]any, len(frame.Defers))
+	for i, d := range frame.Defers {
+		item := map[string]any{"index": i + 1}
+		if d.Unreadable != "" {
+			item["unreadable"] = d.Unreadable
+		} else {
+			item["call"] = deferLocation(d.DeferredLoc)
+			item["deferredAt"] = deferLocation(d.DeferLoc)
+		}
+		defers[i] = item
+	}
+	data := deferLocation(frame.Location)
+	data["index"] = index
+	data["defers"] = defers
+	if frame.Err != "" {
+		data["error"] = frame.Err
+	}
+	return data
+}
+
+// addDefersCommand adds the defers command
+func addDefersCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var defersGoroutine int64
+	var defersAll bool
+	var defersDepth int
+
+	defersCmd := &cobra.Command{
+		Use:   "defers [frame]",
+		Short: "List the deferred calls registered in a frame",
+		Long: `List the functions deferred in a stack frame that have not run yet, in the
+order they will run (last deferred first): the deferred function and the
+defer statement that registered it. Without an argument the selected frame
+(see frame) is used; --all lists every frame that has deferred calls.
+
+A mutex that is never unlocked or a file that is never closed shows up as a
+missing defer here, or as one registered in a frame that never returns. The
+index of each call is what locals --deferred N and args --deferred N take
+to inspect it.
+
+Example:
+  godebug --addr $ADDR defers
+  godebug --addr $ADDR defers 2
+  godebug --addr $ADDR defers --all --goroutine 7`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			frameIdx := -1
+			if len(args) == 1 {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 0 {
+					output.ErrorWithInfo("defers", output.InvalidArgumentWithDetails(
+						fmt.Sprintf("invalid frame index: %s", args[0]),
+						map[string]any{"index": args[0]},
+					)).PrintAndExit(getOutputFormat())
+				}
+				frameIdx = n
+			}
+			if defersAll && frameIdx >= 0 {
+				output.ErrorWithInfo("defers", output.InvalidArgument("--all cannot be combined with a frame")).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("defers")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("defers", err).PrintAndExit(getOutputFormat())
+			}
+			goroutineID, ok := targetGoroutine(state, defersGoroutine)
+			if !ok {
+				output.ErrorWithInfo("defers", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+			}
+			if frameIdx < 0 && !defersAll {
+				frameIdx = mustSelectedFrame(c, "defers", state, goroutineID, getOutputFormat)
+			}
+
+			depth := defersDepth
+			if !defersAll {
+				depth = frameIdx
+			}
+			frames, err := c.StacktraceDefers(goroutineID, depth)
+			if err != nil {
+				output.Error("defers", err).PrintAndExit(getOutputFormat())
+			}
+
+			if !defersAll {
+				if frameIdx >= len(frames) {
+					output.ErrorWithInfo("defers", output.NotFound("frame", strconv.Itoa(frameIdx)).WithDetails(map[string]any{
+						"frames": len(frames),
+					})).PrintAndExit(getOutputFormat())
+				}
+				data := frameDefers(frameIdx, frames[frameIdx])
+				data["goroutineId"] = goroutineID
+				n := len(frames[frameIdx].Defers)
+				output.Success("defers", data, fmt.Sprintf("%d deferred calls in frame %d (%s)", n, frameIdx, frames[frameIdx].Function.Name())).PrintAndExit(getOutputFormat())
+			}
+
+			listed := []map[string]any{}
+			total := 0
+			for i, f := range frames {
+				if len(f.Defers) == 0 {
+					continue
+				}
+				listed = append(listed, frameDefers(i, f))
+				total += len(f.Defers)
+			}
+			data := map[string]any{
+				"goroutineId": goroutineID,
+				"frames":      listed,
+				"count":       total,
+			}
+			output.Success("defers", data, fmt.Sprintf("%d deferred calls in %d of %d frames", total, len(listed), len(frames))).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	defersCmd.Flags().Int64Var(&defersGoroutine, "goroutine", 0, "Goroutine to inspect without switching (default: selected)")
+	defersCmd.Flags().BoolVar(&defersAll, "all", false, "List the deferred calls of every frame")
+	defersCmd.Flags().IntVar(&defersDepth, "depth", 50, "Frames to search with --all")
+	root.AddCommand(defersCmd)
+}
+
+func init() {
+	addDefersCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
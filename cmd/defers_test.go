@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestFrameDefers(t *testing.T) {
+	frame := api.Stackframe{
+		Location: api.Location{File: "/app/store.go", Line: 40, Function: &api.Function{Name_: "main.(*Store).Put"}},
+		Defers: []api.Defer{
+			{
+				DeferredLoc: api.Location{File: "/usr/lib/go/src/sync/mutex.go", Line: 212, Function: &api.Function{Name_: "sync.(*Mutex).Unlock"}},
+				DeferLoc:    api.Location{File: "/app/store.go", Line: 35, Function: &api.Function{Name_: "main.(*Store).Put"}},
+			},
+			{Unreadable: "could not read defer record"},
+		},
+	}
+
+	data := frameDefers(3, frame)
+	if data["index"] != 3 || data["function"] != "main.(*Store).Put" {
+		t.Errorf("frame = %v", data)
+	}
+	defers := data["defers"].([]map[string]any)
+	if len(defers) != 2 || defers[0]["index"] != 1 || defers[1]["index"] != 2 {
+		t.Fatalf("defers = %v", defers)
+	}
+	if defers[0]["call"].(map[string]any)["function"] != "sync.(*Mutex).Unlock" || defers[0]["deferredAt"].(map[string]any)["line"] != 35 {
+		t.Errorf("defer = %v", defers[0])
+	}
+	if defers[1]["unreadable"] == nil || defers[1]["call"] != nil {
+		t.Errorf("unreadable defer = %v", defers[1])
+	}
+}
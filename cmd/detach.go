@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// addDetachCommand adds the detach command
+func addDetachCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	detachCmd := &cobra.Command{
+		Use:   "detach",
+		Short: "End the session and leave the process running",
+		Long: `Detach from the debugged process and shut down the Delve server, leaving the
+process running whatever the session mode. Breakpoints are removed and a
+stopped process resumes.
+
+quit also leaves a process alone when godebug attached to it (start --mode
+attach), but kills anything else, including a process a dlv attach server
+started outside godebug was debugging. Use detach wherever the process must
+not be terminated, such as production services.
+
+When the server runs on this host, detach checks the process is still alive
+(data.running). A process godebug launched keeps running too, detached from
+the terminal that started it.
+
+Example:
+  godebug --addr $ADDR detach`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("detach")
+			addr := c.Addr()
+
+			pid, err := c.ProcessPid()
+			if err != nil {
+				output.Error("detach", err).PrintAndExit(getOutputFormat())
+			}
+			launched := false
+			if s, err := session.Load(addr); err == nil && s.Mode != string(debugger.ModeAttach) {
+				launched = true
+			}
+
+			// Note: don't close the client, the server shuts down
+			if err := c.Detach(false); err != nil {
+				output.Error("detach", err).PrintAndExit(getOutputFormat())
+			}
+			recordSessionEnd(addr, "detach")
+
+			accepting, _ := waitGone(addr, nil)
+			data := map[string]any{
+				"detached":      true,
+				"pid":           pid,
+				"serverRunning": accepting,
+			}
+			if launched {
+				data["launched"] = true
+			}
+			msg := fmt.Sprintf("Detached from process %d; it keeps running", pid)
+			if serverIsLocal(addr) {
+				running := processAlive(pid)
+				data["running"] = running
+				if !running {
+					output.ErrorWithInfo("detach", output.NewErrorInfo(output.ErrCodeProcessExited,
+						fmt.Sprintf("process %d is gone after detaching", pid)).WithDetails(data)).PrintAndExit(getOutputFormat())
+				}
+			}
+			if accepting {
+				msg += "; the server is still accepting connections"
+			}
+			output.Success("detach", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	root.AddCommand(detachCmd)
+}
+
+func init() {
+	addDetachCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
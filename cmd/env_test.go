@@ -0,0 +1,37 @@
+package cmd
+
+import "testing"
+
+// TestRedactEnvValue checks secret names are matched on whole segments and
+// URL passwords are hidden however they are encoded
+func TestRedactEnvValue(t *testing.T) {
+	tests := []struct {
+		name, value string
+		want        string
+		redacted    bool
+	}{
+		{"GITHUB_TOKEN", "ghp_abc", redactedValue, true},
+		{"DB_PASSWORD", "hunter2", redactedValue, true},
+		{"db_pwd", "hunter2", redactedValue, true},
+		{"STRIPE_API_KEY", "sk_live", redactedValue, true},
+		{"APIKEY", "k", redactedValue, true},
+		{"AUTH", "basic", redactedValue, true},
+		{"DATABASE_DSN", "user@tcp(db)/app", redactedValue, true},
+		{"PWD", "/home/app", "/home/app", false},
+		{"OLDPWD", "/tmp", "/tmp", false},
+		{"AUTHOR", "jane", "jane", false},
+		{"OAUTHLIB_DEBUG", "1", "1", false},
+		{"PATH", "/usr/bin:/bin", "/usr/bin:/bin", false},
+		{"DATABASE_URL", "postgres://u:secret@h/db", "postgres://u:" + redactedValue + "@h/db", true},
+		{"DATABASE_URL", "postgres://u:p%40ss@h/db", "postgres://u:" + redactedValue + "@h/db", true},
+		{"DATABASE_URL", "postgres://u:p@ss@h/db?sslmode=off", "postgres://u:" + redactedValue + "@h/db?sslmode=off", true},
+		{"DATABASE_URL", "postgres://u@h/db", "postgres://u@h/db", false},
+		{"UPSTREAM", "https://example.com/a:b@c", "https://example.com/a:b@c", false},
+	}
+	for _, tt := range tests {
+		got, redacted := redactEnvValue(tt.name, tt.value)
+		if got != tt.want || redacted != tt.redacted {
+			t.Errorf("redactEnvValue(%q, %q) = %q, %v; want %q, %v", tt.name, tt.value, got, redacted, tt.want, tt.redacted)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"strings"
+	"unicode"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Side effect assessments of calls in eval expressions
+const (
+	sideEffectsUnlikely = "unlikely" // getters, formatters and pure helpers
+	sideEffectsPossible = "possible" // setters, I/O, locking or unknown functions
+)
+
+// exprCall is a function or method call found in an eval expression
+type exprCall struct {
+	Call        string `json:"call"`
+	SideEffects string `json:"sideEffects"`
+	Reason      string `json:"reason"`
+}
+
+// pureBuiltins are evaluated by Delve itself without calling into the target
+var pureBuiltins = map[string]bool{
+	"len": true, "cap": true, "real": true, "imag": true, "complex": true, "min": true, "max": true,
+	"bool": true, "string": true, "byte": true, "rune": true, "error": true, "any": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// readOnlyPrefixes start the names of methods that conventionally only read
+// their receiver
+var readOnlyPrefixes = []string{
+	"String", "GoString", "Error", "Get", "Is", "Has", "Len", "Cap", "Size", "Count",
+	"Name", "Key", "Value", "Equal", "Less", "Compare", "Contains", "Bytes", "Load",
+	"Seconds", "Milliseconds", "Microseconds", "Nanoseconds", "Minutes", "Hours",
+	"Unix", "Before", "After", "Sub", "Sum", "Type", "Kind", "Peek", "Cmp", "Sign",
+}
+
+// mutatingPrefixes start the names of methods that conventionally change state
+// or perform I/O
+var mutatingPrefixes = []string{
+	"Set", "Add", "Inc", "Dec", "Write", "Read", "Close", "Reset", "Delete", "Remove",
+	"Push", "Pop", "Lock", "Unlock", "RLock", "RUnlock", "TryLock", "Send", "Store",
+	"Put", "Append", "Clear", "Update", "Init", "Start", "Stop", "Run", "Flush",
+	"Insert", "Swap", "CompareAndSwap", "Do", "Done", "Wait", "Signal", "Broadcast",
+	"Cancel", "Commit", "Rollback", "Exec", "Save", "Register", "Next", "Scan", "Seek",
+	"Grow", "Truncate", "Free", "Release", "Acquire", "Drain", "Shutdown", "Kill",
+}
+
+// pureStdPackages are standard packages whose functions compute values from
+// their arguments
+var pureStdPackages = map[string]bool{
+	"strings": true, "strconv": true, "math": true, "bytes": true, "unicode": true,
+	"utf8": true, "filepath": true, "path": true, "reflect": true,
+}
+
+// hasNamePrefix reports whether name is prefix or starts with prefix followed
+// by an upper case letter or digit (Get, GetName, but not Getaway)
+func hasNamePrefix(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	rest := name[len(prefix):]
+	return rest == "" || unicode.IsUpper(rune(rest[0])) || unicode.IsDigit(rune(rest[0]))
+}
+
+// classifyCall assesses the side effects of calling fn, written as in the
+// expression (pkg.Func, recv.Method or Func)
+func classifyCall(fn string) exprCall {
+	name := fn
+	qualifier := ""
+	if i := strings.LastIndex(fn, "."); i >= 0 {
+		qualifier, name = fn[:i], fn[i+1:]
+	}
+	if pureStdPackages[qualifier] {
+		return exprCall{fn, sideEffectsUnlikely, "standard library function computing a value"}
+	}
+	if qualifier == "fmt" && strings.HasPrefix(name, "Sprint") {
+		return exprCall{fn, sideEffectsUnlikely, "formats its arguments"}
+	}
+	// Mutating prefixes are checked first so that e.g. SetName is not read
+	// as a Name getter
+	for _, p := range mutatingPrefixes {
+		if hasNamePrefix(name, p) {
+			return exprCall{fn, sideEffectsPossible, fmt.Sprintf("%s* methods conventionally change state or do I/O", p)}
+		}
+	}
+	for _, p := range readOnlyPrefixes {
+		if hasNamePrefix(name, p) {
+			return exprCall{fn, sideEffectsUnlikely, fmt.Sprintf("%s* methods conventionally only read", p)}
+		}
+	}
+	return exprCall{fn, sideEffectsPossible, "unknown function; it may change state"}
+}
+
+// findCalls returns the calls an expression makes into the target. Builtins
+// and conversions to basic types are left out since Delve evaluates them.
+func findCalls(expr string) ([]exprCall, error) {
+	tree, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	var calls []exprCall
+	ast.Inspect(tree, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var fn string
+		switch f := call.Fun.(type) {
+		case *ast.Ident:
+			if pureBuiltins[f.Name] {
+				return true
+			}
+			fn = f.Name
+		case *ast.SelectorExpr:
+			fn = exprString(f)
+		case *ast.ArrayType, *ast.StarExpr, *ast.ParenExpr, *ast.MapType, *ast.ChanType, *ast.InterfaceType:
+			// Conversions such as []byte(s) or (*T)(p)
+			return true
+		default:
+			fn = exprString(call.Fun)
+		}
+		calls = append(calls, classifyCall(fn))
+		return true
+	})
+	return calls, nil
+}
+
+// exprHasCalls reports whether expr parses and calls into the target
+func exprHasCalls(expr string) bool {
+	calls, err := findCalls(expr)
+	return err == nil && len(calls) > 0
+}
+
+// exprString renders a selector chain such as a.b.c, eliding anything more
+// complex than identifiers and selectors
+func exprString(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return exprString(x.X) + "." + x.Sel.Name
+	case *ast.CallExpr:
+		return exprString(x.Fun) + "()"
+	case *ast.IndexExpr:
+		return exprString(x.X) + "[...]"
+	default:
+		return "..."
+	}
+}
+
+// callsSideEffects summarizes the assessments of all calls
+func callsSideEffects(calls []exprCall) string {
+	for _, c := range calls {
+		if c.SideEffects == sideEffectsPossible {
+			return sideEffectsPossible
+		}
+	}
+	return sideEffectsUnlikely
+}
+
+// isCallNotAllowed reports whether Delve rejected an expression for calling a function
+func isCallNotAllowed(err error) bool {
+	return strings.Contains(err.Error(), "function calls are not allowed") ||
+		strings.Contains(err.Error(), "function calls not allowed")
+}
+
+// callsNotAllowedError explains that expr calls functions and how to allow it
+func callsNotAllowedError(expr string) *output.ErrorInfo {
+	calls, _ := findCalls(expr)
+	details := map[string]any{"expression": expr, "calls": calls}
+	if len(calls) > 0 {
+		details["sideEffects"] = callsSideEffects(calls)
+	}
+	return output.InvalidArgumentWithDetails(
+		fmt.Sprintf("expression '%s' calls functions in the target; rerun with --allow-calls to run them", expr),
+		details,
+	)
+}
+
+// evalWithCalls evaluates expr with function calls allowed on goroutineID and
+// returns the response data. Calls run in the goroutine's topmost frame and
+// resume the target until they return.
+func evalWithCalls(c *debugger.Client, goroutineID int64, expr string) (map[string]any, error) {
+	calls, err := findCalls(expr)
+	if err != nil {
+		return nil, output.EvalFailed(expr, err)
+	}
+	state, err := c.CallFunction(goroutineID, expr, debugger.DefaultLoadConfig())
+	if err != nil {
+		return nil, err
+	}
+	if state.Exited {
+		return nil, output.ProcessExited(state.ExitStatus)
+	}
+	t := state.CurrentThread
+	if t == nil || !t.CallReturn {
+		where := ""
+		if t != nil && t.Breakpoint != nil {
+			where = fmt.Sprintf(" at breakpoint %d (%s:%d)", t.Breakpoint.ID, t.File, t.Line)
+		}
+		return nil, output.EvalFailed(expr, fmt.Errorf("the call stopped%s before returning; continue to finish it", where))
+	}
+
+	data := map[string]any{}
+	if len(t.ReturnValues) == 1 {
+		data = variableToMap(t.ReturnValues[0])
+	} else if len(t.ReturnValues) > 1 {
+		values := make([]map[string]any, len(t.ReturnValues))
+		for i, v := range t.ReturnValues {
+			values[i] = variableToMap(v)
+		}
+		data["values"] = values
+	}
+	for _, v := range t.ReturnValues {
+		if v.Name == "~panic" {
+			data["panicked"] = true
+		}
+	}
+	data["expression"] = expr
+	data["calls"] = calls
+	data["sideEffects"] = callsSideEffects(calls)
+	return data, nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import "testing"
+
+// TestFindCalls checks which parts of an expression are reported as calls
+// and how their side effects are assessed.
+func TestFindCalls(t *testing.T) {
+	cases := []struct {
+		expr string
+		want map[string]string
+	}{
+		{"user.Name", map[string]string{}},
+		{"len(items) + int(x)", map[string]string{}},
+		{"[]byte(s)", map[string]string{}},
+		{"user.String()", map[string]string{"user.String": sideEffectsUnlikely}},
+		{"cache.GetOrLoad(k)", map[string]string{"cache.GetOrLoad": sideEffectsUnlikely}},
+		{"q.SetName(\"x\")", map[string]string{"q.SetName": sideEffectsPossible}},
+		{"strings.ToUpper(s.Name())", map[string]string{"strings.ToUpper": sideEffectsUnlikely, "s.Name": sideEffectsUnlikely}},
+		{"fmt.Sprintf(\"%d\", n)", map[string]string{"fmt.Sprintf": sideEffectsUnlikely}},
+		{"process(job)", map[string]string{"process": sideEffectsPossible}},
+		{"w.Getaway()", map[string]string{"w.Getaway": sideEffectsPossible}},
+		{"conn.Close()", map[string]string{"conn.Close": sideEffectsPossible}},
+	}
+	for _, tc := range cases {
+		calls, err := findCalls(tc.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if len(calls) != len(tc.want) {
+			t.Errorf("%s: calls = %v, want %v", tc.expr, calls, tc.want)
+			continue
+		}
+		for _, c := range calls {
+			if tc.want[c.Call] != c.SideEffects {
+				t.Errorf("%s: %s side effects %q, want %q", tc.expr, c.Call, c.SideEffects, tc.want[c.Call])
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// examineRowLen is the number of bytes per row of --format hex
+const examineRowLen = 16
+
+// examineFormats are the renderings of examine --format
+var examineFormats = []string{"hex", "ascii", "typed"}
+
+// examineTypes are the element types of examine --format typed
+var examineTypes = []string{
+	"int8", "int16", "int32", "int64",
+	"uint8", "uint16", "uint32", "uint64", "uintptr",
+	"float32", "float64",
+}
+
+// examineTypeSize returns the size of an --type element; uintptr takes the
+// target's pointer size
+func examineTypeSize(typ string, ptrSize int) int {
+	switch typ {
+	case "int8", "uint8":
+		return 1
+	case "int16", "uint16":
+		return 2
+	case "int32", "uint32", "float32":
+		return 4
+	case "uintptr":
+		return ptrSize
+	}
+	return 8
+}
+
+// examineAddress resolves the address argument of examine: an integer
+// literal is used as is; an expression gives the pointee of a pointer, the
+// contents of a string, slice or channel, and the variable itself otherwise.
+// The second result names which of these it was.
+func examineAddress(c *debugger.Client, scope api.EvalScope, arg string) (uint64, string, error) {
+	if addr, err := strconv.ParseUint(arg, 0, 64); err == nil {
+		return addr, "literal", nil
+	}
+	v, err := c.EvalInScope(scope, arg, api.LoadConfig{})
+	if err != nil {
+		return 0, "", err
+	}
+	switch v.Kind {
+	case reflect.Ptr, reflect.UnsafePointer:
+		if len(v.Children) == 1 {
+			return v.Children[0].Addr, "pointee", nil
+		}
+	case reflect.Uintptr:
+		if addr, err := strconv.ParseUint(v.Value, 0, 64); err == nil {
+			return addr, "value", nil
+		}
+	case reflect.String, reflect.Slice, reflect.Chan:
+		return v.Base, "contents", nil
+	}
+	if v.Addr == 0 {
+		return 0, "", fmt.Errorf("%s has no address", arg)
+	}
+	return v.Addr, "variable", nil
+}
+
+// hexRows renders memory read at addr as rows of hex bytes with their ASCII
+func hexRows(addr uint64, mem []byte) []map[string]any {
+	rows := make([]map[string]any, 0, (len(mem)+examineRowLen-1)/examineRowLen)
+	for off := 0; off < len(mem); off += examineRowLen {
+		row := mem[off:min(off+examineRowLen, len(mem))]
+		hex := make([]string, len(row))
+		for i, b := range row {
+			hex[i] = fmt.Sprintf("%02x", b)
+		}
+		rows = append(rows, map[string]any{
+			"address": formatAddr(addr + uint64(off)),
+			"hex":     strings.Join(hex, " "),
+			"ascii":   printableASCII(row),
+		})
+	}
+	return rows
+}
+
+// printableASCII renders bytes as ASCII, with '.' for anything not printable
+func printableASCII(mem []byte) string {
+	var b strings.Builder
+	for _, c := range mem {
+		if c >= 0x20 && c < 0x7f {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('.')
+		}
+	}
+	return b.String()
+}
+
+// typedValues decodes memory read at addr as consecutive elements of typ in
+// the target's byte order. uintptr values are rendered in hex, and floats
+// that JSON cannot hold (NaN, ±Inf) as strings.
+func typedValues(arch debugger.Arch, addr uint64, mem []byte, typ string) ([]map[string]any, error) {
+	size := examineTypeSize(typ, arch.PtrSize)
+	values := make([]map[string]any, 0, len(mem)/size)
+	for off := 0; off+size <= len(mem); off += size {
+		u, err := arch.Uint(mem[off : off+size])
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		switch typ {
+		case "int8":
+			value = int8(u)
+		case "int16":
+			value = int16(u)
+		case "int32":
+			value = int32(u)
+		case "int64":
+			value = int64(u)
+		case "uintptr":
+			value = formatAddr(u)
+		case "float32", "float64":
+			f := math.Float64frombits(u)
+			if typ == "float32" {
+				f = float64(math.Float32frombits(uint32(u)))
+			}
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				value = strconv.FormatFloat(f, 'g', -1, 64)
+			} else {
+				value = f
+			}
+		default:
+			value = u
+		}
+		values = append(values, map[string]any{
+			"address": formatAddr(addr + uint64(off)),
+			"value":   value,
+		})
+	}
+	return values, nil
+}
+
+// addExamineCommand adds the examine command
+func addExamineCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var examineFormat, examineType string
+
+	examineCmd := &cobra.Command{
+		Use:   "examine <address> <length>",
+		Short: "Read raw memory of the target",
+		Long: `Read <length> bytes of the target's memory, at most 65536, starting at
+<address>: an integer literal (0xc000012340) or an expression. An
+expression gives the memory a pointer points to, the contents of a string,
+slice or channel, and the variable's own memory otherwise; data.resolved
+tells which. Expressions are evaluated in the selected goroutine and frame.
+
+--format selects the rendering:
+  hex    rows of 16 bytes in hex, with their ASCII (default)
+  ascii  the bytes as one string, '.' for anything not printable
+  typed  consecutive --type elements: int8..int64, uint8..uint64, uintptr,
+         float32, float64; <length> must be a multiple of the element size
+
+Multi-byte values are decoded in the target's byte order, which the response
+reports as data.byteOrder next to data.arch.
+
+Example:
+  godebug --addr $ADDR examine 0xc000012340 64
+  godebug --addr $ADDR examine buf 32 --format ascii
+  godebug --addr $ADDR examine &hdr 16 --format typed --type uint32`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !slices.Contains(examineFormats, examineFormat) {
+				output.ErrorWithInfo("examine", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid format: %s", examineFormat),
+					map[string]any{"format": examineFormat, "valid": examineFormats},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if examineFormat == "typed" && !slices.Contains(examineTypes, examineType) {
+				output.ErrorWithInfo("examine", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid type: %s", examineType),
+					map[string]any{"type": examineType, "valid": examineTypes},
+				)).PrintAndExit(getOutputFormat())
+			}
+			length, err := strconv.ParseUint(args[1], 0, 32)
+			if err != nil || length == 0 || length > rpc2.ExamineMemoryLengthLimit {
+				output.ErrorWithInfo("examine", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid length: %s (must be 1 to %d)", args[1], rpc2.ExamineMemoryLengthLimit),
+					map[string]any{"length": args[1], "max": rpc2.ExamineMemoryLengthLimit},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("examine")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("examine", err).PrintAndExit(getOutputFormat())
+			}
+			arch := debugger.Arch{PtrSize: 8}
+			if a := sessionArch(c); a != nil {
+				arch = *a
+			}
+			if examineFormat == "typed" {
+				if size := examineTypeSize(examineType, arch.PtrSize); length%uint64(size) != 0 {
+					output.ErrorWithInfo("examine", output.InvalidArgumentWithDetails(
+						fmt.Sprintf("length %d is not a multiple of the %d byte size of %s", length, size, examineType),
+						map[string]any{"length": length, "type": examineType, "size": size},
+					)).PrintAndExit(getOutputFormat())
+				}
+			}
+
+			scope := api.EvalScope{GoroutineID: -1}
+			if goroutineID, ok := targetGoroutine(state, 0); ok {
+				scope.GoroutineID = goroutineID
+				if _, err := strconv.ParseUint(args[0], 0, 64); err != nil {
+					scope.Frame = mustSelectedFrame(c, "examine", state, goroutineID, getOutputFormat)
+				}
+			}
+			addr, resolved, err := examineAddress(c, scope, args[0])
+			if err != nil {
+				output.Error("examine", err).PrintAndExit(getOutputFormat())
+			}
+
+			mem, littleEndian, err := c.ExamineMemory(addr, int(length))
+			if err != nil {
+				output.Error("examine", err).PrintAndExit(getOutputFormat())
+			}
+			// The server's answer is authoritative when the arch is not cached
+			arch.LittleEndian = littleEndian
+
+			data := map[string]any{
+				"address":   formatAddr(addr),
+				"length":    len(mem),
+				"format":    examineFormat,
+				"resolved":  resolved,
+				"byteOrder": "little",
+			}
+			if !littleEndian {
+				data["byteOrder"] = "big"
+			}
+			if arch.Name != "" {
+				data["arch"] = arch.Name
+			}
+			if resolved != "literal" {
+				data["expression"] = args[0]
+				if scope.Frame > 0 {
+					data["frame"] = scope.Frame
+				}
+			}
+			switch examineFormat {
+			case "hex":
+				data["rows"] = hexRows(addr, mem)
+			case "ascii":
+				data["ascii"] = printableASCII(mem)
+			case "typed":
+				values, err := typedValues(arch, addr, mem, examineType)
+				if err != nil {
+					output.Error("examine", err).PrintAndExit(getOutputFormat())
+				}
+				data["type"] = examineType
+				data["values"] = values
+			}
+			output.Success("examine", data, fmt.Sprintf("Read %d bytes at %s", len(mem), formatAddr(addr))).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	examineCmd.Flags().StringVar(&examineFormat, "format", "hex", "Rendering: hex, ascii or typed")
+	examineCmd.Flags().StringVar(&examineType, "type", "uint64", "Element type for --format typed")
+	root.AddCommand(examineCmd)
+}
+
+func init() {
+	addExamineCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+)
+
+// TestHexRows checks rows are split every 16 bytes and render their ASCII.
+func TestHexRows(t *testing.T) {
+	mem := append([]byte("GET /index.html\x00"), 0xff, 'H', 'T')
+	rows := hexRows(0xc000010000, mem)
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[0]["ascii"] != "GET /index.html." || rows[1]["address"] != "0xc000010010" {
+		t.Errorf("got %v", rows)
+	}
+	if rows[1]["hex"] != "ff 48 54" || rows[1]["ascii"] != ".HT" {
+		t.Errorf("last row: got %v", rows[1])
+	}
+}
+
+// TestTypedValues checks decoding in both byte orders and the rendering of
+// signed, pointer and float elements.
+func TestTypedValues(t *testing.T) {
+	mem := []byte{0xfe, 0xff, 0xff, 0xff, 0x00, 0x00, 0x80, 0x7f}
+
+	little := debugger.Arch{Name: "amd64", PtrSize: 8, LittleEndian: true}
+	vals, err := typedValues(little, 0x1000, mem, "int32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vals) != 2 || vals[0]["value"] != int32(-2) || vals[1]["address"] != "0x1004" {
+		t.Errorf("int32: got %v", vals)
+	}
+	vals, _ = typedValues(little, 0x1000, mem, "float32")
+	if vals[1]["value"] != "+Inf" {
+		t.Errorf("float32 +Inf: got %v", vals[1]["value"])
+	}
+	vals, _ = typedValues(little, 0x1000, mem, "uintptr")
+	if len(vals) != 1 || vals[0]["value"] != "0x7f800000fffffffe" {
+		t.Errorf("uintptr: got %v", vals)
+	}
+
+	big := debugger.Arch{Name: "ppc64", PtrSize: 8}
+	vals, _ = typedValues(big, 0x1000, mem, "uint16")
+	if len(vals) != 4 || vals[0]["value"] != uint64(0xfeff) || vals[3]["value"] != uint64(0x807f) {
+		t.Errorf("big-endian uint16: got %v", vals)
+	}
+}
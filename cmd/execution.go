@@ -1,12 +1,86 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
 	"github.com/go-delve/delve/service/api"
 	"github.com/spf13/cobra"
 
+	"github.com/8gears/godebug-agentic/internal/debugger"
 	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
 )
 
+var continueNoTimeout bool
+
+var stepCancelPending bool
+
+var restartCheckpoint int
+
+// continueUntil resumes the target without a time limit and halts it when halt
+// fires, unless it stops on its own first. It reports whether it was halted.
+func continueUntil[T any](c *debugger.Client, halt <-chan T) (*api.DebuggerState, bool, error) {
+	type result struct {
+		state *api.DebuggerState
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		state, err := c.ContinueWithContext(context.Background())
+		done <- result{state, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.state, false, r.err
+	case <-halt:
+		if _, err := c.Halt(); err != nil {
+			return nil, true, err
+		}
+		r := <-done
+		return r.state, true, r.err
+	}
+}
+
+// continueUntilInterrupt resumes the target without a time limit. SIGINT and
+// SIGTERM halt the target instead of terminating godebug, so the stop state
+// is still reported.
+func continueUntilInterrupt(c *debugger.Client) (*api.DebuggerState, bool, error) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	return continueUntil(c, sigs)
+}
+
+// stepCancellingPending runs step. With cancelPending, a step Delve refuses
+// because an earlier one a breakpoint interrupted is still pending drops that
+// one with CancelNext and runs again; the second result reports it did.
+func stepCancellingPending(c *debugger.Client, step func() (*api.DebuggerState, error), cancelPending bool) (*api.DebuggerState, bool, error) {
+	state, err := step()
+	if err == nil || !cancelPending || !isPendingStep(err) {
+		return state, false, err
+	}
+	if err := c.CancelNext(); err != nil {
+		return nil, false, err
+	}
+	state, err = step()
+	return state, true, err
+}
+
+// isPendingStep reports whether err is Delve refusing a step because another
+// one is still pending
+func isPendingStep(err error) bool {
+	info := output.FromError(err)
+	details, _ := info.Details.(map[string]any)
+	return info.Code == output.ErrCodeOperationInProgress && details["operation"] == output.PendingStep
+}
+
 // stateToData converts a DebuggerState to a response data map
 func stateToData(state *api.DebuggerState) map[string]any {
 	data := map[string]any{
@@ -21,9 +95,7 @@ func stateToData(state *api.DebuggerState) map[string]any {
 
 	if state.SelectedGoroutine != nil {
 		g := state.SelectedGoroutine
-		data["goroutine"] = map[string]any{
-			"id": g.ID,
-		}
+		data["goroutine"] = goroutineRef(g)
 		if g.CurrentLoc.File != "" {
 			data["location"] = map[string]any{
 				"file":     g.CurrentLoc.File,
@@ -51,30 +123,65 @@ var continueCmd = &cobra.Command{
 	Short: "Continue execution until breakpoint",
 	Long: `Continue execution until the next breakpoint is hit or the program exits.
 
+With --no-timeout the global --timeout does not apply: continue waits until
+the program stops on its own. Interrupting godebug (Ctrl-C, SIGINT or
+SIGTERM) halts the program and reports where it stopped.
+
+Breakpoints set with break --dump-goroutines do not stop continue: each hit
+records a goroutine summary (see goroutine-dumps) and the program resumes.
+Likewise each hit of a break --stacktrace breakpoint records its call path
+(see hit-stacks). A break --collect-diff breakpoint stops continue only when
+its expression changed since the previous hit: data.collected holds the diff
+and data.unchangedHits counts the hits passed before it. Interrupt continue
+to stop sooner.
+The timeout bounds the whole run.
+
+Stopping at a watchpoint (see watch) reports the access and the old and new
+value in data.watchpoint.
+
 Example:
-  godebug --addr $ADDR continue`,
+  godebug --addr $ADDR continue
+  godebug --addr $ADDR continue --no-timeout`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("continue")
 		defer func() { _ = c.Close() }()
 
-		// Set the timeout from global flag
-		c.SetTimeout(GetTimeout())
-
-		state, err := c.Continue()
+		// Hits of --dump-goroutines and --stacktrace breakpoints are recorded and continued past
+		state, interrupted, passed, err := continuePastDumps(c, continueNoTimeout, GetTimeout())
 		if err != nil {
 			output.Error("continue", err).PrintAndExit(GetOutputFormat())
 		}
 
+		stoppedAt := recordStop(c, "continue", state)
+
 		var msg string
 		if state.Exited {
 			msg = "Process exited"
+		} else if interrupted {
+			msg = "Interrupted; process halted"
 		} else if state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil {
 			msg = "Stopped at breakpoint"
 		} else {
 			msg = "Process stopped"
 		}
 
-		output.Success("continue", stateToData(state), msg).PrintAndExit(GetOutputFormat())
+		data := stateToData(state)
+		stoppedAt.addTo(data)
+		if interrupted {
+			data["interrupted"] = true
+		}
+		passed.addTo(data)
+		if watch := watchpointHit(c, state); watch != nil {
+			data["watchpoint"] = watch
+			if watch["id"] != nil {
+				msg = "Stopped at watchpoint"
+			}
+		}
+		if crash := captureCrash(c, state); crash != nil {
+			data["crash"] = crash
+		}
+
+		output.Success("continue", data, msg).PrintAndExit(GetOutputFormat())
 	},
 }
 
@@ -83,20 +190,37 @@ var nextCmd = &cobra.Command{
 	Short: "Step over to next source line",
 	Long: `Step to the next source line, stepping over function calls.
 
+When a breakpoint in another goroutine interrupted an earlier next, step or
+stepout, Delve refuses a new one until it is finished: the error is
+OPERATION_IN_PROGRESS. continue finishes the pending one; --cancel-pending
+drops it and steps from where the program is (data.cancelledPending).
+
 Example:
-  godebug --addr $ADDR next`,
+  godebug --addr $ADDR next
+  godebug --addr $ADDR next --cancel-pending`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("next")
 		defer func() { _ = c.Close() }()
 
 		c.SetTimeout(GetTimeout())
 
-		state, err := c.Next()
+		state, cancelled, err := stepCancellingPending(c, c.Next, stepCancelPending)
 		if err != nil {
 			output.Error("next", err).PrintAndExit(GetOutputFormat())
 		}
 
-		output.Success("next", stateToData(state), "Stepped to next line").PrintAndExit(GetOutputFormat())
+		stoppedAt := recordStop(c, "next", state)
+
+		data := stateToData(state)
+		stoppedAt.addTo(data)
+		if cancelled {
+			data["cancelledPending"] = true
+		}
+		if crash := captureCrash(c, state); crash != nil {
+			data["crash"] = crash
+		}
+
+		output.Success("next", data, "Stepped to next line").PrintAndExit(GetOutputFormat())
 	},
 }
 
@@ -105,20 +229,37 @@ var stepCmd = &cobra.Command{
 	Short: "Step into function call",
 	Long: `Step into the next function call.
 
+When a breakpoint in another goroutine interrupted an earlier next, step or
+stepout, Delve refuses a new one until it is finished: the error is
+OPERATION_IN_PROGRESS. continue finishes the pending one; --cancel-pending
+drops it and steps from where the program is (data.cancelledPending).
+
 Example:
-  godebug --addr $ADDR step`,
+  godebug --addr $ADDR step
+  godebug --addr $ADDR step --cancel-pending`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("step")
 		defer func() { _ = c.Close() }()
 
 		c.SetTimeout(GetTimeout())
 
-		state, err := c.Step()
+		state, cancelled, err := stepCancellingPending(c, c.Step, stepCancelPending)
 		if err != nil {
 			output.Error("step", err).PrintAndExit(GetOutputFormat())
 		}
 
-		output.Success("step", stateToData(state), "Stepped into function").PrintAndExit(GetOutputFormat())
+		stoppedAt := recordStop(c, "step", state)
+
+		data := stateToData(state)
+		stoppedAt.addTo(data)
+		if cancelled {
+			data["cancelledPending"] = true
+		}
+		if crash := captureCrash(c, state); crash != nil {
+			data["crash"] = crash
+		}
+
+		output.Success("step", data, "Stepped into function").PrintAndExit(GetOutputFormat())
 	},
 }
 
@@ -127,20 +268,37 @@ var stepoutCmd = &cobra.Command{
 	Short: "Step out of current function",
 	Long: `Step out of the current function to the caller.
 
+When a breakpoint in another goroutine interrupted an earlier next, step or
+stepout, Delve refuses a new one until it is finished: the error is
+OPERATION_IN_PROGRESS. continue finishes the pending one; --cancel-pending
+drops it and steps from where the program is (data.cancelledPending).
+
 Example:
-  godebug --addr $ADDR stepout`,
+  godebug --addr $ADDR stepout
+  godebug --addr $ADDR stepout --cancel-pending`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("stepout")
 		defer func() { _ = c.Close() }()
 
 		c.SetTimeout(GetTimeout())
 
-		state, err := c.StepOut()
+		state, cancelled, err := stepCancellingPending(c, c.StepOut, stepCancelPending)
 		if err != nil {
 			output.Error("stepout", err).PrintAndExit(GetOutputFormat())
 		}
 
-		output.Success("stepout", stateToData(state), "Stepped out of function").PrintAndExit(GetOutputFormat())
+		stoppedAt := recordStop(c, "stepout", state)
+
+		data := stateToData(state)
+		stoppedAt.addTo(data)
+		if cancelled {
+			data["cancelledPending"] = true
+		}
+		if crash := captureCrash(c, state); crash != nil {
+			data["crash"] = crash
+		}
+
+		output.Success("stepout", data, "Stepped out of function").PrintAndExit(GetOutputFormat())
 	},
 }
 
@@ -149,27 +307,161 @@ var restartCmd = &cobra.Command{
 	Short: "Restart the debugged program",
 	Long: `Restart the program from the beginning.
 
-All breakpoints are preserved.
+All breakpoints are preserved. In a session started with --backend rr,
+--from-checkpoint moves back to a checkpoint (see checkpoint) instead.
 
 Example:
-  godebug --addr $ADDR restart`,
+  godebug --addr $ADDR restart
+  godebug --addr $ADDR restart --from-checkpoint 1`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("restart")
 		defer func() { _ = c.Close() }()
 
-		state, err := c.Restart()
+		var state *api.DebuggerState
+		var err error
+		msg := "Program restarted"
+		if cmd.Flags().Changed("from-checkpoint") {
+			state, err = restartFromCheckpoint(c, restartCheckpoint)
+			msg = fmt.Sprintf("Restarted from checkpoint %d", restartCheckpoint)
+		} else {
+			state, err = c.Restart()
+		}
 		if err != nil {
 			output.Error("restart", err).PrintAndExit(GetOutputFormat())
 		}
 
-		output.Success("restart", stateToData(state), "Program restarted").PrintAndExit(GetOutputFormat())
+		stoppedAt := recordStop(c, "restart", state)
+
+		data := stateToData(state)
+		stoppedAt.addTo(data)
+		if cmd.Flags().Changed("from-checkpoint") {
+			data["checkpoint"] = restartCheckpoint
+		}
+		output.Success("restart", data, msg).PrintAndExit(GetOutputFormat())
 	},
 }
 
+// Delve backends start accepts
+var launchBackends = []string{"default", "native", "lldb", debugger.BackendRR}
+
+// validateBackend rejects unknown backends and rr for attach sessions
+func validateBackend(backend string, mode debugger.LaunchMode, getOutputFormat func() output.OutputFormat) string {
+	if !slices.Contains(launchBackends, backend) {
+		output.ErrorWithInfo("start", output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid backend: %s (expected default, native, lldb or rr)", backend),
+			map[string]any{"backend": backend},
+		)).PrintAndExit(getOutputFormat())
+	}
+	if backend == debugger.BackendRR && mode == debugger.ModeAttach {
+		output.ErrorWithInfo("start", output.InvalidArgument("the rr backend cannot attach to a running process")).PrintAndExit(getOutputFormat())
+	}
+	return backend
+}
+
+// recordingError rejects a feature of rr recordings (reverse execution,
+// checkpoints) in sessions that start launched without rr. Sessions godebug
+// did not launch are left for Delve to judge.
+func recordingError(addr, feature string) *output.ErrorInfo {
+	s, err := session.Load(addr)
+	if err != nil || s.Target == "" || s.Backend == debugger.BackendRR {
+		return nil
+	}
+	return output.InvalidArgumentWithDetails(
+		feature+" needs a session started with --backend rr",
+		map[string]any{"backend": s.Backend, "suggestion": "godebug start --backend rr " + s.Target},
+	)
+}
+
+// addReverseCommands adds the commands moving an rr recording backwards
+func addReverseCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	reverse := func(name, msg string, move func(*debugger.Client) (*api.DebuggerState, error)) func(*cobra.Command, []string) {
+		return func(cmd *cobra.Command, args []string) {
+			c := mustGetClient(name)
+			defer func() { _ = c.Close() }()
+			if info := recordingError(c.Addr(), "reverse execution"); info != nil {
+				output.ErrorWithInfo(name, info).PrintAndExit(getOutputFormat())
+			}
+
+			c.SetTimeout(getTimeout())
+			state, err := move(c)
+			if err != nil {
+				output.Error(name, err).PrintAndExit(getOutputFormat())
+			}
+
+			stoppedAt := recordStop(c, name, state)
+
+			data := stateToData(state)
+			stoppedAt.addTo(data)
+			data["reverse"] = true
+			output.Success(name, data, msg).PrintAndExit(getOutputFormat())
+		}
+	}
+
+	rewindCmd := &cobra.Command{
+		Use:     "rewind",
+		Aliases: []string{"reverse-continue"},
+		Short:   "Run backwards to the previous breakpoint",
+		Long: `Run the recording backwards until a breakpoint is hit or the start of the
+recording is reached. Needs a session started with --backend rr.
+
+reverse-continue is another name for rewind.
+
+Example:
+  godebug --addr $ADDR rewind
+  godebug --addr $ADDR reverse-continue`,
+		Args: cobra.NoArgs,
+		Run:  reverse("rewind", "Rewound to previous stop", (*debugger.Client).Rewind),
+	}
+
+	reverseNextCmd := &cobra.Command{
+		Use:   "reverse-next",
+		Short: "Step back to the previous source line",
+		Long: `Step backwards to the previous source line, stepping over function calls.
+Needs a session started with --backend rr.
+
+Example:
+  godebug --addr $ADDR reverse-next`,
+		Args: cobra.NoArgs,
+		Run:  reverse("reverse-next", "Stepped back to previous line", (*debugger.Client).ReverseNext),
+	}
+
+	reverseStepCmd := &cobra.Command{
+		Use:   "reverse-step",
+		Short: "Step back into the previous function call",
+		Long: `Step backwards to the previous source line, entering a function call that
+returned there. Needs a session started with --backend rr.
+
+Example:
+  godebug --addr $ADDR reverse-step`,
+		Args: cobra.NoArgs,
+		Run:  reverse("reverse-step", "Stepped back into function", (*debugger.Client).ReverseStep),
+	}
+
+	reverseStepoutCmd := &cobra.Command{
+		Use:   "reverse-stepout",
+		Short: "Step back out to the call of the current function",
+		Long: `Step backwards out of the current function to the line that called it.
+Needs a session started with --backend rr.
+
+Example:
+  godebug --addr $ADDR reverse-stepout`,
+		Args: cobra.NoArgs,
+		Run:  reverse("reverse-stepout", "Stepped back to caller", (*debugger.Client).ReverseStepOut),
+	}
+
+	root.AddCommand(rewindCmd, reverseNextCmd, reverseStepCmd, reverseStepoutCmd)
+}
+
 func init() {
+	addReverseCommands(rootCmd, MustGetClient, GetOutputFormat, GetTimeout)
 	rootCmd.AddCommand(continueCmd)
+	continueCmd.Flags().BoolVar(&continueNoTimeout, "no-timeout", false, "Wait until the program stops; interrupting godebug halts it")
 	rootCmd.AddCommand(nextCmd)
 	rootCmd.AddCommand(stepCmd)
 	rootCmd.AddCommand(stepoutCmd)
+	for _, cmd := range []*cobra.Command{nextCmd, stepCmd, stepoutCmd} {
+		cmd.Flags().BoolVar(&stepCancelPending, "cancel-pending", false, "Cancel a next, step or stepout a breakpoint interrupted and step anyway")
+	}
 	rootCmd.AddCommand(restartCmd)
+	restartCmd.Flags().IntVar(&restartCheckpoint, "from-checkpoint", 0, "Restart an rr recording from this checkpoint")
 }
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// TestRecordingError checks that only sessions launched without rr are refused
+// reverse execution.
+func TestRecordingError(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	native := session.New("127.0.0.1:40001")
+	native.Target = "./cmd/app"
+	recorded := session.New("127.0.0.1:40002")
+	recorded.Target, recorded.Backend = "./cmd/app", debugger.BackendRR
+	for _, s := range []*session.Session{native, recorded} {
+		if err := s.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if recordingError(native.Addr, "reverse execution") == nil {
+		t.Error("a native session should be refused reverse execution")
+	}
+	if info := recordingError(recorded.Addr, "reverse execution"); info != nil {
+		t.Errorf("an rr session was refused: %v", info)
+	}
+	if info := recordingError("127.0.0.1:40003", "reverse execution"); info != nil {
+		t.Errorf("a session godebug did not launch was refused: %v", info)
+	}
+}
+
+// TestIsPendingStep checks that Delve's refusals of a command while another
+// operation is pending are classified, and only a pending step is cancelled.
+func TestIsPendingStep(t *testing.T) {
+	tests := []struct {
+		err       string
+		operation string
+		pending   bool
+	}{
+		{"next while nexting", output.PendingStep, true},
+		{"cannot call function while another function call is already in progress", output.PendingCall, false},
+		{"core dump in progress", output.PendingCoreDump, false},
+		{"could not find statement", "", false},
+	}
+	for _, tt := range tests {
+		err := errors.New(tt.err)
+		if got := isPendingStep(err); got != tt.pending {
+			t.Errorf("isPendingStep(%q) = %v, want %v", tt.err, got, tt.pending)
+		}
+		info := output.FromError(err)
+		if tt.operation == "" {
+			if info.Code == output.ErrCodeOperationInProgress {
+				t.Errorf("%q classified as %s", tt.err, info.Code)
+			}
+			continue
+		}
+		details, _ := info.Details.(map[string]any)
+		if info.Code != output.ErrCodeOperationInProgress || details["operation"] != tt.operation {
+			t.Errorf("%q = %s %v, want %s operation %q", tt.err, info.Code, info.Details, output.ErrCodeOperationInProgress, tt.operation)
+		}
+	}
+	if code := output.ExitCodeFor(output.ErrCodeOperationInProgress); code != output.ExitInvalidState {
+		t.Errorf("exit code = %d, want %d", code, output.ExitInvalidState)
+	}
+}
@@ -0,0 +1,416 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// Outcomes of an exploration
+const (
+	exploreComplete = "complete" // every candidate function was reached
+	exploreExited   = "exited"   // the program exited first
+	exploreCrash    = "crash"    // the program panicked or hit a fatal error
+	exploreBudget   = "budget"   // --budget expired
+	exploreStopped  = "stopped"  // the program stopped somewhere else
+	exploreError    = "error"    // the session failed
+)
+
+const (
+	exploreMaxVars   = 50 // package variables recorded at main
+	explorePathDepth = 10 // frames walked for the call path of a visit
+)
+
+// exploreVisit is the first hit of a candidate function
+type exploreVisit struct {
+	Function  string            `json:"function"`
+	File      string            `json:"file"`
+	Line      int               `json:"line"`
+	Goroutine int64             `json:"goroutine"`
+	AtMs      int64             `json:"atMs"`
+	Path      []string          `json:"path,omitempty"`
+	Args      map[string]string `json:"args,omitempty"`
+	Locals    map[string]string `json:"locals,omitempty"`
+	Errors    []string          `json:"errors,omitempty"`
+}
+
+// exploreEdge is a call between user functions seen on the visits' stacks
+type exploreEdge struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+	Count  int    `json:"count"`
+}
+
+// exploreCandidates picks the functions explore breaks on: those of the main
+// module, except main.main, which is the first stop anyway, package
+// initialization, which runs before it, and method value wrappers
+func exploreCandidates(functions []string, inMainModule func(string) bool) []string {
+	var candidates []string
+	for _, fn := range listedFunctions(functions, inMainModule, false) {
+		sym := parseFuncSymbol(fn)
+		if fn == "main.main" || strings.HasPrefix(sym.Name, "init") || strings.HasSuffix(fn, "-fm") {
+			continue
+		}
+		candidates = append(candidates, fn)
+	}
+	return candidates
+}
+
+// exploreCallPath names the user functions on a stack, innermost first,
+// starting with the function the stack stopped in
+func exploreCallPath(frames []api.Stackframe) []string {
+	var path []string
+	for _, f := range frames {
+		if f.Function == nil || !isUserSource(f.File) {
+			continue
+		}
+		name := stripTypeParams(f.Function.Name())
+		if len(path) == 0 || path[len(path)-1] != name {
+			path = append(path, name)
+		}
+	}
+	return path
+}
+
+// exploreCallGraph counts the caller-callee pairs on the visits' call paths
+func exploreCallGraph(visits []exploreVisit) []exploreEdge {
+	index := map[[2]string]int{}
+	var edges []exploreEdge
+	for _, v := range visits {
+		for i := 0; i+1 < len(v.Path); i++ {
+			key := [2]string{v.Path[i+1], v.Path[i]}
+			n, ok := index[key]
+			if !ok {
+				n = len(edges)
+				index[key] = n
+				edges = append(edges, exploreEdge{Caller: key[0], Callee: key[1]})
+			}
+			edges[n].Count++
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].Caller != edges[j].Caller {
+			return edges[i].Caller < edges[j].Caller
+		}
+		return edges[i].Callee < edges[j].Callee
+	})
+	return edges
+}
+
+// compactVars renders variables by name on one line each
+func compactVars(vars []api.Variable) map[string]string {
+	if len(vars) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(vars))
+	for i := range vars {
+		m[vars[i].Name] = compactValue(&vars[i])
+	}
+	return m
+}
+
+// exploreSample records the stop of goroutineID in fn: its call path and a
+// shallow load of its args and locals
+func exploreSample(c *debugger.Client, fn string, goroutineID int64, loc *api.Location, since time.Duration) exploreVisit {
+	v := exploreVisit{Function: fn, Goroutine: goroutineID, AtMs: since.Milliseconds()}
+	if loc != nil {
+		v.File, v.Line = loc.File, loc.Line
+	}
+	fail := func(section string, err error) {
+		v.Errors = append(v.Errors, fmt.Sprintf("%s: %v", section, err))
+	}
+	if frames, err := c.Stacktrace(goroutineID, explorePathDepth, nil); err != nil {
+		fail("stack", err)
+	} else {
+		v.Path = exploreCallPath(frames)
+	}
+	cfg := annotateLoadConfig()
+	if vars, err := c.ListFunctionArgs(goroutineID, 0, cfg); err != nil {
+		fail("args", err)
+	} else {
+		v.Args = compactVars(vars)
+	}
+	if vars, err := c.ListLocalVars(goroutineID, 0, cfg); err != nil {
+		fail("locals", err)
+	} else {
+		v.Locals = compactVars(vars)
+	}
+	return v
+}
+
+// exploreMain runs the target to main.main and records the state there: the
+// goroutines and the main module's package variables. It returns nil if the
+// program stopped anywhere else; state is where it stopped.
+func exploreMain(ctx context.Context, c *debugger.Client, inMainModule func(string) bool) (map[string]any, *api.DebuggerState, error) {
+	bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.main"})
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := c.ContinueWithContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	th := state.CurrentThread
+	if state.Exited || th == nil || th.Breakpoint == nil || th.Breakpoint.ID != bp.ID {
+		return nil, state, nil
+	}
+	_, _ = c.ClearBreakpoint(bp.ID)
+
+	at := map[string]any{"file": th.File, "line": th.Line}
+	if goroutines, _, err := c.ListGoroutines(0, 0); err == nil {
+		at["goroutines"] = len(goroutines)
+	}
+	if vars, err := c.ListPackageVars("", annotateLoadConfig()); err == nil {
+		listed := listedPackageVars(vars, inMainModule, false)
+		if len(listed) > exploreMaxVars {
+			listed = listed[:exploreMaxVars]
+			at["packageVarsTruncated"] = true
+		}
+		if m := compactVars(listed); m != nil {
+			at["packageVars"] = m
+		}
+	}
+	return at, state, nil
+}
+
+// addExploreCommand adds the explore command
+func addExploreCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var exploreTarget, exploreMode string
+	var exploreBudgetDur time.Duration
+	var exploreMaxFunctions int
+
+	exploreCmd := &cobra.Command{
+		Use:   "explore",
+		Short: "Run the program within a time budget and map what it does",
+		Long: `Launch the target and explore it on its own within --budget: run to
+main.main and record the state there, set a breakpoint on the entry of every
+function of the main module (at most --max-functions), then continue from hit
+to hit. The first hit of each function records where it was reached, the
+user call path leading to it and its args and locals; the breakpoint is then
+removed, so the exploration spreads across functions instead of repeating a
+loop.
+
+The result is a map of observed behavior to plan a debugging session from:
+  main        state at main.main: goroutines and package variables
+  visited     each function reached, in order, with its sample
+  unvisited   candidates not reached within the budget
+  callGraph   caller -> callee edges seen on the visits' stacks
+  outcome     complete, exited, crash, budget, stopped or error
+
+Breakpoints that cannot be set are reported with data.partial (see
+break --assign). The session exists only for the exploration; the target's
+output is kept in the files data.stdout and data.stderr.
+
+Example:
+  godebug explore --target ./cmd/app --budget 60s
+  godebug explore --target ./app --mode exec --max-functions 200 -- -config dev.yaml`,
+		Args: cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if exploreTarget == "" {
+				output.ErrorWithInfo("explore", output.InvalidArgument("--target is required")).PrintAndExit(getOutputFormat())
+			}
+			if len(args) > 0 && cmd.ArgsLenAtDash() != 0 {
+				output.ErrorWithInfo("explore", output.InvalidArgumentWithDetails(
+					"program arguments go after --",
+					map[string]any{"args": args},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if exploreBudgetDur <= 0 {
+				output.ErrorWithInfo("explore", output.InvalidArgument("--budget must be positive")).PrintAndExit(getOutputFormat())
+			}
+			if exploreMaxFunctions < 1 {
+				output.ErrorWithInfo("explore", output.InvalidArgument("--max-functions must be at least 1")).PrintAndExit(getOutputFormat())
+			}
+			mode, ok := launchMode(exploreMode)
+			if !ok || mode == debugger.ModeAttach {
+				output.ErrorWithInfo("explore", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid mode: %s (expected debug, test or exec)", exploreMode),
+					map[string]any{"mode": exploreMode},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			redirects, stdout, stderr, err := crashRedirects()
+			if err != nil {
+				output.Error("explore", err).PrintAndExit(getOutputFormat())
+			}
+			result, err := debugger.Launch(debugger.LaunchConfig{Mode: mode, Target: exploreTarget, Args: args, Redirects: redirects, Timeout: getTimeout()})
+			if err != nil {
+				output.Error("explore", err).PrintAndExit(getOutputFormat())
+			}
+			// The budget starts once the target is built and launched
+			start := time.Now()
+			ctx, cancel := context.WithTimeout(context.Background(), exploreBudgetDur)
+			c, err := debugger.Connect(result.Addr)
+			if err != nil {
+				cancel()
+				_ = result.Kill()
+				_ = session.Remove(result.Addr)
+				output.Error("explore", err).PrintAndExit(getOutputFormat())
+			}
+			c.SetTimeout(getTimeout())
+			// The session exists only for this exploration. A continue the
+			// budget cut short is still running, so the server is killed
+			// rather than asked to detach.
+			cleanup := func() {
+				cancel()
+				_ = c.Close()
+				_ = result.Kill()
+				_ = session.Remove(result.Addr)
+			}
+
+			data := map[string]any{
+				"target":   result.Target,
+				"mode":     result.Mode,
+				"budgetMs": exploreBudgetDur.Milliseconds(),
+				"stdout":   stdout,
+				"stderr":   stderr,
+			}
+			var state *api.DebuggerState
+			outcome := ""
+			// finish records how the program stopped exploring, once state
+			// is neither a visit nor main
+			finish := func(err error) {
+				switch {
+				case err != nil && ctx.Err() != nil:
+					outcome = exploreBudget
+				case err != nil:
+					outcome = exploreError
+					data["error"] = err.Error()
+				case state.Exited:
+					outcome = exploreExited
+					data["exitStatus"] = state.ExitStatus
+				case crashReason(state) != "":
+					outcome = exploreCrash
+					crash := map[string]any{"reason": crashReason(state)}
+					if g := state.SelectedGoroutine; g != nil {
+						if frames, err := c.Stacktrace(g.ID, explorePathDepth, nil); err == nil {
+							crash["signature"] = crashSignature(frames)
+							crash["path"] = exploreCallPath(frames)
+						}
+					}
+					data["crash"] = crash
+				default:
+					outcome = exploreStopped
+					data["stop"] = stateToData(state)
+				}
+			}
+
+			inMainModule := mainModuleFilter(mainModulePath(c))
+			mainState, state, err := exploreMain(ctx, c, inMainModule)
+			if mainState != nil {
+				data["main"] = mainState
+			} else {
+				finish(err)
+			}
+
+			var visits []exploreVisit
+			var unvisited []string
+			if outcome == "" {
+				functions, err := c.ListFunctions("")
+				if err != nil {
+					cleanup()
+					output.Error("explore", err).PrintAndExit(getOutputFormat())
+				}
+				candidates := exploreCandidates(functions, inMainModule)
+				data["candidates"] = len(candidates)
+				if len(candidates) > exploreMaxFunctions {
+					candidates = candidates[:exploreMaxFunctions]
+					data["candidatesTruncated"] = true
+				}
+
+				var results itemResults
+				pending := map[int]string{}
+				for _, fn := range candidates {
+					bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: fn})
+					if err != nil {
+						results.fail(map[string]any{"function": fn}, err)
+						continue
+					}
+					pending[bp.ID] = fn
+					results.ok(map[string]any{"id": bp.ID, "function": fn})
+				}
+				results.apply(data, "breakpoints")
+
+				for len(pending) > 0 && outcome == "" {
+					state, err = c.ContinueWithContext(ctx)
+					if err != nil || state.Exited || crashReason(state) != "" {
+						finish(err)
+						break
+					}
+					hit := false
+					for _, th := range state.Threads {
+						if th.Breakpoint == nil {
+							continue
+						}
+						fn, ok := pending[th.Breakpoint.ID]
+						if !ok || th.GoroutineID == 0 {
+							continue
+						}
+						delete(pending, th.Breakpoint.ID)
+						_, _ = c.ClearBreakpoint(th.Breakpoint.ID)
+						visits = append(visits, exploreSample(c, fn, th.GoroutineID, &api.Location{File: th.File, Line: th.Line}, time.Since(start)))
+						hit = true
+					}
+					if !hit {
+						finish(nil)
+					}
+				}
+				if outcome == "" {
+					outcome = exploreComplete
+				}
+				for _, fn := range pending {
+					unvisited = append(unvisited, fn)
+				}
+				sort.Strings(unvisited)
+			}
+			cleanup()
+
+			if visits == nil {
+				visits = []exploreVisit{}
+			}
+			data["visited"] = visits
+			data["unvisited"] = nonNilStrings(unvisited)
+			data["callGraph"] = exploreCallGraph(visits)
+			data["outcome"] = outcome
+			data["durationMs"] = time.Since(start).Milliseconds()
+			if outcome == exploreCrash {
+				if msg := panicMessage(tailLines(stderr, crashOutputLines)); msg != "" {
+					data["crash"].(map[string]any)["message"] = msg
+				}
+			}
+
+			msg := fmt.Sprintf("Reached %d functions; %s", len(visits), outcome)
+			if n, ok := data["candidates"].(int); ok {
+				msg = fmt.Sprintf("Reached %d of %d functions; %s", len(visits), min(n, exploreMaxFunctions), outcome)
+			}
+			output.Success("explore", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	exploreCmd.Flags().StringVar(&exploreTarget, "target", "", "Package, test package or binary to explore")
+	exploreCmd.Flags().StringVar(&exploreMode, "mode", "debug", "Debug mode: debug, test, or exec")
+	exploreCmd.Flags().DurationVar(&exploreBudgetDur, "budget", 60*time.Second, "Time the exploration may run the program")
+	exploreCmd.Flags().IntVar(&exploreMaxFunctions, "max-functions", 100, "Maximum number of functions to break on")
+	root.AddCommand(exploreCmd)
+}
+
+// nonNilStrings returns s, or an empty slice so it is encoded as []
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func init() {
+	addExploreCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestExploreCandidates(t *testing.T) {
+	functions := []string{
+		"main.main",
+		"main.init",
+		"main.init.0",
+		"main.init.func1",
+		"main.process",
+		"main.(*Server).Handle",
+		"main.(*Server).Handle-fm",
+		"main.Map[go.shape.int].Get",
+		"main.Map[go.shape.string].Get",
+		"runtime.main",
+		"fmt.Println",
+	}
+	got := exploreCandidates(functions, mainModuleFilter(""))
+	want := []string{"main.(*Server).Handle", "main.Map.Get", "main.process"}
+	if !slices.Equal(got, want) {
+		t.Errorf("exploreCandidates = %v, want %v", got, want)
+	}
+}
+
+func TestExploreCallGraph(t *testing.T) {
+	frame := func(name, file string) api.Stackframe {
+		return api.Stackframe{Location: api.Location{File: file, Function: &api.Function{Name_: name}}}
+	}
+	frames := []api.Stackframe{
+		frame("main.parse", "/app/parse.go"),
+		frame("main.parse", "/app/parse.go"),
+		frame("main.load", "/app/load.go"),
+		frame("runtime.goexit", "/usr/local/go/src/runtime/asm_amd64.s"),
+	}
+	path := exploreCallPath(frames)
+	if !slices.Equal(path, []string{"main.parse", "main.load"}) {
+		t.Fatalf("exploreCallPath = %v", path)
+	}
+
+	visits := []exploreVisit{
+		{Function: "main.parse", Path: path},
+		{Function: "main.load", Path: []string{"main.load", "main.main"}},
+		{Function: "main.parse", Path: path},
+	}
+	edges := exploreCallGraph(visits)
+	want := []exploreEdge{
+		{Caller: "main.load", Callee: "main.parse", Count: 2},
+		{Caller: "main.main", Callee: "main.load", Count: 1},
+	}
+	if !slices.Equal(edges, want) {
+		t.Errorf("exploreCallGraph = %v, want %v", edges, want)
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+)
+
+// runFake runs godebug with args against a fake target and decodes the
+// JSON response it prints
+func runFake(t *testing.T, args ...string) map[string]any {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runCLI(append([]string{"--addr", "fake:"}, args...))
+	os.Stdout = stdout
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+
+	var resp map[string]any
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatalf("%v: invalid response %q: %v", args, out, err)
+	}
+	return resp
+}
+
+// TestFakeTargetSession drives a session through break, continue and
+// inspection against the sample program of the fake target. The state of
+// the fake carries over between invocations as a Delve server's would.
+func TestFakeTargetSession(t *testing.T) {
+	setupFuzzTest(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if resp := runFake(t, "break", "main.square", "--cond", "n == 3"); resp["success"] != true {
+		t.Fatalf("break: %v", resp)
+	}
+	resp := runFake(t, "continue")
+	data, _ := resp["data"].(map[string]any)
+	loc, _ := data["location"].(map[string]any)
+	if loc["function"] != "main.square" || loc["line"] != float64(20) {
+		t.Fatalf("continue stopped at %v, want main.square:20", resp)
+	}
+
+	resp = runFake(t, "print", "n")
+	if data, _ := resp["data"].(map[string]any); data["value"] != "3" {
+		t.Errorf("print n = %v, want 3", resp)
+	}
+	resp = runFake(t, "stack")
+	if data, _ := resp["data"].(map[string]any); data["count"] != float64(2) {
+		t.Errorf("stack = %v, want 2 frames", resp)
+	}
+
+	resp = runFake(t, "continue")
+	if data, _ := resp["data"].(map[string]any); data["exited"] != true {
+		t.Errorf("second continue = %v, want the program to exit", resp)
+	}
+	resp = runFake(t, "print", "n")
+	if resp["success"] != false {
+		t.Errorf("print after exit = %v, want an error", resp)
+	}
+}
+
+// TestSuspendedBreakpoint checks that break --suspended keeps a breakpoint
+// whose function is not in the binary instead of failing
+func TestSuspendedBreakpoint(t *testing.T) {
+	setupFuzzTest(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	if resp := runFake(t, "break", "main.Handle"); resp["success"] != false {
+		t.Fatalf("break without --suspended = %v, want an error", resp)
+	}
+	resp := runFake(t, "break", "main.Handle", "--suspended")
+	data, _ := resp["data"].(map[string]any)
+	if resp["success"] != true || data["suspended"] != true {
+		t.Fatalf("break --suspended = %v, want a suspended breakpoint", resp)
+	}
+	if _, ok := data["warnings"]; ok {
+		t.Errorf("break --suspended warns about the missing address: %v", data["warnings"])
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// applyFastStart applies start's --continue, --build-cache-dir and
+// --reuse-build to config. With --reuse-build the target is built, or its
+// earlier build reused, and config launches that binary in exec mode.
+func applyFastStart(config *debugger.LaunchConfig, cont bool, buildCacheDir string, reuse bool, getOutputFormat func() output.OutputFormat) *debugger.Prebuild {
+	fail := func(info *output.ErrorInfo) {
+		output.ErrorWithInfo("start", info).PrintAndExit(getOutputFormat())
+	}
+	if cont && config.Mode == debugger.ModeAttach {
+		fail(output.InvalidArgument("--continue does not apply to attach mode (use --no-halt)"))
+	}
+	config.Continue = config.Continue || cont
+
+	if buildCacheDir != "" {
+		if config.Mode != debugger.ModeDebug && config.Mode != debugger.ModeTest {
+			fail(output.InvalidArgumentWithDetails("--build-cache-dir only applies to debug and test mode", map[string]any{"mode": string(config.Mode)}))
+		}
+		dir, err := filepath.Abs(buildCacheDir)
+		if err != nil {
+			output.Error("start", err).PrintAndExit(getOutputFormat())
+		}
+		config.BuildCacheDir = dir
+	}
+
+	if !reuse {
+		return nil
+	}
+	if config.Mode != debugger.ModeDebug {
+		fail(output.InvalidArgumentWithDetails("--reuse-build only applies to debug mode", map[string]any{"mode": string(config.Mode)}))
+	}
+	dir, err := session.BuildsDir()
+	if err != nil {
+		output.Error("start", err).PrintAndExit(getOutputFormat())
+	}
+	prebuilt, prebuild, err := debugger.PrebuildTarget(*config, dir)
+	if err != nil {
+		output.Error("start", err).PrintAndExit(getOutputFormat())
+	}
+	*config = prebuilt
+	return prebuild
+}
+
+// fastStartData reports a prebuilt binary and a target started running in
+// start's response and message
+func fastStartData(data map[string]any, msg string, prebuild *debugger.Prebuild, running bool) string {
+	if prebuild != nil {
+		data["build"] = prebuild
+		if prebuild.Reused {
+			msg += " (reused build)"
+		}
+	}
+	if running {
+		data["running"] = true
+		msg += ", program running"
+	}
+	return msg
+}
@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// listenerAcceptFunc matches the frame of a goroutine blocked accepting on a listener
+var listenerAcceptFunc = regexp.MustCompile(`^net\.\(\*(TCPListener|UnixListener)\)\.accept$`)
+
+// listenerStackDepth bounds the stack searched for an accept frame
+const listenerStackDepth = 30
+
+// socketInfo describes the socket behind a file descriptor
+type socketInfo struct {
+	Proto  string `json:"proto"`
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote,omitempty"`
+	State  string `json:"state,omitempty"`
+}
+
+// listenerInfo is the goroutine accepting on a listening socket and the user
+// code that started it
+type listenerInfo struct {
+	GoroutineID int64  `json:"goroutineId"`
+	Function    string `json:"function,omitempty"`
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`
+}
+
+// fdEntry is one open file descriptor of the target
+type fdEntry struct {
+	FD       int           `json:"fd"`
+	Type     string        `json:"type"`
+	Target   string        `json:"target"`
+	Socket   *socketInfo   `json:"socket,omitempty"`
+	Listener *listenerInfo `json:"listener,omitempty"`
+}
+
+// tcpStates maps /proc/net/tcp state codes to names
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED", "02": "SYN_SENT", "03": "SYN_RECV", "04": "FIN_WAIT1",
+	"05": "FIN_WAIT2", "06": "TIME_WAIT", "07": "CLOSE", "08": "CLOSE_WAIT",
+	"09": "LAST_ACK", "0A": "LISTEN", "0B": "CLOSING",
+}
+
+// parseProcAddr decodes a /proc/net address such as 0100007F:1F90. The IP is
+// stored as native-endian 32-bit words, the port big-endian.
+func parseProcAddr(s string) string {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return s
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || len(raw)%4 != 0 {
+		return s
+	}
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return s
+	}
+	return net.JoinHostPort(net.IP(raw).String(), strconv.FormatUint(port, 10))
+}
+
+// parseProcNet indexes the sockets of a /proc/net/{tcp,tcp6,udp,udp6} table by inode
+func parseProcNet(content, proto string, sockets map[string]*socketInfo) {
+	for _, line := range strings.Split(content, "\n")[1:] {
+		f := strings.Fields(line)
+		if len(f) < 10 {
+			continue
+		}
+		info := &socketInfo{Proto: proto, Local: parseProcAddr(f[1]), Remote: parseProcAddr(f[2])}
+		if strings.HasPrefix(proto, "tcp") {
+			info.State = tcpStates[f[3]]
+		}
+		// Listening and unconnected sockets have no peer
+		if strings.HasSuffix(info.Remote, ":0") {
+			info.Remote = ""
+		}
+		sockets[f[9]] = info
+	}
+}
+
+// parseProcNetUnix indexes the sockets of /proc/net/unix by inode
+func parseProcNetUnix(content string, sockets map[string]*socketInfo) {
+	for _, line := range strings.Split(content, "\n")[1:] {
+		f := strings.Fields(line)
+		if len(f) < 7 {
+			continue
+		}
+		info := &socketInfo{Proto: "unix"}
+		if len(f) > 7 {
+			info.Local = f[7]
+		}
+		// __SO_ACCEPTCON marks a listening socket
+		if f[3] == "00010000" {
+			info.State = "LISTEN"
+		} else if f[5] == "03" {
+			info.State = "CONNECTED"
+		}
+		sockets[f[6]] = info
+	}
+}
+
+// procFds lists the descriptors of pid from /proc, resolving sockets through
+// the process's network namespace tables
+func procFds(pid int) ([]fdEntry, error) {
+	dir := fmt.Sprintf("/proc/%d/fd", pid)
+	names, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	sockets := map[string]*socketInfo{}
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		if raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/%s", pid, proto)); err == nil {
+			parseProcNet(string(raw), proto, sockets)
+		}
+	}
+	if raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/unix", pid)); err == nil {
+		parseProcNetUnix(string(raw), sockets)
+	}
+
+	var fds []fdEntry
+	for _, name := range names {
+		fd, err := strconv.Atoi(name.Name())
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(dir, name.Name()))
+		if err != nil {
+			continue
+		}
+		entry := fdEntry{FD: fd, Target: target, Type: "file"}
+		switch {
+		case strings.HasPrefix(target, "socket:["):
+			entry.Type = "socket"
+			entry.Socket = sockets[strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")]
+		case strings.HasPrefix(target, "pipe:["):
+			entry.Type = "pipe"
+		case strings.HasPrefix(target, "anon_inode:"):
+			entry.Type = "anon"
+		}
+		fds = append(fds, entry)
+	}
+	return fds, nil
+}
+
+// lsofFds lists the descriptors of pid with lsof, for systems without /proc
+func lsofFds(pid int) ([]fdEntry, error) {
+	out, err := exec.Command("lsof", "-n", "-P", "-p", strconv.Itoa(pid), "-F", "ftnT").Output()
+	if err != nil {
+		return nil, fmt.Errorf("lsof: %w", err)
+	}
+
+	var fds []fdEntry
+	var cur *fdEntry
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		value := line[1:]
+		switch line[0] {
+		case 'f':
+			cur = nil
+			if fd, err := strconv.Atoi(value); err == nil {
+				fds = append(fds, fdEntry{FD: fd, Type: "file"})
+				cur = &fds[len(fds)-1]
+			}
+		case 't':
+			if cur == nil {
+				continue
+			}
+			switch value {
+			case "IPv4", "IPv6":
+				cur.Type = "socket"
+				cur.Socket = &socketInfo{Proto: strings.ToLower(value)}
+			case "unix":
+				cur.Type = "socket"
+				cur.Socket = &socketInfo{Proto: "unix"}
+			case "PIPE", "FIFO":
+				cur.Type = "pipe"
+			}
+		case 'n':
+			if cur == nil {
+				continue
+			}
+			cur.Target = value
+			if cur.Socket != nil {
+				local, remote, _ := strings.Cut(value, "->")
+				cur.Socket.Local, cur.Socket.Remote = local, remote
+			}
+		case 'T':
+			if cur != nil && cur.Socket != nil && strings.HasPrefix(value, "ST=") {
+				cur.Socket.State = strings.TrimPrefix(value, "ST=")
+			}
+		}
+	}
+	return fds, nil
+}
+
+// findListeners maps listening descriptors to the goroutines accepting on them.
+// Only goroutines parked in IO wait can be blocked in Accept.
+func findListeners(c *debugger.Client) map[int]*listenerInfo {
+	goroutines, _, err := c.ListGoroutines(0, 0)
+	if err != nil {
+		return nil
+	}
+	ver := targetGoVersion(c)
+
+	listeners := map[int]*listenerInfo{}
+	for _, g := range goroutines {
+		if ver != nil && waitReasonName(ver, g.WaitReason) != "IO wait" {
+			continue
+		}
+		frames, err := c.Stacktrace(g.ID, listenerStackDepth, nil)
+		if err != nil {
+			continue
+		}
+		for i, f := range frames {
+			if f.Function == nil || !listenerAcceptFunc.MatchString(f.Function.Name()) {
+				continue
+			}
+			v, err := c.EvalInScope(api.EvalScope{GoroutineID: g.ID, Frame: i}, "ln.fd.pfd.Sysfd", api.LoadConfig{})
+			if err != nil {
+				break
+			}
+			fd, err := strconv.Atoi(v.Value)
+			if err != nil {
+				break
+			}
+			info := &listenerInfo{GoroutineID: g.ID}
+			// Attribute the listener to the first caller outside the standard library
+			for _, caller := range frames[i+1:] {
+				if caller.Function != nil && isUserSource(caller.File) {
+					info.Function, info.File, info.Line = caller.Function.Name(), caller.File, caller.Line
+					break
+				}
+			}
+			listeners[fd] = info
+			break
+		}
+	}
+	return listeners
+}
+
+// addFdsCommand adds the fds command
+func addFdsCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var fdsSockets bool
+
+	fdsCmd := &cobra.Command{
+		Use:   "fds",
+		Short: "List the target's open files and sockets",
+		Long: `List the open file descriptors of the debugged process: files, pipes and
+sockets with their local/remote address and state.
+
+Descriptors are read from /proc on Linux and with lsof elsewhere. Listening
+sockets are matched to the goroutine blocked accepting on them and the user
+code that started it, which shows which net.Listener owns which port.
+
+Example:
+  godebug --addr $ADDR fds
+  godebug --addr $ADDR fds --sockets`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("fds")
+			defer func() { _ = c.Close() }()
+
+			pid, err := c.ProcessPid()
+			if err != nil {
+				output.Error("fds", err).PrintAndExit(getOutputFormat())
+			}
+
+			source := "/proc"
+			fds, err := procFds(pid)
+			if err != nil {
+				source = "lsof"
+				if fds, err = lsofFds(pid); err != nil {
+					output.Error("fds", err).PrintAndExit(getOutputFormat())
+				}
+			}
+			sort.Slice(fds, func(i, j int) bool { return fds[i].FD < fds[j].FD })
+
+			listeners := findListeners(c)
+			counts := map[string]int{}
+			filtered := []fdEntry{}
+			for _, fd := range fds {
+				if fd.Socket != nil && fd.Socket.State == "LISTEN" {
+					fd.Listener = listeners[fd.FD]
+				}
+				counts[fd.Type]++
+				if !fdsSockets || fd.Type == "socket" {
+					filtered = append(filtered, fd)
+				}
+			}
+
+			data := map[string]any{
+				"pid":    pid,
+				"fds":    filtered,
+				"count":  len(filtered),
+				"byType": counts,
+				"source": source,
+			}
+
+			output.Success("fds", data, fmt.Sprintf("%d open descriptors (%d sockets)", len(fds), counts["socket"])).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	fdsCmd.Flags().BoolVar(&fdsSockets, "sockets", false, "Only list sockets")
+	root.AddCommand(fdsCmd)
+}
+
+func init() {
+	addFdsCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
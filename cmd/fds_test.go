@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseProcNet checks address decoding for IPv4 and IPv6 and that
+// listening sockets have no remote address.
+func TestParseProcNet(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 4242 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000  1000        0 4243 1 0000000000000000 20 4 30 10 -1`
+	tcp6 := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 99 1 0000000000000000 100 0 0 10 0`
+
+	sockets := map[string]*socketInfo{}
+	parseProcNet(tcp, "tcp", sockets)
+	parseProcNet(tcp6, "tcp6", sockets)
+
+	want := map[string]*socketInfo{
+		"4242": {Proto: "tcp", Local: "127.0.0.1:8080", State: "LISTEN"},
+		"4243": {Proto: "tcp", Local: "127.0.0.1:8080", Remote: "127.0.0.1:50000", State: "ESTABLISHED"},
+		"99":   {Proto: "tcp6", Local: "[::1]:80", State: "LISTEN"},
+	}
+	if !reflect.DeepEqual(sockets, want) {
+		for k, v := range sockets {
+			t.Logf("%s: %+v", k, *v)
+		}
+		t.Errorf("parseProcNet mismatch")
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// selectedFrameFile holds the frame chosen with frame, up or down
+const selectedFrameFile = "frame.json"
+
+// frameSelection is the persisted frame of a goroutine at one stop. Delve has
+// no notion of a selected frame, so the CLI keeps it between invocations.
+type frameSelection struct {
+	GoroutineID int64 `json:"goroutineId"`
+	Frame       int   `json:"frame"`
+	// PC and LastStop identify the stop the selection was made at, and
+	// FrameOffset (frame 0's CFA) the invocation, for recursive code or loops
+	// that stop at the same PC again
+	PC          uint64    `json:"pc"`
+	LastStop    time.Time `json:"lastStop"`
+	FrameOffset int64     `json:"frameOffset,omitempty"`
+}
+
+// lastStopTime returns when the session last stopped, or the zero time
+func lastStopTime(addr string) time.Time {
+	stops := loadStops(addr)
+	if len(stops) == 0 {
+		return time.Time{}
+	}
+	return stops[len(stops)-1].Time
+}
+
+// frameSelectionStatus compares the persisted selection with the current stop
+// of goroutine g, whose frame 0 has the CFA frameOffset. A selection made
+// before the last stop godebug recorded is simply over, and every stop starts
+// at frame 0; one whose stop the target left without godebug recording a new
+// one (another client resumed it) is stale.
+func frameSelectionStatus(sel frameSelection, g *api.Goroutine, frameOffset int64, lastStop time.Time) (frame int, stale bool) {
+	if sel.GoroutineID != g.ID || !sel.LastStop.Equal(lastStop) {
+		return 0, false
+	}
+	if sel.PC != g.CurrentLoc.PC || (sel.FrameOffset != 0 && sel.FrameOffset != frameOffset) {
+		return 0, sel.Frame > 0
+	}
+	return sel.Frame, false
+}
+
+// selectedFrame returns the persisted frame of goroutineID. The selection is
+// discarded once the target has moved, so every stop starts at frame 0.
+func selectedFrame(addr string, state *api.DebuggerState, goroutineID int64) int {
+	g := state.SelectedGoroutine
+	if g == nil || g.ID != goroutineID {
+		return 0
+	}
+	var sel frameSelection
+	if err := session.LoadData(addr, selectedFrameFile, &sel); err != nil {
+		return 0
+	}
+	frame, _ := frameSelectionStatus(sel, g, sel.FrameOffset, lastStopTime(addr))
+	return frame
+}
+
+// mustSelectedFrame is selectedFrame for commands that read the frame's
+// scope: when the selection is stale it exits with STALE_CONTEXT and the new
+// location instead of silently reading another scope. The stale selection is
+// dropped, so the next command starts at frame 0.
+func mustSelectedFrame(c *debugger.Client, cmdName string, state *api.DebuggerState, goroutineID int64, getOutputFormat func() output.OutputFormat) int {
+	g := state.SelectedGoroutine
+	if g == nil || g.ID != goroutineID {
+		return 0
+	}
+	var sel frameSelection
+	if err := session.LoadData(c.Addr(), selectedFrameFile, &sel); err != nil || sel.Frame == 0 {
+		return 0
+	}
+	frameOffset := sel.FrameOffset
+	if sel.FrameOffset != 0 && sel.PC == g.CurrentLoc.PC {
+		if frames, err := c.Stacktrace(g.ID, 0, nil); err == nil && len(frames) > 0 {
+			frameOffset = frames[0].FrameOffset
+		}
+	}
+	frame, stale := frameSelectionStatus(sel, g, frameOffset, lastStopTime(c.Addr()))
+	if !stale {
+		return frame
+	}
+
+	_ = session.SaveData(c.Addr(), selectedFrameFile, frameSelection{})
+	current := map[string]any{
+		"goroutineId": g.ID,
+		"file":        g.CurrentLoc.File,
+		"line":        g.CurrentLoc.Line,
+		"function":    g.CurrentLoc.Function.Name(),
+	}
+	output.ErrorWithInfo(cmdName, output.NewErrorInfo(
+		output.ErrCodeStaleContext,
+		fmt.Sprintf("frame %d was selected at a stop the target has left; it is now at %s:%d", sel.Frame, g.CurrentLoc.File, g.CurrentLoc.Line),
+	).WithDetails(map[string]any{
+		"selectedFrame": sel.Frame,
+		"current":       current,
+		"suggestions":   []string{"stack", fmt.Sprintf("frame %d", sel.Frame)},
+	})).PrintAndExit(getOutputFormat())
+	return 0
+}
+
+// switchFrame selects frame frameIdx of the selected goroutine, persists the
+// choice for locals, args and eval, and prints the result
+func switchFrame(c *debugger.Client, cmdName string, state *api.DebuggerState, frameIdx int, getOutputFormat func() output.OutputFormat) {
+	if state.SelectedGoroutine == nil {
+		output.ErrorWithInfo(cmdName, output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+	}
+	g := state.SelectedGoroutine
+	previous := selectedFrame(c.Addr(), state, g.ID)
+
+	if frameIdx < 0 {
+		output.ErrorWithInfo(cmdName, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("cannot move below frame 0 (current frame is %d)", previous),
+			map[string]any{"index": frameIdx, "current": previous},
+		)).PrintAndExit(getOutputFormat())
+	}
+
+	cfg := debugger.DefaultLoadConfig()
+	frames, err := c.Stacktrace(g.ID, frameIdx+1, &cfg)
+	if err != nil {
+		output.Error(cmdName, err).PrintAndExit(getOutputFormat())
+	}
+
+	if frameIdx >= len(frames) {
+		output.ErrorWithInfo(cmdName, output.NotFound("frame", fmt.Sprintf("%d (stack has %d frames)", frameIdx, len(frames)))).PrintAndExit(getOutputFormat())
+	}
+
+	_ = session.SaveData(c.Addr(), selectedFrameFile, frameSelection{
+		GoroutineID: g.ID,
+		Frame:       frameIdx,
+		PC:          g.CurrentLoc.PC,
+		LastStop:    lastStopTime(c.Addr()),
+		FrameOffset: frames[0].FrameOffset,
+	})
+
+	frame := frames[frameIdx]
+	data := map[string]any{
+		"index":    frameIdx,
+		"previous": previous,
+		"file":     frame.File,
+		"line":     frame.Line,
+	}
+	if frame.Function != nil {
+		data["function"] = frame.Function.Name()
+	}
+
+	output.Success(cmdName, data, fmt.Sprintf("Switched to frame %d", frameIdx)).PrintAndExit(getOutputFormat())
+}
+
+// addFrameMoveCommands adds the up and down commands
+func addFrameMoveCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	// moveFrame returns the Run function moving the selection by direction*n frames
+	moveFrame := func(cmdName string, direction int) func(*cobra.Command, []string) {
+		return func(cmd *cobra.Command, args []string) {
+			n := 1
+			if len(args) > 0 {
+				var err error
+				if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+					output.ErrorWithInfo(cmdName, output.InvalidArgumentWithDetails(
+						fmt.Sprintf("invalid frame count: %s (must be >= 1)", args[0]),
+						map[string]any{"count": args[0]},
+					)).PrintAndExit(getOutputFormat())
+				}
+			}
+
+			c := mustGetClient(cmdName)
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error(cmdName, err).PrintAndExit(getOutputFormat())
+			}
+
+			current := 0
+			if state.SelectedGoroutine != nil {
+				current = mustSelectedFrame(c, cmdName, state, state.SelectedGoroutine.ID, getOutputFormat)
+			}
+			switchFrame(c, cmdName, state, current+direction*n, getOutputFormat)
+		}
+	}
+
+	upCmd := &cobra.Command{
+		Use:   "up [n]",
+		Short: "Move n frames toward the caller",
+		Long: `Select the frame n levels above the current one (default 1), i.e. the
+caller. locals, args and eval then use that frame until the program moves.
+
+Example:
+  godebug --addr $ADDR up
+  godebug --addr $ADDR up 2`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  moveFrame("up", 1),
+	}
+
+	downCmd := &cobra.Command{
+		Use:   "down [n]",
+		Short: "Move n frames toward the innermost frame",
+		Long: `Select the frame n levels below the current one (default 1), back toward
+frame 0 where the program is stopped.
+
+Example:
+  godebug --addr $ADDR down`,
+		Args: cobra.MaximumNArgs(1),
+		Run:  moveFrame("down", -1),
+	}
+
+	root.AddCommand(upCmd, downCmd)
+}
+
+func init() {
+	addFrameMoveCommands(rootCmd, MustGetClient, GetOutputFormat)
+}
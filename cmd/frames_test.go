@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestFrameSelectionStatus checks when a persisted frame is used, dropped
+// after a recorded stop, or reported stale.
+func TestFrameSelectionStatus(t *testing.T) {
+	stop := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	sel := frameSelection{GoroutineID: 1, Frame: 2, PC: 0x4a1b20, LastStop: stop, FrameOffset: -160}
+	at := func(id int64, pc uint64) *api.Goroutine {
+		return &api.Goroutine{ID: id, CurrentLoc: api.Location{PC: pc}}
+	}
+
+	tests := []struct {
+		name        string
+		g           *api.Goroutine
+		frameOffset int64
+		lastStop    time.Time
+		frame       int
+		stale       bool
+	}{
+		{"same stop", at(1, 0x4a1b20), -160, stop, 2, false},
+		{"other goroutine", at(5, 0x4a1b20), -160, stop, 0, false},
+		{"new recorded stop", at(1, 0x4a2000), -96, stop.Add(time.Second), 0, false},
+		{"moved without a recorded stop", at(1, 0x4a2000), -160, stop, 0, true},
+		{"same pc, other invocation", at(1, 0x4a1b20), -480, stop, 0, true},
+	}
+	for _, tt := range tests {
+		frame, stale := frameSelectionStatus(sel, tt.g, tt.frameOffset, tt.lastStop)
+		if frame != tt.frame || stale != tt.stale {
+			t.Errorf("%s: got frame %d stale %v, want %d %v", tt.name, frame, stale, tt.frame, tt.stale)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// maxFunctionCandidates bounds the functions listed for an ambiguous location
+const maxFunctionCandidates = 20
+
+// closureName matches the compiler's names for function literals and wrappers
+var closureName = regexp.MustCompile(`^(func|gowrap|deferwrap)\d+$`)
+
+// userInitName matches the names of a package's init functions (init.0, init.1)
+var userInitName = regexp.MustCompile(`^init\.\d+$`)
+
+// pluginPackage matches the path the linker gives the main package of a
+// plugin built without -pluginpath
+var pluginPackage = regexp.MustCompile(`^plugin/unnamed-[0-9a-f]+$`)
+
+// funcSymbol is a function's runtime name split into its parts
+type funcSymbol struct {
+	Pkg  string // import path
+	Recv string // receiver type without * and type parameters
+	Ptr  bool   // pointer receiver
+	Name string // function or method name, including closure suffixes
+}
+
+// stripTypeParams removes the type parameters of a generic instantiation the
+// way Delve does, so the result names every instantiation
+// (main.(*List[go.shape.int]).Push -> main.(*List).Push)
+func stripTypeParams(name string) string {
+	open := strings.Index(name, "[")
+	end := strings.LastIndex(name, "]")
+	if open < 0 || end < open {
+		return name
+	}
+	return name[:open] + name[end+1:]
+}
+
+// parseFuncSymbol splits a runtime function name such as
+// github.com/me/app/db.(*Conn).Close into package, receiver and name
+func parseFuncSymbol(name string) funcSymbol {
+	name = stripTypeParams(name)
+	// The package path ends at the first dot after its last slash
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return funcSymbol{Name: name}
+	}
+	sym := funcSymbol{Pkg: name[:slash+1+dot]}
+	rest := name[slash+1+dot+1:]
+	switch {
+	case strings.HasPrefix(rest, "("):
+		end := strings.Index(rest, ")")
+		if end < 0 || end+2 > len(rest) {
+			sym.Name = rest
+			return sym
+		}
+		sym.Recv, sym.Ptr = strings.CutPrefix(rest[1:end], "*")
+		sym.Name = rest[end+2:]
+	case userInitName.MatchString(rest):
+		sym.Name = rest
+	default:
+		first, after, ok := strings.Cut(rest, ".")
+		// main.main.func1 is a closure in main, main.T.M a method of T
+		if !ok || closureName.MatchString(strings.SplitN(after, ".", 2)[0]) {
+			sym.Name = rest
+		} else {
+			sym.Recv, sym.Name = first, after
+		}
+	}
+	return sym
+}
+
+// tail is the symbol without its package: Recv.Name or Name
+func (s funcSymbol) tail() string {
+	if s.Recv == "" {
+		return s.Name
+	}
+	return s.Recv + "." + s.Name
+}
+
+// keys are the spellings a location may use for the symbol: the full import
+// path, the last path element, and, within the main module, no package at
+// all. A plugin's main package is spelled main, as in its source.
+func (s funcSymbol) keys(inMainModule func(string) bool) []string {
+	keys := []string{s.Pkg + "." + s.tail()}
+	if i := strings.LastIndex(s.Pkg, "/"); i >= 0 {
+		keys = append(keys, s.Pkg[i+1:]+"."+s.tail())
+	}
+	if pluginPackage.MatchString(s.Pkg) {
+		keys = append(keys, "main."+s.tail(), s.tail())
+	} else if inMainModule(s.Pkg) {
+		keys = append(keys, s.tail())
+	}
+	return keys
+}
+
+// mainModuleFilter reports whether a package belongs to the main module. With
+// the module unknown, every package outside the standard library does.
+func mainModuleFilter(module string) func(string) bool {
+	return func(pkg string) bool {
+		if pkg == "main" {
+			return true
+		}
+		if module != "" {
+			return pkg == module || strings.HasPrefix(pkg, module+"/")
+		}
+		first, _, _ := strings.Cut(pkg, "/")
+		return strings.Contains(first, ".")
+	}
+}
+
+// matchFunctions returns the functions a location names, as names Delve
+// resolves (generic instantiations collapse to one name). A location may
+// write the receiver as (*T), *T or T, leave out type parameters, qualify
+// the function by import path or its last element, or, within the main
+// module, not at all. pkg.init also names the package's init.N functions.
+func matchFunctions(functions []string, location string, inMainModule func(string) bool) []string {
+	stripped := stripTypeParams(location)
+	ptr := strings.Contains(stripped, "*")
+	want := strings.NewReplacer("(", "", ")", "", "*", "").Replace(stripped)
+	isInit := want == "init" || strings.HasSuffix(want, ".init")
+
+	var exact, other []string
+	seen := map[string]bool{}
+	for _, fn := range functions {
+		sym := parseFuncSymbol(fn)
+		if ptr && !sym.Ptr {
+			continue
+		}
+		for _, key := range sym.keys(inMainModule) {
+			if isInit && userInitName.MatchString(sym.Name) {
+				key = key[:strings.LastIndex(key, ".")]
+			}
+			if key != want {
+				continue
+			}
+			name := stripTypeParams(fn)
+			if !seen[name] {
+				seen[name] = true
+				// T.M prefers the value method over the (*T).M wrapper
+				if sym.Ptr == ptr {
+					exact = append(exact, name)
+				} else {
+					other = append(other, name)
+				}
+			}
+			break
+		}
+	}
+	matches := exact
+	if len(matches) == 0 {
+		matches = other
+	}
+	if isInit {
+		// Named init functions are what a breakpoint on init is after, not
+		// the compiler's package initializer
+		var user []string
+		for _, m := range matches {
+			if userInitName.MatchString(parseFuncSymbol(m).Name) {
+				user = append(user, m)
+			}
+		}
+		if len(user) > 0 {
+			matches = user
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// mainModulePath returns the main module of the debugged binary, from its
+// build info or else the local go.mod
+func mainModulePath(c *debugger.Client) string {
+	if path, err := targetBinary(c); err == nil {
+		if info, err := buildinfo.ReadFile(path); err == nil && info.Main.Path != "" {
+			return info.Main.Path
+		}
+	}
+	if m := sessionPathMap(c); m != nil {
+		return m.Module
+	}
+	return ""
+}
+
+// resolveFunctionLocation turns a function location into the runtime name
+// Delve expects. Exact names are used as given; shorthand is matched
+// against the binary's functions and must name exactly one.
+func resolveFunctionLocation(c *debugger.Client, location string) (string, error) {
+	want := strings.NewReplacer("(", "", ")", "", "*", "").Replace(stripTypeParams(location))
+	// Only functions containing the last element can match
+	last := want[strings.LastIndex(want, ".")+1:]
+	functions, err := c.ListFunctions(regexp.QuoteMeta(last))
+	if err != nil {
+		return location, nil
+	}
+	if slices.Contains(functions, location) && !strings.HasSuffix(location, ".init") {
+		return location, nil
+	}
+
+	matches := matchFunctions(functions, location, mainModuleFilter(mainModulePath(c)))
+	switch len(matches) {
+	case 0:
+		// Let Delve report the unknown function
+		return location, nil
+	case 1:
+		return matches[0], nil
+	}
+	shown := matches
+	if len(shown) > maxFunctionCandidates {
+		shown = shown[:maxFunctionCandidates]
+	}
+	return "", output.InvalidArgumentWithDetails(
+		fmt.Sprintf("location %s matches %d functions; use one of the candidates", location, len(matches)),
+		map[string]any{"location": location, "candidates": shown, "count": len(matches)},
+	)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseFuncSymbol checks the split of runtime names into their parts.
+func TestParseFuncSymbol(t *testing.T) {
+	tests := []struct {
+		name string
+		want funcSymbol
+	}{
+		{"main.main", funcSymbol{Pkg: "main", Name: "main"}},
+		{"main.main.func1", funcSymbol{Pkg: "main", Name: "main.func1"}},
+		{"main.init.0", funcSymbol{Pkg: "main", Name: "init.0"}},
+		{"main.Server.Handle", funcSymbol{Pkg: "main", Recv: "Server", Name: "Handle"}},
+		{"github.com/me/app/db.(*Conn).Close", funcSymbol{Pkg: "github.com/me/app/db", Recv: "Conn", Ptr: true, Name: "Close"}},
+		{"github.com/me/app/db.(*Conn).Close.func2", funcSymbol{Pkg: "github.com/me/app/db", Recv: "Conn", Ptr: true, Name: "Close.func2"}},
+		{"main.(*List[go.shape.int]).Push", funcSymbol{Pkg: "main", Recv: "List", Ptr: true, Name: "Push"}},
+		{"main.Map[go.shape.int,go.shape.string]", funcSymbol{Pkg: "main", Name: "Map"}},
+	}
+	for _, tt := range tests {
+		if got := parseFuncSymbol(tt.name); got != tt.want {
+			t.Errorf("parseFuncSymbol(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestMatchFunctions checks the shorthand a location may use.
+func TestMatchFunctions(t *testing.T) {
+	functions := []string{
+		"main.main",
+		"main.init",
+		"main.init.0",
+		"main.Server.Handle",
+		"main.(*Server).Handle",
+		"main.(*Server).Close",
+		"main.(*List[go.shape.int]).Push",
+		"main.(*List[go.shape.string]).Push",
+		"main.Map[go.shape.int]",
+		"example.com/app/db.(*Conn).Close",
+		"example.com/app/db.init.0",
+		"example.com/app/db.init.1",
+		"net/http.(*Server).Close",
+	}
+	inMain := mainModuleFilter("example.com/app")
+	tests := []struct {
+		location string
+		want     []string
+	}{
+		{"Server.Handle", []string{"main.Server.Handle"}},
+		{"(*Server).Handle", []string{"main.(*Server).Handle"}},
+		{"*Server.Handle", []string{"main.(*Server).Handle"}},
+		{"Server.Close", []string{"main.(*Server).Close"}},
+		{"main.List.Push", []string{"main.(*List).Push"}},
+		{"main.(*List[int]).Push", []string{"main.(*List).Push"}},
+		{"Map", []string{"main.Map"}},
+		{"db.Conn.Close", []string{"example.com/app/db.(*Conn).Close"}},
+		{"Conn.Close", []string{"example.com/app/db.(*Conn).Close"}},
+		{"http.Server.Close", []string{"net/http.(*Server).Close"}},
+		{"main.init", []string{"main.init.0"}},
+		{"db.init", []string{"example.com/app/db.init.0", "example.com/app/db.init.1"}},
+		{"Close", nil},
+	}
+	for _, tt := range tests {
+		if got := matchFunctions(functions, tt.location, inMain); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matchFunctions(%q) = %v, want %v", tt.location, got, tt.want)
+		}
+	}
+}
+
+// TestMatchPluginFunctions checks a plugin's main package is spelled main
+// or left out, as in the plugin's source.
+func TestMatchPluginFunctions(t *testing.T) {
+	functions := []string{
+		"main.main",
+		"plugin/unnamed-4f1c2b9e0a.Handle",
+		"plugin/unnamed-4f1c2b9e0a.(*Cache).Get",
+		"example.com/app/db.Open",
+	}
+	inMain := mainModuleFilter("example.com/app")
+	tests := []struct {
+		location string
+		want     []string
+	}{
+		{"main.Handle", []string{"plugin/unnamed-4f1c2b9e0a.Handle"}},
+		{"Handle", []string{"plugin/unnamed-4f1c2b9e0a.Handle"}},
+		{"Cache.Get", []string{"plugin/unnamed-4f1c2b9e0a.(*Cache).Get"}},
+		{"main.main", []string{"main.main"}},
+	}
+	for _, tt := range tests {
+		if got := matchFunctions(functions, tt.location, inMain); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matchFunctions(%q) = %v, want %v", tt.location, got, tt.want)
+		}
+	}
+}
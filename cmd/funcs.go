@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// defaultFuncsLimit bounds the functions funcs lists unless --limit is given
+const defaultFuncsLimit = 200
+
+// listedFunctions reduces the binary's functions to the names funcs reports:
+// generic instantiations collapse to the name break accepts, and unless all is
+// set only functions of the main module are kept
+func listedFunctions(functions []string, inMainModule func(string) bool, all bool) []string {
+	seen := map[string]bool{}
+	var listed []string
+	for _, fn := range functions {
+		name := stripTypeParams(fn)
+		if seen[name] {
+			continue
+		}
+		if !all && !inMainModule(parseFuncSymbol(name).Pkg) {
+			continue
+		}
+		seen[name] = true
+		listed = append(listed, name)
+	}
+	sort.Strings(listed)
+	return listed
+}
+
+// addFuncsCommand adds the funcs command
+func addFuncsCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var funcsAll bool
+	var funcsLimit int
+
+	funcsCmd := &cobra.Command{
+		Use:   "funcs [regexp]",
+		Short: "List the functions in the binary",
+		Long: `List the functions of the debugged binary whose name matches regexp, to find
+a valid breakpoint location instead of guessing one. Names are as break takes
+them: generic functions are listed once, without type parameters.
+
+By default only functions of the main module are listed; --all includes the
+standard library, dependencies and the runtime. At most --limit names are
+returned (0 = no limit); data.total counts all matches.
+
+Example:
+  godebug --addr $ADDR funcs
+  godebug --addr $ADDR funcs 'main\..*'
+  godebug --addr $ADDR funcs 'Handler\)?\.ServeHTTP$'
+  godebug --addr $ADDR funcs --all '^net/http\.\(\*Server\)'`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			filter := ""
+			if len(args) > 0 {
+				filter = args[0]
+			}
+			if _, err := regexp.Compile(filter); err != nil {
+				output.ErrorWithInfo("funcs", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid regexp: %v", err),
+					map[string]any{"filter": filter},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if funcsLimit < 0 {
+				output.ErrorWithInfo("funcs", output.InvalidArgument("--limit must not be negative")).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("funcs")
+			defer func() { _ = c.Close() }()
+
+			functions, err := c.ListFunctions(filter)
+			if err != nil {
+				output.Error("funcs", err).PrintAndExit(getOutputFormat())
+			}
+			module := mainModulePath(c)
+			listed := listedFunctions(functions, mainModuleFilter(module), funcsAll)
+
+			data := map[string]any{
+				"total": len(listed),
+			}
+			if filter != "" {
+				data["filter"] = filter
+			}
+			if !funcsAll && module != "" {
+				data["module"] = module
+			}
+			if funcsLimit > 0 && len(listed) > funcsLimit {
+				listed = listed[:funcsLimit]
+				data["truncated"] = true
+			}
+			data["functions"] = listed
+			data["count"] = len(listed)
+			if len(listed) == 0 && !funcsAll && len(functions) > 0 {
+				data["hint"] = fmt.Sprintf("%d functions outside the main module match; use --all to list them", len(listedFunctions(functions, nil, true)))
+			}
+
+			output.Success("funcs", data, fmt.Sprintf("%d functions", data["total"])).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	funcsCmd.Flags().BoolVar(&funcsAll, "all", false, "Include functions outside the main module")
+	funcsCmd.Flags().IntVar(&funcsLimit, "limit", defaultFuncsLimit, "Maximum number of functions listed (0 = no limit)")
+	root.AddCommand(funcsCmd)
+}
+
+func init() {
+	addFuncsCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestListedFunctions checks the main module filter and the collapsing of
+// generic instantiations.
+func TestListedFunctions(t *testing.T) {
+	functions := []string{
+		"main.main",
+		"main.(*List[go.shape.int]).Push",
+		"main.(*List[go.shape.string]).Push",
+		"example.com/app/db.(*Conn).Close",
+		"fmt.Println",
+		"runtime.main",
+	}
+	inMain := mainModuleFilter("example.com/app")
+
+	got := listedFunctions(functions, inMain, false)
+	want := []string{"example.com/app/db.(*Conn).Close", "main.(*List).Push", "main.main"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("main module: got %v, want %v", got, want)
+	}
+
+	if got := listedFunctions(functions, inMain, true); len(got) != 5 {
+		t.Errorf("--all: got %v, want 5 functions", got)
+	}
+}
@@ -272,6 +272,18 @@ func TestFuzzAllCommands(t *testing.T) {
 		"locals", "args", "eval",
 		"stack", "frame", "goroutines", "goroutine",
 		"list", "sources",
+		"debug-fuzz-crash", "reload", "annotate", "summarize", "compare",
+		"bisect-run", "buildinfo", "env", "fds", "watch-live", "condition",
+		"up", "down", "scope", "maps", "analyze", "interrupt", "selftest",
+		"tutorial", "scenario", "print", "goroutine-dumps", "toolspec",
+		"init-agent", "halt", "report", "triage", "probe", "trace",
+		"server-logs",
+		"rewind", "reverse-continue", "reverse-next", "reverse-step", "reverse-stepout",
+		"call", "set", "watch", "project", "funcs", "types", "vars",
+		"threads", "thread", "trace-http", "stepi", "nexti", "hunt",
+		"until", "checkpoint", "checkpoints", "checkpoint-clear",
+		"examine", "detach", "breakpoint", "explore", "panics",
+		"hit-stacks", "artifacts", "defers", "libs",
 	}
 
 	rapid.Check(t, func(t *rapid.T) {
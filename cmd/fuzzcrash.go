@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// parseCorpusEntry extracts the fuzz target and entry name from a corpus path
+// of the form testdata/fuzz/<FuzzTarget>/<entry>
+func parseCorpusEntry(path string) (target, entry string, err error) {
+	entry = filepath.Base(path)
+	target = filepath.Base(filepath.Dir(path))
+	if entry == "." || entry == string(filepath.Separator) || !strings.HasPrefix(target, "Fuzz") {
+		return "", "", output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid corpus entry: %s (expected testdata/fuzz/FuzzXxx/<entry>)", path),
+			map[string]any{"corpusEntry": path},
+		)
+	}
+	return target, entry, nil
+}
+
+// addDebugFuzzCrashCommand adds the debug-fuzz-crash command
+func addDebugFuzzCrashCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var corpusEntry string
+
+	fuzzCrashCmd := &cobra.Command{
+		Use:   "debug-fuzz-crash <package>",
+		Short: "Debug a crashing fuzz corpus entry",
+		Long: `Start a test-mode debug session that replays a single fuzz corpus entry.
+
+The test binary is run with -test.run selecting only the given corpus entry,
+and a breakpoint is set on the fuzz function passed to f.Fuzz so the crashing
+input can be stepped through after the first continue.
+
+Example:
+  godebug debug-fuzz-crash ./pkg --corpus-entry testdata/fuzz/FuzzParse/8a1f2c
+  godebug --addr $ADDR continue`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			pkg := args[0]
+
+			if corpusEntry == "" {
+				output.ErrorWithInfo("debug-fuzz-crash", output.InvalidArgument("--corpus-entry flag is required")).PrintAndExit(getOutputFormat())
+			}
+
+			fuzzTarget, entry, err := parseCorpusEntry(corpusEntry)
+			if err != nil {
+				output.Error("debug-fuzz-crash", err).PrintAndExit(getOutputFormat())
+			}
+
+			// Corpus entries are usually given relative to the package directory
+			entryPath := corpusEntry
+			if _, err := os.Stat(entryPath); err != nil {
+				entryPath = filepath.Join(pkg, corpusEntry)
+				if _, err := os.Stat(entryPath); err != nil {
+					output.ErrorWithInfo("debug-fuzz-crash", output.NotFound("corpus entry", corpusEntry)).PrintAndExit(getOutputFormat())
+				}
+			}
+
+			runPattern := fmt.Sprintf("^%s$/^%s$", regexp.QuoteMeta(fuzzTarget), regexp.QuoteMeta(entry))
+			result, err := debugger.Launch(debugger.LaunchConfig{
+				Mode:    debugger.ModeTest,
+				Target:  pkg,
+				Args:    []string{"-test.run", runPattern},
+				Timeout: getTimeout(),
+			})
+			if err != nil {
+				output.Error("debug-fuzz-crash", err).PrintAndExit(getOutputFormat())
+			}
+
+			recordLaunchSession(result)
+
+			c, err := debugger.Connect(result.Addr)
+			if err != nil {
+				_ = result.Kill()
+				output.Error("debug-fuzz-crash", err).PrintAndExit(getOutputFormat())
+			}
+			defer func() { _ = c.Close() }()
+
+			// Prefer the closure passed to f.Fuzz, fall back to the fuzz target itself
+			var funcName string
+			for _, filter := range []string{
+				`\.` + regexp.QuoteMeta(fuzzTarget) + `\.func1$`,
+				`\.` + regexp.QuoteMeta(fuzzTarget) + `$`,
+			} {
+				funcs, err := c.ListFunctions(filter)
+				if err == nil && len(funcs) > 0 {
+					funcName = funcs[0]
+					break
+				}
+			}
+			if funcName == "" {
+				_ = c.Detach(true)
+				output.ErrorWithInfo("debug-fuzz-crash", output.NotFound("fuzz target", fuzzTarget)).PrintAndExit(getOutputFormat())
+			}
+
+			created, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: funcName})
+			if err != nil {
+				_ = c.Detach(true)
+				output.Error("debug-fuzz-crash", err).PrintAndExit(getOutputFormat())
+			}
+
+			data := map[string]any{
+				"addr":        result.Addr,
+				"pid":         result.PID,
+				"target":      result.Target,
+				"mode":        result.Mode,
+				"fuzzTarget":  fuzzTarget,
+				"corpusEntry": entryPath,
+				"run":         runPattern,
+				"breakpoint": map[string]any{
+					"id":       created.ID,
+					"file":     created.File,
+					"line":     created.Line,
+					"function": created.FunctionName,
+				},
+			}
+
+			output.Success("debug-fuzz-crash", data, fmt.Sprintf("Debug server started for %s/%s", fuzzTarget, entry)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	fuzzCrashCmd.Flags().StringVar(&corpusEntry, "corpus-entry", "", "Path to the fuzz corpus entry (testdata/fuzz/FuzzXxx/<entry>)")
+	root.AddCommand(fuzzCrashCmd)
+}
+
+func init() {
+	addDebugFuzzCrashCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
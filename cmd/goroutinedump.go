@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/pkg/goversion"
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// dumpBreakpointsFile maps breakpoint IDs set with --dump-goroutines to their filter
+const dumpBreakpointsFile = "dump-breakpoints.json"
+
+// goroutineDumpsFile holds the goroutine summaries captured at dump breakpoints
+const goroutineDumpsFile = "goroutine-dumps.json"
+
+// maxGoroutineDumps bounds the dumps kept per session
+const maxGoroutineDumps = 200
+
+// dumpBreakpoint is the configuration of a --dump-goroutines breakpoint
+type dumpBreakpoint struct {
+	// Filter keeps goroutines whose user location contains it; empty keeps
+	// all goroutines with a user frame
+	Filter string `json:"filter,omitempty"`
+}
+
+// goroutineGroup counts goroutines at the same user location in the same state
+type goroutineGroup struct {
+	Location string `json:"location"`
+	State    string `json:"state"`
+	Count    int    `json:"count"`
+}
+
+// goroutineDump is the summary captured at one hit of a dump breakpoint
+type goroutineDump struct {
+	eventTimestamp
+	BreakpointID int              `json:"breakpointId"`
+	Hit          uint64           `json:"hit"`
+	GoroutineID  int64            `json:"goroutineId,omitempty"`
+	Total        int              `json:"total"`
+	Matched      int              `json:"matched"`
+	Groups       []goroutineGroup `json:"groups"`
+}
+
+// loadDumpBreakpoints returns the session's --dump-goroutines breakpoints by ID
+func loadDumpBreakpoints(addr string) map[int]dumpBreakpoint {
+	dumps := map[int]dumpBreakpoint{}
+	_ = session.LoadData(addr, dumpBreakpointsFile, &dumps)
+	return dumps
+}
+
+// saveDumpBreakpoint marks breakpoint id as a dump breakpoint of the session
+func saveDumpBreakpoint(addr string, id int, filter string) {
+	dumps := loadDumpBreakpoints(addr)
+	dumps[id] = dumpBreakpoint{Filter: filter}
+	_ = session.SaveData(addr, dumpBreakpointsFile, dumps)
+}
+
+// goroutineState names what a goroutine is doing: its wait reason when parked
+func goroutineState(ver *goversion.GoVersion, g *api.Goroutine) string {
+	switch {
+	case g.WaitReason != 0:
+		return waitReasonName(ver, g.WaitReason)
+	case g.Status == api.GoroutineSyscall:
+		return "syscall"
+	case g.Status == api.GoroutineWaiting:
+		return "waiting"
+	case g.ThreadID != 0:
+		return "running"
+	default:
+		return "runnable"
+	}
+}
+
+// summarizeGoroutines groups goroutines by user location and state, keeping
+// those whose user location contains filter (any user location when empty)
+func summarizeGoroutines(goroutines []*api.Goroutine, filter string, state func(*api.Goroutine) string) ([]goroutineGroup, int) {
+	counts := map[goroutineGroup]int{}
+	matched := 0
+	for _, g := range goroutines {
+		loc := g.UserCurrentLoc
+		if loc.File == "" || !isUserSource(loc.File) {
+			continue
+		}
+		where := fmt.Sprintf("%s (%s:%d)", loc.Function.Name(), loc.File, loc.Line)
+		if filter != "" && !strings.Contains(where, filter) {
+			continue
+		}
+		matched++
+		counts[goroutineGroup{Location: where, State: state(g)}]++
+	}
+	groups := make([]goroutineGroup, 0, len(counts))
+	for key, n := range counts {
+		key.Count = n
+		groups = append(groups, key)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		if groups[i].Location != groups[j].Location {
+			return groups[i].Location < groups[j].Location
+		}
+		return groups[i].State < groups[j].State
+	})
+	return groups, matched
+}
+
+// recordGoroutineDump appends a goroutine summary to the session's dump log if
+// the target stopped at a dump breakpoint, and reports whether it did
+func recordGoroutineDump(c *debugger.Client, state *api.DebuggerState) bool {
+	if state == nil || state.Exited || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return false
+	}
+	bp := state.CurrentThread.Breakpoint
+	cfg, ok := loadDumpBreakpoints(c.Addr())[bp.ID]
+	if !ok {
+		return false
+	}
+
+	dump := goroutineDump{
+		eventTimestamp: newEventTimestamp(c.Addr(), time.Now()),
+		BreakpointID:   bp.ID,
+		Hit:            bp.TotalHitCount,
+		Groups:         []goroutineGroup{},
+	}
+	if state.SelectedGoroutine != nil {
+		dump.GoroutineID = state.SelectedGoroutine.ID
+	}
+	if goroutines, _, err := c.ListGoroutines(0, 0); err == nil {
+		ver := targetGoVersion(c)
+		dump.Total = len(goroutines)
+		dump.Groups, dump.Matched = summarizeGoroutines(goroutines, cfg.Filter, func(g *api.Goroutine) string {
+			return goroutineState(ver, g)
+		})
+	}
+
+	var dumps []goroutineDump
+	_ = session.LoadData(c.Addr(), goroutineDumpsFile, &dumps)
+	dumps = append(dumps, dump)
+	if len(dumps) > maxGoroutineDumps {
+		dumps = dumps[len(dumps)-maxGoroutineDumps:]
+	}
+	_ = session.SaveData(c.Addr(), goroutineDumpsFile, dumps)
+	return true
+}
+
+// passedHits counts the hits continuePastDumps recorded and continued past
+type passedHits struct {
+	dumps  int
+	stacks int
+	// collects counts --collect-diff hits whose values did not change;
+	// collected is the diff of the hit that stopped
+	collects  int
+	collected []map[string]any
+}
+
+// addTo reports the recorded hits in data
+func (p passedHits) addTo(data map[string]any) {
+	if p.dumps > 0 {
+		data["goroutineDumps"] = p.dumps
+	}
+	if p.stacks > 0 {
+		data["hitStacks"] = p.stacks
+	}
+	if p.collects > 0 {
+		data["unchangedHits"] = p.collects
+	}
+	if p.collected != nil {
+		data["collected"] = p.collected
+	}
+}
+
+// continuePastDumps resumes the target until it stops anywhere but at a
+// --dump-goroutines or --stacktrace breakpoint, recording a dump or the call
+// path at each of those hits, or at a --collect-diff breakpoint whose values
+// did not change since its previous hit. The timeout bounds the whole run
+// unless noTimeout is set.
+func continuePastDumps(c *debugger.Client, noTimeout bool, timeout time.Duration) (*api.DebuggerState, bool, passedHits, error) {
+	ctx := context.Background()
+	if !noTimeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		c.SetTimeout(timeout)
+	}
+	var passed passedHits
+	for {
+		var state *api.DebuggerState
+		var interrupted bool
+		var err error
+		if noTimeout {
+			state, interrupted, err = continueUntilInterrupt(c)
+		} else {
+			state, err = c.ContinueWithContext(ctx)
+		}
+		if err != nil || interrupted {
+			return state, interrupted, passed, err
+		}
+		dumped := recordGoroutineDump(c, state)
+		stacked := recordHitStack(c, state)
+		collected := collectedDiff(c, state)
+		if collected != nil && collectChanged(collected) {
+			passed.collected = collected
+			return state, interrupted, passed, err
+		}
+		if !dumped && !stacked && collected == nil {
+			return state, interrupted, passed, err
+		}
+		if dumped {
+			passed.dumps++
+		}
+		if stacked {
+			passed.stacks++
+		}
+		if collected != nil {
+			passed.collects++
+		}
+	}
+}
+
+// addGoroutineDumpsCommand adds the goroutine-dumps command
+func addGoroutineDumpsCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var dumpsBreakpoint int
+	var dumpsLast int
+
+	dumpsCmd := &cobra.Command{
+		Use:   "goroutine-dumps",
+		Short: "Show goroutine summaries captured at --dump-goroutines breakpoints",
+		Long: `List the goroutine summaries recorded each time continue passed a breakpoint
+set with break --dump-goroutines, oldest first. Each dump groups the
+goroutines with a user frame by location and state, so consecutive dumps
+show how the concurrency state at that program point evolves: workers
+piling up on a channel, a lock convoy forming, a pool draining.
+
+Reads the session log only; the target is not resumed or inspected.
+
+Example:
+  godebug --addr $ADDR goroutine-dumps
+  godebug --addr $ADDR goroutine-dumps --breakpoint 2 --last 5`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("goroutine-dumps")
+			defer func() { _ = c.Close() }()
+
+			var dumps []goroutineDump
+			_ = session.LoadData(c.Addr(), goroutineDumpsFile, &dumps)
+			listed := []goroutineDump{}
+			for _, d := range dumps {
+				if dumpsBreakpoint == 0 || d.BreakpointID == dumpsBreakpoint {
+					listed = append(listed, d)
+				}
+			}
+			matched := len(listed)
+			if dumpsLast > 0 && len(listed) > dumpsLast {
+				listed = listed[len(listed)-dumpsLast:]
+			}
+
+			data := map[string]any{
+				"dumps":   listed,
+				"count":   len(listed),
+				"matched": matched,
+			}
+			if dumpsBreakpoint > 0 {
+				data["breakpointId"] = dumpsBreakpoint
+			}
+			msg := fmt.Sprintf("%d goroutine dumps", len(listed))
+			if n := len(listed); n >= 2 {
+				msg += fmt.Sprintf("; matched goroutines %d -> %d", listed[0].Matched, listed[n-1].Matched)
+			}
+			output.Success("goroutine-dumps", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	dumpsCmd.Flags().IntVar(&dumpsBreakpoint, "breakpoint", 0, "Only show dumps of this breakpoint")
+	dumpsCmd.Flags().IntVar(&dumpsLast, "last", 10, "Show the last N dumps (0 = all)")
+	root.AddCommand(dumpsCmd)
+}
+
+func init() {
+	addGoroutineDumpsCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
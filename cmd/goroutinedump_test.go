@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestSummarizeGoroutines checks grouping by user location and state, the
+// filter and that goroutines without a user frame are left out.
+func TestSummarizeGoroutines(t *testing.T) {
+	g := func(fn, file string, line int, reason int64) *api.Goroutine {
+		return &api.Goroutine{
+			UserCurrentLoc: api.Location{File: file, Line: line, Function: &api.Function{Name_: fn}},
+			WaitReason:     reason,
+		}
+	}
+	goroutines := []*api.Goroutine{
+		g("main.worker", "/app/worker.go", 30, 1),
+		g("main.worker", "/app/worker.go", 30, 1),
+		g("main.worker", "/app/worker.go", 30, 0),
+		g("main.main", "/app/main.go", 12, 0),
+		g("runtime.gopark", "/usr/local/go/src/runtime/proc.go", 461, 1),
+		g("", "", 0, 0),
+	}
+	state := func(g *api.Goroutine) string {
+		if g.WaitReason != 0 {
+			return "chan send"
+		}
+		return "running"
+	}
+
+	groups, matched := summarizeGoroutines(goroutines, "", state)
+	want := []goroutineGroup{
+		{Location: "main.worker (/app/worker.go:30)", State: "chan send", Count: 2},
+		{Location: "main.main (/app/main.go:12)", State: "running", Count: 1},
+		{Location: "main.worker (/app/worker.go:30)", State: "running", Count: 1},
+	}
+	if matched != 4 || !reflect.DeepEqual(groups, want) {
+		t.Errorf("summarizeGoroutines() = %v, %d; want %v, 4", groups, matched, want)
+	}
+
+	groups, matched = summarizeGoroutines(goroutines, "worker.go", state)
+	if matched != 3 || len(groups) != 2 {
+		t.Errorf("filtered summarizeGoroutines() = %v, %d; want 2 groups, 3 matched", groups, matched)
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Goroutine states accepted by goroutines --state
+const (
+	gStateRunning  = "running"  // on a thread, executing Go code
+	gStateRunnable = "runnable" // ready, waiting for a thread
+	gStateWaiting  = "waiting"  // parked: channel, lock, sleep, I/O...
+	gStateSyscall  = "syscall"  // in a system call
+)
+
+var goroutineStates = []string{gStateRunning, gStateRunnable, gStateWaiting, gStateSyscall}
+
+// gStatusRunnable is the runtime's _Grunnable, which api does not export
+const gStatusRunnable = 1
+
+// runtimeClockExprs are runtime variables holding recent readings of the
+// target's monotonic clock: the last network poll and GC start and end
+var runtimeClockExprs = []string{
+	"runtime.sched.lastpoll.value",
+	"runtime.work.tstart",
+	"runtime.memstats.last_gc_nanotime",
+}
+
+// stateFilter selects goroutines by scheduling state, wait reason and how
+// long they have been waiting
+type stateFilter struct {
+	State       string
+	WaitReason  string
+	WaitingOver time.Duration
+	// now is the target's clock the wait durations are measured to
+	now int64
+}
+
+// parseStateFilter validates the goroutines state flags
+func parseStateFilter(state, waitReason string, waitingOver time.Duration) (stateFilter, *output.ErrorInfo) {
+	state = strings.ToLower(strings.TrimSpace(state))
+	if state != "" && !slices.Contains(goroutineStates, state) {
+		return stateFilter{}, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid goroutine state: %s", state),
+			map[string]any{"state": state, "valid": goroutineStates},
+		)
+	}
+	if waitingOver < 0 {
+		return stateFilter{}, output.InvalidArgument("--waiting-more-than must not be negative")
+	}
+	return stateFilter{State: state, WaitReason: strings.TrimSpace(waitReason), WaitingOver: waitingOver}, nil
+}
+
+// active reports whether the filter selects anything
+func (f stateFilter) active() bool {
+	return f.State != "" || f.WaitReason != "" || f.WaitingOver > 0
+}
+
+// delveFilters are the conditions Delve applies itself. A goroutine in a
+// system call keeps its thread, so Delve counts it as running.
+func (f stateFilter) delveFilters() []api.ListGoroutinesFilter {
+	switch f.State {
+	case gStateRunning, gStateSyscall:
+		return []api.ListGoroutinesFilter{{Kind: api.GoroutineRunning}}
+	case gStateRunnable, gStateWaiting:
+		return []api.ListGoroutinesFilter{{Kind: api.GoroutineRunning, Negated: true}}
+	}
+	return nil
+}
+
+// schedState names the scheduling state of g, or "" for states the
+// filter does not cover (idle, dead)
+func schedState(g *api.Goroutine) string {
+	switch {
+	case g.Status == api.GoroutineSyscall:
+		return gStateSyscall
+	case g.Status == api.GoroutineWaiting:
+		return gStateWaiting
+	case g.ThreadID != 0:
+		return gStateRunning
+	case g.Status == gStatusRunnable:
+		return gStateRunnable
+	}
+	return ""
+}
+
+// waited is how long g has been waiting as the runtime knows it. The
+// runtime stamps a waiting goroutine at the start of the first GC that sees
+// it, so this is a lower bound, and 0 until a GC has run.
+func (f stateFilter) waited(g *api.Goroutine) (time.Duration, bool) {
+	if g.WaitSince == 0 || f.now == 0 || f.now < g.WaitSince {
+		return 0, false
+	}
+	return time.Duration(f.now - g.WaitSince), true
+}
+
+// matches reports whether g, whose wait reason is named reason, passes the
+// filter
+func (f stateFilter) matches(g *api.Goroutine, reason string) bool {
+	if f.State != "" && schedState(g) != f.State {
+		return false
+	}
+	if f.WaitReason != "" && !strings.Contains(strings.ToLower(reason), strings.ToLower(f.WaitReason)) {
+		return false
+	}
+	if f.WaitingOver > 0 {
+		waited, ok := f.waited(g)
+		if !ok || waited < f.WaitingOver {
+			return false
+		}
+	}
+	return true
+}
+
+// runtimeClock estimates the target's monotonic clock at the stop from the
+// latest reading the runtime recorded, or returns 0 when none is readable
+func runtimeClock(c *debugger.Client) int64 {
+	var now int64
+	for _, expr := range runtimeClockExprs {
+		v, err := c.EvalInScope(api.EvalScope{GoroutineID: -1}, expr, api.LoadConfig{})
+		if err != nil {
+			continue
+		}
+		if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+			now = max(now, n)
+		}
+	}
+	return now
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestStateFilter checks state, wait reason and wait duration matching
+func TestStateFilter(t *testing.T) {
+	running := &api.Goroutine{ID: 1, ThreadID: 7, Status: 2}
+	syscall := &api.Goroutine{ID: 2, ThreadID: 8, Status: api.GoroutineSyscall}
+	parked := &api.Goroutine{ID: 3, Status: api.GoroutineWaiting, WaitSince: int64(10 * time.Second)}
+	fresh := &api.Goroutine{ID: 4, Status: api.GoroutineWaiting}
+	ready := &api.Goroutine{ID: 5, Status: gStatusRunnable}
+
+	tests := []struct {
+		state, reason string
+		over          time.Duration
+		g             *api.Goroutine
+		gReason       string
+		want          bool
+	}{
+		{"running", "", 0, running, "", true},
+		{"running", "", 0, syscall, "", false},
+		{"syscall", "", 0, syscall, "", true},
+		{"runnable", "", 0, ready, "", true},
+		{"waiting", "chan send", 0, parked, "chan send", true},
+		{"waiting", "Chan", 0, parked, "chan receive", true},
+		{"", "select", 0, parked, "chan receive", false},
+		{"", "", 5 * time.Second, parked, "", true},
+		{"", "", 30 * time.Second, parked, "", false},
+		// Not seen waiting by a GC yet: no duration is known
+		{"", "", time.Second, fresh, "", false},
+	}
+	for _, tt := range tests {
+		f, info := parseStateFilter(tt.state, tt.reason, tt.over)
+		if info != nil {
+			t.Fatalf("parseStateFilter(%q, %q, %v): %v", tt.state, tt.reason, tt.over, info)
+		}
+		f.now = int64(20 * time.Second)
+		if got := f.matches(tt.g, tt.gReason); got != tt.want {
+			t.Errorf("state=%q reason=%q over=%v on goroutine %d = %v, want %v", tt.state, tt.reason, tt.over, tt.g.ID, got, tt.want)
+		}
+	}
+
+	if _, info := parseStateFilter("parked", "", 0); info == nil {
+		t.Error("parseStateFilter accepted an unknown state")
+	}
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Session states as seen by the CLI
+const (
+	sessionNotStarted = "not-started" // stopped at entry, no goroutine selected yet
+	sessionStopped    = "stopped"
+	sessionRunning    = "running"
+	sessionExited     = "exited"
+)
+
+// commandClass groups commands by what they require of the target
+type commandClass int
+
+const (
+	classStep commandClass = iota + 1
+	classContinue
+	classInspect          // reads process state; needs a live, stopped target
+	classInspectGoroutine // like classInspect, and reads the selected goroutine's variables
+)
+
+// commandClasses lists the commands guarded by checkCommandState.
+// Commands not listed are valid in every state.
+var commandClasses = map[string]commandClass{
+	"next":            classStep,
+	"step":            classStep,
+	"stepi":           classStep,
+	"nexti":           classStep,
+	"stepout":         classStep,
+	"rewind":          classStep,
+	"reverse-next":    classStep,
+	"reverse-step":    classStep,
+	"reverse-stepout": classStep,
+	"continue":        classContinue,
+	"watch-live":      classContinue,
+	"trace":           classContinue,
+	"trace-http":      classContinue,
+	"until":           classContinue,
+	"stack":           classInspect,
+	"frame":           classInspect,
+	"up":              classInspect,
+	"down":            classInspect,
+	"goroutines":      classInspect,
+	"threads":         classInspect,
+	"thread":          classInspect,
+	"env":             classInspect,
+	"fds":             classInspect,
+	"maps":            classInspect,
+	"vars":            classInspect,
+	"checkpoint":      classInspect,
+	"examine":         classInspect,
+	"locals":          classInspectGoroutine,
+	"args":            classInspectGoroutine,
+	"eval":            classInspectGoroutine,
+	"annotate":        classInspectGoroutine,
+	"scope":           classInspectGoroutine,
+	"print":           classInspectGoroutine,
+	"call":            classInspectGoroutine,
+	"set":             classInspectGoroutine,
+	"watch":           classInspectGoroutine,
+
+	// Subcommands are keyed by their full path
+	"analyze threads":  classInspect,
+	"analyze wait-for": classInspect,
+	"probe run":        classInspect,
+}
+
+// sessionStateName maps a Delve state onto the CLI's session states
+func sessionStateName(state *api.DebuggerState) string {
+	switch {
+	case state.Exited:
+		return sessionExited
+	case state.Running:
+		return sessionRunning
+	case state.SelectedGoroutine == nil:
+		return sessionNotStarted
+	default:
+		return sessionStopped
+	}
+}
+
+// validateTransition checks cmdName against the session state and returns an
+// error with suggested next commands if the command makes no sense in it
+func validateTransition(cmdName string, state *api.DebuggerState) *output.ErrorInfo {
+	class, ok := commandClasses[cmdName]
+	if !ok {
+		return nil
+	}
+	name := sessionStateName(state)
+	afterExit := []string{"restart", "quit"}
+	whileRunning := []string{"status"}
+
+	switch class {
+	case classStep:
+		switch name {
+		case sessionRunning:
+			return output.InvalidState(output.ErrCodeStepWhileRunning,
+				fmt.Sprintf("cannot %s: target is running", cmdName), name, whileRunning)
+		case sessionExited:
+			return output.InvalidState(output.ErrCodeStepAfterExit,
+				fmt.Sprintf("cannot %s: target exited with status %d", cmdName, state.ExitStatus), name, afterExit)
+		}
+	case classContinue:
+		switch name {
+		case sessionRunning:
+			return output.InvalidState(output.ErrCodeContinueWhileRunning,
+				"cannot continue: target is already running", name, whileRunning)
+		case sessionExited:
+			return output.InvalidState(output.ErrCodeContinueAfterExit,
+				fmt.Sprintf("cannot continue: target exited with status %d", state.ExitStatus), name, afterExit)
+		}
+	case classInspect, classInspectGoroutine:
+		switch name {
+		case sessionRunning:
+			return output.InvalidState(output.ErrCodeInspectWhileRunning,
+				fmt.Sprintf("cannot run %s: target is running", cmdName), name, whileRunning)
+		case sessionExited:
+			return output.InvalidState(output.ErrCodeInspectAfterExit,
+				fmt.Sprintf("cannot run %s: target exited with status %d", cmdName, state.ExitStatus), name, afterExit)
+		case sessionNotStarted:
+			if class == classInspectGoroutine {
+				return output.InvalidState(output.ErrCodeInspectBeforeStart,
+					fmt.Sprintf("cannot run %s: target has not stopped in any goroutine yet", cmdName), name,
+					[]string{"break main.main", "continue"})
+			}
+		}
+	}
+	return nil
+}
+
+// checkCommandState rejects cmdName when it is invalid in the current session
+// state instead of forwarding a request Delve would reject or misinterpret
+func checkCommandState(c *debugger.Client, cmdName string, getOutputFormat func() output.OutputFormat) {
+	if _, ok := commandClasses[cmdName]; !ok {
+		return
+	}
+	state, err := c.GetState()
+	if err != nil {
+		// Let the command report connection problems itself
+		return
+	}
+	if errInfo := validateTransition(cmdName, state); errInfo != nil {
+		_ = c.Close()
+		output.ErrorWithInfo(cmdName, errInfo).PrintAndExit(getOutputFormat())
+	}
+}
+
+// stopContext returns a provider of the compact state of the session at
+// addr for --with-state. It connects afresh so the state reflects the end of
+// the command, and yields nil when addr is empty or unreachable.
+func stopContext(addr *string) func() *output.StopContext {
+	return func() *output.StopContext {
+		if *addr == "" {
+			return nil
+		}
+		c, err := debugger.Connect(*addr)
+		if err != nil {
+			return nil
+		}
+		defer func() { _ = c.Close() }()
+		state, err := c.GetState()
+		if err != nil {
+			return nil
+		}
+
+		ctx := &output.StopContext{State: sessionStateName(state)}
+		if state.Exited {
+			ctx.ExitStatus = &state.ExitStatus
+			return ctx
+		}
+		if g := state.SelectedGoroutine; g != nil && !state.Running {
+			ctx.GoroutineID = g.ID
+			if loc := g.CurrentLoc; loc.File != "" {
+				ctx.Location = fmt.Sprintf("%s:%d:%s", loc.File, loc.Line, loc.Function.Name())
+			}
+		}
+		return ctx
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// TestValidateTransition checks the error code for each command class in each session state.
+func TestValidateTransition(t *testing.T) {
+	stopped := &api.DebuggerState{SelectedGoroutine: &api.Goroutine{ID: 1}}
+	running := &api.DebuggerState{Running: true}
+	exited := &api.DebuggerState{Exited: true, ExitStatus: 2}
+	entry := &api.DebuggerState{}
+
+	tests := []struct {
+		cmd   string
+		state *api.DebuggerState
+		want  string
+	}{
+		{"next", stopped, ""},
+		{"next", running, output.ErrCodeStepWhileRunning},
+		{"step", exited, output.ErrCodeStepAfterExit},
+		{"continue", entry, ""},
+		{"continue", running, output.ErrCodeContinueWhileRunning},
+		{"continue", exited, output.ErrCodeContinueAfterExit},
+		{"locals", running, output.ErrCodeInspectWhileRunning},
+		{"eval", exited, output.ErrCodeInspectAfterExit},
+		{"locals", entry, output.ErrCodeInspectBeforeStart},
+		{"goroutines", entry, ""},
+		{"restart", exited, ""},
+		{"breakpoints", running, ""},
+	}
+	for _, tt := range tests {
+		errInfo := validateTransition(tt.cmd, tt.state)
+		got := ""
+		if errInfo != nil {
+			got = errInfo.Code
+		}
+		if got != tt.want {
+			t.Errorf("validateTransition(%q, %s) = %q; want %q", tt.cmd, sessionStateName(tt.state), got, tt.want)
+		}
+	}
+}
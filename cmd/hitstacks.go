@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// hitStacksFile holds the call paths recorded at --stacktrace breakpoints,
+// keyed by breakpoint ID
+const hitStacksFile = "hit-stacks.json"
+
+// maxHitPaths bounds the distinct call paths kept per breakpoint; hits on
+// paths beyond it are only counted
+const maxHitPaths = 500
+
+// hitPath is a call path that led to a breakpoint and how often it did
+type hitPath struct {
+	Frames   []string `json:"frames"`
+	Count    int      `json:"count"`
+	FirstHit uint64   `json:"firstHit"`
+	LastHit  uint64   `json:"lastHit"`
+}
+
+// hitStacks aggregates the call paths of a --stacktrace breakpoint's hits
+type hitStacks struct {
+	Depth int       `json:"depth"`
+	Hits  int       `json:"hits"`
+	Other int       `json:"other,omitempty"`
+	Paths []hitPath `json:"paths"`
+}
+
+// add counts a hit (the breakpoint's hit number) on the path frames
+func (h *hitStacks) add(frames []string, hit uint64) {
+	h.Hits++
+	key := strings.Join(frames, "\n")
+	for i := range h.Paths {
+		if strings.Join(h.Paths[i].Frames, "\n") == key {
+			h.Paths[i].Count++
+			h.Paths[i].LastHit = hit
+			return
+		}
+	}
+	if len(h.Paths) >= maxHitPaths {
+		h.Other++
+		return
+	}
+	h.Paths = append(h.Paths, hitPath{Frames: frames, Count: 1, FirstHit: hit, LastHit: hit})
+}
+
+// top returns the n most frequent paths (all for n <= 0) with their share
+// of the hits, most frequent first
+func (h *hitStacks) top(n int) []map[string]any {
+	paths := make([]hitPath, len(h.Paths))
+	copy(paths, h.Paths)
+	sort.SliceStable(paths, func(i, j int) bool { return paths[i].Count > paths[j].Count })
+	if n > 0 && len(paths) > n {
+		paths = paths[:n]
+	}
+	listed := make([]map[string]any, len(paths))
+	for i, p := range paths {
+		listed[i] = map[string]any{
+			"frames":   p.Frames,
+			"count":    p.Count,
+			"percent":  float64(p.Count*1000/max(h.Hits, 1)) / 10,
+			"firstHit": p.FirstHit,
+			"lastHit":  p.LastHit,
+		}
+	}
+	return listed
+}
+
+// hitStackFrames renders a stack as a call path, innermost first
+func hitStackFrames(frames []api.Stackframe) []string {
+	path := make([]string, 0, len(frames))
+	for _, f := range frames {
+		if f.Function == nil {
+			continue
+		}
+		path = append(path, fmt.Sprintf("%s %s:%d", f.Function.Name(), f.File, f.Line))
+	}
+	return path
+}
+
+// loadHitStacks returns the session's --stacktrace breakpoints by ID
+func loadHitStacks(addr string) map[int]*hitStacks {
+	stacks := map[int]*hitStacks{}
+	_ = session.LoadData(addr, hitStacksFile, &stacks)
+	return stacks
+}
+
+// saveStackBreakpoint marks breakpoint id as recording stacks of depth frames
+func saveStackBreakpoint(addr string, id, depth int) {
+	stacks := loadHitStacks(addr)
+	stacks[id] = &hitStacks{Depth: depth, Paths: []hitPath{}}
+	_ = session.SaveData(addr, hitStacksFile, stacks)
+}
+
+// recordHitStack adds the call path of the hit to the session's log if the
+// target stopped at a --stacktrace breakpoint, and reports whether it did.
+// Delve captures the stack with the hit; it is read directly if it did not.
+func recordHitStack(c *debugger.Client, state *api.DebuggerState) bool {
+	if state == nil || state.Exited || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return false
+	}
+	th := state.CurrentThread
+	stacks := loadHitStacks(c.Addr())
+	h, ok := stacks[th.Breakpoint.ID]
+	if !ok {
+		return false
+	}
+
+	var frames []api.Stackframe
+	if th.BreakpointInfo != nil {
+		frames = th.BreakpointInfo.Stacktrace
+	}
+	if len(frames) == 0 && th.GoroutineID != 0 {
+		frames, _ = c.Stacktrace(th.GoroutineID, h.Depth, nil)
+	}
+	h.add(hitStackFrames(frames), th.Breakpoint.TotalHitCount)
+	_ = session.SaveData(c.Addr(), hitStacksFile, stacks)
+	return true
+}
+
+// addHitStacksCommand adds the hit-stacks command
+func addHitStacksCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var hitStacksTop int
+
+	hitStacksCmd := &cobra.Command{
+		Use:   "hit-stacks [breakpoint-id]",
+		Short: "Show the call paths recorded at --stacktrace breakpoints",
+		Long: `Show the call paths that led to breakpoints set with break --stacktrace N,
+aggregated by path: how often each was taken, its share of the hits and the
+first and last hit it was seen at. Hits of those breakpoints do not stop
+continue, so a hot line can be profiled by its callers while the program
+runs.
+
+Without an ID every --stacktrace breakpoint is shown. Paths are listed most
+frequent first, at most --top of them (0 = all); frames are innermost first.
+Reads the session log only; the target is not resumed or inspected.
+
+Example:
+  godebug --addr $ADDR break cache.go:88 --stacktrace 8
+  godebug --addr $ADDR continue
+  godebug --addr $ADDR hit-stacks 3 --top 5`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if hitStacksTop < 0 {
+				output.ErrorWithInfo("hit-stacks", output.InvalidArgument("--top must not be negative")).PrintAndExit(getOutputFormat())
+			}
+			id := 0
+			if len(args) == 1 {
+				id = parseBreakpointID("hit-stacks", args[0], getOutputFormat)
+			}
+
+			c := mustGetClient("hit-stacks")
+			defer func() { _ = c.Close() }()
+
+			stacks := loadHitStacks(c.Addr())
+			if id != 0 && stacks[id] == nil {
+				output.ErrorWithInfo("hit-stacks", output.NotFound("--stacktrace breakpoint", strconv.Itoa(id))).PrintAndExit(getOutputFormat())
+			}
+			ids := make([]int, 0, len(stacks))
+			for bpID := range stacks {
+				if id == 0 || bpID == id {
+					ids = append(ids, bpID)
+				}
+			}
+			sort.Ints(ids)
+
+			breakpoints := make([]map[string]any, 0, len(ids))
+			hits := 0
+			for _, bpID := range ids {
+				h := stacks[bpID]
+				bp := map[string]any{
+					"id":       bpID,
+					"depth":    h.Depth,
+					"hits":     h.Hits,
+					"distinct": len(h.Paths),
+					"paths":    h.top(hitStacksTop),
+				}
+				if h.Other > 0 {
+					bp["otherHits"] = h.Other
+				}
+				breakpoints = append(breakpoints, bp)
+				hits += h.Hits
+			}
+
+			data := map[string]any{"breakpoints": breakpoints, "hits": hits}
+			msg := fmt.Sprintf("%d hits recorded at %d breakpoints", hits, len(breakpoints))
+			if len(breakpoints) == 1 {
+				if paths := breakpoints[0]["paths"].([]map[string]any); len(paths) > 0 {
+					msg = fmt.Sprintf("%d hits on %d paths; top: %v%% via %s", hits, breakpoints[0]["distinct"], paths[0]["percent"], topCaller(paths[0]["frames"].([]string)))
+				}
+			}
+			output.Success("hit-stacks", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	hitStacksCmd.Flags().IntVar(&hitStacksTop, "top", 10, "Show the N most frequent paths per breakpoint (0 = all)")
+	root.AddCommand(hitStacksCmd)
+}
+
+// topCaller names the caller in a call path, or the frame itself
+func topCaller(frames []string) string {
+	if len(frames) > 1 {
+		return frames[1]
+	}
+	if len(frames) == 1 {
+		return frames[0]
+	}
+	return "?"
+}
+
+func init() {
+	addHitStacksCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
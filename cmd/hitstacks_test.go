@@ -0,0 +1,39 @@
+package cmd
+
+import "testing"
+
+func TestHitStacksAdd(t *testing.T) {
+	h := &hitStacks{Depth: 2}
+	a := []string{"main.lookup cache.go:88", "main.handle server.go:40"}
+	b := []string{"main.lookup cache.go:88", "main.warm cache.go:20"}
+	h.add(a, 1)
+	h.add(b, 2)
+	h.add(a, 3)
+
+	if h.Hits != 3 || len(h.Paths) != 2 {
+		t.Fatalf("hits = %d, paths = %d, want 3 and 2", h.Hits, len(h.Paths))
+	}
+	top := h.top(1)
+	if len(top) != 1 {
+		t.Fatalf("top(1) = %d paths", len(top))
+	}
+	if top[0]["count"] != 2 || top[0]["percent"] != 66.6 {
+		t.Errorf("top path count = %v, percent = %v, want 2 and 66.6", top[0]["count"], top[0]["percent"])
+	}
+	if top[0]["firstHit"] != uint64(1) || top[0]["lastHit"] != uint64(3) {
+		t.Errorf("top path hits = %v..%v, want 1..3", top[0]["firstHit"], top[0]["lastHit"])
+	}
+	if got := topCaller(top[0]["frames"].([]string)); got != "main.handle server.go:40" {
+		t.Errorf("topCaller = %q", got)
+	}
+}
+
+func TestHitStacksOther(t *testing.T) {
+	h := &hitStacks{}
+	for i := 0; i <= maxHitPaths; i++ {
+		h.add([]string{string(rune('a' + i%26)), string(rune('a' + i/26))}, uint64(i))
+	}
+	if len(h.Paths) != maxHitPaths || h.Other != 1 {
+		t.Errorf("paths = %d, other = %d, want %d and 1", len(h.Paths), h.Other, maxHitPaths)
+	}
+}
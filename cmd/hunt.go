@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// How a hunt run ended
+const (
+	huntReproduced = "reproduced" // --until held at a stop
+	huntExited     = "exited"     // the process exited
+	huntFinished   = "finished"   // the script ran out of steps
+	huntTimeout    = "timeout"    // halted after --run-timeout
+	huntInterrupt  = "interrupt"  // SIGINT or SIGTERM; ends the hunt
+)
+
+// huntRun is the outcome of one run, emitted as a "run" event
+type huntRun struct {
+	Event      string `json:"event"`
+	Run        int    `json:"run"`
+	Outcome    string `json:"outcome"`
+	Stops      int    `json:"stops"`
+	DurationMs int64  `json:"durationMs"`
+	ExitStatus *int   `json:"exitStatus,omitempty"`
+	Location   string `json:"location,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// huntUntilHit interprets the value of the --until expression
+func huntUntilHit(expr string, v *api.Variable) (bool, error) {
+	if v.Kind != reflect.Bool {
+		return false, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("--until must be a boolean expression, %s is %s", expr, v.Type),
+			map[string]any{"until": expr, "type": v.Type},
+		)
+	}
+	return v.Value == "true", nil
+}
+
+// huntBreakpoint builds the breakpoint of a script break step, resolving
+// its location as break does
+func huntBreakpoint(c *debugger.Client, step bisectStep) (*api.Breakpoint, error) {
+	cond, info := conditionFlag(step.Cond)
+	if info != nil {
+		return nil, info
+	}
+	bp, err := locationBreakpoint(c, step.Arg)
+	if err != nil {
+		return nil, err
+	}
+	bp.Cond = cond
+	return bp, nil
+}
+
+// hunter runs the hunt's runs against one session
+type hunter struct {
+	c          *debugger.Client
+	until      string
+	script     *bisectScript
+	runTimeout time.Duration
+
+	// evaluated counts the stops at which --until could be evaluated;
+	// evalErr is the last reason it could not
+	evaluated int
+	evalErr   string
+}
+
+// check evaluates --until at the current stop. A stop where it cannot be
+// evaluated, e.g. because a variable is out of scope there, does not match.
+func (h *hunter) check(state *api.DebuggerState) (bool, error) {
+	scope := api.EvalScope{GoroutineID: -1}
+	if state.SelectedGoroutine != nil {
+		scope.GoroutineID = state.SelectedGoroutine.ID
+	}
+	v, err := h.c.EvalInScope(scope, h.until, debugger.DefaultLoadConfig())
+	if err != nil {
+		h.evalErr = err.Error()
+		return false, nil
+	}
+	h.evaluated++
+	return huntUntilHit(h.until, v)
+}
+
+// advance runs one execution step; continue is halted once halt fires
+func (h *hunter) advance(cmd string, halt <-chan string) (*api.DebuggerState, string, error) {
+	switch cmd {
+	case "next":
+		state, err := h.c.Next()
+		return state, "", err
+	case "step":
+		state, err := h.c.Step()
+		return state, "", err
+	case "stepout":
+		state, err := h.c.StepOut()
+		return state, "", err
+	}
+	return traceContinue(h.c, halt)
+}
+
+// run restarts the program and drives it through the script (or from stop
+// to stop until it exits) checking --until after every step. obs collects
+// what the script's eval, locals and args steps observed.
+func (h *hunter) run(n int, obs map[string]string) (huntRun, *api.DebuggerState, error) {
+	res := huntRun{Event: "run", Run: n}
+	start := time.Now()
+	defer func() { res.DurationMs = time.Since(start).Milliseconds() }()
+
+	var args []string
+	if h.script != nil {
+		args = h.script.Args
+	}
+	state, err := h.c.Rerun(args)
+	if err != nil {
+		return res, nil, err
+	}
+	halt, finished := traceHalts(h.runTimeout)
+	defer finished()
+
+	steps := []bisectStep{{Cmd: "continue"}}
+	if h.script != nil {
+		steps = h.script.Steps
+	}
+	for i := 0; i < len(steps); i++ {
+		step := steps[i]
+		key := strconv.Itoa(i+1) + ":" + step.Cmd
+		switch step.Cmd {
+		case "break":
+			// Set once before the first run; breakpoints survive restarts
+			continue
+		case "eval", "locals", "args":
+			observeBisectStep(h.c, state, step, key, obs)
+			continue
+		}
+
+		var reason string
+		state, reason, err = h.advance(step.Cmd, halt)
+		if err != nil {
+			res.Error = err.Error()
+			return res, state, nil
+		}
+		res.Stops++
+		if state.Exited {
+			status := state.ExitStatus
+			res.Outcome, res.ExitStatus = huntExited, &status
+			return res, state, nil
+		}
+		hit, err := h.check(state)
+		if err != nil {
+			return res, state, err
+		}
+		res.Location = bisectLocation("", state)
+		switch {
+		case hit:
+			res.Outcome = huntReproduced
+		case reason == traceStopInterrupt:
+			res.Outcome = huntInterrupt
+		case reason != "":
+			res.Outcome = huntTimeout
+		case h.script == nil:
+			// Without a script, keep continuing until the program exits
+			i--
+			continue
+		default:
+			continue
+		}
+		return res, state, nil
+	}
+	res.Outcome = huntFinished
+	return res, state, nil
+}
+
+// addHuntCommand adds the hunt command
+func addHuntCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var huntUntil, huntScriptPath string
+	var huntMaxRuns int
+	var huntRunTimeout time.Duration
+
+	huntCmd := &cobra.Command{
+		Use:   "hunt --until <expr>",
+		Short: "Restart the program until a flaky condition reproduces",
+		Long: `Restart the program over and over, drive each run to its stops and evaluate
+--until at every one, until it is true. The hunt ends paused at the stop
+where the condition held, ready for locals, stack and goroutines, or after
+--max-runs runs without it.
+
+Without --script each run continues from breakpoint to breakpoint (set them
+beforehand) until the program exits. With --script each run replays its
+steps instead, in the format bisect-run takes:
+  {
+    "args":  ["-workers", "8"],
+    "steps": [
+      {"cmd": "break", "arg": "queue.go:88"},
+      {"cmd": "continue"},
+      {"cmd": "next"},
+      {"cmd": "eval", "arg": "q.len"}
+    ]
+  }
+break steps are set once before the first run (paths relative to the working
+directory, as for break); --until is checked after every continue, next, step
+and stepout; eval, locals and args steps are reported for the reproducing run.
+
+--until is a boolean Go expression evaluated in the selected goroutine; it
+takes the same helpers as break --cond. A stop where it cannot be evaluated
+(a variable out of scope) does not match. A run still going after
+--run-timeout is halted and checked once more, which catches hangs. The
+program is restarted from the same binary, without rebuilding.
+
+Each run is streamed as a "run" event line; the final response reports the
+stop and the outcome counts.
+
+Example:
+  godebug --addr $ADDR break worker.go:57
+  godebug --addr $ADDR hunt --until 'len(results) != expected' --max-runs 100
+  godebug --addr $ADDR hunt --until 'balance < 0' --script steps.json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if huntUntil == "" {
+				output.ErrorWithInfo("hunt", output.InvalidArgument("--until is required")).PrintAndExit(getOutputFormat())
+			}
+			if huntMaxRuns < 1 {
+				output.ErrorWithInfo("hunt", output.InvalidArgument("--max-runs must be at least 1")).PrintAndExit(getOutputFormat())
+			}
+			until, info := conditionFlag(huntUntil)
+			if info != nil {
+				output.ErrorWithInfo("hunt", info).PrintAndExit(getOutputFormat())
+			}
+			var script *bisectScript
+			if huntScriptPath != "" {
+				var err error
+				if script, err = loadBisectScript(huntScriptPath); err != nil {
+					output.Error("hunt", err).PrintAndExit(getOutputFormat())
+				}
+			}
+
+			c := mustGetClient("hunt")
+			defer func() { _ = c.Close() }()
+			c.SetTimeout(getTimeout())
+
+			if script != nil {
+				for i, step := range script.Steps {
+					if step.Cmd != "break" {
+						continue
+					}
+					bp, err := huntBreakpoint(c, step)
+					if err == nil {
+						_, err = c.CreateBreakpoint(bp)
+					}
+					// Breakpoints of an earlier hunt with the same script are reused
+					if err != nil && !strings.Contains(err.Error(), "Breakpoint exists") {
+						output.ErrorWithInfo("hunt", output.FromError(err).WithDetails(map[string]any{
+							"script": huntScriptPath,
+							"step":   i + 1,
+						})).PrintAndExit(getOutputFormat())
+					}
+				}
+			}
+
+			h := &hunter{c: c, until: until, script: script, runTimeout: huntRunTimeout}
+			outcomes := map[string]int{}
+			var last huntRun
+			var state *api.DebuggerState
+			var obs map[string]string
+			for n := 1; n <= huntMaxRuns; n++ {
+				obs = map[string]string{}
+				var err error
+				last, state, err = h.run(n, obs)
+				if err != nil {
+					output.ErrorWithInfo("hunt", output.FromError(err).WithDetails(map[string]any{"run": n})).PrintAndExit(getOutputFormat())
+				}
+				output.Emit(last)
+				if last.Error != "" {
+					outcomes["error"]++
+				} else {
+					outcomes[last.Outcome]++
+				}
+				if last.Outcome == huntReproduced || last.Outcome == huntInterrupt {
+					break
+				}
+			}
+
+			data := map[string]any{
+				"until":      huntUntil,
+				"reproduced": last.Outcome == huntReproduced,
+				"runs":       last.Run,
+				"outcomes":   outcomes,
+			}
+			if state != nil {
+				stoppedAt := recordStop(c, "hunt", state)
+				for k, v := range stateToData(state) {
+					data[k] = v
+				}
+				stoppedAt.addTo(data)
+			}
+			if until != huntUntil {
+				data["untilExpanded"] = until
+			}
+
+			if last.Outcome == huntReproduced {
+				data["run"] = last.Run
+				if len(obs) > 0 {
+					data["observations"] = obs
+				}
+				output.Success("hunt", data, fmt.Sprintf("Reproduced on run %d of %d: %s", last.Run, huntMaxRuns, huntUntil)).PrintAndExit(getOutputFormat())
+			}
+			switch {
+			case h.evaluated > 0:
+			case h.evalErr != "":
+				data["hint"] = fmt.Sprintf("--until could not be evaluated at any stop (%s); set a breakpoint where its variables are in scope", h.evalErr)
+			default:
+				data["hint"] = "the program never stopped; set a breakpoint where --until should be checked"
+			}
+			output.Success("hunt", data, fmt.Sprintf("Not reproduced in %d runs", last.Run)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	huntCmd.Flags().StringVar(&huntUntil, "until", "", "Boolean expression that identifies the bug")
+	huntCmd.Flags().IntVar(&huntMaxRuns, "max-runs", 100, "Maximum number of runs")
+	huntCmd.Flags().StringVar(&huntScriptPath, "script", "", "JSON file with program args and the steps of each run")
+	huntCmd.Flags().DurationVar(&huntRunTimeout, "run-timeout", 30*time.Second, "Halt a run still going after this long (0 = never)")
+	root.AddCommand(huntCmd)
+}
+
+func init() {
+	addHuntCommand(rootCmd, MustGetClient, GetOutputFormat, GetTimeout)
+}
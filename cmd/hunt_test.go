@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestHuntUntilHit checks that only a true boolean matches and that other
+// types are rejected.
+func TestHuntUntilHit(t *testing.T) {
+	if hit, err := huntUntilHit("n > 3", &api.Variable{Kind: reflect.Bool, Type: "bool", Value: "true"}); !hit || err != nil {
+		t.Errorf("true: got %v, %v", hit, err)
+	}
+	if hit, err := huntUntilHit("n > 3", &api.Variable{Kind: reflect.Bool, Type: "bool", Value: "false"}); hit || err != nil {
+		t.Errorf("false: got %v, %v", hit, err)
+	}
+	if _, err := huntUntilHit("n", &api.Variable{Kind: reflect.Int, Type: "int", Value: "1"}); err == nil {
+		t.Error("int: expected an error")
+	}
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Agents init-agent can configure
+const (
+	agentClaudeCode = "claude-code"
+	agentCursor     = "cursor"
+)
+
+// Outcomes of writing one configuration file
+const (
+	agentFileCreated   = "created"
+	agentFileUpdated   = "updated"
+	agentFileUnchanged = "unchanged"
+	agentFileSkipped   = "skipped" // exists and --force was not given
+)
+
+// agentSkill holds .claude/skills/godebug/SKILL.md, embedded by package main
+var agentSkill []byte
+
+// SetAgentSkill provides the skill document init-agent installs
+func SetAgentSkill(skill []byte) {
+	agentSkill = skill
+}
+
+// readOnlyRefused are options and commands the wrapper refuses in read-only
+// mode because they change the target's state
+var readOnlyRefused = []string{"--allow-calls", "call", "set"}
+
+// timeoutRefused are options the wrapper always refuses because they would
+// let a command outlive the pinned timeout
+var timeoutRefused = []string{"--no-timeout"}
+
+// agentFile is a configuration file init-agent wrote or left alone
+type agentFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Note   string `json:"note,omitempty"`
+}
+
+// agentPreset describes the files written for one agent
+type agentPreset struct {
+	Wrapper  string // wrapper script, relative to the project directory
+	Doc      string // skill or rule document
+	Settings string // permission settings merged into, if the agent has them
+}
+
+var agentPresets = map[string]agentPreset{
+	agentClaudeCode: {
+		Wrapper:  ".claude/bin/godebug",
+		Doc:      ".claude/skills/godebug/SKILL.md",
+		Settings: ".claude/settings.json",
+	},
+	agentCursor: {
+		Wrapper: ".cursor/bin/godebug",
+		Doc:     ".cursor/rules/godebug.mdc",
+	},
+}
+
+// refusedOptions lists the options the wrapper refuses
+func refusedOptions(readOnly bool) []string {
+	if readOnly {
+		return append(slices.Clone(readOnlyRefused), timeoutRefused...)
+	}
+	return slices.Clone(timeoutRefused)
+}
+
+// agentWrapper renders the wrapper script. It pins the operation timeout and
+// answers refused options with a response envelope instead of running them.
+func agentWrapper(timeout time.Duration, readOnly bool) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Written by godebug init-agent: runs godebug with the agent's safe defaults.\n")
+	if readOnly {
+		b.WriteString("# Read-only: options that change the target's state are refused.\n")
+	}
+	b.WriteString("for arg in \"$@\"; do\n\tcase \"$arg\" in\n")
+	for _, opt := range refusedOptions(readOnly) {
+		if strings.HasPrefix(opt, "-") {
+			fmt.Fprintf(&b, "\t%s | %s=*)\n", opt, opt)
+		} else {
+			fmt.Fprintf(&b, "\t%s)\n", opt)
+		}
+		fmt.Fprintf(&b, "\t\techo '{\"success\":false,\"command\":\"godebug\",\"error\":{\"code\":\"INVALID_ARGUMENT\",\"message\":\"%s is disabled by the agent configuration (see godebug init-agent)\"}}'\n", opt)
+		fmt.Fprintf(&b, "\t\texit %d\n\t\t;;\n", output.ExitUsageError)
+	}
+	b.WriteString("\tesac\ndone\n")
+	// Flags given by the caller come later and win, so --timeout stays adjustable
+	fmt.Fprintf(&b, "exec godebug --timeout %s \"$@\"\n", timeout)
+	return b.String()
+}
+
+// agentNote tells the agent to run godebug through the wrapper
+func agentNote(wrapper string, readOnly bool) string {
+	note := fmt.Sprintf("In this project run godebug as `%s` (from the project root): it pins the command timeout", wrapper)
+	if readOnly {
+		note += " and refuses --allow-calls and the call and set commands, so inspection never changes the program"
+	}
+	return note + ". Use it exactly like `godebug` in the examples below.\n"
+}
+
+// splitFrontMatter separates a markdown document's --- front matter from its body
+func splitFrontMatter(doc string) (string, string) {
+	if !strings.HasPrefix(doc, "---\n") {
+		return "", doc
+	}
+	end := strings.Index(doc[4:], "\n---\n")
+	if end < 0 {
+		return "", doc
+	}
+	return doc[:4+end+5], doc[4+end+5:]
+}
+
+// claudeSkill is the skill document with the wrapper note after its front matter
+func claudeSkill(skill, wrapper string, readOnly bool) string {
+	front, body := splitFrontMatter(skill)
+	return front + "\n" + agentNote(wrapper, readOnly) + body
+}
+
+// cursorRule turns the skill into a Cursor project rule, attached to Go files
+// and otherwise available on request
+func cursorRule(skill, wrapper string, readOnly bool) string {
+	_, body := splitFrontMatter(skill)
+	front := "---\n" +
+		"description: Debug Go programs with godebug, a Delve CLI whose commands print JSON\n" +
+		"globs: \"**/*.go\"\n" +
+		"alwaysApply: false\n" +
+		"---\n"
+	return front + "\n" + agentNote(wrapper, readOnly) + body
+}
+
+// claudePermissions are the settings entries init-agent adds: the wrapper
+// runs without prompting, godebug itself still asks
+func claudePermissions(wrapper string) map[string][]string {
+	return map[string][]string{
+		"allow": {"Bash(" + wrapper + ":*)"},
+		"ask":   {"Bash(godebug:*)"},
+	}
+}
+
+// mergeClaudeSettings adds the permission entries to existing settings JSON,
+// keeping everything else. It reports whether anything was added.
+func mergeClaudeSettings(existing []byte, permissions map[string][]string) ([]byte, bool, error) {
+	settings := map[string]any{}
+	if len(bytes.TrimSpace(existing)) > 0 {
+		if err := json.Unmarshal(existing, &settings); err != nil {
+			return nil, false, err
+		}
+	}
+	perms, _ := settings["permissions"].(map[string]any)
+	if perms == nil {
+		if _, ok := settings["permissions"]; ok {
+			return nil, false, fmt.Errorf("permissions is not an object")
+		}
+		perms = map[string]any{}
+	}
+	changed := false
+	for _, kind := range []string{"allow", "ask"} {
+		list, _ := perms[kind].([]any)
+		for _, rule := range permissions[kind] {
+			if !slices.Contains(list, any(rule)) {
+				list = append(list, rule)
+				changed = true
+			}
+		}
+		perms[kind] = list
+	}
+	settings["permissions"] = perms
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	return append(data, '\n'), changed, nil
+}
+
+// writeAgentFile writes contents to dir/rel, leaving an existing file with
+// other contents alone unless force is set
+func writeAgentFile(dir, rel string, contents []byte, mode os.FileMode, force bool) (agentFile, error) {
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	file := agentFile{Path: rel, Action: agentFileCreated}
+	if old, err := os.ReadFile(path); err == nil {
+		switch {
+		case bytes.Equal(old, contents):
+			file.Action = agentFileUnchanged
+			return file, os.Chmod(path, mode)
+		case !force:
+			file.Action = agentFileSkipped
+			file.Note = "exists; rerun with --force to replace it"
+			return file, nil
+		}
+		file.Action = agentFileUpdated
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return file, err
+	}
+	if err := os.WriteFile(path, contents, mode); err != nil {
+		return file, err
+	}
+	return file, os.Chmod(path, mode)
+}
+
+// installAgent writes the preset of target into dir
+func installAgent(dir, target string, timeout time.Duration, readOnly, force bool) ([]agentFile, error) {
+	preset := agentPresets[target]
+	wrapper := "./" + preset.Wrapper
+	var files []agentFile
+
+	file, err := writeAgentFile(dir, preset.Wrapper, []byte(agentWrapper(timeout, readOnly)), 0o755, force)
+	if err != nil {
+		return files, err
+	}
+	files = append(files, file)
+
+	doc := claudeSkill(string(agentSkill), wrapper, readOnly)
+	if target == agentCursor {
+		doc = cursorRule(string(agentSkill), wrapper, readOnly)
+	}
+	if file, err = writeAgentFile(dir, preset.Doc, []byte(doc), 0o644, force); err != nil {
+		return files, err
+	}
+	files = append(files, file)
+
+	if preset.Settings != "" {
+		// Settings are merged rather than replaced, so they need no --force
+		path := filepath.Join(dir, filepath.FromSlash(preset.Settings))
+		existing, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return files, err
+		}
+		merged, changed, err := mergeClaudeSettings(existing, claudePermissions(wrapper))
+		if err != nil {
+			return files, fmt.Errorf("%s: %w", preset.Settings, err)
+		}
+		file := agentFile{Path: preset.Settings, Action: agentFileUnchanged}
+		if changed {
+			file.Action = agentFileUpdated
+			if existing == nil {
+				file.Action = agentFileCreated
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return files, err
+			}
+			if err := os.WriteFile(path, merged, 0o644); err != nil {
+				return files, err
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// addInitAgentCommand adds the init-agent command
+func addInitAgentCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var initTarget, initDir string
+	var initForce, initAllowMutation bool
+
+	initAgentCmd := &cobra.Command{
+		Use:   "init-agent",
+		Short: "Write a project's coding agent configuration for godebug",
+		Long: `Write the local configuration a coding agent needs to debug with godebug.
+
+Both targets get a wrapper script that runs godebug with safe defaults: the
+--timeout in effect for init-agent is pinned for every command, --no-timeout
+is refused, and, unless --allow-mutation is given, so are --allow-calls and
+the call and set commands, so the agent can stop and inspect the program but not
+change it.
+
+  claude-code   .claude/bin/godebug              wrapper
+                .claude/skills/godebug/SKILL.md  the godebug skill
+                .claude/settings.json            allow the wrapper, ask before
+                                                 running godebug directly
+  cursor        .cursor/bin/godebug              wrapper
+                .cursor/rules/godebug.mdc        the skill as a project rule
+
+Existing files with other contents are skipped unless --force is given;
+settings.json is merged, keeping its other entries. Each file is reported as
+created, updated, unchanged or skipped.
+
+Example:
+  godebug init-agent --target claude-code
+  godebug init-agent --target cursor --dir ~/src/myapp --timeout 1m`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if _, ok := agentPresets[initTarget]; !ok {
+				output.ErrorWithInfo("init-agent", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("unknown target %q", initTarget),
+					map[string]any{"target": initTarget, "targets": []string{agentClaudeCode, agentCursor}},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if len(agentSkill) == 0 {
+				output.ErrorWithInfo("init-agent", output.NewErrorInfo(output.ErrCodeInternalError, "skill document not embedded in this build")).PrintAndExit(getOutputFormat())
+			}
+			dir, err := filepath.Abs(initDir)
+			if err != nil {
+				output.Error("init-agent", err).PrintAndExit(getOutputFormat())
+			}
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				output.ErrorWithInfo("init-agent", output.NotFound("directory", initDir)).PrintAndExit(getOutputFormat())
+			}
+
+			readOnly := !initAllowMutation
+			files, err := installAgent(dir, initTarget, getTimeout(), readOnly, initForce)
+			if err != nil {
+				output.Error("init-agent", err).PrintAndExit(getOutputFormat())
+			}
+
+			skipped := 0
+			for _, f := range files {
+				if f.Action == agentFileSkipped {
+					skipped++
+				}
+			}
+			data := map[string]any{
+				"target":   initTarget,
+				"dir":      dir,
+				"files":    files,
+				"wrapper":  "./" + agentPresets[initTarget].Wrapper,
+				"timeout":  getTimeout().String(),
+				"readOnly": readOnly,
+				"refused":  refusedOptions(readOnly),
+			}
+			msg := fmt.Sprintf("Configured %s in %s", initTarget, dir)
+			if skipped > 0 {
+				msg += fmt.Sprintf(" (%d existing files skipped)", skipped)
+			}
+			output.Success("init-agent", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	initAgentCmd.Flags().StringVar(&initTarget, "target", "", "Agent to configure: claude-code or cursor")
+	initAgentCmd.Flags().StringVar(&initDir, "dir", ".", "Project directory to write the configuration into")
+	initAgentCmd.Flags().BoolVar(&initForce, "force", false, "Replace existing files")
+	initAgentCmd.Flags().BoolVar(&initAllowMutation, "allow-mutation", false, "Let the wrapper pass --allow-calls, call and set through")
+	root.AddCommand(initAgentCmd)
+}
+
+func init() {
+	addInitAgentCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
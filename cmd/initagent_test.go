@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestInstallAgentClaudeCode checks the written files, the settings merge and
+// that existing files are only replaced with force.
+func TestInstallAgentClaudeCode(t *testing.T) {
+	SetAgentSkill([]byte("---\nname: godebug\n---\n\n# godebug\n"))
+	dir := t.TempDir()
+	settings := filepath.Join(dir, ".claude", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settings), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settings, []byte(`{"model":"x","permissions":{"allow":["Bash(go test:*)"]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := installAgent(dir, agentClaudeCode, 45*time.Second, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		".claude/bin/godebug":             agentFileCreated,
+		".claude/skills/godebug/SKILL.md": agentFileCreated,
+		".claude/settings.json":           agentFileUpdated,
+	}
+	for _, f := range files {
+		if want[f.Path] != f.Action {
+			t.Errorf("%s: action %s, want %s", f.Path, f.Action, want[f.Path])
+		}
+	}
+
+	var merged map[string]any
+	data, _ := os.ReadFile(settings)
+	if err := json.Unmarshal(data, &merged); err != nil {
+		t.Fatal(err)
+	}
+	allow := merged["permissions"].(map[string]any)["allow"].([]any)
+	if merged["model"] != "x" || len(allow) != 2 || allow[1] != "Bash(./.claude/bin/godebug:*)" {
+		t.Errorf("settings not merged: %s", data)
+	}
+
+	skill, _ := os.ReadFile(filepath.Join(dir, ".claude", "skills", "godebug", "SKILL.md"))
+	if !strings.HasPrefix(string(skill), "---\nname: godebug\n---\n\nIn this project run godebug as `./.claude/bin/godebug`") {
+		t.Errorf("skill note not after front matter:\n%s", skill)
+	}
+
+	SetAgentSkill([]byte("---\nname: godebug\n---\n\n# changed\n"))
+	files, err = installAgent(dir, agentClaudeCode, 45*time.Second, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files[0].Action != agentFileUnchanged || files[1].Action != agentFileSkipped || files[2].Action != agentFileUnchanged {
+		t.Errorf("rerun: %+v", files)
+	}
+	files, _ = installAgent(dir, agentClaudeCode, 45*time.Second, true, true)
+	if files[1].Action != agentFileUpdated {
+		t.Errorf("rerun with force: %+v", files[1])
+	}
+}
+
+// TestAgentWrapperRefusesMutation runs the generated wrapper script.
+func TestAgentWrapperRefusesMutation(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	path := filepath.Join(t.TempDir(), "godebug")
+	if err := os.WriteFile(path, []byte(agentWrapper(30*time.Second, true)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(path, "--addr", "x", "eval", "--allow-calls", "f()").Output()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+		t.Fatalf("wrapper did not refuse --allow-calls: %v", err)
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(out, &resp); err != nil || resp["success"] != false {
+		t.Errorf("refusal is not a response envelope: %s", out)
+	}
+	if err := exec.Command(path, "--addr", "x", "call", "u.Reset()").Run(); err == nil {
+		t.Error("wrapper did not refuse the call command")
+	}
+	if err := exec.Command(path, "--addr", "x", "set", "x=42").Run(); err == nil {
+		t.Error("wrapper did not refuse the set command")
+	}
+	if strings.Contains(agentWrapper(30*time.Second, false), "--allow-calls") {
+		t.Error("--allow-mutation wrapper still refuses --allow-calls")
+	}
+}
@@ -30,14 +30,55 @@ func variableToMap(v api.Variable) map[string]any {
 	return m
 }
 
+// deferredScope builds the evaluation scope for a goroutine's frame, optionally
+// selecting its Nth deferred call (1-based, 0 = the frame itself)
+func deferredScope(goroutineID int64, frame, deferred int) api.EvalScope {
+	return api.EvalScope{GoroutineID: goroutineID, Frame: frame, DeferredCall: deferred}
+}
+
+// validateDeferred rejects negative --deferred values
+func validateDeferred(cmdName string, deferred int, getOutputFormat func() output.OutputFormat) {
+	if deferred < 0 {
+		output.ErrorWithInfo(cmdName, output.InvalidArgumentWithDetails(
+			fmt.Sprintf("invalid deferred call index: %d (must be >= 0)", deferred),
+			map[string]any{"deferred": deferred},
+		)).PrintAndExit(getOutputFormat())
+	}
+}
+
+var (
+	localsDeferred  int
+	localsGoroutine int64
+	localsStream    bool
+	argsDeferred    int
+	argsGoroutine   int64
+	evalDeferred    int
+	evalGoroutine   int64
+	evalAllowCalls  bool
+)
+
 var localsCmd = &cobra.Command{
 	Use:   "locals",
 	Short: "Show local variables",
 	Long: `List all local variables in the current scope.
 
+Use --deferred N to list the locals of the Nth deferred call of the current
+frame instead, e.g. a recover handler while a panic is unwinding. Use
+--goroutine ID to read another goroutine without switching to it.
+
+--stream emits each variable tree as NDJSON node records (event, path,
+depth, type, kind, value, len), parents first, instead of nested objects, so
+huge structures can be processed or cut off as they arrive. Values are
+loaded deeper and wider than by default; the final response counts them.
+
 Example:
-  godebug --addr $ADDR locals`,
+  godebug --addr $ADDR locals
+  godebug --addr $ADDR locals --deferred 1
+  godebug --addr $ADDR locals --goroutine 7
+  godebug --addr $ADDR locals --stream`,
 	Run: func(cmd *cobra.Command, args []string) {
+		validateDeferred("locals", localsDeferred, GetOutputFormat)
+
 		c := MustGetClient("locals")
 		defer func() { _ = c.Close() }()
 
@@ -46,26 +87,49 @@ Example:
 			output.Error("locals", err).PrintAndExit(GetOutputFormat())
 		}
 
-		if state.SelectedGoroutine == nil {
+		goroutineID, ok := targetGoroutine(state, localsGoroutine)
+		if !ok {
 			output.ErrorWithInfo("locals", output.NotFound("goroutine", "none selected")).PrintAndExit(GetOutputFormat())
 		}
 
-		vars, err := c.ListLocalVars(state.SelectedGoroutine.ID, 0, debugger.DefaultLoadConfig())
+		frame := mustSelectedFrame(c, "locals", state, goroutineID, GetOutputFormat)
+		cfg := debugger.DefaultLoadConfig()
+		if localsStream {
+			cfg = streamLoadConfig
+		}
+		vars, err := c.ListLocalVarsInScope(deferredScope(goroutineID, frame, localsDeferred), cfg)
 		if err != nil {
 			output.Error("locals", err).PrintAndExit(GetOutputFormat())
 		}
 
-		variables := make([]map[string]any, len(vars))
-		for i, v := range vars {
-			variables[i] = variableToMap(v)
+		var data map[string]any
+		if localsStream {
+			data = map[string]any{
+				"count":    len(vars),
+				"nodes":    emitVariables(vars),
+				"streamed": true,
+			}
+		} else {
+			variables := make([]map[string]any, len(vars))
+			for i, v := range vars {
+				variables[i] = variableToMap(v)
+			}
+			data = map[string]any{
+				"variables": variables,
+				"count":     len(variables),
+			}
 		}
-
-		data := map[string]any{
-			"variables": variables,
-			"count":     len(variables),
+		if localsGoroutine > 0 {
+			data["goroutineId"] = goroutineID
+		}
+		if frame > 0 {
+			data["frame"] = frame
+		}
+		if localsDeferred > 0 {
+			data["deferredCall"] = localsDeferred
 		}
 
-		output.Success("locals", data, fmt.Sprintf("%d local variables", len(variables))).PrintAndExit(GetOutputFormat())
+		output.Success("locals", data, fmt.Sprintf("%d local variables", len(vars))).PrintAndExit(GetOutputFormat())
 	},
 }
 
@@ -74,9 +138,15 @@ var argsCmd = &cobra.Command{
 	Short: "Show function arguments",
 	Long: `List all arguments to the current function.
 
+Use --deferred N to list the arguments of the Nth deferred call instead,
+and --goroutine ID to read another goroutine without switching to it.
+
 Example:
-  godebug --addr $ADDR args`,
+  godebug --addr $ADDR args
+  godebug --addr $ADDR args --deferred 1`,
 	Run: func(cmd *cobra.Command, args []string) {
+		validateDeferred("args", argsDeferred, GetOutputFormat)
+
 		c := MustGetClient("args")
 		defer func() { _ = c.Close() }()
 
@@ -85,11 +155,13 @@ Example:
 			output.Error("args", err).PrintAndExit(GetOutputFormat())
 		}
 
-		if state.SelectedGoroutine == nil {
+		goroutineID, ok := targetGoroutine(state, argsGoroutine)
+		if !ok {
 			output.ErrorWithInfo("args", output.NotFound("goroutine", "none selected")).PrintAndExit(GetOutputFormat())
 		}
 
-		funcArgs, err := c.ListFunctionArgs(state.SelectedGoroutine.ID, 0, debugger.DefaultLoadConfig())
+		frame := mustSelectedFrame(c, "args", state, goroutineID, GetOutputFormat)
+		funcArgs, err := c.ListFunctionArgsInScope(deferredScope(goroutineID, frame, argsDeferred), debugger.DefaultLoadConfig())
 		if err != nil {
 			output.Error("args", err).PrintAndExit(GetOutputFormat())
 		}
@@ -103,6 +175,15 @@ Example:
 			"arguments": arguments,
 			"count":     len(arguments),
 		}
+		if argsGoroutine > 0 {
+			data["goroutineId"] = goroutineID
+		}
+		if frame > 0 {
+			data["frame"] = frame
+		}
+		if argsDeferred > 0 {
+			data["deferredCall"] = argsDeferred
+		}
 
 		output.Success("args", data, fmt.Sprintf("%d arguments", len(arguments))).PrintAndExit(GetOutputFormat())
 	},
@@ -113,13 +194,31 @@ var evalCmd = &cobra.Command{
 	Short: "Evaluate an expression",
 	Long: `Evaluate a Go expression in the current context.
 
+Use --deferred N to evaluate in the scope of the Nth deferred call of the
+current frame, e.g. inside a recover handler while a panic is unwinding.
+Use --goroutine ID to evaluate in another goroutine without switching to it.
+
+Expressions that call functions or methods, such as user.String(), are
+rejected unless --allow-calls is given: the call runs in the target and can
+change its state. The response lists the calls with a heuristic side effect
+assessment (unlikely for getters and formatters, possible otherwise). Calls
+run in the goroutine's topmost frame.
+
+When a path such as u.Profile.Address.City fails on a nil pointer, each
+prefix is evaluated to find which one is nil; the error names it in
+details.nilSegment with its type.
+
 Examples:
   godebug --addr $ADDR eval "x"
   godebug --addr $ADDR eval "user.Name"
   godebug --addr $ADDR eval "len(items)"
-  godebug --addr $ADDR eval "x > 10"`,
+  godebug --addr $ADDR eval "x > 10"
+  godebug --addr $ADDR eval --deferred 1 "err"
+  godebug --addr $ADDR eval --allow-calls "user.String()"`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		validateDeferred("eval", evalDeferred, GetOutputFormat)
+
 		c := MustGetClient("eval")
 		defer func() { _ = c.Close() }()
 
@@ -130,23 +229,78 @@ Examples:
 			output.Error("eval", err).PrintAndExit(GetOutputFormat())
 		}
 
-		if state.SelectedGoroutine == nil {
+		goroutineID, ok := targetGoroutine(state, evalGoroutine)
+		if !ok {
 			output.ErrorWithInfo("eval", output.NotFound("goroutine", "none selected")).PrintAndExit(GetOutputFormat())
 		}
 
-		result, err := c.Eval(state.SelectedGoroutine.ID, 0, expr, debugger.DefaultLoadConfig())
+		frame := mustSelectedFrame(c, "eval", state, goroutineID, GetOutputFormat)
+		if evalAllowCalls && exprHasCalls(expr) {
+			if frame > 0 || evalDeferred > 0 {
+				output.ErrorWithInfo("eval", output.InvalidArgumentWithDetails(
+					"--allow-calls runs calls in the goroutine's topmost frame; select frame 0 and drop --deferred",
+					map[string]any{"frame": frame, "deferred": evalDeferred},
+				)).PrintAndExit(GetOutputFormat())
+			}
+			data, err := evalWithCalls(c, goroutineID, expr)
+			if err != nil {
+				output.Error("eval", err).PrintAndExit(GetOutputFormat())
+			}
+			if evalGoroutine > 0 {
+				data["goroutineId"] = goroutineID
+			}
+			msg := ""
+			if data["sideEffects"] == sideEffectsPossible {
+				msg = "the expression called functions that may have changed program state"
+			}
+			output.Success("eval", data, msg).PrintAndExit(GetOutputFormat())
+		}
+
+		scope := deferredScope(goroutineID, frame, evalDeferred)
+		result, err := c.EvalInScope(scope, expr, debugger.DefaultLoadConfig())
 		if err != nil {
+			if isCallNotAllowed(err) {
+				output.ErrorWithInfo("eval", callsNotAllowedError(expr)).PrintAndExit(GetOutputFormat())
+			}
+			if info := nilPathError(c, scope, expr); info != nil {
+				output.ErrorWithInfo("eval", info).PrintAndExit(GetOutputFormat())
+			}
 			output.Error("eval", err).PrintAndExit(GetOutputFormat())
 		}
+		// A field behind a nil pointer can come back unreadable instead of failing
+		if result.Unreadable != "" {
+			if info := nilPathError(c, scope, expr); info != nil {
+				output.ErrorWithInfo("eval", info).PrintAndExit(GetOutputFormat())
+			}
+		}
 
 		data := variableToMap(*result)
 		data["expression"] = expr
+		if evalGoroutine > 0 {
+			data["goroutineId"] = goroutineID
+		}
+		if frame > 0 {
+			data["frame"] = frame
+		}
+		if evalDeferred > 0 {
+			data["deferredCall"] = evalDeferred
+		}
 
 		output.Success("eval", data, "").PrintAndExit(GetOutputFormat())
 	},
 }
 
 func init() {
+	localsCmd.Flags().IntVar(&localsDeferred, "deferred", 0, "Inspect the scope of the Nth deferred call of the frame")
+	argsCmd.Flags().IntVar(&argsDeferred, "deferred", 0, "Inspect the scope of the Nth deferred call of the frame")
+	evalCmd.Flags().IntVar(&evalDeferred, "deferred", 0, "Evaluate in the scope of the Nth deferred call of the frame")
+
+	localsCmd.Flags().Int64Var(&localsGoroutine, "goroutine", 0, "Goroutine to inspect without switching (default: selected)")
+	localsCmd.Flags().BoolVar(&localsStream, "stream", false, "Emit the variable trees as NDJSON node records, loading deeper")
+	argsCmd.Flags().Int64Var(&argsGoroutine, "goroutine", 0, "Goroutine to inspect without switching (default: selected)")
+	evalCmd.Flags().Int64Var(&evalGoroutine, "goroutine", 0, "Goroutine to evaluate in without switching (default: selected)")
+	evalCmd.Flags().BoolVar(&evalAllowCalls, "allow-calls", false, "Allow the expression to call functions in the target (may change its state)")
+
 	rootCmd.AddCommand(localsCmd)
 	rootCmd.AddCommand(argsCmd)
 	rootCmd.AddCommand(evalCmd)
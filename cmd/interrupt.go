@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// addInterruptCommand adds the interrupt command
+func addInterruptCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	interruptCmd := &cobra.Command{
+		Use:     "interrupt",
+		Aliases: []string{"halt", "stop"},
+		Short:   "Halt a running program from another invocation",
+		Long: `Halt the program while it runs, regardless of which godebug invocation
+started the continue. The pending continue returns with the halted state, and
+so does interrupt.
+
+Use it when one invocation is blocked in continue (e.g. continue --no-timeout
+in the background) and the decision to stop comes later from another. If the
+program is not running, interrupt reports the current state and changes nothing.
+Once halted, goroutines, stack and locals inspect where the program was.
+
+halt and stop are other names for interrupt. Of the lifecycle commands, only
+interrupt keeps the session: the program stays halted under the debugger
+until continue. detach ends the session and leaves the process running;
+quit ends it and terminates the process (unless the session attached to it).
+
+Example:
+  godebug --addr $ADDR continue --no-timeout &
+  godebug --addr $ADDR interrupt
+  godebug --addr $ADDR halt && godebug --addr $ADDR goroutines
+  godebug --addr $ADDR stop`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("interrupt")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("interrupt", err).PrintAndExit(getOutputFormat())
+			}
+			if !state.Running {
+				data := stateToData(state)
+				data["interrupted"] = false
+				msg := "Process is not running; nothing to interrupt"
+				if state.Exited {
+					msg = "Process has exited; nothing to interrupt"
+				}
+				output.Success("interrupt", data, msg).PrintAndExit(getOutputFormat())
+			}
+
+			c.SetTimeout(getTimeout())
+			state, err = c.Halt()
+			if err != nil {
+				output.Error("interrupt", err).PrintAndExit(getOutputFormat())
+			}
+			stoppedAt := recordStop(c, "interrupt", state)
+
+			data := stateToData(state)
+			data["interrupted"] = true
+			stoppedAt.addTo(data)
+			msg := "Interrupted; process halted"
+			if state.Exited {
+				msg = "Process exited"
+			}
+			output.Success("interrupt", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	root.AddCommand(interruptCmd)
+}
+
+func init() {
+	addInterruptCommand(rootCmd, MustGetClient, GetOutputFormat, GetTimeout)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// labelFilter selects goroutines by a pprof label: Key with any value, or
+// Key set to Value
+type labelFilter struct {
+	Key   string
+	Value string
+	Any   bool
+}
+
+// parseLabelFilters parses --label values, key=value or key
+func parseLabelFilters(specs []string) ([]labelFilter, *output.ErrorInfo) {
+	filters := make([]labelFilter, 0, len(specs))
+	for _, spec := range specs {
+		key, value, hasValue := strings.Cut(spec, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, output.InvalidArgumentWithDetails(
+				fmt.Sprintf("invalid --label %q: expected key=value or key", spec),
+				map[string]any{"label": spec},
+			)
+		}
+		filters = append(filters, labelFilter{Key: key, Value: value, Any: !hasValue})
+	}
+	return filters, nil
+}
+
+// matchesLabels reports whether labels satisfy every filter
+func matchesLabels(labels map[string]string, filters []labelFilter) bool {
+	for _, f := range filters {
+		v, ok := labels[f.Key]
+		if !ok || (!f.Any && v != f.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// goroutineRef is the goroutine a stop reports: its ID and pprof labels,
+// which tell which request or tenant hit the breakpoint
+func goroutineRef(g *api.Goroutine) map[string]any {
+	ref := map[string]any{"id": g.ID}
+	if len(g.Labels) > 0 {
+		ref["labels"] = g.Labels
+	}
+	return ref
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestLabelFilters checks key=value and key-only filters and that all must
+// match.
+func TestLabelFilters(t *testing.T) {
+	labels := map[string]string{"request_id": "abc", "handler": "/users"}
+	tests := []struct {
+		specs []string
+		want  bool
+	}{
+		{nil, true},
+		{[]string{"request_id=abc"}, true},
+		{[]string{"request_id=abd"}, false},
+		{[]string{"handler"}, true},
+		{[]string{"tenant"}, false},
+		{[]string{"request_id=abc", "handler=/users"}, true},
+		{[]string{"request_id=abc", "handler=/orders"}, false},
+		{[]string{"request_id="}, false},
+	}
+	for _, tt := range tests {
+		filters, info := parseLabelFilters(tt.specs)
+		if info != nil {
+			t.Fatalf("parseLabelFilters(%q): %v", tt.specs, info)
+		}
+		if got := matchesLabels(labels, filters); got != tt.want {
+			t.Errorf("matchesLabels(%q) = %v, want %v", tt.specs, got, tt.want)
+		}
+	}
+	if _, info := parseLabelFilters([]string{"=abc"}); info == nil {
+		t.Error("--label =abc accepted, want an error")
+	}
+}
+
+// TestGoroutineRef checks stops report a goroutine's labels with its ID.
+func TestGoroutineRef(t *testing.T) {
+	ref := goroutineRef(&api.Goroutine{ID: 7, Labels: map[string]string{"tenant": "acme"}})
+	if ref["id"] != int64(7) || ref["labels"].(map[string]string)["tenant"] != "acme" {
+		t.Errorf("goroutineRef = %v", ref)
+	}
+	if _, ok := goroutineRef(&api.Goroutine{ID: 1})["labels"]; ok {
+		t.Error("goroutineRef without labels has a labels key")
+	}
+}
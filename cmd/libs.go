@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"debug/buildinfo"
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Kinds of loaded images reported by libs
+const (
+	libKindPlugin  = "plugin"  // a Go plugin opened with plugin.Open
+	libKindLibrary = "library" // any other shared object
+)
+
+// loadedLib is a shared object loaded by the target
+type loadedLib struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Address string `json:"address"`
+	// Module is the main module of a Go plugin
+	Module string `json:"module,omitempty"`
+	// Start, End and Size span the mappings of the file; they are only known
+	// when the target runs on this machine
+	Start     string `json:"start,omitempty"`
+	End       string `json:"end,omitempty"`
+	Size      uint64 `json:"size,omitempty"`
+	LoadError string `json:"loadError,omitempty"`
+}
+
+// newLoadedLib describes img, telling Go plugins apart by their build info
+func newLoadedLib(img api.Image) loadedLib {
+	lib := loadedLib{
+		Path:      img.Path,
+		Kind:      libKindLibrary,
+		Address:   fmt.Sprintf("%#x", img.Address),
+		LoadError: img.LoadError,
+	}
+	if info, err := buildinfo.ReadFile(img.Path); err == nil {
+		lib.Kind = libKindPlugin
+		lib.Module = info.Main.Path
+	}
+	return lib
+}
+
+// addLibRanges sets the address range of each library from the mappings of
+// its file
+func addLibRanges(libs []loadedLib, regions []memRegion) {
+	for i := range libs {
+		var start, end uint64
+		for _, r := range regions {
+			if r.Path != libs[i].Path {
+				continue
+			}
+			var s, e uint64
+			if _, err := fmt.Sscanf(r.Start+" "+r.End, "0x%x 0x%x", &s, &e); err != nil {
+				continue
+			}
+			if start == 0 || s < start {
+				start = s
+			}
+			end = max(end, e)
+		}
+		if start != 0 {
+			libs[i].Start = fmt.Sprintf("%#x", start)
+			libs[i].End = fmt.Sprintf("%#x", end)
+			libs[i].Size = end - start
+		}
+	}
+}
+
+// loadsPlugins reports whether the program can open Go plugins
+func loadsPlugins(c *debugger.Client) bool {
+	functions, err := c.ListFunctions(`^plugin\.Open$`)
+	return err == nil && len(functions) > 0
+}
+
+// addPluginHint points at --suspended in the details of an unresolved
+// breakpoint when the location may be in a plugin that is not loaded yet
+func addPluginHint(c *debugger.Client, details map[string]any) {
+	if loadsPlugins(c) {
+		details["plugins"] = "the program opens Go plugins: libs lists those loaded, break --suspended sets the breakpoint once the plugin providing it is loaded"
+	}
+}
+
+// addLibsCommand adds the libs command
+func addLibsCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var libsPlugins bool
+
+	libsCmd := &cobra.Command{
+		Use:   "libs",
+		Short: "List the shared libraries and Go plugins loaded by the target",
+		Long: `List the shared objects loaded by the debugged process with their load
+address, telling Go plugins (opened with plugin.Open) apart from other
+shared libraries. For a plugin the main module it was built from is shown.
+
+When the target runs on this machine the address range each object is
+mapped at is added from /proc, which tells which library a raw PC or
+pointer falls into.
+
+Functions in a plugin are named like those of the main program, e.g.
+main.Handle for a func in the plugin's main package. To break in a plugin
+that is not loaded yet, use break --suspended.
+
+Example:
+  godebug --addr $ADDR libs
+  godebug --addr $ADDR libs --plugins`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("libs")
+			defer func() { _ = c.Close() }()
+
+			images, err := c.ListDynamicLibraries()
+			if err != nil {
+				output.Error("libs", err).PrintAndExit(getOutputFormat())
+			}
+
+			libs := []loadedLib{}
+			plugins := 0
+			for _, img := range images {
+				lib := newLoadedLib(img)
+				if lib.Kind == libKindPlugin {
+					plugins++
+				} else if libsPlugins {
+					continue
+				}
+				libs = append(libs, lib)
+			}
+			if serverIsLocal(c.Addr()) {
+				if pid, err := c.ProcessPid(); err == nil {
+					if regions, _, err := readProcMaps(pid); err == nil {
+						addLibRanges(libs, regions)
+					}
+				}
+			}
+
+			data := map[string]any{
+				"libraries": libs,
+				"count":     len(libs),
+				"plugins":   plugins,
+			}
+			msg := fmt.Sprintf("%d libraries loaded, %d Go plugins", len(images), plugins)
+			output.Success("libs", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	libsCmd.Flags().BoolVar(&libsPlugins, "plugins", false, "Only list Go plugins")
+	root.AddCommand(libsCmd)
+}
+
+func init() {
+	addLibsCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
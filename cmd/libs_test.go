@@ -0,0 +1,24 @@
+package cmd
+
+import "testing"
+
+// TestAddLibRanges checks that a library spans all the mappings of its file
+// and that libraries without mappings keep no range.
+func TestAddLibRanges(t *testing.T) {
+	maps := `7f0000000000-7f0000020000 r--p 00000000 fe:00 200 /app/plugin.so
+7f0000020000-7f0000080000 r-xp 00020000 fe:00 200 /app/plugin.so
+7f0000100000-7f0000200000 r-xp 00000000 fe:00 300 /usr/lib/libc.so.6
+7f0000090000-7f0000098000 rw-p 00080000 fe:00 200 /app/plugin.so`
+	libs := []loadedLib{{Path: "/app/plugin.so"}, {Path: "/usr/lib/libc.so.6"}, {Path: "/gone.so"}}
+	addLibRanges(libs, parseProcMaps(maps, "/app/server"))
+
+	if l := libs[0]; l.Start != "0x7f0000000000" || l.End != "0x7f0000098000" || l.Size != 0x98000 {
+		t.Errorf("plugin range = %s-%s (%#x)", l.Start, l.End, l.Size)
+	}
+	if l := libs[1]; l.Start != "0x7f0000100000" || l.Size != 0x100000 {
+		t.Errorf("libc range = %s-%s (%#x)", l.Start, l.End, l.Size)
+	}
+	if l := libs[2]; l.Start != "" || l.Size != 0 {
+		t.Errorf("unmapped library has range %s-%s", l.Start, l.End)
+	}
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Kinds of memory regions reported by maps
+const (
+	regionHeap    = "heap"    // the brk heap (C allocations; the Go heap is anonymous)
+	regionStack   = "stack"   // the main thread's stack
+	regionBinary  = "binary"  // the target executable
+	regionLibrary = "library" // shared libraries
+	regionFile    = "file"    // other mmapped files
+	regionAnon    = "anon"    // anonymous memory: Go heap arenas, goroutine and thread stacks
+	regionKernel  = "kernel"  // vdso, vvar, vsyscall
+)
+
+// memRegion is one mapping of the target's address space
+type memRegion struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Size  uint64 `json:"size"`
+	// Rss is the resident size; it is only known when smaps is readable
+	Rss   uint64 `json:"rss,omitempty"`
+	Perms string `json:"perms"`
+	Kind  string `json:"kind"`
+	Path  string `json:"path,omitempty"`
+}
+
+// regionKinds lists the kinds accepted by --kind
+var regionKinds = []string{regionHeap, regionStack, regionBinary, regionLibrary, regionFile, regionAnon, regionKernel}
+
+// regionSummary totals the regions of one kind
+type regionSummary struct {
+	Regions int    `json:"regions"`
+	Size    uint64 `json:"size"`
+	Rss     uint64 `json:"rss,omitempty"`
+}
+
+// regionKind classifies a mapping by its path
+func regionKind(path, exe string) string {
+	switch {
+	case path == "":
+		return regionAnon
+	case path == "[heap]":
+		return regionHeap
+	case strings.HasPrefix(path, "[stack"):
+		return regionStack
+	case path == "[vdso]" || path == "[vvar]" || path == "[vsyscall]" || path == "[vvar_vclock]":
+		return regionKernel
+	case strings.HasPrefix(path, "["):
+		return regionAnon
+	case path == exe:
+		return regionBinary
+	case strings.Contains(filepath.Base(path), ".so"):
+		return regionLibrary
+	default:
+		return regionFile
+	}
+}
+
+// parseProcMaps parses /proc/<pid>/maps or /proc/<pid>/smaps. The smaps
+// variant adds per-region fields, of which Rss is kept.
+func parseProcMaps(content, exe string) []memRegion {
+	var regions []memRegion
+	for _, line := range strings.Split(content, "\n") {
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		if strings.HasSuffix(f[0], ":") {
+			if f[0] == "Rss:" && len(f) >= 2 && len(regions) > 0 {
+				if kb, err := strconv.ParseUint(f[1], 10, 64); err == nil {
+					regions[len(regions)-1].Rss = kb * 1024
+				}
+			}
+			continue
+		}
+		if len(f) < 5 {
+			continue
+		}
+		start, end, ok := strings.Cut(f[0], "-")
+		if !ok {
+			continue
+		}
+		lo, err1 := strconv.ParseUint(start, 16, 64)
+		hi, err2 := strconv.ParseUint(end, 16, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		path := ""
+		if len(f) > 5 {
+			path = strings.Join(f[5:], " ")
+		}
+		regions = append(regions, memRegion{
+			Start: "0x" + start,
+			End:   "0x" + end,
+			Size:  hi - lo,
+			Perms: f[1],
+			Kind:  regionKind(path, exe),
+			Path:  path,
+		})
+	}
+	return regions
+}
+
+// readProcMaps reads the mappings of pid, preferring smaps for resident sizes
+func readProcMaps(pid int) ([]memRegion, string, error) {
+	exe, _ := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/smaps", pid)); err == nil {
+		return parseProcMaps(string(raw), exe), "smaps", nil
+	}
+	raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot read memory maps (requires /proc, i.e. Linux): %w", err)
+	}
+	return parseProcMaps(string(raw), exe), "maps", nil
+}
+
+// summarizeRegions totals regions by kind
+func summarizeRegions(regions []memRegion) (map[string]*regionSummary, regionSummary) {
+	byKind := map[string]*regionSummary{}
+	var total regionSummary
+	for _, r := range regions {
+		s := byKind[r.Kind]
+		if s == nil {
+			s = &regionSummary{}
+			byKind[r.Kind] = s
+		}
+		s.Regions++
+		s.Size += r.Size
+		s.Rss += r.Rss
+		total.Regions++
+		total.Size += r.Size
+		total.Rss += r.Rss
+	}
+	return byKind, total
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// addMapsCommand adds the maps command
+func addMapsCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var mapsKind string
+	var mapsTop int
+
+	mapsCmd := &cobra.Command{
+		Use:   "maps",
+		Short: "Summarize the target's memory mappings",
+		Long: `Report the memory mappings of the debugged process grouped by kind, with
+virtual and resident sizes, and the largest regions.
+
+Kinds: heap (brk heap), stack (main thread stack), binary, library, file
+(other mmapped files), anon (anonymous memory: the Go heap arenas and
+goroutine/OS thread stacks live here) and kernel (vdso, vvar).
+
+A resident size far above the Go heap usually points at memory outside the
+Go allocator: cgo allocations, many OS threads or mmapped files. Region
+addresses also tell which mapping a raw pointer falls into.
+
+Requires /proc (Linux).
+
+Example:
+  godebug --addr $ADDR maps
+  godebug --addr $ADDR maps --kind library
+  godebug --addr $ADDR maps --top 0`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if mapsKind != "" && !slices.Contains(regionKinds, mapsKind) {
+				output.ErrorWithInfo("maps", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid region kind: %s", mapsKind),
+					map[string]any{"kind": mapsKind, "valid": regionKinds},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("maps")
+			defer func() { _ = c.Close() }()
+
+			pid, err := c.ProcessPid()
+			if err != nil {
+				output.Error("maps", err).PrintAndExit(getOutputFormat())
+			}
+
+			regions, source, err := readProcMaps(pid)
+			if err != nil {
+				output.Error("maps", err).PrintAndExit(getOutputFormat())
+			}
+			byKind, total := summarizeRegions(regions)
+
+			listed := []memRegion{}
+			for _, r := range regions {
+				if mapsKind == "" || r.Kind == mapsKind {
+					listed = append(listed, r)
+				}
+			}
+			sort.SliceStable(listed, func(i, j int) bool {
+				if listed[i].Rss != listed[j].Rss {
+					return listed[i].Rss > listed[j].Rss
+				}
+				return listed[i].Size > listed[j].Size
+			})
+			matched := len(listed)
+			if mapsTop > 0 && len(listed) > mapsTop {
+				listed = listed[:mapsTop]
+			}
+
+			data := map[string]any{
+				"pid":     pid,
+				"byKind":  byKind,
+				"total":   total,
+				"regions": listed,
+				"matched": matched,
+				"source":  source,
+			}
+			if mapsKind != "" {
+				data["kind"] = mapsKind
+			}
+
+			msg := fmt.Sprintf("%d regions, %s virtual", total.Regions, formatBytes(total.Size))
+			if source == "smaps" {
+				msg += fmt.Sprintf(", %s resident", formatBytes(total.Rss))
+			}
+			output.Success("maps", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	mapsCmd.Flags().StringVar(&mapsKind, "kind", "", "Only list regions of this kind (heap, stack, binary, library, file, anon, kernel)")
+	mapsCmd.Flags().IntVar(&mapsTop, "top", 20, "List at most N regions, largest first (0 = all)")
+	root.AddCommand(mapsCmd)
+}
+
+func init() {
+	addMapsCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import "testing"
+
+// TestParseProcMaps checks region classification and that smaps Rss lines are
+// attached to the preceding region.
+func TestParseProcMaps(t *testing.T) {
+	smaps := `00400000-00401000 r-xp 00000000 fe:00 100 /app/server
+Size:                  4 kB
+Rss:                   4 kB
+c000000000-c004000000 rw-p 00000000 00:00 0
+Rss:                2048 kB
+01a2b000-01a4c000 rw-p 00000000 00:00 0                                  [heap]
+7f0000000000-7f0000020000 r-xp 00000000 fe:00 200 /usr/lib/libc.so.6
+7f0000100000-7f0000200000 r--p 00000000 fe:00 300 /var/data/my file.db
+7ffd00000000-7ffd00021000 rw-p 00000000 00:00 0                          [stack]
+7ffd000f0000-7ffd000f2000 r-xp 00000000 00:00 0                          [vdso]`
+
+	regions := parseProcMaps(smaps, "/app/server")
+	want := []struct {
+		kind, path string
+		size, rss  uint64
+	}{
+		{regionBinary, "/app/server", 0x1000, 4096},
+		{regionAnon, "", 0x4000000, 2048 * 1024},
+		{regionHeap, "[heap]", 0x21000, 0},
+		{regionLibrary, "/usr/lib/libc.so.6", 0x20000, 0},
+		{regionFile, "/var/data/my file.db", 0x100000, 0},
+		{regionStack, "[stack]", 0x21000, 0},
+		{regionKernel, "[vdso]", 0x2000, 0},
+	}
+	if len(regions) != len(want) {
+		t.Fatalf("got %d regions, want %d", len(regions), len(want))
+	}
+	for i, w := range want {
+		r := regions[i]
+		if r.Kind != w.kind || r.Path != w.path || r.Size != w.size || r.Rss != w.rss {
+			t.Errorf("region %d = %+v, want kind=%s path=%q size=%#x rss=%d", i, r, w.kind, w.path, w.size, w.rss)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"strconv"
+	"time"
 
+	"github.com/go-delve/delve/service/api"
 	"github.com/spf13/cobra"
 
 	"github.com/8gears/godebug-agentic/internal/debugger"
@@ -11,20 +13,111 @@ import (
 )
 
 var (
-	stackDepth int
+	stackDepth     int
+	stackGoroutine int64
+	stackBlame     bool
+	stackFull      bool
+	goroutineDepth int
+
+	goroutinesBlockedOn   string
+	goroutinesLabels      []string
+	goroutinesState       string
+	goroutinesWaitReason  string
+	goroutinesWaitingOver time.Duration
+	goroutinesGroupBy     string
+	goroutinesDepth       int
 )
 
+// targetGoroutine returns the goroutine to inspect: the one requested with
+// --goroutine, or the selected goroutine when requested is 0
+func targetGoroutine(state *api.DebuggerState, requested int64) (int64, bool) {
+	if requested > 0 {
+		return requested, true
+	}
+	if state.SelectedGoroutine == nil {
+		return 0, false
+	}
+	return state.SelectedGoroutine.ID, true
+}
+
+// stackFullLoadConfig loads the variables of stack --full shallower than
+// locals does, as those of every frame are returned at once
+var stackFullLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       128,
+	MaxArrayValues:     16,
+	MaxStructFields:    -1,
+}
+
+// goroutineStackData loads the stack of a goroutine without switching to it
+func goroutineStackData(c *debugger.Client, goroutineID int64, depth int) (map[string]any, error) {
+	cfg := debugger.DefaultLoadConfig()
+	frames, err := c.Stacktrace(goroutineID, depth, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	return stackData(goroutineID, frames, false), nil
+}
+
+// fullStackData loads the stack of a goroutine with each frame's arguments
+// and locals
+func fullStackData(c *debugger.Client, goroutineID int64, depth int) (map[string]any, error) {
+	frames, err := c.StacktraceFull(goroutineID, depth, &stackFullLoadConfig)
+	if err != nil {
+		return nil, err
+	}
+	return stackData(goroutineID, frames, true), nil
+}
+
+// stackData converts a goroutine's frames for JSON output, with their
+// variables when full
+func stackData(goroutineID int64, frames []api.Stackframe, full bool) map[string]any {
+	stackFrames := make([]map[string]any, len(frames))
+	for i, frame := range frames {
+		frameData := map[string]any{
+			"index": i,
+			"file":  frame.File,
+			"line":  frame.Line,
+		}
+		if frame.Function != nil {
+			frameData["function"] = frame.Function.Name()
+		}
+		if full {
+			frameData["arguments"] = variablesToMaps(frame.Arguments)
+			frameData["locals"] = variablesToMaps(frame.Locals)
+			if frame.Err != "" {
+				frameData["error"] = frame.Err
+			}
+		}
+		stackFrames[i] = frameData
+	}
+
+	return map[string]any{
+		"frames":      stackFrames,
+		"count":       len(stackFrames),
+		"goroutineId": goroutineID,
+	}
+}
+
 var stackCmd = &cobra.Command{
 	Use:   "stack",
 	Short: "Show stack trace",
 	Long: `Show the current stack trace.
 
 Options:
-  --depth N   Maximum stack depth (default 50)
+  --depth N       Maximum stack depth (default 50)
+  --goroutine ID  Show another goroutine's stack without switching to it
+  --blame         Annotate each frame with the last commit that changed its line
+  --full          Include each frame's arguments and locals (loaded shallowly;
+                  combine with --depth to bound the response)
 
 Example:
   godebug --addr $ADDR stack
-  godebug --addr $ADDR stack --depth 20`,
+  godebug --addr $ADDR stack --depth 20
+  godebug --addr $ADDR stack --goroutine 7
+  godebug --addr $ADDR stack --blame
+  godebug --addr $ADDR stack --full --depth 5`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("stack")
 		defer func() { _ = c.Close() }()
@@ -34,36 +127,24 @@ Example:
 			output.Error("stack", err).PrintAndExit(GetOutputFormat())
 		}
 
-		if state.SelectedGoroutine == nil {
+		goroutineID, ok := targetGoroutine(state, stackGoroutine)
+		if !ok {
 			output.ErrorWithInfo("stack", output.NotFound("goroutine", "none selected")).PrintAndExit(GetOutputFormat())
 		}
 
-		cfg := debugger.DefaultLoadConfig()
-		frames, err := c.Stacktrace(state.SelectedGoroutine.ID, stackDepth, &cfg)
+		stackLoad := goroutineStackData
+		if stackFull {
+			stackLoad = fullStackData
+		}
+		data, err := stackLoad(c, goroutineID, stackDepth)
 		if err != nil {
 			output.Error("stack", err).PrintAndExit(GetOutputFormat())
 		}
-
-		stackFrames := make([]map[string]any, len(frames))
-		for i, frame := range frames {
-			frameData := map[string]any{
-				"index": i,
-				"file":  frame.File,
-				"line":  frame.Line,
-			}
-			if frame.Function != nil {
-				frameData["function"] = frame.Function.Name()
-			}
-			stackFrames[i] = frameData
-		}
-
-		data := map[string]any{
-			"frames":      stackFrames,
-			"count":       len(stackFrames),
-			"goroutineId": state.SelectedGoroutine.ID,
+		if stackBlame {
+			addFramesBlame(data["frames"].([]map[string]any))
 		}
 
-		output.Success("stack", data, fmt.Sprintf("%d frames", len(stackFrames))).PrintAndExit(GetOutputFormat())
+		output.Success("stack", data, fmt.Sprintf("%d frames", data["count"])).PrintAndExit(GetOutputFormat())
 	},
 }
 
@@ -72,7 +153,9 @@ var frameCmd = &cobra.Command{
 	Short: "Switch to a stack frame",
 	Long: `Switch to a specific stack frame by index.
 
-Frame 0 is the current (innermost) frame.
+Frame 0 is the current (innermost) frame. The selection is remembered:
+locals, args and eval use the frame until the program moves again. Use up
+and down to move relative to it.
 
 Example:
   godebug --addr $ADDR frame 2`,
@@ -94,31 +177,7 @@ Example:
 			output.Error("frame", err).PrintAndExit(GetOutputFormat())
 		}
 
-		if state.SelectedGoroutine == nil {
-			output.ErrorWithInfo("frame", output.NotFound("goroutine", "none selected")).PrintAndExit(GetOutputFormat())
-		}
-
-		cfg := debugger.DefaultLoadConfig()
-		frames, err := c.Stacktrace(state.SelectedGoroutine.ID, frameIdx+1, &cfg)
-		if err != nil {
-			output.Error("frame", err).PrintAndExit(GetOutputFormat())
-		}
-
-		if frameIdx >= len(frames) {
-			output.ErrorWithInfo("frame", output.NotFound("frame", fmt.Sprintf("%d (stack has %d frames)", frameIdx, len(frames)))).PrintAndExit(GetOutputFormat())
-		}
-
-		frame := frames[frameIdx]
-		data := map[string]any{
-			"index": frameIdx,
-			"file":  frame.File,
-			"line":  frame.Line,
-		}
-		if frame.Function != nil {
-			data["function"] = frame.Function.Name()
-		}
-
-		output.Success("frame", data, fmt.Sprintf("Switched to frame %d", frameIdx)).PrintAndExit(GetOutputFormat())
+		switchFrame(c, "frame", state, frameIdx, GetOutputFormat)
 	},
 }
 
@@ -127,13 +186,52 @@ var goroutinesCmd = &cobra.Command{
 	Short: "List all goroutines",
 	Long: `List all goroutines in the debugged process.
 
+Waiting goroutines include their wait reason and, when they are blocked on a
+channel (send, receive or select) or a sync primitive (Mutex, RWMutex,
+WaitGroup, Cond), the address of that object in waitingOn. Use --blocked-on
+with an address or an expression to list only the goroutines stuck on it.
+
+Goroutines carrying pprof labels (pprof.Do, pprof.SetGoroutineLabels) list
+them in labels; --label key=value, or --label key for any value, lists only
+the goroutines with that label, e.g. those serving one request. Repeated
+--label flags must all match.
+
+--state (running, runnable, waiting, syscall), --wait-reason (text within
+the wait reason) and --waiting-more-than isolate stuck goroutines. Wait
+durations are those the runtime recorded: a goroutine is stamped when a GC
+first sees it waiting, so they are lower bounds (in waitingFor).
+
+--group-by stack collapses goroutines with the same state and user-code
+stack (--depth frames read) into groups, largest first, with their count
+and up to 5 IDs: thousands of leaked workers become one line each.
+
 Example:
-  godebug --addr $ADDR goroutines`,
+  godebug --addr $ADDR goroutines
+  godebug --addr $ADDR goroutines --blocked-on results
+  godebug --addr $ADDR goroutines --blocked-on 0xc000012345
+  godebug --addr $ADDR goroutines --label request_id=abc123
+  godebug --addr $ADDR goroutines --state waiting --wait-reason "chan send" --waiting-more-than 5s
+  godebug --addr $ADDR goroutines --group-by stack`,
 	Run: func(cmd *cobra.Command, args []string) {
+		labels, info := parseLabelFilters(goroutinesLabels)
+		if info != nil {
+			output.ErrorWithInfo("goroutines", info).PrintAndExit(GetOutputFormat())
+		}
+		filter, info := parseStateFilter(goroutinesState, goroutinesWaitReason, goroutinesWaitingOver)
+		if info != nil {
+			output.ErrorWithInfo("goroutines", info).PrintAndExit(GetOutputFormat())
+		}
+		if goroutinesGroupBy != "" && goroutinesGroupBy != groupByStack {
+			output.ErrorWithInfo("goroutines", output.InvalidArgumentWithDetails(
+				fmt.Sprintf("invalid --group-by: %s", goroutinesGroupBy),
+				map[string]any{"groupBy": goroutinesGroupBy, "valid": []string{groupByStack}},
+			)).PrintAndExit(GetOutputFormat())
+		}
+
 		c := MustGetClient("goroutines")
 		defer func() { _ = c.Close() }()
 
-		goroutines, _, err := c.ListGoroutines(0, 0)
+		goroutines, _, err := c.ListGoroutinesFiltered(0, 0, filter.delveFilters())
 		if err != nil {
 			output.Error("goroutines", err).PrintAndExit(GetOutputFormat())
 		}
@@ -144,8 +242,35 @@ Example:
 			selectedID = state.SelectedGoroutine.ID
 		}
 
-		gs := make([]map[string]any, len(goroutines))
-		for i, g := range goroutines {
+		var blockedOn string
+		if goroutinesBlockedOn != "" {
+			blockedOn, err = resolveWaitAddr(c, state, goroutinesBlockedOn)
+			if err != nil {
+				output.Error("goroutines", err).PrintAndExit(GetOutputFormat())
+			}
+		}
+		ver := targetGoVersion(c)
+		if filter.WaitingOver > 0 {
+			if filter.now = runtimeClock(c); filter.now == 0 {
+				output.ErrorWithInfo("goroutines", output.NewErrorInfo(output.ErrCodeEvalFailed,
+					"cannot measure wait durations: the runtime's clock readings are not readable")).PrintAndExit(GetOutputFormat())
+			}
+		}
+
+		gs := make([]map[string]any, 0, len(goroutines))
+		var matched []*api.Goroutine
+		for _, g := range goroutines {
+			if !matchesLabels(g.Labels, labels) {
+				continue
+			}
+			if !filter.matches(g, waitReasonName(ver, g.WaitReason)) {
+				continue
+			}
+			// Groups need no per-goroutine details, whose wait lookups are slow
+			if goroutinesGroupBy != "" && blockedOn == "" {
+				matched = append(matched, g)
+				continue
+			}
 			gData := map[string]any{
 				"id":       g.ID,
 				"selected": g.ID == selectedID,
@@ -164,7 +289,18 @@ Example:
 					"function": g.UserCurrentLoc.Function.Name(),
 				}
 			}
-			gs[i] = gData
+			if len(g.Labels) > 0 {
+				gData["labels"] = g.Labels
+			}
+			if waited, ok := filter.waited(g); ok {
+				gData["waitingFor"] = waited.Round(time.Millisecond).String()
+			}
+			objs := annotateWait(c, ver, g, gData)
+			if blockedOn != "" && !waitsOn(objs, blockedOn) {
+				continue
+			}
+			matched = append(matched, g)
+			gs = append(gs, gData)
 		}
 
 		data := map[string]any{
@@ -174,19 +310,53 @@ Example:
 		if selectedID > 0 {
 			data["selectedId"] = selectedID
 		}
+		if blockedOn != "" {
+			data["blockedOn"] = blockedOn
+		}
+		if len(goroutinesLabels) > 0 {
+			data["labels"] = goroutinesLabels
+		}
+		msg := fmt.Sprintf("%d goroutines", len(gs))
+		if goroutinesGroupBy == groupByStack {
+			groups, unreadable := goroutineStackGroups(c, ver, matched, goroutinesDepth)
+			delete(data, "goroutines")
+			data["count"] = len(matched)
+			data["groupBy"] = groupByStack
+			data["groups"] = groups
+			if unreadable > 0 {
+				data["unreadable"] = unreadable
+			}
+			msg = fmt.Sprintf("%d goroutines in %d stack groups", len(matched), len(groups))
+		}
+		if filter.active() {
+			data["total"] = len(goroutines)
+			if filter.State != "" {
+				data["state"] = filter.State
+			}
+			if filter.WaitReason != "" {
+				data["waitReason"] = filter.WaitReason
+			}
+			if filter.WaitingOver > 0 {
+				data["waitingMoreThan"] = filter.WaitingOver.String()
+			}
+		}
 
-		output.Success("goroutines", data, fmt.Sprintf("%d goroutines", len(gs))).PrintAndExit(GetOutputFormat())
+		output.Success("goroutines", data, msg).PrintAndExit(GetOutputFormat())
 	},
 }
 
 var goroutineCmd = &cobra.Command{
-	Use:   "goroutine <id>",
+	Use:   "goroutine <id> [stack]",
 	Short: "Switch to a goroutine",
 	Long: `Switch to a specific goroutine by ID.
 
+With "stack" after the ID, show that goroutine's stack instead of
+switching to it; the selected goroutine is left unchanged.
+
 Example:
-  godebug --addr $ADDR goroutine 5`,
-	Args: cobra.ExactArgs(1),
+  godebug --addr $ADDR goroutine 5
+  godebug --addr $ADDR goroutine 5 stack --depth 20`,
+	Args: cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("goroutine")
 		defer func() { _ = c.Close() }()
@@ -199,6 +369,21 @@ Example:
 			)).PrintAndExit(GetOutputFormat())
 		}
 
+		// "goroutine <id> stack" reads the stack without switching
+		if len(args) == 2 {
+			if args[1] != "stack" {
+				output.ErrorWithInfo("goroutine", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("unknown goroutine subcommand: %s (expected stack)", args[1]),
+					map[string]any{"subcommand": args[1]},
+				)).PrintAndExit(GetOutputFormat())
+			}
+			data, err := goroutineStackData(c, id, goroutineDepth)
+			if err != nil {
+				output.Error("goroutine", err).PrintAndExit(GetOutputFormat())
+			}
+			output.Success("goroutine", data, fmt.Sprintf("Goroutine %d: %d frames", id, data["count"])).PrintAndExit(GetOutputFormat())
+		}
+
 		state, err := c.SwitchGoroutine(id)
 		if err != nil {
 			output.Error("goroutine", err).PrintAndExit(GetOutputFormat())
@@ -217,6 +402,9 @@ Example:
 					"function": g.CurrentLoc.Function.Name(),
 				}
 			}
+			if len(g.Labels) > 0 {
+				data["labels"] = g.Labels
+			}
 		}
 
 		output.Success("goroutine", data, fmt.Sprintf("Switched to goroutine %d", id)).PrintAndExit(GetOutputFormat())
@@ -230,4 +418,15 @@ func init() {
 	rootCmd.AddCommand(goroutineCmd)
 
 	stackCmd.Flags().IntVar(&stackDepth, "depth", 50, "Maximum stack depth")
+	stackCmd.Flags().Int64Var(&stackGoroutine, "goroutine", 0, "Goroutine to inspect without switching (default: selected)")
+	stackCmd.Flags().BoolVar(&stackBlame, "blame", false, "Annotate frames with git blame for their file:line")
+	stackCmd.Flags().BoolVar(&stackFull, "full", false, "Include each frame's arguments and locals")
+	goroutinesCmd.Flags().StringVar(&goroutinesBlockedOn, "blocked-on", "", "Only goroutines blocked on this channel/mutex (address or expression)")
+	goroutinesCmd.Flags().StringArrayVar(&goroutinesLabels, "label", nil, "Only goroutines with this pprof label, key=value or key (repeatable)")
+	goroutinesCmd.Flags().StringVar(&goroutinesState, "state", "", "Only goroutines in this state: running, runnable, waiting or syscall")
+	goroutinesCmd.Flags().StringVar(&goroutinesWaitReason, "wait-reason", "", "Only goroutines whose wait reason contains this text, e.g. \"chan send\"")
+	goroutinesCmd.Flags().DurationVar(&goroutinesWaitingOver, "waiting-more-than", 0, "Only goroutines the runtime has seen waiting for at least this long")
+	goroutinesCmd.Flags().StringVar(&goroutinesGroupBy, "group-by", "", "Collapse goroutines: stack groups those with identical user-code stacks")
+	goroutinesCmd.Flags().IntVar(&goroutinesDepth, "depth", 20, "Frames read per goroutine with --group-by stack")
+	goroutineCmd.Flags().IntVar(&goroutineDepth, "depth", 50, "Maximum stack depth for goroutine <id> stack")
 }
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestStackDataFull(t *testing.T) {
+	frames := []api.Stackframe{{
+		Location:  api.Location{File: "/app/main.go", Line: 12, Function: &api.Function{Name_: "main.handle"}},
+		Arguments: []api.Variable{{Name: "req", Type: "*main.Request", Value: "0xc000010000"}},
+		Locals:    []api.Variable{{Name: "n", Type: "int", Value: "3"}},
+	}, {
+		Location: api.Location{File: "/app/main.go", Line: 30, Function: &api.Function{Name_: "main.main"}},
+		Err:      "could not read locals",
+	}}
+
+	frame := stackData(1, frames, false)["frames"].([]map[string]any)[0]
+	if _, ok := frame["locals"]; ok {
+		t.Error("locals without full")
+	}
+
+	full := stackData(1, frames, true)["frames"].([]map[string]any)
+	if args := full[0]["arguments"].([]map[string]any); len(args) != 1 || args[0]["name"] != "req" {
+		t.Errorf("arguments = %v", full[0]["arguments"])
+	}
+	if locals := full[0]["locals"].([]map[string]any); len(locals) != 1 || locals[0]["value"] != "3" {
+		t.Errorf("locals = %v", full[0]["locals"])
+	}
+	if full[1]["error"] != "could not read locals" {
+		t.Errorf("error = %v", full[1]["error"])
+	}
+}
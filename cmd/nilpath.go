@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"reflect"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// nilProbeConfig loads just enough of a value to tell whether it is nil
+var nilProbeConfig = api.LoadConfig{FollowPointers: true, MaxStringLen: 1}
+
+// pathSegments splits a path expression such as u.Profile.Addrs[0].City into
+// its prefixes, shortest first: u, u.Profile, u.Profile.Addrs, ... It returns
+// nil for expressions that are not a path from a name.
+func pathSegments(expr string) []string {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil
+	}
+	var chain []ast.Expr
+	for {
+		chain = append(chain, e)
+		switch x := e.(type) {
+		case *ast.Ident:
+			segments := make([]string, len(chain))
+			for i, node := range chain {
+				segments[len(chain)-1-i] = types.ExprString(node)
+			}
+			return segments
+		case *ast.SelectorExpr:
+			e = x.X
+		case *ast.IndexExpr:
+			e = x.X
+		case *ast.StarExpr:
+			e = x.X
+		case *ast.ParenExpr:
+			e = x.X
+		default:
+			return nil
+		}
+	}
+}
+
+// isNilRef reports whether v is a nil pointer or interface, or an interface
+// holding a nil pointer, i.e. whether dereferencing it fails
+func isNilRef(v *api.Variable) bool {
+	switch v.Kind {
+	case reflect.Ptr:
+		return len(v.Children) == 0 || v.Children[0].Addr == 0
+	case reflect.Interface:
+		return len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid || isNilRef(&v.Children[0])
+	}
+	return false
+}
+
+// nilPathError walks the path expr segment by segment and, if one of them is
+// a nil pointer or interface that a later segment dereferences, returns an
+// error naming it. It returns nil when expr is not a path or nothing on it is
+// nil.
+func nilPathError(c *debugger.Client, scope api.EvalScope, expr string) *output.ErrorInfo {
+	segments := pathSegments(expr)
+	walked := make([]map[string]any, 0, len(segments))
+	for i, segment := range segments[:max(len(segments)-1, 0)] {
+		v, err := c.EvalInScope(scope, segment, nilProbeConfig)
+		if err != nil {
+			return nil
+		}
+		walked = append(walked, map[string]any{"path": segment, "type": v.Type})
+		if isNilRef(v) {
+			return output.NewErrorInfo(output.ErrCodeEvalFailed,
+				fmt.Sprintf("nil pointer dereference in '%s': %s is nil (%s)", expr, segment, v.Type),
+			).WithDetails(map[string]any{
+				"expression":  expr,
+				"nilSegment":  segment,
+				"type":        v.Type,
+				"dereference": segments[i+1],
+				"segments":    walked,
+			})
+		}
+	}
+	return nil
+}
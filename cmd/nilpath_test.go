@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestPathSegments checks the prefixes of path expressions
+func TestPathSegments(t *testing.T) {
+	got := pathSegments("u.Profile.Addrs[i].City")
+	want := []string{"u", "u.Profile", "u.Profile.Addrs", "u.Profile.Addrs[i]", "u.Profile.Addrs[i].City"}
+	if !slices.Equal(got, want) {
+		t.Errorf("path: %v", got)
+	}
+	if got := pathSegments("(*p).x"); !slices.Equal(got, []string{"p", "*p", "(*p)", "(*p).x"}) {
+		t.Errorf("dereference: %v", got)
+	}
+	for _, expr := range []string{"a + b", "f().x", "len(items)", "x ="} {
+		if got := pathSegments(expr); got != nil {
+			t.Errorf("pathSegments(%q) = %v, want nil", expr, got)
+		}
+	}
+}
+
+// TestIsNilRef checks nil pointers, nil interfaces and interfaces holding a
+// nil pointer
+func TestIsNilRef(t *testing.T) {
+	nilPtr := api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct}}}
+	ptr := api.Variable{Kind: reflect.Ptr, Children: []api.Variable{{Kind: reflect.Struct, Addr: 0xc000010000}}}
+	tests := []struct {
+		name string
+		v    api.Variable
+		want bool
+	}{
+		{"nil pointer", nilPtr, true},
+		{"pointer", ptr, false},
+		{"nil interface", api.Variable{Kind: reflect.Interface, Children: []api.Variable{{}}}, true},
+		{"interface holding nil pointer", api.Variable{Kind: reflect.Interface, Children: []api.Variable{nilPtr}}, true},
+		{"interface holding pointer", api.Variable{Kind: reflect.Interface, Children: []api.Variable{ptr}}, false},
+		{"nil map", api.Variable{Kind: reflect.Map}, false},
+	}
+	for _, tt := range tests {
+		if got := isNilRef(&tt.v); got != tt.want {
+			t.Errorf("%s: isNilRef = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
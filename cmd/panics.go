@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// panicBreakpointNames are the crash breakpoints panics toggles
+var panicBreakpointNames = []string{unrecoveredPanicBreakpoint, fatalThrowBreakpoint}
+
+// panicBreakpointFunctions are the runtime functions Delve sets each crash
+// breakpoint on at launch; runtime.winthrow only exists on Windows
+var panicBreakpointFunctions = map[string][]string{
+	unrecoveredPanicBreakpoint: {"runtime.fatalpanic"},
+	fatalThrowBreakpoint:       {"runtime.throw", "runtime.fatal", "runtime.winthrow", "runtime.fatalsignal"},
+}
+
+// findPanicBreakpoints returns the session's crash breakpoints by name
+func findPanicBreakpoints(c *debugger.Client) (map[string]*api.Breakpoint, error) {
+	bps, err := c.ListBreakpoints()
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]*api.Breakpoint{}
+	for _, bp := range bps {
+		for _, name := range panicBreakpointNames {
+			if bp.Name == name {
+				found[name] = bp
+			}
+		}
+	}
+	return found, nil
+}
+
+// createPanicBreakpoint sets the crash breakpoint name again after it was
+// cleared, on those of its runtime functions the binary has
+func createPanicBreakpoint(c *debugger.Client, name string) (*api.Breakpoint, error) {
+	var addrs []uint64
+	for _, fn := range panicBreakpointFunctions[name] {
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, fn)
+		if err != nil {
+			continue
+		}
+		for _, loc := range locs {
+			if len(loc.PCs) > 0 {
+				addrs = append(addrs, loc.PCs...)
+			} else if loc.PC != 0 {
+				addrs = append(addrs, loc.PC)
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, output.NotFound("runtime function", strings.Join(panicBreakpointFunctions[name], ", "))
+	}
+	return c.CreateBreakpoint(&api.Breakpoint{Name: name, Addrs: addrs})
+}
+
+// setPanicBreakpoints enables or disables the crash breakpoints Delve sets
+// at launch, creating those that were cleared when enabling. Disabling keeps
+// them (and their IDs) for panics on.
+func setPanicBreakpoints(c *debugger.Client, on bool) (map[string]any, error) {
+	existing, err := findPanicBreakpoints(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var results itemResults
+	for _, name := range panicBreakpointNames {
+		item := map[string]any{"name": name, "enabled": on, "changed": true}
+		bp := existing[name]
+		switch {
+		case bp == nil && on:
+			created, err := createPanicBreakpoint(c, name)
+			if err != nil {
+				results.fail(item, err)
+				continue
+			}
+			item["id"] = created.ID
+		case bp == nil:
+			item["changed"] = false
+		case bp.Disabled == on:
+			bp.Disabled = !on
+			if err := c.AmendBreakpoint(bp); err != nil {
+				results.fail(item, err)
+				continue
+			}
+			item["id"] = bp.ID
+		default:
+			item["id"] = bp.ID
+			item["changed"] = false
+		}
+		results.ok(item)
+	}
+
+	data := map[string]any{"panics": on}
+	results.apply(data, "breakpoints")
+	if info := results.allFailed("panic breakpoints", data); info != nil {
+		return nil, info
+	}
+	return data, nil
+}
+
+// panicsMessage summarizes the outcome of setPanicBreakpoints
+func panicsMessage(data map[string]any) string {
+	if data["panics"] == true {
+		return "Stopping on unrecovered panics and fatal runtime errors" + failedSuffix(data)
+	}
+	return "Not stopping on unrecovered panics and fatal runtime errors" + failedSuffix(data)
+}
+
+// addPanicsCommand adds the panics command
+func addPanicsCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	panicsCmd := &cobra.Command{
+		Use:   "panics [on|off]",
+		Short: "Stop on unrecovered panics and fatal runtime errors, or not",
+		Long: `Delve sets two breakpoints at launch: unrecovered-panic (ID -1) in
+runtime.fatalpanic and runtime-fatal-throw (ID -2) in runtime.throw and the
+runtime's other fatal error paths. With them, continue stops at the panic
+site with the whole stack available, before deferred functions have run, and
+reports data.crash.
+
+panics on enables them, setting them again if they were cleared (clear -1);
+panics off disables them so the program panics and exits as it would outside
+the debugger. Without an argument, panics reports whether they are set.
+break --on-panic is panics on.
+
+Example:
+  godebug --addr $ADDR panics
+  godebug --addr $ADDR panics off
+  godebug --addr $ADDR panics on`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"on", "off"},
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 1 && args[0] != "on" && args[0] != "off" {
+				output.ErrorWithInfo("panics", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid argument: %s (expected on or off)", args[0]),
+					map[string]any{"arg": args[0]},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("panics")
+			defer func() { _ = c.Close() }()
+
+			if len(args) == 1 {
+				data, err := setPanicBreakpoints(c, args[0] == "on")
+				if err != nil {
+					output.Error("panics", err).PrintAndExit(getOutputFormat())
+				}
+				output.Success("panics", data, panicsMessage(data)).PrintAndExit(getOutputFormat())
+			}
+
+			existing, err := findPanicBreakpoints(c)
+			if err != nil {
+				output.Error("panics", err).PrintAndExit(getOutputFormat())
+			}
+			on := true
+			breakpoints := make([]map[string]any, 0, len(panicBreakpointNames))
+			for _, name := range panicBreakpointNames {
+				item := map[string]any{"name": name, "enabled": false}
+				if bp := existing[name]; bp != nil {
+					item["id"] = bp.ID
+					item["enabled"] = !bp.Disabled
+					item["hitCount"] = bp.TotalHitCount
+				}
+				on = on && item["enabled"] == true
+				breakpoints = append(breakpoints, item)
+			}
+			data := map[string]any{"panics": on, "breakpoints": breakpoints}
+			output.Success("panics", data, panicsMessage(data)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	root.AddCommand(panicsCmd)
+}
+
+func init() {
+	addPanicsCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Statuses of the items in data.results
+const (
+	itemOK     = "ok"
+	itemFailed = "failed"
+)
+
+// itemResults collects the outcome of each item of a command that operates
+// on several, such as the breakpoints break --assign sets. A failing item
+// does not fail the command: the others are still done, and the response
+// reports the status of every item.
+type itemResults struct {
+	results   []map[string]any
+	succeeded []map[string]any
+	failed    []map[string]any
+}
+
+// ok records an item that succeeded
+func (r *itemResults) ok(item map[string]any) {
+	r.succeeded = append(r.succeeded, item)
+	r.results = append(r.results, withStatus(item, itemOK, nil))
+}
+
+// fail records an item that failed with err
+func (r *itemResults) fail(item map[string]any, err error) {
+	failed := withStatus(item, itemFailed, err)
+	r.failed = append(r.failed, failed)
+	r.results = append(r.results, failed)
+}
+
+// withStatus returns a copy of item with its status, and the error code and
+// message of a failure
+func withStatus(item map[string]any, status string, err error) map[string]any {
+	out := make(map[string]any, len(item)+3)
+	for k, v := range item {
+		out[k] = v
+	}
+	out["status"] = status
+	if err != nil {
+		info := output.FromError(err)
+		out["errorCode"] = info.Code
+		out["error"] = info.Message
+	}
+	return out
+}
+
+// apply adds the outcome to data: the items that succeeded under key, every
+// item with its status under results, those that failed under failed, and
+// partial when some but not all failed
+func (r *itemResults) apply(data map[string]any, key string) {
+	data[key] = nonNil(r.succeeded)
+	data["results"] = nonNil(r.results)
+	if len(r.failed) > 0 {
+		data["failed"] = r.failed
+	}
+	if len(r.failed) > 0 && len(r.succeeded) > 0 {
+		data["partial"] = true
+	}
+}
+
+// nonNil returns items, or an empty slice so it is encoded as []
+func nonNil(items []map[string]any) []map[string]any {
+	if items == nil {
+		return []map[string]any{}
+	}
+	return items
+}
+
+// allFailed returns the error for a command none of whose items succeeded,
+// with the code of the first failure and data as details; nil if any
+// succeeded or there were none
+func (r *itemResults) allFailed(what string, data map[string]any) *output.ErrorInfo {
+	if len(r.succeeded) > 0 || len(r.failed) == 0 {
+		return nil
+	}
+	first := r.failed[0]
+	msg := fmt.Sprintf("%d of %d %s failed: %v", len(r.failed), len(r.failed), what, first["error"])
+	return output.NewErrorInfo(first["errorCode"].(string), msg).WithDetails(data)
+}
+
+// failedSuffix returns the message suffix for the items in data that failed
+func failedSuffix(data map[string]any) string {
+	if failed, ok := data["failed"].([]map[string]any); ok {
+		return fmt.Sprintf(" (%d failed)", len(failed))
+	}
+	return ""
+}
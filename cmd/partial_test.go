@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+func TestItemResults(t *testing.T) {
+	var r itemResults
+	r.ok(map[string]any{"id": 1, "line": 10})
+	r.fail(map[string]any{"line": 20}, errors.New("could not find statement at main.go:20: not found"))
+	r.ok(map[string]any{"id": 2, "line": 30})
+
+	data := map[string]any{}
+	r.apply(data, "breakpoints")
+	if data["partial"] != true {
+		t.Errorf("partial = %v, want true", data["partial"])
+	}
+	if got := len(data["breakpoints"].([]map[string]any)); got != 2 {
+		t.Errorf("breakpoints = %d, want 2", got)
+	}
+	results := data["results"].([]map[string]any)
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3", len(results))
+	}
+	for i, want := range []string{itemOK, itemFailed, itemOK} {
+		if results[i]["status"] != want {
+			t.Errorf("results[%d].status = %v, want %s", i, results[i]["status"], want)
+		}
+	}
+	if results[1]["errorCode"] != output.ErrCodeNotFound {
+		t.Errorf("errorCode = %v, want %s", results[1]["errorCode"], output.ErrCodeNotFound)
+	}
+	if failedSuffix(data) != " (1 failed)" {
+		t.Errorf("failedSuffix = %q", failedSuffix(data))
+	}
+	if info := r.allFailed("breakpoints", data); info != nil {
+		t.Errorf("allFailed = %v, want nil", info)
+	}
+}
+
+func TestItemResultsAllFailed(t *testing.T) {
+	var r itemResults
+	r.fail(map[string]any{"op": "send"}, output.InvalidArgument("bad"))
+	r.fail(map[string]any{"op": "recv"}, errors.New("boom"))
+
+	data := map[string]any{}
+	r.apply(data, "breakpoints")
+	if _, ok := data["partial"]; ok {
+		t.Error("partial set when every item failed")
+	}
+	info := r.allFailed("breakpoints", data)
+	if info == nil {
+		t.Fatal("allFailed = nil")
+	}
+	if info.Code != output.ErrCodeInvalidArgument {
+		t.Errorf("code = %s, want %s", info.Code, output.ErrCodeInvalidArgument)
+	}
+
+	var none itemResults
+	none.apply(data, "breakpoints")
+	if none.allFailed("breakpoints", data) != nil {
+		t.Error("allFailed with no items")
+	}
+}
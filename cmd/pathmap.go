@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// pathMapFile holds the session's mapping between the binary's compile paths
+// and the local checkout
+const pathMapFile = "pathmap.json"
+
+// Ways resolveSourceFile turned a file argument into a compile path
+const (
+	resolvedByPathMap = "path map"      // mapped from the local checkout
+	resolvedBySuffix  = "unique suffix" // the only source ending in the relative path
+)
+
+// checkoutRoot returns the module root containing the working directory, or
+// the working directory itself outside a module
+func checkoutRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	if root, _ := session.FindModule(dir); root != "" {
+		return root
+	}
+	return filepath.Clean(dir)
+}
+
+// buildSessionPathMap maps the binary's sources onto the checkout containing
+// the working directory and stores the result with the session
+func buildSessionPathMap(addr string, sources []string) *session.PathMap {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	m := session.BuildPathMap(sources, dir)
+	_ = session.SaveData(addr, pathMapFile, m)
+	return m
+}
+
+// sessionPathMap returns the session's path map, building it when there is
+// none yet or godebug now runs from another checkout. The project's
+// substitute paths come first.
+func sessionPathMap(c *debugger.Client) *session.PathMap {
+	var m session.PathMap
+	if err := session.LoadData(c.Addr(), pathMapFile, &m); err != nil || m.Root == "" || m.Root != checkoutRoot() {
+		sources, err := c.ListSources("")
+		if err != nil {
+			return nil
+		}
+		m = *buildSessionPathMap(c.Addr(), sources)
+	}
+	if rules := projectPathRules(); len(rules) > 0 {
+		m.Rules = append(rules, m.Rules...)
+	}
+	return &m
+}
+
+// resolveSourceFile turns a file argument into the path the binary was
+// compiled with. Relative paths are taken from the working directory, local
+// paths are translated through the session's path map, and a relative path
+// that still matches no source is looked up by suffix. It returns the path
+// and how it was resolved, empty when the file is used as given.
+func resolveSourceFile(c *debugger.Client, file string) (string, string) {
+	abs := file
+	if !filepath.IsAbs(file) {
+		if a, err := filepath.Abs(file); err == nil {
+			abs = a
+		}
+	}
+	sources, err := c.ListSources("")
+	if err != nil || slices.Contains(sources, abs) {
+		return abs, ""
+	}
+	if mapped := sessionPathMap(c).ToBinary(abs); mapped != abs && slices.Contains(sources, mapped) {
+		return mapped, resolvedByPathMap
+	}
+	if !filepath.IsAbs(file) {
+		suffix := "/" + filepath.ToSlash(filepath.Clean(file))
+		var matches []string
+		for _, src := range sources {
+			if strings.HasSuffix(filepath.ToSlash(src), suffix) {
+				matches = append(matches, src)
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0], resolvedBySuffix
+		}
+	}
+	return abs, ""
+}
+
+// localSourceFile returns the local file to read for a compile path: the path
+// itself if it exists, else its counterpart in the local checkout
+func localSourceFile(c *debugger.Client, file string) string {
+	if _, err := os.Stat(file); err == nil {
+		return file
+	}
+	if local := sessionPathMap(c).ToLocal(file); local != file {
+		if _, err := os.Stat(local); err == nil {
+			return local
+		}
+	}
+	return file
+}
@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// byteArrayType matches the resolved type of a byte array
+var byteArrayType = regexp.MustCompile(`^\[\d+\](uint8|byte)$`)
+
+// byteContents returns where the bytes of a string, byte slice or byte array
+// live in the target and how many there are
+func byteContents(v *api.Variable) (uint64, int64, error) {
+	switch {
+	case v.Kind == reflect.String:
+		return v.Base, v.Len, nil
+	case v.Kind == reflect.Slice && (v.RealType == "[]uint8" || v.RealType == "[]byte"):
+		return v.Base, v.Len, nil
+	case v.Kind == reflect.Array && byteArrayType.MatchString(v.RealType):
+		return v.Addr, v.Len, nil
+	}
+	return 0, 0, fmt.Errorf("%s is not a string, byte slice or byte array", v.Type)
+}
+
+// typeSizes are the sizes in bytes of types whose size follows from their
+// name on 64-bit targets
+var typeSizes = map[string]int64{
+	"bool": 1, "int8": 1, "uint8": 1, "byte": 1,
+	"int16": 2, "uint16": 2,
+	"int32": 4, "uint32": 4, "float32": 4, "rune": 4,
+	"int": 8, "uint": 8, "int64": 8, "uint64": 8, "float64": 8, "uintptr": 8, "complex64": 8,
+	"complex128": 16, "string": 16, "error": 16, "any": 16, "interface {}": 16,
+}
+
+// typeSize returns the size of a type from its name, or 0 when it does not
+// follow from the name (structs, named types)
+func typeSize(t string) int64 {
+	if n, ok := typeSizes[t]; ok {
+		return n
+	}
+	switch {
+	case strings.HasPrefix(t, "*"), strings.HasPrefix(t, "map["), strings.HasPrefix(t, "chan "),
+		strings.HasPrefix(t, "<-chan "), strings.HasPrefix(t, "func("), strings.HasPrefix(t, "unsafe.Pointer"):
+		return 8
+	case strings.HasPrefix(t, "[]"):
+		return 24
+	case strings.HasPrefix(t, "interface {"):
+		return 16
+	}
+	return 0
+}
+
+// splitMapType splits map[K]V into K and V, matching brackets in K
+func splitMapType(t string) (string, string, bool) {
+	if !strings.HasPrefix(t, "map[") {
+		return "", "", false
+	}
+	depth := 0
+	for i := len("map"); i < len(t); i++ {
+		switch t[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return t[len("map["):i], t[i+1:], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// elemType returns the element type of a slice, array or channel type
+func elemType(t string) string {
+	for _, prefix := range []string{"chan<- ", "<-chan ", "chan "} {
+		if strings.HasPrefix(t, prefix) {
+			return t[len(prefix):]
+		}
+	}
+	if strings.HasPrefix(t, "[") {
+		if i := strings.IndexByte(t, ']'); i >= 0 {
+			return t[i+1:]
+		}
+	}
+	return ""
+}
+
+// valuePreview renders a loaded value compactly for a stats sample
+func valuePreview(v api.Variable) string {
+	if v.Value != "" {
+		return v.Value
+	}
+	if v.Kind == reflect.Slice || v.Kind == reflect.Array || v.Kind == reflect.Map {
+		return fmt.Sprintf("%s len %d", v.Type, v.Len)
+	}
+	return v.Type
+}
+
+// collectionStats summarizes a string, slice, array, map or channel from a
+// load that kept at most sample elements. The footprint is shallow: memory
+// referenced by the elements (string bytes, pointed-to values) is not counted.
+func collectionStats(v *api.Variable, sample int) (map[string]any, error) {
+	stats := map[string]any{
+		"type": v.Type,
+		"kind": v.Kind.String(),
+		"len":  v.Len,
+	}
+	var footprint, elemSize int64
+	method := "type sizes"
+	switch v.Kind {
+	case reflect.String:
+		footprint = v.Len
+	case reflect.Slice, reflect.Array, reflect.Chan:
+		elem := elemType(v.RealType)
+		stats["elemType"] = elem
+		elemSize = typeSize(elem)
+		// Consecutive elements give the exact stride, padding included
+		if len(v.Children) >= 2 && v.Kind != reflect.Chan && v.Children[1].Addr > v.Children[0].Addr {
+			elemSize = int64(v.Children[1].Addr - v.Children[0].Addr)
+			method = "element stride"
+		}
+		count := v.Len
+		if v.Kind != reflect.Array {
+			stats["cap"] = v.Cap
+			count = v.Cap
+		}
+		footprint = count * elemSize
+		previews := []string{}
+		if v.Kind != reflect.Chan {
+			for i := 0; i < len(v.Children) && i < sample; i++ {
+				previews = append(previews, valuePreview(v.Children[i]))
+			}
+			stats["sample"] = previews
+		}
+	case reflect.Map:
+		key, val, _ := splitMapType(v.RealType)
+		stats["keyType"] = key
+		stats["valueType"] = val
+		if ks, vs := typeSize(key), typeSize(val); ks > 0 && vs > 0 {
+			elemSize = ks + vs
+			// Swiss table groups hold 8 slots plus a control byte each and
+			// are kept at most 7/8 full
+			footprint = v.Len * (elemSize + 1) * 8 / 7
+		}
+		keys := []string{}
+		for i := 0; i+1 < len(v.Children) && len(keys) < sample; i += 2 {
+			keys = append(keys, valuePreview(v.Children[i]))
+		}
+		stats["sampleKeys"] = keys
+	default:
+		return nil, fmt.Errorf("%s is not a string, slice, array, map or channel", v.Type)
+	}
+	if elemSize > 0 {
+		stats["elemSize"] = elemSize
+	}
+	if footprint > 0 || v.Len == 0 {
+		stats["footprint"] = map[string]any{
+			"bytes":   footprint,
+			"human":   formatBytes(uint64(footprint)),
+			"method":  method,
+			"shallow": true,
+		}
+	}
+	return stats, nil
+}
+
+// addPrintCommand adds the print command
+func addPrintCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var printOut string
+	var printBase64 bool
+	var printMaxSize int64
+	var printStats bool
+	var printSample int
+	var printStream bool
+
+	printCmd := &cobra.Command{
+		Use:   "print <expression>",
+		Short: "Print a value or export its full contents to a file",
+		Long: `Evaluate an expression like eval. With --out, write the complete contents
+of a string, []byte or [N]byte to a local file instead: responses truncate
+long values, so request bodies, serialized messages and other large
+payloads can only be inspected this way. The memory is read in chunks, so
+the size is only bounded by --max-size.
+
+The response reports the byte count, whether the contents are valid UTF-8
+and their SHA-256, to compare with a known payload without reading it.
+
+With --stats, summarize a string, slice, array, map or channel instead:
+length, capacity, element (or key and value) types, an approximate shallow
+memory footprint and a preview of --sample elements or map keys. Only the
+sample is loaded, so it is cheap on collections with millions of entries;
+use it to decide whether paging through the contents is worthwhile.
+
+With --stream, the value is emitted as NDJSON node records (event, path,
+depth, type, kind, value, len), parents first, instead of one nested
+object, so a consumer can process it or stop reading as it arrives. The
+value is loaded deeper and wider than by default.
+
+Example:
+  godebug --addr $ADDR print "cache.entries" --stats
+  godebug --addr $ADDR print "req.body" --out /tmp/body.json
+  godebug --addr $ADDR print "msg.Payload" --out /tmp/payload.b64 --base64
+  godebug --addr $ADDR print "buf[:n]" --out /tmp/frame.bin
+  godebug --addr $ADDR print "index.byKey" --stream | head -1000`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			expr := args[0]
+			if printBase64 && printOut == "" {
+				output.ErrorWithInfo("print", output.InvalidArgument("--base64 requires --out")).PrintAndExit(getOutputFormat())
+			}
+			if printStats && printOut != "" {
+				output.ErrorWithInfo("print", output.InvalidArgument("--stats and --out cannot be combined")).PrintAndExit(getOutputFormat())
+			}
+			if printStream && (printStats || printOut != "") {
+				output.ErrorWithInfo("print", output.InvalidArgument("--stream cannot be combined with --stats or --out")).PrintAndExit(getOutputFormat())
+			}
+			if printSample < 0 {
+				output.ErrorWithInfo("print", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid sample size: %d (must be >= 0)", printSample),
+					map[string]any{"sample": printSample},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("print")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("print", err).PrintAndExit(getOutputFormat())
+			}
+			goroutineID, ok := targetGoroutine(state, 0)
+			if !ok {
+				output.ErrorWithInfo("print", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+			}
+			frame := mustSelectedFrame(c, "print", state, goroutineID, getOutputFormat)
+
+			if printStats {
+				// Load only the sampled elements, plus two for the element stride
+				cfg := api.LoadConfig{
+					FollowPointers:     true,
+					MaxVariableRecurse: 1,
+					MaxStringLen:       64,
+					MaxArrayValues:     max(printSample, 2),
+					MaxStructFields:    -1,
+				}
+				v, err := c.Eval(goroutineID, frame, expr, cfg)
+				if err != nil {
+					if info := nilPathError(c, api.EvalScope{GoroutineID: goroutineID, Frame: frame}, expr); info != nil {
+						output.ErrorWithInfo("print", info).PrintAndExit(getOutputFormat())
+					}
+					output.Error("print", err).PrintAndExit(getOutputFormat())
+				}
+				if v.Kind == reflect.Ptr && len(v.Children) == 1 {
+					v = &v.Children[0]
+				}
+				data, err := collectionStats(v, printSample)
+				if err != nil {
+					output.ErrorWithInfo("print", output.InvalidArgumentWithDetails(
+						fmt.Sprintf("cannot compute stats of %s: %v", expr, err),
+						map[string]any{"expression": expr, "type": v.Type},
+					)).PrintAndExit(getOutputFormat())
+				}
+				data["expression"] = expr
+				if frame > 0 {
+					data["frame"] = frame
+				}
+				msg := fmt.Sprintf("%s: len %d", v.Type, v.Len)
+				if fp, ok := data["footprint"].(map[string]any); ok {
+					msg += fmt.Sprintf(", ~%s", fp["human"])
+				}
+				output.Success("print", data, msg).PrintAndExit(getOutputFormat())
+			}
+
+			cfg := debugger.DefaultLoadConfig()
+			if printStream {
+				cfg = streamLoadConfig
+			}
+			v, err := c.Eval(goroutineID, frame, expr, cfg)
+			if err != nil {
+				if info := nilPathError(c, api.EvalScope{GoroutineID: goroutineID, Frame: frame}, expr); info != nil {
+					output.ErrorWithInfo("print", info).PrintAndExit(getOutputFormat())
+				}
+				output.Error("print", err).PrintAndExit(getOutputFormat())
+			}
+
+			if printStream {
+				data := map[string]any{
+					"expression": expr,
+					"type":       v.Type,
+					"nodes":      emitVariable(expr, *v),
+					"streamed":   true,
+				}
+				if frame > 0 {
+					data["frame"] = frame
+				}
+				output.Success("print", data, fmt.Sprintf("Streamed %d nodes of %s", data["nodes"], expr)).PrintAndExit(getOutputFormat())
+			}
+
+			if printOut == "" {
+				data := variableToMap(*v)
+				data["expression"] = expr
+				if frame > 0 {
+					data["frame"] = frame
+				}
+				output.Success("print", data, "").PrintAndExit(getOutputFormat())
+			}
+
+			addr, n, err := byteContents(v)
+			if err != nil {
+				output.ErrorWithInfo("print", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("cannot export %s: %v", expr, err),
+					map[string]any{"expression": expr, "type": v.Type},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if n > printMaxSize {
+				output.ErrorWithInfo("print", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("%s is %d bytes, above --max-size %d; raise it or export a slice such as %s[:%d]", expr, n, printMaxSize, expr, printMaxSize),
+					map[string]any{"expression": expr, "bytes": n, "maxSize": printMaxSize},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if n > 0 && addr == 0 {
+				output.ErrorWithInfo("print", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("contents of %s are not addressable", expr),
+					map[string]any{"expression": expr},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			var raw []byte
+			if n > 0 {
+				if raw, err = c.ReadMemory(addr, n); err != nil {
+					output.Error("print", err).PrintAndExit(getOutputFormat())
+				}
+			}
+
+			contents, encoding := raw, "raw"
+			if printBase64 {
+				contents, encoding = []byte(base64.StdEncoding.EncodeToString(raw)), "base64"
+			}
+			if err := os.WriteFile(printOut, contents, 0o644); err != nil {
+				output.Error("print", err).PrintAndExit(getOutputFormat())
+			}
+			file, _ := filepath.Abs(printOut)
+
+			sum := sha256.Sum256(raw)
+			data := map[string]any{
+				"expression": expr,
+				"type":       v.Type,
+				"file":       file,
+				"bytes":      len(raw),
+				"written":    len(contents),
+				"encoding":   encoding,
+				"utf8":       utf8.Valid(raw),
+				"sha256":     hex.EncodeToString(sum[:]),
+			}
+			if frame > 0 {
+				data["frame"] = frame
+			}
+			output.Success("print", data, fmt.Sprintf("Wrote %d bytes of %s to %s", len(raw), expr, file)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	printCmd.Flags().StringVar(&printOut, "out", "", "Write the full contents of a string or byte slice/array to this file")
+	printCmd.Flags().BoolVar(&printBase64, "base64", false, "Base64-encode the contents written with --out")
+	printCmd.Flags().Int64Var(&printMaxSize, "max-size", 64<<20, "Refuse to export more than this many bytes")
+	printCmd.Flags().BoolVar(&printStats, "stats", false, "Summarize a string, slice, array, map or channel without loading its contents")
+	printCmd.Flags().IntVar(&printSample, "sample", 5, "Number of elements (or map keys) previewed by --stats")
+	printCmd.Flags().BoolVar(&printStream, "stream", false, "Emit the value as NDJSON node records, loading deeper")
+	root.AddCommand(printCmd)
+}
+
+func init() {
+	addPrintCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
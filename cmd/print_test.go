@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestByteContents checks which variables print --out can export and where
+// their bytes are read from.
+func TestByteContents(t *testing.T) {
+	cases := []struct {
+		v    api.Variable
+		addr uint64
+		ok   bool
+	}{
+		{api.Variable{Kind: reflect.String, Type: "string", RealType: "string", Base: 0x100, Addr: 0x10, Len: 5}, 0x100, true},
+		{api.Variable{Kind: reflect.Slice, Type: "[]byte", RealType: "[]uint8", Base: 0x200, Addr: 0x20, Len: 3}, 0x200, true},
+		{api.Variable{Kind: reflect.Slice, Type: "json.RawMessage", RealType: "[]uint8", Base: 0x300, Len: 2}, 0x300, true},
+		{api.Variable{Kind: reflect.Array, Type: "[16]uint8", RealType: "[16]uint8", Addr: 0x400, Len: 16}, 0x400, true},
+		{api.Variable{Kind: reflect.Slice, Type: "[]int", RealType: "[]int", Base: 0x500, Len: 4}, 0, false},
+		{api.Variable{Kind: reflect.Struct, Type: "main.User", RealType: "main.User"}, 0, false},
+	}
+	for _, tc := range cases {
+		addr, n, err := byteContents(&tc.v)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v, want ok %v", tc.v.Type, err, tc.ok)
+			continue
+		}
+		if tc.ok && (addr != tc.addr || n != tc.v.Len) {
+			t.Errorf("%s: contents at %#x (%d bytes), want %#x (%d)", tc.v.Type, addr, n, tc.addr, tc.v.Len)
+		}
+	}
+}
+
+// TestCollectionStats checks types, footprints and samples computed from a
+// partial load.
+func TestCollectionStats(t *testing.T) {
+	slice := api.Variable{
+		Kind: reflect.Slice, Type: "[]main.Item", RealType: "[]main.Item", Len: 1000, Cap: 1024,
+		Children: []api.Variable{
+			{Addr: 0x1000, Type: "main.Item"},
+			{Addr: 0x1028, Type: "main.Item"},
+		},
+	}
+	stats, err := collectionStats(&slice, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["elemSize"] != int64(40) || stats["footprint"].(map[string]any)["bytes"] != int64(40*1024) {
+		t.Errorf("slice stats = %v", stats)
+	}
+
+	m := api.Variable{
+		Kind: reflect.Map, Type: "map[string][]int", RealType: "map[string][]int", Len: 7,
+		Children: []api.Variable{
+			{Value: "a"}, {Type: "[]int", Kind: reflect.Slice, Len: 2},
+			{Value: "b"}, {Type: "[]int", Kind: reflect.Slice, Len: 0},
+		},
+	}
+	stats, err = collectionStats(&m, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["keyType"] != "string" || stats["valueType"] != "[]int" || stats["elemSize"] != int64(40) {
+		t.Errorf("map stats = %v", stats)
+	}
+	if keys := stats["sampleKeys"].([]string); len(keys) != 1 || keys[0] != "a" {
+		t.Errorf("sampleKeys = %v", keys)
+	}
+
+	if k, v, ok := splitMapType("map[[2]int]map[string]bool"); !ok || k != "[2]int" || v != "map[string]bool" {
+		t.Errorf("splitMapType = %q, %q, %v", k, v, ok)
+	}
+	if _, err := collectionStats(&api.Variable{Kind: reflect.Struct, Type: "main.User"}, 5); err == nil {
+		t.Error("struct accepted")
+	}
+}
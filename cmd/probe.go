@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// probeLibraryVersion changes whenever a probe's expressions change, so saved
+// results can be told apart
+const probeLibraryVersion = 1
+
+// probeExprPlaceholder is replaced with the probe's argument
+const probeExprPlaceholder = "{expr}"
+
+// probeValue is one value a probe reads. Runtime and stdlib internals change
+// between Go versions, so a value lists alternative expressions, newest
+// layout first; the first one that evaluates is used.
+type probeValue struct {
+	Name  string   `json:"name"`
+	Exprs []string `json:"expressions"`
+}
+
+// probe is a named set of expressions reading the internals of a runtime or
+// stdlib type
+type probe struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Arg         string       `json:"arg,omitempty"`     // what --expr must evaluate to
+	Default     string       `json:"default,omitempty"` // used for --expr when not given
+	Values      []probeValue `json:"values"`
+	// count adds values computed through the API rather than by expressions
+	count func(c *debugger.Client) (map[string]any, error)
+}
+
+// probes is the probe library, keyed by name
+var probes = map[string]probe{
+	"runtime.numgoroutine": {
+		Name:        "runtime.numgoroutine",
+		Description: "Live goroutines (as runtime.NumGoroutine) and goroutine structures ever allocated",
+		Values: []probeValue{
+			{Name: "allocated", Exprs: []string{"len(runtime.allgs)"}},
+		},
+		count: func(c *debugger.Client) (map[string]any, error) {
+			goroutines, _, err := c.ListGoroutines(0, 0)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"live": len(goroutines)}, nil
+		},
+	},
+	"runtime.gomaxprocs": {
+		Name:        "runtime.gomaxprocs",
+		Description: "GOMAXPROCS and the CPUs the runtime detected",
+		Values: []probeValue{
+			{Name: "gomaxprocs", Exprs: []string{"runtime.gomaxprocs"}},
+			{Name: "ncpu", Exprs: []string{"runtime.ncpu"}},
+		},
+	},
+	"runtime.gc": {
+		Name:        "runtime.gc",
+		Description: "Completed GC cycles, GOGC, memory limit and live heap",
+		Values: []probeValue{
+			{Name: "numGC", Exprs: []string{"runtime.memstats.numgc"}},
+			{Name: "gcPercent", Exprs: []string{"runtime.gcController.gcPercent.value", "runtime.gcController.gcPercent"}},
+			{Name: "memoryLimit", Exprs: []string{"runtime.gcController.memoryLimit.value", "runtime.gcController.memoryLimit"}},
+			{Name: "heapLive", Exprs: []string{"runtime.gcController.heapLive.value", "runtime.gcController.heapLive"}},
+		},
+	},
+	"runtime.sched": {
+		Name:        "runtime.sched",
+		Description: "Scheduler: global run queue, idle and spinning Ps and Ms, threads created",
+		Values: []probeValue{
+			{Name: "runqsize", Exprs: []string{"runtime.sched.runq.size", "runtime.sched.runqsize"}},
+			{Name: "idlePs", Exprs: []string{"runtime.sched.npidle.value", "runtime.sched.npidle"}},
+			{Name: "spinningMs", Exprs: []string{"runtime.sched.nmspinning.value", "runtime.sched.nmspinning"}},
+			{Name: "idleMs", Exprs: []string{"runtime.sched.nmidle"}},
+			{Name: "threadsCreated", Exprs: []string{"runtime.sched.mnext"}},
+		},
+	},
+	"http.default_transport_conns": {
+		Name:        "http.default_transport_conns",
+		Description: "Connections of an http.Transport per host: open, idle and the limits",
+		Arg:         "*http.Transport",
+		Default:     `"net/http".DefaultTransport.(*"net/http".Transport)`,
+		Values: []probeValue{
+			{Name: "connsPerHost", Exprs: []string{"{expr}.connsPerHost"}},
+			{Name: "idleConn", Exprs: []string{"{expr}.idleConn"}},
+			{Name: "maxIdleConns", Exprs: []string{"{expr}.MaxIdleConns"}},
+			{Name: "maxIdleConnsPerHost", Exprs: []string{"{expr}.MaxIdleConnsPerHost"}},
+			{Name: "maxConnsPerHost", Exprs: []string{"{expr}.MaxConnsPerHost"}},
+		},
+	},
+	"sql.db_stats": {
+		Name:        "sql.db_stats",
+		Description: "Connection pool of a *sql.DB, as sql.DB.Stats reports it",
+		Arg:         "*sql.DB",
+		Values: []probeValue{
+			{Name: "open", Exprs: []string{"{expr}.numOpen"}},
+			{Name: "maxOpen", Exprs: []string{"{expr}.maxOpen"}},
+			{Name: "idle", Exprs: []string{"len({expr}.freeConn)"}},
+			{Name: "maxIdle", Exprs: []string{"{expr}.maxIdleCount"}},
+			{Name: "waitCount", Exprs: []string{"{expr}.waitCount"}},
+			{Name: "waitDuration", Exprs: []string{"{expr}.waitDuration.v", "{expr}.waitDuration"}},
+			{Name: "pendingRequests", Exprs: []string{"len({expr}.connRequests.s)", "len({expr}.connRequests)"}},
+			{Name: "closed", Exprs: []string{"{expr}.closed"}},
+		},
+	},
+	"sync.mutex": {
+		Name:        "sync.mutex",
+		Description: "State of a sync.Mutex: locked, starving and the number of waiters",
+		Arg:         "sync.Mutex",
+		Values: []probeValue{
+			{Name: "locked", Exprs: []string{"{expr}.mu.state & 1 == 1", "{expr}.state & 1 == 1"}},
+			{Name: "starving", Exprs: []string{"{expr}.mu.state & 4 == 4", "{expr}.state & 4 == 4"}},
+			{Name: "waiters", Exprs: []string{"{expr}.mu.state >> 3", "{expr}.state >> 3"}},
+		},
+	},
+	"sync.waitgroup": {
+		Name:        "sync.waitgroup",
+		Description: "Counter of a sync.WaitGroup and the goroutines waiting in Wait",
+		Arg:         "sync.WaitGroup",
+		Values: []probeValue{
+			{Name: "counter", Exprs: []string{"{expr}.state.v >> 32"}},
+			{Name: "waiters", Exprs: []string{"{expr}.state.v & 0x7fffffff"}},
+		},
+	},
+}
+
+// probeNames returns the names of the probe library in order
+func probeNames() []string {
+	names := make([]string, 0, len(probes))
+	for name := range probes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expand substitutes the probe's argument into an expression
+func (p probe) expand(expr, arg string) string {
+	return strings.ReplaceAll(expr, probeExprPlaceholder, "("+arg+")")
+}
+
+// runProbe evaluates every value of p in scope. A value none of whose
+// expressions evaluates reports the last error instead.
+func runProbe(c *debugger.Client, p probe, scope api.EvalScope, arg string) ([]map[string]any, int) {
+	cfg := debugger.DefaultLoadConfig()
+	values := make([]map[string]any, 0, len(p.Values))
+	ok := 0
+	for _, v := range p.Values {
+		result := map[string]any{"name": v.Name}
+		for _, tmpl := range v.Exprs {
+			expr := p.expand(tmpl, arg)
+			variable, err := c.EvalInScope(scope, expr, cfg)
+			if err != nil {
+				result["error"] = err.Error()
+				continue
+			}
+			delete(result, "error")
+			result["expression"] = expr
+			result["value"] = variable.Value
+			result["type"] = variable.Type
+			if len(variable.Children) > 0 {
+				result["children"] = variableToMap(*variable)["children"]
+			}
+			ok++
+			break
+		}
+		values = append(values, result)
+	}
+	return values, ok
+}
+
+// addProbeCommand adds the probe command and its subcommands
+func addProbeCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var probeExpr string
+
+	probeCmd := &cobra.Command{
+		Use:   "probe",
+		Short: "Read runtime and stdlib internals with named expression templates",
+		Long: `Probes are named sets of eval expressions that read useful internals of the
+runtime and standard library types: the expressions are maintained inside
+godebug, including their variants for different Go versions.
+
+Subcommands:
+  list   Show the probes and their expressions
+  run    Evaluate a probe in the selected goroutine and frame`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the available probes",
+		Long: `List the probe library: each probe's description, the argument it takes with
+--expr, and the expressions of its values.
+
+Example:
+  godebug probe list`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			list := make([]probe, 0, len(probes))
+			for _, name := range probeNames() {
+				list = append(list, probes[name])
+			}
+			data := map[string]any{
+				"probes":  list,
+				"count":   len(list),
+				"version": probeLibraryVersion,
+			}
+			output.Success("probe list", data, fmt.Sprintf("%d probes", len(list))).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	runCmd := &cobra.Command{
+		Use:   "run <probe>",
+		Short: "Evaluate a probe",
+		Long: `Evaluate every value of a probe. Probes of a type (sql.db_stats, sync.mutex)
+take the variable to inspect with --expr, evaluated in the selected goroutine
+and frame; package level probes (runtime.*) need none.
+
+Each value reports the expression that worked for this Go version, or the
+error if none did; the probe fails only if no value could be read.
+
+Example:
+  godebug --addr $ADDR probe run runtime.numgoroutine
+  godebug --addr $ADDR probe run http.default_transport_conns
+  godebug --addr $ADDR probe run sql.db_stats --expr db
+  godebug --addr $ADDR probe run sync.mutex --expr s.mu`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			p, ok := probes[args[0]]
+			if !ok {
+				output.ErrorWithInfo("probe run", output.NotFound("probe", args[0]).WithDetails(map[string]any{"probes": probeNames()})).PrintAndExit(getOutputFormat())
+			}
+			arg := probeExpr
+			if arg == "" {
+				arg = p.Default
+			}
+			if p.Arg != "" && arg == "" {
+				output.ErrorWithInfo("probe run", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("probe %s needs --expr naming a %s", p.Name, p.Arg),
+					map[string]any{"probe": p.Name, "arg": p.Arg},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if p.Arg == "" && probeExpr != "" {
+				output.ErrorWithInfo("probe run", output.InvalidArgument(fmt.Sprintf("probe %s takes no --expr", p.Name))).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("probe run")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("probe run", err).PrintAndExit(getOutputFormat())
+			}
+			// Package variables can be read without a goroutine, locals cannot
+			scope := api.EvalScope{GoroutineID: -1}
+			if state.SelectedGoroutine != nil {
+				scope.GoroutineID = state.SelectedGoroutine.ID
+				scope.Frame = mustSelectedFrame(c, "probe run", state, scope.GoroutineID, getOutputFormat)
+			} else if probeExpr != "" {
+				output.ErrorWithInfo("probe run", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+			}
+
+			values, read := runProbe(c, p, scope, arg)
+			total := len(values)
+			data := map[string]any{
+				"probe":   p.Name,
+				"values":  values,
+				"version": probeLibraryVersion,
+			}
+			if p.Arg != "" {
+				data["expr"] = arg
+			}
+			if p.count != nil {
+				counts, err := p.count(c)
+				if err != nil {
+					output.Error("probe run", err).PrintAndExit(getOutputFormat())
+				}
+				for name, n := range counts {
+					data[name] = n
+				}
+				read += len(counts)
+				total += len(counts)
+			}
+			if read == 0 {
+				output.ErrorWithInfo("probe run", output.NewErrorInfo(output.ErrCodeEvalFailed,
+					fmt.Sprintf("no value of probe %s could be read", p.Name)).WithDetails(data)).PrintAndExit(getOutputFormat())
+			}
+			output.Success("probe run", data, fmt.Sprintf("%s: %d of %d values read", p.Name, read, total)).PrintAndExit(getOutputFormat())
+		},
+	}
+	runCmd.Flags().StringVar(&probeExpr, "expr", "", "Variable the probe inspects, for probes that take one")
+
+	probeCmd.AddCommand(listCmd, runCmd)
+	root.AddCommand(probeCmd)
+}
+
+func init() {
+	addProbeCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
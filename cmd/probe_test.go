@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestProbeLibrary checks that probes taking --expr use it and the others do not.
+func TestProbeLibrary(t *testing.T) {
+	for _, name := range probeNames() {
+		p := probes[name]
+		if p.Name != name {
+			t.Errorf("probe %s is registered as %s", p.Name, name)
+		}
+		for _, v := range p.Values {
+			for _, expr := range v.Exprs {
+				if uses := strings.Contains(expr, probeExprPlaceholder); uses != (p.Arg != "") {
+					t.Errorf("%s.%s: expression %q, arg %q", name, v.Name, expr, p.Arg)
+				}
+			}
+		}
+	}
+}
+
+// TestProbeExpand checks that the argument is parenthesized when substituted.
+func TestProbeExpand(t *testing.T) {
+	p := probes["sql.db_stats"]
+	if got, want := p.expand("len({expr}.freeConn)", "s.db"), "len((s.db).freeConn)"; got != want {
+		t.Errorf("expand = %q, want %q", got, want)
+	}
+}
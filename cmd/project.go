@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/project"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// loadProject returns the project configuration for the working directory,
+// or nil if there is none or it cannot be read
+func loadProject() *project.Config {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	cfg, _ := project.Find(dir)
+	return cfg
+}
+
+// projectError reports a project configuration that cannot be used
+func projectError(file string, err error) *output.ErrorInfo {
+	return output.InvalidArgumentWithDetails(
+		fmt.Sprintf("invalid project configuration: %v", err),
+		map[string]any{"file": file},
+	)
+}
+
+// applyProjectDefaults sets the flags of cmd the project has defaults for and
+// that were not given. The flags are not marked as changed, so commands
+// treat them like built-in defaults.
+func applyProjectDefaults(cmd *cobra.Command) *output.ErrorInfo {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	cfg, err := project.Find(dir)
+	if err != nil {
+		return projectError(filepath.Join(dir, project.DirName, project.ConfigFile), err)
+	}
+	if cfg == nil {
+		return nil
+	}
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	for name, value := range cfg.CommandDefaults(command) {
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			// Flags for all commands only apply where they exist
+			if _, ok := cfg.Defaults[command][name]; !ok {
+				continue
+			}
+			return projectError(cfg.File, fmt.Errorf("%s has no flag --%s", command, name))
+		}
+		if f.Changed {
+			continue
+		}
+		if err := f.Value.Set(value); err != nil {
+			return projectError(cfg.File, fmt.Errorf("defaults for %s: --%s %q: %v", command, name, value, err))
+		}
+	}
+	return nil
+}
+
+// projectPathRules turns the project's substitute paths into path map rules
+func projectPathRules() []session.PathRule {
+	cfg := loadProject()
+	if cfg == nil {
+		return nil
+	}
+	rules := make([]session.PathRule, 0, len(cfg.SubstitutePaths))
+	for _, p := range cfg.SubstitutePaths {
+		rules = append(rules, session.PathRule{
+			Binary: strings.TrimSuffix(filepath.ToSlash(p.From), "/"),
+			Local:  filepath.ToSlash(cfg.Path(p.To)),
+			Source: session.PathSourceProject,
+		})
+	}
+	return rules
+}
+
+// setProjectBreakpoints sets the project's breakpoints in a new session and
+// returns what start reports about them, or nil without a project
+func setProjectBreakpoints(addr string) map[string]any {
+	cfg := loadProject()
+	if cfg == nil {
+		return nil
+	}
+	data := map[string]any{"file": cfg.File}
+	if len(cfg.Breakpoints) == 0 {
+		return data
+	}
+	c, err := debugger.Connect(addr)
+	if err != nil {
+		data["error"] = err.Error()
+		return data
+	}
+	defer func() { _ = c.Close() }()
+
+	var results itemResults
+	for _, pb := range cfg.Breakpoints {
+		created, err := createProjectBreakpoint(c, cfg, pb)
+		if err != nil {
+			results.fail(map[string]any{"location": pb.Location}, err)
+			continue
+		}
+		results.ok(map[string]any{
+			"id":       created.ID,
+			"location": pb.Location,
+			"file":     created.File,
+			"line":     created.Line,
+			"function": created.FunctionName,
+		})
+	}
+	results.apply(data, "breakpoints")
+	return data
+}
+
+// createProjectBreakpoint sets one project breakpoint, resolving its location
+// and condition as break does
+func createProjectBreakpoint(c *debugger.Client, cfg *project.Config, pb project.Breakpoint) (*api.Breakpoint, error) {
+	bp := &api.Breakpoint{}
+	if file, line, ok := strings.Cut(pb.Location, ":"); ok {
+		n, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line number: %s", line)
+		}
+		// Files are relative to the project root; a bare name may also be the
+		// unique suffix of a source
+		var resolvedBy string
+		bp.File, resolvedBy = resolveSourceFile(c, cfg.Path(file))
+		if resolvedBy == "" && !filepath.IsAbs(file) {
+			if p, by := resolveSourceFile(c, file); by == resolvedBySuffix {
+				bp.File = p
+			}
+		}
+		bp.Line = n
+	} else {
+		name, err := resolveFunctionLocation(c, pb.Location)
+		if err != nil {
+			return nil, err
+		}
+		bp.FunctionName = name
+	}
+	cond, info := conditionFlag(pb.Cond)
+	if info != nil {
+		return nil, info
+	}
+	bp.Cond = cond
+	return c.CreateBreakpoint(bp)
+}
+
+// addProjectCommand adds the project command
+func addProjectCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat) {
+	var initForce bool
+
+	projectCmd := &cobra.Command{
+		Use:   "project",
+		Short: "Show the project's shared godebug configuration",
+		Long: `Show the configuration godebug loads from .godebug/config.yaml in the
+nearest directory above the working directory. It is meant to be checked in,
+so a team's debugging setup is reproducible:
+
+  substitutePaths  directories the binary was compiled in (from) and where
+                   those files are locally (to); used before the path rules
+                   godebug detects itself
+  start            the target (and args) start launches when given none
+  breakpoints      locations (and conditions) every start sets
+  defaults         flag values per command path ("start", "probe run", or
+                   "*" for every command) used when the flag is not given
+
+Relative paths are relative to the directory containing .godebug. project
+init writes a commented template.
+
+Example:
+  godebug project
+  godebug project init`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir, err := os.Getwd()
+			if err != nil {
+				output.Error("project", err).PrintAndExit(getOutputFormat())
+			}
+			cfg, err := project.Find(dir)
+			if err != nil {
+				output.ErrorWithInfo("project", projectError(filepath.Join(dir, project.DirName, project.ConfigFile), err)).PrintAndExit(getOutputFormat())
+			}
+			if cfg == nil {
+				output.ErrorWithInfo("project", output.NotFound("project configuration", dir).WithDetails(map[string]any{
+					"hint": "godebug project init writes " + filepath.Join(project.DirName, project.ConfigFile),
+				})).PrintAndExit(getOutputFormat())
+			}
+
+			data := map[string]any{
+				"file":            cfg.File,
+				"root":            cfg.Root,
+				"substitutePaths": cfg.SubstitutePaths,
+				"breakpoints":     cfg.Breakpoints,
+				"defaults":        cfg.Defaults,
+			}
+			if cfg.Start.Target != "" {
+				data["start"] = map[string]any{"target": cfg.StartTarget(), "args": cfg.Start.Args}
+			}
+			output.Success("project", data, fmt.Sprintf("Project configuration %s", cfg.File)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Write a project configuration template",
+		Long: `Write a commented .godebug/config.yaml at the module root (or the working
+directory outside a module). An existing file is kept unless --force.
+
+Example:
+  godebug project init`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			file := filepath.Join(checkoutRoot(), project.DirName, project.ConfigFile)
+			if _, err := os.Stat(file); err == nil && !initForce {
+				output.ErrorWithInfo("project init", output.InvalidArgumentWithDetails(
+					"project configuration already exists; use --force to replace it",
+					map[string]any{"file": file},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+				output.Error("project init", err).PrintAndExit(getOutputFormat())
+			}
+			if err := os.WriteFile(file, []byte(project.Template), 0o644); err != nil {
+				output.Error("project init", err).PrintAndExit(getOutputFormat())
+			}
+			output.Success("project init", map[string]any{"file": file}, fmt.Sprintf("Wrote %s", file)).PrintAndExit(getOutputFormat())
+		},
+	}
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Replace an existing configuration")
+
+	projectCmd.AddCommand(initCmd)
+	root.AddCommand(projectCmd)
+}
+
+func init() {
+	addProjectCommand(rootCmd, GetOutputFormat)
+}
@@ -1,9 +1,26 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
 	"github.com/spf13/cobra"
 
+	"github.com/8gears/godebug-agentic/internal/debugger"
 	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// quitWait bounds waiting for the server and process to go away after quit
+const quitWait = 3 * time.Second
+
+var (
+	quitTerminateServer bool
+	quitDetachOnly      bool
 )
 
 var quitCmd = &cobra.Command{
@@ -11,23 +28,169 @@ var quitCmd = &cobra.Command{
 	Short: "Stop debugging and terminate the debug server",
 	Long: `Stop the debug session and terminate the debugged process.
 
-This cleanly detaches from the process and shuts down the Delve server.
+This cleanly detaches from the process and shuts down the Delve server. A
+process the session attached to (start --mode attach) is left running; use
+detach to leave the process running in any session, and stop (interrupt) to
+pause the program without ending the session. quit and detach mark the
+session as ended, so a later command on the address says so instead of
+only failing to connect.
+
+quit then checks that the server stopped accepting connections and that its
+processes are gone, and reports any still running in data.residualPids.
+--terminate-server also kills a server started by godebug that is still
+running, once its recorded PID is checked to still be a dlv process;
+otherwise the PID is only reported. --detach-only only disconnects: the server (started with
+--accept-multiclient) and the process keep running for other clients.
 
 Example:
-  godebug --addr 127.0.0.1:38697 quit`,
+  godebug --addr 127.0.0.1:38697 quit
+  godebug --addr 127.0.0.1:38697 quit --terminate-server
+  godebug --addr 127.0.0.1:38697 quit --detach-only`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		c := MustGetClient("quit")
-		// Note: don't defer close, we're detaching
+		runQuit(MustGetClient, GetOutputFormat, quitTerminateServer, quitDetachOnly)
+	},
+}
 
-		err := c.Detach(true)
-		if err != nil {
-			output.Error("quit", err).PrintAndExit(GetOutputFormat())
+// addQuitFlags adds the flags choosing how quit ends the session
+func addQuitFlags(quitCmd *cobra.Command, terminateServer, detachOnly *bool) {
+	quitCmd.Flags().BoolVar(terminateServer, "terminate-server", false, "Kill the Delve server if it is still running after detaching")
+	quitCmd.Flags().BoolVar(detachOnly, "detach-only", false, "Only disconnect; leave the server and process running")
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// launchedServerPID returns the PID of the Delve server godebug launched
+// for addr, or 0 when godebug did not launch it or the session has ended
+func launchedServerPID(addr string) int {
+	s, err := session.Load(addr)
+	if err != nil || s.Addr != addr || s.EndedBy != "" {
+		return 0
+	}
+	return s.PID
+}
+
+// isDelveProcess reports whether pid is running dlv, so that a PID reused
+// since the session was recorded is not killed. It is false where the
+// command line of pid cannot be read.
+func isDelveProcess(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return false
+	}
+	argv0, _, _ := strings.Cut(string(cmdline), "\x00")
+	return strings.HasPrefix(filepath.Base(argv0), "dlv")
+}
+
+// waitGone waits until the server stops accepting connections and none of
+// pids is alive, and returns the PIDs still alive when quitWait expires
+func waitGone(addr string, pids []int) (bool, []int) {
+	deadline := time.Now().Add(quitWait)
+	for {
+		accepting := debugger.Accepting(addr)
+		var alive []int
+		for _, pid := range pids {
+			if processAlive(pid) {
+				alive = append(alive, pid)
+			}
+		}
+		if (!accepting && len(alive) == 0) || time.Now().After(deadline) {
+			return accepting, alive
 		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
 
-		output.Success("quit", nil, "Debug session terminated").PrintAndExit(GetOutputFormat())
-	},
+// runQuit ends the session and verifies the outcome: the server is gone, or,
+// with detachOnly, still accepting connections
+func runQuit(mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, terminateServer, detachOnly bool) {
+	if terminateServer && detachOnly {
+		output.ErrorWithInfo("quit", output.InvalidArgument("--terminate-server and --detach-only are mutually exclusive")).PrintAndExit(getOutputFormat())
+	}
+
+	c := mustGetClient("quit")
+	addr := c.Addr()
+
+	if detachOnly {
+		_ = c.Close()
+		data := map[string]any{"detachOnly": true, "serverRunning": debugger.Accepting(addr)}
+		if data["serverRunning"] == false {
+			output.ErrorWithInfo("quit", output.NewErrorInfo(output.ErrCodeConnectionFailed,
+				"the server stopped after disconnecting; it was not started with --accept-multiclient").WithDetails(data)).PrintAndExit(getOutputFormat())
+		}
+		output.Success("quit", data, fmt.Sprintf("Disconnected; server still accepting at %s", addr)).PrintAndExit(getOutputFormat())
+	}
+
+	// The server's PID is known for sessions godebug launched. The debugged
+	// process must go too, unless the session attached to it.
+	serverPID := launchedServerPID(addr)
+	pids := []int{serverPID}
+	attached := attachedPID(addr)
+	if pid, err := c.ProcessPid(); err == nil && attached == 0 {
+		pids = append(pids, pid)
+	}
+
+	// Note: don't close the client, we're detaching
+	data, msg, err := endSession(c)
+	if err != nil {
+		output.Error("quit", err).PrintAndExit(getOutputFormat())
+	}
+	recordSessionEnd(addr, "quit")
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	accepting, residual := waitGone(addr, pids)
+	if (accepting || len(residual) > 0) && terminateServer && processAlive(serverPID) {
+		// Only a process still running dlv is killed; otherwise the PID is
+		// reported for the user to check
+		if isDelveProcess(serverPID) {
+			if p, err := os.FindProcess(serverPID); err == nil {
+				_ = p.Kill()
+			}
+			data["serverKilled"] = true
+			accepting, residual = waitGone(addr, pids)
+		} else {
+			data["serverKilled"] = false
+		}
+	}
+	data["serverRunning"] = accepting
+	if serverPID > 0 {
+		data["serverPid"] = serverPID
+	}
+	if len(residual) > 0 {
+		data["residualPids"] = residual
+	}
+
+	if accepting || len(residual) > 0 {
+		details := map[string]any{"addr": addr, "serverRunning": accepting, "residualPids": residual}
+		if terminateServer {
+			reason := "the debug server or process is still running after --terminate-server"
+			if killed, ok := data["serverKilled"].(bool); ok && !killed {
+				details["serverPid"] = serverPID
+				reason = fmt.Sprintf("the debug server is still running; recorded PID %d is not a dlv process and was not killed", serverPID)
+			}
+			output.ErrorWithInfo("quit", output.NewErrorInfo(output.ErrCodeInternalError, reason).WithDetails(details)).PrintAndExit(getOutputFormat())
+		}
+		msg += "; the server or process is still running (see quit --terminate-server)"
+	}
+	output.Success("quit", data, msg).PrintAndExit(getOutputFormat())
 }
 
 func init() {
 	rootCmd.AddCommand(quitCmd)
+	addQuitFlags(quitCmd, &quitTerminateServer, &quitDetachOnly)
 }
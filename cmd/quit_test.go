@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// TestWaitGone checks that a listening server and a live process are
+// reported, and a closed server is not.
+func TestWaitGone(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	accepting, residual := waitGone(addr, []int{0})
+	if accepting || len(residual) > 0 {
+		t.Errorf("closed server: accepting %v, residual %v", accepting, residual)
+	}
+	if !processAlive(os.Getpid()) {
+		t.Error("processAlive is false for the test process")
+	}
+}
+
+// TestLaunchedServerPID checks that only the PID of a live session godebug
+// launched for the address is returned, and that a PID not running dlv is
+// not taken for the server.
+func TestLaunchedServerPID(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	const addr = "127.0.0.1:38697"
+	if pid := launchedServerPID(addr); pid != 0 {
+		t.Errorf("no session: pid %d", pid)
+	}
+
+	s := session.New(addr)
+	s.PID = os.Getpid()
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if pid := launchedServerPID(addr); pid != os.Getpid() {
+		t.Errorf("launched session: pid %d, want %d", pid, os.Getpid())
+	}
+	if isDelveProcess(os.Getpid()) {
+		t.Error("the test process is taken for dlv")
+	}
+
+	now := time.Now()
+	s.EndedBy, s.EndedAt = "quit", &now
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if pid := launchedServerPID(addr); pid != 0 {
+		t.Errorf("ended session: pid %d", pid)
+	}
+}
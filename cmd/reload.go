@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// Breakpoint statuses reported by reload
+const (
+	bpStatusKept     = "kept"
+	bpStatusMoved    = "moved"
+	bpStatusRemapped = "remapped"
+	bpStatusDropped  = "dropped"
+	bpStatusInvalid  = "invalid"
+)
+
+// userBreakpoints filters out Delve's internal breakpoints (negative IDs)
+func userBreakpoints(bps []*api.Breakpoint) []*api.Breakpoint {
+	var user []*api.Breakpoint
+	for _, bp := range bps {
+		if bp.ID > 0 {
+			user = append(user, bp)
+		}
+	}
+	return user
+}
+
+// remapBreakpointLine finds where a breakpoint's line ended up in the edited file,
+// using the snapshot taken when the file was last stamped
+func remapBreakpointLine(sess *session.Session, bp *api.Breakpoint) (int, bool) {
+	oldContent, err := sess.Snapshot(bp.File)
+	if err != nil {
+		return 0, false
+	}
+	newContent, err := os.ReadFile(bp.File)
+	if err != nil {
+		return 0, false
+	}
+	return session.RemapLine(strings.Split(string(oldContent), "\n"), strings.Split(string(newContent), "\n"), bp.Line)
+}
+
+// relocatedBreakpoint copies a breakpoint's settings to a new line of the same file
+func relocatedBreakpoint(bp *api.Breakpoint, line int) *api.Breakpoint {
+	return &api.Breakpoint{
+		Name:        bp.Name,
+		File:        bp.File,
+		Line:        line,
+		Cond:        bp.Cond,
+		HitCond:     bp.HitCond,
+		HitCondPerG: bp.HitCondPerG,
+		Tracepoint:  bp.Tracepoint,
+		TraceReturn: bp.TraceReturn,
+		Goroutine:   bp.Goroutine,
+		Stacktrace:  bp.Stacktrace,
+		Variables:   bp.Variables,
+		LoadArgs:    bp.LoadArgs,
+		LoadLocals:  bp.LoadLocals,
+		Disabled:    bp.Disabled,
+	}
+}
+
+// addReloadCommand adds the reload command
+func addReloadCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	reloadCmd := &cobra.Command{
+		Use:   "reload",
+		Short: "Rebuild after source edits and re-apply breakpoints",
+		Long: `Rebuild and restart the program after editing its sources.
+
+Compares the source files recorded when the session was started against
+what is on disk, rebuilds and restarts the program, and reports for every
+breakpoint whether it was kept, moved to another line, or became invalid.
+
+Breakpoints in edited files are remapped by locating their original line
+in the new file (anchored on the surrounding lines) and re-created there.
+Breakpoints whose line was deleted or rewritten are dropped. Remapped
+breakpoints get a new ID, reported as newId.
+
+Example:
+  godebug --addr $ADDR reload`,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("reload")
+			defer func() { _ = c.Close() }()
+
+			sess, err := session.Load(c.Addr())
+			if err != nil {
+				output.Error("reload", err).PrintAndExit(getOutputFormat())
+			}
+
+			changed := sess.ChangedSources()
+			changedSet := make(map[string]bool, len(changed))
+			for _, f := range changed {
+				changedSet[f] = true
+			}
+
+			before, err := c.ListBreakpoints()
+			if err != nil {
+				output.Error("reload", err).PrintAndExit(getOutputFormat())
+			}
+
+			state, discarded, err := c.Rebuild()
+			if err != nil {
+				output.Error("reload", err).PrintAndExit(getOutputFormat())
+			}
+			recordStop(c, "reload", state)
+
+			after, err := c.ListBreakpoints()
+			if err != nil {
+				output.Error("reload", err).PrintAndExit(getOutputFormat())
+			}
+
+			afterByID := make(map[int]*api.Breakpoint, len(after))
+			for _, bp := range after {
+				afterByID[bp.ID] = bp
+			}
+			discardedByID := make(map[int]string, len(discarded))
+			for _, d := range discarded {
+				if d.Breakpoint != nil {
+					discardedByID[d.Breakpoint.ID] = d.Reason
+				}
+			}
+
+			var moved, remapped, dropped, invalid int
+			breakpoints := make([]map[string]any, 0, len(before))
+			for _, bp := range userBreakpoints(before) {
+				bpData := map[string]any{
+					"id":          bp.ID,
+					"file":        bp.File,
+					"line":        bp.Line,
+					"function":    bp.FunctionName,
+					"fileChanged": changedSet[bp.File],
+				}
+				cur, present := afterByID[bp.ID]
+
+				// Breakpoints in edited files follow their code rather than their line number
+				if changedSet[bp.File] {
+					newLine, ok := remapBreakpointLine(sess, bp)
+					switch {
+					case !ok:
+						if present {
+							_, _ = c.ClearBreakpoint(bp.ID)
+						}
+						dropped++
+						bpData["status"] = bpStatusDropped
+						bpData["reason"] = "line was removed or rewritten"
+					case present && cur.Line == newLine:
+						if newLine == bp.Line {
+							bpData["status"] = bpStatusKept
+						} else {
+							moved++
+							bpData["status"] = bpStatusMoved
+							bpData["newFile"] = cur.File
+							bpData["newLine"] = cur.Line
+						}
+					default:
+						if present {
+							_, _ = c.ClearBreakpoint(bp.ID)
+						}
+						created, err := c.CreateBreakpoint(relocatedBreakpoint(bp, newLine))
+						if err != nil {
+							dropped++
+							bpData["status"] = bpStatusDropped
+							bpData["reason"] = err.Error()
+						} else {
+							remapped++
+							bpData["status"] = bpStatusRemapped
+							bpData["newId"] = created.ID
+							bpData["newFile"] = created.File
+							bpData["newLine"] = created.Line
+						}
+					}
+					breakpoints = append(breakpoints, bpData)
+					continue
+				}
+
+				switch {
+				case !present:
+					invalid++
+					bpData["status"] = bpStatusInvalid
+					if reason, ok := discardedByID[bp.ID]; ok {
+						bpData["reason"] = reason
+					}
+				case cur.File != bp.File || cur.Line != bp.Line:
+					moved++
+					bpData["status"] = bpStatusMoved
+					bpData["newFile"] = cur.File
+					bpData["newLine"] = cur.Line
+				default:
+					bpData["status"] = bpStatusKept
+				}
+				breakpoints = append(breakpoints, bpData)
+			}
+
+			// Re-stamp sources so the next reload compares against this build
+			if sources, err := c.ListSources(""); err == nil {
+				sess.Sources = map[string]session.FileStamp{}
+				sess.RecordSources(userSources(sources))
+				_ = sess.Save()
+			}
+
+			data := map[string]any{
+				"changedFiles": changed,
+				"breakpoints":  breakpoints,
+				"moved":        moved,
+				"remapped":     remapped,
+				"dropped":      dropped,
+				"invalid":      invalid,
+				"state":        stateToData(state),
+			}
+
+			output.Success("reload", data, fmt.Sprintf("Reloaded: %d files changed, %d breakpoints moved, %d remapped, %d dropped, %d invalid", len(changed), moved, remapped, dropped, invalid)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	root.AddCommand(reloadCmd)
+}
+
+func init() {
+	addReloadCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// launchRecordFile holds the conditions a session's process was launched under
+const launchRecordFile = "launch.json"
+
+// replayEnvVars are the environment variables recorded for every launch:
+// those that change how a Go program or its runtime behaves
+var replayEnvVars = []string{
+	"GODEBUG", "GOMAXPROCS", "GOGC", "GOMEMLIMIT", "GOTRACEBACK", "GORACE",
+	"GOFLAGS", "GOOS", "GOARCH", "GOEXPERIMENT", "CGO_ENABLED",
+	"TZ", "LANG", "LC_ALL",
+}
+
+// launchRecord is what start recorded about a launch so it can be replayed
+type launchRecord struct {
+	Mode    string   `json:"mode"`
+	Backend string   `json:"backend,omitempty"`
+	Target  string   `json:"target"`
+	Args    []string `json:"args,omitempty"`
+	// BuildFlags are the --build-flags the target was compiled with
+	BuildFlags string `json:"buildFlags,omitempty"`
+	Dir        string `json:"dir"`
+	// Env holds the recorded variables that were set; Unset those that were not
+	Env   map[string]string `json:"env,omitempty"`
+	Unset []string          `json:"unset,omitempty"`
+	// Stdin is the session's copy of the program's input, if one was given
+	Stdin       string `json:"stdin,omitempty"`
+	StdinSHA256 string `json:"stdinSha256,omitempty"`
+}
+
+// recordEnv captures the variables named in names from the environment
+func recordEnv(environ, names []string) (map[string]string, []string) {
+	set := map[string]string{}
+	var unset []string
+	for _, name := range names {
+		found := false
+		for _, kv := range environ {
+			if k, v, ok := strings.Cut(kv, "="); ok && k == name {
+				set[name], found = v, true
+			}
+		}
+		if !found {
+			unset = append(unset, name)
+		}
+	}
+	return set, unset
+}
+
+// replayEnv rebuilds an environment: environ with the recorded variables set
+// to their recorded values and the ones recorded as unset removed
+func replayEnv(environ []string, rec *launchRecord) []string {
+	env := make([]string, 0, len(environ)+len(rec.Env))
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		if _, ok := rec.Env[k]; ok || slices.Contains(rec.Unset, k) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	for _, k := range sortedKeys(rec.Env) {
+		env = append(env, k+"="+rec.Env[k])
+	}
+	return env
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// copyStdin copies the program's input into a fresh output directory, so the
+// session owns the exact bytes it was fed, and returns the copy and its hash
+func copyStdin(path string) (string, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", output.NotFound("stdin file", path)
+	}
+	dir, err := session.NewOutputDir()
+	if err != nil {
+		return "", "", err
+	}
+	dst := filepath.Join(dir, "stdin")
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256(data)
+	return dst, hex.EncodeToString(sum[:]), nil
+}
+
+// launchConfig builds the launch of start: from the target and arguments on
+// the command line, or from the record of the session given with
+// --replay-of. It returns the launch's record, nil for attach sessions, which
+// cannot be replayed.
+func launchConfig(cmd *cobra.Command, args []string, mode, onCrash, replayOf, stdin string, recordVars []string, getOutputFormat func() output.OutputFormat) (debugger.LaunchConfig, *launchRecord) {
+	fail := func(info *output.ErrorInfo) {
+		output.ErrorWithInfo("start", info).PrintAndExit(getOutputFormat())
+	}
+
+	if replayOf != "" {
+		if len(args) > 0 || cmd.Flags().Changed("mode") || stdin != "" || len(recordVars) > 0 {
+			fail(output.InvalidArgument("--replay-of takes the target, arguments, mode, stdin and environment from the recorded session"))
+		}
+		var rec launchRecord
+		if err := session.LoadData(replayOf, launchRecordFile, &rec); err != nil {
+			output.Error("start", err).PrintAndExit(getOutputFormat())
+		}
+		if rec.Target == "" {
+			fail(output.NotFound("launch record", replayOf))
+		}
+		config := debugger.LaunchConfig{
+			Mode:       debugger.LaunchMode(rec.Mode),
+			Backend:    rec.Backend,
+			Target:     rec.Target,
+			Args:       rec.Args,
+			BuildFlags: rec.BuildFlags,
+			Dir:        rec.Dir,
+			Env:        replayEnv(os.Environ(), &rec),
+		}
+		if rec.Stdin != "" {
+			copied, sum, err := copyStdin(rec.Stdin)
+			if err != nil {
+				output.Error("start", err).PrintAndExit(getOutputFormat())
+			}
+			if sum != rec.StdinSHA256 {
+				fail(output.NewErrorInfo(output.ErrCodeInternalError, "recorded stdin changed since the session was recorded").WithDetails(map[string]any{"stdin": rec.Stdin}))
+			}
+			config.Redirects = []string{"stdin:" + copied}
+			rec.Stdin = copied
+		}
+		return config, &rec
+	}
+
+	// Without a target (arguments may follow --) the project's is used
+	var target string
+	var programArgs []string
+	switch dash := cmd.ArgsLenAtDash(); {
+	case dash == 0:
+		programArgs = args
+	case len(args) > 0:
+		target = args[0]
+		if dash > 0 {
+			programArgs = args[dash:]
+		}
+	}
+	if target == "" {
+		if p := loadProject(); p != nil && p.Start.Target != "" {
+			target = p.StartTarget()
+			if programArgs == nil {
+				programArgs = p.Start.Args
+			}
+		}
+	}
+	if target == "" {
+		fail(output.InvalidArgument("target is required (or use --replay-of, or set start.target in the project configuration)"))
+	}
+	m := validateStart(mode, target, programArgs, onCrash, getOutputFormat)
+	config := debugger.LaunchConfig{Mode: m, Target: target, Args: programArgs}
+	if m == debugger.ModeAttach {
+		if stdin != "" {
+			fail(output.InvalidArgument("--stdin cannot be combined with attach mode"))
+		}
+		return config, nil
+	}
+
+	dir, _ := os.Getwd()
+	env, unset := recordEnv(os.Environ(), append(slices.Clone(replayEnvVars), recordVars...))
+	rec := &launchRecord{Mode: string(m), Target: target, Args: programArgs, Dir: dir, Env: env, Unset: unset}
+	if stdin != "" {
+		copied, sum, err := copyStdin(stdin)
+		if err != nil {
+			output.Error("start", err).PrintAndExit(getOutputFormat())
+		}
+		config.Redirects = []string{"stdin:" + copied}
+		rec.Stdin, rec.StdinSHA256 = copied, sum
+	}
+	return config, rec
+}
+
+// saveLaunchRecord stores the launch's record with the new session
+func saveLaunchRecord(addr string, rec *launchRecord) {
+	if rec != nil {
+		_ = session.SaveData(addr, launchRecordFile, rec)
+	}
+}
+
+// launchRecordData describes the recorded launch in a start response
+func launchRecordData(rec *launchRecord) map[string]any {
+	data := map[string]any{"dir": rec.Dir}
+	if len(rec.Args) > 0 {
+		data["args"] = rec.Args
+	}
+	if rec.BuildFlags != "" {
+		data["buildFlags"] = rec.BuildFlags
+	}
+	if len(rec.Env) > 0 {
+		data["env"] = rec.Env
+	}
+	if rec.Stdin != "" {
+		data["stdin"] = rec.Stdin
+		data["stdinSha256"] = rec.StdinSHA256
+	}
+	return data
+}
+
+// addReplayFlags adds the recording and replay flags to start
+func addReplayFlags(startCmd *cobra.Command, replayOf, stdin *string, recordVars *[]string) {
+	startCmd.Flags().StringVar(replayOf, "replay-of", "", "Relaunch under the recorded conditions of this session (ID or address)")
+	startCmd.Flags().StringVar(stdin, "stdin", "", "File fed to the program as standard input (recorded for replay)")
+	startCmd.Flags().StringArrayVar(recordVars, "record-env", nil, "Also record this environment variable for replay (repeatable)")
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestReplayEnv checks that a replay restores the recorded variables and
+// drops the ones that were unset when the launch was recorded.
+func TestReplayEnv(t *testing.T) {
+	recorded := []string{"PATH=/bin", "GOMAXPROCS=2", "TZ=UTC"}
+	env, unset := recordEnv(recorded, []string{"GOMAXPROCS", "TZ", "GODEBUG"})
+	if env["GOMAXPROCS"] != "2" || env["TZ"] != "UTC" || !slices.Equal(unset, []string{"GODEBUG"}) {
+		t.Fatalf("recordEnv = %v, %v", env, unset)
+	}
+
+	rec := &launchRecord{Env: env, Unset: unset}
+	now := []string{"PATH=/usr/bin", "GOMAXPROCS=8", "GODEBUG=gctrace=1", "HOME=/root"}
+	got := replayEnv(now, rec)
+	want := []string{"PATH=/usr/bin", "HOME=/root", "GOMAXPROCS=2", "TZ=UTC"}
+	if !slices.Equal(got, want) {
+		t.Errorf("replayEnv = %v, want %v", got, want)
+	}
+}
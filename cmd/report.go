@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// maxReportGoroutines bounds the goroutines listed in a report
+const maxReportGoroutines = 50
+
+// debugReport is the snapshot taken at the first breakpoint hit
+type debugReport struct {
+	Target      string           `json:"target"`
+	Mode        string           `json:"mode"`
+	Breakpoints []map[string]any `json:"breakpoints"`
+	Hit         bool             `json:"hit"`
+	Stop        map[string]any   `json:"stop"`
+	Source      map[string]any   `json:"source,omitempty"`
+	Stack       []map[string]any `json:"stack,omitempty"`
+	Args        []map[string]any `json:"args,omitempty"`
+	Locals      []map[string]any `json:"locals,omitempty"`
+	Goroutines  map[string]any   `json:"goroutines,omitempty"`
+	Errors      []string         `json:"errors,omitempty"`
+	DurationMs  int64            `json:"durationMs"`
+}
+
+// createReportBreakpoint sets a breakpoint at a break-style location
+// (file:line or function), resolving it the way break does
+func createReportBreakpoint(c *debugger.Client, location, cond string) (map[string]any, error) {
+	bp := &api.Breakpoint{Cond: cond}
+	if file, lineStr, ok := strings.Cut(location, ":"); ok {
+		line, err := strconv.Atoi(lineStr)
+		if err != nil {
+			return nil, output.InvalidArgumentWithDetails(
+				fmt.Sprintf("invalid line number: %s", lineStr),
+				map[string]any{"location": location, "line": lineStr},
+			)
+		}
+		bp.File, _ = resolveSourceFile(c, file)
+		bp.Line = line
+	} else {
+		name, err := resolveFunctionLocation(c, location)
+		if err != nil {
+			return nil, err
+		}
+		bp.FunctionName = name
+	}
+
+	created, err := c.CreateBreakpoint(bp)
+	if err != nil {
+		if info := unresolvedFileError(c, bp, err); info != nil {
+			return nil, info
+		}
+		return nil, err
+	}
+	data := map[string]any{
+		"id":       created.ID,
+		"location": location,
+		"file":     created.File,
+		"line":     created.Line,
+		"function": created.FunctionName,
+	}
+	if created.Cond != "" {
+		data["condition"] = created.Cond
+	}
+	if warnings := breakpointWarnings(bp, created); len(warnings) > 0 {
+		data["warnings"] = warnings
+	}
+	return data, nil
+}
+
+// sourceWindow reads the lines of path within context of line
+func sourceWindow(path string, line, context int) ([]map[string]any, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		if n < line-context {
+			continue
+		}
+		if n > line+context {
+			break
+		}
+		lines = append(lines, map[string]any{
+			"lineNumber": n,
+			"content":    scanner.Text(),
+			"current":    n == line,
+		})
+	}
+	return lines, scanner.Err()
+}
+
+// collectReport fills the report from the stop in state. Sections that fail
+// are recorded in Errors so the rest of the snapshot still comes through.
+func collectReport(c *debugger.Client, r *debugReport, state *api.DebuggerState, depth, context int) {
+	fail := func(section string, err error) {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", section, err))
+	}
+	g := state.SelectedGoroutine
+	cfg := debugger.DefaultLoadConfig()
+
+	if loc := g.CurrentLoc; loc.File != "" {
+		local := localSourceFile(c, loc.File)
+		if lines, err := sourceWindow(local, loc.Line, context); err != nil {
+			fail("source", err)
+		} else {
+			r.Source = map[string]any{"file": loc.File, "currentLine": loc.Line, "lines": lines}
+			if local != loc.File {
+				r.Source["localFile"] = local
+			}
+		}
+	}
+
+	if stack, err := goroutineStackData(c, g.ID, depth); err != nil {
+		fail("stack", err)
+	} else {
+		r.Stack = stack["frames"].([]map[string]any)
+	}
+
+	if vars, err := c.ListFunctionArgs(g.ID, 0, cfg); err != nil {
+		fail("args", err)
+	} else {
+		r.Args = make([]map[string]any, len(vars))
+		for i, v := range vars {
+			r.Args[i] = variableToMap(v)
+		}
+	}
+
+	if vars, err := c.ListLocalVars(g.ID, 0, cfg); err != nil {
+		fail("locals", err)
+	} else {
+		r.Locals = make([]map[string]any, len(vars))
+		for i, v := range vars {
+			r.Locals[i] = variableToMap(v)
+		}
+	}
+
+	goroutines, _, err := c.ListGoroutines(0, 0)
+	if err != nil {
+		fail("goroutines", err)
+		return
+	}
+	listed := make([]map[string]any, 0, min(len(goroutines), maxReportGoroutines))
+	for _, gr := range goroutines[:min(len(goroutines), maxReportGoroutines)] {
+		gData := map[string]any{"id": gr.ID}
+		if loc := gr.UserCurrentLoc; loc.File != "" {
+			gData["location"] = fmt.Sprintf("%s:%d", loc.File, loc.Line)
+			if loc.Function != nil {
+				gData["function"] = loc.Function.Name()
+			}
+		}
+		if gr.ID == g.ID {
+			gData["current"] = true
+		}
+		listed = append(listed, gData)
+	}
+	r.Goroutines = map[string]any{"count": len(goroutines), "list": listed}
+	if len(goroutines) > len(listed) {
+		r.Goroutines["truncated"] = true
+	}
+}
+
+// markdown renders the report as a document for notebooks and prompts
+func (r *debugReport) markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Debug report: %s\n\n", r.Target)
+	if !r.Hit {
+		if r.Stop["exited"] == true {
+			fmt.Fprintf(&sb, "The program exited with status %v before reaching a breakpoint.\n", r.Stop["exitStatus"])
+		} else {
+			sb.WriteString("The program did not stop at a breakpoint.\n")
+		}
+	} else if loc, ok := r.Stop["location"].(map[string]any); ok {
+		fmt.Fprintf(&sb, "Stopped at %v:%v in `%v`.\n", loc["file"], loc["line"], loc["function"])
+	}
+
+	sb.WriteString("\n## Breakpoints\n\n")
+	for _, bp := range r.Breakpoints {
+		fmt.Fprintf(&sb, "- #%v `%v` → %v:%v", bp["id"], bp["location"], bp["file"], bp["line"])
+		if cond, ok := bp["condition"]; ok {
+			fmt.Fprintf(&sb, " if `%v`", cond)
+		}
+		sb.WriteString("\n")
+	}
+
+	if lines, ok := r.Source["lines"].([]map[string]any); ok {
+		sb.WriteString("\n## Source\n\n```go\n")
+		for _, l := range lines {
+			marker := "  "
+			if l["current"] == true {
+				marker = "=>"
+			}
+			fmt.Fprintf(&sb, "%s %4v  %v\n", marker, l["lineNumber"], l["content"])
+		}
+		sb.WriteString("```\n")
+	}
+
+	if len(r.Stack) > 0 {
+		sb.WriteString("\n## Stack\n\n")
+		for _, f := range r.Stack {
+			fmt.Fprintf(&sb, "%v. `%v` %v:%v\n", f["index"], f["function"], f["file"], f["line"])
+		}
+	}
+
+	writeVars := func(title string, vars []map[string]any) {
+		if len(vars) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n| Name | Type | Value |\n|---|---|---|\n", title)
+		for _, v := range vars {
+			value := strings.ReplaceAll(fmt.Sprint(v["value"]), "|", `\|`)
+			fmt.Fprintf(&sb, "| %v | `%v` | %s |\n", v["name"], v["type"], value)
+		}
+	}
+	writeVars("Arguments", r.Args)
+	writeVars("Locals", r.Locals)
+
+	if r.Goroutines != nil {
+		fmt.Fprintf(&sb, "\n## Goroutines (%v)\n\n", r.Goroutines["count"])
+		for _, g := range r.Goroutines["list"].([]map[string]any) {
+			fmt.Fprintf(&sb, "- %v", g["id"])
+			if loc, ok := g["location"]; ok {
+				fmt.Fprintf(&sb, " %v `%v`", loc, g["function"])
+			}
+			if g["current"] == true {
+				sb.WriteString(" (current)")
+			}
+			sb.WriteString("\n")
+		}
+		if r.Goroutines["truncated"] == true {
+			sb.WriteString("- …\n")
+		}
+	}
+
+	if len(r.Errors) > 0 {
+		sb.WriteString("\n## Errors\n\n")
+		for _, e := range r.Errors {
+			fmt.Fprintf(&sb, "- %s\n", e)
+		}
+	}
+	return sb.String()
+}
+
+// addReportCommand adds the report command
+func addReportCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var reportTarget, reportMode, reportCond, reportFormat string
+	var reportBreaks []string
+	var reportDepth, reportContext int
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Run to a breakpoint and report the program's state in one call",
+		Long: `Launch the target, set the breakpoints, continue to the first hit, collect
+the source around it, the stack, args, locals and goroutines, then quit, all
+in one call. Use it when a one-shot snapshot answers the question and an
+interactive session is not needed.
+
+Locations take the same forms as break (file:line or function). If the program
+exits without hitting a breakpoint the report says so (hit: false) with the
+exit status. Sections that cannot be collected are listed in errors and the
+rest is still reported.
+
+Formats:
+  json (default) - Structured report in data
+  markdown       - Report rendered as Markdown in data.report
+
+Example:
+  godebug report --target ./cmd/app --break main.go:42
+  godebug report --target ./cmd/app --break handler.go:88 --cond "id == 7" --format markdown
+  godebug report --target ./cmd/app --break main.process -- -config dev.yaml`,
+		Args: cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if reportTarget == "" || len(reportBreaks) == 0 {
+				output.ErrorWithInfo("report", output.InvalidArgument("--target and --break are required")).PrintAndExit(getOutputFormat())
+			}
+			if len(args) > 0 && cmd.ArgsLenAtDash() != 0 {
+				output.ErrorWithInfo("report", output.InvalidArgumentWithDetails(
+					"program arguments go after --",
+					map[string]any{"args": args},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if reportFormat != "json" && reportFormat != "markdown" {
+				output.ErrorWithInfo("report", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid format: %s (expected json or markdown)", reportFormat),
+					map[string]any{"format": reportFormat},
+				)).PrintAndExit(getOutputFormat())
+			}
+			mode := debugger.ModeDebug
+			switch reportMode {
+			case "debug":
+			case "test":
+				mode = debugger.ModeTest
+			case "exec":
+				mode = debugger.ModeExec
+			default:
+				output.ErrorWithInfo("report", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid mode: %s (expected debug, test or exec)", reportMode),
+					map[string]any{"mode": reportMode},
+				)).PrintAndExit(getOutputFormat())
+			}
+			start := time.Now()
+
+			result, err := debugger.Launch(debugger.LaunchConfig{Mode: mode, Target: reportTarget, Args: args, Timeout: getTimeout()})
+			if err != nil {
+				output.Error("report", err).PrintAndExit(getOutputFormat())
+			}
+			c, err := debugger.Connect(result.Addr)
+			if err != nil {
+				_ = result.Kill()
+				output.Error("report", err).PrintAndExit(getOutputFormat())
+			}
+			c.SetTimeout(getTimeout())
+			// The session exists only for this report
+			fail := func(err error) {
+				_ = c.Detach(true)
+				_ = c.Close()
+				_ = result.Kill()
+				_ = session.Remove(result.Addr)
+				output.Error("report", err).PrintAndExit(getOutputFormat())
+			}
+
+			r := &debugReport{Target: result.Target, Mode: result.Mode, Breakpoints: []map[string]any{}}
+			for _, location := range reportBreaks {
+				bp, err := createReportBreakpoint(c, location, reportCond)
+				if err != nil {
+					fail(err)
+				}
+				r.Breakpoints = append(r.Breakpoints, bp)
+			}
+
+			state, err := c.Continue()
+			if err != nil {
+				fail(err)
+			}
+			r.Stop = stateToData(state)
+			r.Hit = !state.Exited && state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil && state.CurrentThread.Breakpoint.ID > 0
+			if !state.Exited && state.SelectedGoroutine != nil {
+				collectReport(c, r, state, reportDepth, reportContext)
+			}
+
+			_ = c.Detach(true)
+			_ = c.Close()
+			_ = result.Kill()
+			_ = session.Remove(result.Addr)
+			r.DurationMs = time.Since(start).Milliseconds()
+
+			var msg string
+			loc, _ := r.Stop["location"].(map[string]any)
+			switch {
+			case state.Exited:
+				msg = fmt.Sprintf("Program exited with status %d before reaching a breakpoint", state.ExitStatus)
+			case r.Hit && loc != nil:
+				msg = fmt.Sprintf("Report at %v:%v", loc["file"], loc["line"])
+			case loc != nil:
+				// e.g. an unrecovered panic stops the program first
+				msg = fmt.Sprintf("Program stopped at %v:%v without hitting a breakpoint", loc["file"], loc["line"])
+			default:
+				msg = "Program stopped without hitting a breakpoint"
+			}
+
+			var data any = r
+			if reportFormat == "markdown" {
+				data = map[string]any{"report": r.markdown(), "hit": r.Hit}
+			}
+			output.Success("report", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	reportCmd.Flags().StringVar(&reportTarget, "target", "", "Package, test package or binary to debug")
+	reportCmd.Flags().StringVar(&reportMode, "mode", "debug", "Debug mode: debug, test, or exec")
+	reportCmd.Flags().StringArrayVar(&reportBreaks, "break", nil, "Breakpoint location (repeatable; the first hit is reported)")
+	reportCmd.Flags().StringVar(&reportCond, "cond", "", "Condition applied to every breakpoint")
+	reportCmd.Flags().IntVar(&reportDepth, "depth", 20, "Maximum stack depth")
+	reportCmd.Flags().IntVar(&reportContext, "context", 5, "Source lines of context before and after")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "json", "Report format: json or markdown")
+	root.AddCommand(reportCmd)
+}
+
+func init() {
+	addReportCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
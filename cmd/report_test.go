@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSourceWindow checks the lines read around a line near the file start.
+func TestSourceWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("a\nb\nc\nd\ne\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := sourceWindow(path, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 || lines[0]["content"] != "a" || lines[1]["current"] != true || lines[2]["lineNumber"] != 3 {
+		t.Errorf("sourceWindow(2, 1) = %v", lines)
+	}
+}
+
+// TestReportMarkdown checks the sections rendered for a hit and an exit.
+func TestReportMarkdown(t *testing.T) {
+	r := &debugReport{
+		Target:      "./cmd/app",
+		Hit:         true,
+		Stop:        map[string]any{"location": map[string]any{"file": "/src/main.go", "line": 42, "function": "main.main"}},
+		Breakpoints: []map[string]any{{"id": 1, "location": "main.go:42", "file": "/src/main.go", "line": 42}},
+		Source:      map[string]any{"lines": []map[string]any{{"lineNumber": 42, "content": "x := f()", "current": true}}},
+		Locals:      []map[string]any{{"name": "s", "type": "string", "value": `"a|b"`}},
+	}
+	md := r.markdown()
+	for _, want := range []string{"Stopped at /src/main.go:42 in `main.main`", "=>   42  x := f()", "## Locals", `"a\|b"`} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	r = &debugReport{Target: "./cmd/app", Stop: map[string]any{"exited": true, "exitStatus": 3}}
+	if md := r.markdown(); !strings.Contains(md, "exited with status 3") {
+		t.Errorf("markdown of an exit:\n%s", md)
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// retryPolicy builds the client's retry policy from --retry-on, --retries
+// and --retry-backoff
+func retryPolicy(on string, attempts int, backoff time.Duration) (debugger.RetryPolicy, *output.ErrorInfo) {
+	classes, err := debugger.ParseRetryClasses(on)
+	if err != nil {
+		return debugger.RetryPolicy{}, output.InvalidArgumentWithDetails(err.Error(), map[string]any{
+			"retryOn": on,
+			"classes": debugger.RetryClasses,
+		})
+	}
+	if attempts < 0 || backoff < 0 {
+		return debugger.RetryPolicy{}, output.InvalidArgument("--retries and --retry-backoff must not be negative")
+	}
+	return debugger.RetryPolicy{Classes: classes, Attempts: attempts, Backoff: backoff}, nil
+}
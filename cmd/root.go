@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -14,13 +13,23 @@ import (
 
 	"github.com/8gears/godebug-agentic/internal/debugger"
 	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
 )
 
 var (
 	// Global flags
-	addr         string
-	outputFormat string
-	timeout      time.Duration
+	addr           string
+	outputFormat   string
+	timeout        time.Duration
+	budgetTokens   int
+	estimateTokens bool
+	withState      bool
+	retryOn        string
+	retryAttempts  int
+	retryBackoff   time.Duration
+
+	// Retry policy of the client's RPCs, from the --retry-on flags
+	clientRetry debugger.RetryPolicy
 
 	// Shared client (initialized per command if --addr is provided)
 	client *debugger.Client
@@ -45,19 +54,21 @@ func GetClient() (*debugger.Client, error) {
 		return client, nil
 	}
 	var err error
-	client, err = debugger.Connect(addr)
+	client, err = debugger.ConnectWithRetry(addr, clientRetry)
 	return client, err
 }
 
-// MustGetClient returns the client or exits with error
+// MustGetClient returns the client or exits with error.
+// Commands that are invalid in the session's current state are rejected here.
 func MustGetClient(cmdName string) *debugger.Client {
 	if addr == "" {
 		output.ErrorWithInfo(cmdName, output.InvalidArgument("--addr flag is required")).PrintAndExit(GetOutputFormat())
 	}
 	c, err := GetClient()
 	if err != nil {
-		output.Error(cmdName, err).PrintAndExit(GetOutputFormat())
+		output.ErrorWithInfo(cmdName, connectError(addr, err)).PrintAndExit(GetOutputFormat())
 	}
+	checkCommandState(c, cmdName, GetOutputFormat)
 	return c
 }
 
@@ -78,6 +89,20 @@ Then use --addr with all subsequent commands:
   godebug --addr 127.0.0.1:38697 locals`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if info := applyProjectDefaults(cmd); info != nil {
+			output.ErrorWithInfo(cmd.Name(), info).PrintAndExit(GetOutputFormat())
+		}
+		policy, info := retryPolicy(retryOn, retryAttempts, retryBackoff)
+		if info != nil {
+			output.ErrorWithInfo(cmd.Name(), info).PrintAndExit(GetOutputFormat())
+		}
+		clientRetry = policy
+		output.SetTokenOptions(estimateTokens, budgetTokens)
+		if withState {
+			output.SetStateProvider(stopContext(&addr))
+		}
+	},
 }
 
 // Execute adds all child commands to the root command
@@ -91,6 +116,12 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&addr, "addr", "", "Delve server address (host:port)")
 	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "json", "Output format: json or text")
 	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "Operation timeout (e.g., 10s, 1m, 30s)")
+	rootCmd.PersistentFlags().BoolVar(&estimateTokens, "estimate-tokens", false, "Include meta.tokens_estimate in responses")
+	rootCmd.PersistentFlags().IntVar(&budgetTokens, "budget-tokens", 0, "Truncate response data to fit approximately N tokens (0 = unlimited)")
+	rootCmd.PersistentFlags().BoolVar(&withState, "with-state", false, "Include the session's stop context (state, goroutine, location) in responses")
+	rootCmd.PersistentFlags().StringVar(&retryOn, "retry-on", "", "Retry RPCs failing with these transient errors: connection, eof, busy or all")
+	rootCmd.PersistentFlags().IntVar(&retryAttempts, "retries", 3, "Retries per RPC with --retry-on")
+	rootCmd.PersistentFlags().DurationVar(&retryBackoff, "retry-backoff", 200*time.Millisecond, "Wait before the first retry, doubled for each further one")
 }
 
 // NewRootCmd creates a fresh root command for testing.
@@ -101,6 +132,13 @@ func NewRootCmd() *cobra.Command {
 	var cmdAddr string
 	var cmdOutputFormat string
 	var cmdTimeout time.Duration
+	var cmdBudgetTokens int
+	var cmdEstimateTokens bool
+	var cmdWithState bool
+	var cmdRetryOn string
+	var cmdRetryAttempts int
+	var cmdRetryBackoff time.Duration
+	var cmdRetry debugger.RetryPolicy
 
 	cmd := &cobra.Command{
 		Use:   "godebug",
@@ -110,11 +148,41 @@ Each invocation runs one command, outputs structured JSON, and exits.
 Designed for AI agent tool calling.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if info := applyProjectDefaults(cmd); info != nil {
+				format := output.FormatJSON
+				if cmdOutputFormat == "text" {
+					format = output.FormatText
+				}
+				output.ErrorWithInfo(cmd.Name(), info).PrintAndExit(format)
+			}
+			policy, info := retryPolicy(cmdRetryOn, cmdRetryAttempts, cmdRetryBackoff)
+			if info != nil {
+				format := output.FormatJSON
+				if cmdOutputFormat == "text" {
+					format = output.FormatText
+				}
+				output.ErrorWithInfo(cmd.Name(), info).PrintAndExit(format)
+			}
+			cmdRetry = policy
+			output.SetTokenOptions(cmdEstimateTokens, cmdBudgetTokens)
+			if cmdWithState {
+				output.SetStateProvider(stopContext(&cmdAddr))
+			} else {
+				output.SetStateProvider(nil)
+			}
+		},
 	}
 
 	cmd.PersistentFlags().StringVar(&cmdAddr, "addr", "", "Delve server address (host:port)")
 	cmd.PersistentFlags().StringVar(&cmdOutputFormat, "output", "json", "Output format: json or text")
 	cmd.PersistentFlags().DurationVar(&cmdTimeout, "timeout", 30*time.Second, "Operation timeout (e.g., 10s, 1m, 30s)")
+	cmd.PersistentFlags().BoolVar(&cmdEstimateTokens, "estimate-tokens", false, "Include meta.tokens_estimate in responses")
+	cmd.PersistentFlags().IntVar(&cmdBudgetTokens, "budget-tokens", 0, "Truncate response data to fit approximately N tokens (0 = unlimited)")
+	cmd.PersistentFlags().BoolVar(&cmdWithState, "with-state", false, "Include the session's stop context (state, goroutine, location) in responses")
+	cmd.PersistentFlags().StringVar(&cmdRetryOn, "retry-on", "", "Retry RPCs failing with these transient errors: connection, eof, busy or all")
+	cmd.PersistentFlags().IntVar(&cmdRetryAttempts, "retries", 3, "Retries per RPC with --retry-on")
+	cmd.PersistentFlags().DurationVar(&cmdRetryBackoff, "retry-backoff", 200*time.Millisecond, "Wait before the first retry, doubled for each further one")
 
 	// Helper functions for this command's context
 	getOutputFormat := func() output.OutputFormat {
@@ -132,10 +200,11 @@ Designed for AI agent tool calling.`,
 		if cmdAddr == "" {
 			output.ErrorWithInfo(cmdName, output.InvalidArgument("--addr flag is required")).PrintAndExit(getOutputFormat())
 		}
-		c, err := debugger.Connect(cmdAddr)
+		c, err := debugger.ConnectWithRetry(cmdAddr, cmdRetry)
 		if err != nil {
-			output.Error(cmdName, err).PrintAndExit(getOutputFormat())
+			output.ErrorWithInfo(cmdName, connectError(cmdAddr, err)).PrintAndExit(getOutputFormat())
 		}
+		checkCommandState(c, cmdName, getOutputFormat)
 		return c
 	}
 
@@ -144,11 +213,63 @@ Designed for AI agent tool calling.`,
 	addConnectCommand(cmd, getOutputFormat)
 	addStatusCommand(cmd, mustGetClient, getOutputFormat)
 	addExecutionCommands(cmd, mustGetClient, getOutputFormat, getTimeout)
+	addReverseCommands(cmd, mustGetClient, getOutputFormat, getTimeout)
 	addBreakpointCommands(cmd, mustGetClient, getOutputFormat)
 	addInspectCommands(cmd, mustGetClient, getOutputFormat)
 	addNavigationCommands(cmd, mustGetClient, getOutputFormat)
 	addSourceCommands(cmd, mustGetClient, getOutputFormat)
 	addQuitCommand(cmd, mustGetClient, getOutputFormat)
+	addDebugFuzzCrashCommand(cmd, getOutputFormat, getTimeout)
+	addReloadCommand(cmd, mustGetClient, getOutputFormat)
+	addAnnotateCommand(cmd, mustGetClient, getOutputFormat)
+	addSummarizeCommand(cmd, mustGetClient, getOutputFormat)
+	addCompareCommand(cmd, getOutputFormat)
+	addBisectRunCommand(cmd, getOutputFormat, getTimeout)
+	addBuildInfoCommand(cmd, mustGetClient, getOutputFormat)
+	addEnvCommand(cmd, mustGetClient, getOutputFormat)
+	addFdsCommand(cmd, mustGetClient, getOutputFormat)
+	addWatchLiveCommand(cmd, mustGetClient, getOutputFormat)
+	addConditionCommand(cmd, mustGetClient, getOutputFormat)
+	addFrameMoveCommands(cmd, mustGetClient, getOutputFormat)
+	addScopeCommand(cmd, mustGetClient, getOutputFormat)
+	addMapsCommand(cmd, mustGetClient, getOutputFormat)
+	addAnalyzeCommand(cmd, mustGetClient, getOutputFormat)
+	addInterruptCommand(cmd, mustGetClient, getOutputFormat, getTimeout)
+	addSelftestCommand(cmd, getOutputFormat, getTimeout)
+	addTutorialCommand(cmd, getOutputFormat, getTimeout)
+	addScenarioCommand(cmd, getOutputFormat, getTimeout)
+	addPrintCommand(cmd, mustGetClient, getOutputFormat)
+	addGoroutineDumpsCommand(cmd, mustGetClient, getOutputFormat)
+	addToolspecCommand(cmd, getOutputFormat)
+	addInitAgentCommand(cmd, getOutputFormat, getTimeout)
+	addReportCommand(cmd, getOutputFormat, getTimeout)
+	addTriageCommand(cmd, getOutputFormat, getTimeout)
+	addProbeCommand(cmd, mustGetClient, getOutputFormat)
+	addTraceCommand(cmd, mustGetClient, getOutputFormat)
+	addServerLogsCommand(cmd, getOutputFormat)
+	addCallCommand(cmd, mustGetClient, getOutputFormat)
+	addSetCommand(cmd, mustGetClient, getOutputFormat)
+	addWatchCommand(cmd, mustGetClient, getOutputFormat)
+	addProjectCommand(cmd, getOutputFormat)
+	addFuncsCommand(cmd, mustGetClient, getOutputFormat)
+	addTypesCommand(cmd, mustGetClient, getOutputFormat)
+	addVarsCommand(cmd, mustGetClient, getOutputFormat)
+	addThreadsCommands(cmd, mustGetClient, getOutputFormat)
+	addTraceHTTPCommand(cmd, mustGetClient, getOutputFormat)
+	addInstructionStepCommands(cmd, mustGetClient, getOutputFormat, getTimeout)
+	addHuntCommand(cmd, mustGetClient, getOutputFormat, getTimeout)
+	addUntilCommand(cmd, mustGetClient, getOutputFormat, getTimeout)
+	addCheckpointCommands(cmd, mustGetClient, getOutputFormat)
+	addExamineCommand(cmd, mustGetClient, getOutputFormat)
+	addAgentPromptCommand(cmd, getOutputFormat)
+	addDetachCommand(cmd, mustGetClient, getOutputFormat)
+	addBreakpointCommand(cmd, mustGetClient, getOutputFormat)
+	addExploreCommand(cmd, getOutputFormat, getTimeout)
+	addPanicsCommand(cmd, mustGetClient, getOutputFormat)
+	addHitStacksCommand(cmd, mustGetClient, getOutputFormat)
+	addArtifactsCommand(cmd, getOutputFormat)
+	addDefersCommand(cmd, mustGetClient, getOutputFormat)
+	addLibsCommand(cmd, mustGetClient, getOutputFormat)
 
 	return cmd
 }
@@ -156,6 +277,13 @@ Designed for AI agent tool calling.`,
 // addStartCommand adds the start command to the root
 func addStartCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
 	var startMode string
+	var startOnCrash string
+	var startReplayOf, startStdin string
+	var startRecordEnv []string
+	var startServerLog, startBackend, startListen, startBuildFlags string
+	var startNoHalt, startContinue, startReuseBuild bool
+	var startBuildCache string
+	var startReadyTimeout time.Duration
 
 	startCmd := &cobra.Command{
 		Use:   "start [target]",
@@ -166,57 +294,168 @@ Modes:
   debug (default) - Compile and debug a Go package
   test            - Compile and debug tests
   exec            - Debug a pre-compiled binary
+  attach          - Debug a running process; the target is its PID. It is
+                    stopped unless --no-halt is given; quit detaches and
+                    leaves the process running
+
+start waits until the server accepts connections: up to --ready-timeout
+(default: --timeout), which large builds may need raised. --listen fixes the
+server address instead of picking a free port.
+
+Delve's own log (--server-log components, debugger,rpc by default) is kept
+in the session directory; server-logs shows it.
+
+With --on-crash capture the program's stdout and stderr are written to files.
+When an execution command stops on an unrecovered panic or fatal error (or the
+process exits non-zero), a goroutine dump, the output tail and a core dump
+are saved with the session's artifacts (see artifacts) and reported in
+data.crash.
+
+--backend rr records the program with rr: rewind (reverse-continue),
+reverse-next, reverse-step and reverse-stepout then move backwards through
+the recording, replaying an intermittent race deterministically.
+
+--build-flags passes extra go build flags (tags, -race) in debug and test
+mode; replays reuse the recorded ones. In a project (see godebug project),
+start without a target launches its start.target, and every start sets its
+breakpoints, reported in data.project.
+
+Short edit-debug loops are dominated by the launch. --continue runs the
+program as soon as the server is up instead of stopping at entry (interrupt
+stops it). --build-cache-dir sets the Go build cache (GOCACHE) the build
+uses. With --reuse-build, debug mode builds the package itself and launches
+the binary in exec mode; a later start whose sources, go.mod files, build
+flags and toolchain are unchanged launches the same binary without building
+(data.build.reused). reload cannot rebuild such a session; start it again.
 
 Examples:
   godebug start ./cmd/myapp           # Debug mode (default)
   godebug start --mode test ./...     # Test mode
   godebug start --mode exec ./binary  # Exec mode
-  godebug start ./cmd/myapp -- -port 8080  # With program args`,
-		Args: cobra.MinimumNArgs(1),
+  godebug start --mode attach 4242    # Attach to a running process
+  godebug start --mode attach --no-halt 4242  # Attach without stopping it
+  godebug start --reuse-build --continue ./cmd/myapp  # Fast restart
+  godebug start ./cmd/myapp -- -port 8080  # With program args
+  godebug start --on-crash capture ./cmd/myapp  # Save artifacts if it crashes`,
+		Args: cobra.ArbitraryArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			target := args[0]
-			var programArgs []string
-			if cmd.ArgsLenAtDash() > 0 {
-				programArgs = args[cmd.ArgsLenAtDash():]
+			validateOnCrash(startOnCrash, getOutputFormat)
+
+			config, launch := launchConfig(cmd, args, startMode, startOnCrash, startReplayOf, startStdin, startRecordEnv, getOutputFormat)
+			config.Timeout = getTimeout()
+			if startReadyTimeout > 0 {
+				config.Timeout = startReadyTimeout
+			}
+			config.Listen = startListen
+			mode, target := config.Mode, config.Target
+			validateNoHalt(startNoHalt, mode, getOutputFormat)
+			config.Continue = startNoHalt
+			if startBackend != "" {
+				config.Backend = validateBackend(startBackend, mode, getOutputFormat)
 			}
+			if startBuildFlags != "" && startReplayOf == "" {
+				config.BuildFlags = startBuildFlags
+			}
+			if launch != nil {
+				launch.Backend = config.Backend
+				launch.BuildFlags = config.BuildFlags
+			}
+			prebuild := applyFastStart(&config, startContinue, startBuildCache, startReuseBuild, getOutputFormat)
 
-			mode := debugger.ModeDebug
-			switch startMode {
-			case "test":
-				mode = debugger.ModeTest
-			case "exec":
-				mode = debugger.ModeExec
+			// Keep the target's output in files so a crash capture can include its tail
+			var stdout, stderr string
+			if startOnCrash == onCrashCapture {
+				redirects, out, errOut, err := crashRedirects()
+				if err != nil {
+					output.Error("start", err).PrintAndExit(getOutputFormat())
+				}
+				config.Redirects = append(config.Redirects, redirects...)
+				stdout, stderr = out, errOut
 			}
 
-			config := debugger.LaunchConfig{
-				Mode:    mode,
-				Target:  target,
-				Args:    programArgs,
-				Timeout: getTimeout(),
+			if err := enableServerLog(&config, startServerLog); err != nil {
+				output.Error("start", err).PrintAndExit(getOutputFormat())
 			}
 
 			result, err := debugger.Launch(config)
 			if err != nil {
-				output.Error("start", err).PrintAndExit(getOutputFormat())
+				// A binary without debug info is the usual reason exec mode fails
+				if mode == debugger.ModeExec {
+					if d := debuggability(target); d != nil && d["level"] == debuggabilityNone {
+						output.ErrorWithInfo("start", output.FromError(err).WithDetails(map[string]any{"debuggability": d})).PrintAndExit(getOutputFormat())
+					}
+				}
+				output.ErrorWithInfo("start", launchFailure(config, err)).PrintAndExit(getOutputFormat())
+			}
+
+			recordLaunchSession(result)
+			serverLog := adoptServerLog(result)
+			saveLaunchRecord(result.Addr, launch)
+			if startOnCrash == onCrashCapture {
+				recordCrashCapture(result.Addr, stdout, stderr)
 			}
 
 			data := map[string]any{
 				"addr":   result.Addr,
 				"pid":    result.PID,
-				"target": result.Target,
-				"mode":   result.Mode,
+				"target": target,
+				"mode":   string(mode),
+			}
+			if launch != nil {
+				data["sessionId"] = session.ID(result.Addr)
+				data["launch"] = launchRecordData(launch)
+			}
+			if startReplayOf != "" {
+				data["replayOf"] = startReplayOf
+			}
+			if result.Backend != "" {
+				data["backend"] = result.Backend
+			}
+			if startOnCrash == onCrashCapture {
+				data["onCrash"] = onCrashCapture
+				data["stdout"] = stdout
+				data["stderr"] = stderr
+			}
+			if serverLog != "" {
+				data["serverLog"] = serverLog
+			}
+			if p := setProjectBreakpoints(result.Addr); p != nil {
+				data["project"] = p
+			}
+			if d := sessionDebuggability(result.Addr); d != nil {
+				data["debuggability"] = d
 			}
 
-			output.Success("start", data, "Debug server started").PrintAndExit(getOutputFormat())
+			msg := fastStartData(data, "Debug server started", prebuild, config.Continue && mode != debugger.ModeAttach)
+			if mode == debugger.ModeAttach {
+				halted := attachHalted(result.Addr, startNoHalt)
+				data["halted"] = halted
+				msg = attachMessage(target, halted)
+			}
+
+			output.Success("start", data, msg).PrintAndExit(getOutputFormat())
 		},
 	}
 
-	startCmd.Flags().StringVar(&startMode, "mode", "debug", "Debug mode: debug, test, or exec")
+	startCmd.Flags().StringVar(&startMode, "mode", "debug", "Debug mode: debug, test, exec, or attach")
+	startCmd.Flags().StringVar(&startOnCrash, "on-crash", "", "Crash handling: capture saves post-mortem artifacts")
+	addReplayFlags(startCmd, &startReplayOf, &startStdin, &startRecordEnv)
+	startCmd.Flags().StringVar(&startBackend, "backend", "", "Delve backend: default, native, lldb, or rr (record for reverse execution)")
+	startCmd.Flags().StringVar(&startBuildFlags, "build-flags", "", "Extra flags for building the target in debug and test mode, e.g. -tags=integration")
+	startCmd.Flags().BoolVar(&startNoHalt, "no-halt", false, "In attach mode, leave the process running instead of stopping it")
+	startCmd.Flags().BoolVar(&startContinue, "continue", false, "Run the program right away instead of stopping it at entry")
+	startCmd.Flags().BoolVar(&startReuseBuild, "reuse-build", false, "In debug mode, launch the binary of the previous start when its sources have not changed")
+	startCmd.Flags().StringVar(&startBuildCache, "build-cache-dir", "", "Go build cache (GOCACHE) for building the target in debug and test mode")
+	startCmd.Flags().StringVar(&startListen, "listen", "", "Address the Delve server listens on (default 127.0.0.1 on a free port)")
+	startCmd.Flags().DurationVar(&startReadyTimeout, "ready-timeout", 0, "How long to wait for the server to build and listen (0 = --timeout)")
+	startCmd.Flags().StringVar(&startServerLog, "server-log", defaultServerLogOutput, "Delve log components kept for server-logs (\"\" = none)")
 	root.AddCommand(startCmd)
 }
 
 // addConnectCommand adds the connect command to the root
 func addConnectCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat) {
+	var connectArch string
+
 	connectCmd := &cobra.Command{
 		Use:   "connect <addr>",
 		Short: "Connect to an existing Delve server",
@@ -224,9 +463,16 @@ func addConnectCommand(root *cobra.Command, getOutputFormat func() output.Output
 
 This is useful for remote debugging or attaching to a manually started Delve server.
 
+The server may run on another architecture than this host (an ARM board
+debugged from an x86 workstation). While the target is stopped, connect
+detects its architecture and reports it in data.arch, with cross set when it
+differs from the host's. --arch fails the connect unless the target runs on
+the given architecture (amd64, 386, arm64, riscv64, ppc64le, loong64).
+
 Example:
   dlv debug ./myapp --headless --api-version=2 --listen=:38697
-  godebug connect localhost:38697`,
+  godebug connect localhost:38697
+  godebug connect --arch arm64 board.local:2345`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			serverAddr := args[0]
@@ -243,6 +489,15 @@ Example:
 				output.Error("connect", err).PrintAndExit(getOutputFormat())
 			}
 
+			recordConnectSession(serverAddr)
+
+			arch := sessionArch(c)
+			if connectArch != "" {
+				if info := checkArch(connectArch, arch); info != nil {
+					output.ErrorWithInfo("connect", info).PrintAndExit(getOutputFormat())
+				}
+			}
+
 			data := map[string]any{
 				"addr":    serverAddr,
 				"running": state.Running,
@@ -250,11 +505,18 @@ Example:
 			if state.SelectedGoroutine != nil {
 				data["goroutineId"] = state.SelectedGoroutine.ID
 			}
+			if arch != nil {
+				data["arch"] = archData(*arch)
+			}
+			if d := sessionDebuggability(serverAddr); d != nil {
+				data["debuggability"] = d
+			}
 
 			output.Success("connect", data, "Connected to debug server").PrintAndExit(getOutputFormat())
 		},
 	}
 
+	connectCmd.Flags().StringVar(&connectArch, "arch", "", "Fail unless the target runs on this architecture (GOARCH name)")
 	root.AddCommand(connectCmd)
 }
 
@@ -290,9 +552,7 @@ Example:
 
 			if state.SelectedGoroutine != nil {
 				g := state.SelectedGoroutine
-				data["goroutine"] = map[string]any{
-					"id": g.ID,
-				}
+				data["goroutine"] = goroutineRef(g)
 				if g.CurrentLoc.File != "" {
 					data["location"] = map[string]any{
 						"file":     g.CurrentLoc.File,
@@ -320,6 +580,8 @@ Example:
 
 // addExecutionCommands adds execution control commands (continue, next, step, etc.)
 func addExecutionCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var continueNoTimeout bool
+
 	// continue
 	continueCmd := &cobra.Command{
 		Use:   "continue",
@@ -327,27 +589,49 @@ func addExecutionCommands(root *cobra.Command, mustGetClient func(string) *debug
 		Run: func(cmd *cobra.Command, args []string) {
 			c := mustGetClient("continue")
 			defer func() { _ = c.Close() }()
-			c.SetTimeout(getTimeout())
 
-			state, err := c.Continue()
+			// Hits of --dump-goroutines and --stacktrace breakpoints are recorded and continued past
+			state, interrupted, passed, err := continuePastDumps(c, continueNoTimeout, getTimeout())
 			if err != nil {
 				output.Error("continue", err).PrintAndExit(getOutputFormat())
 			}
 
+			stoppedAt := recordStop(c, "continue", state)
+
 			var msg string
 			if state.Exited {
 				msg = "Process exited"
+			} else if interrupted {
+				msg = "Interrupted; process halted"
 			} else if state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil {
 				msg = "Stopped at breakpoint"
 			} else {
 				msg = "Process stopped"
 			}
 
-			output.Success("continue", stateToData(state), msg).PrintAndExit(getOutputFormat())
+			data := stateToData(state)
+			stoppedAt.addTo(data)
+			if interrupted {
+				data["interrupted"] = true
+			}
+			passed.addTo(data)
+			if watch := watchpointHit(c, state); watch != nil {
+				data["watchpoint"] = watch
+				if watch["id"] != nil {
+					msg = "Stopped at watchpoint"
+				}
+			}
+			if crash := captureCrash(c, state); crash != nil {
+				data["crash"] = crash
+			}
+
+			output.Success("continue", data, msg).PrintAndExit(getOutputFormat())
 		},
 	}
+	continueCmd.Flags().BoolVar(&continueNoTimeout, "no-timeout", false, "Wait until the program stops; interrupting godebug halts it")
 
-	// next
+	// next, step and stepout
+	var stepCancelPending bool
 	nextCmd := &cobra.Command{
 		Use:   "next",
 		Short: "Step over to next source line",
@@ -356,12 +640,23 @@ func addExecutionCommands(root *cobra.Command, mustGetClient func(string) *debug
 			defer func() { _ = c.Close() }()
 			c.SetTimeout(getTimeout())
 
-			state, err := c.Next()
+			state, cancelled, err := stepCancellingPending(c, c.Next, stepCancelPending)
 			if err != nil {
 				output.Error("next", err).PrintAndExit(getOutputFormat())
 			}
 
-			output.Success("next", stateToData(state), "Stepped to next line").PrintAndExit(getOutputFormat())
+			stoppedAt := recordStop(c, "next", state)
+
+			data := stateToData(state)
+			stoppedAt.addTo(data)
+			if cancelled {
+				data["cancelledPending"] = true
+			}
+			if crash := captureCrash(c, state); crash != nil {
+				data["crash"] = crash
+			}
+
+			output.Success("next", data, "Stepped to next line").PrintAndExit(getOutputFormat())
 		},
 	}
 
@@ -374,12 +669,23 @@ func addExecutionCommands(root *cobra.Command, mustGetClient func(string) *debug
 			defer func() { _ = c.Close() }()
 			c.SetTimeout(getTimeout())
 
-			state, err := c.Step()
+			state, cancelled, err := stepCancellingPending(c, c.Step, stepCancelPending)
 			if err != nil {
 				output.Error("step", err).PrintAndExit(getOutputFormat())
 			}
 
-			output.Success("step", stateToData(state), "Stepped into function").PrintAndExit(getOutputFormat())
+			stoppedAt := recordStop(c, "step", state)
+
+			data := stateToData(state)
+			stoppedAt.addTo(data)
+			if cancelled {
+				data["cancelledPending"] = true
+			}
+			if crash := captureCrash(c, state); crash != nil {
+				data["crash"] = crash
+			}
+
+			output.Success("step", data, "Stepped into function").PrintAndExit(getOutputFormat())
 		},
 	}
 
@@ -392,29 +698,64 @@ func addExecutionCommands(root *cobra.Command, mustGetClient func(string) *debug
 			defer func() { _ = c.Close() }()
 			c.SetTimeout(getTimeout())
 
-			state, err := c.StepOut()
+			state, cancelled, err := stepCancellingPending(c, c.StepOut, stepCancelPending)
 			if err != nil {
 				output.Error("stepout", err).PrintAndExit(getOutputFormat())
 			}
 
-			output.Success("stepout", stateToData(state), "Stepped out of function").PrintAndExit(getOutputFormat())
+			stoppedAt := recordStop(c, "stepout", state)
+
+			data := stateToData(state)
+			stoppedAt.addTo(data)
+			if cancelled {
+				data["cancelledPending"] = true
+			}
+			if crash := captureCrash(c, state); crash != nil {
+				data["crash"] = crash
+			}
+
+			output.Success("stepout", data, "Stepped out of function").PrintAndExit(getOutputFormat())
 		},
 	}
 
 	// restart
+	var restartCheckpoint int
 	restartCmd := &cobra.Command{
 		Use:   "restart",
 		Short: "Restart the debugged program",
+		Long: `Restart the program from the beginning.
+
+All breakpoints are preserved. In a session started with --backend rr,
+--from-checkpoint moves back to a checkpoint (see checkpoint) instead.
+
+Example:
+  godebug --addr $ADDR restart
+  godebug --addr $ADDR restart --from-checkpoint 1`,
 		Run: func(cmd *cobra.Command, args []string) {
 			c := mustGetClient("restart")
 			defer func() { _ = c.Close() }()
 
-			state, err := c.Restart()
+			var state *api.DebuggerState
+			var err error
+			msg := "Program restarted"
+			if cmd.Flags().Changed("from-checkpoint") {
+				state, err = restartFromCheckpoint(c, restartCheckpoint)
+				msg = fmt.Sprintf("Restarted from checkpoint %d", restartCheckpoint)
+			} else {
+				state, err = c.Restart()
+			}
 			if err != nil {
 				output.Error("restart", err).PrintAndExit(getOutputFormat())
 			}
 
-			output.Success("restart", stateToData(state), "Program restarted").PrintAndExit(getOutputFormat())
+			stoppedAt := recordStop(c, "restart", state)
+
+			data := stateToData(state)
+			stoppedAt.addTo(data)
+			if cmd.Flags().Changed("from-checkpoint") {
+				data["checkpoint"] = restartCheckpoint
+			}
+			output.Success("restart", data, msg).PrintAndExit(getOutputFormat())
 		},
 	}
 
@@ -422,24 +763,142 @@ func addExecutionCommands(root *cobra.Command, mustGetClient func(string) *debug
 	root.AddCommand(nextCmd)
 	root.AddCommand(stepCmd)
 	root.AddCommand(stepoutCmd)
+	for _, c := range []*cobra.Command{nextCmd, stepCmd, stepoutCmd} {
+		c.Flags().BoolVar(&stepCancelPending, "cancel-pending", false, "Cancel a next, step or stepout a breakpoint interrupted and step anyway")
+	}
+	restartCmd.Flags().IntVar(&restartCheckpoint, "from-checkpoint", 0, "Restart an rr recording from this checkpoint")
 	root.AddCommand(restartCmd)
 }
 
 // addBreakpointCommands adds breakpoint management commands
 func addBreakpointCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
 	var breakCond string
+	var breakHitCond string
+	var breakStacktrace int
+	var breakRegex string
+	var breakType string
+	var breakSuspended bool
+	var breakCollectDiff string
+	var breakAssign string
+	var breakChanOps []string
+	var breakExpr string
+	var breakDump bool
+	var breakOnPanic bool
+	var breakDumpFilter string
 
 	// break
 	breakCmd := &cobra.Command{
-		Use:   "break <location>",
+		Use:   "break [location]",
 		Short: "Set a breakpoint",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			// A suspended breakpoint waits for one location to appear in a plugin
+			if breakSuspended && (len(args) == 0 || breakOnPanic || breakAssign != "" || len(breakChanOps) > 0 || breakRegex != "" || breakType != "") {
+				output.ErrorWithInfo("break", output.InvalidArgument("--suspended takes a location and cannot be combined with --on-panic, --assign, --chan-op, --regex or --type")).PrintAndExit(getOutputFormat())
+			}
+			// --on-panic only turns on the crash breakpoints, like panics on
+			if breakOnPanic {
+				if len(args) > 0 || breakAssign != "" || len(breakChanOps) > 0 || breakRegex != "" || breakType != "" {
+					output.ErrorWithInfo("break", output.InvalidArgument("--on-panic cannot be combined with a location, --assign, --chan-op, --regex or --type")).PrintAndExit(getOutputFormat())
+				}
+				c := mustGetClient("break")
+				data, err := setPanicBreakpoints(c, true)
+				_ = c.Close()
+				if err != nil {
+					output.Error("break", err).PrintAndExit(getOutputFormat())
+				}
+				output.Success("break", data, panicsMessage(data)).PrintAndExit(getOutputFormat())
+			}
+			// --assign places breakpoints itself and takes no location
+			if breakAssign != "" && len(args) > 0 {
+				output.ErrorWithInfo("break", output.InvalidArgumentWithDetails(
+					"--assign cannot be combined with a location",
+					map[string]any{"location": args[0], "assign": breakAssign},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if breakDumpFilter != "" {
+				breakDump = true
+			}
+			if breakAssign != "" && breakDump {
+				output.ErrorWithInfo("break", output.InvalidArgument("--dump-goroutines cannot be combined with --assign")).PrintAndExit(getOutputFormat())
+			}
+			if info := chanOpArgsError(args, breakChanOps, breakExpr, breakAssign, breakDump); info != nil {
+				output.ErrorWithInfo("break", info).PrintAndExit(getOutputFormat())
+			}
+			if info := functionsArgsError(args, breakRegex, breakType, breakAssign, breakChanOps, breakDump, breakHitCond); info != nil {
+				output.ErrorWithInfo("break", info).PrintAndExit(getOutputFormat())
+			}
+			if breakAssign == "" && len(breakChanOps) == 0 && breakRegex == "" && breakType == "" && len(args) == 0 {
+				output.ErrorWithInfo("break", output.InvalidArgument("location is required (or use --assign, --chan-op, --regex, --type or --on-panic)")).PrintAndExit(getOutputFormat())
+			}
+
+			// Condition helpers such as approx() are expanded into Delve expressions
+			cond, info := conditionFlag(breakCond)
+			if info != nil {
+				output.ErrorWithInfo("break", info).PrintAndExit(getOutputFormat())
+			}
+			hitCond, info := hitCondFlag(breakHitCond)
+			if info != nil {
+				output.ErrorWithInfo("break", info).PrintAndExit(getOutputFormat())
+			}
+			// Hit counts are per breakpoint, and --assign and --chan-op set several
+			if hitCond != "" && (breakAssign != "" || len(breakChanOps) > 0) {
+				output.ErrorWithInfo("break", output.InvalidArgument("--hitcond cannot be combined with --assign or --chan-op")).PrintAndExit(getOutputFormat())
+			}
+			if breakStacktrace < 0 {
+				output.ErrorWithInfo("break", output.InvalidArgument("--stacktrace must not be negative")).PrintAndExit(getOutputFormat())
+			}
+			if breakStacktrace > 0 && (breakAssign != "" || len(breakChanOps) > 0) {
+				output.ErrorWithInfo("break", output.InvalidArgument("--stacktrace cannot be combined with --assign or --chan-op")).PrintAndExit(getOutputFormat())
+			}
+
 			c := mustGetClient("break")
 			defer func() { _ = c.Close() }()
 
+			if len(breakChanOps) > 0 {
+				data, err := breakOnChanOps(c, breakChanOps, breakExpr, cond)
+				if err != nil {
+					output.Error("break", err).PrintAndExit(getOutputFormat())
+				}
+				output.Success("break", data, fmt.Sprintf("%d breakpoints set on %s of %s%s", data["count"], strings.Join(breakChanOps, "/"), breakExpr, failedSuffix(data))).PrintAndExit(getOutputFormat())
+			}
+
+			if breakAssign != "" {
+				data, err := breakOnAssignments(c, breakAssign, cond, breakCollectDiff)
+				if err != nil {
+					output.Error("break", err).PrintAndExit(getOutputFormat())
+				}
+				if data["count"] == 0 && data["failed"] == nil {
+					output.ErrorWithInfo("break", output.NotFound("assignment", breakAssign)).PrintAndExit(getOutputFormat())
+				}
+				output.Success("break", data, fmt.Sprintf("%d breakpoints set on assignments to %s%s", data["count"], breakAssign, failedSuffix(data))).PrintAndExit(getOutputFormat())
+			}
+
+			if breakRegex != "" || breakType != "" {
+				tmpl := api.Breakpoint{Cond: cond, Stacktrace: breakStacktrace}
+				if breakCollectDiff != "" {
+					tmpl.Variables = []string{breakCollectDiff}
+				}
+				var data map[string]any
+				var err error
+				if breakType != "" {
+					data, err = breakOnType(c, breakType, tmpl)
+				} else {
+					data, err = breakOnRegex(c, breakRegex, tmpl)
+				}
+				if err != nil {
+					output.Error("break", err).PrintAndExit(getOutputFormat())
+				}
+				msg := fmt.Sprintf("%d breakpoints set on functions matching %s", data["count"], breakRegex)
+				if breakType != "" {
+					msg = fmt.Sprintf("%d breakpoints set on methods of %s", data["count"], data["type"])
+				}
+				output.Success("break", data, msg+failedSuffix(data)).PrintAndExit(getOutputFormat())
+			}
+
 			location := args[0]
 			bp := &api.Breakpoint{}
+			var requestedFile, resolvedBy, requestedFunction string
 
 			// Parse location: file:line or function name
 			if strings.Contains(location, ":") {
@@ -452,26 +911,43 @@ func addBreakpointCommands(root *cobra.Command, mustGetClient func(string) *debu
 						map[string]any{"location": location, "line": parts[1]},
 					)).PrintAndExit(getOutputFormat())
 				}
-				// Convert to absolute path if relative
-				if !filepath.IsAbs(file) {
-					absPath, err := filepath.Abs(file)
-					if err == nil {
-						file = absPath
-					}
+				// Translate the argument to the path the binary was compiled with
+				bp.File, resolvedBy = resolveSourceFile(c, file)
+				if resolvedBy != "" {
+					requestedFile = file
 				}
-				bp.File = file
 				bp.Line = line
 			} else {
-				bp.FunctionName = location
+				// Shorthand such as Type.Method is normalized to the runtime name
+				name, err := resolveFunctionLocation(c, location)
+				if err != nil {
+					output.Error("break", err).PrintAndExit(getOutputFormat())
+				}
+				bp.FunctionName = name
+				if name != location {
+					requestedFunction = location
+				}
 			}
 
 			// Add condition if specified
-			if breakCond != "" {
-				bp.Cond = breakCond
+			bp.Cond = cond
+			bp.HitCond = hitCond
+			bp.Stacktrace = breakStacktrace
+
+			// Collected expressions are diffed between consecutive hits by continue
+			if breakCollectDiff != "" {
+				bp.Variables = []string{breakCollectDiff}
 			}
 
-			created, err := c.CreateBreakpoint(bp)
+			create := c.CreateBreakpoint
+			if breakSuspended {
+				create = c.CreateSuspendedBreakpoint
+			}
+			created, err := create(bp)
 			if err != nil {
+				if info := unresolvedFileError(c, bp, err); info != nil {
+					output.ErrorWithInfo("break", info).PrintAndExit(getOutputFormat())
+				}
 				output.Error("break", err).PrintAndExit(getOutputFormat())
 			}
 
@@ -484,11 +960,68 @@ func addBreakpointCommands(root *cobra.Command, mustGetClient func(string) *debu
 			if created.Cond != "" {
 				data["condition"] = created.Cond
 			}
+			if cond != breakCond {
+				data["conditionSource"] = breakCond
+			}
+			if created.HitCond != "" {
+				data["hitCondition"] = created.HitCond
+			}
+			if len(created.Variables) > 0 {
+				data["collectDiff"] = created.Variables
+			}
+			// A breakpoint that did not resolve where it was asked for is set but
+			// would silently never trigger there
+			if resolvedBy != "" {
+				data["requestedFile"] = requestedFile
+				data["resolvedBy"] = resolvedBy
+			}
+			if requestedFunction != "" {
+				data["requestedFunction"] = requestedFunction
+			}
+			warnings := breakpointWarnings(bp, created)
+			if breakSuspended && len(created.Addrs) == 0 && created.Addr == 0 {
+				// Waiting for a plugin to be loaded is what was asked for
+				warnings = withoutWarning(warnings, warnBreakpointUnresolved)
+				data["suspended"] = true
+			}
+			if len(warnings) > 0 {
+				data["warnings"] = warnings
+			}
+			if breakDump {
+				saveDumpBreakpoint(c.Addr(), created.ID, breakDumpFilter)
+				data["dumpGoroutines"] = true
+				if breakDumpFilter != "" {
+					data["dumpFilter"] = breakDumpFilter
+				}
+			}
+			if breakStacktrace > 0 {
+				saveStackBreakpoint(c.Addr(), created.ID, breakStacktrace)
+				data["stacktrace"] = breakStacktrace
+			}
 
-			output.Success("break", data, fmt.Sprintf("Breakpoint %d set", created.ID)).PrintAndExit(getOutputFormat())
+			msg := fmt.Sprintf("Breakpoint %d set", created.ID)
+			if data["suspended"] == true {
+				msg += ", suspended until a plugin providing it is loaded"
+			}
+			if len(warnings) > 0 {
+				msg += fmt.Sprintf(" with warning: %s", warnings[0].Message)
+			}
+			output.Success("break", data, msg).PrintAndExit(getOutputFormat())
 		},
 	}
 	breakCmd.Flags().StringVar(&breakCond, "cond", "", "Conditional expression")
+	breakCmd.Flags().StringVar(&breakHitCond, "hitcond", "", "Hit count condition, e.g. \"== 100\" or \"% 10\"")
+	breakCmd.Flags().StringVar(&breakCollectDiff, "collect-diff", "", "Expression to collect at each hit; continue stops when it changed since the previous hit")
+	breakCmd.Flags().StringVar(&breakAssign, "assign", "", "Break at every statement that writes this variable or field")
+	breakCmd.Flags().StringSliceVar(&breakChanOps, "chan-op", nil, "Break on these operations (send, recv, close) on the --expr channel")
+	breakCmd.Flags().StringVar(&breakExpr, "expr", "", "Channel expression for --chan-op")
+	breakCmd.Flags().BoolVar(&breakDump, "dump-goroutines", false, "Record a goroutine summary at each hit instead of stopping continue")
+	breakCmd.Flags().BoolVar(&breakOnPanic, "on-panic", false, "Stop on unrecovered panics and fatal runtime errors (same as panics on)")
+	breakCmd.Flags().StringVar(&breakDumpFilter, "dump-filter", "", "Only summarize goroutines whose user location contains this text (implies --dump-goroutines)")
+	breakCmd.Flags().IntVar(&breakStacktrace, "stacktrace", 0, "Record the call path (this many frames) at each hit instead of stopping continue")
+	breakCmd.Flags().StringVar(&breakRegex, "regex", "", "Break at the entry of every function whose name matches this regexp")
+	breakCmd.Flags().StringVar(&breakType, "type", "", "Break at the entry of every method of this type")
+	breakCmd.Flags().BoolVar(&breakSuspended, "suspended", false, "Keep the breakpoint if the location is not found and set it when a plugin providing it is loaded")
 
 	// clear
 	clearCmd := &cobra.Command{
@@ -535,6 +1068,7 @@ func addBreakpointCommands(root *cobra.Command, mustGetClient func(string) *debu
 				output.Error("breakpoints", err).PrintAndExit(getOutputFormat())
 			}
 
+			dumps := loadDumpBreakpoints(c.Addr())
 			breakpoints := make([]map[string]any, 0, len(bps))
 			for _, bp := range bps {
 				if bp.ID < 0 {
@@ -551,9 +1085,27 @@ func addBreakpointCommands(root *cobra.Command, mustGetClient func(string) *debu
 				if bp.Cond != "" {
 					bpData["condition"] = bp.Cond
 				}
+				if bp.HitCond != "" {
+					bpData["hitCondition"] = bp.HitCond
+				}
+				if len(bp.Variables) > 0 {
+					bpData["collectDiff"] = bp.Variables
+				}
+				if bp.Stacktrace > 0 {
+					bpData["stacktrace"] = bp.Stacktrace
+				}
+				if d, ok := dumps[bp.ID]; ok {
+					bpData["dumpGoroutines"] = true
+					if d.Filter != "" {
+						bpData["dumpFilter"] = d.Filter
+					}
+				}
 				if bp.TotalHitCount > 0 {
 					bpData["hitCount"] = bp.TotalHitCount
 				}
+				if len(bp.Addrs) == 0 && bp.Addr == 0 {
+					bpData["unresolved"] = true
+				}
 				breakpoints = append(breakpoints, bpData)
 			}
 
@@ -573,11 +1125,18 @@ func addBreakpointCommands(root *cobra.Command, mustGetClient func(string) *debu
 
 // addInspectCommands adds variable inspection commands (locals, args, eval)
 func addInspectCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var localsDeferred, argsDeferred, evalDeferred int
+	var localsGoroutine, argsGoroutine, evalGoroutine int64
+	var evalAllowCalls bool
+	var localsStream bool
+
 	// locals
 	localsCmd := &cobra.Command{
 		Use:   "locals",
 		Short: "Show local variables",
 		Run: func(cmd *cobra.Command, args []string) {
+			validateDeferred("locals", localsDeferred, getOutputFormat)
+
 			c := mustGetClient("locals")
 			defer func() { _ = c.Close() }()
 
@@ -586,26 +1145,49 @@ func addInspectCommands(root *cobra.Command, mustGetClient func(string) *debugge
 				output.Error("locals", err).PrintAndExit(getOutputFormat())
 			}
 
-			if state.SelectedGoroutine == nil {
+			goroutineID, ok := targetGoroutine(state, localsGoroutine)
+			if !ok {
 				output.ErrorWithInfo("locals", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
 			}
 
-			vars, err := c.ListLocalVars(state.SelectedGoroutine.ID, 0, debugger.DefaultLoadConfig())
+			frame := mustSelectedFrame(c, "locals", state, goroutineID, getOutputFormat)
+			cfg := debugger.DefaultLoadConfig()
+			if localsStream {
+				cfg = streamLoadConfig
+			}
+			vars, err := c.ListLocalVarsInScope(deferredScope(goroutineID, frame, localsDeferred), cfg)
 			if err != nil {
 				output.Error("locals", err).PrintAndExit(getOutputFormat())
 			}
 
-			variables := make([]map[string]any, len(vars))
-			for i, v := range vars {
-				variables[i] = variableToMap(v)
+			var data map[string]any
+			if localsStream {
+				data = map[string]any{
+					"count":    len(vars),
+					"nodes":    emitVariables(vars),
+					"streamed": true,
+				}
+			} else {
+				variables := make([]map[string]any, len(vars))
+				for i, v := range vars {
+					variables[i] = variableToMap(v)
+				}
+				data = map[string]any{
+					"variables": variables,
+					"count":     len(variables),
+				}
 			}
-
-			data := map[string]any{
-				"variables": variables,
-				"count":     len(variables),
+			if localsGoroutine > 0 {
+				data["goroutineId"] = goroutineID
+			}
+			if frame > 0 {
+				data["frame"] = frame
+			}
+			if localsDeferred > 0 {
+				data["deferredCall"] = localsDeferred
 			}
 
-			output.Success("locals", data, fmt.Sprintf("%d local variables", len(variables))).PrintAndExit(getOutputFormat())
+			output.Success("locals", data, fmt.Sprintf("%d local variables", len(vars))).PrintAndExit(getOutputFormat())
 		},
 	}
 
@@ -614,6 +1196,8 @@ func addInspectCommands(root *cobra.Command, mustGetClient func(string) *debugge
 		Use:   "args",
 		Short: "Show function arguments",
 		Run: func(cmd *cobra.Command, args []string) {
+			validateDeferred("args", argsDeferred, getOutputFormat)
+
 			c := mustGetClient("args")
 			defer func() { _ = c.Close() }()
 
@@ -622,11 +1206,13 @@ func addInspectCommands(root *cobra.Command, mustGetClient func(string) *debugge
 				output.Error("args", err).PrintAndExit(getOutputFormat())
 			}
 
-			if state.SelectedGoroutine == nil {
+			goroutineID, ok := targetGoroutine(state, argsGoroutine)
+			if !ok {
 				output.ErrorWithInfo("args", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
 			}
 
-			funcArgs, err := c.ListFunctionArgs(state.SelectedGoroutine.ID, 0, debugger.DefaultLoadConfig())
+			frame := mustSelectedFrame(c, "args", state, goroutineID, getOutputFormat)
+			funcArgs, err := c.ListFunctionArgsInScope(deferredScope(goroutineID, frame, argsDeferred), debugger.DefaultLoadConfig())
 			if err != nil {
 				output.Error("args", err).PrintAndExit(getOutputFormat())
 			}
@@ -640,6 +1226,15 @@ func addInspectCommands(root *cobra.Command, mustGetClient func(string) *debugge
 				"arguments": arguments,
 				"count":     len(arguments),
 			}
+			if argsGoroutine > 0 {
+				data["goroutineId"] = goroutineID
+			}
+			if frame > 0 {
+				data["frame"] = frame
+			}
+			if argsDeferred > 0 {
+				data["deferredCall"] = argsDeferred
+			}
 
 			output.Success("args", data, fmt.Sprintf("%d arguments", len(arguments))).PrintAndExit(getOutputFormat())
 		},
@@ -651,6 +1246,8 @@ func addInspectCommands(root *cobra.Command, mustGetClient func(string) *debugge
 		Short: "Evaluate an expression",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			validateDeferred("eval", evalDeferred, getOutputFormat)
+
 			c := mustGetClient("eval")
 			defer func() { _ = c.Close() }()
 
@@ -661,22 +1258,77 @@ func addInspectCommands(root *cobra.Command, mustGetClient func(string) *debugge
 				output.Error("eval", err).PrintAndExit(getOutputFormat())
 			}
 
-			if state.SelectedGoroutine == nil {
+			goroutineID, ok := targetGoroutine(state, evalGoroutine)
+			if !ok {
 				output.ErrorWithInfo("eval", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
 			}
 
-			result, err := c.Eval(state.SelectedGoroutine.ID, 0, expr, debugger.DefaultLoadConfig())
+			frame := mustSelectedFrame(c, "eval", state, goroutineID, getOutputFormat)
+			if evalAllowCalls && exprHasCalls(expr) {
+				if frame > 0 || evalDeferred > 0 {
+					output.ErrorWithInfo("eval", output.InvalidArgumentWithDetails(
+						"--allow-calls runs calls in the goroutine's topmost frame; select frame 0 and drop --deferred",
+						map[string]any{"frame": frame, "deferred": evalDeferred},
+					)).PrintAndExit(getOutputFormat())
+				}
+				data, err := evalWithCalls(c, goroutineID, expr)
+				if err != nil {
+					output.Error("eval", err).PrintAndExit(getOutputFormat())
+				}
+				if evalGoroutine > 0 {
+					data["goroutineId"] = goroutineID
+				}
+				msg := ""
+				if data["sideEffects"] == sideEffectsPossible {
+					msg = "the expression called functions that may have changed program state"
+				}
+				output.Success("eval", data, msg).PrintAndExit(getOutputFormat())
+			}
+
+			scope := deferredScope(goroutineID, frame, evalDeferred)
+			result, err := c.EvalInScope(scope, expr, debugger.DefaultLoadConfig())
 			if err != nil {
+				if isCallNotAllowed(err) {
+					output.ErrorWithInfo("eval", callsNotAllowedError(expr)).PrintAndExit(getOutputFormat())
+				}
+				if info := nilPathError(c, scope, expr); info != nil {
+					output.ErrorWithInfo("eval", info).PrintAndExit(getOutputFormat())
+				}
 				output.Error("eval", err).PrintAndExit(getOutputFormat())
 			}
+			// A field behind a nil pointer can come back unreadable instead of failing
+			if result.Unreadable != "" {
+				if info := nilPathError(c, scope, expr); info != nil {
+					output.ErrorWithInfo("eval", info).PrintAndExit(getOutputFormat())
+				}
+			}
 
 			data := variableToMap(*result)
 			data["expression"] = expr
+			if evalGoroutine > 0 {
+				data["goroutineId"] = goroutineID
+			}
+			if frame > 0 {
+				data["frame"] = frame
+			}
+			if evalDeferred > 0 {
+				data["deferredCall"] = evalDeferred
+			}
 
 			output.Success("eval", data, "").PrintAndExit(getOutputFormat())
 		},
 	}
 
+	localsCmd.Flags().IntVar(&localsDeferred, "deferred", 0, "Inspect the scope of the Nth deferred call of the frame")
+	argsCmd.Flags().IntVar(&argsDeferred, "deferred", 0, "Inspect the scope of the Nth deferred call of the frame")
+	evalCmd.Flags().IntVar(&evalDeferred, "deferred", 0, "Evaluate in the scope of the Nth deferred call of the frame")
+
+	localsCmd.Flags().Int64Var(&localsGoroutine, "goroutine", 0, "Goroutine to inspect without switching (default: selected)")
+	localsCmd.Flags().BoolVar(&localsStream, "stream", false, "Emit the variable trees as NDJSON node records, loading deeper")
+	argsCmd.Flags().Int64Var(&argsGoroutine, "goroutine", 0, "Goroutine to inspect without switching (default: selected)")
+	evalCmd.Flags().Int64Var(&evalGoroutine, "goroutine", 0, "Goroutine to evaluate in without switching (default: selected)")
+	evalCmd.Flags().BoolVar(&evalAllowCalls, "allow-calls", false, "Allow the expression to call functions in the target (may change its state)")
+
 	root.AddCommand(localsCmd)
 	root.AddCommand(argsCmd)
 	root.AddCommand(evalCmd)
@@ -685,6 +1337,16 @@ func addInspectCommands(root *cobra.Command, mustGetClient func(string) *debugge
 // addNavigationCommands adds stack and goroutine navigation commands
 func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
 	var stackDepth int
+	var stackGoroutine int64
+	var stackBlame bool
+	var stackFull bool
+	var goroutineDepth int
+	var goroutinesBlockedOn string
+	var goroutinesLabels []string
+	var goroutinesState, goroutinesWaitReason string
+	var goroutinesWaitingOver time.Duration
+	var goroutinesGroupBy string
+	var goroutinesDepth int
 
 	// stack
 	stackCmd := &cobra.Command{
@@ -699,39 +1361,30 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 				output.Error("stack", err).PrintAndExit(getOutputFormat())
 			}
 
-			if state.SelectedGoroutine == nil {
+			goroutineID, ok := targetGoroutine(state, stackGoroutine)
+			if !ok {
 				output.ErrorWithInfo("stack", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
 			}
 
-			cfg := debugger.DefaultLoadConfig()
-			frames, err := c.Stacktrace(state.SelectedGoroutine.ID, stackDepth, &cfg)
+			stackLoad := goroutineStackData
+			if stackFull {
+				stackLoad = fullStackData
+			}
+			data, err := stackLoad(c, goroutineID, stackDepth)
 			if err != nil {
 				output.Error("stack", err).PrintAndExit(getOutputFormat())
 			}
-
-			stackFrames := make([]map[string]any, len(frames))
-			for i, frame := range frames {
-				frameData := map[string]any{
-					"index": i,
-					"file":  frame.File,
-					"line":  frame.Line,
-				}
-				if frame.Function != nil {
-					frameData["function"] = frame.Function.Name()
-				}
-				stackFrames[i] = frameData
-			}
-
-			data := map[string]any{
-				"frames":      stackFrames,
-				"count":       len(stackFrames),
-				"goroutineId": state.SelectedGoroutine.ID,
+			if stackBlame {
+				addFramesBlame(data["frames"].([]map[string]any))
 			}
 
-			output.Success("stack", data, fmt.Sprintf("%d frames", len(stackFrames))).PrintAndExit(getOutputFormat())
+			output.Success("stack", data, fmt.Sprintf("%d frames", data["count"])).PrintAndExit(getOutputFormat())
 		},
 	}
 	stackCmd.Flags().IntVar(&stackDepth, "depth", 50, "Maximum stack depth")
+	stackCmd.Flags().Int64Var(&stackGoroutine, "goroutine", 0, "Goroutine to inspect without switching (default: selected)")
+	stackCmd.Flags().BoolVar(&stackBlame, "blame", false, "Annotate frames with git blame for their file:line")
+	stackCmd.Flags().BoolVar(&stackFull, "full", false, "Include each frame's arguments and locals")
 
 	// frame
 	frameCmd := &cobra.Command{
@@ -755,31 +1408,7 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 				output.Error("frame", err).PrintAndExit(getOutputFormat())
 			}
 
-			if state.SelectedGoroutine == nil {
-				output.ErrorWithInfo("frame", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
-			}
-
-			cfg := debugger.DefaultLoadConfig()
-			frames, err := c.Stacktrace(state.SelectedGoroutine.ID, frameIdx+1, &cfg)
-			if err != nil {
-				output.Error("frame", err).PrintAndExit(getOutputFormat())
-			}
-
-			if frameIdx >= len(frames) {
-				output.ErrorWithInfo("frame", output.NotFound("frame", fmt.Sprintf("%d (stack has %d frames)", frameIdx, len(frames)))).PrintAndExit(getOutputFormat())
-			}
-
-			frame := frames[frameIdx]
-			data := map[string]any{
-				"index": frameIdx,
-				"file":  frame.File,
-				"line":  frame.Line,
-			}
-			if frame.Function != nil {
-				data["function"] = frame.Function.Name()
-			}
-
-			output.Success("frame", data, fmt.Sprintf("Switched to frame %d", frameIdx)).PrintAndExit(getOutputFormat())
+			switchFrame(c, "frame", state, frameIdx, getOutputFormat)
 		},
 	}
 
@@ -788,10 +1417,25 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 		Use:   "goroutines",
 		Short: "List all goroutines",
 		Run: func(cmd *cobra.Command, args []string) {
+			labels, info := parseLabelFilters(goroutinesLabels)
+			if info != nil {
+				output.ErrorWithInfo("goroutines", info).PrintAndExit(getOutputFormat())
+			}
+			filter, info := parseStateFilter(goroutinesState, goroutinesWaitReason, goroutinesWaitingOver)
+			if info != nil {
+				output.ErrorWithInfo("goroutines", info).PrintAndExit(getOutputFormat())
+			}
+			if goroutinesGroupBy != "" && goroutinesGroupBy != groupByStack {
+				output.ErrorWithInfo("goroutines", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid --group-by: %s", goroutinesGroupBy),
+					map[string]any{"groupBy": goroutinesGroupBy, "valid": []string{groupByStack}},
+				)).PrintAndExit(getOutputFormat())
+			}
+
 			c := mustGetClient("goroutines")
 			defer func() { _ = c.Close() }()
 
-			goroutines, _, err := c.ListGoroutines(0, 0)
+			goroutines, _, err := c.ListGoroutinesFiltered(0, 0, filter.delveFilters())
 			if err != nil {
 				output.Error("goroutines", err).PrintAndExit(getOutputFormat())
 			}
@@ -802,8 +1446,35 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 				selectedID = state.SelectedGoroutine.ID
 			}
 
-			gs := make([]map[string]any, len(goroutines))
-			for i, g := range goroutines {
+			var blockedOn string
+			if goroutinesBlockedOn != "" {
+				blockedOn, err = resolveWaitAddr(c, state, goroutinesBlockedOn)
+				if err != nil {
+					output.Error("goroutines", err).PrintAndExit(getOutputFormat())
+				}
+			}
+			ver := targetGoVersion(c)
+			if filter.WaitingOver > 0 {
+				if filter.now = runtimeClock(c); filter.now == 0 {
+					output.ErrorWithInfo("goroutines", output.NewErrorInfo(output.ErrCodeEvalFailed,
+						"cannot measure wait durations: the runtime's clock readings are not readable")).PrintAndExit(getOutputFormat())
+				}
+			}
+
+			gs := make([]map[string]any, 0, len(goroutines))
+			var matched []*api.Goroutine
+			for _, g := range goroutines {
+				if !matchesLabels(g.Labels, labels) {
+					continue
+				}
+				if !filter.matches(g, waitReasonName(ver, g.WaitReason)) {
+					continue
+				}
+				// Groups need no per-goroutine details, whose wait lookups are slow
+				if goroutinesGroupBy != "" && blockedOn == "" {
+					matched = append(matched, g)
+					continue
+				}
 				gData := map[string]any{
 					"id":       g.ID,
 					"selected": g.ID == selectedID,
@@ -822,7 +1493,18 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 						"function": g.UserCurrentLoc.Function.Name(),
 					}
 				}
-				gs[i] = gData
+				if len(g.Labels) > 0 {
+					gData["labels"] = g.Labels
+				}
+				if waited, ok := filter.waited(g); ok {
+					gData["waitingFor"] = waited.Round(time.Millisecond).String()
+				}
+				objs := annotateWait(c, ver, g, gData)
+				if blockedOn != "" && !waitsOn(objs, blockedOn) {
+					continue
+				}
+				matched = append(matched, g)
+				gs = append(gs, gData)
 			}
 
 			data := map[string]any{
@@ -832,16 +1514,46 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 			if selectedID > 0 {
 				data["selectedId"] = selectedID
 			}
+			if blockedOn != "" {
+				data["blockedOn"] = blockedOn
+			}
+			if len(goroutinesLabels) > 0 {
+				data["labels"] = goroutinesLabels
+			}
+			msg := fmt.Sprintf("%d goroutines", len(gs))
+			if goroutinesGroupBy == groupByStack {
+				groups, unreadable := goroutineStackGroups(c, ver, matched, goroutinesDepth)
+				delete(data, "goroutines")
+				data["count"] = len(matched)
+				data["groupBy"] = groupByStack
+				data["groups"] = groups
+				if unreadable > 0 {
+					data["unreadable"] = unreadable
+				}
+				msg = fmt.Sprintf("%d goroutines in %d stack groups", len(matched), len(groups))
+			}
+			if filter.active() {
+				data["total"] = len(goroutines)
+				if filter.State != "" {
+					data["state"] = filter.State
+				}
+				if filter.WaitReason != "" {
+					data["waitReason"] = filter.WaitReason
+				}
+				if filter.WaitingOver > 0 {
+					data["waitingMoreThan"] = filter.WaitingOver.String()
+				}
+			}
 
-			output.Success("goroutines", data, fmt.Sprintf("%d goroutines", len(gs))).PrintAndExit(getOutputFormat())
+			output.Success("goroutines", data, msg).PrintAndExit(getOutputFormat())
 		},
 	}
 
 	// goroutine
 	goroutineCmd := &cobra.Command{
-		Use:   "goroutine <id>",
+		Use:   "goroutine <id> [stack]",
 		Short: "Switch to a goroutine",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.RangeArgs(1, 2),
 		Run: func(cmd *cobra.Command, args []string) {
 			c := mustGetClient("goroutine")
 			defer func() { _ = c.Close() }()
@@ -854,6 +1566,21 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 				)).PrintAndExit(getOutputFormat())
 			}
 
+			// "goroutine <id> stack" reads the stack without switching
+			if len(args) == 2 {
+				if args[1] != "stack" {
+					output.ErrorWithInfo("goroutine", output.InvalidArgumentWithDetails(
+						fmt.Sprintf("unknown goroutine subcommand: %s (expected stack)", args[1]),
+						map[string]any{"subcommand": args[1]},
+					)).PrintAndExit(getOutputFormat())
+				}
+				data, err := goroutineStackData(c, id, goroutineDepth)
+				if err != nil {
+					output.Error("goroutine", err).PrintAndExit(getOutputFormat())
+				}
+				output.Success("goroutine", data, fmt.Sprintf("Goroutine %d: %d frames", id, data["count"])).PrintAndExit(getOutputFormat())
+			}
+
 			state, err := c.SwitchGoroutine(id)
 			if err != nil {
 				output.Error("goroutine", err).PrintAndExit(getOutputFormat())
@@ -872,12 +1599,24 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 						"function": g.CurrentLoc.Function.Name(),
 					}
 				}
+				if len(g.Labels) > 0 {
+					data["labels"] = g.Labels
+				}
 			}
 
 			output.Success("goroutine", data, fmt.Sprintf("Switched to goroutine %d", id)).PrintAndExit(getOutputFormat())
 		},
 	}
 
+	goroutinesCmd.Flags().StringVar(&goroutinesBlockedOn, "blocked-on", "", "Only goroutines blocked on this channel/mutex (address or expression)")
+	goroutinesCmd.Flags().StringArrayVar(&goroutinesLabels, "label", nil, "Only goroutines with this pprof label, key=value or key (repeatable)")
+	goroutinesCmd.Flags().StringVar(&goroutinesState, "state", "", "Only goroutines in this state: running, runnable, waiting or syscall")
+	goroutinesCmd.Flags().StringVar(&goroutinesWaitReason, "wait-reason", "", "Only goroutines whose wait reason contains this text, e.g. \"chan send\"")
+	goroutinesCmd.Flags().DurationVar(&goroutinesWaitingOver, "waiting-more-than", 0, "Only goroutines the runtime has seen waiting for at least this long")
+	goroutinesCmd.Flags().StringVar(&goroutinesGroupBy, "group-by", "", "Collapse goroutines: stack groups those with identical user-code stacks")
+	goroutinesCmd.Flags().IntVar(&goroutinesDepth, "depth", 20, "Frames read per goroutine with --group-by stack")
+	goroutineCmd.Flags().IntVar(&goroutineDepth, "depth", 50, "Maximum stack depth for goroutine <id> stack")
+
 	root.AddCommand(stackCmd)
 	root.AddCommand(frameCmd)
 	root.AddCommand(goroutinesCmd)
@@ -887,6 +1626,9 @@ func addNavigationCommands(root *cobra.Command, mustGetClient func(string) *debu
 // addSourceCommands adds source viewing commands (list, sources)
 func addSourceCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
 	var listContext int
+	var listBlame bool
+	var sourcesTree bool
+	var sourcesPackage string
 
 	// list
 	listCmd := &cobra.Command{
@@ -910,8 +1652,9 @@ func addSourceCommands(root *cobra.Command, mustGetClient func(string) *debugger
 				output.ErrorWithInfo("list", output.NotFound("source location", "none available")).PrintAndExit(getOutputFormat())
 			}
 
-			// Read the source file
-			file, err := os.Open(loc.File)
+			// Read the source file, from the local checkout if the binary was built elsewhere
+			local := localSourceFile(c, loc.File)
+			file, err := os.Open(local)
 			if err != nil {
 				output.ErrorWithInfo("list", output.NotFound("source file", loc.File)).PrintAndExit(getOutputFormat())
 			}
@@ -948,12 +1691,18 @@ func addSourceCommands(root *cobra.Command, mustGetClient func(string) *debugger
 			if err := scanner.Err(); err != nil {
 				output.Error("list", err).PrintAndExit(getOutputFormat())
 			}
+			if listBlame {
+				addLinesBlame(local, lines)
+			}
 
 			data := map[string]any{
 				"file":        loc.File,
 				"currentLine": loc.Line,
 				"lines":       lines,
 			}
+			if local != loc.File {
+				data["localFile"] = local
+			}
 			if loc.Function != nil {
 				data["function"] = loc.Function.Name()
 			}
@@ -962,6 +1711,7 @@ func addSourceCommands(root *cobra.Command, mustGetClient func(string) *debugger
 		},
 	}
 	listCmd.Flags().IntVar(&listContext, "context", 5, "Lines of context before and after")
+	listCmd.Flags().BoolVar(&listBlame, "blame", false, "Annotate lines with git blame")
 
 	// sources
 	sourcesCmd := &cobra.Command{
@@ -976,32 +1726,16 @@ func addSourceCommands(root *cobra.Command, mustGetClient func(string) *debugger
 				filter = args[0]
 			}
 
-			sources, err := c.ListSources(filter)
+			data, err := sourcesData(c, filter, sourcesPackage, sourcesTree)
 			if err != nil {
 				output.Error("sources", err).PrintAndExit(getOutputFormat())
 			}
 
-			// Filter out runtime/internal sources for cleaner output
-			var filtered []string
-			for _, src := range sources {
-				// Skip standard library and internal paths
-				if strings.Contains(src, "/go/src/") ||
-					strings.Contains(src, "/runtime/") ||
-					strings.HasPrefix(src, "<") {
-					continue
-				}
-				filtered = append(filtered, src)
-			}
-
-			data := map[string]any{
-				"sources": filtered,
-				"count":   len(filtered),
-				"total":   len(sources),
-			}
-
-			output.Success("sources", data, fmt.Sprintf("%d source files", len(filtered))).PrintAndExit(getOutputFormat())
+			output.Success("sources", data, fmt.Sprintf("%d source files", data["count"])).PrintAndExit(getOutputFormat())
 		},
 	}
+	sourcesCmd.Flags().BoolVar(&sourcesTree, "tree", false, "Show sources as a directory tree")
+	sourcesCmd.Flags().StringVar(&sourcesPackage, "package", "", "Only sources of this import path (PATH/... includes subpackages)")
 
 	root.AddCommand(listCmd)
 	root.AddCommand(sourcesCmd)
@@ -1009,21 +1743,17 @@ func addSourceCommands(root *cobra.Command, mustGetClient func(string) *debugger
 
 // addQuitCommand adds the quit command
 func addQuitCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var quitTerminateServer, quitDetachOnly bool
+
 	quitCmd := &cobra.Command{
 		Use:   "quit",
 		Short: "Stop debugging and terminate the debug server",
+		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			c := mustGetClient("quit")
-			// Note: don't defer close, we're detaching
-
-			err := c.Detach(true)
-			if err != nil {
-				output.Error("quit", err).PrintAndExit(getOutputFormat())
-			}
-
-			output.Success("quit", nil, "Debug session terminated").PrintAndExit(getOutputFormat())
+			runQuit(mustGetClient, getOutputFormat, quitTerminateServer, quitDetachOnly)
 		},
 	}
 
+	addQuitFlags(quitCmd, &quitTerminateServer, &quitDetachOnly)
 	root.AddCommand(quitCmd)
 }
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/scenario"
+)
+
+// scenarioStepResult is the outcome of one step of a scenario
+type scenarioStepResult struct {
+	Name       string   `json:"name"`
+	Command    []string `json:"command"`
+	OK         bool     `json:"ok"`
+	Skipped    bool     `json:"skipped,omitempty"`
+	DurationMs int64    `json:"durationMs"`
+	Failures   []string `json:"failures,omitempty"`
+	// Response is the command's response, kept only when the step failed
+	Response any `json:"response,omitempty"`
+}
+
+// scenarioResult is the outcome of one scenario file
+type scenarioResult struct {
+	Name       string               `json:"name"`
+	File       string               `json:"file"`
+	OK         bool                 `json:"ok"`
+	Passed     int                  `json:"passed"`
+	Failed     int                  `json:"failed"`
+	Skipped    int                  `json:"skipped"`
+	DurationMs int64                `json:"durationMs"`
+	Error      string               `json:"error,omitempty"`
+	Steps      []scenarioStepResult `json:"steps"`
+}
+
+// runScenarioCommand runs one godebug invocation against addr and decodes its
+// JSON response. Streaming commands emit one object per line; the last one
+// is the response.
+func runScenarioCommand(self, addr string, timeout time.Duration, args []string) (any, error) {
+	// The command's own --timeout bounds its RPCs; this bounds a hung process
+	ctx, cancel := context.WithTimeout(context.Background(), 2*timeout+10*time.Second)
+	defer cancel()
+	full := append([]string{"--addr", addr, "--output", "json", "--timeout", timeout.String()}, args...)
+	var stdout, stderr bytes.Buffer
+	run := exec.CommandContext(ctx, self, full...)
+	run.Stdout = &stdout
+	run.Stderr = &stderr
+	err := run.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	last := lines[len(lines)-1]
+	var resp any
+	if err := json.Unmarshal([]byte(last), &resp); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = last
+		}
+		return nil, fmt.Errorf("no JSON response: %s", msg)
+	}
+	return resp, nil
+}
+
+// checkStep returns the expectations resp does not meet
+func checkStep(step scenario.Step, resp any) []string {
+	var failures []string
+	if len(step.Expect) == 0 {
+		if ok, _, _ := scenario.Lookup(resp, "success"); ok != true {
+			msg, _, _ := scenario.Lookup(resp, "error.message")
+			failures = append(failures, fmt.Sprintf("command failed: %v", msg))
+		}
+		return failures
+	}
+	for _, path := range step.Paths() {
+		v, found, err := scenario.Lookup(resp, path)
+		if err == nil {
+			err = step.Expect[path].Check(v, found)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	return failures
+}
+
+// launchScenario starts the debug session of sc and returns it with the
+// directory its sources were compiled from
+func launchScenario(sc *scenario.Scenario, workDir string, timeout time.Duration) (*debugger.LaunchResult, string, error) {
+	target, err := filepath.Abs(sc.TargetPath())
+	if err != nil {
+		return nil, "", err
+	}
+	cfg := debugger.LaunchConfig{
+		Mode:       debugger.LaunchMode(sc.Launch.Mode),
+		Target:     target,
+		Args:       sc.Launch.Args,
+		BuildFlags: sc.Launch.BuildFlags,
+		Timeout:    timeout,
+	}
+	dir := target
+	if sc.Launch.Standalone {
+		binary, err := buildEmbeddedProgram(os.DirFS(target), workDir)
+		if err != nil {
+			return nil, "", fmt.Errorf("build %s: %w", target, err)
+		}
+		cfg.Mode, cfg.Target, dir = debugger.ModeExec, binary, workDir
+	} else if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		dir = filepath.Dir(target)
+	}
+	result, err := debugger.Launch(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, dir, nil
+}
+
+// runScenario launches the scenario's target, runs its steps and terminates
+// the session. Steps after a failing one are skipped unless keepGoing is set.
+func runScenario(sc *scenario.Scenario, self string, timeout time.Duration, keepGoing bool) (res scenarioResult) {
+	res = scenarioResult{Name: sc.Name, File: sc.File, Steps: []scenarioStepResult{}}
+	start := time.Now()
+	defer func() { res.DurationMs = time.Since(start).Milliseconds() }()
+
+	workDir, err := os.MkdirTemp("", "godebug-scenario-")
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	result, dir, err := launchScenario(sc, workDir, timeout)
+	if err != nil {
+		res.Error = fmt.Sprintf("launch: %v", err)
+		return res
+	}
+	defer func() {
+		if c, err := debugger.Connect(result.Addr); err == nil {
+			_ = c.Detach(true)
+			_ = c.Close()
+		}
+		_ = result.Kill()
+	}()
+	recordLaunchSession(result)
+
+	vars := map[string]string{
+		"addr":     result.Addr,
+		"dir":      dir,
+		"scenario": filepath.Dir(sc.File),
+	}
+	failed := false
+	for _, step := range sc.Steps {
+		sr := scenarioStepResult{Name: step.Title(), Command: step.Expand(vars)}
+		if failed && !keepGoing {
+			sr.Skipped = true
+			res.Skipped++
+			res.Steps = append(res.Steps, sr)
+			continue
+		}
+		stepStart := time.Now()
+		resp, err := runScenarioCommand(self, result.Addr, timeout, sr.Command)
+		if err != nil {
+			sr.Failures = []string{err.Error()}
+		} else {
+			sr.Failures = checkStep(step, resp)
+		}
+		sr.DurationMs = time.Since(stepStart).Milliseconds()
+		sr.OK = len(sr.Failures) == 0
+		if sr.OK {
+			res.Passed++
+		} else {
+			sr.Response = resp
+			res.Failed++
+			failed = true
+		}
+		res.Steps = append(res.Steps, sr)
+	}
+	res.OK = !failed
+	return res
+}
+
+// addScenarioCommand adds the scenario command and its run subcommand
+func addScenarioCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var keepGoing bool
+
+	scenarioCmd := &cobra.Command{
+		Use:   "scenario",
+		Short: "Run debugger regression scenarios",
+		Long: `Scenarios script a debug session and assert on the responses, to
+regression-test debugging of a program (or godebug itself).
+
+Subcommands:
+  run   Run scenario files and report each step`,
+	}
+
+	runCmd := &cobra.Command{
+		Use:   "run <file.yaml>...",
+		Short: "Run scenario files and check the responses",
+		Long: `Launch the target of each scenario, run its commands in order against the
+session and check fields of each JSON response. The session is terminated
+when the scenario ends. A step whose expectations fail stops its scenario
+unless --keep-going is set.
+
+Scenario format:
+  name: channel_nil                 # defaults to the file name
+  launch:
+    mode: debug                     # debug (default), test or exec
+    target: ../concurrency_bugs/channel_nil   # relative to the file
+    args: []
+    buildFlags: ""
+    standalone: true                # build the directory as its own module (go 1.21)
+  steps:
+    - name: stop at the send        # optional
+      run: break {dir}/main.go:16   # or a list: [eval, "len(xs) + 1"]
+      expect:
+        success: true
+        data.line: 16
+    - run: continue
+      expect:
+        data.location.function: {contains: testChannelSend}
+    - run: eval ch
+      expect:
+        data.type: chan int
+
+Commands run with --addr and --output json added. {dir} is the directory
+the target was compiled from, {addr} the session address and {scenario}
+the scenario file's directory.
+
+Paths select response fields: dots for fields, [n] for array elements and
+[field=value] for the first element whose field has that value. A plain
+expected value must be equal (numbers and strings compare by their text);
+operators are equals, contains, matches (regexp), exists, min, max and len.
+A step without expect passes when the command succeeds.
+
+Example:
+  godebug scenario run testdata/scenarios/*.yaml
+  godebug scenario run my-bug.yaml --keep-going`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			scenarios := make([]*scenario.Scenario, 0, len(args))
+			for _, path := range args {
+				sc, err := scenario.Load(path)
+				if err != nil {
+					output.ErrorWithInfo("scenario run", output.InvalidArgumentWithDetails(
+						fmt.Sprintf("invalid scenario: %v", err),
+						map[string]any{"file": path},
+					)).PrintAndExit(getOutputFormat())
+				}
+				scenarios = append(scenarios, sc)
+			}
+
+			self, err := os.Executable()
+			if err != nil {
+				output.Error("scenario run", err).PrintAndExit(getOutputFormat())
+			}
+
+			start := time.Now()
+			results := make([]scenarioResult, 0, len(scenarios))
+			var failed []string
+			for _, sc := range scenarios {
+				res := runScenario(sc, self, getTimeout(), keepGoing)
+				if !res.OK {
+					failed = append(failed, res.Name)
+				}
+				results = append(results, res)
+			}
+
+			data := map[string]any{
+				"scenarios":  results,
+				"passed":     len(results) - len(failed),
+				"failed":     len(failed),
+				"durationMs": time.Since(start).Milliseconds(),
+			}
+			if len(failed) > 0 {
+				output.ErrorWithInfo("scenario run", output.NewErrorInfo(
+					output.ErrCodeScenarioFailed,
+					fmt.Sprintf("%d of %d scenarios failed: %s", len(failed), len(results), strings.Join(failed, ", ")),
+				).WithDetails(data)).PrintAndExit(getOutputFormat())
+			}
+			output.Success("scenario run", data, fmt.Sprintf("%d scenarios passed", len(results))).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	runCmd.Flags().BoolVar(&keepGoing, "keep-going", false, "Run the remaining steps of a scenario after a failing one")
+	scenarioCmd.AddCommand(runCmd)
+	root.AddCommand(scenarioCmd)
+}
+
+func init() {
+	addScenarioCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/8gears/godebug-agentic/internal/scenario"
+)
+
+// TestScenarioFiles checks that the bundled scenarios load, only run existing
+// commands and cover every concurrency bug program.
+func TestScenarioFiles(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "testdata", "scenarios", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := NewRootCmd()
+	covered := map[string]bool{}
+	for _, file := range files {
+		sc, err := scenario.Load(file)
+		if err != nil {
+			t.Errorf("%v", err)
+			continue
+		}
+		if _, err := os.Stat(sc.TargetPath()); err != nil {
+			t.Errorf("%s: target: %v", file, err)
+		}
+		covered[filepath.Base(sc.TargetPath())] = true
+		for i, step := range sc.Steps {
+			if found, _, err := root.Find(step.Run); err != nil || found == root {
+				t.Errorf("%s: step %d: unknown command %v", file, i+1, step.Run)
+			}
+		}
+	}
+
+	dirs, err := os.ReadDir(filepath.Join("..", "testdata", "concurrency_bugs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range dirs {
+		if !covered[d.Name()] {
+			t.Errorf("no scenario covers concurrency_bugs/%s", d.Name())
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// packageVarsFilter matches the qualified names of pkg's package-level variables
+func packageVarsFilter(pkg string) string {
+	return "^" + regexp.QuoteMeta(pkg) + `\.[^./]+$`
+}
+
+// variablesToMaps converts variables for JSON output
+func variablesToMaps(vars []api.Variable) []map[string]any {
+	out := make([]map[string]any, len(vars))
+	for i, v := range vars {
+		out[i] = variableToMap(v)
+	}
+	return out
+}
+
+// addScopeCommand adds the scope command
+func addScopeCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var scopeGlobals []string
+	var scopeGoroutine int64
+	var scopeDeferred int
+
+	scopeCmd := &cobra.Command{
+		Use:   "scope",
+		Short: "Show arguments, locals and package globals at once",
+		Long: `Dump everything visible at the current stop in one response: the function's
+arguments, its local variables and, with --globals, the package-level
+variables of the given packages, each in its own section.
+
+The selected frame (see frame/up/down) is used, like locals and args.
+
+Example:
+  godebug --addr $ADDR scope
+  godebug --addr $ADDR scope --globals main
+  godebug --addr $ADDR scope --globals main --globals github.com/acme/app/config`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			validateDeferred("scope", scopeDeferred, getOutputFormat)
+
+			c := mustGetClient("scope")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("scope", err).PrintAndExit(getOutputFormat())
+			}
+
+			goroutineID, ok := targetGoroutine(state, scopeGoroutine)
+			if !ok {
+				output.ErrorWithInfo("scope", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+			}
+
+			frame := mustSelectedFrame(c, "scope", state, goroutineID, getOutputFormat)
+			evalScope := deferredScope(goroutineID, frame, scopeDeferred)
+			cfg := debugger.DefaultLoadConfig()
+
+			funcArgs, err := c.ListFunctionArgsInScope(evalScope, cfg)
+			if err != nil {
+				output.Error("scope", err).PrintAndExit(getOutputFormat())
+			}
+			locals, err := c.ListLocalVarsInScope(evalScope, cfg)
+			if err != nil {
+				output.Error("scope", err).PrintAndExit(getOutputFormat())
+			}
+
+			data := map[string]any{
+				"arguments": variablesToMaps(funcArgs),
+				"locals":    variablesToMaps(locals),
+			}
+			globalCount := 0
+			if len(scopeGlobals) > 0 {
+				globals := map[string]any{}
+				for _, pkg := range scopeGlobals {
+					vars, err := c.ListPackageVars(packageVarsFilter(pkg), cfg)
+					if err != nil {
+						output.Error("scope", err).PrintAndExit(getOutputFormat())
+					}
+					if len(vars) == 0 {
+						output.ErrorWithInfo("scope", output.NotFound("package variables", pkg)).PrintAndExit(getOutputFormat())
+					}
+					globals[pkg] = variablesToMaps(vars)
+					globalCount += len(vars)
+				}
+				data["globals"] = globals
+			}
+
+			if scopeGoroutine > 0 {
+				data["goroutineId"] = goroutineID
+			}
+			if frame > 0 {
+				data["frame"] = frame
+			}
+			if scopeDeferred > 0 {
+				data["deferredCall"] = scopeDeferred
+			}
+
+			output.Success("scope", data, fmt.Sprintf("%d arguments, %d locals, %d globals", len(funcArgs), len(locals), globalCount)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	scopeCmd.Flags().StringArrayVar(&scopeGlobals, "globals", nil, "Include the package-level variables of this package (repeatable)")
+	scopeCmd.Flags().Int64Var(&scopeGoroutine, "goroutine", 0, "Goroutine to inspect without switching (default: selected)")
+	scopeCmd.Flags().IntVar(&scopeDeferred, "deferred", 0, "Inspect the scope of the Nth deferred call of the frame")
+	root.AddCommand(scopeCmd)
+}
+
+func init() {
+	addScopeCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
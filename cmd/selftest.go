@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// selftestProgram holds the sources of testdata/debugme, embedded by package main
+var selftestProgram fs.FS
+
+// SetSelftestProgram provides the sources of the program selftest debugs
+func SetSelftestProgram(fsys fs.FS) {
+	selftestProgram = fsys
+}
+
+// selftestStep is the outcome of one step of the self-test
+type selftestStep struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	DurationMs int64  `json:"durationMs"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// selftestRunner runs steps in order and skips the rest after the first failure
+type selftestRunner struct {
+	steps  []selftestStep
+	failed bool
+}
+
+// run executes f as step name unless an earlier step failed
+func (r *selftestRunner) run(name string, f func() (string, error)) {
+	if r.failed {
+		r.steps = append(r.steps, selftestStep{Name: name, Skipped: true})
+		return
+	}
+	start := time.Now()
+	detail, err := f()
+	step := selftestStep{Name: name, OK: err == nil, DurationMs: time.Since(start).Milliseconds(), Detail: detail}
+	if err != nil {
+		step.Error = err.Error()
+		r.failed = true
+	}
+	r.steps = append(r.steps, step)
+}
+
+// buildEmbeddedProgram copies an embedded main package into dir as its own
+// module and builds it for debugging, returning the binary. The module keeps
+// the pre-1.22 loop variable semantics some scenarios depend on.
+func buildEmbeddedProgram(fsys fs.FS, dir string) (string, error) {
+	if fsys == nil {
+		return "", errors.New("program not embedded in this build")
+	}
+	if err := os.CopyFS(dir, fsys); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module debugme\n\ngo 1.21\n"), 0o644); err != nil {
+		return "", err
+	}
+	binary := filepath.Join(dir, filepath.Base(dir))
+	build := exec.Command("go", "build", "-gcflags=all=-N -l", "-o", binary, ".")
+	build.Dir = dir
+	if out, err := build.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.Join(tailLines(string(out), 5), "; "))
+	}
+	return binary, nil
+}
+
+// addSelftestCommand adds the selftest command
+func addSelftestCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	selftestCmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Check that debugging works in this environment",
+		Long: `Build a small bundled Go program, debug it end to end and report each step:
+toolchain and dlv lookup, build, launch, break, continue, args, locals,
+stack, goroutines and quit. Steps after the first failure are skipped.
+
+Run it once before real debugging to tell environment problems (no dlv,
+ptrace not permitted, no Go toolchain) apart from problems in your program.
+
+Example:
+  godebug selftest`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			timeout := getTimeout()
+			r := &selftestRunner{}
+			start := time.Now()
+
+			r.run("dlv", func() (string, error) {
+				return exec.LookPath("dlv")
+			})
+			r.run("go", func() (string, error) {
+				out, err := exec.Command("go", "env", "GOVERSION").Output()
+				return strings.TrimSpace(string(out)), err
+			})
+
+			dir, err := os.MkdirTemp("", "godebug-selftest-")
+			if err != nil {
+				output.Error("selftest", err).PrintAndExit(getOutputFormat())
+			}
+			defer func() { _ = os.RemoveAll(dir) }()
+
+			var binary string
+			r.run("build", func() (string, error) {
+				var err error
+				binary, err = buildEmbeddedProgram(selftestProgram, dir)
+				return binary, err
+			})
+
+			var result *debugger.LaunchResult
+			var c *debugger.Client
+			r.run("launch", func() (string, error) {
+				var err error
+				result, err = debugger.Launch(debugger.LaunchConfig{Mode: debugger.ModeExec, Target: binary, Timeout: timeout})
+				if err != nil {
+					return "", err
+				}
+				if c, err = debugger.Connect(result.Addr); err != nil {
+					return "", err
+				}
+				c.SetTimeout(timeout)
+				return result.Addr, nil
+			})
+			defer func() {
+				if c != nil {
+					_ = c.Close()
+				}
+				if result != nil {
+					_ = result.Kill()
+				}
+			}()
+
+			r.run("break", func() (string, error) {
+				bp, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.innerFunc"})
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("breakpoint %d at %s:%d", bp.ID, filepath.Base(bp.File), bp.Line), nil
+			})
+
+			var state *api.DebuggerState
+			r.run("continue", func() (string, error) {
+				var err error
+				if state, err = c.Continue(); err != nil {
+					return "", err
+				}
+				if state.Exited {
+					return "", fmt.Errorf("program exited with status %d before reaching the breakpoint", state.ExitStatus)
+				}
+				if state.SelectedGoroutine == nil || state.SelectedGoroutine.CurrentLoc.Function.Name() != "main.innerFunc" {
+					return "", errors.New("did not stop in main.innerFunc")
+				}
+				return fmt.Sprintf("stopped at line %d", state.SelectedGoroutine.CurrentLoc.Line), nil
+			})
+
+			r.run("args", func() (string, error) {
+				vars, err := c.ListFunctionArgs(state.SelectedGoroutine.ID, 0, debugger.DefaultLoadConfig())
+				if err != nil {
+					return "", err
+				}
+				// main calls outerFunc(10) -> middleFunc(20) -> innerFunc(25)
+				for _, v := range vars {
+					if v.Name == "x" {
+						if v.Value != "25" {
+							return "", fmt.Errorf("x = %s, want 25", v.Value)
+						}
+						return "x = 25", nil
+					}
+				}
+				return "", errors.New("argument x not found")
+			})
+
+			r.run("locals", func() (string, error) {
+				vars, err := c.ListLocalVars(state.SelectedGoroutine.ID, 0, debugger.DefaultLoadConfig())
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d locals", len(vars)), nil
+			})
+
+			r.run("stack", func() (string, error) {
+				frames, err := c.Stacktrace(state.SelectedGoroutine.ID, 10, nil)
+				if err != nil {
+					return "", err
+				}
+				var names []string
+				for _, f := range frames {
+					if f.Function != nil {
+						names = append(names, f.Function.Name())
+					}
+				}
+				for _, want := range []string{"main.middleFunc", "main.outerFunc", "main.main"} {
+					if !slices.Contains(names, want) {
+						return "", fmt.Errorf("%s missing from stack %v", want, names)
+					}
+				}
+				return fmt.Sprintf("%d frames", len(frames)), nil
+			})
+
+			r.run("goroutines", func() (string, error) {
+				goroutines, _, err := c.ListGoroutines(0, 0)
+				if err != nil {
+					return "", err
+				}
+				if len(goroutines) == 0 {
+					return "", errors.New("no goroutines listed")
+				}
+				return fmt.Sprintf("%d goroutines", len(goroutines)), nil
+			})
+
+			r.run("quit", func() (string, error) {
+				return "", c.Detach(true)
+			})
+
+			passed := 0
+			for _, s := range r.steps {
+				if s.OK {
+					passed++
+				}
+			}
+			data := map[string]any{
+				"steps":      r.steps,
+				"passed":     passed,
+				"total":      len(r.steps),
+				"durationMs": time.Since(start).Milliseconds(),
+			}
+
+			if r.failed {
+				failed := r.steps[passed]
+				output.ErrorWithInfo("selftest", output.InternalError(
+					fmt.Sprintf("selftest failed at step %s: %s", failed.Name, failed.Error),
+				).WithDetails(data)).PrintAndExit(getOutputFormat())
+			}
+			output.Success("selftest", data, fmt.Sprintf("selftest passed: %d/%d steps", passed, len(r.steps))).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	root.AddCommand(selftestCmd)
+}
+
+func init() {
+	addSelftestCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
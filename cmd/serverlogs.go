@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// serverLogFile is the name of Delve's log inside the session directory
+const serverLogFile = "server.log"
+
+// defaultServerLogOutput are the Delve log components start captures
+const defaultServerLogOutput = "debugger,rpc"
+
+// serverLogLines is how much of the log a failed launch reports
+const serverLogLines = 20
+
+// enableServerLog points Delve's log at a file. The session directory does not
+// exist before the server address is known, so the log starts in an output
+// directory and adoptServerLog moves it.
+func enableServerLog(config *debugger.LaunchConfig, components string) error {
+	if components == "" {
+		return nil
+	}
+	dir, err := session.NewOutputDir()
+	if err != nil {
+		return err
+	}
+	config.LogFile = filepath.Join(dir, serverLogFile)
+	config.LogOutput = components
+	return nil
+}
+
+// adoptServerLog moves the log of a launched server into its session directory
+// and records it with the session. Delve keeps writing to the moved file; if
+// it cannot be moved, the log stays where it is.
+func adoptServerLog(result *debugger.LaunchResult) string {
+	if result.LogFile == "" {
+		return ""
+	}
+	path := result.LogFile
+	if dir, err := session.Dir(result.Addr); err == nil && os.MkdirAll(dir, 0o755) == nil {
+		dst := filepath.Join(dir, serverLogFile)
+		if os.Rename(path, dst) == nil {
+			_ = os.Remove(filepath.Dir(path))
+			path = dst
+		}
+	}
+	if s, err := session.Load(result.Addr); err == nil {
+		s.ServerLog = path
+		_ = s.Save()
+	}
+	return path
+}
+
+// launchFailure adds the tail of Delve's log to a failed launch's error
+func launchFailure(config debugger.LaunchConfig, err error) *output.ErrorInfo {
+	info := output.FromError(err)
+	if config.LogFile == "" {
+		return info
+	}
+	if tail := tailLines(config.LogFile, serverLogLines); len(tail) > 0 {
+		details, _ := info.Details.(map[string]any)
+		if details == nil {
+			details = map[string]any{}
+		}
+		details["serverLog"] = config.LogFile
+		details["serverLogTail"] = tail
+		info = info.WithDetails(details)
+	}
+	return info
+}
+
+// addServerLogsCommand adds the server-logs command
+func addServerLogsCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat) {
+	var logsTail int
+	var logsGrep string
+
+	serverLogsCmd := &cobra.Command{
+		Use:   "server-logs [session]",
+		Short: "Show the Delve server's own log",
+		Long: `Show the log Delve wrote for a session started with start: its debugger
+and rpc components by default (start --server-log selects others). When RPCs
+behave strangely, time out or return odd errors, the server side of the
+story is here.
+
+The session is the --addr address or a session ID. The log is read from the
+session directory, so it is available after the server died.
+
+Example:
+  godebug --addr $ADDR server-logs --tail 50
+  godebug server-logs 127.0.0.1_40213 --grep 'error|panic'`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			addr := cmd.Flag("addr").Value.String()
+			if len(args) == 1 {
+				addr = args[0]
+			}
+			if addr == "" {
+				output.ErrorWithInfo("server-logs", output.InvalidArgument("--addr flag or a session ID is required")).PrintAndExit(getOutputFormat())
+			}
+			if logsTail < 0 {
+				output.ErrorWithInfo("server-logs", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid --tail: %d (must be >= 0)", logsTail),
+					map[string]any{"tail": logsTail},
+				)).PrintAndExit(getOutputFormat())
+			}
+			var filter *regexp.Regexp
+			if logsGrep != "" {
+				re, err := regexp.Compile(logsGrep)
+				if err != nil {
+					output.ErrorWithInfo("server-logs", output.InvalidArgumentWithDetails(
+						fmt.Sprintf("invalid --grep regexp: %v", err),
+						map[string]any{"grep": logsGrep},
+					)).PrintAndExit(getOutputFormat())
+				}
+				filter = re
+			}
+
+			s, err := session.Load(addr)
+			if err != nil {
+				output.Error("server-logs", err).PrintAndExit(getOutputFormat())
+			}
+			if s.ServerLog == "" {
+				output.ErrorWithInfo("server-logs", output.NotFound("server log", addr).WithDetails(map[string]any{
+					"hint": "only sessions launched by start record Delve's log",
+				})).PrintAndExit(getOutputFormat())
+			}
+			if _, err := os.Stat(s.ServerLog); err != nil {
+				output.ErrorWithInfo("server-logs", output.NotFound("server log", s.ServerLog)).PrintAndExit(getOutputFormat())
+			}
+
+			// Read everything when filtering, so --tail counts matching lines
+			lines := tailLines(s.ServerLog, math.MaxInt)
+			total := len(lines)
+			if filter != nil {
+				matched := lines[:0]
+				for _, line := range lines {
+					if filter.MatchString(line) {
+						matched = append(matched, line)
+					}
+				}
+				lines = matched
+			}
+			if logsTail > 0 && len(lines) > logsTail {
+				lines = lines[len(lines)-logsTail:]
+			}
+
+			data := map[string]any{
+				"file":  s.ServerLog,
+				"lines": lines,
+				"total": total,
+			}
+			output.Success("server-logs", data, fmt.Sprintf("%d of %d log lines", len(lines), total)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	serverLogsCmd.Flags().IntVar(&logsTail, "tail", 100, "Show only the last N lines (0 = all)")
+	serverLogsCmd.Flags().StringVar(&logsGrep, "grep", "", "Show only lines matching this regexp")
+	root.AddCommand(serverLogsCmd)
+}
+
+func init() {
+	addServerLogsCommand(rootCmd, GetOutputFormat)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// TestAdoptServerLog checks that the log moves into the session directory
+// and is recorded with the session.
+func TestAdoptServerLog(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	addr := "127.0.0.1:40213"
+	if err := session.New(addr).Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	var config debugger.LaunchConfig
+	if err := enableServerLog(&config, defaultServerLogOutput); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config.LogFile, []byte("rpc call\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := adoptServerLog(&debugger.LaunchResult{Addr: addr, LogFile: config.LogFile})
+	dir, _ := session.Dir(addr)
+	if path != filepath.Join(dir, serverLogFile) {
+		t.Errorf("adoptServerLog = %s, want it in %s", path, dir)
+	}
+	if _, err := os.Stat(filepath.Dir(config.LogFile)); !os.IsNotExist(err) {
+		t.Errorf("output directory left behind: %v", err)
+	}
+	s, err := session.Load(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ServerLog != path {
+		t.Errorf("session records %q, want %q", s.ServerLog, path)
+	}
+}
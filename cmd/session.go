@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// recordLaunchSession stores the session record for a server started by godebug.
+// Recording is best effort: the debug server is usable even if it fails.
+func recordLaunchSession(result *debugger.LaunchResult) {
+	s := session.New(result.Addr)
+	s.PID = result.PID
+	s.Target = result.Target
+	s.Mode = result.Mode
+	s.Backend = result.Backend
+	recordSessionSources(s)
+}
+
+// recordConnectSession stores a session record for an externally started server
+// unless one already exists
+func recordConnectSession(addr string) {
+	if _, err := session.Load(addr); err == nil {
+		return
+	}
+	recordSessionSources(session.New(addr))
+}
+
+// recordSessionSources stamps the user sources of the debugged binary, maps
+// them onto the local checkout and saves the record
+func recordSessionSources(s *session.Session) {
+	if c, err := debugger.Connect(s.Addr); err == nil {
+		if sources, err := c.ListSources(""); err == nil {
+			s.RecordSources(userSources(sources))
+			buildSessionPathMap(s.Addr, sources)
+		}
+		_ = c.Close()
+	}
+	_ = s.Save()
+}
+
+// recordSessionEnd marks the session at addr as ended by command
+func recordSessionEnd(addr, command string) {
+	s, err := session.Load(addr)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	s.EndedBy = command
+	s.EndedAt = &now
+	_ = s.Save()
+}
+
+// connectError is the error for a failed connection to addr, explaining that
+// the session was ended on purpose when it was
+func connectError(addr string, err error) *output.ErrorInfo {
+	info := output.FromError(err)
+	s, loadErr := session.Load(addr)
+	if loadErr != nil || s.EndedBy == "" {
+		return info
+	}
+	details := map[string]any{
+		"addr":    addr,
+		"endedBy": s.EndedBy,
+		"hint":    "start a new session; artifacts list --session " + session.ID(addr) + " shows what this one left",
+	}
+	if s.EndedAt != nil {
+		details["endedAt"] = s.EndedAt.UTC().Format(time.RFC3339)
+	}
+	msg := fmt.Sprintf("%s (the session was ended by %s)", info.Message, s.EndedBy)
+	return output.NewErrorInfo(info.Code, msg).WithDetails(details)
+}
+
+// stopsFile holds the most recent stops of the session
+const stopsFile = "stops.json"
+
+// maxRecordedStops bounds the stop history kept per session
+const maxRecordedStops = 50
+
+// stopRecord is one entry of the session's stop history
+type stopRecord struct {
+	Command      string    `json:"command"`
+	Time         time.Time `json:"time"`
+	SinceStartMs int64     `json:"sinceStartMs,omitempty"`
+	Exited       bool      `json:"exited,omitempty"`
+	ExitStatus   int       `json:"exitStatus,omitempty"`
+	GoroutineID  int64     `json:"goroutineId,omitempty"`
+	File         string    `json:"file,omitempty"`
+	Line         int       `json:"line,omitempty"`
+	Function     string    `json:"function,omitempty"`
+	BreakpointID int       `json:"breakpointId,omitempty"`
+	// CPUTimeMs is the target's total CPU time (user and system) at the stop
+	CPUTimeMs *int64 `json:"cpuTimeMs,omitempty"`
+}
+
+// commandStarted is when this godebug invocation started, which for an
+// execution command is about when the target was resumed
+var commandStarted = time.Now()
+
+// clockTicks is USER_HZ, the unit of CPU times in /proc; 100 on every Linux
+// architecture Go supports
+const clockTicks = 100
+
+// stopElapsed is how long the target ran up to a stop: since the previous
+// stop and during the command that stopped it. CPUMs is the target's CPU time
+// since the previous stop; a wall time much above it means the target was
+// blocked, a CPU time near it a busy computation.
+type stopElapsed struct {
+	WallMs    int64  `json:"wallMs"`
+	RunMs     int64  `json:"runMs"`
+	CPUMs     *int64 `json:"cpuMs,omitempty"`
+	SinceStop string `json:"sinceStop"`
+}
+
+// stopTime is when an execution command stopped, and how long the target ran
+type stopTime struct {
+	At      eventTimestamp
+	Elapsed *stopElapsed
+}
+
+// addTo reports the stop time in data as timestamp and elapsed
+func (t stopTime) addTo(data map[string]any) {
+	data["timestamp"] = t.At
+	if t.Elapsed != nil {
+		data["elapsed"] = t.Elapsed
+	}
+}
+
+// parseProcStatCPU returns the CPU time (user and system) in a
+// /proc/<pid>/stat line
+func parseProcStatCPU(stat string) (time.Duration, bool) {
+	// The command name is parenthesized and may contain spaces
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return 0, false
+	}
+	// Fields after the name start at state (3); utime is 14, stime 15
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 13 {
+		return 0, false
+	}
+	utime, err1 := strconv.ParseInt(fields[11], 10, 64)
+	stime, err2 := strconv.ParseInt(fields[12], 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return time.Duration(utime+stime) * time.Second / clockTicks, true
+}
+
+// targetCPUTime returns the CPU time the target has used, read from /proc
+// when the server runs on this Linux host
+func targetCPUTime(c *debugger.Client) (time.Duration, bool) {
+	// A remote server's PIDs are not this host's
+	if runtime.GOOS != "linux" || !serverIsLocal(c.Addr()) {
+		return 0, false
+	}
+	pid, err := c.ProcessPid()
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	raw, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, false
+	}
+	return parseProcStatCPU(string(raw))
+}
+
+// elapsedSince returns the time between the previous stop and this one
+func elapsedSince(prev, cur stopRecord) *stopElapsed {
+	e := &stopElapsed{
+		WallMs:    cur.Time.Sub(prev.Time).Milliseconds(),
+		RunMs:     cur.Time.Sub(commandStarted).Milliseconds(),
+		SinceStop: prev.Command,
+	}
+	// A restart starts a new process whose CPU time counts from zero
+	if prev.CPUTimeMs != nil && cur.CPUTimeMs != nil && cur.Command != "restart" && *cur.CPUTimeMs >= *prev.CPUTimeMs {
+		cpu := *cur.CPUTimeMs - *prev.CPUTimeMs
+		e.CPUMs = &cpu
+	}
+	return e
+}
+
+// eventTimestamp places an event on the session timeline: wall clock time to
+// correlate with external logs, and time since the session started to order
+// events without clock arithmetic
+type eventTimestamp struct {
+	Time         string `json:"time"`
+	SinceStartMs int64  `json:"sinceStartMs,omitempty"`
+}
+
+// newEventTimestamp stamps an event of the session at addr that happened at t.
+// SinceStartMs is left out when the session start is unknown.
+func newEventTimestamp(addr string, t time.Time) eventTimestamp {
+	ts := eventTimestamp{Time: t.UTC().Format(time.RFC3339Nano)}
+	if s, err := session.Load(addr); err == nil && !s.StartedAt.IsZero() {
+		ts.SinceStartMs = t.Sub(s.StartedAt).Milliseconds()
+	}
+	return ts
+}
+
+// recordStop appends the state an execution command stopped in to the stop history
+// and returns the stop's time, with how long the target ran since the previous
+// stop. Like all session recording this is best effort.
+func recordStop(c *debugger.Client, command string, state *api.DebuggerState) stopTime {
+	now := time.Now()
+	ts := newEventTimestamp(c.Addr(), now)
+	rec := stopRecord{
+		Command:      command,
+		Time:         now,
+		SinceStartMs: ts.SinceStartMs,
+		Exited:       state.Exited,
+		ExitStatus:   state.ExitStatus,
+	}
+	if g := state.SelectedGoroutine; g != nil {
+		rec.GoroutineID = g.ID
+		rec.File = g.CurrentLoc.File
+		rec.Line = g.CurrentLoc.Line
+		rec.Function = g.CurrentLoc.Function.Name()
+	}
+	if state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil {
+		rec.BreakpointID = state.CurrentThread.Breakpoint.ID
+	}
+	if !state.Exited {
+		if cpu, ok := targetCPUTime(c); ok {
+			ms := cpu.Milliseconds()
+			rec.CPUTimeMs = &ms
+		}
+	}
+
+	stops := loadStops(c.Addr())
+	stopped := stopTime{At: ts}
+	if len(stops) > 0 {
+		stopped.Elapsed = elapsedSince(stops[len(stops)-1], rec)
+	}
+	stops = append(stops, rec)
+	if len(stops) > maxRecordedStops {
+		stops = stops[len(stops)-maxRecordedStops:]
+	}
+	_ = session.SaveData(c.Addr(), stopsFile, stops)
+	return stopped
+}
+
+// loadStops returns the recorded stop history, oldest first
+func loadStops(addr string) []stopRecord {
+	var stops []stopRecord
+	_ = session.LoadData(addr, stopsFile, &stops)
+	return stops
+}
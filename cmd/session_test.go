@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProcStatCPU(t *testing.T) {
+	stat := "4242 (my prog) S 1 4242 4242 0 -1 4194560 1234 0 0 0 150 25 0 0 20 0 8 0 98765 123456 789 18446744073709551615"
+	cpu, ok := parseProcStatCPU(stat)
+	if !ok || cpu != 1750*time.Millisecond {
+		t.Errorf("parseProcStatCPU = %v, %v, want 1.75s", cpu, ok)
+	}
+	if _, ok := parseProcStatCPU("4242 (trunc"); ok {
+		t.Error("parsed a truncated line")
+	}
+}
+
+func TestElapsedSince(t *testing.T) {
+	at := time.Now()
+	ms := func(n int64) *int64 { return &n }
+	prev := stopRecord{Command: "continue", Time: at, CPUTimeMs: ms(100)}
+	cur := stopRecord{Command: "next", Time: at.Add(3 * time.Second), CPUTimeMs: ms(2600)}
+
+	e := elapsedSince(prev, cur)
+	if e.WallMs != 3000 || e.SinceStop != "continue" || e.CPUMs == nil || *e.CPUMs != 2500 {
+		t.Errorf("elapsed = %+v", e)
+	}
+	cur.Command = "restart"
+	if e := elapsedSince(prev, cur); e.CPUMs != nil {
+		t.Errorf("restart cpuMs = %d, want none", *e.CPUMs)
+	}
+	cur.Command, cur.CPUTimeMs = "next", nil
+	if e := elapsedSince(prev, cur); e.CPUMs != nil {
+		t.Errorf("unknown cpuMs = %d, want none", *e.CPUMs)
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// settableKinds are the kinds Delve assigns values to. Values of other kinds
+// can only be copied from another variable of the same type.
+var settableKinds = []string{
+	"int", "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+	"float32", "float64", "complex64", "complex128",
+	"bool", "string", "ptr", "unsafe.Pointer", "slice", "map", "chan", "func", "interface",
+}
+
+// splitAssignment splits "x=42" or "x = 42" into the variable and the value.
+// The first "=" that is not part of ==, !=, <= or >= separates them, so
+// index expressions like m[a==b] stay in the variable.
+func splitAssignment(s string) (string, string, bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '=' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == '=' {
+			i++
+			continue
+		}
+		if i > 0 && strings.ContainsRune("!<>", rune(s[i-1])) {
+			continue
+		}
+		symbol, value := strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])
+		return symbol, value, symbol != "" && value != ""
+	}
+	return "", "", false
+}
+
+// stringLiteral reports whether a value is a quoted Go string literal
+func stringLiteral(value string) bool {
+	return len(value) >= 2 && (value[0] == '"' || value[0] == '`') && value[len(value)-1] == value[0]
+}
+
+// setFailure turns a failed assignment into an error naming the variable's
+// type and what can be assigned to it
+func setFailure(symbol, value string, v *api.Variable, err error) *output.ErrorInfo {
+	details := map[string]any{"variable": symbol, "value": value}
+	if v != nil {
+		details["type"] = v.Type
+		details["kind"] = v.Kind.String()
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "literal string can not be allocated"):
+		details["hint"] = "assigning a string literal allocates memory in the target; add --allow-calls"
+		return output.InvalidArgumentWithDetails(fmt.Sprintf("can not assign %s to '%s' without --allow-calls", value, symbol), details)
+	case strings.Contains(msg, "can not convert") || strings.Contains(msg, "can not assign") ||
+		strings.Contains(msg, "mismatched types") || strings.Contains(msg, "not assignable"):
+		details["supported"] = settableKinds
+		details["hint"] = "structs and arrays can only be copied from a variable of the same type; set their fields or elements one at a time"
+		return output.InvalidArgumentWithDetails(fmt.Sprintf("can not assign %s to '%s': %v", value, symbol, err), details)
+	}
+	info := output.EvalFailed(symbol+" = "+value, err)
+	return info.WithDetails(details)
+}
+
+// addSetCommand adds the set command
+func addSetCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var setGoroutine int64
+	var setAllowCalls bool
+
+	setCmd := &cobra.Command{
+		Use:   "set <variable>=<value>",
+		Short: "Assign a value to a variable in the target",
+		Long: `Assign a value to a variable, field, element or pointer target in the
+selected goroutine and frame, to test a fix without recompiling.
+
+The value is a Go expression. Numbers, booleans, pointers (including nil)
+and nil-able values (slices, maps, channels, funcs, interfaces) can be
+assigned. Structs and arrays can only be copied from another variable of the
+same type; set their fields or elements instead. Assigning a string literal
+allocates memory in the target through a function call, so it needs
+--allow-calls.
+
+The response reports the variable's type and its value before and after.
+
+Example:
+  godebug --addr $ADDR set x=42
+  godebug --addr $ADDR set 'user.Name="bob"' --allow-calls
+  godebug --addr $ADDR set cfg.Retries = 0`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			symbol, value, ok := splitAssignment(strings.Join(args, " "))
+			if !ok {
+				output.ErrorWithInfo("set", output.InvalidArgumentWithDetails(
+					"expected <variable>=<value>",
+					map[string]any{"args": args},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("set")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("set", err).PrintAndExit(getOutputFormat())
+			}
+			goroutineID, ok := targetGoroutine(state, setGoroutine)
+			if !ok {
+				output.ErrorWithInfo("set", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+			}
+			scope := api.EvalScope{GoroutineID: goroutineID, Frame: mustSelectedFrame(c, "set", state, goroutineID, getOutputFormat)}
+			cfg := debugger.DefaultLoadConfig()
+
+			old, err := c.EvalInScope(scope, symbol, cfg)
+			if err != nil {
+				output.Error("set", err).PrintAndExit(getOutputFormat())
+			}
+
+			usedCall := false
+			err = c.SetVariable(scope, symbol, value)
+			if err != nil && setAllowCalls && stringLiteral(value) && strings.Contains(err.Error(), "literal string can not be allocated") {
+				// Delve allocates the string when the assignment runs as a call,
+				// which only works in the goroutine's topmost frame
+				if scope.Frame > 0 {
+					output.ErrorWithInfo("set", output.InvalidArgumentWithDetails(
+						"assigning a string literal runs in the goroutine's topmost frame; select frame 0 first",
+						map[string]any{"frame": scope.Frame},
+					)).PrintAndExit(getOutputFormat())
+				}
+				_, err = c.CallFunction(goroutineID, symbol+" = "+value, cfg)
+				usedCall = true
+			}
+			if err != nil {
+				var info *output.ErrorInfo
+				if errors.As(err, &info) && info.Code != output.ErrCodeEvalFailed {
+					output.ErrorWithInfo("set", info).PrintAndExit(getOutputFormat())
+				}
+				output.ErrorWithInfo("set", setFailure(symbol, value, old, err)).PrintAndExit(getOutputFormat())
+			}
+
+			data := map[string]any{
+				"variable": symbol,
+				"type":     old.Type,
+				"old":      variableToMap(*old),
+			}
+			if updated, err := c.EvalInScope(scope, symbol, cfg); err == nil {
+				data["new"] = variableToMap(*updated)
+			}
+			if usedCall {
+				data["call"] = true
+			}
+			if setGoroutine > 0 {
+				data["goroutineId"] = goroutineID
+			}
+			if scope.Frame > 0 {
+				data["frame"] = scope.Frame
+			}
+			output.Success("set", data, fmt.Sprintf("Set %s = %s", symbol, value)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	setCmd.Flags().Int64Var(&setGoroutine, "goroutine", 0, "Goroutine whose variable to set (default: selected)")
+	setCmd.Flags().BoolVar(&setAllowCalls, "allow-calls", false, "Allow a function call in the target to allocate string literals")
+	root.AddCommand(setCmd)
+}
+
+func init() {
+	addSetCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
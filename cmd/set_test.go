@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// TestSplitAssignment checks that comparison operators do not split an
+// assignment
+func TestSplitAssignment(t *testing.T) {
+	tests := []struct {
+		in, symbol, value string
+		ok                bool
+	}{
+		{"x=42", "x", "42", true},
+		{"user.Name = \"a=b\"", "user.Name", "\"a=b\"", true},
+		{"m[a==b]=true", "m[a==b]", "true", true},
+		{"ok = n >= 3", "ok", "n >= 3", true},
+		{"x==42", "", "", false},
+		{"x=", "", "", false},
+		{"x 42", "", "", false},
+	}
+	for _, tt := range tests {
+		symbol, value, ok := splitAssignment(tt.in)
+		if ok != tt.ok || (ok && (symbol != tt.symbol || value != tt.value)) {
+			t.Errorf("splitAssignment(%q) = %q, %q, %v; want %q, %q, %v", tt.in, symbol, value, ok, tt.symbol, tt.value, tt.ok)
+		}
+	}
+}
+
+// TestSetFailure checks that unsupported assignments are invalid arguments
+// naming the variable's type
+func TestSetFailure(t *testing.T) {
+	v := &api.Variable{Type: "main.User"}
+	info := setFailure("u", "1", v, errors.New("can not convert 1 constant to main.User"))
+	details, _ := info.Details.(map[string]any)
+	if info.Code != output.ErrCodeInvalidArgument || details["type"] != "main.User" || details["supported"] == nil {
+		t.Errorf("type mismatch: %+v", info)
+	}
+
+	info = setFailure("s", `"bob"`, v, errors.New("literal string can not be allocated because function calls are not allowed without using 'call'"))
+	if info.Code != output.ErrCodeInvalidArgument {
+		t.Errorf("string literal: %+v", info)
+	}
+
+	info = setFailure("y", "1", nil, errors.New("could not find symbol value for y"))
+	if info.Code != output.ErrCodeEvalFailed {
+		t.Errorf("unknown variable: %+v", info)
+	}
+}
@@ -4,17 +4,164 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/8gears/godebug-agentic/internal/debugger"
 	"github.com/8gears/godebug-agentic/internal/output"
 )
 
 var (
 	listContext int
+	listBlame   bool
+
+	sourcesTree    bool
+	sourcesPackage string
 )
 
+// isUserSource reports whether a source path belongs to user code
+// rather than the standard library, the runtime, or generated code
+func isUserSource(src string) bool {
+	return !strings.Contains(src, "/go/src/") &&
+		!strings.Contains(src, "/runtime/") &&
+		!strings.HasPrefix(src, "<")
+}
+
+// userSources filters a source list down to user code
+func userSources(sources []string) []string {
+	var filtered []string
+	for _, src := range sources {
+		if isUserSource(src) {
+			filtered = append(filtered, src)
+		}
+	}
+	return filtered
+}
+
+// sourceNode is a directory in the sources tree. FileCount includes the files
+// of all subdirectories.
+type sourceNode struct {
+	Name      string        `json:"name"`
+	FileCount int           `json:"fileCount"`
+	Dirs      []*sourceNode `json:"dirs,omitempty"`
+	Files     []string      `json:"files,omitempty"`
+}
+
+// buildSourceTree nests file paths into directories. Chains of directories
+// with a single subdirectory and no files are collapsed into one node
+// (e.g. "home/me/project") to keep the tree shallow.
+func buildSourceTree(paths []string) *sourceNode {
+	root := &sourceNode{Name: "/"}
+	index := map[string]*sourceNode{"": root}
+	var dirFor func(dir string) *sourceNode
+	dirFor = func(dir string) *sourceNode {
+		if n, ok := index[dir]; ok {
+			return n
+		}
+		parent, name := path.Split(dir)
+		n := &sourceNode{Name: name}
+		p := dirFor(strings.TrimSuffix(parent, "/"))
+		p.Dirs = append(p.Dirs, n)
+		index[dir] = n
+		return n
+	}
+	for _, p := range paths {
+		dir, file := path.Split(strings.TrimPrefix(p, "/"))
+		n := dirFor(strings.TrimSuffix(dir, "/"))
+		n.Files = append(n.Files, file)
+	}
+
+	var finish func(n *sourceNode) int
+	finish = func(n *sourceNode) int {
+		for len(n.Files) == 0 && len(n.Dirs) == 1 && n != root {
+			child := n.Dirs[0]
+			n.Name = n.Name + "/" + child.Name
+			n.Files, n.Dirs = child.Files, child.Dirs
+		}
+		sort.Strings(n.Files)
+		sort.Slice(n.Dirs, func(i, j int) bool { return n.Dirs[i].Name < n.Dirs[j].Name })
+		n.FileCount = len(n.Files)
+		for _, d := range n.Dirs {
+			n.FileCount += finish(d)
+		}
+		return n.FileCount
+	}
+	finish(root)
+	return root
+}
+
+// packageFilter turns an import path, optionally ending in /..., into a
+// regexp for ListPackagesBuildInfo
+func packageFilter(pkg string) string {
+	if prefix, ok := strings.CutSuffix(pkg, "/..."); ok {
+		return "^" + regexp.QuoteMeta(prefix) + "(/|$)"
+	}
+	return "^" + regexp.QuoteMeta(pkg) + "$"
+}
+
+// sourcesData lists source files matching filter, either all user sources or
+// the files of the packages matching pkg, as a flat list or as a tree
+func sourcesData(c *debugger.Client, filter, pkg string, tree bool) (map[string]any, error) {
+	var sources, filtered []string
+	if pkg != "" {
+		pkgs, err := c.ListPackagesBuildInfo(packageFilter(pkg), true)
+		if err != nil {
+			return nil, err
+		}
+		if len(pkgs) == 0 {
+			return nil, output.NotFound("package", pkg)
+		}
+		var re *regexp.Regexp
+		if filter != "" {
+			if re, err = regexp.Compile(filter); err != nil {
+				return nil, output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid filter: %v", err),
+					map[string]any{"filter": filter},
+				)
+			}
+		}
+		for _, p := range pkgs {
+			sources = append(sources, p.Files...)
+		}
+		sort.Strings(sources)
+		// The package was asked for explicitly, so keep non-user sources
+		for _, src := range sources {
+			if re == nil || re.MatchString(src) {
+				filtered = append(filtered, src)
+			}
+		}
+	} else {
+		var err error
+		if sources, err = c.ListSources(filter); err != nil {
+			return nil, err
+		}
+		// Filter out runtime/internal sources for cleaner output
+		filtered = userSources(sources)
+	}
+
+	data := map[string]any{
+		"count": len(filtered),
+		"total": len(sources),
+	}
+	if pkg != "" {
+		data["package"] = pkg
+	}
+	if m := sessionPathMap(c); m != nil && len(m.Rules) > 0 {
+		// Files are listed as compiled; break, list and annotate translate local paths
+		data["pathMap"] = m.Rules
+	}
+	if tree {
+		data["tree"] = buildSourceTree(filtered)
+	} else {
+		data["sources"] = filtered
+	}
+	return data, nil
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Show source code at current location",
@@ -22,10 +169,12 @@ var listCmd = &cobra.Command{
 
 Options:
   --context N   Number of lines before and after (default 5)
+  --blame       Annotate each line with the last commit that changed it
 
 Example:
   godebug --addr $ADDR list
-  godebug --addr $ADDR list --context 10`,
+  godebug --addr $ADDR list --context 10
+  godebug --addr $ADDR list --blame`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("list")
 		defer func() { _ = c.Close() }()
@@ -44,8 +193,9 @@ Example:
 			output.ErrorWithInfo("list", output.NotFound("source location", "none available")).PrintAndExit(GetOutputFormat())
 		}
 
-		// Read the source file
-		file, err := os.Open(loc.File)
+		// Read the source file, from the local checkout if the binary was built elsewhere
+		local := localSourceFile(c, loc.File)
+		file, err := os.Open(local)
 		if err != nil {
 			output.ErrorWithInfo("list", output.NotFound("source file", loc.File)).PrintAndExit(GetOutputFormat())
 		}
@@ -82,12 +232,18 @@ Example:
 		if err := scanner.Err(); err != nil {
 			output.Error("list", err).PrintAndExit(GetOutputFormat())
 		}
+		if listBlame {
+			addLinesBlame(local, lines)
+		}
 
 		data := map[string]any{
 			"file":        loc.File,
 			"currentLine": loc.Line,
 			"lines":       lines,
 		}
+		if local != loc.File {
+			data["localFile"] = local
+		}
 		if loc.Function != nil {
 			data["function"] = loc.Function.Name()
 		}
@@ -103,9 +259,15 @@ var sourcesCmd = &cobra.Command{
 
 Optional filter argument matches file paths.
 
+Options:
+  --tree             Nest files into directories with per-directory file counts
+  --package PATH     Only files of this import path (PATH/... for subpackages)
+
 Example:
   godebug --addr $ADDR sources
-  godebug --addr $ADDR sources main`,
+  godebug --addr $ADDR sources main
+  godebug --addr $ADDR sources --tree
+  godebug --addr $ADDR sources --package github.com/me/app/internal/...`,
 	Run: func(cmd *cobra.Command, args []string) {
 		c := MustGetClient("sources")
 		defer func() { _ = c.Close() }()
@@ -115,30 +277,12 @@ Example:
 			filter = args[0]
 		}
 
-		sources, err := c.ListSources(filter)
+		data, err := sourcesData(c, filter, sourcesPackage, sourcesTree)
 		if err != nil {
 			output.Error("sources", err).PrintAndExit(GetOutputFormat())
 		}
 
-		// Filter out runtime/internal sources for cleaner output
-		var filtered []string
-		for _, src := range sources {
-			// Skip standard library and internal paths
-			if strings.Contains(src, "/go/src/") ||
-				strings.Contains(src, "/runtime/") ||
-				strings.HasPrefix(src, "<") {
-				continue
-			}
-			filtered = append(filtered, src)
-		}
-
-		data := map[string]any{
-			"sources": filtered,
-			"count":   len(filtered),
-			"total":   len(sources),
-		}
-
-		output.Success("sources", data, fmt.Sprintf("%d source files", len(filtered))).PrintAndExit(GetOutputFormat())
+		output.Success("sources", data, fmt.Sprintf("%d source files", data["count"])).PrintAndExit(GetOutputFormat())
 	},
 }
 
@@ -147,4 +291,7 @@ func init() {
 	rootCmd.AddCommand(sourcesCmd)
 
 	listCmd.Flags().IntVar(&listContext, "context", 5, "Lines of context before and after")
+	listCmd.Flags().BoolVar(&listBlame, "blame", false, "Annotate lines with git blame")
+	sourcesCmd.Flags().BoolVar(&sourcesTree, "tree", false, "Show sources as a directory tree")
+	sourcesCmd.Flags().StringVar(&sourcesPackage, "package", "", "Only sources of this import path (PATH/... includes subpackages)")
 }
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestBuildSourceTree checks nesting, file counts and collapsing of
+// single-directory chains.
+func TestBuildSourceTree(t *testing.T) {
+	tree := buildSourceTree([]string{
+		"/home/me/app/main.go",
+		"/home/me/app/internal/db/db.go",
+		"/home/me/app/internal/db/tx.go",
+		"/home/me/app/internal/api/api.go",
+	})
+	got, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"/","fileCount":4,"dirs":[{"name":"home/me/app","fileCount":4,"dirs":[` +
+		`{"name":"internal","fileCount":3,"dirs":[` +
+		`{"name":"api","fileCount":1,"files":["api.go"]},` +
+		`{"name":"db","fileCount":2,"files":["db.go","tx.go"]}]}],` +
+		`"files":["main.go"]}]}`
+	if string(got) != want {
+		t.Errorf("buildSourceTree =\n%s\nwant\n%s", got, want)
+	}
+}
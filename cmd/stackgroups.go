@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/goversion"
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+)
+
+// groupByStack is the value of goroutines --group-by that collapses
+// goroutines with identical stacks
+const groupByStack = "stack"
+
+// stackGroupSample is how many goroutine IDs a stack group lists
+const stackGroupSample = 5
+
+// stackFrame is a frame of a grouped stack
+type stackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// stackGroup is the goroutines sharing a state and a stack. Only user-code
+// frames are compared, unless the stack has none (runtime goroutines).
+type stackGroup struct {
+	Count  int          `json:"count"`
+	State  string       `json:"state"`
+	IDs    []int64      `json:"ids"`
+	Frames []stackFrame `json:"frames"`
+	// Runtime is set when the stack has no user code
+	Runtime bool `json:"runtime,omitempty"`
+}
+
+// goroutineStack is a goroutine's state and stack, innermost frame first
+type goroutineStack struct {
+	ID     int64
+	State  string
+	Frames []api.Stackframe
+}
+
+// groupStacks collapses stacks with the same state and frames into groups,
+// the largest first
+func groupStacks(stacks []goroutineStack) []stackGroup {
+	index := map[string]int{}
+	var groups []stackGroup
+	for _, s := range stacks {
+		var frames []stackFrame
+		for _, f := range s.Frames {
+			if isUserSource(f.File) {
+				frames = append(frames, stackFrame{Function: f.Function.Name(), File: f.File, Line: f.Line})
+			}
+		}
+		runtimeOnly := len(frames) == 0
+		if runtimeOnly {
+			for _, f := range s.Frames {
+				frames = append(frames, stackFrame{Function: f.Function.Name(), File: f.File, Line: f.Line})
+			}
+		}
+
+		var key strings.Builder
+		key.WriteString(s.State)
+		for _, f := range frames {
+			fmt.Fprintf(&key, "\n%s %s:%d", f.Function, f.File, f.Line)
+		}
+		i, ok := index[key.String()]
+		if !ok {
+			i = len(groups)
+			index[key.String()] = i
+			groups = append(groups, stackGroup{State: s.State, IDs: []int64{}, Frames: frames, Runtime: runtimeOnly})
+		}
+		groups[i].Count++
+		if len(groups[i].IDs) < stackGroupSample {
+			groups[i].IDs = append(groups[i].IDs, s.ID)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Count > groups[j].Count
+	})
+	return groups
+}
+
+// goroutineStackGroups reads the stacks of goroutines, depth frames deep,
+// and groups them. Goroutines whose stack cannot be read are counted apart.
+func goroutineStackGroups(c *debugger.Client, ver *goversion.GoVersion, goroutines []*api.Goroutine, depth int) ([]stackGroup, int) {
+	stacks := make([]goroutineStack, 0, len(goroutines))
+	unreadable := 0
+	for _, g := range goroutines {
+		frames, err := c.Stacktrace(g.ID, depth, nil)
+		if err != nil {
+			unreadable++
+			continue
+		}
+		stacks = append(stacks, goroutineStack{ID: g.ID, State: goroutineState(ver, g), Frames: frames})
+	}
+	return groupStacks(stacks), unreadable
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestGroupStacks checks that goroutines differing only in runtime frames
+// share a group, that state splits groups and that the largest comes first
+func TestGroupStacks(t *testing.T) {
+	frame := func(fn, file string, line int) api.Stackframe {
+		return api.Stackframe{Location: api.Location{File: file, Line: line, Function: &api.Function{Name_: fn}}}
+	}
+	park := frame("runtime.gopark", "/usr/local/go/src/runtime/proc.go", 435)
+	send := frame("runtime.chansend1", "/usr/local/go/src/runtime/chan.go", 161)
+	worker := frame("main.worker", "/app/main.go", 20)
+	sched := frame("runtime.schedule", "/usr/local/go/src/runtime/proc.go", 4000)
+
+	var stacks []goroutineStack
+	for id := int64(10); id < 17; id++ {
+		frames := []api.Stackframe{park, send, worker}
+		if id%2 == 0 {
+			frames = []api.Stackframe{send, worker}
+		}
+		stacks = append(stacks, goroutineStack{ID: id, State: "chan send", Frames: frames})
+	}
+	stacks = append(stacks,
+		goroutineStack{ID: 2, State: "running", Frames: []api.Stackframe{worker}},
+		goroutineStack{ID: 3, State: "GC worker (idle)", Frames: []api.Stackframe{park, sched}},
+	)
+
+	groups := groupStacks(stacks)
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want 3: %+v", len(groups), groups)
+	}
+	g := groups[0]
+	if g.Count != 7 || g.State != "chan send" || len(g.IDs) != stackGroupSample || g.IDs[0] != 10 {
+		t.Errorf("largest group = %+v, want the 7 senders with %d sample IDs", g, stackGroupSample)
+	}
+	if len(g.Frames) != 1 || g.Frames[0].Function != "main.worker" || g.Runtime {
+		t.Errorf("largest group frames = %+v, want main.worker only", g.Frames)
+	}
+	if rt := groups[2]; !rt.Runtime || len(rt.Frames) != 2 {
+		t.Errorf("runtime group = %+v, want its full stack", rt)
+	}
+}
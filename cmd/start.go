@@ -1,14 +1,30 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/8gears/godebug-agentic/internal/debugger"
 	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
 )
 
 var (
-	startMode string
+	startMode         string
+	startOnCrash      string
+	startReplayOf     string
+	startStdin        string
+	startRecordEnv    []string
+	startServerLog    string
+	startBackend      string
+	startListen       string
+	startBuildFlags   string
+	startNoHalt       bool
+	startContinue     bool
+	startReuseBuild   bool
+	startBuildCache   string
+	startReadyTimeout time.Duration
 )
 
 var startCmd = &cobra.Command{
@@ -20,52 +36,167 @@ Modes:
   debug (default) - Compile and debug a Go package
   test            - Compile and debug tests
   exec            - Debug a pre-compiled binary
+  attach          - Debug a running process; the target is its PID. It is
+                    stopped unless --no-halt is given; quit detaches and
+                    leaves the process running
+
+start waits until the server accepts connections: up to --ready-timeout
+(default: --timeout), which large builds may need raised. --listen fixes the
+server address instead of picking a free port.
+
+Delve's own log (--server-log components, debugger,rpc by default) is kept
+in the session directory; server-logs shows it.
+
+With --on-crash capture the program's stdout and stderr are written to files.
+When an execution command stops on an unrecovered panic or fatal error (or the
+process exits non-zero), a goroutine dump, the output tail and a core dump
+are saved with the session's artifacts (see artifacts) and reported in
+data.crash.
+
+Every launch is recorded with the session: target, mode, program arguments,
+working directory, the runtime-relevant environment (GODEBUG, GOMAXPROCS,
+GOGC, GOTRACEBACK, TZ, LANG and similar, plus --record-env names) and the
+--stdin input. start --replay-of <session ID or address> relaunches under the
+same conditions, to retry a flaky failure. data.sessionId names the session.
+
+--backend rr records the program with rr: rewind (reverse-continue),
+reverse-next, reverse-step and reverse-stepout then move backwards through
+the recording, replaying an intermittent race deterministically.
+
+--build-flags passes extra go build flags (tags, -race) in debug and test
+mode; replays reuse the recorded ones. In a project (see godebug project),
+start without a target launches its start.target, and every start sets its
+breakpoints, reported in data.project.
+
+Short edit-debug loops are dominated by the launch. --continue runs the
+program as soon as the server is up instead of stopping at entry (interrupt
+stops it). --build-cache-dir sets the Go build cache (GOCACHE) the build
+uses. With --reuse-build, debug mode builds the package itself and launches
+the binary in exec mode; a later start whose sources, go.mod files, build
+flags and toolchain are unchanged launches the same binary without building
+(data.build.reused). reload cannot rebuild such a session; start it again.
 
 Examples:
   godebug start ./cmd/myapp           # Debug mode (default)
   godebug start --mode test ./...     # Test mode
   godebug start --mode exec ./binary  # Exec mode
-  godebug start ./cmd/myapp -- -port 8080  # With program args`,
-	Args: cobra.MinimumNArgs(1),
+  godebug start --mode attach 4242    # Attach to a running process
+  godebug start --mode attach --no-halt 4242  # Attach without stopping it
+  godebug start --reuse-build --continue ./cmd/myapp  # Fast restart
+  godebug start ./cmd/myapp -- -port 8080  # With program args
+  godebug start --on-crash capture ./cmd/myapp  # Save artifacts if it crashes`,
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		target := args[0]
-		var programArgs []string
-		if cmd.ArgsLenAtDash() > 0 {
-			programArgs = args[cmd.ArgsLenAtDash():]
+		validateOnCrash(startOnCrash, GetOutputFormat)
+
+		config, launch := launchConfig(cmd, args, startMode, startOnCrash, startReplayOf, startStdin, startRecordEnv, GetOutputFormat)
+		config.Timeout = GetTimeout()
+		if startReadyTimeout > 0 {
+			config.Timeout = startReadyTimeout
+		}
+		config.Listen = startListen
+		mode, target := config.Mode, config.Target
+		validateNoHalt(startNoHalt, mode, GetOutputFormat)
+		config.Continue = startNoHalt
+		if startBackend != "" {
+			config.Backend = validateBackend(startBackend, mode, GetOutputFormat)
 		}
+		if startBuildFlags != "" && startReplayOf == "" {
+			config.BuildFlags = startBuildFlags
+		}
+		if launch != nil {
+			launch.Backend = config.Backend
+			launch.BuildFlags = config.BuildFlags
+		}
+		prebuild := applyFastStart(&config, startContinue, startBuildCache, startReuseBuild, GetOutputFormat)
 
-		mode := debugger.ModeDebug
-		switch startMode {
-		case "test":
-			mode = debugger.ModeTest
-		case "exec":
-			mode = debugger.ModeExec
+		// Keep the target's output in files so a crash capture can include its tail
+		var stdout, stderr string
+		if startOnCrash == onCrashCapture {
+			redirects, out, errOut, err := crashRedirects()
+			if err != nil {
+				output.Error("start", err).PrintAndExit(GetOutputFormat())
+			}
+			config.Redirects = append(config.Redirects, redirects...)
+			stdout, stderr = out, errOut
 		}
 
-		config := debugger.LaunchConfig{
-			Mode:    mode,
-			Target:  target,
-			Args:    programArgs,
-			Timeout: GetTimeout(),
+		if err := enableServerLog(&config, startServerLog); err != nil {
+			output.Error("start", err).PrintAndExit(GetOutputFormat())
 		}
 
 		result, err := debugger.Launch(config)
 		if err != nil {
-			output.Error("start", err).PrintAndExit(GetOutputFormat())
+			// A binary without debug info is the usual reason exec mode fails
+			if mode == debugger.ModeExec {
+				if d := debuggability(target); d != nil && d["level"] == debuggabilityNone {
+					output.ErrorWithInfo("start", output.FromError(err).WithDetails(map[string]any{"debuggability": d})).PrintAndExit(GetOutputFormat())
+				}
+			}
+			output.ErrorWithInfo("start", launchFailure(config, err)).PrintAndExit(GetOutputFormat())
+		}
+
+		recordLaunchSession(result)
+		serverLog := adoptServerLog(result)
+		saveLaunchRecord(result.Addr, launch)
+		if startOnCrash == onCrashCapture {
+			recordCrashCapture(result.Addr, stdout, stderr)
 		}
 
 		data := map[string]any{
 			"addr":   result.Addr,
 			"pid":    result.PID,
-			"target": result.Target,
-			"mode":   result.Mode,
+			"target": target,
+			"mode":   string(mode),
+		}
+		if launch != nil {
+			data["sessionId"] = session.ID(result.Addr)
+			data["launch"] = launchRecordData(launch)
+		}
+		if startReplayOf != "" {
+			data["replayOf"] = startReplayOf
+		}
+		if result.Backend != "" {
+			data["backend"] = result.Backend
+		}
+		if startOnCrash == onCrashCapture {
+			data["onCrash"] = onCrashCapture
+			data["stdout"] = stdout
+			data["stderr"] = stderr
+		}
+		if serverLog != "" {
+			data["serverLog"] = serverLog
+		}
+		if p := setProjectBreakpoints(result.Addr); p != nil {
+			data["project"] = p
+		}
+		if d := sessionDebuggability(result.Addr); d != nil {
+			data["debuggability"] = d
+		}
+
+		msg := fastStartData(data, "Debug server started", prebuild, config.Continue && mode != debugger.ModeAttach)
+		if mode == debugger.ModeAttach {
+			halted := attachHalted(result.Addr, startNoHalt)
+			data["halted"] = halted
+			msg = attachMessage(target, halted)
 		}
 
-		output.Success("start", data, "Debug server started").PrintAndExit(GetOutputFormat())
+		output.Success("start", data, msg).PrintAndExit(GetOutputFormat())
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(startCmd)
-	startCmd.Flags().StringVar(&startMode, "mode", "debug", "Debug mode: debug, test, or exec")
+	startCmd.Flags().StringVar(&startMode, "mode", "debug", "Debug mode: debug, test, exec, or attach")
+	startCmd.Flags().StringVar(&startOnCrash, "on-crash", "", "Crash handling: capture saves post-mortem artifacts")
+	addReplayFlags(startCmd, &startReplayOf, &startStdin, &startRecordEnv)
+	startCmd.Flags().StringVar(&startBackend, "backend", "", "Delve backend: default, native, lldb, or rr (record for reverse execution)")
+	startCmd.Flags().StringVar(&startBuildFlags, "build-flags", "", "Extra flags for building the target in debug and test mode, e.g. -tags=integration")
+	startCmd.Flags().BoolVar(&startNoHalt, "no-halt", false, "In attach mode, leave the process running instead of stopping it")
+	startCmd.Flags().BoolVar(&startContinue, "continue", false, "Run the program right away instead of stopping it at entry")
+	startCmd.Flags().BoolVar(&startReuseBuild, "reuse-build", false, "In debug mode, launch the binary of the previous start when its sources have not changed")
+	startCmd.Flags().StringVar(&startBuildCache, "build-cache-dir", "", "Go build cache (GOCACHE) for building the target in debug and test mode")
+	startCmd.Flags().StringVar(&startListen, "listen", "", "Address the Delve server listens on (default 127.0.0.1 on a free port)")
+	startCmd.Flags().DurationVar(&startReadyTimeout, "ready-timeout", 0, "How long to wait for the server to build and listen (0 = --timeout)")
+	startCmd.Flags().StringVar(&startServerLog, "server-log", defaultServerLogOutput, "Delve log components kept for server-logs (\"\" = none)")
 }
@@ -36,9 +36,7 @@ Example:
 
 		if state.SelectedGoroutine != nil {
 			g := state.SelectedGoroutine
-			data["goroutine"] = map[string]any{
-				"id": g.ID,
-			}
+			data["goroutine"] = goroutineRef(g)
 			if g.CurrentLoc.File != "" {
 				data["location"] = map[string]any{
 					"file":     g.CurrentLoc.File,
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// maxInstructionLen covers the longest instruction of any architecture Delve
+// supports (15 bytes on amd64)
+const maxInstructionLen = 16
+
+// instructionAt disassembles the instruction at pc, or returns nil if it
+// cannot be read
+func instructionAt(c *debugger.Client, pc uint64) map[string]any {
+	insts, err := c.Disassemble(api.EvalScope{GoroutineID: -1}, pc, pc+maxInstructionLen)
+	if err != nil || len(insts) == 0 || insts[0].Loc.PC != pc {
+		return nil
+	}
+	return asmToMap(insts[0])
+}
+
+// asmToMap converts an instruction for JSON output
+func asmToMap(inst api.AsmInstruction) map[string]any {
+	m := map[string]any{
+		"pc":    fmt.Sprintf("%#x", inst.Loc.PC),
+		"text":  inst.Text,
+		"bytes": fmt.Sprintf("%x", inst.Bytes),
+	}
+	if inst.Loc.File != "" {
+		m["file"] = inst.Loc.File
+		m["line"] = inst.Loc.Line
+	}
+	if inst.DestLoc != nil {
+		dest := map[string]any{"pc": fmt.Sprintf("%#x", inst.DestLoc.PC)}
+		if inst.DestLoc.Function != nil {
+			dest["function"] = inst.DestLoc.Function.Name()
+		}
+		m["call"] = dest
+	}
+	return m
+}
+
+// addInstructionStepCommands adds stepi and nexti
+func addInstructionStepCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	stepInstructions := func(name string, skipCalls bool, count *int) func(*cobra.Command, []string) {
+		return func(cmd *cobra.Command, args []string) {
+			if *count < 1 {
+				output.ErrorWithInfo(name, output.InvalidArgument("--count must be at least 1")).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient(name)
+			defer func() { _ = c.Close() }()
+
+			c.SetTimeout(getTimeout())
+			var state *api.DebuggerState
+			var err error
+			steps := 0
+			for steps < *count {
+				state, err = c.StepInstruction(skipCalls)
+				if err != nil {
+					output.Error(name, err).PrintAndExit(getOutputFormat())
+				}
+				steps++
+				if state.Exited || (state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil) {
+					break
+				}
+			}
+
+			stoppedAt := recordStop(c, name, state)
+
+			data := stateToData(state)
+			stoppedAt.addTo(data)
+			data["steps"] = steps
+			if state.CurrentThread != nil && !state.Exited {
+				data["pc"] = fmt.Sprintf("%#x", state.CurrentThread.PC)
+				if inst := instructionAt(c, state.CurrentThread.PC); inst != nil {
+					data["instruction"] = inst
+				}
+			}
+			if crash := captureCrash(c, state); crash != nil {
+				data["crash"] = crash
+			}
+
+			msg := fmt.Sprintf("Stepped %d instruction", steps)
+			if steps != 1 {
+				msg += "s"
+			}
+			output.Success(name, data, msg).PrintAndExit(getOutputFormat())
+		}
+	}
+
+	var stepiCount, nextiCount int
+
+	stepiCmd := &cobra.Command{
+		Use:   "stepi",
+		Short: "Step a single CPU instruction",
+		Long: `Execute exactly one CPU instruction (--count N for several), entering calls.
+For assembly, inlined code and runtime internals, where step moves a whole
+source line at once.
+
+The response adds the pc and the instruction now about to execute, in Go
+assembler syntax, with the called function for a CALL. Stepping several
+instructions stops early at a breakpoint or when the program exits.
+
+Example:
+  godebug --addr $ADDR stepi
+  godebug --addr $ADDR stepi --count 5`,
+		Args: cobra.NoArgs,
+		Run:  stepInstructions("stepi", false, &stepiCount),
+	}
+
+	nextiCmd := &cobra.Command{
+		Use:   "nexti",
+		Short: "Step a single CPU instruction, over calls",
+		Long: `Execute one CPU instruction (--count N for several) like stepi, but run a
+CALL instruction's function to its return instead of entering it.
+
+Example:
+  godebug --addr $ADDR nexti
+  godebug --addr $ADDR nexti --count 10`,
+		Args: cobra.NoArgs,
+		Run:  stepInstructions("nexti", true, &nextiCount),
+	}
+
+	stepiCmd.Flags().IntVar(&stepiCount, "count", 1, "Number of instructions to step")
+	nextiCmd.Flags().IntVar(&nextiCount, "count", 1, "Number of instructions to step")
+	root.AddCommand(stepiCmd, nextiCmd)
+}
+
+func init() {
+	addInstructionStepCommands(rootCmd, MustGetClient, GetOutputFormat, GetTimeout)
+}
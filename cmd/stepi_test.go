@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestAsmToMap checks a plain instruction and a CALL with its destination.
+func TestAsmToMap(t *testing.T) {
+	mov := asmToMap(api.AsmInstruction{
+		Loc:   api.Location{PC: 0x4a1b20, File: "/app/main.go", Line: 12},
+		Text:  "MOVQ AX, 0x8(SP)",
+		Bytes: []byte{0x48, 0x89, 0x44, 0x24, 0x08},
+	})
+	if mov["pc"] != "0x4a1b20" || mov["bytes"] != "4889442408" || mov["line"] != 12 {
+		t.Errorf("mov: got %v", mov)
+	}
+	if _, ok := mov["call"]; ok {
+		t.Errorf("mov has a call destination: %v", mov)
+	}
+
+	call := asmToMap(api.AsmInstruction{
+		Loc:     api.Location{PC: 0x4a1b25},
+		DestLoc: &api.Location{PC: 0x4a1c00, Function: &api.Function{Name_: "main.validate"}},
+		Text:    "CALL main.validate(SB)",
+	})
+	if dest, _ := call["call"].(map[string]any); dest["function"] != "main.validate" || dest["pc"] != "0x4a1c00" {
+		t.Errorf("call destination: got %v", call["call"])
+	}
+	if _, ok := call["file"]; ok {
+		t.Errorf("instruction without a file has one: %v", call)
+	}
+}
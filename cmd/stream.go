@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// streamLoadConfig loads deeper and wider than the default for --stream, where
+// the size of the tree no longer makes the response unreadable
+var streamLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 8,
+	MaxStringLen:       4096,
+	MaxArrayValues:     1024,
+	MaxStructFields:    -1,
+}
+
+// variableNode is one node of a variable tree streamed by --stream
+type variableNode struct {
+	Event string `json:"event"`
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+	Type  string `json:"type"`
+	Kind  string `json:"kind"`
+	Value string `json:"value,omitempty"`
+	// Len is the length of strings and collections, of which only Children
+	// elements were loaded when Truncated is set
+	Len        *int64 `json:"len,omitempty"`
+	Children   int    `json:"children,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	Unreadable string `json:"unreadable,omitempty"`
+}
+
+// streamVariable walks a variable tree depth-first and passes each node to
+// emit, parents before their children. Paths follow flattenVariable:
+// order.Items[2].Qty, m["key"]; pointers and interfaces are followed
+// transparently. It returns the number of nodes emitted.
+func streamVariable(path string, depth int, v api.Variable, emit func(variableNode)) int {
+	if (v.Kind == reflect.Ptr || v.Kind == reflect.Interface) && len(v.Children) == 1 {
+		return streamVariable(path, depth, v.Children[0], emit)
+	}
+
+	node := variableNode{
+		Event:      "node",
+		Path:       path,
+		Depth:      depth,
+		Type:       v.Type,
+		Kind:       v.Kind.String(),
+		Value:      v.Value,
+		Unreadable: v.Unreadable,
+	}
+	switch v.Kind {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		n := v.Len
+		node.Len = &n
+	}
+	if v.Kind == reflect.String {
+		node.Truncated = int64(len(v.Value)) < v.Len
+		emit(node)
+		return 1
+	}
+	loaded := len(v.Children)
+	if v.Kind == reflect.Map {
+		loaded /= 2
+	}
+	node.Children = loaded
+	switch v.Kind {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		node.Truncated = int64(loaded) < v.Len
+	}
+	emit(node)
+
+	count := 1
+	switch v.Kind {
+	case reflect.Map:
+		// Map children alternate key, value
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			key := v.Children[i]
+			count += streamVariable(fmt.Sprintf("%s[%s]", path, key.SinglelineString()), depth+1, v.Children[i+1], emit)
+		}
+	case reflect.Slice, reflect.Array:
+		for i, child := range v.Children {
+			count += streamVariable(fmt.Sprintf("%s[%d]", path, i), depth+1, child, emit)
+		}
+	default:
+		for _, child := range v.Children {
+			count += streamVariable(path+"."+child.Name, depth+1, child, emit)
+		}
+	}
+	return count
+}
+
+// emitVariable streams a variable tree as NDJSON node records
+func emitVariable(path string, v api.Variable) int {
+	return streamVariable(path, 0, v, func(node variableNode) { output.Emit(node) })
+}
+
+// emitVariables streams the trees of vars, each rooted at its name, and
+// returns the number of nodes emitted
+func emitVariables(vars []api.Variable) int {
+	nodes := 0
+	for _, v := range vars {
+		nodes += emitVariable(v.Name, v)
+	}
+	return nodes
+}
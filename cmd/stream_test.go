@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestStreamVariable checks node order, paths and truncation of a struct
+// holding a pointer, a truncated slice and a map.
+func TestStreamVariable(t *testing.T) {
+	order := api.Variable{
+		Name: "order", Type: "*main.Order", Kind: reflect.Ptr,
+		Children: []api.Variable{{
+			Type: "main.Order", Kind: reflect.Struct,
+			Children: []api.Variable{
+				{Name: "ID", Type: "int", Kind: reflect.Int, Value: "7"},
+				{Name: "Items", Type: "[]int", Kind: reflect.Slice, Len: 3, Children: []api.Variable{
+					{Type: "int", Kind: reflect.Int, Value: "1"},
+					{Type: "int", Kind: reflect.Int, Value: "2"},
+				}},
+				{Name: "Tags", Type: "map[string]bool", Kind: reflect.Map, Len: 1, Children: []api.Variable{
+					{Type: "string", Kind: reflect.String, Value: "vip", Len: 3},
+					{Type: "bool", Kind: reflect.Bool, Value: "true"},
+				}},
+			},
+		}},
+	}
+
+	var nodes []variableNode
+	count := streamVariable("order", 0, order, func(n variableNode) { nodes = append(nodes, n) })
+	want := []string{"order", "order.ID", "order.Items", "order.Items[0]", "order.Items[1]", "order.Tags", `order.Tags["vip"]`}
+	if count != len(want) || len(nodes) != len(want) {
+		t.Fatalf("got %d nodes (count %d), want %d: %+v", len(nodes), count, len(want), nodes)
+	}
+	for i, path := range want {
+		if nodes[i].Path != path {
+			t.Errorf("node %d: path %q, want %q", i, nodes[i].Path, path)
+		}
+	}
+	if root := nodes[0]; root.Type != "main.Order" || root.Children != 3 || root.Depth != 0 {
+		t.Errorf("pointer not followed: %+v", root)
+	}
+	if items := nodes[2]; !items.Truncated || items.Children != 2 || *items.Len != 3 {
+		t.Errorf("items: %+v", items)
+	}
+	if tags := nodes[5]; tags.Truncated || tags.Children != 1 {
+		t.Errorf("tags: %+v", tags)
+	}
+	if v := nodes[6]; v.Value != "true" || v.Depth != 2 {
+		t.Errorf("map value: %+v", v)
+	}
+}
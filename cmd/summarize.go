@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// sessionBrief is the content of a summarize response, ordered by importance
+type sessionBrief struct {
+	State       map[string]any   `json:"state"`
+	Breakpoints []map[string]any `json:"breakpoints"`
+	RecentStops []map[string]any `json:"recentStops"`
+	Watched     []map[string]any `json:"watched"`
+	Findings    []string         `json:"findings"`
+	Truncated   bool             `json:"truncated,omitempty"`
+}
+
+// trim drops one item from the least important non-empty section.
+// It returns false when there is nothing left to drop.
+func (b *sessionBrief) trim() bool {
+	switch {
+	case len(b.RecentStops) > 0:
+		b.RecentStops = b.RecentStops[:len(b.RecentStops)-1]
+	case len(b.Findings) > 0:
+		b.Findings = b.Findings[:len(b.Findings)-1]
+	case len(b.Watched) > 0:
+		b.Watched = b.Watched[:len(b.Watched)-1]
+	case len(b.Breakpoints) > 0:
+		b.Breakpoints = b.Breakpoints[:len(b.Breakpoints)-1]
+	default:
+		return false
+	}
+	b.Truncated = true
+	return true
+}
+
+// markdown renders the brief for inclusion in a prompt
+func (b *sessionBrief) markdown() string {
+	var sb strings.Builder
+	sb.WriteString("## Debug session\n\n")
+	switch {
+	case b.State["exited"] == true:
+		fmt.Fprintf(&sb, "- Process exited (status %v)\n", b.State["exitStatus"])
+	case b.State["running"] == true:
+		sb.WriteString("- Process running\n")
+	default:
+		sb.WriteString("- Process paused")
+		if loc, ok := b.State["location"].(map[string]any); ok {
+			fmt.Fprintf(&sb, " at %v:%v in %v", loc["file"], loc["line"], loc["function"])
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(b.Breakpoints) > 0 {
+		sb.WriteString("\n### Breakpoints\n\n")
+		for _, bp := range b.Breakpoints {
+			fmt.Fprintf(&sb, "- #%v %v (%v)", bp["id"], bp["location"], bp["function"])
+			if cond, ok := bp["condition"]; ok {
+				fmt.Fprintf(&sb, " if %v", cond)
+			}
+			if hits, ok := bp["hitCount"]; ok {
+				fmt.Fprintf(&sb, ", %v hits", hits)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(b.RecentStops) > 0 {
+		sb.WriteString("\n### Recent stops (newest first)\n\n")
+		for _, stop := range b.RecentStops {
+			fmt.Fprintf(&sb, "- %v → %v\n", stop["command"], stop["location"])
+		}
+	}
+
+	if len(b.Watched) > 0 {
+		sb.WriteString("\n### Watched expressions\n\n")
+		for _, w := range b.Watched {
+			fmt.Fprintf(&sb, "- `%v` at breakpoint #%v\n", w["expression"], w["breakpointId"])
+		}
+	}
+
+	if len(b.Findings) > 0 {
+		sb.WriteString("\n### Findings\n\n")
+		for _, f := range b.Findings {
+			fmt.Fprintf(&sb, "- %s\n", f)
+		}
+	}
+
+	if b.Truncated {
+		sb.WriteString("\n_(truncated to fit the token budget)_\n")
+	}
+	return sb.String()
+}
+
+// addSummarizeCommand adds the summarize command
+func addSummarizeCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var summarizeMaxTokens int
+	var summarizeFormat string
+
+	summarizeCmd := &cobra.Command{
+		Use:   "summarize",
+		Short: "Produce a compact brief of the debug session",
+		Long: `Compile the current state, breakpoints, recent stops, watched
+expressions and notable findings into one compact brief.
+
+The brief is trimmed (least important items first) until it fits the
+--max-tokens budget, so a fresh agent can be re-grounded with one call.
+
+Formats:
+  json (default) - Structured brief in data
+  markdown       - Brief rendered as Markdown in data.brief
+
+Example:
+  godebug --addr $ADDR summarize
+  godebug --addr $ADDR summarize --format markdown --max-tokens 500`,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("summarize")
+			defer func() { _ = c.Close() }()
+
+			if summarizeFormat != "json" && summarizeFormat != "markdown" {
+				output.ErrorWithInfo("summarize", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid format: %s (expected json or markdown)", summarizeFormat),
+					map[string]any{"format": summarizeFormat},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("summarize", err).PrintAndExit(getOutputFormat())
+			}
+
+			brief := &sessionBrief{
+				State:       stateToData(state),
+				Breakpoints: []map[string]any{},
+				RecentStops: []map[string]any{},
+				Watched:     []map[string]any{},
+				Findings:    []string{},
+			}
+
+			bps, err := c.ListBreakpoints()
+			if err != nil {
+				output.Error("summarize", err).PrintAndExit(getOutputFormat())
+			}
+			hot := 0
+			for _, bp := range userBreakpoints(bps) {
+				bpData := map[string]any{
+					"id":       bp.ID,
+					"location": fmt.Sprintf("%s:%d", bp.File, bp.Line),
+					"function": bp.FunctionName,
+				}
+				if bp.Cond != "" {
+					bpData["condition"] = bp.Cond
+				}
+				if bp.TotalHitCount > 0 {
+					bpData["hitCount"] = bp.TotalHitCount
+				}
+				if bp.TotalHitCount >= 100 {
+					hot++
+				}
+				brief.Breakpoints = append(brief.Breakpoints, bpData)
+				for _, expr := range bp.Variables {
+					brief.Watched = append(brief.Watched, map[string]any{
+						"breakpointId": bp.ID,
+						"expression":   expr,
+					})
+				}
+			}
+
+			stops := loadStops(c.Addr())
+			for i := len(stops) - 1; i >= 0; i-- {
+				stop := stops[i]
+				stopData := map[string]any{
+					"command": stop.Command,
+					"time":    stop.Time,
+				}
+				if stop.Exited {
+					stopData["location"] = fmt.Sprintf("exited (%d)", stop.ExitStatus)
+				} else {
+					stopData["location"] = fmt.Sprintf("%s:%d %s", stop.File, stop.Line, stop.Function)
+				}
+				if stop.BreakpointID > 0 {
+					stopData["breakpointId"] = stop.BreakpointID
+				}
+				brief.RecentStops = append(brief.RecentStops, stopData)
+			}
+
+			// Findings are cheap observations worth an agent's attention
+			if sess, err := session.Load(c.Addr()); err == nil {
+				if changed := sess.ChangedSources(); len(changed) > 0 {
+					brief.Findings = append(brief.Findings, fmt.Sprintf("%d source files changed since the last build; run reload", len(changed)))
+				}
+			}
+			if hot > 0 {
+				brief.Findings = append(brief.Findings, fmt.Sprintf("%d breakpoints hit 100+ times; consider --cond", hot))
+			}
+			if !state.Running && !state.Exited {
+				if goroutines, _, err := c.ListGoroutines(0, 0); err == nil {
+					locations := map[string]int{}
+					for _, g := range goroutines {
+						if g.UserCurrentLoc.File != "" {
+							locations[fmt.Sprintf("%s:%d", g.UserCurrentLoc.File, g.UserCurrentLoc.Line)]++
+						}
+					}
+					brief.Findings = append(brief.Findings, fmt.Sprintf("%d goroutines", len(goroutines)))
+					// Many goroutines parked on the same user line often indicate a leak
+					var piles []string
+					for loc, n := range locations {
+						if n >= 10 {
+							piles = append(piles, fmt.Sprintf("%d goroutines at %s", n, loc))
+						}
+					}
+					sort.Strings(piles)
+					brief.Findings = append(brief.Findings, piles...)
+				}
+			}
+
+			estimate := func() int {
+				if summarizeFormat == "markdown" {
+					return output.EstimateTextTokens(brief.markdown())
+				}
+				return output.EstimateTokens(brief)
+			}
+			for summarizeMaxTokens > 0 && estimate() > summarizeMaxTokens {
+				if !brief.trim() {
+					break
+				}
+			}
+
+			var data any = brief
+			if summarizeFormat == "markdown" {
+				data = map[string]any{
+					"brief":     brief.markdown(),
+					"truncated": brief.Truncated,
+				}
+			}
+
+			output.Success("summarize", data, fmt.Sprintf("Session brief (~%d tokens)", estimate())).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	summarizeCmd.Flags().IntVar(&summarizeMaxTokens, "max-tokens", 1000, "Approximate token budget for the brief (0 = unlimited)")
+	summarizeCmd.Flags().StringVar(&summarizeFormat, "format", "json", "Brief format: json or markdown")
+	root.AddCommand(summarizeCmd)
+}
+
+func init() {
+	addSummarizeCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
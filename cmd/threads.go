@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// threadToMap converts a thread for JSON output. Delve identifies threads by
+// their OS thread ID (the TID on Linux).
+func threadToMap(t *api.Thread, currentID int) map[string]any {
+	m := map[string]any{
+		"id":      t.ID,
+		"current": t.ID == currentID,
+		"pc":      fmt.Sprintf("%#x", t.PC),
+	}
+	if t.File != "" {
+		loc := map[string]any{
+			"file": t.File,
+			"line": t.Line,
+		}
+		if t.Function != nil {
+			loc["function"] = t.Function.Name()
+		}
+		m["location"] = loc
+	}
+	// A thread without a goroutine is idle in the scheduler or runs C code
+	if t.GoroutineID != 0 {
+		m["goroutineId"] = t.GoroutineID
+	}
+	if t.Breakpoint != nil {
+		m["breakpointId"] = t.Breakpoint.ID
+	}
+	return m
+}
+
+// addThreadsCommands adds the threads and thread commands
+func addThreadsCommands(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	threadsCmd := &cobra.Command{
+		Use:   "threads",
+		Short: "List the OS threads of the target",
+		Long: `List the OS threads of the debugged process with their thread ID (the TID on
+Linux), where each is stopped and the goroutine it is running, if any.
+Threads without a goroutine are idle in the scheduler or run C code.
+
+The current thread is the one that stopped the process; thread switches to
+another one. analyze threads diagnoses threads blocked in syscalls or cgo.
+
+Example:
+  godebug --addr $ADDR threads`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			c := mustGetClient("threads")
+			defer func() { _ = c.Close() }()
+
+			threads, err := c.ListThreads()
+			if err != nil {
+				output.Error("threads", err).PrintAndExit(getOutputFormat())
+			}
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("threads", err).PrintAndExit(getOutputFormat())
+			}
+			currentID := 0
+			if state.CurrentThread != nil {
+				currentID = state.CurrentThread.ID
+			}
+
+			sort.Slice(threads, func(i, j int) bool { return threads[i].ID < threads[j].ID })
+			list := make([]map[string]any, len(threads))
+			withGoroutine := 0
+			for i, t := range threads {
+				list[i] = threadToMap(t, currentID)
+				if t.GoroutineID != 0 {
+					withGoroutine++
+				}
+			}
+
+			data := map[string]any{
+				"threads":       list,
+				"count":         len(list),
+				"withGoroutine": withGoroutine,
+			}
+			if currentID != 0 {
+				data["currentId"] = currentID
+			}
+			output.Success("threads", data, fmt.Sprintf("%d threads, %d with a goroutine", len(list), withGoroutine)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	threadCmd := &cobra.Command{
+		Use:   "thread <id>",
+		Short: "Switch to an OS thread",
+		Long: `Make the OS thread with the given ID (see threads) the current thread. The
+goroutine it runs becomes the selected goroutine, so locals, stack and eval
+read it. Switching to a thread without a goroutine leaves none selected.
+
+Example:
+  godebug --addr $ADDR threads
+  godebug --addr $ADDR thread 48213`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				output.ErrorWithInfo("thread", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid thread ID: %s", args[0]),
+					map[string]any{"id": args[0]},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("thread")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.SwitchThread(id)
+			if err != nil {
+				if threads, listErr := c.ListThreads(); listErr == nil && !hasThread(threads, id) {
+					ids := make([]int, len(threads))
+					for i, t := range threads {
+						ids[i] = t.ID
+					}
+					sort.Ints(ids)
+					output.ErrorWithInfo("thread", output.NotFound("thread", args[0]).WithDetails(map[string]any{
+						"threads": ids,
+					})).PrintAndExit(getOutputFormat())
+				}
+				output.Error("thread", err).PrintAndExit(getOutputFormat())
+			}
+
+			data := map[string]any{"id": id}
+			if state.CurrentThread != nil {
+				data = threadToMap(state.CurrentThread, state.CurrentThread.ID)
+			}
+			if g := state.SelectedGoroutine; g != nil {
+				data["goroutineId"] = g.ID
+			}
+			output.Success("thread", data, fmt.Sprintf("Switched to thread %d", id)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	root.AddCommand(threadsCmd)
+	root.AddCommand(threadCmd)
+}
+
+// hasThread reports whether a thread with the ID exists
+func hasThread(threads []*api.Thread, id int) bool {
+	for _, t := range threads {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	addThreadsCommands(rootCmd, MustGetClient, GetOutputFormat)
+}
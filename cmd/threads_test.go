@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestThreadToMap checks the fields of a thread with and without a goroutine.
+func TestThreadToMap(t *testing.T) {
+	running := threadToMap(&api.Thread{
+		ID: 4242, PC: 0x4a1b20, File: "/app/main.go", Line: 12,
+		Function: &api.Function{Name_: "main.work"}, GoroutineID: 7,
+	}, 4242)
+	if running["current"] != true || running["goroutineId"] != int64(7) || running["pc"] != "0x4a1b20" {
+		t.Errorf("running thread: got %v", running)
+	}
+	if loc, _ := running["location"].(map[string]any); loc["function"] != "main.work" {
+		t.Errorf("running thread location: got %v", running["location"])
+	}
+
+	idle := threadToMap(&api.Thread{ID: 4243, PC: 0x46e1c3}, 4242)
+	if idle["current"] != false {
+		t.Errorf("idle thread is current: %v", idle)
+	}
+	if _, ok := idle["goroutineId"]; ok {
+		t.Errorf("idle thread has a goroutine: %v", idle)
+	}
+	if _, ok := idle["location"]; ok {
+		t.Errorf("thread without a file has a location: %v", idle)
+	}
+}
@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Tool manifest formats
+const (
+	toolspecOpenAI    = "openai"
+	toolspecAnthropic = "anthropic"
+	toolspecMCP       = "mcp"
+)
+
+// toolspecSkipFlags are global flags the tool runner sets itself
+var toolspecSkipFlags = map[string]bool{"output": true, "help": true}
+
+// usageArg matches a positional argument placeholder: <name>, [name], with an
+// optional ... for repeated arguments
+var usageArg = regexp.MustCompile(`^([<\[])([^>\]]+)[>\]](\.\.\.)?$`)
+
+// toolParam is a tool input derived from a positional argument or a flag
+type toolParam struct {
+	Name       string
+	Schema     map[string]any
+	Required   bool
+	Positional bool
+	Global     bool // a persistent flag of the root command
+}
+
+// toolDef is a runnable command described as a tool. Its positional
+// parameters come first, in usage order; all others are flags.
+type toolDef struct {
+	Name        string
+	Description string
+	Short       string
+	Usage       string
+	Path        []string
+	Params      []toolParam
+}
+
+// nonParamChars are the characters not allowed in tool and property names
+var nonParamChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// paramName turns a placeholder or flag name into a schema property name
+func paramName(name string) string {
+	return strings.Trim(nonParamChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+// flagSchema describes a flag's value as a JSON schema
+func flagSchema(f *pflag.Flag) map[string]any {
+	schema := map[string]any{"description": f.Usage}
+	switch f.Value.Type() {
+	case "bool":
+		schema["type"] = "boolean"
+		if f.DefValue == "true" {
+			schema["default"] = true
+		}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		schema["type"] = "integer"
+		if n, err := strconv.ParseInt(f.DefValue, 10, 64); err == nil && n != 0 {
+			schema["default"] = n
+		}
+	case "float32", "float64":
+		schema["type"] = "number"
+		if n, err := strconv.ParseFloat(f.DefValue, 64); err == nil && n != 0 {
+			schema["default"] = n
+		}
+	case "stringArray", "stringSlice", "intSlice":
+		schema["type"] = "array"
+		schema["items"] = map[string]any{"type": "string"}
+	case "duration":
+		schema["type"] = "string"
+		schema["default"] = f.DefValue
+	default:
+		schema["type"] = "string"
+		if f.DefValue != "" {
+			schema["default"] = f.DefValue
+		}
+	}
+	return schema
+}
+
+// usageParams derives the positional parameters from a command's Use line.
+// A Use line that is not a plain list of placeholders becomes one args array.
+func usageParams(cmd *cobra.Command) []toolParam {
+	fields := strings.Fields(cmd.Use)[1:]
+	params := make([]toolParam, 0, len(fields))
+	for i, field := range fields {
+		m := usageArg.FindStringSubmatch(field)
+		if m == nil {
+			return []toolParam{{
+				Name: "args",
+				Schema: map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Positional arguments: " + strings.Join(fields, " "),
+				},
+				Required:   cmd.Args != nil && cmd.Args(cmd, nil) != nil,
+				Positional: true,
+			}}
+		}
+		schema := map[string]any{"type": "string", "description": fmt.Sprintf("Positional argument %d (%s)", i+1, m[2])}
+		if m[3] != "" {
+			schema = map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": fmt.Sprintf("Positional arguments from %d on (%s...)", i+1, m[2])}
+			if m[1] == "<" {
+				schema["minItems"] = 1
+			}
+		}
+		params = append(params, toolParam{Name: paramName(m[2]), Schema: schema, Required: m[1] == "<", Positional: true})
+	}
+	return params
+}
+
+// commandTool describes a runnable command as a tool
+func commandTool(cmd *cobra.Command, brief bool) toolDef {
+	path := strings.Fields(cmd.CommandPath())[1:]
+	desc := cmd.Short
+	if !brief && cmd.Long != "" {
+		desc = cmd.Short + "\n\n" + cmd.Long
+	}
+	usage := append([]string{cmd.Root().Name()}, path[:len(path)-1]...)
+	usageLine := strings.Join(append(usage, cmd.Use), " ")
+	desc += "\n\nUsage: " + usageLine
+	tool := toolDef{
+		Name:        paramName(cmd.Root().Name() + "_" + strings.Join(path, "_")),
+		Description: desc,
+		Short:       cmd.Short,
+		Usage:       usageLine,
+		Path:        path,
+	}
+
+	taken := map[string]bool{}
+	for _, p := range usageParams(cmd) {
+		tool.Params = append(tool.Params, p)
+		taken[p.Name] = true
+	}
+	rootFlags := cmd.Root().PersistentFlags()
+	addFlag := func(f *pflag.Flag) {
+		if f.Hidden || toolspecSkipFlags[f.Name] || taken[f.Name] {
+			return
+		}
+		taken[f.Name] = true
+		tool.Params = append(tool.Params, toolParam{Name: f.Name, Schema: flagSchema(f), Global: rootFlags.Lookup(f.Name) == f})
+	}
+	cmd.LocalNonPersistentFlags().VisitAll(addFlag)
+	cmd.InheritedFlags().VisitAll(addFlag)
+	cmd.PersistentFlags().VisitAll(addFlag)
+	return tool
+}
+
+// collectTools describes every runnable, visible command below root
+func collectTools(root *cobra.Command, brief bool) []toolDef {
+	var tools []toolDef
+	var walk func(*cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, sub := range c.Commands() {
+			// Shell completion is no use to an agent
+			if sub.Hidden || !sub.IsAvailableCommand() || sub.Name() == "toolspec" || sub.Name() == "agent-prompt" || sub.Name() == "completion" {
+				continue
+			}
+			if sub.Runnable() {
+				tools = append(tools, commandTool(sub, brief))
+			}
+			walk(sub)
+		}
+	}
+	walk(root)
+	return tools
+}
+
+// selectTools keeps the tools of the named commands (subcommands as
+// "analyze threads"), or all of them without names. It returns the first
+// name that matches no command.
+func selectTools(tools []toolDef, names []string) ([]toolDef, string) {
+	if len(names) == 0 {
+		return tools, ""
+	}
+	selected := make([]toolDef, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, t := range tools {
+			if strings.Join(t.Path, " ") == name {
+				selected = append(selected, t)
+				found = true
+			}
+		}
+		if !found {
+			return nil, name
+		}
+	}
+	return selected, ""
+}
+
+// inputSchema is the JSON schema of a tool's parameters
+func (t toolDef) inputSchema() map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for _, p := range t.Params {
+		properties[p.Name] = p.Schema
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// responseSchema is the JSON schema of the response envelope every command prints
+func responseSchema() map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"success", "command"},
+		"properties": map[string]any{
+			"success": map[string]any{"type": "boolean", "description": "Whether the command succeeded"},
+			"command": map[string]any{"type": "string", "description": "Command that produced the response"},
+			"data":    map[string]any{"type": "object", "description": "Command specific result"},
+			"message": map[string]any{"type": "string", "description": "One-line summary"},
+			"error": map[string]any{
+				"type":        "object",
+				"description": "Code, message and details of a failure",
+				"required":    []string{"code", "message"},
+				"properties": map[string]any{
+					"code":    map[string]any{"type": "string", "description": "Machine readable code such as NOT_FOUND or INSPECT_WHILE_RUNNING"},
+					"message": map[string]any{"type": "string"},
+					"details": map[string]any{"description": "Additional context"},
+				},
+			},
+			"meta":  map[string]any{"type": "object", "description": "Token estimates, with --estimate-tokens or --budget-tokens; retry counts, with --retry-on"},
+			"state": map[string]any{"type": "object", "description": "Stop context, with --with-state"},
+		},
+	}
+}
+
+// toolManifest renders the tools in the requested format
+func toolManifest(tools []toolDef, format string) any {
+	switch format {
+	case toolspecOpenAI:
+		out := make([]map[string]any, len(tools))
+		for i, t := range tools {
+			out[i] = map[string]any{
+				"type": "function",
+				"function": map[string]any{
+					"name":        t.Name,
+					"description": t.Description,
+					"parameters":  t.inputSchema(),
+				},
+			}
+		}
+		return out
+	case toolspecAnthropic:
+		out := make([]map[string]any, len(tools))
+		for i, t := range tools {
+			out[i] = map[string]any{
+				"name":         t.Name,
+				"description":  t.Description,
+				"input_schema": t.inputSchema(),
+			}
+		}
+		return out
+	default:
+		out := make([]map[string]any, len(tools))
+		for i, t := range tools {
+			out[i] = map[string]any{
+				"name":         t.Name,
+				"description":  t.Description,
+				"inputSchema":  t.inputSchema(),
+				"outputSchema": responseSchema(),
+			}
+		}
+		return map[string]any{"tools": out}
+	}
+}
+
+// addToolspecCommand adds the toolspec command
+func addToolspecCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat) {
+	var toolspecFormat string
+	var toolspecBrief bool
+	var toolspecCommands []string
+
+	toolspecCmd := &cobra.Command{
+		Use:   "toolspec",
+		Short: "Print tool definitions of every command for agent frameworks",
+		Long: `Print a JSON tool definition for every command, ready to register with an
+agent framework:
+
+  openai      Chat Completions / Responses function tools
+  anthropic   Messages API tools (input_schema)
+  mcp         MCP tools/list result, with the response envelope as outputSchema
+
+Each tool is named godebug_<command path> (godebug_break, godebug_analyze_threads).
+Its parameters are the command's flags under their flag names plus the
+positional arguments named in its usage line. To run a tool call, pass the
+positional parameters in usage order and every other parameter as --name value
+(booleans as --name when true), and add --output json. Global flags such as
+addr and timeout are parameters of every tool.
+
+Unlike other commands, toolspec prints the manifest itself rather than the
+response envelope, so it can be redirected into a file.
+
+Example:
+  godebug toolspec --format anthropic > tools.json
+  godebug toolspec --format openai --brief --command break --command continue`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if toolspecFormat != toolspecOpenAI && toolspecFormat != toolspecAnthropic && toolspecFormat != toolspecMCP {
+				output.ErrorWithInfo("toolspec", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("unknown format %q", toolspecFormat),
+					map[string]any{"format": toolspecFormat, "formats": []string{toolspecOpenAI, toolspecAnthropic, toolspecMCP}},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			tools, missing := selectTools(collectTools(cmd.Root(), toolspecBrief), toolspecCommands)
+			if missing != "" {
+				output.ErrorWithInfo("toolspec", output.NotFound("command", missing)).PrintAndExit(getOutputFormat())
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(toolManifest(tools, toolspecFormat)); err != nil {
+				output.Error("toolspec", err).PrintAndExit(getOutputFormat())
+			}
+		},
+	}
+
+	toolspecCmd.Flags().StringVar(&toolspecFormat, "format", toolspecAnthropic, "Manifest format: openai, anthropic or mcp")
+	toolspecCmd.Flags().BoolVar(&toolspecBrief, "brief", false, "Use only the one-line summary as description")
+	toolspecCmd.Flags().StringArrayVar(&toolspecCommands, "command", nil, "Only this command (repeatable, subcommands as \"analyze threads\")")
+	root.AddCommand(toolspecCmd)
+}
+
+func init() {
+	addToolspecCommand(rootCmd, GetOutputFormat)
+}
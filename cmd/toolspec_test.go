@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"regexp"
+	"testing"
+)
+
+// TestToolspecCoversCommands checks that every command becomes a tool with a
+// valid name and that positional arguments are parsed from the usage line.
+func TestToolspecCoversCommands(t *testing.T) {
+	root := NewRootCmd()
+	tools := collectTools(root, false)
+	validName := regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+	byName := map[string]toolDef{}
+	for _, tool := range tools {
+		if !validName.MatchString(tool.Name) {
+			t.Errorf("invalid tool name %q", tool.Name)
+		}
+		if _, dup := byName[tool.Name]; dup {
+			t.Errorf("duplicate tool name %q", tool.Name)
+		}
+		byName[tool.Name] = tool
+	}
+
+	for _, name := range []string{"godebug_break", "godebug_analyze_threads", "godebug_scenario_run", "godebug_eval"} {
+		if _, ok := byName[name]; !ok {
+			t.Errorf("missing tool %s", name)
+		}
+	}
+
+	eval := byName["godebug_eval"].inputSchema()
+	if req, _ := eval["required"].([]string); len(req) != 1 || req[0] != "expression" {
+		t.Errorf("eval required = %v, want [expression]", eval["required"])
+	}
+	props := eval["properties"].(map[string]any)
+	for _, p := range []string{"expression", "addr", "timeout"} {
+		if _, ok := props[p]; !ok {
+			t.Errorf("eval is missing parameter %s", p)
+		}
+	}
+	if _, ok := props["output"]; ok {
+		t.Errorf("eval exposes the output flag")
+	}
+}
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Why a trace ended
+const (
+	traceStopDuration   = "duration"   // --duration expired
+	traceStopCount      = "count"      // --count events were emitted
+	traceStopInterrupt  = "interrupt"  // SIGINT or SIGTERM
+	traceStopBreakpoint = "breakpoint" // the target stopped on something other than a tracepoint
+	traceStopExited     = "exited"     // the process exited
+)
+
+// traceLoadConfig keeps traced arguments and return values short, as they are
+// loaded on every call
+var traceLoadConfig = api.LoadConfig{
+	FollowPointers:     false,
+	MaxVariableRecurse: 1,
+	MaxStringLen:       64,
+	MaxArrayValues:     16,
+	MaxStructFields:    3,
+}
+
+// traceValue is an argument or return value of a traced call
+type traceValue struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// traceEvent is one NDJSON record emitted by trace
+type traceEvent struct {
+	Event string `json:"event"` // call or return
+	eventTimestamp
+	Function  string       `json:"function"`
+	Goroutine int64        `json:"goroutine"`
+	File      string       `json:"file,omitempty"`
+	Line      int          `json:"line,omitempty"`
+	Args      []traceValue `json:"args,omitempty"`
+	Returns   []traceValue `json:"returns,omitempty"`
+	Stack     []string     `json:"stack,omitempty"`
+}
+
+// traceValues flattens variables onto one line each
+func traceValues(vars []api.Variable) []traceValue {
+	values := make([]traceValue, 0, len(vars))
+	for _, v := range vars {
+		value := v.Value
+		if len(v.Children) > 0 {
+			value = v.SinglelineString()
+		}
+		values = append(values, traceValue{Name: v.Name, Type: v.Type, Value: value})
+	}
+	return values
+}
+
+// traceEvents returns the events of the threads stopped at tracepoints, and
+// whether every stopped thread is at one
+func traceEvents(state *api.DebuggerState, ts eventTimestamp) ([]traceEvent, bool) {
+	var events []traceEvent
+	onlyTrace := false
+	for _, th := range state.Threads {
+		bp := th.Breakpoint
+		if bp == nil {
+			continue
+		}
+		if !bp.Tracepoint && !bp.TraceReturn {
+			return events, false
+		}
+		onlyTrace = true
+		ev := traceEvent{Event: "call", eventTimestamp: ts, Goroutine: th.GoroutineID, File: th.File, Line: th.Line}
+		if th.Function != nil {
+			ev.Function = th.Function.Name()
+		}
+		if bp.TraceReturn {
+			ev.Event = "return"
+			ev.Returns = traceValues(th.ReturnValues)
+		}
+		if info := th.BreakpointInfo; info != nil {
+			if !bp.TraceReturn {
+				ev.Args = traceValues(info.Arguments)
+			}
+			for _, f := range info.Stacktrace {
+				if f.Function != nil {
+					ev.Stack = append(ev.Stack, fmt.Sprintf("%s %s:%d", f.Function.Name(), f.File, f.Line))
+				}
+			}
+		}
+		events = append(events, ev)
+	}
+	return events, onlyTrace
+}
+
+// traceContinue resumes the target, loading return values, until it stops or
+// a reason to end the trace arrives on halt, in which case the target is
+// halted and the reason returned
+func traceContinue(c *debugger.Client, halt <-chan string) (*api.DebuggerState, string, error) {
+	type result struct {
+		state *api.DebuggerState
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		state, err := c.ContinueLoadingReturns(context.Background(), traceLoadConfig)
+		done <- result{state, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.state, "", r.err
+	case reason := <-halt:
+		if _, err := c.Halt(); err != nil {
+			return nil, reason, err
+		}
+		r := <-done
+		return r.state, reason, r.err
+	}
+}
+
+// traceHalts delivers the reasons to halt a running trace on halt: the
+// duration expiring (0 = never) or SIGINT/SIGTERM. finished stops watching.
+func traceHalts(duration time.Duration) (halt <-chan string, finished func()) {
+	ch := make(chan string, 1)
+	done := make(chan struct{})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	var deadline <-chan time.Time
+	if duration > 0 {
+		deadline = time.After(duration)
+	}
+	go func() {
+		defer signal.Stop(sigs)
+		select {
+		case <-deadline:
+			ch <- traceStopDuration
+		case <-sigs:
+			ch <- traceStopInterrupt
+		case <-done:
+		}
+	}()
+	return ch, func() { close(done) }
+}
+
+// addTraceCommand adds the trace command
+func addTraceCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var traceDuration time.Duration
+	var traceCount, traceMaxFuncs, traceStack int
+	var traceNoReturns, traceKeep bool
+
+	traceCmd := &cobra.Command{
+		Use:   "trace <regexp>",
+		Short: "Trace calls to the functions matching a regexp",
+		Long: `Set tracepoints on every function matching the regexp and let the program
+run, emitting one NDJSON line per call with its arguments and one per return
+with its return values, like dlv trace. The final line is the usual response
+with the traced functions, the number of calls per function and why the
+trace ended.
+
+Tracing ends after --duration, after --count events, on SIGINT, when the
+program stops on an ordinary breakpoint or when it exits. The tracepoints
+are cleared afterwards unless --keep, and the target is left stopped.
+
+Anchor the regexp: tracing runtime functions slows the program down badly,
+so more than --max-funcs matches are refused.
+
+Example:
+  godebug --addr $ADDR trace '^main\.handle' --duration 30s
+  godebug --addr $ADDR trace '^github.com/acme/store\.\(\*Store\)\.' --count 100 --stack 3`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			pattern := args[0]
+			if traceDuration <= 0 && traceCount <= 0 {
+				output.ErrorWithInfo("trace", output.InvalidArgument("--count or --duration must be > 0")).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("trace")
+			defer func() { _ = c.Close() }()
+
+			funcs, err := c.ListFunctions(pattern)
+			if err != nil {
+				output.ErrorWithInfo("trace", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid function regexp: %v", err),
+					map[string]any{"regexp": pattern},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if len(funcs) == 0 {
+				output.ErrorWithInfo("trace", output.NotFound("function matching", pattern)).PrintAndExit(getOutputFormat())
+			}
+			if traceMaxFuncs > 0 && len(funcs) > traceMaxFuncs {
+				output.ErrorWithInfo("trace", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("%d functions match %s (more than --max-funcs %d); narrow the regexp", len(funcs), pattern, traceMaxFuncs),
+					map[string]any{"regexp": pattern, "matches": len(funcs), "sample": funcs[:min(len(funcs), 20)]},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			// Set the tracepoints, remembering them to clear them afterwards
+			var created []int
+			var traced []string
+			var skipped []map[string]any
+			for _, fn := range funcs {
+				bp, err := c.CreateBreakpoint(&api.Breakpoint{
+					FunctionName: fn,
+					Tracepoint:   true,
+					Line:         -1,
+					Stacktrace:   traceStack,
+					LoadArgs:     &traceLoadConfig,
+				})
+				if err != nil {
+					skipped = append(skipped, map[string]any{"function": fn, "error": err.Error()})
+					continue
+				}
+				created = append(created, bp.ID)
+				traced = append(traced, fn)
+				if traceNoReturns {
+					continue
+				}
+				addrs, err := c.FunctionReturnLocations(fn)
+				if err != nil {
+					skipped = append(skipped, map[string]any{"function": fn, "returns": true, "error": err.Error()})
+					continue
+				}
+				for _, addr := range addrs {
+					bp, err := c.CreateBreakpoint(&api.Breakpoint{
+						Addr:        addr,
+						TraceReturn: true,
+						Line:        -1,
+						Stacktrace:  traceStack,
+						LoadArgs:    &traceLoadConfig,
+					})
+					if err == nil {
+						created = append(created, bp.ID)
+					}
+				}
+			}
+			// PrintAndExit skips deferred calls, so clearing is explicit
+			clearTracepoints := func() {
+				if traceKeep {
+					return
+				}
+				for _, id := range created {
+					_, _ = c.ClearBreakpoint(id)
+				}
+			}
+			if len(traced) == 0 {
+				output.ErrorWithInfo("trace", output.NewErrorInfo(output.ErrCodeInternalError,
+					fmt.Sprintf("no tracepoint could be set on the functions matching %s", pattern)).WithDetails(map[string]any{"skipped": skipped})).PrintAndExit(getOutputFormat())
+			}
+
+			halt, finished := traceHalts(traceDuration)
+
+			start := time.Now()
+			calls := map[string]int{}
+			events := 0
+			var state *api.DebuggerState
+			stoppedBy := ""
+			for stoppedBy == "" {
+				var reason string
+				state, reason, err = traceContinue(c, halt)
+				if err != nil {
+					clearTracepoints()
+					output.Error("trace", err).PrintAndExit(getOutputFormat())
+				}
+				if state.Exited {
+					stoppedBy = traceStopExited
+					break
+				}
+				stepEvents, onlyTrace := traceEvents(state, newEventTimestamp(c.Addr(), time.Now()))
+				for _, ev := range stepEvents {
+					if traceCount > 0 && events >= traceCount {
+						break
+					}
+					output.Emit(ev)
+					events++
+					if ev.Event == "call" {
+						calls[ev.Function]++
+					}
+				}
+				switch {
+				case reason != "":
+					stoppedBy = reason
+				case !onlyTrace:
+					stoppedBy = traceStopBreakpoint
+				case traceCount > 0 && events >= traceCount:
+					stoppedBy = traceStopCount
+				}
+			}
+			finished()
+
+			clearTracepoints()
+			recordStop(c, "trace", state)
+
+			data := map[string]any{
+				"regexp":    pattern,
+				"functions": traced,
+				"events":    events,
+				"calls":     calls,
+				"stoppedBy": stoppedBy,
+				"elapsedMs": time.Since(start).Milliseconds(),
+				"state":     stateToData(state),
+			}
+			if len(skipped) > 0 {
+				data["skipped"] = skipped
+			}
+			if traceKeep {
+				data["tracepoints"] = created
+			}
+			msg := fmt.Sprintf("%d events from %d traced functions; stopped by %s", events, len(traced), stoppedBy)
+			output.Success("trace", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	traceCmd.Flags().DurationVar(&traceDuration, "duration", 10*time.Second, "Stop tracing after this long (0 = no limit)")
+	traceCmd.Flags().IntVar(&traceCount, "count", 0, "Stop after this many events (0 = no limit)")
+	traceCmd.Flags().IntVar(&traceMaxFuncs, "max-funcs", 50, "Refuse regexps matching more functions (0 = no limit)")
+	traceCmd.Flags().IntVar(&traceStack, "stack", 0, "Frames of stack recorded with each event")
+	traceCmd.Flags().BoolVar(&traceNoReturns, "no-returns", false, "Trace calls only, not returns")
+	traceCmd.Flags().BoolVar(&traceKeep, "keep", false, "Leave the tracepoints set afterwards")
+	root.AddCommand(traceCmd)
+}
+
+func init() {
+	addTraceCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestTraceEvents checks call and return events and that an ordinary
+// breakpoint ends the trace.
+func TestTraceEvents(t *testing.T) {
+	fn := &api.Function{Name_: "main.handle"}
+	state := &api.DebuggerState{Threads: []*api.Thread{
+		{GoroutineID: 1, Function: fn, Breakpoint: &api.Breakpoint{Tracepoint: true},
+			BreakpointInfo: &api.BreakpointInfo{Arguments: []api.Variable{{Name: "id", Type: "int", Value: "7"}}}},
+		{GoroutineID: 2, Function: fn, Breakpoint: &api.Breakpoint{TraceReturn: true},
+			ReturnValues: []api.Variable{{Name: "~r0", Type: "error", Value: "nil"}}},
+		{GoroutineID: 3},
+	}}
+	events, onlyTrace := traceEvents(state, eventTimestamp{})
+	if !onlyTrace || len(events) != 2 {
+		t.Fatalf("traceEvents = %d events, onlyTrace %v", len(events), onlyTrace)
+	}
+	if ev := events[0]; ev.Event != "call" || ev.Function != "main.handle" || len(ev.Args) != 1 || ev.Args[0].Value != "7" {
+		t.Errorf("call event = %+v", ev)
+	}
+	if ev := events[1]; ev.Event != "return" || ev.Goroutine != 2 || len(ev.Returns) != 1 {
+		t.Errorf("return event = %+v", ev)
+	}
+
+	state.Threads[2].Breakpoint = &api.Breakpoint{ID: 1}
+	if _, onlyTrace := traceEvents(state, eventTimestamp{}); onlyTrace {
+		t.Error("an ordinary breakpoint should end the trace")
+	}
+}
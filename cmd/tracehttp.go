@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// handlerType is the type of a function usable as an http.HandlerFunc
+const handlerType = "func(net/http.ResponseWriter, *net/http.Request)"
+
+// handlerFuncServeHTTP calls every function used as an http.HandlerFunc,
+// closures included
+const handlerFuncServeHTTP = "net/http.HandlerFunc.ServeHTTP"
+
+// requestType is the type of a handler's request argument
+const requestType = "*net/http.Request"
+
+// httpLoadConfig loads a handler's arguments just deep enough to reach the
+// request's Method and URL.Path
+var httpLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 2,
+	MaxStringLen:       256,
+	MaxArrayValues:     0,
+	MaxStructFields:    -1,
+}
+
+// httpEvent is one NDJSON record emitted by trace-http
+type httpEvent struct {
+	Event string `json:"event"` // request
+	eventTimestamp
+	Method    string   `json:"method,omitempty"`
+	Path      string   `json:"path,omitempty"`
+	Handler   string   `json:"handler"`
+	Goroutine int64    `json:"goroutine"`
+	File      string   `json:"file,omitempty"`
+	Line      int      `json:"line,omitempty"`
+	Stack     []string `json:"stack,omitempty"`
+}
+
+// isServeHTTP reports whether fn is a ServeHTTP method, the other way a type
+// handles requests
+func isServeHTTP(fn string) bool {
+	sym := parseFuncSymbol(fn)
+	return sym.Recv != "" && sym.Name == "ServeHTTP"
+}
+
+// findHTTPHandlers returns the functions among candidates that handle HTTP
+// requests: ServeHTTP methods, and functions whose type is that of an
+// http.HandlerFunc, which eval of the function's name reveals
+func findHTTPHandlers(c *debugger.Client, candidates []string) []string {
+	var handlers []string
+	scope := api.EvalScope{GoroutineID: -1}
+	for _, fn := range candidates {
+		if isServeHTTP(fn) {
+			handlers = append(handlers, fn)
+			continue
+		}
+		v, err := c.EvalInScope(scope, fn, api.LoadConfig{})
+		if err == nil && v.Type == handlerType {
+			handlers = append(handlers, fn)
+		}
+	}
+	return handlers
+}
+
+// requestLine finds the request among a handler's arguments and returns its
+// method and path
+func requestLine(args []api.Variable) (method, path string) {
+	for _, a := range args {
+		if a.Type != requestType || len(a.Children) == 0 {
+			continue
+		}
+		for _, f := range a.Children[0].Children {
+			switch f.Name {
+			case "Method":
+				method = f.Value
+			case "URL":
+				if len(f.Children) > 0 {
+					for _, u := range f.Children[0].Children {
+						if u.Name == "Path" {
+							path = u.Value
+						}
+					}
+				}
+			}
+		}
+		// The zero Method means GET
+		if method == "" {
+			method = "GET"
+		}
+		return method, path
+	}
+	return "", ""
+}
+
+// handlerFuncValue returns the name of the function an
+// http.HandlerFunc.ServeHTTP call runs, from its receiver f
+func handlerFuncValue(args []api.Variable) string {
+	for _, a := range args {
+		if a.Name == "f" {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// httpEvents returns the requests of the threads stopped at handler
+// tracepoints, and whether every stopped thread is at one. traced are the
+// handlers with a tracepoint of their own.
+func httpEvents(state *api.DebuggerState, ts eventTimestamp, traced map[string]bool) ([]httpEvent, bool) {
+	var events []httpEvent
+	onlyTrace := false
+	for _, th := range state.Threads {
+		bp := th.Breakpoint
+		if bp == nil {
+			continue
+		}
+		if !bp.Tracepoint {
+			return events, false
+		}
+		onlyTrace = true
+		ev := httpEvent{Event: "request", eventTimestamp: ts, Goroutine: th.GoroutineID, File: th.File, Line: th.Line}
+		if th.Function != nil {
+			ev.Handler = th.Function.Name()
+		}
+		if info := th.BreakpointInfo; info != nil {
+			if ev.Handler == handlerFuncServeHTTP {
+				// Report the function called through the HandlerFunc, unless
+				// its own tracepoint reports it
+				f := handlerFuncValue(info.Arguments)
+				if traced[f] {
+					continue
+				}
+				if f != "" {
+					ev.Handler = f
+				}
+			}
+			ev.Method, ev.Path = requestLine(info.Arguments)
+			for _, f := range info.Stacktrace {
+				if f.Function != nil {
+					ev.Stack = append(ev.Stack, fmt.Sprintf("%s %s:%d", f.Function.Name(), f.File, f.Line))
+				}
+			}
+		}
+		events = append(events, ev)
+	}
+	return events, onlyTrace
+}
+
+// addTraceHTTPCommand adds the trace-http command
+func addTraceHTTPCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var traceDuration time.Duration
+	var traceCount, traceMaxFuncs, traceStack int
+	var traceAll, traceKeep bool
+
+	traceHTTPCmd := &cobra.Command{
+		Use:   "trace-http [regexp]",
+		Short: "Trace requests through the program's HTTP handlers",
+		Long: `Find the program's net/http handlers and trace every request they serve,
+emitting one NDJSON line per handler call with the request's method and path.
+The final line is the usual response with the handlers, the requests per
+route and why the trace ended.
+
+Handlers are ServeHTTP methods and named functions with the signature of an
+http.HandlerFunc in the main module, or everywhere with --all; regexp narrows
+the functions considered. Closures registered with http.HandleFunc (and
+middleware built on HandlerFunc) are traced through
+net/http.HandlerFunc.ServeHTTP and reported under their closure name.
+Frameworks with their own handler signatures are not detected; use trace on
+their handler functions.
+
+Tracing ends after --duration, after --count requests, on SIGINT, when the
+program stops on an ordinary breakpoint or when it exits. The tracepoints
+are cleared afterwards unless --keep, and the target is left stopped.
+
+Example:
+  godebug --addr $ADDR trace-http --duration 1m
+  godebug --addr $ADDR trace-http '^main\.handle' --count 20 --stack 5`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			filter := ""
+			if len(args) > 0 {
+				filter = args[0]
+			}
+			if _, err := regexp.Compile(filter); err != nil {
+				output.ErrorWithInfo("trace-http", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid regexp: %v", err),
+					map[string]any{"regexp": filter},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if traceDuration <= 0 && traceCount <= 0 {
+				output.ErrorWithInfo("trace-http", output.InvalidArgument("--count or --duration must be > 0")).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("trace-http")
+			defer func() { _ = c.Close() }()
+
+			functions, err := c.ListFunctions(filter)
+			if err != nil {
+				output.Error("trace-http", err).PrintAndExit(getOutputFormat())
+			}
+			// Candidates are ServeHTTP methods and plain functions; generic
+			// functions cannot be handlers
+			inMainModule := mainModuleFilter(mainModulePath(c))
+			var candidates []string
+			for _, fn := range functions {
+				sym := parseFuncSymbol(fn)
+				if stripTypeParams(fn) != fn || strings.HasPrefix(fn, "net/http.") || !(traceAll || inMainModule(sym.Pkg)) {
+					continue
+				}
+				if isServeHTTP(fn) || (sym.Recv == "" && !strings.Contains(sym.Name, ".")) {
+					candidates = append(candidates, fn)
+				}
+			}
+			handlers := findHTTPHandlers(c, candidates)
+			// Closures are not found by name; they are reported by the
+			// HandlerFunc they are called through
+			if hf, err := c.ListFunctions("^" + regexp.QuoteMeta(handlerFuncServeHTTP) + "$"); err == nil && len(hf) > 0 {
+				handlers = append(handlers, handlerFuncServeHTTP)
+			}
+			if len(handlers) == 0 {
+				output.ErrorWithInfo("trace-http", output.NotFound("HTTP handler", "the binary does not use net/http handlers").WithDetails(map[string]any{
+					"regexp":     filter,
+					"candidates": len(candidates),
+					"hint":       "trace the handler functions of your framework instead",
+				})).PrintAndExit(getOutputFormat())
+			}
+			if traceMaxFuncs > 0 && len(handlers) > traceMaxFuncs {
+				output.ErrorWithInfo("trace-http", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("%d handlers found (more than --max-funcs %d); narrow them with a regexp", len(handlers), traceMaxFuncs),
+					map[string]any{"handlers": handlers[:min(len(handlers), 20)], "count": len(handlers)},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			var created []int
+			var traced []string
+			var skipped []map[string]any
+			for _, fn := range handlers {
+				bp, err := c.CreateBreakpoint(&api.Breakpoint{
+					FunctionName: fn,
+					Tracepoint:   true,
+					Line:         -1,
+					Stacktrace:   traceStack,
+					LoadArgs:     &httpLoadConfig,
+				})
+				if err != nil {
+					skipped = append(skipped, map[string]any{"function": fn, "error": err.Error()})
+					continue
+				}
+				created = append(created, bp.ID)
+				traced = append(traced, fn)
+			}
+			// PrintAndExit skips deferred calls, so clearing is explicit
+			clearTracepoints := func() {
+				if traceKeep {
+					return
+				}
+				for _, id := range created {
+					_, _ = c.ClearBreakpoint(id)
+				}
+			}
+			if len(traced) == 0 {
+				output.ErrorWithInfo("trace-http", output.NewErrorInfo(output.ErrCodeInternalError,
+					"no tracepoint could be set on the handlers").WithDetails(map[string]any{"skipped": skipped})).PrintAndExit(getOutputFormat())
+			}
+
+			tracedSet := make(map[string]bool, len(traced))
+			for _, fn := range traced {
+				tracedSet[fn] = true
+			}
+			halt, finished := traceHalts(traceDuration)
+			start := time.Now()
+			routes := map[string]int{}
+			requests := 0
+			var state *api.DebuggerState
+			stoppedBy := ""
+			for stoppedBy == "" {
+				var reason string
+				state, reason, err = traceContinue(c, halt)
+				if err != nil {
+					clearTracepoints()
+					output.Error("trace-http", err).PrintAndExit(getOutputFormat())
+				}
+				if state.Exited {
+					stoppedBy = traceStopExited
+					break
+				}
+				stepEvents, onlyTrace := httpEvents(state, newEventTimestamp(c.Addr(), time.Now()), tracedSet)
+				for _, ev := range stepEvents {
+					if traceCount > 0 && requests >= traceCount {
+						break
+					}
+					output.Emit(ev)
+					requests++
+					routes[strings.TrimSpace(ev.Method+" "+ev.Path)]++
+				}
+				switch {
+				case reason != "":
+					stoppedBy = reason
+				case !onlyTrace:
+					stoppedBy = traceStopBreakpoint
+				case traceCount > 0 && requests >= traceCount:
+					stoppedBy = traceStopCount
+				}
+			}
+			finished()
+
+			clearTracepoints()
+			recordStop(c, "trace-http", state)
+
+			sort.Strings(traced)
+			data := map[string]any{
+				"handlers":  traced,
+				"requests":  requests,
+				"routes":    routes,
+				"stoppedBy": stoppedBy,
+				"elapsedMs": time.Since(start).Milliseconds(),
+				"state":     stateToData(state),
+			}
+			if len(skipped) > 0 {
+				data["skipped"] = skipped
+			}
+			if traceKeep {
+				data["tracepoints"] = created
+			}
+			msg := fmt.Sprintf("%d requests through %d handlers; stopped by %s", requests, len(traced), stoppedBy)
+			output.Success("trace-http", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	traceHTTPCmd.Flags().DurationVar(&traceDuration, "duration", 30*time.Second, "Stop tracing after this long (0 = no limit)")
+	traceHTTPCmd.Flags().IntVar(&traceCount, "count", 0, "Stop after this many requests (0 = no limit)")
+	traceHTTPCmd.Flags().IntVar(&traceMaxFuncs, "max-funcs", 50, "Refuse to trace more handlers (0 = no limit)")
+	traceHTTPCmd.Flags().IntVar(&traceStack, "stack", 0, "Frames of stack recorded with each request")
+	traceHTTPCmd.Flags().BoolVar(&traceAll, "all", false, "Look for handlers outside the main module too")
+	traceHTTPCmd.Flags().BoolVar(&traceKeep, "keep", false, "Leave the tracepoints set afterwards")
+	root.AddCommand(traceHTTPCmd)
+}
+
+func init() {
+	addTraceHTTPCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
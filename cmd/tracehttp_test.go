@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// request builds a handler's *http.Request argument as Delve loads it
+func request(method, path string) api.Variable {
+	url := api.Variable{Name: "URL", Type: "*net/url.URL", Children: []api.Variable{
+		{Children: []api.Variable{{Name: "Scheme"}, {Name: "Path", Value: path}}},
+	}}
+	return api.Variable{Name: "r", Type: requestType, Children: []api.Variable{
+		{Children: []api.Variable{{Name: "Method", Value: method}, url}},
+	}}
+}
+
+// TestHTTPEvents checks the request line and the reporting of functions
+// called through http.HandlerFunc.
+func TestHTTPEvents(t *testing.T) {
+	named := &api.Function{Name_: "main.listUsers"}
+	handlerFunc := &api.Function{Name_: handlerFuncServeHTTP}
+	tp := &api.Breakpoint{Tracepoint: true}
+	state := &api.DebuggerState{Threads: []*api.Thread{
+		{GoroutineID: 1, Function: named, Breakpoint: tp,
+			BreakpointInfo: &api.BreakpointInfo{Arguments: []api.Variable{{Name: "w"}, request("POST", "/users")}}},
+		// Through HandlerFunc to a traced handler: reported by its own tracepoint
+		{GoroutineID: 1, Function: handlerFunc, Breakpoint: tp,
+			BreakpointInfo: &api.BreakpointInfo{Arguments: []api.Variable{{Name: "f", Value: "main.listUsers"}, {Name: "w"}, request("POST", "/users")}}},
+		// Through HandlerFunc to a closure
+		{GoroutineID: 2, Function: handlerFunc, Breakpoint: tp,
+			BreakpointInfo: &api.BreakpointInfo{Arguments: []api.Variable{{Name: "f", Value: "main.main.func1"}, {Name: "w"}, request("", "/health")}}},
+	}}
+	events, onlyTrace := httpEvents(state, eventTimestamp{}, map[string]bool{"main.listUsers": true})
+	if !onlyTrace || len(events) != 2 {
+		t.Fatalf("httpEvents = %+v, onlyTrace %v; want 2 events", events, onlyTrace)
+	}
+	if ev := events[0]; ev.Handler != "main.listUsers" || ev.Method != "POST" || ev.Path != "/users" {
+		t.Errorf("named handler event = %+v", ev)
+	}
+	if ev := events[1]; ev.Handler != "main.main.func1" || ev.Method != "GET" || ev.Path != "/health" {
+		t.Errorf("closure event = %+v", ev)
+	}
+}
+
+// TestIsServeHTTP checks that only ServeHTTP methods count.
+func TestIsServeHTTP(t *testing.T) {
+	for fn, want := range map[string]bool{
+		"main.(*api).ServeHTTP":                true,
+		"example.com/app/web.Router.ServeHTTP": true,
+		"main.ServeHTTP":                       false,
+		"main.(*api).ServeHTTPWithTimeout":     false,
+	} {
+		if got := isServeHTTP(fn); got != want {
+			t.Errorf("isServeHTTP(%s) = %v, want %v", fn, got, want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// Outcomes of one triage run
+const (
+	triageClean   = "clean"   // exited with status 0
+	triageCrash   = "crash"   // panicked, hit a fatal error or exited non-zero
+	triageTimeout = "timeout" // still running when --timeout expired
+	triageError   = "error"   // could not be launched or debugged
+)
+
+// triageExitWait bounds letting a panicked program print its message and exit
+const triageExitWait = 5 * time.Second
+
+// triageRun is the outcome of running the target once
+type triageRun struct {
+	Run        int      `json:"run"`
+	Outcome    string   `json:"outcome"`
+	Reason     string   `json:"reason,omitempty"`
+	ExitStatus *int     `json:"exitStatus,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Signature  string   `json:"signature,omitempty"`
+	Stack      []string `json:"stack,omitempty"`
+	Stderr     string   `json:"stderr,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	DurationMs int64    `json:"durationMs"`
+}
+
+// triageCluster groups the crashes sharing a signature
+type triageCluster struct {
+	Signature string   `json:"signature"`
+	Reason    string   `json:"reason"`
+	Count     int      `json:"count"`
+	Runs      []int    `json:"runs"`
+	Message   string   `json:"message,omitempty"`
+	Stack     []string `json:"stack,omitempty"`
+}
+
+// crashSignature names a crash by the top user frame of the crashing
+// goroutine, or its top frame if none is user code
+func crashSignature(frames []api.Stackframe) string {
+	for _, f := range frames {
+		if f.Function != nil && f.File != "" && isUserSource(f.File) {
+			return fmt.Sprintf("%s (%s:%d)", f.Function.Name(), f.File, f.Line)
+		}
+	}
+	if len(frames) > 0 && frames[0].Function != nil {
+		return frames[0].Function.Name()
+	}
+	return ""
+}
+
+// panicMessage finds the runtime's panic or fatal error line in the output
+func panicMessage(lines []string) string {
+	for _, line := range lines {
+		if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+			return line
+		}
+	}
+	return ""
+}
+
+// clusterCrashes groups the crashed runs by signature, most frequent first
+func clusterCrashes(runs []triageRun) []triageCluster {
+	var clusters []triageCluster
+	index := map[string]int{}
+	for _, r := range runs {
+		if r.Outcome != triageCrash {
+			continue
+		}
+		i, ok := index[r.Signature]
+		if !ok {
+			i = len(clusters)
+			index[r.Signature] = i
+			clusters = append(clusters, triageCluster{Signature: r.Signature, Reason: r.Reason, Message: r.Message, Stack: r.Stack})
+		}
+		clusters[i].Count++
+		clusters[i].Runs = append(clusters[i].Runs, r.Run)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Count > clusters[j].Count })
+	return clusters
+}
+
+// triageOnce launches the target under Delve, runs it until it stops and
+// records how it ended. It returns an error only if the target could not be
+// launched.
+func triageOnce(config debugger.LaunchConfig, run, depth int, timeout time.Duration) (r triageRun, err error) {
+	start := time.Now()
+	r = triageRun{Run: run}
+	defer func() { r.DurationMs = time.Since(start).Milliseconds() }()
+
+	redirects, _, stderr, err := crashRedirects()
+	if err != nil {
+		return r, err
+	}
+	config.Redirects = redirects
+	r.Stderr = stderr
+
+	result, err := debugger.Launch(config)
+	if err != nil {
+		return r, err
+	}
+	defer func() {
+		_ = result.Kill()
+		_ = session.Remove(result.Addr)
+	}()
+	c, err := debugger.Connect(result.Addr)
+	if err != nil {
+		r.Outcome, r.Error = triageError, err.Error()
+		return r, nil
+	}
+	defer func() { _ = c.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	c.SetTimeout(timeout)
+	state, err := c.ContinueWithContext(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			r.Outcome = triageTimeout
+		} else {
+			r.Outcome, r.Error = triageError, err.Error()
+		}
+		return r, nil
+	}
+
+	r.Reason = crashReason(state)
+	if !state.Exited && r.Reason != "" && state.SelectedGoroutine != nil {
+		cfg := debugger.DefaultLoadConfig()
+		if frames, err := c.Stacktrace(state.SelectedGoroutine.ID, depth, &cfg); err == nil {
+			r.Signature = crashSignature(frames)
+			for _, f := range frames {
+				if f.Function != nil {
+					r.Stack = append(r.Stack, fmt.Sprintf("%s %s:%d", f.Function.Name(), f.File, f.Line))
+				}
+			}
+		}
+		// Let the runtime print the panic and exit
+		exitCtx, exitCancel := context.WithTimeout(context.Background(), triageExitWait)
+		if exited, err := c.ContinueWithContext(exitCtx); err == nil {
+			state = exited
+		}
+		exitCancel()
+	}
+	if state.Exited {
+		status := state.ExitStatus
+		r.ExitStatus = &status
+		if r.Reason == "" {
+			r.Reason = crashReason(state)
+		}
+	}
+	r.Message = panicMessage(tailLines(stderr, crashOutputLines))
+
+	switch {
+	case r.Reason == "" && state.Exited:
+		r.Outcome = triageClean
+	case r.Reason == "":
+		// Stopped on something other than a crash, e.g. a breakpoint in the code
+		r.Outcome, r.Error = triageError, "stopped without crashing"
+	default:
+		r.Outcome = triageCrash
+		if r.Signature == "" {
+			r.Signature = r.Reason
+		}
+	}
+	return r, nil
+}
+
+// addTriageCommand adds the triage command
+func addTriageCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var triageMode string
+	var triageRestarts, triageDepth int
+
+	triageCmd := &cobra.Command{
+		Use:   "triage <target>",
+		Short: "Run a crashing program repeatedly and cluster its crashes",
+		Long: `Run the target under Delve --restarts times, each run until it exits,
+crashes or --timeout expires. For every crash the stack of the crashing
+goroutine, the runtime's panic or fatal error message and the exit status are
+captured. Crashes are clustered by signature, the top user frame of the
+crashing goroutine (or the exit status when the program exited non-zero
+without panicking), and the most frequent cluster is reported as dominant.
+
+Each run's stderr is kept in a file listed with the run. Runs are
+independent: no session is left behind.
+
+Example:
+  godebug triage --mode exec ./service --restarts 5
+  godebug triage ./cmd/worker --restarts 10 --timeout 1m -- -queue jobs`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if cmd.ArgsLenAtDash() > 1 || (cmd.ArgsLenAtDash() < 0 && len(args) > 1) {
+				output.ErrorWithInfo("triage", output.InvalidArgumentWithDetails(
+					"program arguments go after --",
+					map[string]any{"args": args},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if triageRestarts < 1 {
+				output.ErrorWithInfo("triage", output.InvalidArgument("--restarts must be at least 1")).PrintAndExit(getOutputFormat())
+			}
+			mode := debugger.ModeDebug
+			switch triageMode {
+			case "debug":
+			case "test":
+				mode = debugger.ModeTest
+			case "exec":
+				mode = debugger.ModeExec
+			default:
+				output.ErrorWithInfo("triage", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid mode: %s (expected debug, test or exec)", triageMode),
+					map[string]any{"mode": triageMode},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			config := debugger.LaunchConfig{Mode: mode, Target: args[0], Args: args[1:], Timeout: getTimeout()}
+			start := time.Now()
+			runs := make([]triageRun, 0, triageRestarts)
+			outcomes := map[string]int{}
+			for i := 1; i <= triageRestarts; i++ {
+				r, err := triageOnce(config, i, triageDepth, getTimeout())
+				if err != nil {
+					// A target that cannot be launched will not launch on the next try
+					if i == 1 {
+						output.Error("triage", err).PrintAndExit(getOutputFormat())
+					}
+					r.Outcome, r.Error = triageError, err.Error()
+				}
+				runs = append(runs, r)
+				outcomes[r.Outcome]++
+			}
+
+			clusters := clusterCrashes(runs)
+			data := map[string]any{
+				"target":     args[0],
+				"runs":       runs,
+				"outcomes":   outcomes,
+				"clusters":   clusters,
+				"durationMs": time.Since(start).Milliseconds(),
+			}
+			msg := fmt.Sprintf("No crashes in %d runs", len(runs))
+			if len(clusters) > 0 {
+				data["dominant"] = clusters[0]
+				msg = fmt.Sprintf("%d of %d runs crashed; dominant: %s (%d)", outcomes[triageCrash], len(runs), clusters[0].Signature, clusters[0].Count)
+			}
+			output.Success("triage", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	triageCmd.Flags().StringVar(&triageMode, "mode", "debug", "Debug mode: debug, test, or exec")
+	triageCmd.Flags().IntVar(&triageRestarts, "restarts", 5, "Number of times to run the target")
+	triageCmd.Flags().IntVar(&triageDepth, "depth", 20, "Frames captured of each crashing goroutine")
+	root.AddCommand(triageCmd)
+}
+
+func init() {
+	addTriageCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
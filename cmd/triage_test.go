@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestCrashSignature checks that runtime frames are skipped for the top user frame.
+func TestCrashSignature(t *testing.T) {
+	frames := []api.Stackframe{
+		{Location: api.Location{File: "/usr/local/go/src/runtime/panic.go", Line: 770, Function: &api.Function{Name_: "runtime.gopanic"}}},
+		{Location: api.Location{File: "/src/app/store.go", Line: 42, Function: &api.Function{Name_: "app.(*Store).Get"}}},
+		{Location: api.Location{File: "/src/app/main.go", Line: 9, Function: &api.Function{Name_: "main.main"}}},
+	}
+	if got, want := crashSignature(frames), "app.(*Store).Get (/src/app/store.go:42)"; got != want {
+		t.Errorf("crashSignature = %q, want %q", got, want)
+	}
+	if got := crashSignature(frames[:1]); got != "runtime.gopanic" {
+		t.Errorf("crashSignature without user frames = %q", got)
+	}
+}
+
+// TestClusterCrashes checks grouping by signature, most frequent first.
+func TestClusterCrashes(t *testing.T) {
+	runs := []triageRun{
+		{Run: 1, Outcome: triageCrash, Signature: "a"},
+		{Run: 2, Outcome: triageCrash, Signature: "b"},
+		{Run: 3, Outcome: triageClean},
+		{Run: 4, Outcome: triageCrash, Signature: "b"},
+		{Run: 5, Outcome: triageTimeout},
+	}
+	clusters := clusterCrashes(runs)
+	if len(clusters) != 2 || clusters[0].Signature != "b" || clusters[0].Count != 2 || clusters[0].Runs[1] != 4 {
+		t.Errorf("clusterCrashes = %+v", clusters)
+	}
+	if msg := panicMessage([]string{"starting", "panic: boom", "", "goroutine 1 [running]:"}); msg != "panic: boom" {
+		t.Errorf("panicMessage = %q", msg)
+	}
+}
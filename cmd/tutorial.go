@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// tutorialPrograms holds testdata/concurrency_bugs, embedded by package main
+var tutorialPrograms fs.FS
+
+// SetTutorialPrograms provides the scenario programs of the tutorial
+func SetTutorialPrograms(fsys fs.FS) {
+	tutorialPrograms = fsys
+}
+
+// tutorialStep is one hint of a scenario. {addr} and {dir} in Command are
+// replaced with the session address and the scenario directory.
+type tutorialStep struct {
+	Goal    string `json:"goal"`
+	Command string `json:"command,omitempty"`
+}
+
+// tutorialScenario describes a bug program and how to investigate it
+type tutorialScenario struct {
+	Title string
+	// Symptom is what the program reports, often with a misleading explanation
+	Symptom string
+	Steps   []tutorialStep
+	Cause   string
+	Fix     string
+}
+
+// tutorialScenarios are keyed by their directory in testdata/concurrency_bugs
+var tutorialScenarios = map[string]tutorialScenario{
+	"race_counter": {
+		Title:   "Lost increments",
+		Symptom: "The counter ends below 1000 and the program blames GOMAXPROCS.",
+		Steps: []tutorialStep{
+			{"Stop inside one of the goroutines", "godebug --addr {addr} break {dir}/main.go:16"},
+			{"Run to it", "godebug --addr {addr} continue"},
+			{"See how many goroutines are at the same line at once", "godebug --addr {addr} goroutines"},
+			{"Read the shared counter between hits", "godebug --addr {addr} eval counter"},
+		},
+		Cause: "counter++ is an unsynchronized read-modify-write run by 1000 goroutines at once (a data race), and main waits with a sleep instead of for the goroutines.",
+		Fix:   "Use atomic.AddInt64 or a mutex for the counter and a sync.WaitGroup instead of time.Sleep.",
+	},
+	"closure_loop": {
+		Title:   "Repeated loop values",
+		Symptom: "Goroutines print the same number several times; the program blames scheduling.",
+		Steps: []tutorialStep{
+			{"Stop in the first goroutine closure", "godebug --addr {addr} break {dir}/main.go:19"},
+			{"Run to it", "godebug --addr {addr} continue"},
+			{"Read the captured loop variable", "godebug --addr {addr} eval i"},
+			{"Compare with the closure that takes idx as a parameter", "godebug --addr {addr} break {dir}/main.go:50"},
+		},
+		Cause: "Before Go 1.22 a for loop has one variable i for all iterations; the closures capture that variable, not its value, and read it after the loop moved on. Test 3 passes idx but still reads i.",
+		Fix:   "Pass the value as a parameter and use it (results[idx] = idx), or build with go 1.22+ in go.mod for per-iteration loop variables.",
+	},
+	"deadlock_circular": {
+		Title:   "Transfers that never finish",
+		Symptom: "Neither transfer completes, yet main reports success after its sleep.",
+		Steps: []tutorialStep{
+			{"Stop main while the transfers run", "godebug --addr {addr} break {dir}/main.go:56"},
+			{"Run to it", "godebug --addr {addr} continue"},
+			{"Find the goroutines stuck on each mutex", "godebug --addr {addr} goroutines --blocked-on resourceA"},
+			{"And on the other mutex", "godebug --addr {addr} goroutines --blocked-on resourceB"},
+		},
+		Cause: "transferAtoB locks A then B, transferBtoA locks B then A. Each holds the lock the other waits for: a lock order inversion.",
+		Fix:   "Acquire the mutexes in the same global order in every code path.",
+	},
+	"leak_forgotten_sender": {
+		Title:   "Goroutines that pile up after timeouts",
+		Symptom: "The goroutine count grows each iteration; the program blames the GC.",
+		Steps: []tutorialStep{
+			{"Stop after the loop", "godebug --addr {addr} break {dir}/main.go:46"},
+			{"Run to it", "godebug --addr {addr} continue"},
+			{"List goroutines and find the ones parked on a channel send", "godebug --addr {addr} goroutines"},
+			{"Look at where they are blocked", "godebug --addr {addr} summarize"},
+		},
+		Cause: "After the timeout nobody receives from the unbuffered results channel, so every worker blocks forever on results <- result.",
+		Fix:   "Make the channel buffered with capacity 1 (make(chan int, 1)) so the send never blocks, or cancel the worker with a context.",
+	},
+	"mutex_copy": {
+		Title:   "A counter that stays at 0",
+		Symptom: "The mutex-protected counter reads 0 after 1000 increments; the program blames false sharing.",
+		Steps: []tutorialStep{
+			{"Stop inside Counter.Inc", "godebug --addr {addr} break {dir}/main.go:15"},
+			{"Run to it", "godebug --addr {addr} continue"},
+			{"Look at the receiver", "godebug --addr {addr} args"},
+			{"Compare its address with the caller's counter", "godebug --addr {addr} eval &c"},
+			{"Move to the caller and read the original", "godebug --addr {addr} up"},
+		},
+		Cause: "Inc and Get have value receivers: every call works on a copy of Counter, including a copy of its mutex, so the increment is lost.",
+		Fix:   "Use pointer receivers (func (c *Counter) Inc()); go vet's copylocks check reports this.",
+	},
+	"select_timeout_leak": {
+		Title:   "Heap objects growing under load",
+		Symptom: "Heap objects grow while messages are processed; the program suggests sync.Pool.",
+		Steps: []tutorialStep{
+			{"Stop at the select's timeout case", "godebug --addr {addr} break {dir}/main.go:14"},
+			{"Run to it", "godebug --addr {addr} continue"},
+			{"See that time.After is evaluated on every iteration", "godebug --addr {addr} stack"},
+			{"Compare with the reused timer in optimizedProcessor", "godebug --addr {addr} list {dir}/main.go:23"},
+		},
+		Cause: "time.After in a loop creates a new timer for every select; before Go 1.23 none is collected until it fires a second later, so 10000 messages leave 10000 live timers.",
+		Fix:   "Create one time.Timer outside the loop and Reset it, as optimizedProcessor does.",
+	},
+	"waitgroup_race": {
+		Title:   "Wait returns before the workers finish",
+		Symptom: "Wait() returns with fewer than 10 completed; the program blames atomic memory ordering.",
+		Steps: []tutorialStep{
+			{"Stop right after wg.Wait returns", "godebug --addr {addr} break {dir}/main.go:29"},
+			{"Run to it", "godebug --addr {addr} continue"},
+			{"Read how many workers completed", "godebug --addr {addr} eval completed"},
+			{"See where the workers are", "godebug --addr {addr} goroutines"},
+		},
+		Cause: "wg.Add(1) runs inside the goroutine, so Wait can run before any Add and return at once. processWorkersWithQuota adds 5 but starts 3 workers, so its Wait never returns.",
+		Fix:   "Call wg.Add before the go statement, and Add exactly as many as there are Done calls.",
+	},
+	"channel_nil": {
+		Title:   "Channels that block forever",
+		Symptom: "Sends and receives never complete and close panics; the program suggests bigger buffers.",
+		Steps: []tutorialStep{
+			{"Stop at the first send", "godebug --addr {addr} break {dir}/main.go:16"},
+			{"Run to it", "godebug --addr {addr} continue"},
+			{"Read the channel", "godebug --addr {addr} eval ch"},
+			{"Stop in TaskQueue.Submit and read its channel", "godebug --addr {addr} break {dir}/main.go:65"},
+		},
+		Cause: "var ch chan int and TaskQueue{} leave the channels nil. Send and receive on a nil channel block forever and close panics; capacity has nothing to do with it.",
+		Fix:   "Initialize channels with make(chan T) (or make(chan T, n)) before use, e.g. in a TaskQueue constructor.",
+	},
+}
+
+// tutorialNames returns the scenario names in order
+func tutorialNames() []string {
+	names := make([]string, 0, len(tutorialScenarios))
+	for name := range tutorialScenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupScenario returns the named scenario or exits with NOT_FOUND
+func lookupScenario(cmdName, name string, getOutputFormat func() output.OutputFormat) tutorialScenario {
+	sc, ok := tutorialScenarios[name]
+	if !ok {
+		output.ErrorWithInfo(cmdName, output.NotFound("tutorial scenario", name).WithDetails(map[string]any{
+			"resource_type": "tutorial scenario",
+			"identifier":    name,
+			"available":     tutorialNames(),
+		})).PrintAndExit(getOutputFormat())
+	}
+	return sc
+}
+
+// addTutorialCommand adds the tutorial command and its subcommands
+func addTutorialCommand(root *cobra.Command, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	tutorialCmd := &cobra.Command{
+		Use:   "tutorial",
+		Short: "Practice on bundled concurrency bug scenarios",
+		Long: `Interactive training on small programs with real concurrency bugs. Each
+program prints a misleading explanation of its own symptom; the tutorial
+gives step-by-step commands to find the actual cause.
+
+Subcommands:
+  list             List the scenarios
+  start <name>     Build and launch a scenario under the debugger
+  solution <name>  Reveal the root cause and the fix
+
+Example:
+  godebug tutorial list
+  godebug tutorial start deadlock_circular`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the tutorial scenarios",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			scenarios := []map[string]any{}
+			for _, name := range tutorialNames() {
+				sc := tutorialScenarios[name]
+				scenarios = append(scenarios, map[string]any{
+					"name":    name,
+					"title":   sc.Title,
+					"symptom": sc.Symptom,
+					"steps":   len(sc.Steps),
+				})
+			}
+			data := map[string]any{"scenarios": scenarios, "count": len(scenarios)}
+			output.Success("tutorial list", data, fmt.Sprintf("%d scenarios", len(scenarios))).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	startCmd := &cobra.Command{
+		Use:   "start <name>",
+		Short: "Build and launch a tutorial scenario",
+		Long: `Copy the scenario program into a temporary directory, build it for
+debugging and start a debug session stopped at entry. The response holds the
+session address, the program directory and the steps to follow; quit the
+session when done.
+
+Example:
+  godebug tutorial start race_counter`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+			sc := lookupScenario("tutorial start", name, getOutputFormat)
+
+			if tutorialPrograms == nil {
+				output.ErrorWithInfo("tutorial start", output.InternalError("tutorial programs not embedded in this build")).PrintAndExit(getOutputFormat())
+			}
+			program, err := fs.Sub(tutorialPrograms, name)
+			if err != nil {
+				output.Error("tutorial start", err).PrintAndExit(getOutputFormat())
+			}
+
+			dir, err := os.MkdirTemp("", "godebug-tutorial-"+name+"-")
+			if err != nil {
+				output.Error("tutorial start", err).PrintAndExit(getOutputFormat())
+			}
+			binary, err := buildEmbeddedProgram(program, dir)
+			if err != nil {
+				output.ErrorWithInfo("tutorial start", output.InternalError(
+					fmt.Sprintf("cannot build scenario %s: %v", name, err),
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			result, err := debugger.Launch(debugger.LaunchConfig{
+				Mode:    debugger.ModeExec,
+				Target:  binary,
+				Timeout: getTimeout(),
+			})
+			if err != nil {
+				output.Error("tutorial start", err).PrintAndExit(getOutputFormat())
+			}
+			recordLaunchSession(result)
+
+			replacer := strings.NewReplacer("{addr}", result.Addr, "{dir}", dir)
+			steps := make([]map[string]any, len(sc.Steps))
+			for i, step := range sc.Steps {
+				steps[i] = map[string]any{
+					"step": i + 1,
+					"goal": step.Goal,
+				}
+				if step.Command != "" {
+					steps[i]["command"] = replacer.Replace(step.Command)
+				}
+			}
+
+			data := map[string]any{
+				"scenario": name,
+				"title":    sc.Title,
+				"symptom":  sc.Symptom,
+				"addr":     result.Addr,
+				"pid":      result.PID,
+				"dir":      dir,
+				"source":   dir + "/main.go",
+				"steps":    steps,
+				"solution": "godebug tutorial solution " + name,
+			}
+
+			output.Success("tutorial start", data, fmt.Sprintf("Scenario %s started at %s", name, result.Addr)).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	solutionCmd := &cobra.Command{
+		Use:   "solution <name>",
+		Short: "Reveal the root cause and fix of a scenario",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			sc := lookupScenario("tutorial solution", args[0], getOutputFormat)
+			data := map[string]any{
+				"scenario": args[0],
+				"title":    sc.Title,
+				"cause":    sc.Cause,
+				"fix":      sc.Fix,
+			}
+			output.Success("tutorial solution", data, sc.Cause).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	tutorialCmd.AddCommand(listCmd, startCmd, solutionCmd)
+	root.AddCommand(tutorialCmd)
+}
+
+func init() {
+	addTutorialCommand(rootCmd, GetOutputFormat, GetTimeout)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestTutorialScenarios checks that every scenario has a program and every
+// main.go:N in its steps points into that program.
+func TestTutorialScenarios(t *testing.T) {
+	dirs, err := os.ReadDir(filepath.Join("..", "testdata", "concurrency_bugs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != len(tutorialScenarios) {
+		t.Errorf("%d scenario programs, %d scenarios", len(dirs), len(tutorialScenarios))
+	}
+
+	lineRef := regexp.MustCompile(`main\.go:(\d+)`)
+	for name, sc := range tutorialScenarios {
+		src, err := os.ReadFile(filepath.Join("..", "testdata", "concurrency_bugs", name, "main.go"))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		lines := strings.Count(string(src), "\n")
+		for _, step := range sc.Steps {
+			for _, m := range lineRef.FindAllStringSubmatch(step.Command, -1) {
+				if n, _ := strconv.Atoi(m[1]); n < 1 || n > lines {
+					t.Errorf("%s: %q refers to line %d of %d", name, step.Command, n, lines)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// defaultTypesLimit bounds the types types lists unless --limit is given
+const defaultTypesLimit = 200
+
+// namedTypePackage returns the package of a named type such as
+// example.com/app/db.Conn or main.List[int], or false for a type literal
+// (pointers, slices, maps, funcs, anonymous structs)
+func namedTypePackage(name string) (string, bool) {
+	if name == "" || strings.ContainsAny(name[:1], "*[(") {
+		return "", false
+	}
+	for _, prefix := range []string{"map[", "func(", "chan ", "<-chan", "struct {", "interface {"} {
+		if strings.HasPrefix(name, prefix) {
+			return "", false
+		}
+	}
+	base := name
+	if i := strings.Index(base, "["); i >= 0 {
+		base = base[:i]
+	}
+	slash := strings.LastIndex(base, "/")
+	dot := strings.LastIndex(base, ".")
+	if dot < 0 || dot < slash {
+		return "", false
+	}
+	return base[:dot], true
+}
+
+// listedTypes reduces the binary's types to the named types types reports,
+// only those of the main module unless all is set
+func listedTypes(types []string, inMainModule func(string) bool, all bool) []string {
+	var listed []string
+	for _, t := range types {
+		pkg, named := namedTypePackage(t)
+		if !named || (!all && !inMainModule(pkg)) {
+			continue
+		}
+		listed = append(listed, t)
+	}
+	sort.Strings(listed)
+	return listed
+}
+
+// typeMethods groups the methods among functions by their receiver type,
+// pointer receivers included, as names break takes
+func typeMethods(functions []string) map[string][]string {
+	methods := map[string][]string{}
+	seen := map[string]bool{}
+	for _, fn := range functions {
+		name := stripTypeParams(fn)
+		sym := parseFuncSymbol(name)
+		if sym.Recv == "" || seen[name] || closureName.MatchString(sym.Name[strings.LastIndex(sym.Name, ".")+1:]) {
+			continue
+		}
+		seen[name] = true
+		recv := sym.Pkg + "." + sym.Recv
+		methods[recv] = append(methods[recv], name)
+	}
+	for _, m := range methods {
+		sort.Strings(m)
+	}
+	return methods
+}
+
+// addTypesCommand adds the types command
+func addTypesCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var typesAll, typesMethods bool
+	var typesLimit int
+
+	typesCmd := &cobra.Command{
+		Use:   "types [regexp]",
+		Short: "List the types in the binary",
+		Long: `List the named types compiled into the debugged binary whose name matches
+regexp, to find the exact type name for an eval conversion such as
+*(*db.Conn)(0xc000123000) or the receiver of a method breakpoint.
+
+By default only types of the main module are listed; --all includes the
+standard library, dependencies and the runtime. Type literals (pointers,
+slices, maps, anonymous structs) are left out. --methods adds each type's
+methods, as break takes them. At most --limit types are returned (0 = no
+limit); data.total counts all matches.
+
+Example:
+  godebug --addr $ADDR types
+  godebug --addr $ADDR types 'Config$'
+  godebug --addr $ADDR types --methods '^main\.Server$'
+  godebug --addr $ADDR types --all '^net/http\.'`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			filter := ""
+			if len(args) > 0 {
+				filter = args[0]
+			}
+			if _, err := regexp.Compile(filter); err != nil {
+				output.ErrorWithInfo("types", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid regexp: %v", err),
+					map[string]any{"filter": filter},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if typesLimit < 0 {
+				output.ErrorWithInfo("types", output.InvalidArgument("--limit must not be negative")).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("types")
+			defer func() { _ = c.Close() }()
+
+			types, err := c.ListTypes(filter)
+			if err != nil {
+				output.Error("types", err).PrintAndExit(getOutputFormat())
+			}
+			module := mainModulePath(c)
+			listed := listedTypes(types, mainModuleFilter(module), typesAll)
+
+			data := map[string]any{
+				"total": len(listed),
+			}
+			if filter != "" {
+				data["filter"] = filter
+			}
+			if !typesAll && module != "" {
+				data["module"] = module
+			}
+			if typesLimit > 0 && len(listed) > typesLimit {
+				listed = listed[:typesLimit]
+				data["truncated"] = true
+			}
+			data["count"] = len(listed)
+			if typesMethods {
+				functions, err := c.ListFunctions("")
+				if err != nil {
+					output.Error("types", err).PrintAndExit(getOutputFormat())
+				}
+				methods := typeMethods(functions)
+				withMethods := make([]map[string]any, 0, len(listed))
+				for _, t := range listed {
+					m := methods[stripTypeParams(t)]
+					if m == nil {
+						m = []string{}
+					}
+					withMethods = append(withMethods, map[string]any{"type": t, "methods": m})
+				}
+				data["types"] = withMethods
+			} else {
+				data["types"] = listed
+			}
+			if len(listed) == 0 && !typesAll && len(types) > 0 {
+				data["hint"] = fmt.Sprintf("%d types outside the main module match; use --all to list them", len(listedTypes(types, nil, true)))
+			}
+
+			output.Success("types", data, fmt.Sprintf("%d types", data["total"])).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	typesCmd.Flags().BoolVar(&typesAll, "all", false, "Include types outside the main module")
+	typesCmd.Flags().BoolVar(&typesMethods, "methods", false, "List each type's methods")
+	typesCmd.Flags().IntVar(&typesLimit, "limit", defaultTypesLimit, "Maximum number of types listed (0 = no limit)")
+	root.AddCommand(typesCmd)
+}
+
+func init() {
+	addTypesCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestListedTypes checks that type literals and types outside the main
+// module are left out.
+func TestListedTypes(t *testing.T) {
+	types := []string{
+		"main.Server",
+		"*main.Server",
+		"[]main.Server",
+		"map[string]main.Server",
+		"struct { a int }",
+		"main.List[int]",
+		"example.com/app/db.Conn",
+		"net/http.Server",
+		"int",
+	}
+	got := listedTypes(types, mainModuleFilter("example.com/app"), false)
+	want := []string{"example.com/app/db.Conn", "main.List[int]", "main.Server"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("main module: got %v, want %v", got, want)
+	}
+	if got := listedTypes(types, nil, true); len(got) != 4 {
+		t.Errorf("--all: got %v, want the 4 named types", got)
+	}
+}
+
+// TestTypeMethods checks grouping by receiver, with closures left out and
+// generic instantiations collapsed.
+func TestTypeMethods(t *testing.T) {
+	methods := typeMethods([]string{
+		"main.(*Server).Start",
+		"main.(*Server).Start.func1",
+		"main.Server.String",
+		"main.(*List[go.shape.int]).Push",
+		"main.(*List[go.shape.string]).Push",
+		"main.main",
+	})
+	if got, want := methods["main.Server"], []string{"main.(*Server).Start", "main.Server.String"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("main.Server: got %v, want %v", got, want)
+	}
+	if got, want := methods["main.List"], []string{"main.(*List).Push"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("main.List: got %v, want %v", got, want)
+	}
+	if len(methods) != 2 {
+		t.Errorf("got receivers %v, want main.Server and main.List", methods)
+	}
+}
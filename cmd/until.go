@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// addUntilCommand adds the until command
+func addUntilCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat, getTimeout func() time.Duration) {
+	var untilNoTimeout bool
+
+	untilCmd := &cobra.Command{
+		Use:   "until <location>",
+		Short: "Continue to a location",
+		Long: `Continue until the program reaches a file:line or function, through a
+one-shot breakpoint that is removed again however continue ends. The
+breakpoint list is left as it was.
+
+Other breakpoints still stop the program first: data.reached tells whether
+it stopped at the location. If a breakpoint already exists there, it is used
+and kept. Locations resolve as for break. Should the timeout expire first,
+the program is halted so the breakpoint can be removed.
+
+Example:
+  godebug --addr $ADDR until main.go:88
+  godebug --addr $ADDR until main.(*Server).flush
+  godebug --addr $ADDR until worker.go:120 --no-timeout`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			location := args[0]
+			c := mustGetClient("until")
+			defer func() { _ = c.Close() }()
+
+			bp, err := locationBreakpoint(c, location)
+			if err != nil {
+				output.Error("until", err).PrintAndExit(getOutputFormat())
+			}
+			created, err := c.CreateBreakpoint(bp)
+			existing := err != nil && strings.Contains(err.Error(), "Breakpoint exists")
+			if err != nil && !existing {
+				if info := unresolvedFileError(c, bp, err); info != nil {
+					output.ErrorWithInfo("until", info).PrintAndExit(getOutputFormat())
+				}
+				output.Error("until", err).PrintAndExit(getOutputFormat())
+			}
+
+			state, interrupted, passed, err := continuePastDumps(c, untilNoTimeout, getTimeout())
+			if created != nil {
+				// A target still running after a timeout must stop to drop the breakpoint
+				if s, serr := c.GetState(); serr == nil && s.Running {
+					_, _ = c.Halt()
+				}
+				_, _ = c.ClearBreakpoint(created.ID)
+			}
+			if err != nil {
+				output.Error("until", err).PrintAndExit(getOutputFormat())
+			}
+
+			stoppedAt := recordStop(c, "until", state)
+
+			data := stateToData(state)
+			stoppedAt.addTo(data)
+			data["location"] = location
+			reached := untilReached(state, created, bp)
+			data["reached"] = reached
+			if interrupted {
+				data["interrupted"] = true
+			}
+			passed.addTo(data)
+			if crash := captureCrash(c, state); crash != nil {
+				data["crash"] = crash
+			}
+
+			var msg string
+			switch {
+			case reached:
+				msg = fmt.Sprintf("Reached %s", location)
+			case state.Exited:
+				msg = fmt.Sprintf("Process exited before reaching %s", location)
+			case interrupted:
+				msg = fmt.Sprintf("Interrupted before reaching %s; process halted", location)
+			default:
+				msg = fmt.Sprintf("Stopped before reaching %s", location)
+			}
+			output.Success("until", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	untilCmd.Flags().BoolVar(&untilNoTimeout, "no-timeout", false, "Wait until the program stops; interrupting godebug halts it")
+	root.AddCommand(untilCmd)
+}
+
+// untilReached reports whether the program stopped at the until location:
+// at the one-shot breakpoint, or at the breakpoint that already existed there
+func untilReached(state *api.DebuggerState, created, requested *api.Breakpoint) bool {
+	if state.Exited || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return false
+	}
+	hit := state.CurrentThread.Breakpoint
+	if created != nil {
+		return hit.ID == created.ID
+	}
+	if requested.FunctionName != "" {
+		return hit.FunctionName == requested.FunctionName
+	}
+	return hit.File == requested.File && hit.Line == requested.Line
+}
+
+func init() {
+	addUntilCommand(rootCmd, MustGetClient, GetOutputFormat, GetTimeout)
+}
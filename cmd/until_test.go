@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestUntilReached checks the stop is matched against the one-shot
+// breakpoint, or the location of an existing one.
+func TestUntilReached(t *testing.T) {
+	stoppedAt := func(bp *api.Breakpoint) *api.DebuggerState {
+		return &api.DebuggerState{CurrentThread: &api.Thread{Breakpoint: bp}}
+	}
+	requested := &api.Breakpoint{File: "/app/main.go", Line: 88}
+	created := &api.Breakpoint{ID: 7, File: "/app/main.go", Line: 88}
+
+	if !untilReached(stoppedAt(&api.Breakpoint{ID: 7}), created, requested) {
+		t.Error("stop at the one-shot breakpoint not reached")
+	}
+	if untilReached(stoppedAt(&api.Breakpoint{ID: 2, File: "/app/main.go", Line: 40}), created, requested) {
+		t.Error("stop at another breakpoint reached")
+	}
+	if !untilReached(stoppedAt(&api.Breakpoint{ID: 3, File: "/app/main.go", Line: 88}), nil, requested) {
+		t.Error("stop at the existing breakpoint not reached")
+	}
+	if untilReached(&api.DebuggerState{Exited: true}, created, requested) {
+		t.Error("exit reached")
+	}
+	if !untilReached(stoppedAt(&api.Breakpoint{ID: 4, FunctionName: "main.flush"}), nil, &api.Breakpoint{FunctionName: "main.flush"}) {
+		t.Error("stop at the existing function breakpoint not reached")
+	}
+}
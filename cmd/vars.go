@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// defaultVarsLimit bounds the variables vars lists unless --limit is given
+const defaultVarsLimit = 100
+
+// listedPackageVars keeps the package-level variables vars reports: not the
+// compiler's own (main..stmp_0, go:itab...), and unless all is set only
+// those of the main module
+func listedPackageVars(vars []api.Variable, inMainModule func(string) bool, all bool) []api.Variable {
+	var listed []api.Variable
+	for _, v := range vars {
+		if strings.HasPrefix(v.Name, "go:") || strings.Contains(v.Name, "..") {
+			continue
+		}
+		if !all && !inMainModule(parseFuncSymbol(v.Name).Pkg) {
+			continue
+		}
+		listed = append(listed, v)
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].Name < listed[j].Name })
+	return listed
+}
+
+// addVarsCommand adds the vars command
+func addVarsCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var varsAll bool
+	var varsLimit int
+
+	varsCmd := &cobra.Command{
+		Use:   "vars [regexp]",
+		Short: "Show package-level variables",
+		Long: `List the package-level variables whose qualified name (main.counter,
+github.com/acme/app/config.Default) matches regexp, with their values. Values
+are loaded with the same limits as locals.
+
+By default only variables of the main module are listed; --all includes the
+standard library, dependencies and the runtime. At most --limit variables are
+returned (0 = no limit). To read one variable in full, eval its qualified
+name.
+
+Example:
+  godebug --addr $ADDR vars
+  godebug --addr $ADDR vars 'main\.counter$'
+  godebug --addr $ADDR vars --all '^net/http\.DefaultClient$'`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			filter := ""
+			if len(args) > 0 {
+				filter = args[0]
+			}
+			if _, err := regexp.Compile(filter); err != nil {
+				output.ErrorWithInfo("vars", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid regexp: %v", err),
+					map[string]any{"filter": filter},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if varsLimit < 0 {
+				output.ErrorWithInfo("vars", output.InvalidArgument("--limit must not be negative")).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("vars")
+			defer func() { _ = c.Close() }()
+
+			vars, err := c.ListPackageVars(filter, debugger.DefaultLoadConfig())
+			if err != nil {
+				output.Error("vars", err).PrintAndExit(getOutputFormat())
+			}
+			module := mainModulePath(c)
+			listed := listedPackageVars(vars, mainModuleFilter(module), varsAll)
+
+			data := map[string]any{
+				"total": len(listed),
+			}
+			if filter != "" {
+				data["filter"] = filter
+			}
+			if !varsAll && module != "" {
+				data["module"] = module
+			}
+			if varsLimit > 0 && len(listed) > varsLimit {
+				listed = listed[:varsLimit]
+				data["truncated"] = true
+			}
+			data["variables"] = variablesToMaps(listed)
+			data["count"] = len(listed)
+			if len(listed) == 0 && !varsAll && len(vars) > 0 {
+				data["hint"] = fmt.Sprintf("%d variables outside the main module match; use --all to list them", len(listedPackageVars(vars, nil, true)))
+			}
+
+			output.Success("vars", data, fmt.Sprintf("%d package variables", data["total"])).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	varsCmd.Flags().BoolVar(&varsAll, "all", false, "Include variables outside the main module")
+	varsCmd.Flags().IntVar(&varsLimit, "limit", defaultVarsLimit, "Maximum number of variables listed (0 = no limit)")
+	root.AddCommand(varsCmd)
+}
+
+func init() {
+	addVarsCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
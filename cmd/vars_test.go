@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestListedPackageVars checks that compiler variables and variables outside
+// the main module are left out, and the order.
+func TestListedPackageVars(t *testing.T) {
+	vars := []api.Variable{
+		{Name: "main.counter"},
+		{Name: "example.com/app/db.pool"},
+		{Name: "main..stmp_0"},
+		{Name: "go:itab.*os.File,io.Writer"},
+		{Name: "net/http.DefaultClient"},
+		{Name: "main.config"},
+	}
+	inMain := mainModuleFilter("example.com/app")
+
+	var got []string
+	for _, v := range listedPackageVars(vars, inMain, false) {
+		got = append(got, v.Name)
+	}
+	want := []string{"example.com/app/db.pool", "main.config", "main.counter"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+	if all := listedPackageVars(vars, inMain, true); len(all) != 4 {
+		t.Errorf("--all: got %d variables, want 4", len(all))
+	}
+}
@@ -0,0 +1,611 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Edges of the wait-for graph
+const (
+	edgeWaits  = "waits"   // goroutine -> resource it is blocked on
+	edgeHeldBy = "held-by" // resource -> goroutine that may release it
+)
+
+// waitRefDepth is how deep variables are searched for a reference to a resource
+const waitRefDepth = 3
+
+// waitNode is a goroutine or a resource of the wait-for graph
+type waitNode struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	GoroutineID int64  `json:"goroutineId,omitempty"`
+	Location    string `json:"location,omitempty"`
+	WaitReason  string `json:"waitReason,omitempty"`
+	Addr        string `json:"addr,omitempty"`
+	// State is "locked" or "unlocked" for a mutex whose state could be read
+	State string `json:"state,omitempty"`
+}
+
+// waitEdge connects a waiting goroutine to a resource and a resource to a
+// goroutine that may hold it. Evidence names the variable through which the
+// holder refers to the resource.
+type waitEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Kind     string `json:"kind"`
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// waitHolder is a goroutine that refers to a resource without waiting on it
+type waitHolder struct {
+	GoroutineID int64
+	Evidence    string
+}
+
+// waitGraph is the wait-for graph: goroutine -> resource -> holder goroutine
+type waitGraph struct {
+	Nodes []waitNode `json:"nodes"`
+	Edges []waitEdge `json:"edges"`
+}
+
+// goroutineNodeID and resourceNodeID are the IDs of graph nodes
+func goroutineNodeID(id int64) string {
+	return "g" + strconv.FormatInt(id, 10)
+}
+
+func resourceNodeID(obj waitObject) string {
+	return obj.Kind + "@" + obj.Addr
+}
+
+// buildWaitGraph assembles the graph from what each goroutine waits on and
+// the holders found for each resource (keyed by address). goroutines
+// describes the goroutine nodes; resource states are keyed by address too.
+func buildWaitGraph(waits map[int64][]waitObject, holders map[string][]waitHolder, goroutines map[int64]waitNode, states map[string]string) waitGraph {
+	graph := waitGraph{Nodes: []waitNode{}, Edges: []waitEdge{}}
+	seen := map[string]bool{}
+	addNode := func(n waitNode) {
+		if !seen[n.ID] {
+			seen[n.ID] = true
+			graph.Nodes = append(graph.Nodes, n)
+		}
+	}
+	addGoroutine := func(id int64) string {
+		n, ok := goroutines[id]
+		if !ok {
+			n = waitNode{Type: "goroutine", GoroutineID: id}
+		}
+		n.ID = goroutineNodeID(id)
+		addNode(n)
+		return n.ID
+	}
+
+	ids := make([]int64, 0, len(waits))
+	for id := range waits {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		from := addGoroutine(id)
+		for _, obj := range waits[id] {
+			res := resourceNodeID(obj)
+			addNode(waitNode{ID: res, Type: obj.Kind, Addr: obj.Addr, State: states[obj.Addr]})
+			graph.Edges = append(graph.Edges, waitEdge{From: from, To: res, Kind: edgeWaits})
+			for _, h := range holders[obj.Addr] {
+				edge := waitEdge{From: res, To: goroutineNodeID(h.GoroutineID), Kind: edgeHeldBy, Evidence: h.Evidence}
+				if !slices.Contains(graph.Edges, edge) {
+					addGoroutine(h.GoroutineID)
+					graph.Edges = append(graph.Edges, edge)
+				}
+			}
+		}
+	}
+	return graph
+}
+
+// goroutineEdges reduces the graph to goroutine -> goroutine edges: a waits
+// for b when a is blocked on a resource b may hold, and for itself when it
+// locks a mutex it already holds
+func (g waitGraph) goroutineEdges() map[string][]string {
+	heldBy := map[string][]string{}
+	for _, e := range g.Edges {
+		if e.Kind == edgeHeldBy {
+			heldBy[e.From] = append(heldBy[e.From], e.To)
+		}
+	}
+	adj := map[string][]string{}
+	for _, e := range g.Edges {
+		if e.Kind != edgeWaits {
+			continue
+		}
+		for _, h := range heldBy[e.To] {
+			if !slices.Contains(adj[e.From], h) {
+				adj[e.From] = append(adj[e.From], h)
+			}
+		}
+	}
+	for k := range adj {
+		sort.Strings(adj[k])
+	}
+	return adj
+}
+
+// waitCycles returns the strongly connected components of more than one
+// goroutine, and single goroutines waiting on themselves: sets of goroutines
+// each waiting, through the others, on itself
+func waitCycles(adj map[string][]string) [][]string {
+	var nodes []string
+	for n := range adj {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+
+	// Tarjan's algorithm
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var cycles [][]string
+	var visit func(string)
+	visit = func(v string) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range adj[v] {
+			if _, ok := index[w]; !ok {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var scc []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		if len(scc) > 1 || slices.Contains(adj[v], v) {
+			sort.Strings(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for _, n := range nodes {
+		if _, ok := index[n]; !ok {
+			visit(n)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// waitChains returns the longest wait chains outside cycles, from a goroutine
+// no one waits for to the last goroutine it transitively waits for, that
+// involve at least minLen goroutines
+func waitChains(adj map[string][]string, cycles [][]string, minLen int) [][]string {
+	inCycle := map[string]bool{}
+	for _, c := range cycles {
+		for _, n := range c {
+			inCycle[n] = true
+		}
+	}
+	waitedFor := map[string]bool{}
+	for _, targets := range adj {
+		for _, t := range targets {
+			waitedFor[t] = true
+		}
+	}
+
+	longest := map[string][]string{}
+	var walk func(string) []string
+	walk = func(n string) []string {
+		if chain, ok := longest[n]; ok {
+			return chain
+		}
+		best := []string{n}
+		for _, next := range adj[n] {
+			if inCycle[next] {
+				continue
+			}
+			if chain := walk(next); len(chain)+1 > len(best) {
+				best = append([]string{n}, chain...)
+			}
+		}
+		longest[n] = best
+		return best
+	}
+
+	var roots []string
+	for n := range adj {
+		if !waitedFor[n] && !inCycle[n] {
+			roots = append(roots, n)
+		}
+	}
+	sort.Strings(roots)
+	var chains [][]string
+	for _, r := range roots {
+		if chain := walk(r); len(chain) >= minLen {
+			chains = append(chains, chain)
+		}
+	}
+	sort.SliceStable(chains, func(i, j int) bool { return len(chains[i]) > len(chains[j]) })
+	return chains
+}
+
+// contendedResources counts the waiters of each resource with more than one
+func (g waitGraph) contendedResources() []map[string]any {
+	waiters := map[string]int{}
+	for _, e := range g.Edges {
+		if e.Kind == edgeWaits {
+			waiters[e.To]++
+		}
+	}
+	var out []map[string]any
+	for _, n := range g.Nodes {
+		if waiters[n.ID] > 1 {
+			out = append(out, map[string]any{"id": n.ID, "type": n.Type, "waiters": waiters[n.ID]})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i]["waiters"].(int) > out[j]["waiters"].(int) })
+	return out
+}
+
+// waitGraphDOT renders the graph in Graphviz DOT: goroutines as boxes,
+// resources as ellipses, held-by edges dashed and cycle members in red
+func waitGraphDOT(g waitGraph, cycles [][]string) string {
+	inCycle := map[string]bool{}
+	for _, c := range cycles {
+		for _, n := range c {
+			inCycle[n] = true
+		}
+	}
+	var b strings.Builder
+	b.WriteString("digraph waitfor {\n\trankdir=LR;\n")
+	for _, n := range g.Nodes {
+		var label, shape string
+		if n.Type == "goroutine" {
+			shape = "box"
+			label = fmt.Sprintf("goroutine %d", n.GoroutineID)
+			if n.Location != "" {
+				label += "\\n" + n.Location
+			}
+		} else {
+			shape = "ellipse"
+			label = n.Type + "\\n" + n.Addr
+			if n.State != "" {
+				label += "\\n" + n.State
+			}
+		}
+		attrs := fmt.Sprintf("shape=%s, label=%s", shape, strconv.Quote(label))
+		if inCycle[n.ID] {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", strconv.Quote(n.ID), attrs)
+	}
+	for _, e := range g.Edges {
+		attrs := ""
+		if e.Kind == edgeHeldBy {
+			attrs = ` [style=dashed, label="held by?"]`
+		}
+		fmt.Fprintf(&b, "\t%s -> %s%s;\n", strconv.Quote(e.From), strconv.Quote(e.To), attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// varReference reports whether v refers to the resource at addr: is it, points
+// to it, or holds it in a field, as loaded. It returns the path of the
+// reference below name.
+func varReference(name string, v api.Variable, addr uint64, depth int) (string, bool) {
+	switch {
+	case v.Kind == reflect.Chan:
+		return name, v.Base == addr
+	case v.Addr == addr && v.Kind == reflect.Struct:
+		return name, true
+	case depth == 0:
+		return "", false
+	case v.Kind == reflect.Ptr && len(v.Children) == 1:
+		if v.Children[0].Addr == addr {
+			return name, true
+		}
+		return varReference(name, v.Children[0], addr, depth-1)
+	case v.Kind == reflect.Struct:
+		for _, f := range v.Children {
+			if path, ok := varReference(name+"."+f.Name, f, addr, depth-1); ok {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// isWaitMachinery reports whether a frame is part of blocking itself rather
+// than user code that may hold a resource
+func isWaitMachinery(fn string) bool {
+	return strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, "sync.") || strings.HasPrefix(fn, "internal/sync.")
+}
+
+// waitingFrame returns the index of the innermost frame outside the blocking
+// machinery, the user code that called Lock, Wait or the channel operation,
+// or -1 when there is none
+func waitingFrame(frames []api.Stackframe) int {
+	for i, f := range frames {
+		if f.Function != nil && !isWaitMachinery(f.Function.Name()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// selfHeld reports whether a goroutine blocked on obj may hold it itself:
+// a mutex is locked again while a caller of the waiting frame refers to it
+func selfHeld(obj waitObject, frame, waiting int) bool {
+	return (obj.Kind == "sync.Mutex" || obj.Kind == "sync.RWMutex") && waiting >= 0 && frame > waiting
+}
+
+// findHolders scans the stack of goroutine g for variables that refer to any
+// of resources. A resource g itself waits on is only taken as held by g when
+// it is a mutex referred to above the waiting frame. It returns the evidence
+// for each resource found, keyed by address.
+func findHolders(c *debugger.Client, g *api.Goroutine, depth int, resources []waitObject, waiting []waitObject) map[string]string {
+	cfg := api.LoadConfig{FollowPointers: true, MaxVariableRecurse: waitRefDepth, MaxStructFields: -1}
+	frames, err := c.Stacktrace(g.ID, depth, &cfg)
+	if err != nil {
+		return nil
+	}
+	waitingAt := -1
+	if len(waiting) > 0 {
+		waitingAt = waitingFrame(frames)
+	}
+	found := map[string]string{}
+	for i, f := range frames {
+		if f.Function == nil || isWaitMachinery(f.Function.Name()) {
+			continue
+		}
+		vars := append(slices.Clone(f.Arguments), f.Locals...)
+		for _, obj := range resources {
+			if _, ok := found[obj.Addr]; ok {
+				continue
+			}
+			if slices.Contains(waiting, obj) && !selfHeld(obj, i, waitingAt) {
+				continue
+			}
+			addr, err := strconv.ParseUint(strings.TrimPrefix(obj.Addr, "0x"), 16, 64)
+			if err != nil {
+				continue
+			}
+			for _, v := range vars {
+				if path, ok := varReference(v.Name, v, addr, waitRefDepth); ok {
+					found[obj.Addr] = fmt.Sprintf("%s in %s (frame %d, %s:%d)", path, f.Function.Name(), i, filepath.Base(f.File), f.Line)
+					break
+				}
+			}
+		}
+	}
+	return found
+}
+
+// mutexState reads whether the mutex at addr is locked, from the low bit of
+// its state field. It returns "" when the state cannot be read.
+func mutexState(c *debugger.Client, obj waitObject) string {
+	if obj.Kind != "sync.Mutex" {
+		return ""
+	}
+	v, err := c.EvalInScope(api.EvalScope{GoroutineID: -1}, fmt.Sprintf("*(*sync.Mutex)(%s)", obj.Addr), api.LoadConfig{MaxVariableRecurse: 2, MaxStructFields: -1})
+	if err != nil {
+		return ""
+	}
+	var find func(api.Variable) string
+	find = func(v api.Variable) string {
+		for _, f := range v.Children {
+			if f.Name == "state" {
+				return f.Value
+			}
+			if s := find(f); s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	state, err := strconv.ParseInt(find(*v), 10, 64)
+	if err != nil {
+		return ""
+	}
+	if state&1 == 0 {
+		return "unlocked"
+	}
+	return "locked"
+}
+
+// writeDOT writes the DOT rendering of the graph and returns its absolute path
+func writeDOT(path string, g waitGraph, cycles [][]string) (string, error) {
+	if err := os.WriteFile(path, []byte(waitGraphDOT(g, cycles)), 0o600); err != nil {
+		return "", err
+	}
+	return filepath.Abs(path)
+}
+
+// addWaitForCommand adds analyze wait-for to the analyze command
+func addWaitForCommand(analyzeCmd *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var waitDepth, waitMinChain, waitMaxGoroutines int
+	var waitDOT string
+
+	waitForCmd := &cobra.Command{
+		Use:   "wait-for",
+		Short: "Build the wait-for graph between goroutines and find deadlocks",
+		Long: `Build a directed wait-for graph of the stopped target: each blocked goroutine
+points to the channel, sync.Mutex, sync.RWMutex, sync.WaitGroup or sync.Cond
+it is blocked on, and each of those to the goroutines that may release it.
+
+Go does not record who holds a mutex or will send on a channel, so holders
+are inferred: a goroutine that is not blocked on the resource itself but has
+a variable in its stack (--depth frames) referring to it, directly, through a
+pointer or in a field, is a candidate. Every held-by edge carries that
+variable as evidence. A sync.Mutex found unlocked has no holders. A
+goroutine blocked locking a mutex holds it itself when a caller of the
+blocked frame refers to it, the common re-locking deadlock.
+
+From the goroutine -> goroutine edges the analysis reports:
+  cycles      goroutines waiting on each other, or one on itself: a
+              deadlock, unless a candidate holder is a false positive
+  chains      wait chains of at least --min-chain goroutines (convoying)
+  contended   resources with several waiters
+
+--dot writes the graph in Graphviz DOT (render with dot -Tsvg); cycle
+members are red, inferred held-by edges dashed.
+
+Example:
+  godebug --addr $ADDR analyze wait-for
+  godebug --addr $ADDR analyze wait-for --dot waitfor.dot --min-chain 4`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if waitDepth < 1 || waitMinChain < 2 || waitMaxGoroutines < 1 {
+				output.ErrorWithInfo("analyze wait-for", output.InvalidArgumentWithDetails(
+					"--depth and --max-goroutines must be at least 1, --min-chain at least 2",
+					map[string]any{"depth": waitDepth, "minChain": waitMinChain, "maxGoroutines": waitMaxGoroutines},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("analyze wait-for")
+			defer func() { _ = c.Close() }()
+
+			goroutines, _, err := c.ListGoroutines(0, 0)
+			if err != nil {
+				output.Error("analyze wait-for", err).PrintAndExit(getOutputFormat())
+			}
+			ver := targetGoVersion(c)
+
+			nodes := map[int64]waitNode{}
+			waits := map[int64][]waitObject{}
+			var resources []waitObject
+			for _, g := range goroutines {
+				n := waitNode{Type: "goroutine", GoroutineID: g.ID}
+				if loc := g.UserCurrentLoc; loc.Function != nil {
+					n.Location = fmt.Sprintf("%s (%s:%d)", loc.Function.Name(), filepath.Base(loc.File), loc.Line)
+				}
+				gData := map[string]any{}
+				objs := annotateWait(c, ver, g, gData)
+				n.WaitReason, _ = gData["waitReason"].(string)
+				nodes[g.ID] = n
+				if len(objs) == 0 {
+					continue
+				}
+				waits[g.ID] = objs
+				for _, obj := range objs {
+					if !slices.Contains(resources, obj) {
+						resources = append(resources, obj)
+					}
+				}
+			}
+
+			states := map[string]string{}
+			for _, obj := range resources {
+				if s := mutexState(c, obj); s != "" {
+					states[obj.Addr] = s
+				}
+			}
+
+			holders := map[string][]waitHolder{}
+			scanned, truncated := 0, false
+			if len(resources) > 0 {
+				for _, g := range goroutines {
+					if loc := g.UserCurrentLoc; loc.File == "" || !isUserSource(loc.File) {
+						continue
+					}
+					if scanned == waitMaxGoroutines {
+						truncated = true
+						break
+					}
+					scanned++
+					for addr, evidence := range findHolders(c, g, waitDepth, resources, waits[g.ID]) {
+						if states[addr] != "unlocked" {
+							holders[addr] = append(holders[addr], waitHolder{GoroutineID: g.ID, Evidence: evidence})
+						}
+					}
+				}
+			}
+
+			graph := buildWaitGraph(waits, holders, nodes, states)
+			adj := graph.goroutineEdges()
+			cycles := waitCycles(adj)
+			chains := waitChains(adj, cycles, waitMinChain)
+			contended := graph.contendedResources()
+
+			data := map[string]any{
+				"graph":      graph,
+				"blocked":    len(waits),
+				"resources":  len(resources),
+				"scanned":    scanned,
+				"cycles":     cycles,
+				"chains":     chains,
+				"contended":  contended,
+				"goroutines": len(goroutines),
+			}
+			if cycles == nil {
+				data["cycles"] = [][]string{}
+			}
+			if chains == nil {
+				data["chains"] = [][]string{}
+			}
+			if contended == nil {
+				data["contended"] = []map[string]any{}
+			}
+			if truncated {
+				data["truncated"] = true
+			}
+			if waitDOT != "" {
+				file, err := writeDOT(waitDOT, graph, cycles)
+				if err != nil {
+					output.Error("analyze wait-for", err).PrintAndExit(getOutputFormat())
+				}
+				data["dotFile"] = file
+			}
+
+			var findings []string
+			for _, cycle := range cycles {
+				if len(cycle) == 1 {
+					findings = append(findings, fmt.Sprintf("possible deadlock: %s locks a mutex it already holds", cycle[0]))
+					continue
+				}
+				findings = append(findings, fmt.Sprintf("possible deadlock: %s wait on each other", strings.Join(cycle, ", ")))
+			}
+			if len(chains) > 0 {
+				findings = append(findings, fmt.Sprintf("wait chain of %d goroutines: %s", len(chains[0]), strings.Join(chains[0], " -> ")))
+			}
+			if len(contended) > 0 {
+				findings = append(findings, fmt.Sprintf("%d goroutines are blocked on %s", contended[0]["waiters"], contended[0]["id"]))
+			}
+			if len(findings) > 0 {
+				data["findings"] = findings
+			}
+
+			msg := fmt.Sprintf("%d blocked goroutines on %d resources, %d cycles", len(waits), len(resources), len(cycles))
+			output.Success("analyze wait-for", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	waitForCmd.Flags().IntVar(&waitDepth, "depth", 10, "Frames of each goroutine searched for references to a resource")
+	waitForCmd.Flags().IntVar(&waitMinChain, "min-chain", 3, "Goroutines in a wait chain for it to be reported")
+	waitForCmd.Flags().IntVar(&waitMaxGoroutines, "max-goroutines", 1000, "Goroutines searched for holders")
+	waitForCmd.Flags().StringVar(&waitDOT, "dot", "", "Write the graph in Graphviz DOT to this file")
+	analyzeCmd.AddCommand(waitForCmd)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestWaitForGraph checks a lock-order deadlock and a re-locked mutex are
+// found as cycles and a convoy behind a channel as a chain.
+func TestWaitForGraph(t *testing.T) {
+	a := waitObject{Kind: "sync.Mutex", Addr: "0xc000010000"}
+	b := waitObject{Kind: "sync.Mutex", Addr: "0xc000010008"}
+	ch := waitObject{Kind: "chan", Addr: "0xc000020000"}
+	wg := waitObject{Kind: "sync.WaitGroup", Addr: "0xc000030000"}
+	c := waitObject{Kind: "sync.Mutex", Addr: "0xc000040000"}
+	waits := map[int64][]waitObject{
+		1: {b}, 2: {a}, // 1 holds a and wants b, 2 the reverse
+		3: {wg}, 4: {ch}, 5: {ch},
+		7: {c}, // 7 locks c again while holding it
+	}
+	holders := map[string][]waitHolder{
+		a.Addr:  {{GoroutineID: 1, Evidence: "s.a in main.transfer"}},
+		b.Addr:  {{GoroutineID: 2, Evidence: "s.b in main.transfer"}},
+		wg.Addr: {{GoroutineID: 4, Evidence: "wg in main.worker"}},
+		ch.Addr: {{GoroutineID: 6, Evidence: "jobs in main.produce"}},
+		c.Addr:  {{GoroutineID: 7, Evidence: "s.mu in main.(*Store).Flush"}},
+	}
+	graph := buildWaitGraph(waits, holders, nil, map[string]string{a.Addr: "locked"})
+	adj := graph.goroutineEdges()
+
+	cycles := waitCycles(adj)
+	if !reflect.DeepEqual(cycles, [][]string{{"g1", "g2"}, {"g7"}}) {
+		t.Errorf("cycles = %v", cycles)
+	}
+	chains := waitChains(adj, cycles, 3)
+	if !reflect.DeepEqual(chains, [][]string{{"g3", "g4", "g6"}}) {
+		t.Errorf("chains = %v", chains)
+	}
+	contended := graph.contendedResources()
+	if len(contended) != 1 || contended[0]["id"] != "chan@0xc000020000" || contended[0]["waiters"] != 2 {
+		t.Errorf("contended = %v", contended)
+	}
+
+	dot := waitGraphDOT(graph, cycles)
+	for _, want := range []string{
+		`"g1" [shape=box, label="goroutine 1", color=red];`,
+		`"sync.Mutex@0xc000010000" [shape=ellipse, label="sync.Mutex\\n0xc000010000\\nlocked"];`,
+		`"chan@0xc000020000" -> "g6" [style=dashed, label="held by?"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT is missing %s:\n%s", want, dot)
+		}
+	}
+}
+
+// TestVarReference checks references through a pointer, a field and a channel.
+func TestVarReference(t *testing.T) {
+	mu := api.Variable{Name: "mu", Kind: reflect.Struct, Addr: 0x1008}
+	store := api.Variable{Kind: reflect.Struct, Addr: 0x1000, Children: []api.Variable{
+		{Name: "n", Kind: reflect.Int, Addr: 0x1000},
+		mu,
+	}}
+	s := api.Variable{Name: "s", Kind: reflect.Ptr, Children: []api.Variable{store}}
+	if path, ok := varReference("s", s, 0x1008, waitRefDepth); !ok || path != "s.mu" {
+		t.Errorf("field through pointer: got %q %v", path, ok)
+	}
+	if _, ok := varReference("s", s, 0x2000, waitRefDepth); ok {
+		t.Errorf("unrelated address matched")
+	}
+	jobs := api.Variable{Name: "jobs", Kind: reflect.Chan, Base: 0x3000}
+	if path, ok := varReference("jobs", jobs, 0x3000, waitRefDepth); !ok || path != "jobs" {
+		t.Errorf("channel: got %q %v", path, ok)
+	}
+}
+
+// TestSelfHeld checks that a goroutine holds a mutex it waits on only when a
+// frame above the one locking it refers to it.
+func TestSelfHeld(t *testing.T) {
+	fn := func(name string) api.Stackframe {
+		return api.Stackframe{Location: api.Location{Function: &api.Function{Name_: name}}}
+	}
+	frames := []api.Stackframe{fn("runtime.gopark"), fn("sync.(*Mutex).Lock"), fn("main.(*Store).Get"), fn("main.(*Store).Flush")}
+	waiting := waitingFrame(frames)
+	if waiting != 2 {
+		t.Fatalf("waitingFrame = %d, want 2", waiting)
+	}
+	mu := waitObject{Kind: "sync.Mutex", Addr: "0xc000040000"}
+	if !selfHeld(mu, 3, waiting) {
+		t.Error("mutex referred to by the caller is not held")
+	}
+	if selfHeld(mu, 2, waiting) {
+		t.Error("mutex referred to by the waiting frame itself is held")
+	}
+	if selfHeld(waitObject{Kind: "chan", Addr: "0xc000020000"}, 3, waiting) {
+		t.Error("channel is held")
+	}
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/goversion"
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// maxSelectCases bounds how many channels of a blocked select are followed
+const maxSelectCases = 16
+
+// syncWaitDepth is how many frames are searched for a blocking sync method
+const syncWaitDepth = 20
+
+// syncWaitFunc matches the sync methods a goroutine blocks in; the receiver is the object waited on
+var syncWaitFunc = regexp.MustCompile(`^(?:internal/)?sync\.\(\*(Mutex|RWMutex|WaitGroup|Cond)\)\.`)
+
+// waitObject is a channel or sync primitive a goroutine is blocked on
+type waitObject struct {
+	Kind string `json:"kind"`
+	Addr string `json:"addr"`
+}
+
+// formatAddr renders an address the way waitObject and --blocked-on compare them
+func formatAddr(addr uint64) string {
+	return fmt.Sprintf("%#x", addr)
+}
+
+// targetGoVersion returns the Go version the target was built with, or nil if unknown
+func targetGoVersion(c *debugger.Client) *goversion.GoVersion {
+	version, err := c.GetVersion()
+	if err != nil {
+		return nil
+	}
+	v, ok := goversion.Parse(version.TargetGoVersion)
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+// waitReasonName returns the runtime's name for a goroutine wait reason
+func waitReasonName(ver *goversion.GoVersion, reason int64) string {
+	if reason == 0 {
+		return ""
+	}
+	if ver == nil {
+		return fmt.Sprintf("unknown wait reason %d", reason)
+	}
+	return api.WaitReasonString(ver, reason)
+}
+
+// waitChannels follows the goroutine's sudog list to the channels it is parked on.
+// A plain send or receive has one entry, a select one per case.
+func waitChannels(c *debugger.Client, goroutineID int64) []waitObject {
+	var objs []waitObject
+	expr := "runtime.curg.waiting"
+	for range maxSelectCases {
+		v, err := c.EvalInScope(api.EvalScope{GoroutineID: goroutineID}, expr+".c", api.LoadConfig{})
+		if err != nil || len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			break
+		}
+		objs = append(objs, waitObject{Kind: "chan", Addr: formatAddr(v.Children[0].Addr)})
+		expr += ".waitlink"
+	}
+	return objs
+}
+
+// waitSyncObject finds the sync primitive a goroutine is blocked on from the
+// receiver of the outermost sync method on its stack
+func waitSyncObject(c *debugger.Client, goroutineID int64) []waitObject {
+	frames, err := c.Stacktrace(goroutineID, syncWaitDepth, nil)
+	if err != nil {
+		return nil
+	}
+
+	frame, kind := -1, ""
+	for i, f := range frames {
+		if f.Function == nil {
+			continue
+		}
+		if m := syncWaitFunc.FindStringSubmatch(f.Function.Name()); m != nil {
+			frame, kind = i, "sync."+m[1]
+			continue
+		}
+		// Stop at the first caller outside the runtime once a sync method was seen
+		if frame >= 0 && !strings.HasPrefix(f.Function.Name(), "runtime.") {
+			break
+		}
+	}
+	if frame < 0 {
+		return nil
+	}
+
+	args, err := c.ListFunctionArgsInScope(api.EvalScope{GoroutineID: goroutineID, Frame: frame}, api.LoadConfig{})
+	if err != nil || len(args) == 0 || len(args[0].Children) == 0 || args[0].Children[0].Addr == 0 {
+		return nil
+	}
+	return []waitObject{{Kind: kind, Addr: formatAddr(args[0].Children[0].Addr)}}
+}
+
+// annotateWait adds the wait reason and the objects a goroutine is blocked on to
+// its goroutines entry and returns those objects
+func annotateWait(c *debugger.Client, ver *goversion.GoVersion, g *api.Goroutine, gData map[string]any) []waitObject {
+	if g.WaitReason == 0 {
+		return nil
+	}
+	reason := waitReasonName(ver, g.WaitReason)
+	gData["waitReason"] = reason
+
+	unknown := ver == nil || strings.HasPrefix(reason, "unknown")
+	var objs []waitObject
+	if unknown || strings.Contains(reason, "chan") || strings.HasPrefix(reason, "select") {
+		objs = waitChannels(c, g.ID)
+	}
+	if len(objs) == 0 && (unknown || strings.HasPrefix(reason, "sync.") || reason == "semacquire") {
+		objs = waitSyncObject(c, g.ID)
+	}
+	if len(objs) > 0 {
+		gData["waitingOn"] = objs
+	}
+	return objs
+}
+
+// resolveWaitAddr turns a --blocked-on argument into an address. Hex literals are
+// used as is; anything else is evaluated in the selected goroutine, giving the
+// channel for chan values, the pointee for pointers and the variable itself otherwise.
+func resolveWaitAddr(c *debugger.Client, state *api.DebuggerState, arg string) (string, error) {
+	if strings.HasPrefix(arg, "0x") {
+		addr, err := strconv.ParseUint(arg[2:], 16, 64)
+		if err != nil {
+			return "", output.InvalidArgumentWithDetails(
+				fmt.Sprintf("invalid address: %s", arg),
+				map[string]any{"blockedOn": arg},
+			)
+		}
+		return formatAddr(addr), nil
+	}
+
+	goroutineID := int64(-1)
+	if state != nil && state.SelectedGoroutine != nil {
+		goroutineID = state.SelectedGoroutine.ID
+	}
+	v, err := c.EvalInScope(api.EvalScope{GoroutineID: goroutineID}, arg, api.LoadConfig{})
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case v.Kind == reflect.Chan:
+		return formatAddr(v.Base), nil
+	case v.Kind == reflect.Ptr && len(v.Children) > 0:
+		return formatAddr(v.Children[0].Addr), nil
+	default:
+		return formatAddr(v.Addr), nil
+	}
+}
+
+// waitsOn reports whether any of objs is at addr
+func waitsOn(objs []waitObject, addr string) bool {
+	for _, o := range objs {
+		if o.Addr == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// watchpointsFile stores the watchpoints of a session and the last value seen
+// at each
+const watchpointsFile = "watchpoints.json"
+
+// watchRecord is a watchpoint as the watch command set it. The watched memory
+// is re-read through Addr and Type, because the expression may not be in
+// scope where the watchpoint triggers.
+type watchRecord struct {
+	Expr   string `json:"expr"`
+	Type   string `json:"type"`
+	Addr   uint64 `json:"addr"`
+	Access string `json:"access"`
+	Last   string `json:"last"`
+}
+
+// watchAccessName names a watch type as the flags do
+func watchAccessName(wtype api.WatchType) string {
+	switch wtype {
+	case api.WatchRead:
+		return "read"
+	case api.WatchWrite:
+		return "write"
+	default:
+		return "rw"
+	}
+}
+
+// watchedValue reads the memory of a watch record
+func watchedValue(c *debugger.Client, goroutineID int64, rec watchRecord) (*api.Variable, error) {
+	expr := fmt.Sprintf("*(*%s)(%#x)", rec.Type, rec.Addr)
+	return c.EvalInScope(api.EvalScope{GoroutineID: goroutineID}, expr, debugger.DefaultLoadConfig())
+}
+
+// watchpointHit reports the watchpoint we stopped at: which access triggered it
+// and the value before and after, or nil if we did not stop at a watchpoint.
+// Watchpoints that went out of scope are reported and forgotten.
+func watchpointHit(c *debugger.Client, state *api.DebuggerState) map[string]any {
+	records := map[string]watchRecord{}
+	_ = session.LoadData(c.Addr(), watchpointsFile, &records)
+
+	var hit map[string]any
+	if bp := currentBreakpoint(state); bp != nil && bp.WatchExpr != "" {
+		key := strconv.Itoa(bp.ID)
+		rec, ok := records[key]
+		hit = map[string]any{
+			"id":         bp.ID,
+			"expression": bp.WatchExpr,
+			"hit":        bp.TotalHitCount,
+		}
+		if ok && state.SelectedGoroutine != nil {
+			// Watchpoints trigger after the access, so memory holds the new value
+			v, err := watchedValue(c, state.SelectedGoroutine.ID, rec)
+			if err != nil {
+				hit["error"] = err.Error()
+			} else {
+				cur := v.SinglelineString()
+				changed := cur != rec.Last
+				hit["old"] = rec.Last
+				hit["new"] = cur
+				hit["changed"] = changed
+				// The hardware does not tell reads from writes; a write that
+				// stores the same value reads as a read
+				access := rec.Access
+				if access == "rw" {
+					access = "read"
+					if changed {
+						access = "write"
+					}
+				}
+				hit["access"] = access
+				rec.Last = cur
+				records[key] = rec
+			}
+		}
+	}
+
+	var outOfScope []map[string]any
+	for _, bp := range state.WatchOutOfScope {
+		outOfScope = append(outOfScope, map[string]any{"id": bp.ID, "expression": bp.WatchExpr})
+		delete(records, strconv.Itoa(bp.ID))
+	}
+	if len(outOfScope) > 0 {
+		if hit == nil {
+			hit = map[string]any{}
+		}
+		hit["outOfScope"] = outOfScope
+	}
+
+	_ = session.SaveData(c.Addr(), watchpointsFile, records)
+	return hit
+}
+
+// currentBreakpoint returns the breakpoint the current thread stopped at
+func currentBreakpoint(state *api.DebuggerState) *api.Breakpoint {
+	if state.CurrentThread == nil {
+		return nil
+	}
+	return state.CurrentThread.Breakpoint
+}
+
+// addWatchCommand adds the watch command
+func addWatchCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var watchRead, watchWrite, watchRW bool
+
+	watchCmd := &cobra.Command{
+		Use:   "watch <expr>",
+		Short: "Stop when a variable's memory is read or written",
+		Long: `Set a data watchpoint: a hardware breakpoint on the memory expr refers to,
+evaluated in the selected goroutine and frame. continue stops right after an
+instruction writes (--write, the default), reads (--read) or reads or writes
+(--rw) it, wherever that happens.
+
+When continue stops at a watchpoint, data.watchpoint reports the
+expression, the access that triggered it and the value before (old) and after
+(new). With --rw the access is "write" if the value changed, else "read".
+
+The CPU limits watchpoints to a few (4 on amd64) of 1, 2, 4 or 8 bytes: watch
+a field or element rather than a whole struct. A watchpoint on a local
+variable is removed when its function returns; continue reports it in
+data.watchpoint.outOfScope. Use clear with the returned ID to remove one.
+
+Example:
+  godebug --addr $ADDR watch counter
+  godebug --addr $ADDR watch "w.state" --rw
+  godebug --addr $ADDR watch "cfg.Timeout" --read`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			expr := args[0]
+			wtype := api.WatchWrite
+			switch {
+			case countTrue(watchRead, watchWrite, watchRW) > 1:
+				output.ErrorWithInfo("watch", output.InvalidArgument("--read, --write and --rw are mutually exclusive")).PrintAndExit(getOutputFormat())
+			case watchRead:
+				wtype = api.WatchRead
+			case watchRW:
+				wtype = api.WatchRead | api.WatchWrite
+			}
+
+			c := mustGetClient("watch")
+			defer func() { _ = c.Close() }()
+
+			state, err := c.GetState()
+			if err != nil {
+				output.Error("watch", err).PrintAndExit(getOutputFormat())
+			}
+			goroutineID, ok := targetGoroutine(state, 0)
+			if !ok {
+				output.ErrorWithInfo("watch", output.NotFound("goroutine", "none selected")).PrintAndExit(getOutputFormat())
+			}
+			scope := api.EvalScope{GoroutineID: goroutineID, Frame: mustSelectedFrame(c, "watch", state, goroutineID, getOutputFormat)}
+
+			v, err := c.EvalInScope(scope, expr, debugger.DefaultLoadConfig())
+			if err != nil {
+				output.Error("watch", err).PrintAndExit(getOutputFormat())
+			}
+			created, err := c.CreateWatchpoint(scope, expr, wtype)
+			if err != nil {
+				output.ErrorWithInfo("watch", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("cannot watch '%s': %v", expr, err),
+					map[string]any{"expression": expr, "type": v.Type, "addr": fmt.Sprintf("%#x", v.Addr)},
+				)).PrintAndExit(getOutputFormat())
+			}
+
+			records := map[string]watchRecord{}
+			_ = session.LoadData(c.Addr(), watchpointsFile, &records)
+			records[strconv.Itoa(created.ID)] = watchRecord{
+				Expr:   expr,
+				Type:   v.Type,
+				Addr:   v.Addr,
+				Access: watchAccessName(wtype),
+				Last:   v.SinglelineString(),
+			}
+			_ = session.SaveData(c.Addr(), watchpointsFile, records)
+
+			data := map[string]any{
+				"id":         created.ID,
+				"expression": expr,
+				"access":     watchAccessName(wtype),
+				"addr":       fmt.Sprintf("%#x", v.Addr),
+				"type":       v.Type,
+				"value":      variableToMap(*v),
+			}
+			output.Success("watch", data, fmt.Sprintf("Watchpoint %d set on %s (%s)", created.ID, expr, watchAccessName(wtype))).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	watchCmd.Flags().BoolVar(&watchRead, "read", false, "Stop when the memory is read")
+	watchCmd.Flags().BoolVar(&watchWrite, "write", false, "Stop when the memory is written (default)")
+	watchCmd.Flags().BoolVar(&watchRW, "rw", false, "Stop when the memory is read or written")
+	root.AddCommand(watchCmd)
+}
+
+// countTrue counts the flags that are set
+func countTrue(flags ...bool) int {
+	n := 0
+	for _, f := range flags {
+		if f {
+			n++
+		}
+	}
+	return n
+}
+
+func init() {
+	addWatchCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TestWatchAccessName checks the names of the watch types
+func TestWatchAccessName(t *testing.T) {
+	for wtype, want := range map[api.WatchType]string{
+		api.WatchRead:                  "read",
+		api.WatchWrite:                 "write",
+		api.WatchRead | api.WatchWrite: "rw",
+	} {
+		if got := watchAccessName(wtype); got != want {
+			t.Errorf("watchAccessName(%d) = %s, want %s", wtype, got, want)
+		}
+	}
+}
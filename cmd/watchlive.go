@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/spf13/cobra"
+
+	"github.com/8gears/godebug-agentic/internal/debugger"
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// liveSample is one NDJSON record emitted by watch-live
+type liveSample struct {
+	Sample int `json:"sample"`
+	eventTimestamp
+	ElapsedMs int64  `json:"elapsedMs"`
+	Value     string `json:"value,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Breakpoint is set when the target stopped on its own before the interval ended
+	Breakpoint int `json:"breakpoint,omitempty"`
+}
+
+// sampleStats summarizes numeric samples
+type sampleStats struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+// liveStats returns min/max/mean of the samples whose value is a number, or
+// nil if none is
+func liveStats(samples []liveSample) *sampleStats {
+	var stats sampleStats
+	n := 0
+	for _, s := range samples {
+		x, err := strconv.ParseFloat(s.Value, 64)
+		if err != nil {
+			continue
+		}
+		if n == 0 || x < stats.Min {
+			stats.Min = x
+		}
+		if n == 0 || x > stats.Max {
+			stats.Max = x
+		}
+		stats.Mean += x
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	stats.Mean /= float64(n)
+	return &stats
+}
+
+// addWatchLiveCommand adds the watch-live command
+func addWatchLiveCommand(root *cobra.Command, mustGetClient func(string) *debugger.Client, getOutputFormat func() output.OutputFormat) {
+	var liveInterval, liveDuration time.Duration
+	var liveCount int
+	var liveGoroutine int64
+
+	watchLiveCmd := &cobra.Command{
+		Use:   "watch-live <expr>",
+		Short: "Sample an expression while the program runs",
+		Long: `Let the program run and sample an expression every --interval by halting
+it very briefly, emitting one NDJSON line per sample. The final line is the
+usual response with the sample count and, for numeric values, min/max/mean.
+
+Sampling stops after --count samples or --duration, whichever comes first,
+or when the program exits. The target is left stopped afterwards.
+
+The expression is evaluated on the thread that was halted, so package-level
+variables work best; use --goroutine to evaluate in a specific goroutine.
+Each halt pauses the program for the time of one evaluation.
+
+Example:
+  godebug --addr $ADDR watch-live "len(queue.items)" --interval 500ms --duration 30s
+  godebug --addr $ADDR watch-live "stats.inflight" --count 20`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			expr := args[0]
+			if liveInterval <= 0 {
+				output.ErrorWithInfo("watch-live", output.InvalidArgumentWithDetails(
+					fmt.Sprintf("invalid interval: %s (must be > 0)", liveInterval),
+					map[string]any{"interval": liveInterval.String()},
+				)).PrintAndExit(getOutputFormat())
+			}
+			if liveCount <= 0 && liveDuration <= 0 {
+				output.ErrorWithInfo("watch-live", output.InvalidArgument("--count or --duration must be > 0")).PrintAndExit(getOutputFormat())
+			}
+
+			c := mustGetClient("watch-live")
+			defer func() { _ = c.Close() }()
+
+			scope := api.EvalScope{GoroutineID: -1}
+			if liveGoroutine > 0 {
+				scope.GoroutineID = liveGoroutine
+			}
+			cfg := debugger.DefaultLoadConfig()
+
+			start := time.Now()
+			var samples []liveSample
+			var state *api.DebuggerState
+			for (liveCount <= 0 || len(samples) < liveCount) && (liveDuration <= 0 || time.Since(start) < liveDuration) {
+				var err error
+				state, _, err = continueUntil(c, time.After(liveInterval))
+				if err != nil {
+					output.Error("watch-live", err).PrintAndExit(getOutputFormat())
+				}
+				if state.Exited {
+					break
+				}
+
+				now := time.Now()
+				sample := liveSample{
+					Sample:         len(samples) + 1,
+					eventTimestamp: newEventTimestamp(c.Addr(), now),
+					ElapsedMs:      now.Sub(start).Milliseconds(),
+				}
+				if state.CurrentThread != nil && state.CurrentThread.Breakpoint != nil && state.CurrentThread.Breakpoint.ID > 0 {
+					sample.Breakpoint = state.CurrentThread.Breakpoint.ID
+				}
+				if v, err := c.EvalInScope(scope, expr, cfg); err != nil {
+					sample.Error = err.Error()
+				} else {
+					sample.Value, sample.Type = v.Value, v.Type
+					if len(v.Children) > 0 {
+						sample.Value = v.SinglelineString()
+					}
+				}
+				output.Emit(sample)
+				samples = append(samples, sample)
+			}
+
+			data := map[string]any{
+				"expression": expr,
+				"samples":    len(samples),
+				"elapsedMs":  time.Since(start).Milliseconds(),
+				"interval":   liveInterval.String(),
+			}
+			if len(samples) > 0 {
+				data["last"] = samples[len(samples)-1].Value
+			}
+			if stats := liveStats(samples); stats != nil {
+				data["stats"] = stats
+			}
+			if state != nil {
+				recordStop(c, "watch-live", state)
+				data["state"] = stateToData(state)
+			}
+
+			msg := fmt.Sprintf("%d samples of %s", len(samples), expr)
+			if state != nil && state.Exited {
+				msg += "; process exited"
+			}
+			output.Success("watch-live", data, msg).PrintAndExit(getOutputFormat())
+		},
+	}
+
+	watchLiveCmd.Flags().DurationVar(&liveInterval, "interval", 500*time.Millisecond, "Time the program runs between samples")
+	watchLiveCmd.Flags().DurationVar(&liveDuration, "duration", 10*time.Second, "Stop sampling after this long (0 = no limit)")
+	watchLiveCmd.Flags().IntVar(&liveCount, "count", 0, "Stop after this many samples (0 = no limit)")
+	watchLiveCmd.Flags().Int64Var(&liveGoroutine, "goroutine", 0, "Goroutine to evaluate in (default: the halted thread)")
+	root.AddCommand(watchLiveCmd)
+}
+
+func init() {
+	addWatchLiveCommand(rootCmd, MustGetClient, GetOutputFormat)
+}
@@ -0,0 +1,105 @@
+package debugger
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+// Arch describes the architecture the debugged process runs on, which for a
+// remote server need not be that of this host
+type Arch struct {
+	Name         string `json:"name"`
+	PtrSize      int    `json:"ptrSize"`
+	LittleEndian bool   `json:"littleEndian"`
+}
+
+// ByteOrder returns the byte order of the target's memory
+func (a Arch) ByteOrder() binary.ByteOrder {
+	if a.LittleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// Uint decodes an unsigned integer of 1, 2, 4 or 8 bytes read from the
+// target's memory
+func (a Arch) Uint(b []byte) (uint64, error) {
+	order := a.ByteOrder()
+	switch len(b) {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(order.Uint16(b)), nil
+	case 4:
+		return uint64(order.Uint32(b)), nil
+	case 8:
+		return order.Uint64(b), nil
+	}
+	return 0, fmt.Errorf("cannot decode a %d byte integer", len(b))
+}
+
+// archRegisters maps a register only one architecture has to its GOARCH
+// name and pointer size. Delve names registers after the architecture's
+// conventions, so they identify it.
+var archRegisters = []struct {
+	register string
+	arch     string
+	ptrSize  int
+}{
+	{"Rip", "amd64", 8},
+	{"Eip", "386", 4},
+	{"PSTATE", "arm64", 8},
+	{"X31", "riscv64", 8},
+	{"Nip", "ppc64le", 8},
+	{"ERA", "loong64", 8},
+}
+
+// ArchFromRegisters identifies the architecture from a register set. It
+// returns false for a set it does not recognize.
+func ArchFromRegisters(regs api.Registers) (Arch, bool) {
+	names := make(map[string]bool, len(regs))
+	for _, r := range regs {
+		names[r.Name] = true
+	}
+	for _, a := range archRegisters {
+		if names[a.register] {
+			// Every architecture Delve supports is little-endian; the
+			// server's answer for memory reads replaces this when known
+			return Arch{Name: a.arch, PtrSize: a.ptrSize, LittleEndian: true}, true
+		}
+	}
+	return Arch{}, false
+}
+
+// ListRegisters returns the registers of a thread (0 for the current one)
+func (c *Client) ListRegisters(threadID int, includeFp bool) (api.Registers, error) {
+	var out rpc2.ListRegistersOut
+	err := c.call("ListRegisters", rpc2.ListRegistersIn{ThreadID: threadID, IncludeFp: includeFp}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Regs, nil
+}
+
+// TargetArch determines the architecture of the debugged process from its
+// registers and the byte order the server reports for its memory. The
+// process must be stopped.
+func (c *Client) TargetArch() (Arch, error) {
+	regs, err := c.ListRegisters(0, false)
+	if err != nil {
+		return Arch{}, err
+	}
+	arch, ok := ArchFromRegisters(regs)
+	if !ok {
+		return Arch{}, fmt.Errorf("unrecognized register set (%d registers)", len(regs))
+	}
+	if state, err := c.GetState(); err == nil && state.CurrentThread != nil {
+		if mem, littleEndian, err := c.ExamineMemory(state.CurrentThread.PC, 1); err == nil && len(mem) > 0 {
+			arch.LittleEndian = littleEndian
+		}
+	}
+	return arch, nil
+}
@@ -0,0 +1,55 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// regs builds a register set from names
+func regs(names ...string) api.Registers {
+	r := make(api.Registers, len(names))
+	for i, n := range names {
+		r[i] = api.Register{Name: n}
+	}
+	return r
+}
+
+// TestArchFromRegisters checks that each architecture's register set is told
+// apart, in particular arm64 from riscv64, which share X1-X30 and PC.
+func TestArchFromRegisters(t *testing.T) {
+	tests := []struct {
+		regs api.Registers
+		want string
+	}{
+		{regs("Rip", "Rsp", "Rax"), "amd64"},
+		{regs("Eax", "Eip", "Esp"), "386"},
+		{regs("X0", "X1", "X30", "SP", "PC", "PSTATE"), "arm64"},
+		{regs("X1", "X2", "X30", "X31", "PC"), "riscv64"},
+		{regs("R0", "R1", "R31", "Nip", "MSr"), "ppc64le"},
+		{regs("R0", "R1", "R31", "ERA", "BADV"), "loong64"},
+	}
+	for _, tt := range tests {
+		arch, ok := ArchFromRegisters(tt.regs)
+		if !ok || arch.Name != tt.want {
+			t.Errorf("ArchFromRegisters(%v) = %+v, %v; want %s", tt.regs, arch, ok, tt.want)
+		}
+	}
+	if arch, ok := ArchFromRegisters(regs("A0", "A1")); ok {
+		t.Errorf("unknown register set identified as %s", arch.Name)
+	}
+}
+
+// TestArchUint checks decoding in both byte orders.
+func TestArchUint(t *testing.T) {
+	b := []byte{0x01, 0x02, 0x03, 0x04}
+	if got, _ := (Arch{LittleEndian: true}).Uint(b); got != 0x04030201 {
+		t.Errorf("little-endian: got %#x", got)
+	}
+	if got, _ := (Arch{}).Uint(b); got != 0x01020304 {
+		t.Errorf("big-endian: got %#x", got)
+	}
+	if _, err := (Arch{}).Uint(b[:3]); err == nil {
+		t.Error("3 byte integer decoded without an error")
+	}
+}
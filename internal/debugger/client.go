@@ -2,28 +2,35 @@ package debugger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/rpc"
 	"net/rpc/jsonrpc"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/rpc2"
 
+	"github.com/8gears/godebug-agentic/internal/debugger/faketarget"
 	"github.com/8gears/godebug-agentic/internal/output"
 )
 
 // Client wraps the Delve RPC2 client
 type Client struct {
-	addr    string
+	addr string
+	// mu guards client, which a retry replaces after redialling while a call
+	// abandoned on a timeout may still be running
+	mu      sync.Mutex
 	client  *rpc.Client
 	timeout time.Duration
+	retry   RetryPolicy
 }
 
 // Connect creates a new client connected to the Delve server
 func Connect(addr string) (*Client, error) {
-	client, err := jsonrpc.Dial("tcp", addr)
+	client, err := dial(addr)
 	if err != nil {
 		// Classify the connection error
 		if strings.Contains(err.Error(), "connection refused") {
@@ -36,7 +43,7 @@ func Connect(addr string) (*Client, error) {
 
 // ConnectWithTimeout creates a new client with a specific timeout
 func ConnectWithTimeout(addr string, timeout time.Duration) (*Client, error) {
-	client, err := jsonrpc.Dial("tcp", addr)
+	client, err := dial(addr)
 	if err != nil {
 		// Classify the connection error
 		if strings.Contains(err.Error(), "connection refused") {
@@ -47,6 +54,25 @@ func ConnectWithTimeout(addr string, timeout time.Duration) (*Client, error) {
 	return &Client{addr: addr, client: client, timeout: timeout}, nil
 }
 
+// dial opens an RPC connection to the Delve server at addr, or to an
+// in-process fake target for a fake: address
+func dial(addr string) (*rpc.Client, error) {
+	if faketarget.IsAddr(addr) {
+		conn, err := faketarget.Dial(addr)
+		if err != nil {
+			return nil, err
+		}
+		return jsonrpc.NewClient(conn), nil
+	}
+	return jsonrpc.Dial("tcp", addr)
+}
+
+// IsFakeAddr reports whether addr names an in-process fake target rather
+// than a Delve server
+func IsFakeAddr(addr string) bool {
+	return faketarget.IsAddr(addr)
+}
+
 // SetTimeout sets the operation timeout for subsequent calls
 func (c *Client) SetTimeout(timeout time.Duration) {
 	c.timeout = timeout
@@ -54,7 +80,14 @@ func (c *Client) SetTimeout(timeout time.Duration) {
 
 // Close closes the connection
 func (c *Client) Close() error {
-	return c.client.Close()
+	return c.conn().Close()
+}
+
+// conn returns the current RPC connection
+func (c *Client) conn() *rpc.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client
 }
 
 // Addr returns the server address
@@ -64,14 +97,18 @@ func (c *Client) Addr() string {
 
 // call is a helper for RPC calls (without timeout)
 func (c *Client) call(method string, args any, reply any) error {
-	return c.client.Call("RPCServer."+method, args, reply)
+	return c.withRetry(context.Background(), method, func() error {
+		return c.conn().Call("RPCServer."+method, args, reply)
+	})
 }
 
 // callWithTimeout wraps an RPC call with a timeout
 func (c *Client) callWithTimeout(ctx context.Context, method string, args, reply any) error {
 	done := make(chan error, 1)
 	go func() {
-		done <- c.client.Call("RPCServer."+method, args, reply)
+		done <- c.withRetry(ctx, method, func() error {
+			return c.conn().Call("RPCServer."+method, args, reply)
+		})
 	}()
 
 	select {
@@ -99,6 +136,26 @@ func (c *Client) GetState() (*api.DebuggerState, error) {
 	return state.State, nil
 }
 
+// ProcessPid returns the pid of the debugged process
+func (c *Client) ProcessPid() (int, error) {
+	var out rpc2.ProcessPidOut
+	err := c.call("ProcessPid", rpc2.ProcessPidIn{}, &out)
+	if err != nil {
+		return 0, err
+	}
+	return out.Pid, nil
+}
+
+// GetVersion returns the Delve version and the Go version the target was built with
+func (c *Client) GetVersion() (*api.GetVersionOut, error) {
+	var out api.GetVersionOut
+	err := c.call("GetVersion", api.GetVersionIn{}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 // Continue resumes execution until a breakpoint is hit
 func (c *Client) Continue() (*api.DebuggerState, error) {
 	var out rpc2.CommandOut
@@ -120,6 +177,17 @@ func (c *Client) ContinueWithContext(ctx context.Context) (*api.DebuggerState, e
 	return &out.State, nil
 }
 
+// ContinueLoadingReturns resumes execution like ContinueWithContext and loads
+// the return values of the functions whose return tracepoints were hit
+func (c *Client) ContinueLoadingReturns(ctx context.Context, cfg api.LoadConfig) (*api.DebuggerState, error) {
+	var out rpc2.CommandOut
+	err := c.callWithTimeout(ctx, "Command", &api.DebuggerCommand{Name: api.Continue, ReturnInfoLoadConfig: &cfg}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
+
 // Next steps over to the next source line
 func (c *Client) Next() (*api.DebuggerState, error) {
 	var out rpc2.CommandOut
@@ -150,6 +218,68 @@ func (c *Client) StepOut() (*api.DebuggerState, error) {
 	return &out.State, nil
 }
 
+// StepInstruction executes one CPU instruction; with skipCalls a CALL
+// instruction runs the called function to its return
+func (c *Client) StepInstruction(skipCalls bool) (*api.DebuggerState, error) {
+	name := api.StepInstruction
+	if skipCalls {
+		name = api.NextInstruction
+	}
+	var out rpc2.CommandOut
+	err := c.callWithDefaultTimeout("Command", &api.DebuggerCommand{Name: name}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
+
+// Rewind resumes execution backwards until a breakpoint or the start of the recording (recordings only)
+func (c *Client) Rewind() (*api.DebuggerState, error) {
+	var out rpc2.CommandOut
+	err := c.callWithDefaultTimeout("Command", &api.DebuggerCommand{Name: api.Rewind}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
+
+// ReverseNext steps backwards to the previous source line, stepping over calls (recordings only)
+func (c *Client) ReverseNext() (*api.DebuggerState, error) {
+	var out rpc2.CommandOut
+	err := c.callWithDefaultTimeout("Command", &api.DebuggerCommand{Name: api.ReverseNext}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
+
+// ReverseStep steps backwards to the previous source line, entering calls (recordings only)
+func (c *Client) ReverseStep() (*api.DebuggerState, error) {
+	var out rpc2.CommandOut
+	err := c.callWithDefaultTimeout("Command", &api.DebuggerCommand{Name: api.ReverseStep}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
+
+// ReverseStepOut steps backwards out of the current function to its call (recordings only)
+func (c *Client) ReverseStepOut() (*api.DebuggerState, error) {
+	var out rpc2.CommandOut
+	err := c.callWithDefaultTimeout("Command", &api.DebuggerCommand{Name: api.ReverseStepOut}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out.State, nil
+}
+
+// CancelNext drops the next, step or stepout a breakpoint interrupted, so
+// another one can start
+func (c *Client) CancelNext() error {
+	var out rpc2.CancelNextOut
+	return c.call("CancelNext", rpc2.CancelNextIn{}, &out)
+}
+
 // Halt stops execution
 func (c *Client) Halt() (*api.DebuggerState, error) {
 	var out rpc2.CommandOut
@@ -171,6 +301,42 @@ func (c *Client) Restart() (*api.DebuggerState, error) {
 	return c.GetState()
 }
 
+// RestartFromCheckpoint moves a recorded target back to a checkpoint
+func (c *Client) RestartFromCheckpoint(id int) (*api.DebuggerState, error) {
+	var out rpc2.RestartOut
+	err := c.call("Restart", rpc2.RestartIn{Position: fmt.Sprintf("c%d", id)}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetState()
+}
+
+// Rerun restarts the debugged process from the same binary, without
+// rebuilding it. Non-nil args replace the program arguments.
+func (c *Client) Rerun(args []string) (*api.DebuggerState, error) {
+	var out rpc2.RestartOut
+	err := c.call("Restart", rpc2.RestartIn{ResetArgs: args != nil, NewArgs: args}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetState()
+}
+
+// Rebuild rebuilds and restarts the debugged process. It returns the new state
+// together with the breakpoints Delve could not re-apply to the new binary.
+func (c *Client) Rebuild() (*api.DebuggerState, []api.DiscardedBreakpoint, error) {
+	var out rpc2.RestartOut
+	err := c.call("Restart", rpc2.RestartIn{Rebuild: true}, &out)
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := c.GetState()
+	if err != nil {
+		return nil, nil, err
+	}
+	return state, out.DiscardedBreakpoints, nil
+}
+
 // CreateBreakpoint creates a new breakpoint
 func (c *Client) CreateBreakpoint(bp *api.Breakpoint) (*api.Breakpoint, error) {
 	var out rpc2.CreateBreakpointOut
@@ -181,6 +347,28 @@ func (c *Client) CreateBreakpoint(bp *api.Breakpoint) (*api.Breakpoint, error) {
 	return &out.Breakpoint, nil
 }
 
+// CreateSuspendedBreakpoint creates a breakpoint that Delve keeps when its
+// location is not found, and enables once a plugin providing it is loaded
+func (c *Client) CreateSuspendedBreakpoint(bp *api.Breakpoint) (*api.Breakpoint, error) {
+	var out rpc2.CreateBreakpointOut
+	err := c.call("CreateBreakpoint", rpc2.CreateBreakpointIn{Breakpoint: *bp, Suspended: true}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out.Breakpoint, nil
+}
+
+// CreateWatchpoint sets a hardware watchpoint on the memory expr refers to in
+// the given scope
+func (c *Client) CreateWatchpoint(scope api.EvalScope, expr string, wtype api.WatchType) (*api.Breakpoint, error) {
+	var out rpc2.CreateWatchpointOut
+	err := c.call("CreateWatchpoint", rpc2.CreateWatchpointIn{Scope: scope, Expr: expr, Type: wtype}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Breakpoint, nil
+}
+
 // ClearBreakpoint removes a breakpoint by ID
 func (c *Client) ClearBreakpoint(id int) (*api.Breakpoint, error) {
 	var out rpc2.ClearBreakpointOut
@@ -195,6 +383,26 @@ func (c *Client) ClearBreakpoint(id int) (*api.Breakpoint, error) {
 	return out.Breakpoint, nil
 }
 
+// GetBreakpoint returns the breakpoint with the given ID
+func (c *Client) GetBreakpoint(id int) (*api.Breakpoint, error) {
+	var out rpc2.GetBreakpointOut
+	err := c.call("GetBreakpoint", rpc2.GetBreakpointIn{Id: id}, &out)
+	if err != nil {
+		if strings.Contains(err.Error(), "no breakpoint") || strings.Contains(err.Error(), "not found") {
+			return nil, output.NotFound("breakpoint", fmt.Sprintf("%d", id))
+		}
+		return nil, err
+	}
+	return &out.Breakpoint, nil
+}
+
+// AmendBreakpoint updates the condition, hit condition, tracing and other
+// settings of an existing breakpoint, keeping its ID and hit counts
+func (c *Client) AmendBreakpoint(bp *api.Breakpoint) error {
+	var out rpc2.AmendBreakpointOut
+	return c.call("AmendBreakpoint", rpc2.AmendBreakpointIn{Breakpoint: *bp}, &out)
+}
+
 // ListBreakpoints returns all breakpoints
 func (c *Client) ListBreakpoints() ([]*api.Breakpoint, error) {
 	var out rpc2.ListBreakpointsOut
@@ -205,16 +413,28 @@ func (c *Client) ListBreakpoints() ([]*api.Breakpoint, error) {
 	return out.Breakpoints, nil
 }
 
+// FindLocation resolves a location expression to the code locations it names
+func (c *Client) FindLocation(scope api.EvalScope, loc string) ([]api.Location, error) {
+	var out rpc2.FindLocationOut
+	err := c.call("FindLocation", rpc2.FindLocationIn{Scope: scope, Loc: loc}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Locations, nil
+}
+
 // ListLocalVars returns local variables in the current scope
 func (c *Client) ListLocalVars(goroutineID int64, frame int, cfg api.LoadConfig) ([]api.Variable, error) {
+	return c.ListLocalVarsInScope(api.EvalScope{GoroutineID: goroutineID, Frame: frame}, cfg)
+}
+
+// ListLocalVarsInScope returns local variables in the given scope, which may
+// select a deferred call of the frame
+func (c *Client) ListLocalVarsInScope(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error) {
 	var out rpc2.ListLocalVarsOut
 	err := c.call("ListLocalVars", rpc2.ListLocalVarsIn{
-		Scope: api.EvalScope{
-			GoroutineID:  goroutineID,
-			Frame:        frame,
-			DeferredCall: 0,
-		},
-		Cfg: cfg,
+		Scope: scope,
+		Cfg:   cfg,
 	}, &out)
 	if err != nil {
 		return nil, err
@@ -224,14 +444,15 @@ func (c *Client) ListLocalVars(goroutineID int64, frame int, cfg api.LoadConfig)
 
 // ListFunctionArgs returns function arguments
 func (c *Client) ListFunctionArgs(goroutineID int64, frame int, cfg api.LoadConfig) ([]api.Variable, error) {
+	return c.ListFunctionArgsInScope(api.EvalScope{GoroutineID: goroutineID, Frame: frame}, cfg)
+}
+
+// ListFunctionArgsInScope returns function arguments in the given scope
+func (c *Client) ListFunctionArgsInScope(scope api.EvalScope, cfg api.LoadConfig) ([]api.Variable, error) {
 	var out rpc2.ListFunctionArgsOut
 	err := c.call("ListFunctionArgs", rpc2.ListFunctionArgsIn{
-		Scope: api.EvalScope{
-			GoroutineID:  goroutineID,
-			Frame:        frame,
-			DeferredCall: 0,
-		},
-		Cfg: cfg,
+		Scope: scope,
+		Cfg:   cfg,
 	}, &out)
 	if err != nil {
 		return nil, err
@@ -239,17 +460,29 @@ func (c *Client) ListFunctionArgs(goroutineID int64, frame int, cfg api.LoadConf
 	return out.Args, nil
 }
 
+// ListPackageVars returns the package-level variables whose qualified name
+// matches the filter regexp
+func (c *Client) ListPackageVars(filter string, cfg api.LoadConfig) ([]api.Variable, error) {
+	var out rpc2.ListPackageVarsOut
+	err := c.call("ListPackageVars", rpc2.ListPackageVarsIn{Filter: filter, Cfg: cfg}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Variables, nil
+}
+
 // Eval evaluates an expression
 func (c *Client) Eval(goroutineID int64, frame int, expr string, cfg api.LoadConfig) (*api.Variable, error) {
+	return c.EvalInScope(api.EvalScope{GoroutineID: goroutineID, Frame: frame}, expr, cfg)
+}
+
+// EvalInScope evaluates an expression in the given scope
+func (c *Client) EvalInScope(scope api.EvalScope, expr string, cfg api.LoadConfig) (*api.Variable, error) {
 	var out rpc2.EvalOut
 	err := c.call("Eval", rpc2.EvalIn{
-		Scope: api.EvalScope{
-			GoroutineID:  goroutineID,
-			Frame:        frame,
-			DeferredCall: 0,
-		},
-		Expr: expr,
-		Cfg:  &cfg,
+		Scope: scope,
+		Expr:  expr,
+		Cfg:   &cfg,
 	}, &out)
 	if err != nil {
 		return nil, output.EvalFailed(expr, err)
@@ -257,15 +490,58 @@ func (c *Client) Eval(goroutineID int64, frame int, expr string, cfg api.LoadCon
 	return out.Variable, nil
 }
 
+// SetVariable assigns value to the variable symbol in the given scope. Delve
+// only assigns numbers, booleans, pointers and nil-able values.
+func (c *Client) SetVariable(scope api.EvalScope, symbol, value string) error {
+	var out rpc2.SetOut
+	return c.call("Set", rpc2.SetIn{
+		Scope:  scope,
+		Symbol: symbol,
+		Value:  value,
+	}, &out)
+}
+
+// CallFunction evaluates an expression that may call functions in the target,
+// running them on goroutineID. The results are in the returned state's
+// CurrentThread.ReturnValues.
+func (c *Client) CallFunction(goroutineID int64, expr string, cfg api.LoadConfig) (*api.DebuggerState, error) {
+	var out rpc2.CommandOut
+	err := c.callWithDefaultTimeout("Command", &api.DebuggerCommand{
+		Name:                 api.Call,
+		Expr:                 expr,
+		GoroutineID:          goroutineID,
+		ReturnInfoLoadConfig: &cfg,
+	}, &out)
+	if err != nil {
+		var info *output.ErrorInfo
+		if errors.As(err, &info) {
+			return nil, err
+		}
+		return nil, output.EvalFailed(expr, err)
+	}
+	return &out.State, nil
+}
+
 // Stacktrace returns the stack trace
 func (c *Client) Stacktrace(goroutineID int64, depth int, cfg *api.LoadConfig) ([]api.Stackframe, error) {
+	return c.stacktrace(rpc2.StacktraceIn{Id: goroutineID, Depth: depth, Cfg: cfg})
+}
+
+// StacktraceFull returns the stack trace with each frame's arguments and
+// locals, loaded with cfg
+func (c *Client) StacktraceFull(goroutineID int64, depth int, cfg *api.LoadConfig) ([]api.Stackframe, error) {
+	return c.stacktrace(rpc2.StacktraceIn{Id: goroutineID, Depth: depth, Full: true, Cfg: cfg})
+}
+
+// StacktraceDefers returns the stack trace with each frame's deferred calls
+func (c *Client) StacktraceDefers(goroutineID int64, depth int) ([]api.Stackframe, error) {
+	return c.stacktrace(rpc2.StacktraceIn{Id: goroutineID, Depth: depth, Defers: true})
+}
+
+// stacktrace calls the Stacktrace RPC
+func (c *Client) stacktrace(in rpc2.StacktraceIn) ([]api.Stackframe, error) {
 	var out rpc2.StacktraceOut
-	err := c.call("Stacktrace", rpc2.StacktraceIn{
-		Id:    goroutineID,
-		Depth: depth,
-		Cfg:   cfg,
-	}, &out)
-	if err != nil {
+	if err := c.call("Stacktrace", in, &out); err != nil {
 		return nil, err
 	}
 	return out.Locations, nil
@@ -273,10 +549,17 @@ func (c *Client) Stacktrace(goroutineID int64, depth int, cfg *api.LoadConfig) (
 
 // ListGoroutines returns all goroutines
 func (c *Client) ListGoroutines(start, count int) ([]*api.Goroutine, int, error) {
+	return c.ListGoroutinesFiltered(start, count, nil)
+}
+
+// ListGoroutinesFiltered is ListGoroutines for the goroutines matching all
+// of filters, applied by Delve
+func (c *Client) ListGoroutinesFiltered(start, count int, filters []api.ListGoroutinesFilter) ([]*api.Goroutine, int, error) {
 	var out rpc2.ListGoroutinesOut
 	err := c.call("ListGoroutines", rpc2.ListGoroutinesIn{
-		Start: start,
-		Count: count,
+		Start:   start,
+		Count:   count,
+		Filters: filters,
 	}, &out)
 	if err != nil {
 		return nil, 0, err
@@ -284,6 +567,16 @@ func (c *Client) ListGoroutines(start, count int) ([]*api.Goroutine, int, error)
 	return out.Goroutines, out.Nextg, nil
 }
 
+// ListThreads returns all OS threads of the target
+func (c *Client) ListThreads() ([]*api.Thread, error) {
+	var out rpc2.ListThreadsOut
+	err := c.call("ListThreads", rpc2.ListThreadsIn{}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Threads, nil
+}
+
 // SwitchGoroutine switches to a different goroutine
 func (c *Client) SwitchGoroutine(goroutineID int64) (*api.DebuggerState, error) {
 	var out rpc2.CommandOut
@@ -324,6 +617,69 @@ func (c *Client) ListSources(filter string) ([]string, error) {
 	return out.Sources, nil
 }
 
+// ListDynamicLibraries returns the shared objects and plugins loaded by the
+// target, excluding its executable
+func (c *Client) ListDynamicLibraries() ([]api.Image, error) {
+	var out rpc2.ListDynamicLibrariesOut
+	err := c.call("ListDynamicLibraries", rpc2.ListDynamicLibrariesIn{}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.List, nil
+}
+
+// ListPackagesBuildInfo returns the packages of the program whose import path
+// matches the filter regexp, optionally with their source files
+func (c *Client) ListPackagesBuildInfo(filter string, includeFiles bool) ([]api.PackageBuildInfo, error) {
+	var out rpc2.ListPackagesBuildInfoOut
+	err := c.call("ListPackagesBuildInfo", rpc2.ListPackagesBuildInfoIn{Filter: filter, IncludeFiles: includeFiles}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.List, nil
+}
+
+// Disassemble returns the instructions from startPC to endPC in Go assembler
+// syntax, or those of the function containing startPC if endPC is 0
+func (c *Client) Disassemble(scope api.EvalScope, startPC, endPC uint64) (api.AsmInstructions, error) {
+	var out rpc2.DisassembleOut
+	err := c.call("Disassemble", rpc2.DisassembleIn{Scope: scope, StartPC: startPC, EndPC: endPC, Flavour: api.GoFlavour}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Disassemble, nil
+}
+
+// ListTypes returns all types in the binary matching the filter regexp
+func (c *Client) ListTypes(filter string) ([]string, error) {
+	var out rpc2.ListTypesOut
+	err := c.call("ListTypes", rpc2.ListTypesIn{Filter: filter}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Types, nil
+}
+
+// ListFunctions returns all functions in the binary matching the filter regexp
+func (c *Client) ListFunctions(filter string) ([]string, error) {
+	var out rpc2.ListFunctionsOut
+	err := c.call("ListFunctions", rpc2.ListFunctionsIn{Filter: filter}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Funcs, nil
+}
+
+// FunctionReturnLocations returns the addresses at which a function returns
+func (c *Client) FunctionReturnLocations(fnName string) ([]uint64, error) {
+	var out rpc2.FunctionReturnLocationsOut
+	err := c.call("FunctionReturnLocations", rpc2.FunctionReturnLocationsIn{FnName: fnName}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Addrs, nil
+}
+
 // Detach detaches from the debugged process
 func (c *Client) Detach(kill bool) error {
 	var out rpc2.DetachOut
@@ -369,6 +725,44 @@ func (c *Client) ExamineMemory(address uint64, length int) ([]byte, bool, error)
 	return out.Mem, out.IsLittleEndian, nil
 }
 
+// ReadMemory reads n bytes at address, split into requests within the
+// server's per-call limit
+func (c *Client) ReadMemory(address uint64, n int64) ([]byte, error) {
+	buf := make([]byte, 0, n)
+	for int64(len(buf)) < n {
+		chunk := min(n-int64(len(buf)), rpc2.ExamineMemoryLengthLimit)
+		mem, _, err := c.ExamineMemory(address+uint64(len(buf)), int(chunk))
+		if err != nil {
+			return nil, err
+		}
+		if len(mem) == 0 {
+			return nil, fmt.Errorf("no memory readable at %#x", address+uint64(len(buf)))
+		}
+		buf = append(buf, mem...)
+	}
+	return buf, nil
+}
+
+// DumpStart starts writing a core dump of the target to dest
+func (c *Client) DumpStart(dest string) (api.DumpState, error) {
+	var out rpc2.DumpStartOut
+	err := c.call("DumpStart", rpc2.DumpStartIn{Destination: dest}, &out)
+	if err != nil {
+		return api.DumpState{}, err
+	}
+	return out.State, nil
+}
+
+// DumpWait waits up to waitMs milliseconds for a core dump in progress
+func (c *Client) DumpWait(waitMs int) (api.DumpState, error) {
+	var out rpc2.DumpWaitOut
+	err := c.call("DumpWait", rpc2.DumpWaitIn{Wait: waitMs}, &out)
+	if err != nil {
+		return api.DumpState{}, err
+	}
+	return out.State, nil
+}
+
 // DefaultLoadConfig returns a sensible default config for loading variables
 func DefaultLoadConfig() api.LoadConfig {
 	return api.LoadConfig{
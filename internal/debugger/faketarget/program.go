@@ -0,0 +1,242 @@
+package faketarget
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// Program is the canned run of a fake target: the places it stops, in
+// order. Continue moves to the next stop with a breakpoint, next and step
+// to the next stop, and the program exits after the last one.
+type Program struct {
+	Pid        int    `json:"pid,omitempty"`
+	ExitStatus int    `json:"exitStatus,omitempty"`
+	Stops      []Stop `json:"stops"`
+}
+
+// Stop is a point where the program can be stopped. The first goroutine is
+// the one that stopped.
+type Stop struct {
+	Goroutines []Goroutine `json:"goroutines"`
+}
+
+// Goroutine is a goroutine with its stack, innermost frame first
+type Goroutine struct {
+	ID     int64             `json:"id"`
+	Frames []Frame           `json:"frames"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Frame is a stack frame with the variables it holds
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Args     []Var  `json:"args,omitempty"`
+	Locals   []Var  `json:"locals,omitempty"`
+}
+
+// Var is a variable as print shows it. Kind is a reflect.Kind name and is
+// derived from Type, Value and Children when empty.
+type Var struct {
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type"`
+	Kind     string `json:"kind,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Children []Var  `json:"children,omitempty"`
+}
+
+// LoadProgram reads a Program from a JSON file
+func LoadProgram(path string) (*Program, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read fake program: %w", err)
+	}
+	var p Program
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid fake program %s: %w", path, err)
+	}
+	for i, s := range p.Stops {
+		if len(s.Goroutines) == 0 || len(s.Goroutines[0].Frames) == 0 {
+			return nil, fmt.Errorf("invalid fake program %s: stop %d has no goroutine with frames", path, i)
+		}
+	}
+	return &p, nil
+}
+
+// SampleProgram is the program of the fake: address. main.main sums the
+// squares of 1..3 through main.square, which returns n+n instead of n*n for
+// n == 3; goroutine 2 waits in main.report.
+func SampleProgram() *Program {
+	const file = "/fake/app/main.go"
+	main := func(line int, i, total string) Frame {
+		f := Frame{Function: "main.main", File: file, Line: line, Locals: []Var{
+			{Name: "nums", Type: "[]int", Children: []Var{
+				{Type: "int", Value: "1"}, {Type: "int", Value: "2"}, {Type: "int", Value: "3"},
+			}},
+			{Name: "total", Type: "int", Value: total},
+		}}
+		if i != "" {
+			f.Locals = append(f.Locals, Var{Name: "i", Type: "int", Value: i})
+		}
+		return f
+	}
+	worker := Goroutine{ID: 2, Frames: []Frame{{Function: "main.report", File: file, Line: 30, Args: []Var{
+		{Name: "ch", Type: "chan int", Value: "chan int 0/0"},
+	}}}, Labels: map[string]string{"role": "reporter"}}
+	stop := func(frames ...Frame) Stop {
+		return Stop{Goroutines: []Goroutine{{ID: 1, Frames: frames}, worker}}
+	}
+
+	p := &Program{Stops: []Stop{stop(main(9, "", "0"))}}
+	total := 0
+	for n := 1; n <= 3; n++ {
+		i, sum := strconv.Itoa(n-1), strconv.Itoa(total)
+		sq := n * n
+		if n == 3 {
+			sq = n + n
+		}
+		args := []Var{{Name: "n", Type: "int", Value: strconv.Itoa(n)}}
+		p.Stops = append(p.Stops,
+			stop(main(11, i, sum)),
+			stop(Frame{Function: "main.square", File: file, Line: 20, Args: args}, main(11, i, sum)),
+			stop(Frame{Function: "main.square", File: file, Line: 21, Args: args, Locals: []Var{
+				{Name: "r", Type: "int", Value: strconv.Itoa(sq)},
+			}}, main(11, i, sum)),
+		)
+		total += sq
+	}
+	p.Stops = append(p.Stops, stop(main(13, "", strconv.Itoa(total))))
+	return p
+}
+
+// kind is the reflect.Kind of v
+func (v Var) kind() reflect.Kind {
+	name := v.Kind
+	if name == "" {
+		switch {
+		case strings.HasPrefix(v.Type, "[]"):
+			name = "slice"
+		case strings.HasPrefix(v.Type, "["):
+			name = "array"
+		case strings.HasPrefix(v.Type, "map["):
+			name = "map"
+		case strings.HasPrefix(v.Type, "*"):
+			name = "ptr"
+		case strings.HasPrefix(v.Type, "chan "):
+			name = "chan"
+		case strings.HasPrefix(v.Type, "func"):
+			name = "func"
+		case len(v.Children) > 0:
+			name = "struct"
+		default:
+			name = v.Type
+		}
+	}
+	for k := reflect.Bool; k <= reflect.UnsafePointer; k++ {
+		if k.String() == name {
+			return k
+		}
+	}
+	if name == "error" || name == "any" || strings.HasPrefix(name, "interface") {
+		return reflect.Interface
+	}
+	return reflect.Struct
+}
+
+// variable converts v to the form Delve returns
+func (v Var) variable() api.Variable {
+	out := api.Variable{
+		Name:     v.Name,
+		Type:     v.Type,
+		RealType: v.Type,
+		Kind:     v.kind(),
+		Value:    v.Value,
+	}
+	switch out.Kind {
+	case reflect.String:
+		out.Len = int64(len(v.Value))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		out.Len = int64(len(v.Children))
+		out.Cap = out.Len
+	}
+	for i, c := range v.Children {
+		if c.Name == "" && out.Kind != reflect.Struct {
+			c.Name = "[" + strconv.Itoa(i) + "]"
+		}
+		out.Children = append(out.Children, c.variable())
+	}
+	return out
+}
+
+// variables converts vars to the form Delve returns
+func variables(vars []Var, flags api.VariableFlags) []api.Variable {
+	out := make([]api.Variable, 0, len(vars))
+	for _, v := range vars {
+		av := v.variable()
+		av.Flags |= flags
+		out = append(out, av)
+	}
+	return out
+}
+
+// lookup finds the variable an expression of dotted fields and [index]
+// elements names
+func lookup(vars []Var, expr string) (Var, bool) {
+	expr = strings.TrimSpace(expr)
+	name := expr
+	if i := strings.IndexAny(expr, ".["); i >= 0 {
+		name = expr[:i]
+	}
+	for _, v := range vars {
+		if v.Name == name {
+			return v.child(expr[len(name):])
+		}
+	}
+	return Var{}, false
+}
+
+// child resolves the .field and [index] selectors in rest below v
+func (v Var) child(rest string) (Var, bool) {
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			found := false
+			for _, c := range v.Children {
+				if c.Name == rest[:end] {
+					v, found = c, true
+					break
+				}
+			}
+			if !found {
+				return Var{}, false
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return Var{}, false
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil || i < 0 || i >= len(v.Children) {
+				return Var{}, false
+			}
+			v = v.Children[i]
+			rest = rest[end+1:]
+		default:
+			return Var{}, false
+		}
+	}
+	return v, true
+}
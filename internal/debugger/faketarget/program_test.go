@@ -0,0 +1,52 @@
+package faketarget
+
+import "testing"
+
+// TestLookup checks variables, fields and elements are found by expression.
+func TestLookup(t *testing.T) {
+	vars := []Var{
+		{Name: "n", Type: "int", Value: "3"},
+		{Name: "req", Type: "main.Request", Children: []Var{
+			{Name: "ID", Type: "string", Value: "a1"},
+			{Name: "Tags", Type: "[]string", Children: []Var{{Type: "string", Value: "x"}, {Type: "string", Value: "y"}}},
+		}},
+	}
+	tests := []struct {
+		expr, want string
+		ok         bool
+	}{
+		{"n", "3", true},
+		{"req.ID", "a1", true},
+		{"req.Tags[1]", "y", true},
+		{"req.Tags[2]", "", false},
+		{"req.Missing", "", false},
+		{"m", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := lookup(vars, tt.expr)
+		if ok != tt.ok || got.Value != tt.want {
+			t.Errorf("lookup(%q) = %q, %v; want %q, %v", tt.expr, got.Value, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestCondHolds checks the comparisons breakpoint conditions support.
+func TestCondHolds(t *testing.T) {
+	f := Frame{Args: []Var{{Name: "n", Type: "int", Value: "3"}}, Locals: []Var{{Name: "s", Type: "string", Value: "go"}}}
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{"n == 3", true},
+		{"n != 3", false},
+		{"n >= 10", false},
+		{"n < 10", true},
+		{`s == "go"`, true},
+		{"x == 1", false},
+	}
+	for _, tt := range tests {
+		if got := condHolds(tt.cond, f); got != tt.want {
+			t.Errorf("condHolds(%q) = %v, want %v", tt.cond, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,673 @@
+package faketarget
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+// RPCServer serves the RPC2 methods of a fake target. Errors use Delve's
+// wording so godebug classifies them as it does Delve's.
+type RPCServer struct {
+	t *target
+}
+
+// errUnsupported is returned for requests a fake target does not model
+func errUnsupported(what string) error {
+	return fmt.Errorf("%s is not supported by the fake target", what)
+}
+
+// current is the stop the program is at, or nil before the first stop and
+// after the exit
+func (t *target) current() *Stop {
+	if t.st.Exited || t.st.Position < 0 || t.st.Position >= len(t.prog.Stops) {
+		return nil
+	}
+	return &t.prog.Stops[t.st.Position]
+}
+
+// goroutine returns goroutine id at the current stop; an id of 0 or -1 is
+// the selected goroutine
+func (t *target) goroutine(id int64) (*Goroutine, error) {
+	stop := t.current()
+	if stop == nil {
+		if t.st.Exited {
+			return nil, fmt.Errorf("Process %d has exited with status %d", t.prog.Pid, t.prog.ExitStatus)
+		}
+		return nil, errors.New("no current goroutine")
+	}
+	if id <= 0 {
+		id = t.st.Selected
+	}
+	if id <= 0 {
+		return &stop.Goroutines[0], nil
+	}
+	for i := range stop.Goroutines {
+		if stop.Goroutines[i].ID == id {
+			return &stop.Goroutines[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown goroutine %d", id)
+}
+
+// frame returns frame n of the goroutine scope names
+func (t *target) frame(scope api.EvalScope) (*Frame, error) {
+	g, err := t.goroutine(scope.GoroutineID)
+	if err != nil {
+		return nil, err
+	}
+	if scope.Frame < 0 || scope.Frame >= len(g.Frames) {
+		return nil, fmt.Errorf("Invalid frame %d", scope.Frame)
+	}
+	if scope.DeferredCall > 0 {
+		return nil, errUnsupported("deferred call scope")
+	}
+	return &g.Frames[scope.Frame], nil
+}
+
+// files are the source files of the program, sorted
+func (t *target) files() []string {
+	var files []string
+	for _, s := range t.prog.Stops {
+		for _, g := range s.Goroutines {
+			for _, f := range g.Frames {
+				if !slices.Contains(files, f.File) {
+					files = append(files, f.File)
+				}
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// entry returns where function starts: its lowest line in the program
+func (t *target) entry(function string) (Frame, bool) {
+	var found Frame
+	for _, s := range t.prog.Stops {
+		for _, g := range s.Goroutines {
+			for _, f := range g.Frames {
+				if f.Function == function && (found.Function == "" || f.Line < found.Line) {
+					found = f
+				}
+			}
+		}
+	}
+	return found, found.Function != ""
+}
+
+// functions are the functions of the program, sorted
+func (t *target) functions() []string {
+	var functions []string
+	for _, s := range t.prog.Stops {
+		for _, g := range s.Goroutines {
+			for _, f := range g.Frames {
+				if !slices.Contains(functions, f.Function) {
+					functions = append(functions, f.Function)
+				}
+			}
+		}
+	}
+	sort.Strings(functions)
+	return functions
+}
+
+// pc is the made-up program counter of a line
+func (t *target) pc(file string, line int) uint64 {
+	return 0x400000 + uint64(slices.Index(t.files(), file)+1)<<16 + uint64(line)<<4
+}
+
+// location is the Delve location of a frame
+func (t *target) location(f Frame) api.Location {
+	entry, _ := t.entry(f.Function)
+	return api.Location{
+		PC:       t.pc(f.File, f.Line),
+		File:     f.File,
+		Line:     f.Line,
+		Function: &api.Function{Name_: f.Function, Value: t.pc(entry.File, entry.Line)},
+	}
+}
+
+// apiGoroutine is the Delve form of g; the goroutine that stopped runs on
+// thread 1
+func (t *target) apiGoroutine(g Goroutine, stopped bool) *api.Goroutine {
+	loc := t.location(g.Frames[0])
+	out := &api.Goroutine{
+		ID:             g.ID,
+		CurrentLoc:     loc,
+		UserCurrentLoc: loc,
+		StartLoc:       t.location(g.Frames[len(g.Frames)-1]),
+		Labels:         g.Labels,
+	}
+	if stopped {
+		out.ThreadID = 1
+	}
+	return out
+}
+
+// breakpoint returns the breakpoint with id
+func (t *target) breakpoint(id int) *api.Breakpoint {
+	for _, bp := range t.st.Breakpoints {
+		if bp.ID == id {
+			return bp
+		}
+	}
+	return nil
+}
+
+// state is the Delve state of the target
+func (t *target) state() *api.DebuggerState {
+	st := &api.DebuggerState{
+		Pid:        t.prog.Pid,
+		Exited:     t.st.Exited,
+		ExitStatus: t.prog.ExitStatus,
+	}
+	stop := t.current()
+	if stop == nil {
+		return st
+	}
+	g := stop.Goroutines[0]
+	loc := t.location(g.Frames[0])
+	thread := &api.Thread{
+		ID:          1,
+		PC:          loc.PC,
+		File:        loc.File,
+		Line:        loc.Line,
+		Function:    loc.Function,
+		GoroutineID: g.ID,
+		Breakpoint:  t.breakpoint(t.st.Hit),
+	}
+	st.CurrentThread = thread
+	st.Threads = []*api.Thread{thread}
+	if sel, err := t.goroutine(0); err == nil {
+		st.SelectedGoroutine = t.apiGoroutine(*sel, sel.ID == g.ID)
+	}
+	return st
+}
+
+// condOps are the comparisons breakpoint conditions may use, longest first
+var condOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseCond splits a condition of the form <variable> <op> <literal>
+func parseCond(cond string) (lhs, op, rhs string, err error) {
+	for _, op := range condOps {
+		if l, r, ok := strings.Cut(cond, op); ok {
+			return strings.TrimSpace(l), op, strings.TrimSpace(r), nil
+		}
+	}
+	return "", "", "", errUnsupported(fmt.Sprintf("condition %q (use <variable> <op> <literal>)", cond))
+}
+
+// condHolds evaluates a breakpoint condition in frame f
+func condHolds(cond string, f Frame) bool {
+	lhs, op, rhs, err := parseCond(cond)
+	if err != nil {
+		return false
+	}
+	v, ok := lookup(append(slices.Clone(f.Args), f.Locals...), lhs)
+	if !ok {
+		return false
+	}
+	cmp := strings.Compare(v.Value, strings.Trim(rhs, `"`))
+	a, errA := strconv.ParseFloat(v.Value, 64)
+	b, errB := strconv.ParseFloat(rhs, 64)
+	if errA == nil && errB == nil {
+		cmp = 0
+		if a < b {
+			cmp = -1
+		} else if a > b {
+			cmp = 1
+		}
+	}
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// breakpointAt returns the enabled breakpoint the program stops on at stop
+// p, if any
+func (t *target) breakpointAt(p int) *api.Breakpoint {
+	g := t.prog.Stops[p].Goroutines[0]
+	f := g.Frames[0]
+	for _, bp := range t.st.Breakpoints {
+		if bp.Disabled || bp.File != f.File || bp.Line != f.Line {
+			continue
+		}
+		if bp.Cond != "" && !condHolds(bp.Cond, f) {
+			continue
+		}
+		return bp
+	}
+	return nil
+}
+
+// moveTo makes stop p current, recording a breakpoint hit there; a p past
+// the last stop exits the program
+func (t *target) moveTo(p int) {
+	t.st.Selected, t.st.Hit = 0, 0
+	if p >= len(t.prog.Stops) {
+		t.st.Position, t.st.Exited = len(t.prog.Stops), true
+		return
+	}
+	t.st.Position = p
+	if bp := t.breakpointAt(p); bp != nil {
+		gid := strconv.FormatInt(t.prog.Stops[p].Goroutines[0].ID, 10)
+		if bp.HitCount == nil {
+			bp.HitCount = map[string]uint64{}
+		}
+		bp.HitCount[gid]++
+		bp.TotalHitCount++
+		t.st.Hit = bp.ID
+	}
+}
+
+// State returns the state of the target
+func (s *RPCServer) State(arg rpc2.StateIn, out *rpc2.StateOut) error {
+	out.State = s.t.state()
+	return nil
+}
+
+// Command runs an execution command: continue, next, step, stepOut, halt
+// and switchGoroutine are modelled
+func (s *RPCServer) Command(cmd api.DebuggerCommand, out *rpc2.CommandOut) error {
+	t := s.t
+	if t.st.Exited && cmd.Name != api.Halt {
+		return fmt.Errorf("Process %d has exited with status %d", t.prog.Pid, t.prog.ExitStatus)
+	}
+	switch cmd.Name {
+	case api.Continue:
+		p := t.st.Position + 1
+		for p < len(t.prog.Stops) && t.breakpointAt(p) == nil {
+			p++
+		}
+		t.moveTo(p)
+	case api.Next, api.Step:
+		t.moveTo(t.st.Position + 1)
+	case api.StepOut:
+		p := t.st.Position + 1
+		if stop := t.current(); stop != nil {
+			depth := len(stop.Goroutines[0].Frames)
+			for p < len(t.prog.Stops) && len(t.prog.Stops[p].Goroutines[0].Frames) >= depth {
+				p++
+			}
+		}
+		t.moveTo(p)
+	case api.Halt:
+	case api.SwitchGoroutine:
+		g, err := t.goroutine(cmd.GoroutineID)
+		if err != nil {
+			return err
+		}
+		t.st.Selected = g.ID
+	default:
+		return errUnsupported("command " + cmd.Name)
+	}
+	if err := t.save(); err != nil {
+		return err
+	}
+	out.State = *t.state()
+	return nil
+}
+
+// Restart starts the program over, keeping the breakpoints
+func (s *RPCServer) Restart(arg rpc2.RestartIn, out *rpc2.RestartOut) error {
+	t := s.t
+	t.st.Position, t.st.Exited, t.st.Selected, t.st.Hit = -1, false, 0, 0
+	for _, bp := range t.st.Breakpoints {
+		bp.HitCount, bp.TotalHitCount = nil, 0
+	}
+	return t.save()
+}
+
+// Detach ends the session; the next connection starts afresh
+func (s *RPCServer) Detach(arg rpc2.DetachIn, out *rpc2.DetachOut) error {
+	return s.t.reset()
+}
+
+// ProcessPid returns the pid of the program
+func (s *RPCServer) ProcessPid(arg rpc2.ProcessPidIn, out *rpc2.ProcessPidOut) error {
+	out.Pid = s.t.prog.Pid
+	return nil
+}
+
+// GetVersion returns the version of the fake target
+func (s *RPCServer) GetVersion(arg api.GetVersionIn, out *api.GetVersionOut) error {
+	out.DelveVersion = "fake"
+	out.APIVersion = 2
+	out.Backend = "fake"
+	out.TargetGoVersion = runtime.Version()
+	return nil
+}
+
+// CreateBreakpoint sets a breakpoint at a file:line of the program or the
+// entry of one of its functions
+func (s *RPCServer) CreateBreakpoint(arg rpc2.CreateBreakpointIn, out *rpc2.CreateBreakpointOut) error {
+	t := s.t
+	bp := arg.Breakpoint
+	if err := api.ValidBreakpointName(bp.Name); err != nil {
+		return err
+	}
+	if bp.HitCond != "" {
+		return errUnsupported("hit condition")
+	}
+	if bp.Cond != "" {
+		if _, _, _, err := parseCond(bp.Cond); err != nil {
+			return err
+		}
+	}
+	var notFound error
+	switch {
+	case bp.FunctionName != "":
+		entry, ok := t.entry(bp.FunctionName)
+		if !ok {
+			notFound = fmt.Errorf("could not find function %s\n", bp.FunctionName)
+		}
+		bp.File, bp.Line = entry.File, entry.Line
+	case !slices.Contains(t.files(), bp.File):
+		notFound = fmt.Errorf("could not find file %s", bp.File)
+	}
+	if notFound != nil {
+		if !arg.Suspended {
+			return notFound
+		}
+		// No plugin is ever loaded, so a suspended breakpoint stays pending
+		return t.addBreakpoint(&bp, out)
+	}
+	for _, other := range t.st.Breakpoints {
+		if other.File == bp.File && other.Line == bp.Line {
+			return fmt.Errorf("Breakpoint exists at %s:%d at %x", bp.File, bp.Line, other.Addr)
+		}
+	}
+	bp.Addr = t.pc(bp.File, bp.Line)
+	bp.Addrs = []uint64{bp.Addr}
+	if bp.FunctionName == "" {
+		for _, fn := range t.functions() {
+			if f, ok := t.entry(fn); ok && f.File == bp.File && f.Line == bp.Line {
+				bp.FunctionName = fn
+			}
+		}
+	}
+	return t.addBreakpoint(&bp, out)
+}
+
+// addBreakpoint records bp under the next ID
+func (t *target) addBreakpoint(bp *api.Breakpoint, out *rpc2.CreateBreakpointOut) error {
+	bp.ID = t.st.NextID
+	bp.HitCount, bp.TotalHitCount = map[string]uint64{}, 0
+	t.st.NextID++
+	t.st.Breakpoints = append(t.st.Breakpoints, bp)
+	if err := t.save(); err != nil {
+		return err
+	}
+	out.Breakpoint = *bp
+	return nil
+}
+
+// findBreakpoint returns the breakpoint named name, or with id
+func (t *target) findBreakpoint(id int, name string) (*api.Breakpoint, error) {
+	for _, bp := range t.st.Breakpoints {
+		if (name != "" && bp.Name == name) || (name == "" && bp.ID == id) {
+			return bp, nil
+		}
+	}
+	if name != "" {
+		return nil, fmt.Errorf("no breakpoint with name %s", name)
+	}
+	return nil, fmt.Errorf("no breakpoint with id %d", id)
+}
+
+// GetBreakpoint returns a breakpoint by ID or name
+func (s *RPCServer) GetBreakpoint(arg rpc2.GetBreakpointIn, out *rpc2.GetBreakpointOut) error {
+	bp, err := s.t.findBreakpoint(arg.Id, arg.Name)
+	if err != nil {
+		return err
+	}
+	out.Breakpoint = *bp
+	return nil
+}
+
+// ClearBreakpoint deletes a breakpoint by ID or name
+func (s *RPCServer) ClearBreakpoint(arg rpc2.ClearBreakpointIn, out *rpc2.ClearBreakpointOut) error {
+	t := s.t
+	bp, err := t.findBreakpoint(arg.Id, arg.Name)
+	if err != nil {
+		return err
+	}
+	t.st.Breakpoints = slices.DeleteFunc(t.st.Breakpoints, func(b *api.Breakpoint) bool { return b == bp })
+	if err := t.save(); err != nil {
+		return err
+	}
+	out.Breakpoint = bp
+	return nil
+}
+
+// AmendBreakpoint changes the settings of a breakpoint, keeping its
+// location and hit counts
+func (s *RPCServer) AmendBreakpoint(arg rpc2.AmendBreakpointIn, out *rpc2.AmendBreakpointOut) error {
+	t := s.t
+	bp, err := t.findBreakpoint(arg.Breakpoint.ID, "")
+	if err != nil {
+		return err
+	}
+	amended := arg.Breakpoint
+	if amended.HitCond != "" {
+		return errUnsupported("hit condition")
+	}
+	if amended.Cond != "" {
+		if _, _, _, err := parseCond(amended.Cond); err != nil {
+			return err
+		}
+	}
+	amended.File, amended.Line, amended.FunctionName = bp.File, bp.Line, bp.FunctionName
+	amended.Addr, amended.Addrs = bp.Addr, bp.Addrs
+	amended.HitCount, amended.TotalHitCount = bp.HitCount, bp.TotalHitCount
+	*bp = amended
+	return t.save()
+}
+
+// ListBreakpoints returns the breakpoints
+func (s *RPCServer) ListBreakpoints(arg rpc2.ListBreakpointsIn, out *rpc2.ListBreakpointsOut) error {
+	out.Breakpoints = s.t.st.Breakpoints
+	if out.Breakpoints == nil {
+		out.Breakpoints = []*api.Breakpoint{}
+	}
+	return nil
+}
+
+// ListSources returns the source files matching the filter regexp
+func (s *RPCServer) ListSources(arg rpc2.ListSourcesIn, out *rpc2.ListSourcesOut) error {
+	files, err := filter(s.t.files(), arg.Filter)
+	out.Sources = files
+	return err
+}
+
+// ListFunctions returns the functions matching the filter regexp
+func (s *RPCServer) ListFunctions(arg rpc2.ListFunctionsIn, out *rpc2.ListFunctionsOut) error {
+	functions, err := filter(s.t.functions(), arg.Filter)
+	out.Funcs = functions
+	return err
+}
+
+// ListDynamicLibraries returns no libraries: the fake program is linked
+// statically and opens no plugins
+func (s *RPCServer) ListDynamicLibraries(arg rpc2.ListDynamicLibrariesIn, out *rpc2.ListDynamicLibrariesOut) error {
+	out.List = []api.Image{}
+	return nil
+}
+
+// ListTypes returns the types of the program's variables matching the
+// filter regexp
+func (s *RPCServer) ListTypes(arg rpc2.ListTypesIn, out *rpc2.ListTypesOut) error {
+	var types []string
+	var add func(vars []Var)
+	add = func(vars []Var) {
+		for _, v := range vars {
+			if !slices.Contains(types, v.Type) {
+				types = append(types, v.Type)
+			}
+			add(v.Children)
+		}
+	}
+	for _, stop := range s.t.prog.Stops {
+		for _, g := range stop.Goroutines {
+			for _, f := range g.Frames {
+				add(f.Args)
+				add(f.Locals)
+			}
+		}
+	}
+	sort.Strings(types)
+	var err error
+	out.Types, err = filter(types, arg.Filter)
+	return err
+}
+
+// filter returns the names matching the regexp pattern
+func filter(names []string, pattern string) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	matched := []string{}
+	for _, name := range names {
+		if re.MatchString(name) {
+			matched = append(matched, name)
+		}
+	}
+	return matched, nil
+}
+
+// FindLocation resolves a function or file:line location
+func (s *RPCServer) FindLocation(arg rpc2.FindLocationIn, out *rpc2.FindLocationOut) error {
+	t := s.t
+	if entry, ok := t.entry(arg.Loc); ok {
+		out.Locations = []api.Location{t.location(entry)}
+		return nil
+	}
+	file, line, ok := strings.Cut(arg.Loc, ":")
+	n, err := strconv.Atoi(line)
+	if !ok || err != nil {
+		return fmt.Errorf("location %q not found", arg.Loc)
+	}
+	for _, f := range t.files() {
+		if f == file || strings.HasSuffix(f, "/"+file) {
+			out.Locations = []api.Location{{PC: t.pc(f, n), File: f, Line: n}}
+			return nil
+		}
+	}
+	return fmt.Errorf("could not find file %s", file)
+}
+
+// Stacktrace returns the stack of a goroutine
+func (s *RPCServer) Stacktrace(arg rpc2.StacktraceIn, out *rpc2.StacktraceOut) error {
+	t := s.t
+	g, err := t.goroutine(arg.Id)
+	if err != nil {
+		return err
+	}
+	out.Locations = []api.Stackframe{}
+	for i, f := range g.Frames {
+		if i > arg.Depth {
+			break
+		}
+		frame := api.Stackframe{Location: t.location(f), Bottom: i == len(g.Frames)-1}
+		if arg.Full {
+			frame.Arguments = variables(f.Args, api.VariableArgument)
+			frame.Locals = variables(f.Locals, 0)
+		}
+		out.Locations = append(out.Locations, frame)
+	}
+	return nil
+}
+
+// ListGoroutines returns the goroutines at the current stop
+func (s *RPCServer) ListGoroutines(arg rpc2.ListGoroutinesIn, out *rpc2.ListGoroutinesOut) error {
+	t := s.t
+	out.Goroutines = []*api.Goroutine{}
+	stop := t.current()
+	if stop == nil {
+		return nil
+	}
+	for i, g := range stop.Goroutines {
+		if i < arg.Start {
+			continue
+		}
+		if arg.Count > 0 && len(out.Goroutines) == arg.Count {
+			out.Nextg = i
+			return nil
+		}
+		out.Goroutines = append(out.Goroutines, t.apiGoroutine(g, i == 0))
+	}
+	out.Nextg = -1
+	return nil
+}
+
+// ListThreads returns the thread of the goroutine that stopped
+func (s *RPCServer) ListThreads(arg rpc2.ListThreadsIn, out *rpc2.ListThreadsOut) error {
+	out.Threads = s.t.state().Threads
+	if out.Threads == nil {
+		out.Threads = []*api.Thread{}
+	}
+	return nil
+}
+
+// ListLocalVars returns the locals of a frame
+func (s *RPCServer) ListLocalVars(arg rpc2.ListLocalVarsIn, out *rpc2.ListLocalVarsOut) error {
+	f, err := s.t.frame(arg.Scope)
+	if err != nil {
+		return err
+	}
+	out.Variables = variables(f.Locals, 0)
+	return nil
+}
+
+// ListFunctionArgs returns the arguments of a frame
+func (s *RPCServer) ListFunctionArgs(arg rpc2.ListFunctionArgsIn, out *rpc2.ListFunctionArgsOut) error {
+	f, err := s.t.frame(arg.Scope)
+	if err != nil {
+		return err
+	}
+	out.Args = variables(f.Args, api.VariableArgument)
+	return nil
+}
+
+// ListPackageVars returns no package variables: a fake program has none
+func (s *RPCServer) ListPackageVars(arg rpc2.ListPackageVarsIn, out *rpc2.ListPackageVarsOut) error {
+	out.Variables = []api.Variable{}
+	return nil
+}
+
+// Eval evaluates a variable, its fields (v.f) and elements (v[i]) in a frame
+func (s *RPCServer) Eval(arg rpc2.EvalIn, out *rpc2.EvalOut) error {
+	f, err := s.t.frame(arg.Scope)
+	if err != nil {
+		return err
+	}
+	v, ok := lookup(append(slices.Clone(f.Args), f.Locals...), arg.Expr)
+	if !ok {
+		return fmt.Errorf("could not find symbol value for %s", arg.Expr)
+	}
+	av := v.variable()
+	av.Name = arg.Expr
+	out.Variable = &av
+	return nil
+}
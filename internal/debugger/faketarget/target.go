@@ -0,0 +1,98 @@
+// Package faketarget is an in-process stand-in for a Delve server. It
+// serves enough of the RPC2 API (state, breakpoints, execution, stacks,
+// goroutines and variables) over a canned Program to run godebug commands
+// without a debugger or a target binary.
+//
+// A fake target is addressed as fake: for SampleProgram or fake:<path> for
+// the Program in a JSON file. Each connection serves one client; the
+// position in the program and the breakpoints are kept in the session
+// directory of the address, so successive invocations see one session.
+package faketarget
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+
+	"github.com/8gears/godebug-agentic/internal/session"
+)
+
+// Scheme prefixes the addresses of fake targets
+const Scheme = "fake:"
+
+// stateFile holds the session state of a fake target
+const stateFile = "fake-target.json"
+
+// IsAddr reports whether addr is the address of a fake target
+func IsAddr(addr string) bool {
+	return strings.HasPrefix(addr, Scheme)
+}
+
+// state is what a fake target remembers between connections
+type state struct {
+	// Position is the index of the current stop; -1 before the first
+	Position int  `json:"position"`
+	Exited   bool `json:"exited,omitempty"`
+	// Selected is the goroutine switched to at the current stop, 0 for the
+	// one that stopped
+	Selected int64 `json:"selected,omitempty"`
+	// Hit is the ID of the breakpoint that stopped the program, or 0
+	Hit         int               `json:"hit,omitempty"`
+	Breakpoints []*api.Breakpoint `json:"breakpoints"`
+	NextID      int               `json:"nextId"`
+}
+
+// target is a fake target serving one connection
+type target struct {
+	addr string
+	prog *Program
+	st   state
+}
+
+// open loads the program of addr and the session state it left
+func open(addr string) (*target, error) {
+	prog := SampleProgram()
+	if path := strings.TrimPrefix(addr, Scheme); path != "" {
+		var err error
+		if prog, err = LoadProgram(path); err != nil {
+			return nil, err
+		}
+	}
+	t := &target{addr: addr, prog: prog, st: state{Position: -1, NextID: 1}}
+	if err := session.LoadData(addr, stateFile, &t.st); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// save persists the session state for the next connection
+func (t *target) save() error {
+	return session.SaveData(t.addr, stateFile, &t.st)
+}
+
+// reset forgets the session state, as a new debugger process would
+func (t *target) reset() error {
+	t.st = state{Position: -1, NextID: 1}
+	return t.save()
+}
+
+// Dial starts a fake target for addr and returns the client end of an
+// in-process connection to it
+func Dial(addr string) (io.ReadWriteCloser, error) {
+	t, err := open(addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("RPCServer", &RPCServer{t: t}); err != nil {
+		return nil, fmt.Errorf("cannot serve fake target: %w", err)
+	}
+	client, server := net.Pipe()
+	go srv.ServeCodec(jsonrpc.NewServerCodec(server))
+	return client, nil
+}
@@ -1,11 +1,11 @@
 package debugger
 
 import (
-	"bufio"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
-	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,18 +16,46 @@ import (
 type LaunchMode string
 
 const (
-	ModeDebug LaunchMode = "debug" // dlv debug - compile and debug
-	ModeTest  LaunchMode = "test"  // dlv test - debug tests
-	ModeExec  LaunchMode = "exec"  // dlv exec - debug pre-compiled binary
+	ModeDebug  LaunchMode = "debug"  // dlv debug - compile and debug
+	ModeTest   LaunchMode = "test"   // dlv test - debug tests
+	ModeExec   LaunchMode = "exec"   // dlv exec - debug pre-compiled binary
+	ModeAttach LaunchMode = "attach" // dlv attach - debug a running process, Target is its PID
 )
 
+// BackendRR is the Delve backend that records the process with rr, allowing
+// reverse execution
+const BackendRR = "rr"
+
 // LaunchConfig holds configuration for launching Delve
 type LaunchConfig struct {
 	Mode       LaunchMode
 	Target     string        // Path to package/binary
 	Args       []string      // Arguments to pass to the program
 	BuildFlags string        // Additional build flags
-	Timeout    time.Duration // Timeout for startup (0 = use default 30s)
+	Timeout    time.Duration // Timeout for the server to become ready (0 = use default 30s)
+	Listen     string        // Address the server listens on ("" = DefaultListen)
+	Redirects  []string      // Delve redirect rules for the target's stdio (e.g. "stderr:/tmp/err.log")
+	Dir        string        // Working directory of dlv and the target ("" = current directory)
+	Env        []string      // Environment of dlv and the target (nil = inherit godebug's)
+	LogFile    string        // File Delve's own log is written to ("" = no log)
+	LogOutput  string        // Delve log components, e.g. "debugger,rpc" (used with LogFile)
+	Backend    string        // Delve backend: native, lldb or rr ("" = Delve's default)
+	Continue   bool          // Resume the target once the server is up instead of stopping it
+	// BuildCacheDir is the Go build cache (GOCACHE) used to build the target
+	// ("" = go's default)
+	BuildCacheDir string
+}
+
+// environ is the environment of dlv and the go commands building the target
+func (c LaunchConfig) environ() []string {
+	if c.BuildCacheDir == "" {
+		return c.Env
+	}
+	env := c.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	return append(slices.Clone(env), "GOCACHE="+c.BuildCacheDir)
 }
 
 // LaunchResult contains the result of launching Delve
@@ -36,6 +64,8 @@ type LaunchResult struct {
 	PID     int    `json:"pid"`
 	Target  string `json:"target"`
 	Mode    string `json:"mode"`
+	Backend string `json:"backend,omitempty"`
+	LogFile string `json:"logFile,omitempty"`
 	process *os.Process
 }
 
@@ -47,6 +77,27 @@ func Launch(config LaunchConfig) (*LaunchResult, error) {
 		return nil, output.NotFound("executable", "dlv (not found in PATH)")
 	}
 
+	// An attached process is already running: it has its arguments and stdio
+	if config.Mode == ModeAttach && (len(config.Args) > 0 || len(config.Redirects) > 0) {
+		return nil, output.InvalidArgument("attach mode takes no program arguments or redirects")
+	}
+	// rr records a process it starts, it cannot record one already running
+	if config.Mode == ModeAttach && config.Backend == BackendRR {
+		return nil, output.InvalidArgument("the rr backend cannot attach to a running process")
+	}
+
+	listen := config.Listen
+	if listen == "" {
+		listen = DefaultListen
+	}
+	if _, _, err := net.SplitHostPort(listen); err != nil {
+		return nil, output.InvalidArgument(fmt.Sprintf("invalid listen address %q: expected host:port", listen))
+	}
+	// Something answering on a fixed port would be mistaken for the new server
+	if fixedPort(listen) && Accepting(listen) {
+		return nil, output.InvalidArgument(fmt.Sprintf("listen address %s is already in use", listen))
+	}
+
 	// Build command arguments
 	args := []string{string(config.Mode)}
 
@@ -60,11 +111,32 @@ func Launch(config LaunchConfig) (*LaunchResult, error) {
 		"--headless",
 		"--api-version=2",
 		"--accept-multiclient",
-		"--listen=127.0.0.1:0", // Let OS pick a port
+		"--listen="+listen,
 	)
 
-	// Note: Delve already uses -gcflags="all=-N -l" by default when compiling
-	// so we don't need to specify build flags explicitly
+	if config.Backend != "" {
+		args = append(args, "--backend="+config.Backend)
+	}
+
+	if config.Continue {
+		args = append(args, "--continue")
+	}
+
+	// Keep Delve's own log: the address line is printed regardless of --log
+	if config.LogFile != "" {
+		args = append(args, "--log", "--log-output="+config.LogOutput, "--log-dest="+config.LogFile)
+	}
+
+	// Delve already compiles with -gcflags="all=-N -l"; these are extra flags
+	// such as -tags, only meaningful when Delve builds the target
+	if config.BuildFlags != "" && (config.Mode == ModeDebug || config.Mode == ModeTest) {
+		args = append(args, "--build-flags="+config.BuildFlags)
+	}
+
+	// Redirect the target's stdio to files
+	for _, r := range config.Redirects {
+		args = append(args, "-r", r)
+	}
 
 	// Add program arguments after --
 	if len(config.Args) > 0 {
@@ -74,6 +146,10 @@ func Launch(config LaunchConfig) (*LaunchResult, error) {
 
 	cmd := exec.Command(dlvPath, args...) //nolint:gosec // dlvPath is from exec.LookPath, args are controlled
 	cmd.Dir = "."                         // Use current directory
+	if config.Dir != "" {
+		cmd.Dir = config.Dir
+	}
+	cmd.Env = config.environ()
 
 	// Capture both stdout and stderr - dlv outputs to both
 	stdout, err := cmd.StdoutPipe()
@@ -90,60 +166,77 @@ func Launch(config LaunchConfig) (*LaunchResult, error) {
 		return nil, output.InternalError(fmt.Sprintf("failed to start dlv: %v", err))
 	}
 
-	// Parse the address from stdout/stderr
-	addrChan := make(chan string, 1)
-	errChan := make(chan error, 1)
-
-	addrRegex := regexp.MustCompile(`API server listening at: (.+)`)
-
-	// Scanner function for both pipes
-	scanPipe := func(scanner *bufio.Scanner) {
-		for scanner.Scan() {
-			line := scanner.Text()
-			if matches := addrRegex.FindStringSubmatch(line); len(matches) > 1 {
-				select {
-				case addrChan <- matches[1]:
-				default:
-				}
-				return
-			}
-			// Check for errors
-			if strings.Contains(line, "error") || strings.Contains(line, "Error") {
-				select {
-				case errChan <- output.InternalError(fmt.Sprintf("dlv error: %s", line)):
-				default:
-				}
-				return
-			}
-		}
-	}
-
-	go scanPipe(bufio.NewScanner(stdout))
-	go scanPipe(bufio.NewScanner(stderr))
-
 	// Use configured timeout or default to 30s
 	timeout := config.Timeout
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
+	deadline := time.Now().Add(timeout)
 
-	// Wait for address or timeout
-	select {
-	case addr := <-addrChan:
+	result := func(addr string) *LaunchResult {
 		return &LaunchResult{
 			Addr:    addr,
 			PID:     cmd.Process.Pid,
 			Target:  config.Target,
 			Mode:    string(config.Mode),
+			Backend: config.Backend,
+			LogFile: config.LogFile,
 			process: cmd.Process,
-		}, nil
-	case err := <-errChan:
-		_ = cmd.Process.Kill()
-		return nil, err
-	case <-time.After(timeout):
-		_ = cmd.Process.Kill()
-		return nil, output.Timeout("dlv start", timeout.Seconds())
+		}
+	}
+
+	// The server is ready once the address it announces accepts connections.
+	// With a fixed port it is dialed directly too, in case the announcement
+	// is not recognized.
+	watcher := watchOutput(stdout, stderr)
+	defer watcher.stop()
+	var poll <-chan time.Time
+	if fixedPort(listen) {
+		ticker := time.NewTicker(readyPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	expired := time.After(timeout)
+	var tail []string
+	for {
+		select {
+		case line := <-watcher.lines:
+			tail = append(tail, line)
+			if len(tail) > readyOutputLines {
+				tail = tail[1:]
+			}
+			if addr, ok := parseListenAddr(line); ok {
+				if !waitDialable(addr, deadline) {
+					_ = cmd.Process.Kill()
+					return nil, output.Timeout("dlv start", timeout.Seconds())
+				}
+				return result(addr), nil
+			}
+		case <-poll:
+			if Accepting(listen) {
+				return result(listen), nil
+			}
+		case <-watcher.eof:
+			_ = cmd.Process.Kill()
+			return nil, exitedEarly(tail)
+		case <-expired:
+			_ = cmd.Process.Kill()
+			return nil, output.Timeout("dlv start", timeout.Seconds())
+		}
+	}
+}
+
+// exitedEarly describes Delve exiting before its server was ready, e.g. when
+// the target does not build, with the last lines it printed
+func exitedEarly(tail []string) error {
+	msg := "dlv exited before the debug server was ready"
+	for i := len(tail) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(tail[i]); line != "" {
+			msg = fmt.Sprintf("dlv error: %s", line)
+			break
+		}
 	}
+	return output.NewErrorInfo(output.ErrCodeInternalError, msg).WithDetails(map[string]any{"output": tail})
 }
 
 // Kill terminates the Delve process
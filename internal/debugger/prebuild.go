@@ -0,0 +1,189 @@
+package debugger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Prebuild describes the binary a debug-mode target was launched from
+type Prebuild struct {
+	Binary string `json:"binary"`
+	// Reused is set when the binary of an earlier start was launched because
+	// nothing it was built from changed
+	Reused bool `json:"reused"`
+}
+
+// Files of a prebuild directory
+const (
+	prebuildBinary = "debug_bin"
+	prebuildStamp  = "fingerprint"
+)
+
+// listedPackage is the part of go list's output a fingerprint covers
+type listedPackage struct {
+	Dir        string
+	Standard   bool
+	GoFiles    []string
+	CgoFiles   []string
+	CFiles     []string
+	CXXFiles   []string
+	HFiles     []string
+	SFiles     []string
+	SysoFiles  []string
+	EmbedFiles []string
+	Module     *struct{ GoMod string }
+}
+
+// PrebuildTarget builds the package of a debug-mode config the way Delve
+// would, with optimizations off, into a binary kept under dir, and returns
+// the config launching that binary in exec mode. The binary of an earlier
+// call is reused when its sources, go.mod files, build flags and toolchain
+// are unchanged, so a restart skips compiling and linking.
+func PrebuildTarget(config LaunchConfig, dir string) (LaunchConfig, *Prebuild, error) {
+	if config.Mode != ModeDebug {
+		return config, nil, output.InvalidArgument("only debug mode targets can be prebuilt")
+	}
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		return config, nil, output.NotFound("executable", "go (not found in PATH)")
+	}
+
+	workDir := config.Dir
+	if workDir == "" {
+		if workDir, err = os.Getwd(); err != nil {
+			return config, nil, output.InternalError(fmt.Sprintf("cannot determine working directory: %v", err))
+		}
+	}
+	key := sha256.Sum256([]byte(workDir + "\x00" + config.Target + "\x00" + config.BuildFlags))
+	buildDir := filepath.Join(dir, hex.EncodeToString(key[:8]))
+	if err := os.MkdirAll(buildDir, 0o755); err != nil {
+		return config, nil, output.InternalError(fmt.Sprintf("cannot create build directory: %v", err))
+	}
+	result := &Prebuild{Binary: filepath.Join(buildDir, prebuildBinary)}
+
+	fingerprint, err := sourceFingerprint(goPath, config)
+	if err != nil {
+		return config, nil, err
+	}
+	stamp, _ := os.ReadFile(filepath.Join(buildDir, prebuildStamp))
+	if _, statErr := os.Stat(result.Binary); statErr == nil && string(stamp) == fingerprint {
+		result.Reused = true
+	} else if err := buildBinary(goPath, config, result.Binary); err != nil {
+		return config, nil, err
+	} else if err := os.WriteFile(filepath.Join(buildDir, prebuildStamp), []byte(fingerprint), 0o644); err != nil {
+		return config, nil, output.InternalError(fmt.Sprintf("cannot record build fingerprint: %v", err))
+	}
+
+	config.Mode = ModeExec
+	config.Target = result.Binary
+	config.BuildFlags = ""
+	return config, result, nil
+}
+
+// goCommand runs go with args where Delve would build the target
+func goCommand(goPath string, config LaunchConfig, args ...string) *exec.Cmd {
+	cmd := exec.Command(goPath, args...) //nolint:gosec // goPath is from exec.LookPath, args are controlled
+	cmd.Dir = config.Dir
+	cmd.Env = config.environ()
+	return cmd
+}
+
+// sourceFingerprint hashes what the binary of config is built from: the
+// toolchain and build settings, and the size and modification time of every
+// file of the non-standard packages it imports and of their go.mod and go.sum
+func sourceFingerprint(goPath string, config LaunchConfig) (string, error) {
+	h := sha256.New()
+	settings, err := goCommand(goPath, config, "env", "GOVERSION", "GOOS", "GOARCH", "GOFLAGS", "CGO_ENABLED", "GOWORK").Output()
+	if err != nil {
+		return "", goFailure("go env", err)
+	}
+	fmt.Fprintf(h, "%s\n%s\n", settings, config.BuildFlags)
+	stamp := func(path string) {
+		if fi, err := os.Stat(path); err == nil {
+			fmt.Fprintf(h, "%s %d %d\n", path, fi.Size(), fi.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(h, "%s missing\n", path)
+		}
+	}
+	// GOWORK, the last setting, is empty or off outside a workspace
+	if lines := strings.Split(string(settings), "\n"); len(lines) > 5 {
+		if work := strings.TrimSpace(lines[5]); work != "" && work != "off" {
+			stamp(work)
+		}
+	}
+
+	args := []string{"list", "-deps", "-json=Dir,Standard,GoFiles,CgoFiles,CFiles,CXXFiles,HFiles,SFiles,SysoFiles,EmbedFiles,Module"}
+	args = append(args, strings.Fields(config.BuildFlags)...)
+	listed, err := goCommand(goPath, config, append(args, config.Target)...).Output()
+	if err != nil {
+		return "", goFailure("go list", err)
+	}
+	mods := map[string]bool{}
+	dec := json.NewDecoder(bytes.NewReader(listed))
+	for {
+		var pkg listedPackage
+		if err := dec.Decode(&pkg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", output.InternalError(fmt.Sprintf("cannot read go list output: %v", err))
+		}
+		if pkg.Standard {
+			continue
+		}
+		for _, files := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.CXXFiles, pkg.HFiles, pkg.SFiles, pkg.SysoFiles, pkg.EmbedFiles} {
+			for _, f := range files {
+				stamp(filepath.Join(pkg.Dir, f))
+			}
+		}
+		if pkg.Module != nil && pkg.Module.GoMod != "" && !mods[pkg.Module.GoMod] {
+			mods[pkg.Module.GoMod] = true
+			stamp(pkg.Module.GoMod)
+			stamp(filepath.Join(filepath.Dir(pkg.Module.GoMod), "go.sum"))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// buildBinary builds the target of config with optimizations off into
+// binary. It is built next to binary and renamed, so a process still running
+// the previous build is not disturbed.
+func buildBinary(goPath string, config LaunchConfig, binary string) error {
+	tmp := binary + ".tmp"
+	args := append([]string{"build", "-gcflags=all=-N -l", "-o", tmp}, strings.Fields(config.BuildFlags)...)
+	if _, err := goCommand(goPath, config, append(args, config.Target)...).Output(); err != nil {
+		_ = os.Remove(tmp)
+		return goFailure("go build", err)
+	}
+	if err := os.Rename(tmp, binary); err != nil {
+		return output.InternalError(fmt.Sprintf("cannot install built binary: %v", err))
+	}
+	return nil
+}
+
+// goFailure describes a failed go command with the last line it printed
+func goFailure(what string, err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || len(exitErr.Stderr) == 0 {
+		return output.InternalError(fmt.Sprintf("%s failed: %v", what, err))
+	}
+	stderr := strings.TrimSpace(string(exitErr.Stderr))
+	return output.NewErrorInfo(output.ErrCodeInternalError, fmt.Sprintf("%s failed: %s", what, lastLine(stderr))).
+		WithDetails(map[string]any{"output": strings.Split(stderr, "\n")})
+}
+
+// lastLine is the last non-empty line of s
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	return lines[len(lines)-1]
+}
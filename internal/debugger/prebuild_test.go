@@ -0,0 +1,45 @@
+package debugger
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestPrebuildTarget checks that an unchanged package is not rebuilt and
+// that editing one of its files is.
+func TestPrebuildTarget(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not in PATH")
+	}
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module example.com/app\n\ngo 1.21\n")
+	write("main.go", "package main\n\nfunc main() {}\n")
+	config := LaunchConfig{Mode: ModeDebug, Target: ".", Dir: dir}
+	cache := t.TempDir()
+
+	launch, first, err := PrebuildTarget(config, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Reused || launch.Mode != ModeExec || launch.Target != first.Binary {
+		t.Fatalf("first build = %+v, launching %s %s", first, launch.Mode, launch.Target)
+	}
+	if _, second, err := PrebuildTarget(config, cache); err != nil || !second.Reused || second.Binary != first.Binary {
+		t.Fatalf("unchanged build = %+v, %v; want the first binary reused", second, err)
+	}
+
+	write("main.go", "package main\n\nfunc main() { println() }\n")
+	future := time.Now().Add(time.Minute)
+	_ = os.Chtimes(filepath.Join(dir, "main.go"), future, future)
+	if _, third, err := PrebuildTarget(config, cache); err != nil || third.Reused {
+		t.Fatalf("build after an edit = %+v, %v; want a rebuild", third, err)
+	}
+}
@@ -0,0 +1,116 @@
+package debugger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// DefaultListen lets the OS pick a free port for the Delve server
+const DefaultListen = "127.0.0.1:0"
+
+// readyOutputLines is how much of Delve's output a failed launch reports
+const readyOutputLines = 20
+
+// readyPollInterval is the pause between attempts to dial the server
+const readyPollInterval = 50 * time.Millisecond
+
+// listenRegex finds the address in the line Delve prints when its API server
+// listens ("API server listening at: 127.0.0.1:38697"). Past "API server" it
+// only relies on a word starting with "listen" followed by an address, so
+// changed wording still matches. The host must be an IP or a name, so a file
+// name such as listener.go:42 is not taken for one.
+var listenRegex = regexp.MustCompile(`(?i)\bAPI server\b.*?listen\w*\D*?((?:\[[0-9a-f:]+\]|[a-z0-9][a-z0-9.-]*):\d+)`)
+
+// listenFailure matches the errors Delve prints when it cannot listen, which
+// also name the address ("listen tcp 127.0.0.1:4000: bind: address already in use")
+var listenFailure = regexp.MustCompile(`(?i)listen tcp|bind:|error`)
+
+// parseListenAddr returns the server address announced in a line of output
+func parseListenAddr(line string) (string, bool) {
+	if listenFailure.MatchString(line) {
+		return "", false
+	}
+	m := listenRegex.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// fixedPort reports whether a listen address names a port rather than
+// letting the OS pick one
+func fixedPort(listen string) bool {
+	_, port, err := net.SplitHostPort(listen)
+	return err == nil && port != "" && port != "0"
+}
+
+// Accepting reports whether a server at addr accepts connections
+func Accepting(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// waitDialable polls addr until it accepts connections or deadline passes
+func waitDialable(addr string, deadline time.Time) bool {
+	for {
+		if Accepting(addr) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(readyPollInterval)
+	}
+}
+
+// outputWatcher reads Delve's stdout and stderr line by line. Lines are
+// delivered on lines until stop is called; after that the pipes are still
+// drained so Delve never blocks writing to them. eof is closed once both
+// pipes are closed, i.e. Delve exited.
+type outputWatcher struct {
+	lines chan string
+	eof   chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// watchOutput starts reading the pipes
+func watchOutput(pipes ...io.Reader) *outputWatcher {
+	w := &outputWatcher{
+		lines: make(chan string),
+		eof:   make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	var wg sync.WaitGroup
+	for _, pipe := range pipes {
+		wg.Add(1)
+		go func(r io.Reader) {
+			defer wg.Done()
+			scanner := bufio.NewScanner(r)
+			for scanner.Scan() {
+				select {
+				case w.lines <- scanner.Text():
+				case <-w.done:
+				}
+			}
+		}(pipe)
+	}
+	go func() {
+		wg.Wait()
+		close(w.eof)
+	}()
+	return w
+}
+
+// stop ends delivering lines
+func (w *outputWatcher) stop() {
+	w.once.Do(func() { close(w.done) })
+}
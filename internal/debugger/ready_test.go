@@ -0,0 +1,26 @@
+package debugger
+
+import "testing"
+
+// TestParseListenAddr checks the address is found in Delve's announcement
+// and variations of its wording.
+func TestParseListenAddr(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"API server listening at: 127.0.0.1:38697", "127.0.0.1:38697"},
+		{"API server listening at: [::1]:2345", "[::1]:2345"},
+		{"API server now listening on localhost:4040", "localhost:4040"},
+		{"could not launch process: exit status 1", ""},
+		{"main.go:12:2: undefined: foo", ""},
+		{"./internal/server/listener.go:42:3: undefined: foo", ""},
+		{"listen tcp 127.0.0.1:4000: bind: address already in use", ""},
+		{"API server listening at: 127.0.0.1:4000: bind: address already in use", ""},
+	}
+	for _, tt := range tests {
+		got, _ := parseListenAddr(tt.line)
+		if got != tt.want {
+			t.Errorf("parseListenAddr(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
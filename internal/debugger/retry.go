@@ -0,0 +1,166 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// Classes of transient errors a RetryPolicy can retry
+const (
+	// RetryConnection is a connection refused, reset or broken while Delve
+	// restarts or the network hiccups
+	RetryConnection = "connection"
+	// RetryEOF is the connection closing in the middle of an RPC
+	RetryEOF = "eof"
+	// RetryBusy is Delve refusing a request while an operation that ends on
+	// its own, such as a core dump, is in progress
+	RetryBusy = "busy"
+)
+
+// RetryClasses are the error classes --retry-on accepts
+var RetryClasses = []string{RetryConnection, RetryEOF, RetryBusy}
+
+// RetryPolicy retries RPCs failing with one of Classes up to Attempts more
+// times, waiting Backoff before the first retry and doubling it after each
+type RetryPolicy struct {
+	Classes  []string
+	Attempts int
+	Backoff  time.Duration
+}
+
+// ParseRetryClasses parses a comma-separated list of retry classes; "all"
+// stands for every class
+func ParseRetryClasses(s string) ([]string, error) {
+	var classes []string
+	for _, class := range strings.Split(s, ",") {
+		class = strings.TrimSpace(class)
+		switch {
+		case class == "":
+		case class == "all":
+			return RetryClasses, nil
+		case slices.Contains(RetryClasses, class):
+			if !slices.Contains(classes, class) {
+				classes = append(classes, class)
+			}
+		default:
+			return nil, fmt.Errorf("unknown retry class %q (want %s or all)", class, strings.Join(RetryClasses, ", "))
+		}
+	}
+	return classes, nil
+}
+
+// retries reports whether the policy retries errors of class
+func (p RetryPolicy) retries(class string) bool {
+	return class != "" && p.Attempts > 0 && slices.Contains(p.Classes, class)
+}
+
+// delay is the wait before retry n (0-based)
+func (p RetryPolicy) delay(n int) time.Duration {
+	return p.Backoff << min(n, 10)
+}
+
+// retryClass returns the class of a transient error, or "" for errors that
+// are not retried
+func retryClass(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, rpc.ErrShutdown) {
+		return RetryEOF
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "connection refused"),
+		strings.Contains(msg, "connection reset"),
+		strings.Contains(msg, "broken pipe"):
+		return RetryConnection
+	case strings.Contains(msg, "unexpected eof"):
+		return RetryEOF
+	case strings.Contains(msg, "core dump in progress"),
+		strings.Contains(msg, "resource temporarily unavailable"):
+		return RetryBusy
+	}
+	return ""
+}
+
+// readOnlyMethods are the RPCs that can be resent after the connection broke
+// mid-call: whether the server ran the first one does not matter
+var readOnlyMethods = []string{
+	"State", "ProcessPid", "GetVersion", "GetBreakpoint", "ListBreakpoints",
+	"ListCheckpoints", "ListFunctionArgs", "ListFunctions", "ListGoroutines",
+	"ListLocalVars", "ListPackageVars", "ListPackagesBuildInfo", "ListRegisters",
+	"ListSources", "ListThreads", "ListTypes", "ListDynamicLibraries", "Stacktrace",
+	"FindLocation", "FunctionReturnLocations", "Disassemble", "ExamineMemory", "DumpWait",
+}
+
+// resendable reports whether an RPC that failed with an error of class can
+// be sent again. Busy errors are refusals, so nothing ran; a broken
+// connection may have lost a request the server already acted on, such as a
+// continue, so only read-only methods are resent then.
+func resendable(method, class string) bool {
+	return class == RetryBusy || slices.Contains(readOnlyMethods, method)
+}
+
+// SetRetryPolicy sets the retry policy of subsequent calls
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+// ConnectWithRetry connects like Connect, retrying a refused or reset dial
+// when the policy retries connection errors, and applies the policy to the
+// client's calls
+func ConnectWithRetry(addr string, p RetryPolicy) (*Client, error) {
+	c, err := Connect(addr)
+	for n := 0; err != nil && n < p.Attempts && p.retries(retryClass(err)); n++ {
+		output.RecordRetry(retryClass(err))
+		time.Sleep(p.delay(n))
+		c, err = Connect(addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.SetRetryPolicy(p)
+	return c, nil
+}
+
+// withRetry runs send, an RPC of method, retrying it as the client's policy
+// allows until ctx is done. A broken connection is redialled before the
+// retry.
+func (c *Client) withRetry(ctx context.Context, method string, send func() error) error {
+	err := send()
+	for n := 0; err != nil && n < c.retry.Attempts; n++ {
+		class := retryClass(err)
+		if !c.retry.retries(class) || !resendable(method, class) {
+			return err
+		}
+		timer := time.NewTimer(c.retry.delay(n))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		output.RecordRetry(class)
+		if class != RetryBusy {
+			conn, dialErr := dial(c.addr)
+			if dialErr != nil {
+				err = dialErr
+				continue
+			}
+			c.mu.Lock()
+			_ = c.client.Close()
+			c.client = conn
+			c.mu.Unlock()
+		}
+		err = send()
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/rpc"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// TestRetryClass checks transient errors are classified and others are not.
+func TestRetryClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{io.EOF, RetryEOF},
+		{fmt.Errorf("reading body: %w", io.ErrUnexpectedEOF), RetryEOF},
+		{rpc.ErrShutdown, RetryEOF},
+		{errors.New("read tcp 127.0.0.1:51234->127.0.0.1:38697: read: connection reset by peer"), RetryConnection},
+		{errors.New("write tcp 127.0.0.1:51234->127.0.0.1:38697: write: broken pipe"), RetryConnection},
+		{errors.New("connection refused by Delve server at 127.0.0.1:38697"), RetryConnection},
+		{errors.New("core dump in progress"), RetryBusy},
+		{errors.New("could not find symbol value for x"), ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := retryClass(tt.err); got != tt.want {
+			t.Errorf("retryClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestParseRetryClasses checks the --retry-on list is validated.
+func TestParseRetryClasses(t *testing.T) {
+	got, err := ParseRetryClasses("eof, busy,eof")
+	if err != nil || !slices.Equal(got, []string{RetryEOF, RetryBusy}) {
+		t.Errorf("ParseRetryClasses = %v, %v", got, err)
+	}
+	if got, _ := ParseRetryClasses("all"); !slices.Equal(got, RetryClasses) {
+		t.Errorf("ParseRetryClasses(all) = %v", got)
+	}
+	if _, err := ParseRetryClasses("timeout"); err == nil {
+		t.Error("ParseRetryClasses(timeout) succeeded")
+	}
+}
+
+// TestWithRetry checks retries stop at the policy's limit and that a
+// mid-call connection loss is not resent for methods that change state.
+func TestWithRetry(t *testing.T) {
+	c := &Client{retry: RetryPolicy{Classes: RetryClasses, Attempts: 2}}
+
+	calls := 0
+	err := c.withRetry(context.Background(), "DumpWait", func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("core dump in progress")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("busy then success: err %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = c.withRetry(context.Background(), "DumpWait", func() error {
+		calls++
+		return errors.New("core dump in progress")
+	})
+	if err == nil || calls != 3 {
+		t.Errorf("always busy: err %v after %d calls, want 3", err, calls)
+	}
+
+	calls = 0
+	err = c.withRetry(context.Background(), "Command", func() error {
+		calls++
+		return io.ErrUnexpectedEOF
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || calls != 1 {
+		t.Errorf("Command after EOF: err %v after %d calls, want no retry", err, calls)
+	}
+}
+
+// TestRetryAfterTimeout runs with -race: a call abandoned on its timeout
+// keeps retrying, redialling and counting retries while the client makes
+// other calls and the response is printed. The retries stop once the
+// call's context is done.
+func TestRetryAfterTimeout(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	c, err := Connect("fake:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+	c.SetRetryPolicy(RetryPolicy{Classes: RetryClasses, Attempts: 1000, Backoff: time.Millisecond})
+
+	stdout := os.Stdout
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = devNull
+	defer func() { os.Stdout = stdout; _ = devNull.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.withRetry(ctx, "State", func() error {
+			_ = c.conn()
+			return io.EOF
+		})
+	}()
+
+	for ctx.Err() == nil {
+		_, _ = c.GetState()
+		output.Success("status", nil, "").Print(output.FormatJSON)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("abandoned call = %v, want its last error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retries went on after the call's context was done")
+	}
+}
@@ -27,8 +27,68 @@ const (
 
 	// ErrCodeInternalError indicates an unexpected internal error
 	ErrCodeInternalError = "INTERNAL_ERROR"
+
+	// ErrCodeStepWhileRunning indicates a step was requested while the target is running
+	ErrCodeStepWhileRunning = "STEP_WHILE_RUNNING"
+
+	// ErrCodeStepAfterExit indicates a step was requested after the target exited
+	ErrCodeStepAfterExit = "STEP_AFTER_EXIT"
+
+	// ErrCodeContinueWhileRunning indicates continue was requested while the target is already running
+	ErrCodeContinueWhileRunning = "CONTINUE_WHILE_RUNNING"
+
+	// ErrCodeContinueAfterExit indicates continue was requested after the target exited
+	ErrCodeContinueAfterExit = "CONTINUE_AFTER_EXIT"
+
+	// ErrCodeInspectWhileRunning indicates state was inspected while the target is running
+	ErrCodeInspectWhileRunning = "INSPECT_WHILE_RUNNING"
+
+	// ErrCodeInspectAfterExit indicates state was inspected after the target exited
+	ErrCodeInspectAfterExit = "INSPECT_AFTER_EXIT"
+
+	// ErrCodeInspectBeforeStart indicates variables were inspected before any goroutine was stopped in user code
+	ErrCodeInspectBeforeStart = "INSPECT_BEFORE_START"
+
+	// ErrCodeStaleContext indicates the frame selected with frame, up or down
+	// belongs to a stop the target has since left
+	ErrCodeStaleContext = "STALE_CONTEXT"
+
+	// ErrCodeOperationInProgress indicates Delve refused a command because
+	// another one (an interrupted next, a function call, a core dump) is still pending
+	ErrCodeOperationInProgress = "OPERATION_IN_PROGRESS"
+
+	// ErrCodeScenarioFailed indicates a scenario step did not meet its expectations
+	ErrCodeScenarioFailed = "SCENARIO_FAILED"
 )
 
+// ErrorCode describes an error code for generated documentation
+type ErrorCode struct {
+	Code        string
+	Description string
+}
+
+// ErrorCodes lists every error code with a one-line description
+var ErrorCodes = []ErrorCode{
+	{ErrCodeConnectionFailed, "Cannot reach the Delve server"},
+	{ErrCodeConnectionRefused, "The server actively refused the connection"},
+	{ErrCodeTimeout, "The operation exceeded its time limit"},
+	{ErrCodeInvalidArgument, "Bad arguments or flags"},
+	{ErrCodeNotFound, "The breakpoint, goroutine, frame or other resource does not exist"},
+	{ErrCodeProcessExited, "The target program terminated"},
+	{ErrCodeEvalFailed, "Expression evaluation failed"},
+	{ErrCodeInternalError, "Unexpected internal error"},
+	{ErrCodeStepWhileRunning, "next/step/stepout while the target is running"},
+	{ErrCodeStepAfterExit, "next/step/stepout after the target exited"},
+	{ErrCodeContinueWhileRunning, "continue while the target is already running"},
+	{ErrCodeContinueAfterExit, "continue after the target exited"},
+	{ErrCodeInspectWhileRunning, "Inspection while the target is running; halt it first"},
+	{ErrCodeInspectAfterExit, "Inspection after the target exited"},
+	{ErrCodeInspectBeforeStart, "Variables inspected before the target stopped in any goroutine"},
+	{ErrCodeStaleContext, "The selected frame belongs to a stop the target has since left"},
+	{ErrCodeOperationInProgress, "Another operation is still pending in Delve; details.operation names it"},
+	{ErrCodeScenarioFailed, "A scenario step did not meet its expectations"},
+}
+
 // ErrorInfo provides structured error information for AI consumption
 type ErrorInfo struct {
 	Code    string `json:"code"`              // Machine-readable error code
@@ -135,6 +195,42 @@ func EvalFailed(expr string, err error) *ErrorInfo {
 	}
 }
 
+// InvalidState creates an error for a command that is not valid in the session's
+// current state, with the commands that would move it to a state where it is
+func InvalidState(code, message, state string, suggestions []string) *ErrorInfo {
+	return &ErrorInfo{
+		Code:    code,
+		Message: message,
+		Details: map[string]any{
+			"state":       state,
+			"suggestions": suggestions,
+		},
+	}
+}
+
+// Operations OperationInProgress reports as pending
+const (
+	// PendingStep is a next, step or stepout a breakpoint interrupted;
+	// continue finishes it and CancelNext drops it
+	PendingStep     = "step"
+	PendingCall     = "call"
+	PendingCoreDump = "core dump"
+)
+
+// OperationInProgress creates an error for a command Delve refused because
+// operation is still pending, with the commands that resolve it
+func OperationInProgress(operation, message string, suggestions []string) *ErrorInfo {
+	details := map[string]any{"operation": operation}
+	if len(suggestions) > 0 {
+		details["suggestions"] = suggestions
+	}
+	return &ErrorInfo{
+		Code:    ErrCodeOperationInProgress,
+		Message: message,
+		Details: details,
+	}
+}
+
 // InternalError creates an error for unexpected internal errors
 func InternalError(message string) *ErrorInfo {
 	return &ErrorInfo{
@@ -159,6 +255,14 @@ func FromError(err error) *ErrorInfo {
 
 	// Try to classify common error patterns
 	switch {
+	case contains(msg, "next while nexting"):
+		return OperationInProgress(PendingStep,
+			"a next, step or stepout interrupted by a breakpoint is still pending: "+msg,
+			[]string{"continue", "next --cancel-pending", "step --cancel-pending", "stepout --cancel-pending"})
+	case contains(msg, "another function call is already in progress"):
+		return OperationInProgress(PendingCall, msg, []string{"continue"})
+	case contains(msg, "core dump in progress"):
+		return OperationInProgress(PendingCoreDump, msg, nil)
 	case contains(msg, "connection refused"):
 		return NewErrorInfo(ErrCodeConnectionRefused, msg)
 	case contains(msg, "timeout") || contains(msg, "timed out"):
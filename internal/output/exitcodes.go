@@ -18,6 +18,9 @@ const (
 	// ExitNotFound indicates a requested resource was not found (breakpoint, goroutine, etc.)
 	ExitNotFound = 4
 
+	// ExitInvalidState indicates the command is not valid in the session's current state
+	ExitInvalidState = 5
+
 	// ExitTimeout indicates the operation timed out (matches GNU timeout convention)
 	ExitTimeout = 124
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 )
 
 // ExitFunc can be replaced in tests to prevent os.Exit from killing the test process
@@ -11,11 +12,13 @@ var ExitFunc = os.Exit
 
 // Response is the standard JSON response envelope for all commands
 type Response struct {
-	Success bool        `json:"success"`
-	Command string      `json:"command"`
-	Data    any         `json:"data,omitempty"`
-	Message string      `json:"message,omitempty"`
-	Error   *ErrorInfo  `json:"error,omitempty"`
+	Success bool         `json:"success"`
+	Command string       `json:"command"`
+	Data    any          `json:"data,omitempty"`
+	Message string       `json:"message,omitempty"`
+	Error   *ErrorInfo   `json:"error,omitempty"`
+	Meta    *Meta        `json:"meta,omitempty"`
+	State   *StopContext `json:"state,omitempty"`
 }
 
 // OutputFormat specifies the output format
@@ -28,6 +31,9 @@ const (
 
 // Print outputs the response in the specified format
 func (r *Response) Print(format OutputFormat) {
+	r.applyState()
+	r.applyRetries()
+	r.applyTokenOptions()
 	switch format {
 	case FormatText:
 		r.printText()
@@ -52,8 +58,12 @@ func (r *Response) ExitCode() int {
 		return ExitGenericError
 	}
 
-	// Map error codes to exit codes
-	switch r.Error.Code {
+	return ExitCodeFor(r.Error.Code)
+}
+
+// ExitCodeFor returns the exit code of a failure with the given error code
+func ExitCodeFor(code string) int {
+	switch code {
 	case ErrCodeTimeout:
 		return ExitTimeout
 	case ErrCodeConnectionFailed, ErrCodeConnectionRefused:
@@ -64,6 +74,11 @@ func (r *Response) ExitCode() int {
 		return ExitUsageError
 	case ErrCodeProcessExited:
 		return ExitProcessError
+	case ErrCodeStepWhileRunning, ErrCodeStepAfterExit,
+		ErrCodeContinueWhileRunning, ErrCodeContinueAfterExit,
+		ErrCodeInspectWhileRunning, ErrCodeInspectAfterExit, ErrCodeInspectBeforeStart,
+		ErrCodeStaleContext, ErrCodeOperationInProgress:
+		return ExitInvalidState
 	default:
 		return ExitGenericError
 	}
@@ -89,6 +104,19 @@ func (r *Response) printText() {
 		data, _ := json.MarshalIndent(r.Data, "", "  ")
 		fmt.Println(string(data))
 	}
+	if r.Meta != nil && r.Meta.TokensEstimate > 0 {
+		if r.Meta.Truncated {
+			fmt.Printf("(~%d tokens, truncated: %s)\n", r.Meta.TokensEstimate, strings.Join(r.Meta.TruncatedPaths, ", "))
+		} else {
+			fmt.Printf("(~%d tokens)\n", r.Meta.TokensEstimate)
+		}
+	}
+	if r.Meta != nil && r.Meta.Retries > 0 {
+		fmt.Println(r.Meta.retriesText())
+	}
+	if r.State != nil {
+		fmt.Println(r.State)
+	}
 }
 
 // Success creates a successful response
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// retries counts the RPCs retried by this process, by error class. RPCs
+// abandoned on a timeout may still be retrying while the response prints,
+// so retriesMu guards it.
+var (
+	retriesMu sync.Mutex
+	retries   = map[string]int{}
+)
+
+// RecordRetry notes that an RPC was retried after an error of class; the
+// count is reported in meta.retries of the response
+func RecordRetry(class string) {
+	retriesMu.Lock()
+	defer retriesMu.Unlock()
+	retries[class]++
+}
+
+// applyRetries fills in the retry counts of Meta
+func (r *Response) applyRetries() {
+	retriesMu.Lock()
+	defer retriesMu.Unlock()
+	total := 0
+	for _, n := range retries {
+		total += n
+	}
+	if total == 0 {
+		return
+	}
+	if r.Meta == nil {
+		r.Meta = &Meta{}
+	}
+	r.Meta.Retries = total
+	r.Meta.RetriesByClass = make(map[string]int, len(retries))
+	for class, n := range retries {
+		r.Meta.RetriesByClass[class] = n
+	}
+}
+
+// retriesText formats the retry counts on one line for text output
+func (m *Meta) retriesText() string {
+	classes := make([]string, 0, len(m.RetriesByClass))
+	for class, n := range m.RetriesByClass {
+		classes = append(classes, fmt.Sprintf("%s %d", class, n))
+	}
+	sort.Strings(classes)
+	return fmt.Sprintf("(%d retries: %s)", m.Retries, strings.Join(classes, ", "))
+}
@@ -0,0 +1,41 @@
+package output
+
+import "fmt"
+
+// StopContext is the compact session state embedded in responses with --with-state
+type StopContext struct {
+	State       string `json:"state"`
+	GoroutineID int64  `json:"goroutineId,omitempty"`
+	// Location is file:line:function of the selected goroutine
+	Location   string `json:"location,omitempty"`
+	ExitStatus *int   `json:"exitStatus,omitempty"`
+}
+
+// stateProvider fetches the stop context for responses; nil disables it
+var stateProvider func() *StopContext
+
+// SetStateProvider makes every printed response embed the stop context
+// returned by f. f may return nil when no state is available.
+func SetStateProvider(f func() *StopContext) {
+	stateProvider = f
+}
+
+// applyState fills in State from the configured provider
+func (r *Response) applyState() {
+	if stateProvider == nil || r.State != nil {
+		return
+	}
+	r.State = stateProvider()
+}
+
+// String formats the stop context on one line for text output
+func (s *StopContext) String() string {
+	switch {
+	case s.ExitStatus != nil:
+		return fmt.Sprintf("[%s, status %d]", s.State, *s.ExitStatus)
+	case s.Location != "":
+		return fmt.Sprintf("[%s, goroutine %d at %s]", s.State, s.GoroutineID, s.Location)
+	default:
+		return fmt.Sprintf("[%s]", s.State)
+	}
+}
@@ -0,0 +1,17 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Emit writes one intermediate record of a streaming command as a single JSON
+// line, so that a stream of records is NDJSON. The command's final Response
+// follows the records as the last line.
+func Emit(record any) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	_, _ = os.Stdout.Write(append(line, '\n'))
+}
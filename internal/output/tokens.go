@@ -0,0 +1,186 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// charsPerToken is the average number of characters per LLM token for JSON text.
+// It is a deliberately simple heuristic; exact counts depend on the tokenizer.
+const charsPerToken = 4
+
+// minTruncatedString is the length below which strings are never shortened
+const minTruncatedString = 16
+
+// truncationMarker is appended to strings shortened to fit a token budget
+const truncationMarker = "..."
+
+// Meta carries optional information about the response itself
+type Meta struct {
+	TokensEstimate int      `json:"tokens_estimate,omitempty"`
+	BudgetTokens   int      `json:"budget_tokens,omitempty"`
+	Truncated      bool     `json:"truncated,omitempty"`
+	TruncatedPaths []string `json:"truncated_paths,omitempty"`
+	// Retries counts the RPCs retried under --retry-on, by error class
+	Retries        int            `json:"retries,omitempty"`
+	RetriesByClass map[string]int `json:"retries_by_class,omitempty"`
+}
+
+// tokenOptions are the process-wide settings applied when a response is printed
+var tokenOptions struct {
+	estimate bool
+	budget   int
+}
+
+// SetTokenOptions enables the tokens_estimate meta field and, when budget is
+// positive, truncation of response data to fit within budget tokens
+func SetTokenOptions(estimate bool, budget int) {
+	tokenOptions.estimate = estimate || budget > 0
+	tokenOptions.budget = max(budget, 0)
+}
+
+// EstimateTokens returns an approximate LLM token count for the JSON encoding of v
+func EstimateTokens(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return (len(data) + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateTextTokens returns an approximate LLM token count for plain text
+func EstimateTextTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
+
+// applyTokenOptions fills in Meta and truncates Data according to tokenOptions
+func (r *Response) applyTokenOptions() {
+	if !tokenOptions.estimate {
+		return
+	}
+	if r.Meta == nil {
+		r.Meta = &Meta{}
+	}
+	r.Meta.BudgetTokens = tokenOptions.budget
+	if tokenOptions.budget > 0 && r.Data != nil && EstimateTokens(r) > tokenOptions.budget {
+		r.Data, r.Meta.TruncatedPaths = Truncate(r.Data, func(data any) bool {
+			saved := r.Data
+			r.Data = data
+			defer func() { r.Data = saved }()
+			return EstimateTokens(r) <= tokenOptions.budget
+		})
+		r.Meta.Truncated = len(r.Meta.TruncatedPaths) > 0
+	}
+	r.Meta.TokensEstimate = EstimateTokens(r)
+}
+
+// Truncate shrinks data until fits reports true or nothing more can be shrunk.
+// The largest list is halved first, keeping its leading elements; once no list
+// can shrink, the longest string is halved. The result is deterministic for a
+// given input. It returns the shrunk data and the sorted paths that were cut.
+func Truncate(data any, fits func(any) bool) (any, []string) {
+	generic, err := toGeneric(data)
+	if err != nil {
+		return data, nil
+	}
+
+	// The holder lets the top-level value itself be replaced when it is a list
+	holder := map[string]any{"data": generic}
+	cut := map[string]bool{}
+	for !fits(holder["data"]) {
+		path, ok := shrinkLargest(holder)
+		if !ok {
+			break
+		}
+		cut[path] = true
+	}
+
+	paths := make([]string, 0, len(cut))
+	for p := range cut {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return holder["data"], paths
+}
+
+// toGeneric round-trips v through JSON so it consists only of maps, slices and scalars
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// shrinkCandidate is a list or string that can be made smaller
+type shrinkCandidate struct {
+	path  string
+	size  int
+	apply func()
+}
+
+// shrinkLargest halves the largest list below holder, or failing that its longest string
+func shrinkLargest(holder map[string]any) (string, bool) {
+	var lists, strs []shrinkCandidate
+	collectCandidates(holder["data"], "data", func(nv any) { holder["data"] = nv }, &lists, &strs)
+
+	for _, candidates := range [][]shrinkCandidate{lists, strs} {
+		if len(candidates) == 0 {
+			continue
+		}
+		best := candidates[0]
+		for _, c := range candidates[1:] {
+			if c.size > best.size || (c.size == best.size && c.path < best.path) {
+				best = c
+			}
+		}
+		best.apply()
+		return best.path, true
+	}
+	return "", false
+}
+
+// collectCandidates walks v and records every non-empty list and long string
+// along with a setter that replaces it with a halved version
+func collectCandidates(v any, path string, set func(any), lists, strs *[]shrinkCandidate) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			collectCandidates(child, path+"."+k, func(nv any) { val[k] = nv }, lists, strs)
+		}
+	case []any:
+		for i, child := range val {
+			collectCandidates(child, fmt.Sprintf("%s[%d]", path, i), func(nv any) { val[i] = nv }, lists, strs)
+		}
+		if len(val) > 0 {
+			*lists = append(*lists, shrinkCandidate{
+				path:  path,
+				size:  EstimateTokens(val),
+				apply: func() { set(val[:len(val)/2]) },
+			})
+		}
+	case string:
+		if len(val) > minTruncatedString {
+			*strs = append(*strs, shrinkCandidate{
+				path:  path,
+				size:  len(val),
+				apply: func() { set(halveString(val)) },
+			})
+		}
+	}
+}
+
+// halveString keeps the first half of s, cut on a rune boundary, plus truncationMarker
+func halveString(s string) string {
+	n := len(s) / 2
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + truncationMarker
+}
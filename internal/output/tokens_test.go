@@ -0,0 +1,67 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+// TestTruncateHalvesLargestList checks that the biggest list is cut first and reported.
+func TestTruncateHalvesLargestList(t *testing.T) {
+	data := map[string]any{
+		"small": []any{1, 2},
+		"large": []any{"aaaa", "bbbb", "cccc", "dddd", "eeee", "ffff", "gggg", "hhhh"},
+	}
+	got, paths := Truncate(data, func(v any) bool { return EstimateTokens(v) <= 15 })
+
+	if EstimateTokens(got) > 15 {
+		t.Fatalf("Truncate result is %d tokens; want <= 15", EstimateTokens(got))
+	}
+	if !reflect.DeepEqual(paths, []string{"data.large"}) {
+		t.Fatalf("paths = %v; want [data.large]", paths)
+	}
+	if small := got.(map[string]any)["small"].([]any); len(small) != 2 {
+		t.Fatalf("small list was cut to %d elements", len(small))
+	}
+}
+
+// TestTruncateLongString checks that strings are shortened once no list is left.
+func TestTruncateLongString(t *testing.T) {
+	data := map[string]any{"source": "0123456789abcdefghijklmnopqrstuvwxyz0123456789abcdefghijklmnopqrstuvwxyz"}
+	got, paths := Truncate(data, func(v any) bool { return EstimateTokens(v) <= 15 })
+
+	if !reflect.DeepEqual(paths, []string{"data.source"}) {
+		t.Fatalf("paths = %v; want [data.source]", paths)
+	}
+	if s := got.(map[string]any)["source"].(string); len(s) >= len(data["source"].(string)) {
+		t.Fatalf("source was not shortened: %q", s)
+	}
+}
+
+// TestTruncateProperty checks that Truncate either fits the budget or has nothing
+// left to cut, and that the same input always gives the same output.
+func TestTruncateProperty(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		words := rapid.SliceOf(rapid.StringN(0, 40, -1)).Draw(t, "words")
+		nested := rapid.SliceOf(rapid.IntRange(0, 1000)).Draw(t, "nested")
+		budget := rapid.IntRange(1, 200).Draw(t, "budget")
+		data := map[string]any{"words": words, "inner": map[string]any{"nested": nested}}
+		fits := func(v any) bool { return EstimateTokens(v) <= budget }
+
+		got, paths := Truncate(data, fits)
+		again, againPaths := Truncate(data, fits)
+		if !reflect.DeepEqual(got, again) || !reflect.DeepEqual(paths, againPaths) {
+			t.Fatalf("Truncate is not deterministic")
+		}
+		if fits(got) {
+			return
+		}
+		// Over budget is only acceptable when every list is empty and every string short
+		var lists, strs []shrinkCandidate
+		collectCandidates(got, "data", func(any) {}, &lists, &strs)
+		if len(lists) > 0 || len(strs) > 0 {
+			t.Fatalf("Truncate stopped over budget with %d lists and %d strings left", len(lists), len(strs))
+		}
+	})
+}
@@ -0,0 +1,174 @@
+// Package project loads a project's shared godebug configuration from the
+// .godebug directory at its root, so a team's debugging setup is checked in
+// with the code: path rules for binaries built elsewhere, the default start
+// target, breakpoints set at every start and default flag values.
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirName is the directory holding the configuration at the project root
+const DirName = ".godebug"
+
+// ConfigFile is the configuration file inside DirName
+const ConfigFile = "config.yaml"
+
+// AllCommands is the Defaults key whose flags apply to every command
+const AllCommands = "*"
+
+// Config is a project's configuration file
+type Config struct {
+	// SubstitutePaths map directories the binary was compiled in to local
+	// directories, ahead of the rules godebug detects itself
+	SubstitutePaths []SubstitutePath `yaml:"substitutePaths" json:"substitutePaths"`
+	Start           Start            `yaml:"start" json:"start"`
+	// Breakpoints are set by every start
+	Breakpoints []Breakpoint `yaml:"breakpoints" json:"breakpoints"`
+	// Defaults maps a command path ("start", "probe run", or "*" for all
+	// commands) to flag values used when the flag is not given
+	Defaults map[string]map[string]string `yaml:"defaults" json:"defaults"`
+
+	// Root is the directory containing DirName; relative paths resolve
+	// against it
+	Root string `yaml:"-"`
+	// File is the path the configuration was loaded from
+	File string `yaml:"-"`
+}
+
+// SubstitutePath maps the binary's compile directory From to the local To
+type SubstitutePath struct {
+	From string `yaml:"from" json:"from"`
+	To   string `yaml:"to" json:"to"`
+}
+
+// Start is what start launches when it is given no target
+type Start struct {
+	Target string   `yaml:"target" json:"target"`
+	Args   []string `yaml:"args" json:"args,omitempty"`
+}
+
+// Breakpoint is a location as for godebug break, with an optional condition
+type Breakpoint struct {
+	Location string `yaml:"location" json:"location"`
+	Cond     string `yaml:"cond" json:"cond,omitempty"`
+}
+
+// Find walks up from dir to the nearest project configuration and loads it.
+// It returns nil without an error if there is none.
+func Find(dir string) (*Config, error) {
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		file := filepath.Join(d, DirName, ConfigFile)
+		if _, err := os.Stat(file); err == nil {
+			return Load(file)
+		}
+		if filepath.Dir(d) == d {
+			return nil, nil
+		}
+	}
+}
+
+// Load reads a configuration file and checks it
+func Load(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	cfg.File = file
+	cfg.Root = filepath.Dir(filepath.Dir(file))
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return &cfg, nil
+}
+
+// validate checks the entries that must be complete
+func (c *Config) validate() error {
+	for i, p := range c.SubstitutePaths {
+		if p.From == "" || p.To == "" {
+			return fmt.Errorf("substitutePaths[%d] needs from and to", i)
+		}
+	}
+	for i, bp := range c.Breakpoints {
+		if bp.Location == "" {
+			return fmt.Errorf("breakpoints[%d] needs a location", i)
+		}
+	}
+	if len(c.Start.Args) > 0 && c.Start.Target == "" {
+		return errors.New("start.args needs start.target")
+	}
+	return nil
+}
+
+// Path resolves a path from the configuration against the project root
+func (c *Config) Path(p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(c.Root, p)
+}
+
+// StartTarget returns the default start target. Relative package and file
+// paths resolve against the project root; anything else, such as a PID or an
+// import path, is returned as written.
+func (c *Config) StartTarget() string {
+	t := c.Start.Target
+	if t == "." || t == ".." || filepath.IsAbs(t) || (len(t) > 1 && t[0] == '.' && (t[1] == '/' || t[1] == '.')) {
+		return c.Path(t)
+	}
+	return t
+}
+
+// CommandDefaults returns the default flag values for a command path: those
+// for all commands, overridden by those for the command
+func (c *Config) CommandDefaults(command string) map[string]string {
+	values := map[string]string{}
+	for _, key := range []string{AllCommands, command} {
+		for name, value := range c.Defaults[key] {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// Template is the configuration project init writes
+const Template = `# godebug project configuration, shared through the repository.
+# Commands run anywhere below this directory load it.
+
+# Directories the binary was compiled in (CI, containers) and where those
+# files are here; "to" is relative to the project root.
+substitutePaths: []
+#  - from: /build/src/example.com/app
+#    to: .
+
+# What start launches when it is given no target.
+start: {}
+#  target: ./cmd/server
+#  args: ["-port", "8080"]
+
+# Breakpoints every start sets.
+breakpoints: []
+#  - location: main.go:42
+#  - location: server.handleRequest
+#    cond: req.ID == 7
+
+# Flag values used when a command is run without the flag; "*" applies to
+# every command.
+defaults: {}
+#  "*":
+#    timeout: 1m
+#  start:
+#    build-flags: -tags=integration
+#    on-crash: capture
+#  triage:
+#    restarts: "10"
+`
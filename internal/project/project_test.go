@@ -0,0 +1,111 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeConfig writes a configuration under root and returns its path
+func writeConfig(t *testing.T, root, content string) string {
+	t.Helper()
+	file := filepath.Join(root, DirName, ConfigFile)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// TestFind checks the walk up to the configuration and what it resolves against.
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	file := writeConfig(t, root, `
+substitutePaths:
+  - from: /build/src/app
+    to: .
+start:
+  target: ./cmd/server
+  args: ["-port", "8080"]
+breakpoints:
+  - location: main.go:10
+defaults:
+  "*":
+    timeout: 1m
+  start:
+    timeout: 2m
+    build-flags: -race
+`)
+	sub := filepath.Join(root, "internal", "db")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Find(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg == nil || cfg.File != file || cfg.Root != root {
+		t.Fatalf("Find(%s) = %+v, want the configuration in %s", sub, cfg, root)
+	}
+	if got, want := cfg.StartTarget(), filepath.Join(root, "cmd", "server"); got != want {
+		t.Errorf("StartTarget() = %q, want %q", got, want)
+	}
+	if got, want := cfg.Path(cfg.SubstitutePaths[0].To), root; got != want {
+		t.Errorf("Path(.) = %q, want %q", got, want)
+	}
+	start := cfg.CommandDefaults("start")
+	if start["timeout"] != "2m" || start["build-flags"] != "-race" {
+		t.Errorf("CommandDefaults(start) = %v, want the start values over *", start)
+	}
+	if eval := cfg.CommandDefaults("eval"); len(eval) != 1 || eval["timeout"] != "1m" {
+		t.Errorf("CommandDefaults(eval) = %v, want only the * values", eval)
+	}
+
+	if cfg, err := Find(t.TempDir()); cfg != nil || err != nil {
+		t.Errorf("Find without a configuration = %+v, %v; want nil, nil", cfg, err)
+	}
+}
+
+// TestStartTarget checks which targets resolve against the root.
+func TestStartTarget(t *testing.T) {
+	cfg := &Config{Root: "/repo"}
+	for target, want := range map[string]string{
+		".":                   "/repo",
+		"./cmd/app":           "/repo/cmd/app",
+		"../other":            "/other",
+		"/abs/bin":            "/abs/bin",
+		"example.com/app/cmd": "example.com/app/cmd",
+		"4242":                "4242",
+	} {
+		cfg.Start.Target = target
+		if got := cfg.StartTarget(); got != filepath.FromSlash(want) {
+			t.Errorf("StartTarget(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+// TestLoadInvalid checks that incomplete entries are rejected.
+func TestLoadInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"substitute path": "substitutePaths:\n  - from: /build\n",
+		"breakpoint":      "breakpoints:\n  - cond: x > 1\n",
+		"args":            "start:\n  args: [-v]\n",
+		"yaml":            "breakpoints: {\n",
+	} {
+		file := writeConfig(t, t.TempDir(), content)
+		if _, err := Load(file); err == nil {
+			t.Errorf("%s: Load succeeded, want an error", name)
+		}
+	}
+}
+
+// TestTemplate checks that the template project init writes loads.
+func TestTemplate(t *testing.T) {
+	file := writeConfig(t, t.TempDir(), Template)
+	if _, err := Load(file); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,340 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// segment is one step of a response path: a field name, an array index or
+// an array filter selecting the first element whose field equals a value
+type segment struct {
+	key    string
+	index  int
+	filter string // field compared by a [field=value] segment
+	value  string
+	kind   segmentKind
+}
+
+type segmentKind int
+
+const (
+	segKey segmentKind = iota
+	segIndex
+	segFilter
+)
+
+// parsePath parses a path such as data.location.line, data.variables[0].name
+// or data.variables[name=x].value. A leading "$." is accepted and ignored.
+func parsePath(path string) ([]segment, error) {
+	p := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if p == "" {
+		return nil, fmt.Errorf("empty path %q", path)
+	}
+	var segs []segment
+	for p != "" {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			inner := p[1:end]
+			p = p[end+1:]
+			if field, value, ok := strings.Cut(inner, "="); ok {
+				if field == "" {
+					return nil, fmt.Errorf("empty filter field in path %q", path)
+				}
+				segs = append(segs, segment{kind: segFilter, filter: field, value: value})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index [%s] in path %q", inner, path)
+			}
+			segs = append(segs, segment{kind: segIndex, index: n})
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			segs = append(segs, segment{kind: segKey, key: p[:end]})
+			p = p[end:]
+		}
+	}
+	return segs, nil
+}
+
+// Lookup returns the value at path in a decoded JSON document
+func Lookup(doc any, path string) (any, bool, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	v := doc
+	for _, s := range segs {
+		switch s.kind {
+		case segKey:
+			m, ok := v.(map[string]any)
+			if !ok {
+				return nil, false, nil
+			}
+			if v, ok = m[s.key]; !ok {
+				return nil, false, nil
+			}
+		case segIndex:
+			a, ok := v.([]any)
+			if !ok || s.index >= len(a) {
+				return nil, false, nil
+			}
+			v = a[s.index]
+		case segFilter:
+			a, ok := v.([]any)
+			if !ok {
+				return nil, false, nil
+			}
+			found := false
+			for _, elem := range a {
+				if m, ok := elem.(map[string]any); ok && scalarString(m[s.filter]) == s.value {
+					v, found = elem, true
+					break
+				}
+			}
+			if !found {
+				return nil, false, nil
+			}
+		}
+	}
+	return v, true, nil
+}
+
+// Matcher is an expectation on one response value. A plain YAML value must
+// equal the response value; a mapping of operators combines checks:
+//
+//	equals: <value>     equal (numbers and strings compare by their text)
+//	contains: <value>   substring of a string, or element of an array
+//	matches: <regexp>   the value's text matches
+//	exists: <bool>      the path is present (or absent)
+//	min/max: <number>   numeric bounds, inclusive
+//	len: <int>          length of an array, object or string
+type Matcher struct {
+	Equals   any      `yaml:"equals"`
+	Contains any      `yaml:"contains"`
+	Matches  string   `yaml:"matches"`
+	Exists   *bool    `yaml:"exists"`
+	Min      *float64 `yaml:"min"`
+	Max      *float64 `yaml:"max"`
+	Len      *int     `yaml:"len"`
+
+	hasEquals   bool
+	hasContains bool
+	re          *regexp.Regexp
+}
+
+// matcherOps are the keys that make a YAML mapping a set of operators
+var matcherOps = map[string]bool{
+	"equals": true, "contains": true, "matches": true, "exists": true,
+	"min": true, "max": true, "len": true,
+}
+
+// UnmarshalYAML decodes either an operator mapping or a plain expected value
+func (m *Matcher) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode && isOperatorMapping(value) {
+		type plain Matcher
+		var p plain
+		if err := value.Decode(&p); err != nil {
+			return err
+		}
+		*m = Matcher(p)
+		for i := 0; i < len(value.Content); i += 2 {
+			switch value.Content[i].Value {
+			case "equals":
+				m.hasEquals = true
+			case "contains":
+				m.hasContains = true
+			}
+		}
+	} else {
+		var v any
+		if err := value.Decode(&v); err != nil {
+			return err
+		}
+		*m = Matcher{Equals: v, hasEquals: true}
+	}
+	if m.Matches != "" {
+		re, err := regexp.Compile(m.Matches)
+		if err != nil {
+			return fmt.Errorf("invalid matches pattern: %w", err)
+		}
+		m.re = re
+	}
+	return nil
+}
+
+// isOperatorMapping reports whether every key of a mapping node is an operator
+func isOperatorMapping(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return false
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if !matcherOps[node.Content[i].Value] {
+			return false
+		}
+	}
+	return true
+}
+
+// Check returns an error describing the first expectation v does not meet.
+// found reports whether the path was present in the response.
+func (m Matcher) Check(v any, found bool) error {
+	if m.Exists != nil {
+		if found != *m.Exists {
+			if found {
+				return fmt.Errorf("expected no value, got %s", describe(v))
+			}
+			return fmt.Errorf("expected a value, path not found")
+		}
+		if !found {
+			return nil
+		}
+	}
+	if !found {
+		return fmt.Errorf("path not found")
+	}
+	if m.hasEquals && !equal(m.Equals, v) {
+		return fmt.Errorf("expected %s, got %s", describe(m.Equals), describe(v))
+	}
+	if m.hasContains && !contains(v, m.Contains) {
+		return fmt.Errorf("expected %s to contain %s", describe(v), describe(m.Contains))
+	}
+	if m.re != nil && !m.re.MatchString(scalarString(v)) {
+		return fmt.Errorf("expected %s to match %s", describe(v), m.Matches)
+	}
+	if m.Min != nil || m.Max != nil {
+		n, ok := number(v)
+		if !ok {
+			return fmt.Errorf("expected a number, got %s", describe(v))
+		}
+		if m.Min != nil && n < *m.Min {
+			return fmt.Errorf("expected >= %v, got %v", *m.Min, n)
+		}
+		if m.Max != nil && n > *m.Max {
+			return fmt.Errorf("expected <= %v, got %v", *m.Max, n)
+		}
+	}
+	if m.Len != nil {
+		var n int
+		switch x := v.(type) {
+		case []any:
+			n = len(x)
+		case map[string]any:
+			n = len(x)
+		case string:
+			n = len(x)
+		default:
+			return fmt.Errorf("expected a value with a length, got %s", describe(v))
+		}
+		if n != *m.Len {
+			return fmt.Errorf("expected length %d, got %d", *m.Len, n)
+		}
+	}
+	return nil
+}
+
+// equal compares an expected YAML value with a decoded JSON value. Scalars
+// compare by their text, so 25 matches both the number 25 and the string
+// "25" Delve reports as a variable's value.
+func equal(want, got any) bool {
+	w := normalize(want)
+	if reflect.DeepEqual(w, got) {
+		return true
+	}
+	if isScalar(w) && isScalar(got) && w != nil && got != nil {
+		return scalarString(w) == scalarString(got)
+	}
+	return false
+}
+
+// contains reports whether a string holds a substring or an array an element
+func contains(v, needle any) bool {
+	switch x := v.(type) {
+	case string:
+		return strings.Contains(x, scalarString(needle))
+	case []any:
+		for _, elem := range x {
+			if equal(needle, elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalize converts a YAML value to the types encoding/json decodes to
+func normalize(v any) any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func isScalar(v any) bool {
+	switch v.(type) {
+	case []any, map[string]any:
+		return false
+	}
+	return true
+}
+
+// scalarString renders a scalar the way it is written in a scenario
+func scalarString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// number converts a JSON number or numeric string
+func number(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case string:
+		n, err := strconv.ParseFloat(x, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// describe renders a value for a failure message
+func describe(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	const max = 120
+	if len(raw) > max {
+		return string(raw[:max]) + "..."
+	}
+	return string(raw)
+}
@@ -0,0 +1,134 @@
+package scenario
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const matchResponse = `{
+	"success": true,
+	"command": "args",
+	"data": {
+		"count": 2,
+		"variables": [
+			{"name": "x", "type": "int", "value": "25"},
+			{"name": "s", "type": "string", "value": "\"hello\""}
+		],
+		"location": {"file": "/src/main.go", "line": 16, "function": "main.innerFunc"}
+	}
+}`
+
+func decodeResponse(t *testing.T) any {
+	t.Helper()
+	var doc any
+	if err := json.Unmarshal([]byte(matchResponse), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func decodeMatcher(t *testing.T, src string) Matcher {
+	t.Helper()
+	var m Matcher
+	if err := yaml.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatalf("decode %q: %v", src, err)
+	}
+	return m
+}
+
+// TestLookup checks field, index and filter segments.
+func TestLookup(t *testing.T) {
+	doc := decodeResponse(t)
+	cases := map[string]any{
+		"success":                      true,
+		"$.data.count":                 float64(2),
+		"data.variables[1].name":       "s",
+		"data.variables[name=x].value": "25",
+		"data.location.line":           float64(16),
+	}
+	for path, want := range cases {
+		got, found, err := Lookup(doc, path)
+		if err != nil || !found || got != want {
+			t.Errorf("Lookup(%s) = %v, %v, %v; want %v", path, got, found, err, want)
+		}
+	}
+	for _, path := range []string{"data.missing", "data.variables[5]", "data.variables[name=y]", "data.count.x"} {
+		if _, found, _ := Lookup(doc, path); found {
+			t.Errorf("Lookup(%s) found a value", path)
+		}
+	}
+	if _, _, err := Lookup(doc, "data.variables[x"); err == nil {
+		t.Error("unclosed bracket accepted")
+	}
+}
+
+// TestMatcherCheck checks plain values and each operator against a response.
+func TestMatcherCheck(t *testing.T) {
+	doc := decodeResponse(t)
+	cases := []struct {
+		path, matcher string
+		ok            bool
+	}{
+		{"data.location.line", "16", true},
+		{"data.location.line", "17", false},
+		{"data.variables[name=x].value", "25", true},
+		{"data.location.function", "{contains: innerFunc}", true},
+		{"data.location.function", "{matches: '^main\\.outer'}", false},
+		{"data.variables", "{len: 2}", true},
+		{"data.variables", "{contains: {name: x, type: int, value: '25'}}", true},
+		{"data.count", "{min: 1, max: 2}", true},
+		{"data.count", "{min: 3}", false},
+		{"data.crash", "{exists: false}", true},
+		{"data.crash", "true", false},
+		{"data.location", "{equals: {file: /src/main.go, line: 16, function: main.innerFunc}}", true},
+	}
+	for _, tc := range cases {
+		v, found, _ := Lookup(doc, tc.path)
+		err := decodeMatcher(t, tc.matcher).Check(v, found)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s %s: err = %v, want ok %v", tc.path, tc.matcher, err, tc.ok)
+		}
+	}
+}
+
+// TestLoad checks defaults and the two forms of run.
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "args.yaml")
+	src := `launch:
+  target: ../debugme
+steps:
+  - run: break main.innerFunc
+  - run: [eval, "x + 1"]
+    expect:
+      data.value: 26
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sc, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Name != "args" || sc.Launch.Mode != "debug" {
+		t.Errorf("name %q, mode %q; want args, debug", sc.Name, sc.Launch.Mode)
+	}
+	if got := strings.Join(sc.Steps[1].Run, "|"); got != "eval|x + 1" {
+		t.Errorf("run = %s", got)
+	}
+	if got := sc.TargetPath(); got != filepath.Join(filepath.Dir(path), "..", "debugme") {
+		t.Errorf("TargetPath = %s", got)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(bad, []byte("launch: {target: x, mode: core}\nsteps: [{run: stack}]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(bad); err == nil {
+		t.Error("invalid mode accepted")
+	}
+}
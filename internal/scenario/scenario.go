@@ -0,0 +1,155 @@
+// Package scenario loads debugger regression scenarios. A scenario launches a
+// target, runs a sequence of godebug commands against it and asserts fields
+// of each JSON response.
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is one scenario file
+type Scenario struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Launch      Launch `yaml:"launch"`
+	Steps       []Step `yaml:"steps"`
+
+	// File is the path the scenario was loaded from; relative targets resolve
+	// against its directory
+	File string `yaml:"-"`
+}
+
+// Launch describes the debug session a scenario runs in
+type Launch struct {
+	// Mode is debug (default), test or exec, as for godebug start
+	Mode       string   `yaml:"mode"`
+	Target     string   `yaml:"target"`
+	Args       []string `yaml:"args"`
+	BuildFlags string   `yaml:"buildFlags"`
+	// Standalone builds the target directory as a module of its own with the
+	// pre-1.22 loop variable semantics, instead of inside the enclosing module
+	Standalone bool `yaml:"standalone"`
+}
+
+// Step is one command and the expectations on its response
+type Step struct {
+	Name string  `yaml:"name"`
+	Run  Command `yaml:"run"`
+	// Expect maps response paths to matchers. A step without expectations
+	// passes when the command succeeds.
+	Expect map[string]Matcher `yaml:"expect"`
+}
+
+// Command is the arguments of a godebug invocation, without the global
+// --addr and --output flags. It is written as a string split on whitespace
+// or as a list when an argument contains spaces.
+type Command []string
+
+// UnmarshalYAML accepts a string or a list of strings
+func (c *Command) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*c = strings.Fields(value.Value)
+		return nil
+	}
+	var args []string
+	if err := value.Decode(&args); err != nil {
+		return err
+	}
+	*c = args
+	return nil
+}
+
+// Title returns the step name, or its command when it has none
+func (s Step) Title() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return strings.Join(s.Run, " ")
+}
+
+// Paths returns the expectation paths in a stable order
+func (s Step) Paths() []string {
+	paths := make([]string, 0, len(s.Expect))
+	for p := range s.Expect {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Load reads and validates a scenario file
+func Load(path string) (*Scenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sc Scenario
+	if err := yaml.Unmarshal(raw, &sc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	sc.File = path
+	if sc.Name == "" {
+		sc.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := sc.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &sc, nil
+}
+
+// validate checks the launch config, the steps and their paths
+func (sc *Scenario) validate() error {
+	switch sc.Launch.Mode {
+	case "":
+		sc.Launch.Mode = "debug"
+	case "debug", "test", "exec":
+	default:
+		return fmt.Errorf("invalid launch mode %q (use debug, test or exec)", sc.Launch.Mode)
+	}
+	if sc.Launch.Target == "" {
+		return fmt.Errorf("launch.target is required")
+	}
+	if sc.Launch.Standalone && sc.Launch.Mode != "debug" {
+		return fmt.Errorf("launch.standalone requires mode debug")
+	}
+	if len(sc.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+	for i, step := range sc.Steps {
+		if len(step.Run) == 0 {
+			return fmt.Errorf("step %d: run is required", i+1)
+		}
+		for path := range step.Expect {
+			if _, err := parsePath(path); err != nil {
+				return fmt.Errorf("step %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// TargetPath returns the launch target resolved against the scenario file
+func (sc *Scenario) TargetPath() string {
+	if filepath.IsAbs(sc.Launch.Target) {
+		return sc.Launch.Target
+	}
+	return filepath.Join(filepath.Dir(sc.File), sc.Launch.Target)
+}
+
+// Expand replaces {name} placeholders in the step's arguments with vars
+func (s Step) Expand(vars map[string]string) []string {
+	args := make([]string, len(s.Run))
+	for i, arg := range s.Run {
+		for name, value := range vars {
+			arg = strings.ReplaceAll(arg, "{"+name+"}", value)
+		}
+		args[i] = arg
+	}
+	return args
+}
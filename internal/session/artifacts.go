@@ -0,0 +1,172 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+// artifactsDir holds the files godebug writes for the user inside the
+// session directory, one subdirectory per capture or export
+const artifactsDir = "artifacts"
+
+// Kinds of artifacts that are not under the artifacts directory
+const (
+	KindOutput    = "output"
+	KindServerLog = "server-log"
+)
+
+// Artifact is a file godebug produced for a session
+type Artifact struct {
+	// Name identifies the artifact: its path below the artifacts directory,
+	// or stdout, stderr or server.log for the files the session record names
+	Name    string    `json:"name"`
+	Kind    string    `json:"kind"`
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	// Recorded artifacts are written while the target runs and are not cleaned
+	Recorded bool `json:"recorded,omitempty"`
+}
+
+// NewArtifactDir creates the directory for an artifact of kind produced at
+// t: artifacts/<kind>-<time> in the session directory
+func NewArtifactDir(addr, kind string, t time.Time) (string, error) {
+	dir, err := Dir(addr)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, artifactsDir, kind+"-"+t.Format("20060102-150405"))
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return "", output.InternalError(fmt.Sprintf("cannot create artifact directory: %v", err))
+	}
+	return path, nil
+}
+
+// artifactKind is the kind a directory below artifacts was created for
+func artifactKind(name string) string {
+	top, _, _ := strings.Cut(name, "/")
+	if i := strings.LastIndex(top, "-"); i > 0 {
+		// kind-20060102-150405
+		if j := strings.LastIndex(top[:i], "-"); j > 0 {
+			return top[:j]
+		}
+	}
+	return top
+}
+
+// Artifacts lists the files in the session's artifacts directory, oldest
+// first, followed by the output and server log files the session recorded
+func Artifacts(addr string) ([]Artifact, error) {
+	dir, err := Dir(addr)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Join(dir, artifactsDir)
+
+	var artifacts []Artifact
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		name := filepath.ToSlash(rel)
+		artifacts = append(artifacts, Artifact{
+			Name:    name,
+			Kind:    artifactKind(name),
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, output.InternalError(fmt.Sprintf("cannot list artifacts: %v", err))
+	}
+	sort.SliceStable(artifacts, func(i, j int) bool { return artifacts[i].ModTime.Before(artifacts[j].ModTime) })
+
+	if s, err := Load(addr); err == nil {
+		for _, f := range []struct{ name, kind, path string }{
+			{"stdout", KindOutput, s.Stdout},
+			{"stderr", KindOutput, s.Stderr},
+			{"server.log", KindServerLog, s.ServerLog},
+		} {
+			if f.path == "" {
+				continue
+			}
+			info, err := os.Stat(f.path)
+			if err != nil {
+				continue
+			}
+			artifacts = append(artifacts, Artifact{
+				Name:     f.name,
+				Kind:     f.kind,
+				Path:     f.path,
+				Size:     info.Size(),
+				ModTime:  info.ModTime(),
+				Recorded: true,
+			})
+		}
+	}
+	return artifacts, nil
+}
+
+// RemoveArtifacts deletes the artifacts whose name is name or lies below the
+// directory name (all of them for an empty name) and returns them. Recorded
+// artifacts are kept.
+func RemoveArtifacts(addr, name string) ([]Artifact, error) {
+	artifacts, err := Artifacts(addr)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.Trim(name, "/")
+	var removed []Artifact
+	for _, a := range artifacts {
+		if a.Recorded || (name != "" && a.Name != name && !strings.HasPrefix(a.Name, name+"/")) {
+			continue
+		}
+		if err := os.Remove(a.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, output.InternalError(fmt.Sprintf("cannot remove %s: %v", a.Name, err))
+		}
+		removed = append(removed, a)
+	}
+	removeEmptyDirs(addr)
+	return removed, nil
+}
+
+// removeEmptyDirs deletes the directories below artifacts left empty
+func removeEmptyDirs(addr string) {
+	dir, err := Dir(addr)
+	if err != nil {
+		return
+	}
+	root := filepath.Join(dir, artifactsDir)
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != root {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	// Deepest first, so parents are empty by the time they are reached
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+}
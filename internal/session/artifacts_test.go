@@ -0,0 +1,65 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArtifacts(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	addr := "127.0.0.1:4000"
+
+	crash, err := NewArtifactDir(addr, "crash", time.Date(2026, 10, 17, 9, 14, 3, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"core", "goroutines.json"} {
+		if err := os.WriteFile(filepath.Join(crash, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	export, _ := NewArtifactDir(addr, "wait-graph", time.Now())
+	_ = os.WriteFile(filepath.Join(export, "graph.dot"), []byte("digraph {}"), 0o644)
+
+	stdout := filepath.Join(t.TempDir(), "stdout")
+	_ = os.WriteFile(stdout, []byte("hello\n"), 0o644)
+	s := New(addr)
+	s.Stdout = stdout
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts, err := Artifacts(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]string{}
+	for _, a := range artifacts {
+		kinds[a.Name] = a.Kind
+	}
+	if len(artifacts) != 4 || kinds["crash-20261017-091403/core"] != "crash" || kinds["stdout"] != KindOutput {
+		t.Fatalf("artifacts = %v", kinds)
+	}
+	if got := artifactKind(filepath.Base(export) + "/graph.dot"); got != "wait-graph" {
+		t.Errorf("kind = %q, want wait-graph", got)
+	}
+
+	removed, err := RemoveArtifacts(addr, "crash-20261017-091403")
+	if err != nil || len(removed) != 2 {
+		t.Fatalf("removed %d (%v), want 2", len(removed), err)
+	}
+	if _, err := os.Stat(crash); !os.IsNotExist(err) {
+		t.Errorf("crash directory left behind: %v", err)
+	}
+
+	removed, _ = RemoveArtifacts(addr, "")
+	if len(removed) != 1 {
+		t.Errorf("clean removed %d, want 1", len(removed))
+	}
+	if _, err := os.Stat(stdout); err != nil {
+		t.Errorf("recorded output removed: %v", err)
+	}
+}
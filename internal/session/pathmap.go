@@ -0,0 +1,255 @@
+package session
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// maxLocalFiles bounds the files indexed when matching a checkout by suffix
+const maxLocalFiles = 20000
+
+// Ways a path rule was detected
+const (
+	PathSourceTrimpath    = "trimpath"     // -trimpath: paths start with the module path
+	PathSourceModuleCache = "module cache" // built from GOMODCACHE/module@version
+	PathSourceGOPATH      = "gopath"       // built from GOPATH/src/module
+	PathSourceCheckout    = "checkout"     // built from another copy of the same files
+	PathSourceProject     = "project"      // substitutePaths of the project configuration
+)
+
+// PathRule maps a directory of the binary's compile paths to a local directory
+type PathRule struct {
+	Binary string `json:"binary"`
+	Local  string `json:"local"`
+	Source string `json:"source"`
+}
+
+// PathMap translates between the paths the binary was compiled from and the
+// local checkout. With no rules the binary was built from the checkout itself
+// (or nothing could be matched) and paths pass through unchanged.
+type PathMap struct {
+	// Root is the local module root (or directory) the rules were built for
+	Root   string     `json:"root"`
+	Module string     `json:"module,omitempty"`
+	Rules  []PathRule `json:"rules"`
+}
+
+// FindModule walks up from dir to the nearest go.mod and returns its directory
+// and module path, or empty strings if there is none
+func FindModule(dir string) (string, string) {
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if f, err := os.Open(filepath.Join(d, "go.mod")); err == nil {
+			module := ""
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+					module = strings.Trim(strings.TrimSpace(rest), `"`)
+					break
+				}
+			}
+			_ = f.Close()
+			return d, module
+		}
+		if filepath.Dir(d) == d {
+			return "", ""
+		}
+	}
+}
+
+// escapeModulePath escapes upper case letters the way the module cache does
+// (github.com/Foo -> github.com/!foo)
+func escapeModulePath(module string) string {
+	var b strings.Builder
+	for _, r := range module {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// modulePrefix returns the directory of src that corresponds to the root of
+// module, recognizing -trimpath, module cache and GOPATH layouts
+func modulePrefix(src, module string) (string, string) {
+	if strings.HasPrefix(src, module+"/") {
+		return module, PathSourceTrimpath
+	}
+	marker := "/pkg/mod/" + escapeModulePath(module) + "@"
+	if i := strings.Index(src, marker); i >= 0 {
+		// The module root is the module@version element
+		if j := strings.Index(src[i+len(marker):], "/"); j >= 0 {
+			return src[:i+len(marker)+j], PathSourceModuleCache
+		}
+	}
+	if i := strings.Index(src, "/src/"+module+"/"); i >= 0 {
+		return src[:i+len("/src/"+module)], PathSourceGOPATH
+	}
+	return "", ""
+}
+
+// localFiles indexes the Go files below root by their slash-separated path
+// relative to root, skipping hidden and vendor directories
+func localFiles(root string) map[string]bool {
+	files := map[string]bool{}
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(p, ".go") {
+			if rel, err := filepath.Rel(root, p); err == nil {
+				files[filepath.ToSlash(rel)] = true
+			}
+		}
+		if len(files) >= maxLocalFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return files
+}
+
+// checkoutPrefix returns the directory of src under which its longest
+// trailing path exists in files, and the length of that path
+func checkoutPrefix(src string, files map[string]bool) (string, int) {
+	parts := strings.Split(src, "/")
+	for i := 1; i < len(parts); i++ {
+		if rel := strings.Join(parts[i:], "/"); files[rel] {
+			return strings.Join(parts[:i], "/"), len(parts) - i
+		}
+	}
+	return "", 0
+}
+
+// BuildPathMap matches the binary's source paths against the local checkout
+// containing dir. Module-aware layouts (-trimpath, module cache, GOPATH) are
+// recognized from the go.mod module path; otherwise the directory under which
+// most sources have a local counterpart wins.
+func BuildPathMap(sources []string, dir string) *PathMap {
+	root, module := FindModule(dir)
+	if root == "" {
+		root = filepath.Clean(dir)
+	}
+	m := &PathMap{Root: root, Module: module, Rules: []PathRule{}}
+
+	slashRoot := filepath.ToSlash(root)
+	for _, src := range sources {
+		if strings.HasPrefix(filepath.ToSlash(src), slashRoot+"/") {
+			// Built from this checkout
+			return m
+		}
+	}
+
+	type candidate struct {
+		source string
+		votes  int
+		depth  int
+	}
+	candidates := map[string]*candidate{}
+	vote := func(prefix, source string, depth int) {
+		c, ok := candidates[prefix]
+		if !ok {
+			c = &candidate{source: source}
+			candidates[prefix] = c
+		}
+		c.votes++
+		c.depth += depth
+	}
+
+	if module != "" {
+		for _, src := range sources {
+			src = filepath.ToSlash(src)
+			if prefix, source := modulePrefix(src, module); prefix != "" {
+				vote(prefix, source, 0)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		files := localFiles(root)
+		for _, src := range sources {
+			src = filepath.ToSlash(src)
+			if prefix, depth := checkoutPrefix(src, files); prefix != "" {
+				vote(prefix, PathSourceCheckout, depth)
+			}
+		}
+	}
+
+	var best string
+	for prefix, c := range candidates {
+		if best == "" || betterCandidate(prefix, c.votes, c.depth, best, candidates[best].votes, candidates[best].depth) {
+			best = prefix
+		}
+	}
+	if best != "" {
+		m.Rules = append(m.Rules, PathRule{Binary: best, Local: slashRoot, Source: candidates[best].source})
+	}
+	return m
+}
+
+// betterCandidate reports whether prefix a beats prefix b: more sources
+// matched, then longer matched trailing paths, then the shorter prefix
+func betterCandidate(a string, aVotes, aDepth int, b string, bVotes, bDepth int) bool {
+	if aVotes != bVotes {
+		return aVotes > bVotes
+	}
+	if aDepth != bDepth {
+		return aDepth > bDepth
+	}
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// translate replaces the directory from with to in p if p is below from
+func translate(p, from, to string) (string, bool) {
+	slash := filepath.ToSlash(p)
+	if slash == from {
+		return to, true
+	}
+	if rest, ok := strings.CutPrefix(slash, from+"/"); ok {
+		return path.Join(to, rest), true
+	}
+	return p, false
+}
+
+// ToBinary returns the compile path of a local file, or the file unchanged
+// if no rule covers it
+func (m *PathMap) ToBinary(local string) string {
+	if m == nil {
+		return local
+	}
+	for _, r := range m.Rules {
+		if p, ok := translate(local, r.Local, r.Binary); ok {
+			return p
+		}
+	}
+	return local
+}
+
+// ToLocal returns the local file for a compile path, or the path unchanged if
+// no rule covers it
+func (m *PathMap) ToLocal(binary string) string {
+	if m == nil {
+		return binary
+	}
+	for _, r := range m.Rules {
+		if p, ok := translate(binary, r.Binary, r.Local); ok {
+			return filepath.FromSlash(p)
+		}
+	}
+	return binary
+}
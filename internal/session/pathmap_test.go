@@ -0,0 +1,74 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeCheckout creates a module example.com/App with a few files in a temp dir
+func writeCheckout(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":              "module example.com/App\n\ngo 1.25\n",
+		"main.go":             "package main\n",
+		"internal/db/db.go":   "package db\n",
+		"internal/db/conn.go": "package db\n",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// TestBuildPathMap checks detection of each layout and the translation both ways.
+func TestBuildPathMap(t *testing.T) {
+	root := writeCheckout(t)
+	std := "/usr/local/go/src/fmt/print.go"
+	tests := []struct {
+		name    string
+		sources []string
+		binary  string // compile prefix expected for root; empty for no rule
+		source  string
+	}{
+		{"same checkout", []string{root + "/main.go", std}, "", ""},
+		{"trimpath", []string{"example.com/App/main.go", "example.com/App/internal/db/db.go", "fmt/print.go"}, "example.com/App", PathSourceTrimpath},
+		{"module cache", []string{"/home/ci/go/pkg/mod/example.com/!app@v1.4.0/main.go", std}, "/home/ci/go/pkg/mod/example.com/!app@v1.4.0", PathSourceModuleCache},
+		{"gopath", []string{"/home/ci/go/src/example.com/App/internal/db/db.go", std}, "/home/ci/go/src/example.com/App", PathSourceGOPATH},
+		{"other checkout", []string{"/build/ws/internal/db/db.go", "/build/ws/internal/db/conn.go", "/build/ws/main.go", std}, "/build/ws", PathSourceCheckout},
+		{"unrelated", []string{"/build/other/server.go", std}, "", ""},
+	}
+	for _, tt := range tests {
+		m := BuildPathMap(tt.sources, filepath.Join(root, "internal"))
+		if m.Root != root || m.Module != "example.com/App" {
+			t.Errorf("%s: root %q module %q", tt.name, m.Root, m.Module)
+		}
+		if tt.binary == "" {
+			if len(m.Rules) != 0 {
+				t.Errorf("%s: unexpected rules %v", tt.name, m.Rules)
+			}
+			continue
+		}
+		if len(m.Rules) != 1 || m.Rules[0].Binary != tt.binary || m.Rules[0].Source != tt.source {
+			t.Errorf("%s: rules %v, want %s (%s)", tt.name, m.Rules, tt.binary, tt.source)
+			continue
+		}
+		local := filepath.Join(root, "internal", "db", "db.go")
+		compiled := m.ToBinary(local)
+		if compiled != tt.binary+"/internal/db/db.go" {
+			t.Errorf("%s: ToBinary(%s) = %s", tt.name, local, compiled)
+		}
+		if back := m.ToLocal(compiled); back != local {
+			t.Errorf("%s: ToLocal(%s) = %s, want %s", tt.name, compiled, back, local)
+		}
+		if got := m.ToLocal(std); got != std {
+			t.Errorf("%s: ToLocal(%s) = %s, want unchanged", tt.name, std, got)
+		}
+	}
+}
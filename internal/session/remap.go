@@ -0,0 +1,60 @@
+package session
+
+import "strings"
+
+// remapContext is how many lines above and below a breakpoint line are used as anchors
+const remapContext = 3
+
+// RemapLine maps a 1-based line number in oldLines to the line holding the same
+// code in newLines. The line's own text must still exist in the new file; among
+// all candidates the one whose surrounding lines match best wins, with ties going
+// to the candidate closest to the original position. It returns false when the
+// line cannot be located with confidence (deleted or rewritten).
+func RemapLine(oldLines, newLines []string, line int) (int, bool) {
+	if line < 1 || line > len(oldLines) {
+		return 0, false
+	}
+	target := strings.TrimSpace(oldLines[line-1])
+
+	best, bestScore, bestDist := 0, -1, 0
+	for i := range newLines {
+		if strings.TrimSpace(newLines[i]) != target {
+			continue
+		}
+		score := contextScore(oldLines, newLines, line-1, i)
+		dist := abs(i - (line - 1))
+		if score > bestScore || (score == bestScore && dist < bestDist) {
+			best, bestScore, bestDist = i+1, score, dist
+		}
+	}
+
+	if best == 0 {
+		return 0, false
+	}
+	// Lines without content of their own ("}", blank) need at least one anchor
+	if bestScore == 0 && len(target) < 3 {
+		return 0, false
+	}
+	return best, true
+}
+
+// contextScore counts how many of the surrounding lines of old[oi] and new[ni] match
+func contextScore(oldLines, newLines []string, oi, ni int) int {
+	score := 0
+	for d := 1; d <= remapContext; d++ {
+		if oi-d >= 0 && ni-d >= 0 && strings.TrimSpace(oldLines[oi-d]) == strings.TrimSpace(newLines[ni-d]) {
+			score++
+		}
+		if oi+d < len(oldLines) && ni+d < len(newLines) && strings.TrimSpace(oldLines[oi+d]) == strings.TrimSpace(newLines[ni+d]) {
+			score++
+		}
+	}
+	return score
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
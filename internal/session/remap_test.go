@@ -0,0 +1,80 @@
+package session
+
+import (
+	"fmt"
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+var remapSource = []string{
+	"package main",
+	"",
+	"func main() {",
+	"\tx := compute(1)",
+	"\tfmt.Println(x)",
+	"}",
+	"",
+	"func compute(n int) int {",
+	"\treturn n * 2",
+	"}",
+}
+
+// TestRemapLineInsertAbove checks that inserting lines above a breakpoint shifts it down.
+func TestRemapLineInsertAbove(t *testing.T) {
+	edited := append([]string{"// header", "// more"}, remapSource...)
+	got, ok := RemapLine(remapSource, edited, 9)
+	if !ok || got != 11 {
+		t.Fatalf("RemapLine = %d, %v; want 11, true", got, ok)
+	}
+}
+
+// TestRemapLineDeleted checks that a rewritten line is reported as unmappable.
+func TestRemapLineDeleted(t *testing.T) {
+	edited := append([]string{}, remapSource...)
+	edited[8] = "\treturn n + n"
+	if got, ok := RemapLine(remapSource, edited, 9); ok {
+		t.Fatalf("RemapLine = %d, true; want not found", got)
+	}
+}
+
+// TestRemapLineAmbiguousBrace checks that a closing brace is anchored by its context.
+func TestRemapLineAmbiguousBrace(t *testing.T) {
+	edited := append([]string{}, remapSource[:4]...)
+	edited = append(edited, "\tlog.Println(\"start\")")
+	edited = append(edited, remapSource[4:]...)
+	got, ok := RemapLine(remapSource, edited, 10)
+	if !ok || got != 11 {
+		t.Fatalf("RemapLine = %d, %v; want 11, true", got, ok)
+	}
+}
+
+// TestRemapLineShiftProperty checks that inserting unrelated lines at a random
+// position shifts every line after it by the number of inserted lines.
+func TestRemapLineShiftProperty(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		n := rapid.IntRange(5, 40).Draw(t, "n")
+		old := make([]string, n)
+		for i := range old {
+			old[i] = fmt.Sprintf("stmt%d()", i)
+		}
+		at := rapid.IntRange(0, n).Draw(t, "at")
+		k := rapid.IntRange(0, 5).Draw(t, "k")
+
+		edited := append([]string{}, old[:at]...)
+		for i := 0; i < k; i++ {
+			edited = append(edited, fmt.Sprintf("inserted%d()", i))
+		}
+		edited = append(edited, old[at:]...)
+
+		line := rapid.IntRange(1, n).Draw(t, "line")
+		want := line
+		if line > at {
+			want = line + k
+		}
+		got, ok := RemapLine(old, edited, line)
+		if !ok || got != want {
+			t.Fatalf("RemapLine(line=%d, at=%d, k=%d) = %d, %v; want %d", line, at, k, got, ok, want)
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/8gears/godebug-agentic/internal/output"
+)
+
+const (
+	// fileName is the name of the session record inside the session directory
+	fileName = "session.json"
+
+	// snapshotDir holds content-addressed copies of recorded source files
+	snapshotDir = "sources"
+)
+
+// FileStamp records the state of a source file when it was last observed
+type FileStamp struct {
+	ModTime time.Time `json:"modTime"`
+	SHA256  string    `json:"sha256"`
+}
+
+// Session holds what godebug remembers about a debug session between invocations.
+// Delve keeps the runtime state; this only stores what Delve cannot tell us.
+type Session struct {
+	Addr      string               `json:"addr"`
+	PID       int                  `json:"pid,omitempty"`
+	Target    string               `json:"target,omitempty"`
+	Mode      string               `json:"mode,omitempty"`
+	Backend   string               `json:"backend,omitempty"`
+	StartedAt time.Time            `json:"startedAt"`
+	Sources   map[string]FileStamp `json:"sources,omitempty"`
+	OnCrash   string               `json:"onCrash,omitempty"`
+	Stdout    string               `json:"stdout,omitempty"`
+	Stderr    string               `json:"stderr,omitempty"`
+	ServerLog string               `json:"serverLog,omitempty"`
+	// EndedBy is the command that ended the session (quit or detach), so a
+	// later command can tell an ended session from a server that died
+	EndedBy string     `json:"endedBy,omitempty"`
+	EndedAt *time.Time `json:"endedAt,omitempty"`
+}
+
+// BaseDir returns the directory holding all session directories
+func BaseDir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", output.InternalError(fmt.Sprintf("cannot determine cache directory: %v", err))
+	}
+	return filepath.Join(cache, "godebug", "sessions"), nil
+}
+
+// NewOutputDir creates a directory for the target's redirected output. It is
+// needed before the server address (and so the session directory) is known.
+func NewOutputDir() (string, error) {
+	base, err := BaseDir()
+	if err != nil {
+		return "", err
+	}
+	parent := filepath.Join(base, "output")
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return "", output.InternalError(fmt.Sprintf("cannot create output directory: %v", err))
+	}
+	dir, err := os.MkdirTemp(parent, "run-")
+	if err != nil {
+		return "", output.InternalError(fmt.Sprintf("cannot create output directory: %v", err))
+	}
+	return dir, nil
+}
+
+// BuildsDir returns the directory holding the binaries prebuilt for
+// start --reuse-build, shared by all sessions
+func BuildsDir() (string, error) {
+	base, err := BaseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "builds"), nil
+}
+
+// Dir returns the directory for the session served at addr
+func Dir(addr string) (string, error) {
+	base, err := BaseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, sanitize(addr)), nil
+}
+
+// ID returns the identifier of the session at addr: the name of its session
+// directory. ID is idempotent, so an ID can be used wherever an address is.
+func ID(addr string) string {
+	return sanitize(addr)
+}
+
+// sanitize turns a server address into a safe directory name
+func sanitize(addr string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, addr)
+}
+
+// New creates a session record for addr (not yet saved)
+func New(addr string) *Session {
+	return &Session{
+		Addr:      addr,
+		StartedAt: time.Now(),
+		Sources:   map[string]FileStamp{},
+	}
+}
+
+// Load reads the session record for addr
+func Load(addr string) (*Session, error) {
+	dir, err := Dir(addr)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, output.NotFound("session", addr)
+		}
+		return nil, output.InternalError(fmt.Sprintf("cannot read session: %v", err))
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, output.InternalError(fmt.Sprintf("corrupt session record: %v", err))
+	}
+	if s.Sources == nil {
+		s.Sources = map[string]FileStamp{}
+	}
+	return &s, nil
+}
+
+// Save writes the session record, creating the session directory if needed
+func (s *Session) Save() error {
+	return SaveData(s.Addr, fileName, s)
+}
+
+// Remove deletes the session directory for addr
+func Remove(addr string) error {
+	dir, err := Dir(addr)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// LoadData reads a JSON document stored in the session directory for addr.
+// A missing document is not an error and leaves v untouched.
+func LoadData(addr, name string, v any) error {
+	dir, err := Dir(addr)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return output.InternalError(fmt.Sprintf("cannot read %s: %v", name, err))
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return output.InternalError(fmt.Sprintf("corrupt %s: %v", name, err))
+	}
+	return nil
+}
+
+// SaveData stores a JSON document in the session directory for addr
+func SaveData(addr, name string, v any) error {
+	dir, err := Dir(addr)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return output.InternalError(fmt.Sprintf("cannot create session directory: %v", err))
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return output.InternalError(fmt.Sprintf("cannot encode %s: %v", name, err))
+	}
+	// Write atomically so a concurrent invocation never sees a partial document
+	tmp := filepath.Join(dir, name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return output.InternalError(fmt.Sprintf("cannot write %s: %v", name, err))
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+		return output.InternalError(fmt.Sprintf("cannot write %s: %v", name, err))
+	}
+	return nil
+}
+
+// Stamp computes the current FileStamp of a file
+func Stamp(path string) (FileStamp, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return FileStamp{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return FileStamp{}, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return FileStamp{}, err
+	}
+	return FileStamp{ModTime: info.ModTime(), SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// RecordSources stamps the given files and keeps a snapshot of their content,
+// skipping any that are not readable locally
+func (s *Session) RecordSources(paths []string) {
+	dir, err := Dir(s.Addr)
+	if err != nil {
+		return
+	}
+	for _, p := range paths {
+		stamp, err := Stamp(p)
+		if err != nil {
+			continue
+		}
+		s.Sources[p] = stamp
+
+		// Snapshots are content-addressed, so unchanged files are stored once
+		snap := filepath.Join(dir, snapshotDir, stamp.SHA256)
+		if _, err := os.Stat(snap); err == nil {
+			continue
+		}
+		if content, err := os.ReadFile(p); err == nil {
+			_ = os.MkdirAll(filepath.Dir(snap), 0o700)
+			_ = os.WriteFile(snap, content, 0o600)
+		}
+	}
+}
+
+// Snapshot returns the content a recorded file had when it was last stamped
+func (s *Session) Snapshot(path string) ([]byte, error) {
+	stamp, ok := s.Sources[path]
+	if !ok {
+		return nil, output.NotFound("source snapshot", path)
+	}
+	dir, err := Dir(s.Addr)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, snapshotDir, stamp.SHA256))
+	if err != nil {
+		return nil, output.NotFound("source snapshot", path)
+	}
+	return content, nil
+}
+
+// ChangedSources returns recorded files whose content differs from what is on disk.
+// Files that were removed are reported as changed as well.
+func (s *Session) ChangedSources() []string {
+	changed := []string{}
+	for p, old := range s.Sources {
+		cur, err := Stamp(p)
+		if err != nil || cur.SHA256 != old.SHA256 {
+			changed = append(changed, p)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -1,7 +1,32 @@
 package main
 
-import "github.com/8gears/godebug-agentic/cmd"
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/8gears/godebug-agentic/cmd"
+)
+
+// debugme is the program debugged by godebug selftest
+//
+//go:embed testdata/debugme/*.go
+var debugme embed.FS
+
+// concurrencyBugs are the scenarios of godebug tutorial
+//
+//go:embed testdata/concurrency_bugs
+var concurrencyBugs embed.FS
+
+// agentSkill is the skill document godebug init-agent installs
+//
+//go:embed .claude/skills/godebug/SKILL.md
+var agentSkill []byte
 
 func main() {
+	program, _ := fs.Sub(debugme, "testdata/debugme")
+	cmd.SetSelftestProgram(program)
+	scenarios, _ := fs.Sub(concurrencyBugs, "testdata/concurrency_bugs")
+	cmd.SetTutorialPrograms(scenarios)
+	cmd.SetAgentSkill(agentSkill)
 	cmd.Execute()
 }